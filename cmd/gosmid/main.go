@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+)
+
+type arrayStrings []string
+
+func (a arrayStrings) String() string {
+	return strings.Join(a, ",")
+}
+
+func (a *arrayStrings) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// gosmid serves the MIB data of a single set of loaded modules over HTTP as
+// JSON, so that services can query it without loading the MIBs themselves.
+// See server.go for the endpoints.
+func main() {
+	log.SetFlags(0)
+
+	addr := flag.String("addr", "localhost:8161", "Address to listen on")
+	var paths, modules arrayStrings
+	flag.Var(&paths, "p", "Path to add to the MIB search path (repeatable)")
+	flag.Var(&modules, "m", "Module to load at startup (repeatable)")
+	flag.Parse()
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	for _, path := range paths {
+		gosmi.AppendPath(path)
+	}
+	for _, module := range modules {
+		if _, err := gosmi.LoadModule(module); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	log.Printf("Listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, newServer()); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
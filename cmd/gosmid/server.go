@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// maxLintBytes bounds the modules POSTed to /v1/lint
+const maxLintBytes = 4 << 20
+
+// server answers these requests, with JSON, errors as {"error": "..."}:
+//
+//	GET    /v1/translate?oid=IF-MIB::ifInOctets.3  node and instance of an OID
+//	GET    /v1/search?q=octets&limit=50            nodes whose name contains q
+//	GET    /v1/subtree?oid=ifTable                 nodes under an OID
+//	GET    /v1/node?oid=ifEntry&expand=type,index  node with its type and index inlined
+//	GET    /v1/type?name=DisplayString&module=SNMPv2-TC
+//	POST   /v1/lint                                diagnostics of the module in the body
+//	GET    /v1/modules                             statistics of the loaded modules
+//	POST   /v1/modules?name=IF-MIB                 load a module
+//	DELETE /v1/modules?name=IF-MIB                 unload a module and its dependents
+type server struct {
+	// mu serializes the requests, which share the loaded modules
+	mu     sync.Mutex
+	mux    *http.ServeMux
+	routes map[string]map[string]handlerFunc
+}
+
+type handlerFunc func(r *http.Request) (interface{}, error)
+
+// node is a node as returned by the server.
+type node struct {
+	Name        string         `json:"name"`
+	Module      string         `json:"module"`
+	Oid         string         `json:"oid"`
+	Kind        types.NodeKind `json:"kind"`
+	Syntax      string         `json:"syntax,omitempty"`
+	Access      types.Access   `json:"access,omitempty"`
+	Status      types.Status   `json:"status,omitempty"`
+	Description string         `json:"description,omitempty"`
+}
+
+type translation struct {
+	node
+	Instance  types.Oid `json:"instance,omitempty"`
+	Numeric   string    `json:"numeric"`
+	Qualified string    `json:"qualified"`
+}
+
+func newServer() *server {
+	s := &server{mux: http.NewServeMux(), routes: make(map[string]map[string]handlerFunc)}
+	s.handle("/v1/translate", http.MethodGet, s.translate)
+	s.handle("/v1/search", http.MethodGet, s.search)
+	s.handle("/v1/subtree", http.MethodGet, s.subtree)
+	s.handle("/v1/node", http.MethodGet, s.nodeInfo)
+	s.handle("/v1/type", http.MethodGet, s.typeInfo)
+	s.handle("/v1/lint", http.MethodPost, s.lint)
+	s.handle("/v1/modules", http.MethodGet, s.modules)
+	s.handle("/v1/modules", http.MethodPost, s.loadModule)
+	s.handle("/v1/modules", http.MethodDelete, s.unloadModule)
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// httpError is an error with the status to answer it with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string { return e.err.Error() }
+
+func badRequest(msg string) error {
+	return httpError{status: http.StatusBadRequest, err: errors.New(msg)}
+}
+
+func notFound(err error) error {
+	return httpError{status: http.StatusNotFound, err: err}
+}
+
+// handle routes the requests of a method to path to f, answering with what f
+// returns as JSON
+func (s *server) handle(path, method string, f handlerFunc) {
+	methods, ok := s.routes[path]
+	if !ok {
+		methods = make(map[string]handlerFunc)
+		s.routes[path] = methods
+		s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			f, ok := methods[r.Method]
+			if !ok {
+				writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+				return
+			}
+			s.mu.Lock()
+			v, err := f(r)
+			s.mu.Unlock()
+			if err != nil {
+				status := http.StatusInternalServerError
+				var httpErr httpError
+				if errors.As(err, &httpErr) {
+					status = httpErr.status
+				}
+				writeJSON(w, status, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, v)
+		})
+	}
+	methods[method] = f
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newNode(n gosmi.SmiNode) node {
+	out := node{
+		Name:        n.Name,
+		Module:      n.GetModule().Name,
+		Oid:         n.RenderNumeric(),
+		Kind:        n.Kind,
+		Access:      n.Access,
+		Status:      n.Status,
+		Description: n.Description,
+	}
+	if n.Type != nil {
+		out.Syntax = n.Type.Name
+		if out.Syntax == "" {
+			out.Syntax = n.Type.BaseType.String()
+		}
+	}
+	return out
+}
+
+func (s *server) translate(r *http.Request) (interface{}, error) {
+	oid := r.URL.Query().Get("oid")
+	if oid == "" {
+		return nil, badRequest("Missing oid")
+	}
+	n, instance, err := gosmi.ParseOID(oid)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return translation{
+		node:      newNode(n),
+		Instance:  instance,
+		Numeric:   n.FormatOID(types.OidFormatNumeric, instance...),
+		Qualified: n.FormatOID(types.OidFormatModule, instance...),
+	}, nil
+}
+
+func (s *server) search(r *http.Request) (interface{}, error) {
+	q := strings.ToLower(r.URL.Query().Get("q"))
+	if q == "" {
+		return nil, badRequest("Missing q")
+	}
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return nil, badRequest("Invalid limit " + l)
+		}
+	}
+	nodes := []node{}
+	for _, module := range gosmi.GetLoadedModules() {
+		for _, n := range module.GetNodes() {
+			if len(nodes) == limit {
+				return nodes, nil
+			}
+			if strings.Contains(strings.ToLower(n.Name), q) {
+				nodes = append(nodes, newNode(n))
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (s *server) subtree(r *http.Request) (interface{}, error) {
+	oid := r.URL.Query().Get("oid")
+	if oid == "" {
+		return nil, badRequest("Missing oid")
+	}
+	root, instance, err := gosmi.ParseOID(oid)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if len(instance) > 0 {
+		return nil, notFound(errors.New("No node is defined at " + oid))
+	}
+	nodes := []node{}
+	for _, n := range root.GetSubtree() {
+		nodes = append(nodes, newNode(n))
+	}
+	return nodes, nil
+}
+
+func (s *server) nodeInfo(r *http.Request) (interface{}, error) {
+	oid := r.URL.Query().Get("oid")
+	if oid == "" {
+		return nil, badRequest("Missing oid")
+	}
+	var opts []gosmi.MarshalOption
+	if expand := r.URL.Query().Get("expand"); expand != "" {
+		for _, e := range strings.Split(expand, ",") {
+			switch e {
+			case "type":
+				opts = append(opts, gosmi.WithTypeExpansion())
+			case "index":
+				opts = append(opts, gosmi.WithIndexExpansion())
+			default:
+				return nil, badRequest("Invalid expand " + e)
+			}
+		}
+	}
+	n, _, err := gosmi.ParseOID(oid)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return gosmi.NewNodeJSON(n, opts...), nil
+}
+
+func (s *server) typeInfo(r *http.Request) (interface{}, error) {
+	name, module := r.URL.Query().Get("name"), r.URL.Query().Get("module")
+	if name == "" {
+		return nil, badRequest("Missing name")
+	}
+	var t gosmi.SmiType
+	var err error
+	if module != "" {
+		t, err = gosmi.GetTypeInModule(module, name)
+	} else {
+		t, err = gosmi.GetType(name)
+	}
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return t, nil
+}
+
+func (s *server) lint(r *http.Request) (interface{}, error) {
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxLintBytes))
+	if err != nil {
+		return nil, badRequest("Read module: " + err.Error())
+	}
+	filename := r.URL.Query().Get("filename")
+	if filename == "" {
+		filename = "module.mib"
+	}
+	var collector diag.Collector
+	module, err := parser.ParseBytes(filename, b, parser.WithDiagnostics(&collector), parser.WithLimits(parser.Limits{MaxBytes: maxLintBytes - 1}))
+	diagnostics := append([]diag.Diagnostic{}, collector.Diagnostics()...)
+	if err == nil {
+		diagnostics = append(diagnostics, lint.Check(filename, module)...)
+	}
+	return diagnostics, nil
+}
+
+func (s *server) modules(r *http.Request) (interface{}, error) {
+	return append([]gosmi.ModuleStats{}, gosmi.GetStats()...), nil
+}
+
+func (s *server) loadModule(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return nil, badRequest("Missing name")
+	}
+	module, err := gosmi.LoadModule(name)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	m, err := gosmi.GetModule(module)
+	if err != nil {
+		return nil, err
+	}
+	return m.Stats(), nil
+}
+
+func (s *server) unloadModule(r *http.Request) (interface{}, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return nil, badRequest("Missing name")
+	}
+	report, err := gosmi.UnloadModule(name)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return report, nil
+}
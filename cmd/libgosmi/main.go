@@ -0,0 +1,166 @@
+// Command libgosmi builds gosmi as a C shared library, for embedding it in
+// place of libsmi in programs not written in Go. Build it with
+//
+//	go build -buildmode=c-shared -o libgosmi.so ./cmd/libgosmi
+//
+// which also writes libgosmi.h. All functions are safe to call from several
+// threads, the calls being serialized. Strings returned are allocated with
+// malloc and must be released with gosmi_free. Functions returning a string
+// return NULL on error, after which gosmi_last_error describes it, until the
+// next call from any thread.
+//
+// From Python, for instance:
+//
+//	lib = ctypes.CDLL("./libgosmi.so")
+//	lib.gosmi_translate.restype = ctypes.c_void_p
+//	lib.gosmi_init(b"/usr/share/snmp/mibs")
+//	lib.gosmi_load_module(b"IF-MIB")
+//	p = lib.gosmi_translate(b"IF-MIB::ifInOctets.3")
+//	print(ctypes.string_at(p))  # b"1.3.6.1.2.1.2.2.1.10.3"
+//	lib.gosmi_free(p)
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"sync"
+	"unsafe"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+var (
+	mu      sync.Mutex
+	lastErr error
+)
+
+// nodeResult is the JSON of gosmi_get_node.
+type nodeResult struct {
+	gosmi.SmiNode
+	Module   string    `json:"Module"`
+	Instance types.Oid `json:"Instance,omitempty"`
+}
+
+func main() {}
+
+// gosmi_init initializes gosmi, searching for modules in path, a list of
+// directories separated like PATH. A NULL path keeps the default path.
+//
+//export gosmi_init
+func gosmi_init(path *C.char) {
+	mu.Lock()
+	defer mu.Unlock()
+	gosmi.Init()
+	if path != nil {
+		gosmi.SetPath(C.GoString(path))
+	}
+}
+
+// gosmi_exit unloads all modules and releases the resources of gosmi.
+//
+//export gosmi_exit
+func gosmi_exit() {
+	mu.Lock()
+	defer mu.Unlock()
+	gosmi.Exit()
+}
+
+// gosmi_load_module loads the module named name, or the file at name, with
+// the modules it imports, and returns the name of the module.
+//
+//export gosmi_load_module
+func gosmi_load_module(name *C.char) *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	module, err := gosmi.LoadModule(C.GoString(name))
+	return result(module, err)
+}
+
+// gosmi_get_node returns the node an OID belongs to as JSON, with the
+// instance sub-identifiers following it. The OID is numeric, symbolic or
+// module-qualified, such as 1.3.6.1.2.1.2.2.1.10.3 or IF-MIB::ifInOctets.3.
+//
+//export gosmi_get_node
+func gosmi_get_node(oid *C.char) *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	node, instance, err := gosmi.ParseOID(C.GoString(oid))
+	if err != nil {
+		return result("", err)
+	}
+	b, err := json.Marshal(nodeResult{SmiNode: node, Module: node.GetModule().Name, Instance: instance})
+	return result(string(b), err)
+}
+
+// gosmi_translate translates a symbolic OID, such as IF-MIB::ifInOctets.3 or
+// ifInOctets.3, to a numeric one.
+//
+//export gosmi_translate
+func gosmi_translate(name *C.char) *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	node, instance, err := gosmi.ParseOID(C.GoString(name))
+	if err != nil {
+		return result("", err)
+	}
+	return result(node.FormatOID(types.OidFormatNumeric, instance...), nil)
+}
+
+// gosmi_lint parses and lints the file at path without loading it, and
+// returns its diagnostics as a JSON array.
+//
+//export gosmi_lint
+func gosmi_lint(path *C.char) *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	filename := C.GoString(path)
+	var collector diag.Collector
+	module, err := parser.ParseFile(filename, parser.WithDiagnostics(&collector))
+	diagnostics := append([]diag.Diagnostic{}, collector.Diagnostics()...)
+	if err == nil {
+		diagnostics = append(diagnostics, lint.Check(filename, module)...)
+	} else if collector.Len() == 0 {
+		// Such as a file that cannot be read
+		return result("", err)
+	}
+	b, err := json.Marshal(diagnostics)
+	return result(string(b), err)
+}
+
+// gosmi_last_error describes why the last call returning a string returned
+// NULL. It returns NULL if that call succeeded.
+//
+//export gosmi_last_error
+func gosmi_last_error() *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	if lastErr == nil {
+		return nil
+	}
+	return C.CString(lastErr.Error())
+}
+
+// gosmi_free releases a string returned by gosmi.
+//
+//export gosmi_free
+func gosmi_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// result returns s as a C string, or NULL if err is set, recording err for
+// gosmi_last_error.
+func result(s string, err error) *C.char {
+	lastErr = err
+	if err != nil {
+		return nil
+	}
+	return C.CString(s)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/lukeod/gosmi"
+)
+
+// benchCompile compiles dirPath rounds times, each into a fresh handle, and
+// logs the time each round took. If set, a CPU profile of the rounds is
+// written to cpuProfile and a profile of the memory they allocated to
+// memProfile, for go tool pprof.
+func benchCompile(dirPath string, workers, rounds int, cpuProfile, memProfile string) {
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			log.Fatalf("Error creating CPU profile: %v", err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatalf("Error starting CPU profile: %v", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var total, fastest time.Duration
+	for i := 0; i < rounds; i++ {
+		initFork()
+		start := time.Now()
+		report, err := gosmi.CompileDir(dirPath, gosmi.WithWorkers(workers))
+		elapsed := time.Since(start)
+		gosmi.Exit()
+		if err != nil {
+			log.Fatalf("Error compiling directory %q: %v", dirPath, err)
+		}
+		log.Printf("Round %d: compiled %d files in %s, %d failed.", i+1, len(report.Files), elapsed, len(report.Failed()))
+		total += elapsed
+		if i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+	log.Printf("%d rounds, %s on average, %s at best.", rounds, total/time.Duration(rounds), fastest)
+
+	if memProfile != "" {
+		f, err := os.Create(memProfile)
+		if err != nil {
+			log.Fatalf("Error creating memory profile: %v", err)
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			log.Fatalf("Error writing memory profile: %v", err)
+		}
+	}
+}
@@ -1,8 +1,17 @@
 package main
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/models"
 	mainline_gosmi "github.com/sleepinggenius2/gosmi"
+	mainline_models "github.com/sleepinggenius2/gosmi/models"
 	// Note: types and mainline_types are implicitly used via the structs defined in types.go
 	// We might need "fmt" if we uncomment the detailed unknown type logging in helpers (moved to types.go)
 )
@@ -14,28 +23,17 @@ func compareModuleInfo(forkModule gosmi.SmiModule, mainlineModule mainline_gosmi
 	diffs := []ModuleInfoDifference{}
 
 	// Compare basic fields
-	if forkModule.Name != mainlineModule.Name {
-		diffs = append(diffs, ModuleInfoDifference{FieldName: "Name", Diff: ValuePair{Fork: forkModule.Name, Mainline: mainlineModule.Name}})
-	}
+	diffs = appendDiff(diffs, "Name", forkModule.Name, mainlineModule.Name)
 	// Path comparison might be noisy if libs load from different places, maybe skip?
 	// if forkModule.Path != mainlineModule.Path {
 	// 	diffs = append(diffs, ModuleInfoDifference{FieldName: "Path", Diff: ValuePair{Fork: forkModule.Path, Mainline: mainlineModule.Path}})
 	// }
-	if forkModule.Organization != mainlineModule.Organization {
-		diffs = append(diffs, ModuleInfoDifference{FieldName: "Organization", Diff: ValuePair{Fork: forkModule.Organization, Mainline: mainlineModule.Organization}})
-	}
-	if forkModule.ContactInfo != mainlineModule.ContactInfo {
-		diffs = append(diffs, ModuleInfoDifference{FieldName: "ContactInfo", Diff: ValuePair{Fork: forkModule.ContactInfo, Mainline: mainlineModule.ContactInfo}})
-	}
-	if forkModule.Description != mainlineModule.Description {
-		diffs = append(diffs, ModuleInfoDifference{FieldName: "Description", Diff: ValuePair{Fork: forkModule.Description, Mainline: mainlineModule.Description}})
-	}
-	if forkModule.Language.String() != mainlineModule.Language.String() { // Compare string representations of Language enum
-		diffs = append(diffs, ModuleInfoDifference{FieldName: "Language", Diff: ValuePair{Fork: forkModule.Language.String(), Mainline: mainlineModule.Language.String()}})
-	}
+	diffs = appendDiff(diffs, "Description", forkModule.Description, mainlineModule.Description)
+	diffs = appendDiff(diffs, "Language", forkModule.Language.String(), mainlineModule.Language.String())
 	// Conformance field doesn't exist in either SmiModule type, so removing this comparison
 
-	// TODO: Compare Imports, Revisions, Identity Node (or handle them in separate functions)
+	// Organization and ContactInfo are compared with the identity by compareIdentity,
+	// imports and revisions by compareImports and compareRevisions
 
 	return diffs
 }
@@ -55,7 +53,7 @@ func compareNodes(forkNodes []gosmi.SmiNode, mainlineNodes []mainline_gosmi.SmiN
 		mainlineNodeMap[node.Oid.String()] = node
 	}
 
-	// Use maxExamplesPerCategory constant (defined in types.go)
+	// Examples are limited by maxExamplesPerCategory (defined in types.go)
 	// comparedCount = len(forkNodeMap) + len(mainlineNodeMap) // Initial estimate, adjusted later
 
 	processedMainlineOids := make(map[string]bool)
@@ -72,30 +70,31 @@ func compareNodes(forkNodes []gosmi.SmiNode, mainlineNodes []mainline_gosmi.SmiN
 			// Compare Kind first using helper from types.go
 			forkKindStr := getNodeKindString(forkNode.Kind)
 			mainlineKindStr := getNodeKindString(mainlineNode.Kind)
-			if forkKindStr != mainlineKindStr {
+			if !sameField("Kind", forkKindStr, mainlineKindStr) {
 				kindDiff = &ValuePair{Fork: forkKindStr, Mainline: mainlineKindStr}
 			}
 
 			// Compare other relevant fields based on kind? Or generically?
 			// Generic approach: Compare common fields first.
-			if forkNode.Name != mainlineNode.Name {
-				nodeDiffs = append(nodeDiffs, ModuleInfoDifference{FieldName: "Name", Diff: ValuePair{Fork: forkNode.Name, Mainline: mainlineNode.Name}})
-			}
-			if forkNode.Status.String() != mainlineNode.Status.String() { // Compare string representation for enum
-				nodeDiffs = append(nodeDiffs, ModuleInfoDifference{FieldName: "Status", Diff: ValuePair{Fork: forkNode.Status.String(), Mainline: mainlineNode.Status.String()}})
-			}
-			if forkNode.Description != mainlineNode.Description {
-				nodeDiffs = append(nodeDiffs, ModuleInfoDifference{FieldName: "Description", Diff: ValuePair{Fork: forkNode.Description, Mainline: mainlineNode.Description}})
-			}
-			// Reference field doesn't exist in SmiNode
-			// Format field doesn't exist in SmiNode
-			// Units field doesn't exist in SmiNode
-			if forkNode.Access.String() != mainlineNode.Access.String() { // Access enum
-				nodeDiffs = append(nodeDiffs, ModuleInfoDifference{FieldName: "Access", Diff: ValuePair{Fork: forkNode.Access.String(), Mainline: mainlineNode.Access.String()}})
-			}
-			if forkNode.Decl.String() != mainlineNode.Decl.String() { // Decl enum
-				nodeDiffs = append(nodeDiffs, ModuleInfoDifference{FieldName: "Decl", Diff: ValuePair{Fork: forkNode.Decl.String(), Mainline: mainlineNode.Decl.String()}})
+			nodeDiffs = appendDiff(nodeDiffs, "Name", forkNode.Name, mainlineNode.Name)
+			nodeDiffs = appendDiff(nodeDiffs, "Status", forkNode.Status.String(), mainlineNode.Status.String())
+			nodeDiffs = appendDiff(nodeDiffs, "Description", forkNode.Description, mainlineNode.Description)
+			// Mainline only exposes these on the raw node and the node's type
+			mainlineRaw := mainlineNode.GetRaw()
+			nodeDiffs = appendDiff(nodeDiffs, "Reference", forkNode.Reference, mainlineRaw.Reference)
+			mainlineFormat, mainlineUnits := mainlineRaw.Format, mainlineRaw.Units
+			if mainlineNode.Type != nil {
+				if mainlineFormat == "" {
+					mainlineFormat = mainlineNode.Type.Format
+				}
+				if mainlineUnits == "" {
+					mainlineUnits = mainlineNode.Type.Units
+				}
 			}
+			nodeDiffs = appendDiff(nodeDiffs, "Format", forkNode.Format, mainlineFormat)
+			nodeDiffs = appendDiff(nodeDiffs, "Units", forkNode.Units, mainlineUnits)
+			nodeDiffs = appendDiff(nodeDiffs, "Access", forkNode.Access.String(), mainlineNode.Access.String())
+			nodeDiffs = appendDiff(nodeDiffs, "Decl", forkNode.Decl.String(), mainlineNode.Decl.String())
 			// TODO: Compare Type? This requires comparing SmiType objects, might need a dedicated helper or compare base type name.
 			// Compare forkNode.Type.Name vs mainlineNode.Type.Name ?
 			// Compare forkNode.Type.BaseType vs mainlineNode.Type.BaseType ?
@@ -106,7 +105,7 @@ func compareNodes(forkNodes []gosmi.SmiNode, mainlineNodes []mainline_gosmi.SmiN
 
 			if kindDiff != nil || len(nodeDiffs) > 0 {
 				modifiedTotal++
-				if len(modified) < maxExamplesPerCategory {
+				if keepExample(len(modified)) {
 					modified = append(modified, NodeDifference{
 						Name:     forkNode.Name, // Use fork's name as primary identifier in report
 						Oid:      oid,
@@ -120,7 +119,7 @@ func compareNodes(forkNodes []gosmi.SmiNode, mainlineNodes []mainline_gosmi.SmiN
 		} else {
 			// Node exists in Fork but not in Mainline -> Added
 			addedTotal++
-			if len(added) < maxExamplesPerCategory {
+			if keepExample(len(added)) {
 				added = append(added, SimplifiedNode{
 					Name: forkNode.Name,
 					Oid:  oid,
@@ -134,7 +133,7 @@ func compareNodes(forkNodes []gosmi.SmiNode, mainlineNodes []mainline_gosmi.SmiN
 	for oid, mainlineNode := range mainlineNodeMap {
 		if !processedMainlineOids[oid] {
 			removedTotal++
-			if len(removed) < maxExamplesPerCategory {
+			if keepExample(len(removed)) {
 				removed = append(removed, SimplifiedNode{
 					Name: mainlineNode.Name,
 					Oid:  oid,
@@ -167,7 +166,7 @@ func compareTypes(forkTypes []gosmi.SmiType, mainlineTypes []mainline_gosmi.SmiT
 		mainlineTypeMap[t.Name] = t
 	}
 
-	// Use maxExamplesPerCategory constant (defined in types.go)
+	// Examples are limited by maxExamplesPerCategory (defined in types.go)
 	// comparedCount = len(forkTypeMap) + len(mainlineTypeMap) // Initial estimate
 
 	processedMainlineNames := make(map[string]bool)
@@ -185,41 +184,35 @@ func compareTypes(forkTypes []gosmi.SmiType, mainlineTypes []mainline_gosmi.SmiT
 			forkBaseTypeStr := getBaseTypeString(forkType.BaseType)
 			mainlineBaseTypeStr := getBaseTypeString(mainlineType.BaseType)
 
-			if forkBaseTypeStr != mainlineBaseTypeStr {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "BaseType", Diff: ValuePair{Fork: forkBaseTypeStr, Mainline: mainlineBaseTypeStr}})
-			}
-			if forkType.Format != mainlineType.Format {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Format", Diff: ValuePair{Fork: forkType.Format, Mainline: mainlineType.Format}})
-			}
+			typeDiffs = appendDiff(typeDiffs, "BaseType", forkBaseTypeStr, mainlineBaseTypeStr)
+			typeDiffs = appendDiff(typeDiffs, "Format", forkType.Format, mainlineType.Format)
 			// Description comparison was missing, adding it back
-			if forkType.Description != mainlineType.Description {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Description", Diff: ValuePair{Fork: forkType.Description, Mainline: mainlineType.Description}})
-			}
+			typeDiffs = appendDiff(typeDiffs, "Description", forkType.Description, mainlineType.Description)
 			// Status comparison was incorrect (comparing Description), fixing it
-			if forkType.Status.String() != mainlineType.Status.String() {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Status", Diff: ValuePair{Fork: forkType.Status.String(), Mainline: mainlineType.Status.String()}})
-			}
-			if forkType.Reference != mainlineType.Reference {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Reference", Diff: ValuePair{Fork: forkType.Reference, Mainline: mainlineType.Reference}})
+			typeDiffs = appendDiff(typeDiffs, "Status", forkType.Status.String(), mainlineType.Status.String())
+			typeDiffs = appendDiff(typeDiffs, "Reference", forkType.Reference, mainlineType.Reference)
+			typeDiffs = appendDiff(typeDiffs, "Units", forkType.Units, mainlineType.Units)
+			typeDiffs = appendDiff(typeDiffs, "Decl", forkType.Decl.String(), mainlineType.Decl.String())
+
+			var enumDiff *EnumDifference
+			if !isIgnored("Enum") {
+				enumDiff = compareEnums(forkEnumValues(forkType), mainlineEnumValues(mainlineType))
 			}
-			if forkType.Units != mainlineType.Units {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Units", Diff: ValuePair{Fork: forkType.Units, Mainline: mainlineType.Units}})
+			var rangeDiff *RangeDifference
+			if !isIgnored("Range") {
+				rangeDiff = compareRanges(forkRangeValues(forkType), mainlineRangeValues(mainlineType))
 			}
-			if forkType.Decl.String() != mainlineType.Decl.String() {
-				typeDiffs = append(typeDiffs, ModuleInfoDifference{FieldName: "Decl", Diff: ValuePair{Fork: forkType.Decl.String(), Mainline: mainlineType.Decl.String()}})
-			}
-
-			// TODO: Compare NamedNumbers (slice) - needs deeper comparison (name, value)
-			// TODO: Compare Ranges (slice) - needs deeper comparison (min, max, baseType)
 
-			if kindDiff != nil || len(typeDiffs) > 0 {
+			if kindDiff != nil || len(typeDiffs) > 0 || enumDiff != nil || rangeDiff != nil {
 				modifiedTotal++
-				if len(modified) < maxExamplesPerCategory {
+				if keepExample(len(modified)) {
 					modified = append(modified, TypeDifference{
-						Name:     name,
-						BaseType: forkBaseTypeStr, // Use fork's base type string
-						KindDiff: kindDiff,        // If applicable
-						Diffs:    typeDiffs,
+						Name:      name,
+						BaseType:  forkBaseTypeStr, // Use fork's base type string
+						KindDiff:  kindDiff,        // If applicable
+						Diffs:     typeDiffs,
+						EnumDiff:  enumDiff,
+						RangeDiff: rangeDiff,
 					})
 				}
 			} else {
@@ -228,7 +221,7 @@ func compareTypes(forkTypes []gosmi.SmiType, mainlineTypes []mainline_gosmi.SmiT
 		} else {
 			// Type exists in Fork but not in Mainline -> Added
 			addedTotal++
-			if len(added) < maxExamplesPerCategory {
+			if keepExample(len(added)) {
 				added = append(added, SimplifiedType{
 					Name:     name,
 					BaseType: getBaseTypeString(forkType.BaseType), // Use helper
@@ -242,7 +235,7 @@ func compareTypes(forkTypes []gosmi.SmiType, mainlineTypes []mainline_gosmi.SmiT
 	for name, mainlineType := range mainlineTypeMap {
 		if !processedMainlineNames[name] {
 			removedTotal++
-			if len(removed) < maxExamplesPerCategory {
+			if keepExample(len(removed)) {
 				removed = append(removed, SimplifiedType{
 					Name:     name,
 					BaseType: getBaseTypeString(mainlineType.BaseType), // Use helper
@@ -257,6 +250,277 @@ func compareTypes(forkTypes []gosmi.SmiType, mainlineTypes []mainline_gosmi.SmiT
 	return added, removed, modified, comparedCount, okCount, addedTotal, removedTotal, modifiedTotal
 }
 
+func forkEnumValues(t gosmi.SmiType) (values []NamedValue) {
+	if t.Enum != nil {
+		for _, v := range t.Enum.Values {
+			values = append(values, NamedValue{Name: v.Name, Value: v.Value})
+		}
+	}
+	return
+}
+
+func mainlineEnumValues(t mainline_gosmi.SmiType) (values []NamedValue) {
+	if t.Enum != nil {
+		for _, v := range t.Enum.Values {
+			values = append(values, NamedValue{Name: v.Name, Value: v.Value})
+		}
+	}
+	return
+}
+
+func forkRangeValues(t gosmi.SmiType) (ranges []RangeValue) {
+	for _, r := range t.Ranges {
+		ranges = append(ranges, RangeValue{Min: r.MinValue, Max: r.MaxValue})
+	}
+	return
+}
+
+func mainlineRangeValues(t mainline_gosmi.SmiType) (ranges []RangeValue) {
+	for _, r := range t.Ranges {
+		ranges = append(ranges, RangeValue{Min: r.MinValue, Max: r.MaxValue})
+	}
+	return
+}
+
+// compareEnums compares named numbers by value, then by label for the values
+// only one side has, so that a renumbered label is reported once rather than
+// as an addition and a removal. It returns nil if they are the same.
+func compareEnums(fork, mainline []NamedValue) *EnumDifference {
+	diff := &EnumDifference{}
+	mainlineByValue := make(map[int64]string, len(mainline))
+	for _, v := range mainline {
+		mainlineByValue[v.Value] = v.Name
+	}
+	forkByValue := make(map[int64]string, len(fork))
+	for _, v := range fork {
+		forkByValue[v.Value] = v.Name
+	}
+
+	// Values only one side has, by label
+	forkOnly := make(map[string]int64)
+	var forkOnlyOrder []string
+	for _, v := range fork {
+		name, ok := mainlineByValue[v.Value]
+		switch {
+		case !ok:
+			forkOnly[v.Name] = v.Value
+			forkOnlyOrder = append(forkOnlyOrder, v.Name)
+		case name != v.Name:
+			diff.Relabeled = append(diff.Relabeled, LabelChange{Value: v.Value, Fork: v.Name, Mainline: name})
+		}
+	}
+	renumbered := make(map[string]bool)
+	for _, v := range mainline {
+		if _, ok := forkByValue[v.Value]; ok {
+			continue
+		}
+		if value, ok := forkOnly[v.Name]; ok {
+			diff.Renumbered = append(diff.Renumbered, ValueChange{Name: v.Name, Fork: value, Mainline: v.Value})
+			renumbered[v.Name] = true
+			continue
+		}
+		diff.Removed = append(diff.Removed, v)
+	}
+	for _, name := range forkOnlyOrder {
+		if !renumbered[name] {
+			diff.Added = append(diff.Added, NamedValue{Name: name, Value: forkOnly[name]})
+		}
+	}
+
+	var parts []string
+	if len(diff.Added) > 0 {
+		parts = append(parts, "added "+joinNamedValues(diff.Added))
+	}
+	if len(diff.Removed) > 0 {
+		parts = append(parts, "removed "+joinNamedValues(diff.Removed))
+	}
+	for _, c := range diff.Relabeled {
+		parts = append(parts, fmt.Sprintf("relabeled %d from %s to %s", c.Value, c.Mainline, c.Fork))
+	}
+	for _, c := range diff.Renumbered {
+		parts = append(parts, fmt.Sprintf("renumbered %s from %d to %d", c.Name, c.Mainline, c.Fork))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	diff.Summary = strings.Join(parts, "; ")
+	return diff
+}
+
+func joinNamedValues(values []NamedValue) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%s(%d)", v.Name, v.Value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// compareRanges compares the sets of values the ranges allow, so that
+// ranges split or merged differently but allowing the same values are the
+// same. It returns nil if they are the same.
+func compareRanges(fork, mainline []RangeValue) *RangeDifference {
+	forkSet, mainlineSet := mergeRanges(fork), mergeRanges(mainline)
+	forkCovers, mainlineCovers := coversRanges(forkSet, mainlineSet), coversRanges(mainlineSet, forkSet)
+	if forkCovers && mainlineCovers {
+		return nil
+	}
+	diff := &RangeDifference{Fork: fork, Mainline: mainline, Change: rangeDifferent}
+	switch {
+	case forkCovers:
+		diff.Change = rangeWidened
+	case mainlineCovers:
+		diff.Change = rangeNarrowed
+	}
+	diff.Summary = fmt.Sprintf("%s from %s to %s", diff.Change, formatRanges(mainline), formatRanges(fork))
+	return diff
+}
+
+// mergeRanges returns the ranges sorted, with those overlapping or adjacent
+// merged. No ranges allow any value.
+func mergeRanges(ranges []RangeValue) []RangeValue {
+	if len(ranges) == 0 {
+		return []RangeValue{{Min: math.MinInt64, Max: math.MaxInt64}}
+	}
+	sorted := append([]RangeValue(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Min < sorted[j].Min })
+	merged := sorted[:1]
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if last.Max == math.MaxInt64 || r.Min <= last.Max+1 {
+			if r.Max > last.Max {
+				last.Max = r.Max
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// coversRanges reports whether every value of b is in a, both merged.
+func coversRanges(a, b []RangeValue) bool {
+	for _, r := range b {
+		covered := false
+		for _, s := range a {
+			if s.Min <= r.Min && r.Max <= s.Max {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func formatRanges(ranges []RangeValue) string {
+	if len(ranges) == 0 {
+		return "unrestricted"
+	}
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		if r.Min == r.Max {
+			parts[i] = strconv.FormatInt(r.Min, 10)
+		} else {
+			parts[i] = fmt.Sprintf("%d..%d", r.Min, r.Max)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// compareImports compares imported symbols by name, in fork and then
+// mainline import order. A symbol imported from several modules by one side
+// is compared module by module.
+func compareImports(fork []models.Import, mainline []mainline_models.Import) (added, removed []ImportValue, moved []ImportMove) {
+	forkModules := make(map[string][]string)
+	for _, i := range fork {
+		forkModules[i.Name] = append(forkModules[i.Name], i.Module)
+	}
+	mainlineModules := make(map[string][]string)
+	for _, i := range mainline {
+		mainlineModules[i.Name] = append(mainlineModules[i.Name], i.Module)
+	}
+	contains := func(modules []string, module string) bool {
+		for _, m := range modules {
+			if m == module {
+				return true
+			}
+		}
+		return false
+	}
+	for _, i := range fork {
+		others := mainlineModules[i.Name]
+		switch {
+		case contains(others, i.Module):
+		case len(others) == 1 && len(forkModules[i.Name]) == 1:
+			moved = append(moved, ImportMove{Name: i.Name, Fork: i.Module, Mainline: others[0]})
+		default:
+			added = append(added, ImportValue{Module: i.Module, Name: i.Name})
+		}
+	}
+	for _, i := range mainline {
+		others := forkModules[i.Name]
+		if contains(others, i.Module) || (len(others) == 1 && len(mainlineModules[i.Name]) == 1) {
+			continue
+		}
+		removed = append(removed, ImportValue{Module: i.Module, Name: i.Name})
+	}
+	return
+}
+
+// revisionDate formats the date of a revision as RevisionValue has it.
+func revisionDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+// compareRevisions compares the revision histories by date, in fork and then
+// mainline order.
+func compareRevisions(fork []models.Revision, mainline []mainline_models.Revision) (added, removed []RevisionValue, modified []RevisionDifference) {
+	mainlineByDate := make(map[string]string, len(mainline))
+	for _, r := range mainline {
+		mainlineByDate[revisionDate(r.Date)] = r.Description
+	}
+	forkDates := make(map[string]bool, len(fork))
+	for _, r := range fork {
+		date := revisionDate(r.Date)
+		forkDates[date] = true
+		description, ok := mainlineByDate[date]
+		switch {
+		case !ok:
+			added = append(added, RevisionValue{Date: date, Description: r.Description})
+		case !sameField("Description", r.Description, description):
+			modified = append(modified, RevisionDifference{Date: date, Description: ValuePair{Fork: r.Description, Mainline: description}})
+		}
+	}
+	for _, r := range mainline {
+		if date := revisionDate(r.Date); !forkDates[date] {
+			removed = append(removed, RevisionValue{Date: date, Description: r.Description})
+		}
+	}
+	return
+}
+
+// compareIdentity compares the MODULE-IDENTITY of both versions, nil for
+// either if the module has none. It returns nil if they are the same.
+func compareIdentity(fork, mainline *IdentityValue) *IdentityDifference {
+	if fork == nil || mainline == nil {
+		if fork == mainline {
+			return nil
+		}
+		return &IdentityDifference{Fork: fork, Mainline: mainline}
+	}
+	var diffs []ModuleInfoDifference
+	diffs = appendDiff(diffs, "Name", fork.Name, mainline.Name)
+	diffs = appendDiff(diffs, "Oid", fork.Oid, mainline.Oid)
+	diffs = appendDiff(diffs, "Organization", fork.Organization, mainline.Organization)
+	diffs = appendDiff(diffs, "ContactInfo", fork.ContactInfo, mainline.ContactInfo)
+	if len(diffs) == 0 {
+		return nil
+	}
+	return &IdentityDifference{Diffs: diffs}
+}
+
 // compareResolvedResults performs the main semantic comparison.
 func compareResolvedResults(forkResolved, mainlineResolved map[string]interface{}) (*ComparisonResults, error) {
 	results := &ComparisonResults{} // Uses type from types.go
@@ -268,7 +532,6 @@ func compareResolvedResults(forkResolved, mainlineResolved map[string]interface{
 	var mainlineNodes []mainline_gosmi.SmiNode
 	var forkTypes []gosmi.SmiType
 	var mainlineTypes []mainline_gosmi.SmiType
-	// TODO: Extract Imports, Revisions, Identity Node
 
 	// Fork Data
 	fm, ok := forkResolved["moduleInfo"].(gosmi.SmiModule)
@@ -325,7 +588,23 @@ func compareResolvedResults(forkResolved, mainlineResolved map[string]interface{
 	results.TypesAdded, results.TypesRemoved, results.TypesModified,
 		results.TypesCompared, results.TypesOk, results.TypesAddedCount, results.TypesRemovedCount, results.TypesModifiedCount = compareTypes(forkTypes, mainlineTypes)
 
-	// TODO: Call comparison functions for Imports, Revisions, Identity Node
+	// Compare Imports and Revisions
+	if !isIgnored("Imports") {
+		results.ImportsAdded, results.ImportsRemoved, results.ImportsMoved = compareImports(forkModule.GetImports(), mainlineModule.GetImports())
+	}
+	if !isIgnored("Revisions") {
+		results.RevisionsAdded, results.RevisionsRemoved, results.RevisionsModified = compareRevisions(forkModule.GetRevisions(), mainlineModule.GetRevisions())
+	}
+
+	// Compare Identity
+	var forkIdentity, mainlineIdentity *IdentityValue
+	if fi, ok := forkModule.GetIdentity(); ok {
+		forkIdentity = &IdentityValue{Name: fi.Name, Oid: fi.Oid.String(), Organization: fi.Organization, ContactInfo: fi.ContactInfo}
+	}
+	if mi, ok := mainlineModule.GetIdentityNode(); ok {
+		mainlineIdentity = &IdentityValue{Name: mi.Name, Oid: mi.Oid.String(), Organization: mainlineModule.Organization, ContactInfo: mainlineModule.ContactInfo}
+	}
+	results.Identity = compareIdentity(forkIdentity, mainlineIdentity)
 
 	return results, nil
 }
@@ -337,8 +616,10 @@ func hasSemanticDifferences(results *ComparisonResults) bool {
 	}
 	return len(results.ModuleInfoDiffs) > 0 ||
 		results.NodesAddedCount > 0 || results.NodesRemovedCount > 0 || results.NodesModifiedCount > 0 ||
-		results.TypesAddedCount > 0 || results.TypesRemovedCount > 0 || results.TypesModifiedCount > 0
-	// || Add checks for Imports, Revisions, Identity
+		results.TypesAddedCount > 0 || results.TypesRemovedCount > 0 || results.TypesModifiedCount > 0 ||
+		len(results.ImportsAdded) > 0 || len(results.ImportsRemoved) > 0 || len(results.ImportsMoved) > 0 ||
+		len(results.RevisionsAdded) > 0 || len(results.RevisionsRemoved) > 0 || len(results.RevisionsModified) > 0 ||
+		results.Identity != nil
 }
 
 // compareDependencyResults compares fork and mainline dependency parsing results
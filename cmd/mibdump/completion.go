@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+)
+
+const (
+	completionBash = "bash"
+	completionZsh  = "zsh"
+	completionFish = "fish"
+
+	completeModules = "modules"
+	completeObjects = "objects"
+)
+
+// namesIndexEnv names the environment variable overriding the location of the
+// names index read by -complete.
+const namesIndexEnv = "MIBDUMP_NAMES"
+
+// flagValues lists the values completed for the flags taking one of a fixed
+// set.
+var flagValues = map[string][]string{
+	"output":      {"ast", "resolved", "all"},
+	"diag-format": {diagFormatText, diagFormatJSON},
+	"traps":       {trapFormatCSV, trapFormatJSON},
+	"stats":       {statsFormatText, statsFormatJSON},
+	"report":      {reportFormatJUnit, reportFormatSARIF},
+	"oid-format":  {"numeric", "full", "suffix", "module"},
+	"completion":  {completionBash, completionZsh, completionFish},
+	"complete":    {completeModules, completeObjects},
+}
+
+// fileFlags and dirFlags are the flags completed with file and directory
+// names.
+var (
+	fileFlags = map[string]bool{"config": true, "mibfile": true, "template": true, "cpuprofile": true, "memprofile": true, "libsmi": true, "html": true}
+	dirFlags  = map[string]bool{"dir": true}
+)
+
+// completionFlag is a flag as the completion scripts see it.
+type completionFlag struct {
+	name   string
+	usage  string
+	isBool bool
+}
+
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{name: f.Name, usage: f.Usage, isBool: ok && b.IsBoolFlag()})
+	})
+	return flags
+}
+
+// writeCompletion writes the completion script for shell to w. Flags are
+// completed from the flag set, and the module and object names of -extract
+// and -objects from the names index.
+func writeCompletion(w io.Writer, shell string) error {
+	flags := completionFlags()
+	switch shell {
+	case completionBash:
+		return writeBashCompletion(w, flags)
+	case completionZsh:
+		return writeZshCompletion(w, flags)
+	case completionFish:
+		return writeFishCompletion(w, flags)
+	}
+	return fmt.Errorf("Unknown shell %q", shell)
+}
+
+func writeBashCompletion(w io.Writer, flags []completionFlag) error {
+	var b strings.Builder
+	var names, files, dirs []string
+	b.WriteString(`# bash completion for mibdump, generated by mibdump -completion bash
+
+_mibdump() {
+    local cur prev module i
+    cur=${COMP_WORDS[COMP_CWORD]}
+    prev=${COMP_WORDS[COMP_CWORD-1]}
+    case $prev in
+`)
+	for _, f := range flags {
+		names = append(names, "-"+f.name)
+		if values, ok := flagValues[f.name]; ok {
+			fmt.Fprintf(&b, "    -%s)\n        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n        return ;;\n", f.name, strings.Join(values, " "))
+		} else if fileFlags[f.name] {
+			files = append(files, "-"+f.name)
+		} else if dirFlags[f.name] {
+			dirs = append(dirs, "-"+f.name)
+		}
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(&b, "    %s)\n        COMPREPLY=($(compgen -f -- \"$cur\"))\n        return ;;\n", strings.Join(files, "|"))
+	}
+	if len(dirs) > 0 {
+		fmt.Fprintf(&b, "    %s)\n        COMPREPLY=($(compgen -d -- \"$cur\"))\n        return ;;\n", strings.Join(dirs, "|"))
+	}
+	fmt.Fprintf(&b, `    -extract)
+        COMPREPLY=($(compgen -W "$(mibdump -complete %[1]s 2>/dev/null)" -- "$cur"))
+        return ;;
+    -objects)
+        for ((i = 1; i < COMP_CWORD - 1; i++)); do
+            [[ ${COMP_WORDS[i]} == -extract ]] && module=${COMP_WORDS[i+1]}
+        done
+        COMPREPLY=($(compgen -P "${cur%%"${cur##*,}"}" -W "$(mibdump -complete %[2]s $module 2>/dev/null)" -- "${cur##*,}"))
+        return ;;
+    esac
+    COMPREPLY=($(compgen -W %[3]q -- "$cur"))
+}
+
+complete -o default -F _mibdump mibdump
+`, completeModules, completeObjects, strings.Join(names, " "))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// zshEscape escapes the characters of s special to an _arguments spec in
+// single quotes.
+var zshEscape = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`, `'`, `'\''`)
+
+func writeZshCompletion(w io.Writer, flags []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `#compdef mibdump
+# zsh completion for mibdump, generated by mibdump -completion zsh
+
+_mibdump_modules() {
+    local -a names
+    names=(${(f)"$(mibdump -complete %[1]s 2>/dev/null)"})
+    compadd -a names
+}
+
+_mibdump_objects() {
+    local -a names
+    names=(${(f)"$(mibdump -complete %[2]s ${opt_args[-extract]} 2>/dev/null)"})
+    compset -P '*,'
+    compadd -a names
+}
+
+_arguments \
+`, completeModules, completeObjects)
+	for _, f := range flags {
+		spec := "-" + f.name + "[" + zshEscape.Replace(f.usage) + "]"
+		switch {
+		case f.isBool:
+		case flagValues[f.name] != nil:
+			spec += ":" + f.name + ":(" + strings.Join(flagValues[f.name], " ") + ")"
+		case fileFlags[f.name]:
+			spec += ":file:_files"
+		case dirFlags[f.name]:
+			spec += ":directory:_files -/"
+		case f.name == "extract":
+			spec += ":module:_mibdump_modules"
+		case f.name == "objects":
+			spec += ":object:_mibdump_objects"
+		default:
+			spec += ":" + f.name + ":"
+		}
+		fmt.Fprintf(&b, "    '%s' \\\n", spec)
+	}
+	b.WriteString("    && return 0\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishEscape escapes the characters of s special in fish single quotes.
+var fishEscape = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+func writeFishCompletion(w io.Writer, flags []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `# fish completion for mibdump, generated by mibdump -completion fish
+
+function __mibdump_objects
+    set -l tokens (commandline -opc)
+    set -l module
+    for i in (seq (math (count $tokens) - 1))
+        if test "$tokens[$i]" = -extract
+            set module $tokens[(math $i + 1)]
+        end
+    end
+    set -l prefix (string match -r '.*,' -- (commandline -ct))
+    for name in (mibdump -complete %[2]s $module 2>/dev/null)
+        echo $prefix$name
+    end
+end
+
+complete -c mibdump -f
+`, completeModules, completeObjects)
+	for _, f := range flags {
+		fmt.Fprintf(&b, "complete -c mibdump -o %s -d '%s'", f.name, fishEscape.Replace(f.usage))
+		switch {
+		case f.isBool:
+		case flagValues[f.name] != nil:
+			fmt.Fprintf(&b, " -x -a '%s'", strings.Join(flagValues[f.name], " "))
+		case fileFlags[f.name]:
+			b.WriteString(" -r -F")
+		case dirFlags[f.name]:
+			b.WriteString(" -x -a '(__fish_complete_directories)'")
+		case f.name == "extract":
+			fmt.Fprintf(&b, " -x -a '(mibdump -complete %s 2>/dev/null)'", completeModules)
+		case f.name == "objects":
+			b.WriteString(" -x -a '(__mibdump_objects)'")
+		default:
+			b.WriteString(" -x")
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// namesIndexPath returns the location of the names index, set by
+// MIBDUMP_NAMES or else in the user's cache directory.
+func namesIndexPath() (string, error) {
+	if path := os.Getenv(namesIndexEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("Locate names index: %w", err)
+	}
+	return filepath.Join(dir, "mibdump", "names"), nil
+}
+
+// readNamesIndex returns the lines of the names index: a module name, or a
+// module name and the name of one of its definitions separated by "::". A
+// missing index has no lines.
+func readNamesIndex(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Open names index: %w", err)
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// indexNames loads a single MIB file or compiles a directory and records the
+// names of the loaded modules and their definitions in the names index.
+// Entries of other modules already in the index are kept, so that several
+// directories can be indexed in turn.
+func indexNames(mibFilePath, dirPath string, workers int) {
+	path, err := namesIndexPath()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	existing, err := readNamesIndex(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	initFork()
+	defer gosmi.Exit()
+	loadFork(mibFilePath, dirPath, workers)
+
+	loaded := make(map[string]bool)
+	var lines []string
+	for _, m := range gosmi.GetLoadedModules() {
+		// Not a module that can be named, such as <well-known>
+		if strings.HasPrefix(m.Name, "<") {
+			continue
+		}
+		loaded[m.Name] = true
+		lines = append(lines, m.Name)
+		for _, n := range m.GetNodes() {
+			lines = append(lines, m.Name+"::"+n.Name)
+		}
+		for _, t := range m.GetTypes() {
+			lines = append(lines, m.Name+"::"+t.Name)
+		}
+	}
+	for _, line := range existing {
+		if module := strings.SplitN(line, "::", 2)[0]; !loaded[module] {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("Error creating names index directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		log.Fatalf("Error writing names index: %v", err)
+	}
+	log.Printf("Indexed %d modules in %s.", len(loaded), path)
+}
+
+// completeNames writes to w the module names in the names index, or the
+// definition names of module, or of every module if empty, for the
+// completion scripts. Errors are not reported, leaving nothing to complete.
+func completeNames(w io.Writer, kind, module string) {
+	path, err := namesIndexPath()
+	if err != nil {
+		return
+	}
+	lines, err := readNamesIndex(path)
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		parts := strings.SplitN(line, "::", 2)
+		var name string
+		switch {
+		case kind == completeModules && len(parts) == 1:
+			name = parts[0]
+		case kind == completeObjects && len(parts) == 2 && (module == "" || parts[0] == module):
+			name = parts[1]
+		default:
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			fmt.Fprintln(w, name)
+		}
+	}
+}
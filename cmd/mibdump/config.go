@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"path/filepath"
+
+	"github.com/lukeod/gosmi"
+)
+
+// config is the gosmi config in use, nil if there is none.
+var config *gosmi.Config
+
+// loadConfig reads the config at path, or else the closest gosmi.yaml,
+// gosmi.yml or gosmi.toml up from the working directory, if any, and applies
+// its output defaults and strictness to the flags not given on the command
+// line. Its search paths, aliases and excluded files are applied by initFork.
+func loadConfig(path string) {
+	if path == "" {
+		var err error
+		if path, err = gosmi.FindConfig("."); err != nil {
+			log.Fatalf("Error finding config: %v", err)
+		}
+		if path == "" {
+			return
+		}
+	}
+	cfg, err := gosmi.ParseConfigFile(path)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	config = cfg
+
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	defaults := map[string]string{
+		"oid-format":  cfg.Output.OIDFormat,
+		"diag-format": cfg.Output.DiagFormat,
+	}
+	if cfg.Strict {
+		defaults["strict"] = "true"
+	}
+	for name, value := range defaults {
+		if value == "" || given[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			log.Fatalf("Error applying config %s: %v", path, err)
+		}
+	}
+}
+
+// excludedByConfig reports whether the config excludes the file at path
+// found under dirPath.
+func excludedByConfig(dirPath, path string) bool {
+	if config == nil {
+		return false
+	}
+	rel, err := filepath.Rel(dirPath, path)
+	return err == nil && config.IsExcluded(rel)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lukeod/gosmi/diag"
+)
+
+// Diagnostic output formats accepted by -diag-format
+const (
+	diagFormatText = "text"
+	diagFormatJSON = "json"
+)
+
+// writeDiagnostics writes diagnostics one per line in text format, or as a
+// single JSON array (empty rather than null) in json format
+func writeDiagnostics(w io.Writer, format string, diagnostics []diag.Diagnostic) error {
+	if format == diagFormatJSON {
+		if diagnostics == nil {
+			diagnostics = []diag.Diagnostic{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diagnostics)
+	}
+	for _, d := range diagnostics {
+		if _, err := fmt.Fprintln(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
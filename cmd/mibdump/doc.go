@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+)
+
+// writeDoc loads a single MIB file with the fork and writes Markdown
+// documentation of its module to stdout
+func writeDoc(mibFilePath string) {
+	initFork()
+	defer gosmi.Exit()
+
+	gosmi.PrependPath(filepath.Dir(mibFilePath))
+	baseName := filepath.Base(mibFilePath)
+	name, err := gosmi.LoadModule(strings.TrimSuffix(baseName, filepath.Ext(baseName)))
+	if err != nil {
+		log.Fatalf("Error loading MIB %q: %v", mibFilePath, err)
+	}
+	module, err := gosmi.GetModule(name)
+	if err != nil {
+		log.Fatalf("Error loading MIB %q: %v", mibFilePath, err)
+	}
+	if err := gosmi.WriteMarkdown(os.Stdout, module); err != nil {
+		log.Fatalf("Error writing documentation: %v", err)
+	}
+}
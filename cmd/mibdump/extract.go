@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/parser"
+	smilexer "github.com/lukeod/gosmi/parser/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+	"github.com/lukeod/gosmi/types"
+)
+
+// extractModule writes to stdout a copy of the named module keeping only the
+// comma separated objects and the definitions they depend on, transitively,
+// within the module. The module is looked up in dirPath, the directory of
+// mibFilePath or else the default path.
+func extractModule(name, objects, mibFilePath, dirPath string) {
+	initFork()
+	defer gosmi.Exit()
+	if dirPath != "" {
+		gosmi.SetPath(dirPath)
+		if baseModules {
+			gosmi.LoadBaseModules()
+		}
+	} else if mibFilePath != "" {
+		gosmi.PrependPath(filepath.Dir(mibFilePath))
+	}
+	loaded, err := gosmi.LoadModule(name)
+	if err != nil {
+		log.Fatalf("Error loading module %q: %v", name, err)
+	}
+	module, err := gosmi.GetModule(loaded)
+	if err != nil {
+		log.Fatalf("Error loading module %q: %v", name, err)
+	}
+	src, err := os.ReadFile(module.Path)
+	if err != nil {
+		log.Fatalf("Error reading %q: %v", module.Path, err)
+	}
+	ast, err := parser.ParseBytes(module.Path, src)
+	if err != nil {
+		log.Fatalf("Error parsing %q: %v", module.Path, err)
+	}
+	var names []types.SmiIdentifier
+	for _, object := range strings.Split(objects, ",") {
+		if object = strings.TrimSpace(object); object != "" {
+			names = append(names, types.SmiIdentifier(object))
+		}
+	}
+	out, err := extract(module.Path, src, ast, names)
+	if err != nil {
+		log.Fatalf("Error extracting from %q: %v", module.Path, err)
+	}
+	os.Stdout.Write(out)
+}
+
+// definition is the source of a top level definition of a module
+type definition struct {
+	name  types.SmiIdentifier
+	start int
+	// comments are the comment lines leading to the definition
+	comments string
+	text     string
+	// refs are the identifiers the definition uses, keywords included
+	refs map[string]bool
+}
+
+// extract renders the module keeping the definitions of names, its
+// MODULE-IDENTITY and those they refer to, and the imports they use. The
+// definitions are copied from src as written, with their leading comments.
+func extract(path string, src []byte, module *parser.Module, names []types.SmiIdentifier) ([]byte, error) {
+	var defs []*definition
+	add := func(name types.SmiIdentifier, offset int) {
+		defs = append(defs, &definition{name: name, start: offset, refs: make(map[string]bool)})
+	}
+	body := &module.Body
+	if body.Identity != nil {
+		add(body.Identity.Name, body.Identity.Pos.Offset)
+	}
+	for _, t := range body.Types {
+		add(t.Name, t.Pos.Offset)
+	}
+	for _, n := range body.Nodes {
+		add(n.Name, n.Pos.Offset)
+	}
+	for _, m := range body.Macros {
+		add(m.Name, m.Pos.Offset)
+	}
+	for _, m := range body.Invocations {
+		add(m.Name, m.Pos.Offset)
+	}
+	for _, v := range body.Values {
+		add(v.Name, v.Pos.Offset)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].start < defs[j].start })
+
+	// The definitions span up to the next one, or the END of the module
+	end := -1
+	var idents []lexerToken
+	l := smilexer.NewLexerBytes(path, src)
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.EOF() {
+			break
+		}
+		if token.TokenType(tok.Type) == token.Ident {
+			idents = append(idents, lexerToken{value: tok.Value, offset: tok.Pos.Offset})
+			if tok.Value == "END" {
+				end = tok.Pos.Offset
+			}
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("Missing END of module %s", module.Name)
+	}
+	byName := make(map[types.SmiIdentifier]*definition, len(defs))
+	for i, def := range defs {
+		next := end
+		if i+1 < len(defs) {
+			next = defs[i+1].start
+		}
+		text, comments := splitTrailingComments(string(src[def.start:next]))
+		def.text = text
+		if i+1 < len(defs) {
+			defs[i+1].comments = comments
+		}
+		for _, ident := range idents {
+			if ident.offset >= def.start && ident.offset < next {
+				def.refs[ident.value] = true
+			}
+		}
+		if _, ok := byName[def.name]; !ok {
+			byName[def.name] = def
+		}
+	}
+
+	keep := make(map[*definition]bool)
+	var queue []*definition
+	if body.Identity != nil {
+		queue = append(queue, byName[body.Identity.Name])
+	}
+	for _, name := range names {
+		def, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not defined in module %s", name, module.Name)
+		}
+		queue = append(queue, def)
+	}
+	for len(queue) > 0 {
+		def := queue[0]
+		queue = queue[1:]
+		if keep[def] {
+			continue
+		}
+		keep[def] = true
+		for ref := range def.refs {
+			if dep, ok := byName[types.SmiIdentifier(ref)]; ok && !keep[dep] {
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for def := range keep {
+		for ref := range def.refs {
+			used[ref] = true
+		}
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s DEFINITIONS ::= BEGIN\n\n", module.Name)
+	var imports []string
+	for _, imp := range body.Imports {
+		var kept []string
+		for _, name := range imp.Names {
+			if used[string(name)] {
+				kept = append(kept, string(name))
+			}
+		}
+		if len(kept) > 0 {
+			imports = append(imports, fmt.Sprintf("    %s\n        FROM %s", strings.Join(kept, ", "), imp.Module))
+		}
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&buf, "IMPORTS\n%s;\n\n", strings.Join(imports, "\n"))
+	}
+	for _, def := range defs {
+		if keep[def] {
+			buf.WriteString(def.comments)
+			buf.WriteString(def.text)
+			buf.WriteString("\n\n")
+		}
+	}
+	buf.WriteString("END\n")
+	return buf.Bytes(), nil
+}
+
+type lexerToken struct {
+	value  string
+	offset int
+}
+
+// splitTrailingComments splits the comment and blank lines ending text off,
+// as they lead to what follows. Both parts are trimmed.
+func splitTrailingComments(text string) (body, comments string) {
+	lines := strings.Split(strings.TrimRight(text, " \t\r\n"), "\n")
+	i := len(lines)
+	for i > 1 {
+		line := strings.TrimSpace(lines[i-1])
+		if line != "" && !strings.HasPrefix(line, "--") {
+			break
+		}
+		i--
+	}
+	body = strings.Join(lines[:i], "\n")
+	comments = strings.TrimLeft(strings.Join(lines[i:], "\n"), "\r\n")
+	if comments != "" {
+		comments += "\n"
+	}
+	return body, comments
+}
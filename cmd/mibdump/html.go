@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukeod/gosmi"
+)
+
+// htmlReport is the data behind reportTemplate: one row per file, with timing
+// bars scaled to the slowest file, and a breakdown of the errors or
+// diagnostic codes seen.
+type htmlReport struct {
+	Title     string
+	Dir       string
+	Generated time.Time
+	Columns   []string // Names of the timing bars of each row
+	Rows      []htmlRow
+	Breakdown []htmlCount
+	Passed    int
+	Failed    int
+}
+
+type htmlRow struct {
+	File    string
+	OK      bool
+	Status  string
+	Details string // Diff or diagnostics, shown expanded on demand
+	Timings []htmlTiming
+}
+
+type htmlTiming struct {
+	Millis  int64
+	Percent float64
+}
+
+type htmlCount struct {
+	Name  string
+	Count int
+}
+
+// setTimings fills in the timings of the rows, scaling the bars to the
+// slowest duration seen in any column
+func (r *htmlReport) setTimings(durations [][]time.Duration) {
+	var max time.Duration
+	for _, row := range durations {
+		for _, d := range row {
+			if d > max {
+				max = d
+			}
+		}
+	}
+	for i, row := range durations {
+		for _, d := range row {
+			t := htmlTiming{Millis: d.Milliseconds()}
+			if max > 0 {
+				t.Percent = 100 * float64(d) / float64(max)
+			}
+			r.Rows[i].Timings = append(r.Rows[i].Timings, t)
+		}
+	}
+}
+
+// countBreakdown sorts counts by decreasing count, then name
+func countBreakdown(counts map[string]int) []htmlCount {
+	out := make([]htmlCount, 0, len(counts))
+	for name, count := range counts {
+		out = append(out, htmlCount{Name: name, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// errorCategory groups errors by their outermost context, e.g. "Parse file"
+// for "Parse file: unexpected token"
+func errorCategory(err error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, ": "); i > 0 {
+		return msg[:i]
+	}
+	return msg
+}
+
+// relativePath returns path relative to dir for display, or path unchanged
+func relativePath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && dir != "" {
+		return rel
+	}
+	return path
+}
+
+// newComparisonReport builds the report of a fork-vs-mainline directory
+// comparison
+func newComparisonReport(dirPath string, results []DirComparisonResult) *htmlReport {
+	r := &htmlReport{
+		Title:     "gosmi fork vs mainline",
+		Dir:       dirPath,
+		Generated: time.Now(),
+		Columns:   []string{"Fork", "Mainline"},
+	}
+	counts := make(map[string]int)
+	durations := make([][]time.Duration, 0, len(results))
+	for _, res := range results {
+		row := htmlRow{File: relativePath(dirPath, res.FilePath), OK: res.Same}
+		var details []string
+		switch {
+		case res.Same:
+			row.Status = "same"
+		case res.ForkError != nil || res.MainlineError != nil:
+			row.Status = "error"
+		default:
+			row.Status = "different"
+		}
+		if res.ForkError != nil {
+			counts["fork: "+errorCategory(res.ForkError)]++
+			details = append(details, "Fork error: "+res.ForkError.Error())
+		}
+		if res.MainlineError != nil {
+			counts["mainline: "+errorCategory(res.MainlineError)]++
+			details = append(details, "Mainline error: "+res.MainlineError.Error())
+		}
+		if res.Differences != nil && !res.Same {
+			if b, err := json.MarshalIndent(res.Differences, "", "  "); err == nil {
+				details = append(details, string(b))
+			}
+		}
+		row.Details = strings.Join(details, "\n\n")
+		if row.OK {
+			r.Passed++
+		} else {
+			r.Failed++
+		}
+		r.Rows = append(r.Rows, row)
+		durations = append(durations, []time.Duration{res.ForkDuration, res.MainlineDuration})
+	}
+	r.setTimings(durations)
+	r.Breakdown = countBreakdown(counts)
+	return r
+}
+
+// newLintReport builds the report of linting a file or directory, a file
+// passing if it parsed without errors
+func newLintReport(dirPath string, results []gosmi.FileReport) *htmlReport {
+	r := &htmlReport{
+		Title:     "gosmi lint",
+		Dir:       dirPath,
+		Generated: time.Now(),
+		Columns:   []string{"Lint"},
+	}
+	counts := make(map[string]int)
+	durations := make([][]time.Duration, 0, len(results))
+	for _, res := range results {
+		row := htmlRow{File: relativePath(dirPath, res.Path), OK: res.OK()}
+		lines := make([]string, len(res.Diagnostics))
+		for i, d := range res.Diagnostics {
+			counts[d.Code]++
+			lines[i] = d.String()
+		}
+		row.Details = strings.Join(lines, "\n")
+		switch {
+		case !row.OK:
+			row.Status = "error"
+			r.Failed++
+		case len(lines) > 0:
+			row.Status = "warnings"
+			r.Passed++
+		default:
+			row.Status = "clean"
+			r.Passed++
+		}
+		r.Rows = append(r.Rows, row)
+		durations = append(durations, []time.Duration{res.ParseDuration})
+	}
+	r.setTimings(durations)
+	r.Breakdown = countBreakdown(counts)
+	return r
+}
+
+func (r *htmlReport) write(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// writeHTMLReport writes r to the file at path
+func writeHTMLReport(path string, r *htmlReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.3em 0.8em; border-bottom: 1px solid #ddd; vertical-align: top; }
+.same, .clean { color: #1a7f37; }
+.warnings { color: #9a6700; }
+.different, .error { color: #cf222e; }
+.bar { background: #0969da; height: 0.8em; min-width: 1px; }
+.chart { width: 12em; }
+pre { white-space: pre-wrap; font-size: 0.85em; max-width: 80em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{if .Dir}}{{.Dir}}, {{end}}generated {{.Generated.Format "2006-01-02 15:04:05 MST"}}: {{.Passed}} passed, {{.Failed}} failed.</p>
+{{if .Breakdown}}
+<h2>Breakdown</h2>
+<table>
+<tr><th>Error or code</th><th>Count</th></tr>
+{{range .Breakdown}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Status</th>{{range .Columns}}<th>{{.}} (ms)</th>{{end}}</tr>
+{{range .Rows}}<tr>
+<td>{{if .Details}}<details><summary>{{.File}}</summary><pre>{{.Details}}</pre></details>{{else}}{{.File}}{{end}}</td>
+<td class="{{.Status}}">{{.Status}}</td>
+{{range .Timings}}<td class="chart"><div class="bar" style="width: {{printf "%.1f" .Percent}}%"></div>{{.Millis}}</td>{{end}}
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
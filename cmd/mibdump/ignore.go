@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ignoreWhitespace is the -ignore name which compares the text fields by
+// their words, telling apart only changes other than of whitespace.
+const ignoreWhitespace = "whitespace"
+
+// diffFields are the fields -ignore accepts, beside ignoreWhitespace. Kind,
+// Enum and Range stand for the kindDiff, enumDiff and rangeDiff of nodes and
+// types, Imports and Revisions for the imports and revisions of modules.
+var diffFields = []string{
+	"Access", "BaseType", "ContactInfo", "Decl", "Description", "Enum", "Format", "Imports", "Kind",
+	"Language", "Name", "Oid", "Organization", "Range", "Reference", "Revisions", "Status", "Units",
+}
+
+// textFields are the fields compared by their words with -ignore whitespace.
+var textFields = map[string]bool{"ContactInfo": true, "Description": true, "Organization": true, "Reference": true}
+
+// ignoredFields holds the lower case names given to -ignore.
+var ignoredFields = map[string]bool{}
+
+// setIgnoredFields sets the fields of the comma separated list to be
+// ignored by comparisons, matching their names case insensitively.
+func setIgnoredFields(list string) error {
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name != ignoreWhitespace && !isDiffField(name) {
+			return fmt.Errorf("Unknown field %q, must be %s or %s", name, strings.Join(diffFields, ", "), ignoreWhitespace)
+		}
+		ignoredFields[name] = true
+	}
+	return nil
+}
+
+func isDiffField(name string) bool {
+	for _, field := range diffFields {
+		if strings.ToLower(field) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored tells whether the field is ignored by comparisons.
+func isIgnored(field string) bool {
+	return ignoredFields[strings.ToLower(field)]
+}
+
+// sameField tells whether the values of the field compare equal, which
+// ignored fields always do.
+func sameField(field, fork, mainline string) bool {
+	if fork == mainline || isIgnored(field) {
+		return true
+	}
+	return ignoredFields[ignoreWhitespace] && textFields[field] && sameWords(fork, mainline)
+}
+
+// sameWords tells whether the texts differ in whitespace only.
+func sameWords(a, b string) bool {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa) != len(wb) {
+		return false
+	}
+	for i := range wa {
+		if wa[i] != wb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// appendDiff appends the difference of the field to diffs unless the values
+// are the same by sameField.
+func appendDiff(diffs []ModuleInfoDifference, field, fork, mainline string) []ModuleInfoDifference {
+	if sameField(field, fork, mainline) {
+		return diffs
+	}
+	return append(diffs, ModuleInfoDifference{FieldName: field, Diff: ValuePair{Fork: fork, Mainline: mainline}})
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/lukeod/gosmi"
+)
+
+// writeIndex writes the index of the MIB files of dirPath, which later runs
+// compiling the directory use instead of walking it
+func writeIndex(dirPath string) {
+	idx, err := gosmi.WriteDirIndex(dirPath)
+	if err != nil {
+		log.Fatalf("Error writing index of %q: %v", dirPath, err)
+	}
+	log.Printf("Indexed %d files in %s.", len(idx.Files), filepath.Join(dirPath, gosmi.IndexFileName))
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/lukeod/gosmi"
+)
+
+// dumpInventory loads a single MIB file or compiles a directory with the fork
+// and writes the nodes of every loaded module to stdout as CSV
+func dumpInventory(mibFilePath, dirPath string, workers int) {
+	initFork()
+	defer gosmi.Exit()
+	loadFork(mibFilePath, dirPath, workers)
+
+	entries, err := gosmi.GetInventory()
+	if err != nil {
+		log.Fatalf("Error building inventory: %v", err)
+	}
+	log.Printf("Found %d nodes.", len(entries))
+	if err := gosmi.WriteInventoryCSV(os.Stdout, entries); err != nil {
+		log.Fatalf("Error writing inventory: %v", err)
+	}
+}
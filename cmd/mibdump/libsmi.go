@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// refNode is a node as libsmi describes it in smidump -f xml output, which is
+// also what the fork's nodes are converted to for comparison. Empty fields
+// are not compared.
+type refNode struct {
+	Name   string
+	Oid    string
+	Kind   string
+	Status string
+	Access string
+	Type   string
+}
+
+// libsmiComparison counts how the fork's nodes of a module diverge from
+// libsmi's
+type libsmiComparison struct {
+	Matched    int
+	Different  []string // One line per differing field
+	OnlyGosmi  []string
+	OnlyLibsmi []string
+	Differing  int
+}
+
+// Divergence is the share of nodes, from 0 to 1, that are missing on either
+// side or differ
+func (c libsmiComparison) Divergence() float64 {
+	total := c.Matched + c.Differing + len(c.OnlyGosmi) + len(c.OnlyLibsmi)
+	if total == 0 {
+		return 0
+	}
+	return float64(total-c.Matched) / float64(total)
+}
+
+// smidumpNodeKinds are the smidump -f xml elements describing nodes
+var smidumpNodeKinds = map[string]bool{
+	"node": true, "scalar": true, "table": true, "row": true, "column": true,
+	"notification": true, "group": true, "compliance": true, "capabilities": true,
+}
+
+// parseSmidumpXML reads the nodes of the module in smidump -f xml output
+func parseSmidumpXML(r io.Reader) (map[string]refNode, error) {
+	nodes := make(map[string]refNode)
+	dec := xml.NewDecoder(r)
+	var stack []*refNode // Node elements being read, nil for others
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Parse smidump output: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			text.Reset()
+			var node *refNode
+			if smidumpNodeKinds[t.Name.Local] {
+				node = &refNode{Kind: t.Name.Local}
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "name":
+						node.Name = attr.Value
+					case "oid":
+						node.Oid = attr.Value
+					case "status":
+						node.Status = attr.Value
+					}
+				}
+			} else if n := len(stack); t.Name.Local == "type" && n >= 2 && stack[n-1] == nil && stack[n-2] != nil {
+				// <syntax><type module="..." name="..."/></syntax>
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "name" {
+						stack[n-2].Type = attr.Value
+					}
+				}
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			switch {
+			case node != nil && node.Name != "":
+				nodes[node.Name] = *node
+			case t.Name.Local == "access" && len(stack) > 0 && stack[len(stack)-1] != nil:
+				stack[len(stack)-1].Access = strings.TrimSpace(text.String())
+			}
+		}
+	}
+}
+
+// runSmidump runs smidump on a MIB file, with its directory in front of the
+// libsmi search path, and returns the xml output
+func runSmidump(smidump, mibFilePath string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(smidump, "-k", "-f", "xml", mibFilePath)
+	cmd.Env = append(os.Environ(), "SMIPATH="+filepath.Dir(mibFilePath)+string(os.PathListSeparator)+os.Getenv("SMIPATH"))
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		return nil, fmt.Errorf("Run smidump: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// libsmiAccess maps the fork's access values to smidump's
+var libsmiAccess = map[types.Access]string{
+	types.AccessNotImplemented: "not-implemented",
+	types.AccessNotAccessible:  "noaccess",
+	types.AccessNotify:         "notifyonly",
+	types.AccessReadOnly:       "readonly",
+	types.AccessReadWrite:      "readwrite",
+	// libsmi has no write-only access and reads it as read-write
+	types.AccessWriteOnly: "readwrite",
+}
+
+// forkNodes loads a MIB file with the fork and converts the nodes of its
+// module for comparison with libsmi
+func forkNodes(mibFilePath string) (map[string]refNode, error) {
+	initFork()
+	defer gosmi.Exit()
+	gosmi.PrependPath(filepath.Dir(mibFilePath))
+	baseName := filepath.Base(mibFilePath)
+	name, err := gosmi.LoadModule(strings.TrimSuffix(baseName, filepath.Ext(baseName)))
+	if err != nil {
+		return nil, err
+	}
+	module, err := gosmi.GetModule(name)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]refNode)
+	for _, n := range module.GetNodes() {
+		node := refNode{
+			Name:   n.Name,
+			Oid:    n.Oid.String(),
+			Kind:   strings.ToLower(n.Kind.String()),
+			Access: libsmiAccess[n.Access],
+		}
+		if n.Status != types.StatusUnknown {
+			node.Status = strings.ToLower(n.Status.String())
+		}
+		if n.Type != nil {
+			node.Type = n.Type.Name
+		}
+		nodes[n.Name] = node
+	}
+	return nodes, nil
+}
+
+// compareWithLibsmi compares the fork's nodes with libsmi's, field by field
+// where both sides have a value
+func compareWithLibsmi(fork, libsmi map[string]refNode) libsmiComparison {
+	var c libsmiComparison
+	for name, ours := range fork {
+		theirs, ok := libsmi[name]
+		if !ok {
+			c.OnlyGosmi = append(c.OnlyGosmi, name)
+			continue
+		}
+		fields := []struct{ field, ours, theirs string }{
+			{"oid", ours.Oid, theirs.Oid},
+			{"kind", ours.Kind, theirs.Kind},
+			{"status", ours.Status, theirs.Status},
+			{"access", ours.Access, theirs.Access},
+			{"type", ours.Type, theirs.Type},
+		}
+		same := true
+		for _, f := range fields {
+			if f.ours != "" && f.theirs != "" && f.ours != f.theirs {
+				c.Different = append(c.Different, fmt.Sprintf("%s %s: gosmi %q, libsmi %q", name, f.field, f.ours, f.theirs))
+				same = false
+			}
+		}
+		if same {
+			c.Matched++
+		} else {
+			c.Differing++
+		}
+	}
+	for name := range libsmi {
+		if _, ok := fork[name]; !ok {
+			c.OnlyLibsmi = append(c.OnlyLibsmi, name)
+		}
+	}
+	sort.Strings(c.Different)
+	sort.Strings(c.OnlyGosmi)
+	sort.Strings(c.OnlyLibsmi)
+	return c
+}
+
+// compareFileWithLibsmi compares one MIB file, reading libsmi's view from
+// reference, either an smidump binary or a file of its -f xml output
+func compareFileWithLibsmi(mibFilePath, reference string) (libsmiComparison, error) {
+	var out []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(reference), ".xml") {
+		out, err = os.ReadFile(reference)
+	} else {
+		out, err = runSmidump(reference, mibFilePath)
+	}
+	if err != nil {
+		return libsmiComparison{}, err
+	}
+	libsmi, err := parseSmidumpXML(bytes.NewReader(out))
+	if err != nil {
+		return libsmiComparison{}, err
+	}
+	fork, err := forkNodes(mibFilePath)
+	if err != nil {
+		return libsmiComparison{}, fmt.Errorf("Load with gosmi: %w", err)
+	}
+	return compareWithLibsmi(fork, libsmi), nil
+}
+
+// compareLibsmi compares a single MIB file or every MIB file under a
+// directory with libsmi and prints how far the fork diverges
+func compareLibsmi(mibFilePath, dirPath, reference string) {
+	if mibFilePath != "" {
+		c, err := compareFileWithLibsmi(mibFilePath, reference)
+		if err != nil {
+			log.Fatalf("Error comparing %q with libsmi: %v", mibFilePath, err)
+		}
+		fmt.Printf("Matched %d nodes, %d differ, %d only in gosmi, %d only in libsmi: %.1f%% divergence\n",
+			c.Matched, c.Differing, len(c.OnlyGosmi), len(c.OnlyLibsmi), 100*c.Divergence())
+		for _, line := range c.Different {
+			fmt.Println("  ", line)
+		}
+		for _, name := range c.OnlyGosmi {
+			fmt.Println("   only in gosmi:", name)
+		}
+		for _, name := range c.OnlyLibsmi {
+			fmt.Println("   only in libsmi:", name)
+		}
+		return
+	}
+
+	if strings.EqualFold(filepath.Ext(reference), ".xml") {
+		log.Fatal("Error: -libsmi needs an smidump binary rather than its output with -dir")
+	}
+	paths, err := findMibFiles(dirPath)
+	if err != nil {
+		log.Fatalf("Error walking directory %q: %v", dirPath, err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "File\tMatched\tDiffer\tOnly gosmi\tOnly libsmi\tDivergence\tError")
+	fmt.Fprintln(w, "----\t-------\t------\t----------\t-----------\t----------\t-----")
+	var total libsmiComparison
+	for _, path := range paths {
+		c, err := compareFileWithLibsmi(path, reference)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+			if len(errStr) > 50 {
+				errStr = errStr[:47] + "..."
+			}
+		}
+		total.Matched += c.Matched
+		total.Differing += c.Differing
+		total.OnlyGosmi = append(total.OnlyGosmi, c.OnlyGosmi...)
+		total.OnlyLibsmi = append(total.OnlyLibsmi, c.OnlyLibsmi...)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t%s\n", relativePath(dirPath, path),
+			c.Matched, c.Differing, len(c.OnlyGosmi), len(c.OnlyLibsmi), 100*c.Divergence(), errStr)
+	}
+	w.Flush()
+	fmt.Printf("\nOverall: %d nodes matched, %.1f%% divergence from libsmi\n", total.Matched, 100*total.Divergence())
+}
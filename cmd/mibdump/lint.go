@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+)
+
+// lintPaths parses and lints each file, in strict mode if strict is set,
+// reporting the time taken by both as the parse duration
+func lintPaths(paths []string, strict bool) []gosmi.FileReport {
+	results := make([]gosmi.FileReport, 0, len(paths))
+	for _, path := range paths {
+		start := time.Now()
+		var collector diag.Collector
+		opts := []parser.Option{parser.WithDiagnostics(&collector)}
+		if strict {
+			opts = append(opts, parser.WithStrict())
+		}
+		module, err := parser.ParseFile(path, opts...)
+		res := gosmi.FileReport{Path: path, Diagnostics: collector.Diagnostics()}
+		if err == nil {
+			res.Module = module.Name.String()
+			res.Diagnostics = append(res.Diagnostics, lint.Check(path, module)...)
+		}
+		res.ParseDuration = time.Since(start)
+		results = append(results, res)
+	}
+	return results
+}
+
+// findMibFiles returns the potential MIB files under dirPath: those with a
+// .mib or .txt extension or none that look like MIB modules and are not
+// excluded by the config
+func findMibFiles(dirPath string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.IsDir() && (ext == ".mib" || ext == ".txt" || ext == "") && !excludedByConfig(dirPath, path) && looksLikeMib(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// looksLikeMib reports whether the file at path looks like a MIB module,
+// assuming it does if it cannot be read so that parsing it reports the error
+func looksLikeMib(path string) bool {
+	ok, err := parser.SniffFile(path)
+	return ok || err != nil
+}
+
+// lintFiles parses a single MIB file or every MIB file under a directory,
+// rejecting non-conformant constructs if strict is set, and writes the
+// findings of the lint rules to stdout, as diagnostics or in reportFormat if
+// set, and to an HTML report if htmlPath is set
+func lintFiles(mibFilePath, dirPath string, strict bool, diagFormat, reportFormat, htmlPath string) {
+	paths := []string{mibFilePath}
+	if dirPath != "" {
+		var err error
+		if paths, err = findMibFiles(dirPath); err != nil {
+			log.Fatalf("Error walking directory %q: %v", dirPath, err)
+		}
+	}
+
+	results := lintPaths(paths, strict)
+	var diagnostics []diag.Diagnostic
+	for _, res := range results {
+		diagnostics = append(diagnostics, res.Diagnostics...)
+	}
+	log.Printf("Linted %d files, %d findings.", len(paths), len(diagnostics))
+	if htmlPath != "" {
+		if err := writeHTMLReport(htmlPath, newLintReport(dirPath, results)); err != nil {
+			log.Fatalf("Error writing HTML report: %v", err)
+		}
+		log.Printf("Wrote HTML report to %s", htmlPath)
+	}
+	if reportFormat != "" {
+		if err := writeReport(os.Stdout, reportFormat, dirPath, results); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
+	}
+	if err := writeDiagnostics(os.Stdout, diagFormat, diagnostics); err != nil {
+		log.Fatalf("Error writing diagnostics: %v", err)
+	}
+}
@@ -3,8 +3,19 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
 )
 
+// baseModules is set unless the embedded base modules are disabled with
+// -base=false.
+var baseModules bool
+
+// netSNMPEnv is set by -netsnmp to search MIBDIRS and load MIBS.
+var netSNMPEnv bool
+
 func main() {
 	log.SetFlags(0) // Disable log prefixes
 
@@ -13,7 +24,77 @@ func main() {
 	mibDirPath := flag.String("dir", "", "Path to the directory of MIB files to process recursively (mutually exclusive with -mibfile)")
 	outputType := flag.String("output", "all", "Type of output for single file mode: ast, resolved, or all (default)")
 	dumpOutput := flag.Bool("dump", false, "Dump the full JSON output instead of a diff summary (single file mode only)")
+	compileOnly := flag.Bool("compile", false, "Compile the directory with the fork only instead of comparing against mainline (dir mode only)")
+	flag.BoolVar(&baseModules, "base", true, "Fall back to the embedded SNMPv2-SMI, SNMPv2-TC, SNMPv2-CONF, RFC1155-SMI, RFC-1212 and RFC-1215 for modules not found next to the MIBs")
+	flag.BoolVar(&netSNMPEnv, "netsnmp", false, "Search the directories of MIBDIRS first and load the modules of MIBS, as net-snmp tools do")
+	flag.IntVar(&maxExamplesPerCategory, "examples", maxExamplesPerCategory, "Number of added, removed and modified nodes and types to list per category in comparison results, 0 for all")
+	flag.BoolVar(&diffStream, "diff-stream", false, "Write every difference of the comparison to stdout as a line of JSON, with no limit on examples, instead of the summary")
+	ignore := flag.String("ignore", "", "Comma separated fields to leave out of comparisons, such as Description,Reference, or whitespace to compare descriptions, references and contact info by their words")
+	workers := flag.Int("workers", 0, "Number of files to parse concurrently in -dir mode (default: number of CPUs)")
+	diagFormat := flag.String("diag-format", diagFormatText, "Format of diagnostics output: text or json (single file and -compile modes). With json, stdout holds only the diagnostics")
+	trapFormat := flag.String("traps", "", "List the notifications of all loaded modules as csv or json instead of comparing")
+	statsFormat := flag.String("stats", "", "Print statistics of all loaded modules as text or json instead of comparing")
+	templatePath := flag.String("template", "", "Render this text/template file with the loaded modules instead of comparing")
+	doc := flag.Bool("doc", false, "Write Markdown documentation of the module of -mibfile instead of comparing")
+	inventory := flag.Bool("inventory", false, "Write the nodes of all loaded modules as CSV instead of comparing")
+	lintOnly := flag.Bool("lint", false, "Report unused, missing and misplaced imports and other lint findings instead of comparing")
+	strict := flag.Bool("strict", false, "With -lint, also reject trailing commas and other constructs SMI does not allow, which are accepted by default")
+	profile := flag.Int("profile", 0, "Load -mibfile or compile -dir and print this many of the slowest modules with the time of their lex, parse and resolve phases instead of comparing")
+	bench := flag.Int("bench", 0, "Compile -dir this many times, each into a fresh handle, and report the time taken instead of comparing")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile of the -bench rounds to this file")
+	memProfile := flag.String("memprofile", "", "Write a profile of the memory allocated by the -bench rounds to this file")
+	libsmiRef := flag.String("libsmi", "", "Compare the fork's nodes with libsmi, running this smidump binary or reading its -f xml output from this .xml file")
+	reportFormat := flag.String("report", "", "Write the -lint or -compile results to stdout as junit or sarif instead of diagnostics")
+	htmlPath := flag.String("html", "", "Also write an HTML report of the directory comparison or -lint results to this file")
+	oidFormatName := flag.String("oid-format", "numeric", "Format of OIDs in -traps output: numeric, full, suffix or module, or net-snmp's -O letters n, f, s or S")
+	newModule := flag.String("new-module", "", "Write a new SMIv2 module skeleton of this name to stdout instead of loading MIBs")
+	organization := flag.String("organization", "", "ORGANIZATION of the -new-module skeleton")
+	contact := flag.String("contact", "", "CONTACT-INFO of the -new-module skeleton")
+	root := flag.String("root", "enterprises.99999", "OID of the -new-module skeleton, as a node of SNMPv2-SMI followed by numbers")
+	extractName := flag.String("extract", "", "Write a trimmed copy of this module, found in -dir or next to -mibfile, keeping only -objects and what they depend on")
+	objects := flag.String("objects", "", "Comma separated definitions to keep with -extract, such as ifTable,ifXTable")
+	shellMode := flag.Bool("shell", false, "Read commands such as load, translate, tree and search from stdin, keeping modules loaded between them")
+	configPath := flag.String("config", "", "Read search paths, module aliases, strictness, excluded files and output defaults from this gosmi.yaml or gosmi.toml file (default: the closest gosmi.yaml, gosmi.yml or gosmi.toml up from the working directory)")
+	completion := flag.String("completion", "", "Write the completion script for this shell, bash, zsh or fish, to stdout")
+	complete := flag.String("complete", "", "List the modules, or the objects of the module given as argument, recorded by -index-names for the completion scripts")
+	writeIndexOnly := flag.Bool("write-index", false, "Write the index of the MIB files of -dir, module, path and hash, which compiling the directory then uses instead of walking it, rewriting it when out of date")
+	indexNamesOnly := flag.Bool("index-names", false, "Record the names of the loaded modules and their definitions for completion, in $"+namesIndexEnv+" or the user cache directory")
 	flag.Parse()
+	loadConfig(*configPath)
+
+	if *newModule != "" {
+		writeSkeleton(*newModule, *organization, *contact, *root)
+		return
+	}
+
+	if *completion != "" {
+		if err := writeCompletion(os.Stdout, *completion); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	if *complete != "" {
+		completeNames(os.Stdout, *complete, flag.Arg(0))
+		return
+	}
+
+	if *shellMode {
+		oidFormat, err := types.OidFormatFromString(*oidFormatName)
+		if err != nil {
+			log.Fatalf("Error: invalid -oid-format %q. Must be 'numeric', 'full', 'suffix' or 'module'", *oidFormatName)
+		}
+		runShell(*mibFilePath, *mibDirPath, *workers, oidFormat, os.Stdin, os.Stdout)
+		return
+	}
+
+	if *extractName != "" {
+		if *objects == "" {
+			log.Fatal("Error: -extract requires -objects")
+		}
+		extractModule(*extractName, *objects, *mibFilePath, *mibDirPath)
+		return
+	}
 
 	// --- Validate Flags ---
 	if (*mibFilePath == "" && *mibDirPath == "") || (*mibFilePath != "" && *mibDirPath != "") {
@@ -27,16 +108,111 @@ func main() {
 		*dumpOutput = false // Ensure dump is off for dir mode summary
 	}
 
+	if diffStream {
+		if *dumpOutput || *outputType == "ast" {
+			log.Fatal("Error: -diff-stream compares resolved modules and cannot be used with -dump or -output ast")
+		}
+		maxExamplesPerCategory = 0
+	}
+
+	if err := setIgnoredFields(*ignore); err != nil {
+		log.Fatalf("Error: invalid -ignore: %v", err)
+	}
+
+	if *diagFormat != diagFormatText && *diagFormat != diagFormatJSON {
+		log.Fatalf("Error: invalid -diag-format %q. Must be 'text' or 'json'", *diagFormat)
+	}
+
+	if *trapFormat != "" && *trapFormat != trapFormatCSV && *trapFormat != trapFormatJSON {
+		log.Fatalf("Error: invalid -traps format %q. Must be 'csv' or 'json'", *trapFormat)
+	}
+
+	if *writeIndexOnly && *mibDirPath == "" {
+		log.Fatal("Error: -write-index requires -dir")
+	}
+
+	if *bench > 0 && *mibDirPath == "" {
+		log.Fatal("Error: -bench requires -dir")
+	}
+
+	if (*cpuProfile != "" || *memProfile != "") && *bench == 0 {
+		log.Fatal("Error: -cpuprofile and -memprofile require -bench")
+	}
+
+	if *doc && *mibFilePath == "" {
+		log.Fatal("Error: -doc requires -mibfile")
+	}
+
+	if *statsFormat != "" && *statsFormat != statsFormatText && *statsFormat != statsFormatJSON {
+		log.Fatalf("Error: invalid -stats format %q. Must be 'text' or 'json'", *statsFormat)
+	}
+
+	oidFormat, err := types.OidFormatFromString(*oidFormatName)
+	if err != nil {
+		log.Fatalf("Error: invalid -oid-format %q. Must be 'numeric', 'full', 'suffix' or 'module'", *oidFormatName)
+	}
+
+	if *reportFormat != "" {
+		if *reportFormat != reportFormatJUnit && *reportFormat != reportFormatSARIF {
+			log.Fatalf("Error: invalid -report format %q. Must be 'junit' or 'sarif'", *reportFormat)
+		}
+		if !*lintOnly && !*compileOnly {
+			log.Fatal("Error: -report requires -lint or -compile")
+		}
+	}
+
 	// --- Dispatch to Processing Functions ---
-	if *mibFilePath != "" {
+	if *writeIndexOnly {
+		writeIndex(*mibDirPath)
+	} else if *indexNamesOnly {
+		indexNames(*mibFilePath, *mibDirPath, *workers)
+	} else if *libsmiRef != "" {
+		compareLibsmi(*mibFilePath, *mibDirPath, *libsmiRef)
+	} else if *bench > 0 {
+		benchCompile(*mibDirPath, *workers, *bench, *cpuProfile, *memProfile)
+	} else if *profile > 0 {
+		profileLoad(*mibFilePath, *mibDirPath, *workers, *profile)
+	} else if *lintOnly {
+		lintFiles(*mibFilePath, *mibDirPath, *strict, *diagFormat, *reportFormat, *htmlPath)
+	} else if *templatePath != "" {
+		exportTemplate(*mibFilePath, *mibDirPath, *workers, *templatePath)
+	} else if *doc {
+		writeDoc(*mibFilePath)
+	} else if *inventory {
+		dumpInventory(*mibFilePath, *mibDirPath, *workers)
+	} else if *statsFormat != "" {
+		dumpStats(*mibFilePath, *mibDirPath, *workers, *statsFormat)
+	} else if *trapFormat != "" {
+		dumpTrapCatalog(*mibFilePath, *mibDirPath, *workers, *trapFormat, oidFormat)
+	} else if *mibFilePath != "" {
 		// Validate output type for single file mode
 		if *outputType != "ast" && *outputType != "resolved" && *outputType != "all" {
 			log.Fatalf("Error: invalid -output type %q for single file mode. Must be 'ast', 'resolved', or 'all'", *outputType)
 		}
 		// Call the processing function (now in process.go)
-		processSingleMibFile(*mibFilePath, *outputType, *dumpOutput)
+		processSingleMibFile(*mibFilePath, *outputType, *dumpOutput, *diagFormat)
 	} else {
 		// Call the directory processing function (now in process.go)
-		processDirectory(*mibDirPath)
+		processDirectory(*mibDirPath, *compileOnly, *workers, *diagFormat, *reportFormat, *htmlPath)
+	}
+}
+
+// initFork initializes the fork with the embedded base modules at the end of
+// the search path, unless disabled, the config, if any, and the net-snmp
+// environment with -netsnmp.
+func initFork() {
+	gosmi.Init()
+	if baseModules {
+		gosmi.LoadBaseModules()
+	}
+	if config != nil {
+		if err := config.Apply(); err != nil {
+			log.Fatalf("Error applying config: %v", err)
+		}
+	}
+	if netSNMPEnv {
+		if err := gosmi.UseNetSNMPEnv(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
 	}
 }
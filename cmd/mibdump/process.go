@@ -1,20 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors" // Added for panic recovery
 	"fmt"
-	"io/fs"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
 	"runtime/debug" // Added for panic recovery stack trace
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser"
 	mainline_gosmi "github.com/sleepinggenius2/gosmi"
 	mainline_parser "github.com/sleepinggenius2/gosmi/parser"
@@ -24,17 +28,27 @@ import (
 
 // processSingleMibFile handles the original logic for a single MIB file
 // Note: Parameters are now values, not pointers
-func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
+func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool, diagFormat string) {
 	log.Printf("Processing single MIB file: %s\n", mibFilePath)
 
+	// With JSON diagnostics, stdout holds nothing but the diagnostics
+	// document, so everything else goes to stderr
+	out := io.Writer(os.Stdout)
+	if diagFormat == diagFormatJSON {
+		out = os.Stderr
+	}
+
 	// --- Process with Fork (lukeod/gosmi) ---
 	log.Println("--- Processing with Fork (lukeod/gosmi) ---")
 	var forkAstModule *parser.Module
 	var forkParseErr error
-	// Use outputType directly (no *)
-	if outputType == "ast" || outputType == "all" {
-		log.Printf("[Fork] Parsing AST from %s...", mibFilePath)    // Use mibFilePath directly
-		forkAstModule, forkParseErr = parser.ParseFile(mibFilePath) // Use mibFilePath directly
+	var forkDiagnostics []diag.Diagnostic
+	// With "all", the AST is taken from the resolved module below instead
+	if outputType == "ast" {
+		log.Printf("[Fork] Parsing AST from %s...", mibFilePath) // Use mibFilePath directly
+		var astDiagnostics diag.Collector
+		forkAstModule, forkParseErr = parser.ParseFile(mibFilePath, parser.WithDiagnostics(&astDiagnostics))
+		forkDiagnostics = astDiagnostics.Diagnostics()
 		if forkParseErr != nil {
 			log.Printf("[Fork] Error parsing AST: %v", forkParseErr)
 		} else {
@@ -50,33 +64,24 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 	// Use outputType directly (no *)
 	if outputType == "resolved" || outputType == "all" {
 		log.Printf("[Fork] Loading and resolving MIB %s...", mibFilePath) // Use mibFilePath directly
-		gosmi.Init()                                                      // Initialize fork gosmi
+		initFork()                                                        // Initialize fork gosmi
 		defer gosmi.Exit()                                                // Ensure fork exits eventually
 
 		dir := filepath.Dir(mibFilePath) // Use mibFilePath directly
 		gosmi.PrependPath(dir)
 
-		// Simplified dependency loading (assuming they are in the same dir)
-		deps := []string{"SNMPv2-SMI", "SNMPv2-TC", "SNMPv2-CONF", "RFC1155-SMI", "RFC1213-MIB"} // Common base MIBs
-		for _, dep := range deps {
-			depPath := filepath.Join(dir, dep+".mib") // Assuming .mib extension, adjust if needed
-			// Check if file exists before trying to load
-			if _, statErr := os.Stat(depPath); statErr == nil {
-				log.Printf("[Fork] Pre-loading dependency: %s", dep)
-				_, loadErr := gosmi.LoadModule(dep)
-				// Use the trackDependency function (assuming it's defined in dependencies.go)
-				trackDependency(&forkDependencies, dep, depPath, loadErr == nil, loadErr)
-			} else if !os.IsNotExist(statErr) {
-				// Log other errors like permission issues
-				log.Printf("[Fork] Error checking dependency file %s: %v", depPath, statErr)
-			}
-		}
-
 		baseName := filepath.Base(mibFilePath) // Use mibFilePath directly
 		moduleNameFromName := strings.TrimSuffix(baseName, filepath.Ext(baseName))
 
 		log.Printf("[Fork] Attempting to load target MIB by name: %s", moduleNameFromName)
-		_, loadErr := gosmi.LoadModule(moduleNameFromName) // Load by name
+		_, loadErr := gosmi.LoadModule(moduleNameFromName) // Load by name, dependencies are loaded from IMPORTS
+		forkDiagnostics = gosmi.GetDiagnostics()
+		// Track every other module loaded along the way as a dependency
+		for _, dep := range gosmi.GetLoadedModules() {
+			if dep.Name != moduleNameFromName {
+				trackDependency(&forkDependencies, dep.Name, dep.Path, true, nil)
+			}
+		}
 		if loadErr != nil {
 			log.Printf("[Fork] Error loading/resolving target MIB %q: %v", moduleNameFromName, loadErr)
 			forkLoadErr = loadErr
@@ -96,15 +101,28 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 				forkResolvedMap["types"] = forkResolvedModule.GetTypes()
 				forkResolvedMap["imports"] = forkResolvedModule.GetImports()     // Assuming models.Import
 				forkResolvedMap["revisions"] = forkResolvedModule.GetRevisions() // Assuming models.Revision
-				identityNode, ok := forkResolvedModule.GetIdentityNode()
+				identity, ok := forkResolvedModule.GetIdentity()
 				if ok {
-					forkResolvedMap["identityNode"] = identityNode
+					forkResolvedMap["identity"] = identity
 				}
 			}
 		}
 		// gosmi.Exit() is deferred
 	}
 
+	if outputType == "all" {
+		if forkAstModule = forkResolvedModule.AST(); forkAstModule != nil {
+			log.Println("[Fork] AST taken from the resolved module.")
+		} else {
+			// Loading failed, parse on its own for the AST or its error
+			log.Printf("[Fork] Parsing AST from %s...", mibFilePath)
+			forkAstModule, forkParseErr = parser.ParseFile(mibFilePath)
+			if forkParseErr != nil {
+				log.Printf("[Fork] Error parsing AST: %v", forkParseErr)
+			}
+		}
+	}
+
 	// --- Process with Mainline (sleepinggenius2/gosmi) ---
 	log.Println("--- Processing with Mainline (sleepinggenius2/gosmi) ---")
 	var mainlineAstModule *mainline_parser.Module
@@ -134,24 +152,16 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 		dir := filepath.Dir(mibFilePath) // Use mibFilePath directly
 		mainline_gosmi.PrependPath(dir)
 
-		// Simplified dependency loading
-		deps := []string{"SNMPv2-SMI", "SNMPv2-TC", "SNMPv2-CONF", "RFC1155-SMI", "RFC1213-MIB"}
-		for _, dep := range deps {
-			depPath := filepath.Join(dir, dep+".mib") // Assuming .mib extension
-			if _, statErr := os.Stat(depPath); statErr == nil {
-				log.Printf("[Mainline] Pre-loading dependency: %s", dep)
-				_, loadErr := mainline_gosmi.LoadModule(dep)
-				trackDependency(&mainlineDependencies, dep, depPath, loadErr == nil, loadErr)
-			} else if !os.IsNotExist(statErr) {
-				log.Printf("[Mainline] Error checking dependency file %s: %v", depPath, statErr)
-			}
-		}
-
 		baseName := filepath.Base(mibFilePath) // Use mibFilePath directly
 		moduleNameFromName := strings.TrimSuffix(baseName, filepath.Ext(baseName))
 
 		log.Printf("[Mainline] Attempting to load target MIB by name: %s", moduleNameFromName)
 		_, loadErr := mainline_gosmi.LoadModule(moduleNameFromName)
+		for _, dep := range mainline_gosmi.GetLoadedModules() {
+			if dep.Name != moduleNameFromName {
+				trackDependency(&mainlineDependencies, dep.Name, dep.Path, true, nil)
+			}
+		}
 		if loadErr != nil {
 			log.Printf("[Mainline] Error loading/resolving target MIB %q: %v", moduleNameFromName, loadErr)
 			mainlineLoadErr = loadErr
@@ -245,7 +255,7 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 		if err != nil {
 			log.Fatalf("Error marshalling output to JSON: %v", err)
 		}
-		fmt.Println(string(jsonOutput))
+		fmt.Fprintln(out, string(jsonOutput))
 
 	} else if outputResolved {
 		// Perform SEMANTIC comparison only if resolved data is present
@@ -255,26 +265,30 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 		if err != nil {
 			log.Printf("Error during semantic comparison: %v", err)
 			// Fallback to old diff? Or just report error?
-			fmt.Println("❌ Semantic comparison failed:", err)
+			fmt.Fprintln(out, "❌ Semantic comparison failed:", err)
 
+		} else if diffStream {
+			if err := writeDiffStream(out, mibFilePath, comparisonResults); err != nil {
+				log.Fatalf("Error writing differences: %v", err)
+			}
 		} else {
 			// Check if there are any differences found by the semantic comparison
 			// Use hasSemanticDifferences (assuming it's defined in compare.go)
 			hasDiffs := hasSemanticDifferences(comparisonResults)
 
 			if !hasDiffs {
-				fmt.Println("✅ No semantic differences found in resolved MIB data.")
-				fmt.Printf("   Compared: %d Nodes, %d Types\n", comparisonResults.NodesCompared, comparisonResults.TypesCompared) // Provide some context
+				fmt.Fprintln(out, "✅ No semantic differences found in resolved MIB data.")
+				fmt.Fprintf(out, "   Compared: %d Nodes, %d Types\n", comparisonResults.NodesCompared, comparisonResults.TypesCompared) // Provide some context
 			} else {
-				fmt.Println("⚠️ Semantic differences found in resolved MIB data:")
+				fmt.Fprintln(out, "⚠️ Semantic differences found in resolved MIB data:")
 				// TODO: Implement nice printing of the comparisonResults struct here!
 				// For now, just dump the comparison result struct as JSON
 				jsonOutput, err := json.MarshalIndent(comparisonResults, "", "  ")
 				if err != nil {
 					log.Printf("Error marshalling semantic comparison results: %v", err)
-					fmt.Println("Could not display semantic differences.")
+					fmt.Fprintln(out, "Could not display semantic differences.")
 				} else {
-					fmt.Println(string(jsonOutput))
+					fmt.Fprintln(out, string(jsonOutput))
 				}
 			}
 		}
@@ -285,22 +299,22 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 
 		// Check for dependency differences first
 		if dependencyResults != nil && dependencyResults.HasDifferences {
-			fmt.Println("⚠️ Differences found in dependency parsing:")
-			fmt.Println("--- Fork Dependencies ---")
+			fmt.Fprintln(out, "⚠️ Differences found in dependency parsing:")
+			fmt.Fprintln(out, "--- Fork Dependencies ---")
 			for _, dep := range dependencyResults.ForkDependencies {
 				status := "✅ Success"
 				if !dep.Success {
 					status = "❌ Failed: " + dep.Error
 				}
-				fmt.Printf("  %s: %s\n", dep.ModuleName, status)
+				fmt.Fprintf(out, "  %s: %s\n", dep.ModuleName, status)
 			}
-			fmt.Println("--- Mainline Dependencies ---")
+			fmt.Fprintln(out, "--- Mainline Dependencies ---")
 			for _, dep := range dependencyResults.MainlineDependencies {
 				status := "✅ Success"
 				if !dep.Success {
 					status = "❌ Failed: " + dep.Error
 				}
-				fmt.Printf("  %s: %s\n", dep.ModuleName, status)
+				fmt.Fprintf(out, "  %s: %s\n", dep.ModuleName, status)
 			}
 		}
 
@@ -322,16 +336,16 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 		}
 
 		if reflect.DeepEqual(astForkResults, astMainlineResults) && (dependencyResults == nil || !dependencyResults.HasDifferences) {
-			fmt.Println("✅ No differences found in AST, parsing errors, or dependencies.")
+			fmt.Fprintln(out, "✅ No differences found in AST, parsing errors, or dependencies.")
 		} else {
-			fmt.Println("⚠️ Differences found in AST or parsing errors:")
+			fmt.Fprintln(out, "⚠️ Differences found in AST or parsing errors:")
 			// Use the old JSON diff method for AST differences or errors
 			forkJSON, errFork := json.MarshalIndent(astForkResults, "", "  ")
 			mainlineJSON, errMainline := json.MarshalIndent(astMainlineResults, "", "  ")
 
 			if errFork != nil || errMainline != nil {
 				log.Printf("Error marshalling AST results for diffing: ForkErr=%v, MainlineErr=%v", errFork, errMainline)
-				fmt.Println("Could not generate AST diff due to marshalling errors.")
+				fmt.Fprintln(out, "Could not generate AST diff due to marshalling errors.")
 			} else {
 				diff := difflib.UnifiedDiff{
 					A:        difflib.SplitLines(string(mainlineJSON)),
@@ -343,18 +357,29 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 				diffStr, err := difflib.GetUnifiedDiffString(diff)
 				if err != nil {
 					log.Printf("Error generating AST diff string: %v", err)
-					fmt.Println("Could not generate AST diff string.")
+					fmt.Fprintln(out, "Could not generate AST diff string.")
 				} else if diffStr == "" {
 					// This might happen if the only difference is in non-AST parts but we fell into this 'else' block
-					fmt.Println("✅ No differences found in AST/Error data (diff string empty).")
+					fmt.Fprintln(out, "✅ No differences found in AST/Error data (diff string empty).")
 				} else {
-					fmt.Println("--- AST/Error Diff Summary (Mainline vs Fork) ---")
-					fmt.Println(diffStr)
+					fmt.Fprintln(out, "--- AST/Error Diff Summary (Mainline vs Fork) ---")
+					fmt.Fprintln(out, diffStr)
 				}
 			}
 		}
 	}
 
+	if diagFormat == diagFormatJSON {
+		if err := writeDiagnostics(os.Stdout, diagFormat, forkDiagnostics); err != nil {
+			log.Printf("Error writing diagnostics: %v", err)
+		}
+	} else if len(forkDiagnostics) > 0 {
+		fmt.Fprintln(out, "--- Fork Diagnostics ---")
+		if err := writeDiagnostics(out, diagFormat, forkDiagnostics); err != nil {
+			log.Printf("Error writing diagnostics: %v", err)
+		}
+	}
+
 	// Final check for complete failure
 	hasForkOutput := forkResults["ast"] != nil || forkResults["resolved"] != nil
 	hasMainlineOutput := mainlineResults["ast"] != nil || mainlineResults["resolved"] != nil
@@ -364,10 +389,13 @@ func processSingleMibFile(mibFilePath, outputType string, dumpOutput bool) {
 	}
 }
 
-// compareSingleMibForDir processes a single MIB file for directory comparison mode.
-// It initializes gosmi, loads the MIB, performs comparison, and returns results including timing.
-// This function includes panic recovery to prevent halting the directory scan.
-func compareSingleMibForDir(mibFilePath string) (result DirComparisonResult) {
+// compareSingleMibForDir compares the module the fork compiled from a file of
+// a directory against mainline loading the same file, and returns the results
+// including timing. The fork returns parser and resolver panics as errors,
+// but the mainline library may still panic on malformed input, so panics are
+// recovered to prevent halting the directory scan.
+func compareSingleMibForDir(file gosmi.FileReport) (result DirComparisonResult) {
+	mibFilePath := file.Path
 	// Initialize result struct
 	result = DirComparisonResult{FilePath: mibFilePath, Same: false} // Default to not same
 
@@ -381,10 +409,7 @@ func compareSingleMibForDir(mibFilePath string) (result DirComparisonResult) {
 			result.ForkError = errors.New(errMsg)
 			result.MainlineError = errors.New(errMsg)
 			result.Same = false
-			// Ensure gosmi instances are cleaned up if they were initialized before panic
-			// Note: This relies on gosmi.Exit() being safe to call multiple times or if not initialized.
-			// Consider adding checks or more robust cleanup if needed.
-			gosmi.Exit()
+			// Ensure the mainline instance is cleaned up if it was initialized before panic
 			mainline_gosmi.Exit()
 		}
 	}()
@@ -398,36 +423,22 @@ func compareSingleMibForDir(mibFilePath string) (result DirComparisonResult) {
 	var comparisonResults *ComparisonResults
 	var comparisonErr error
 
-	// --- Process with Fork (lukeod/gosmi) ---
-	forkStart := time.Now()
-	gosmi.Init() // Potential panic point
-	gosmi.PrependPath(dir)
-	deps := []string{"SNMPv2-SMI", "SNMPv2-TC", "SNMPv2-CONF", "RFC1155-SMI", "RFC1213-MIB"}
-	for _, dep := range deps {
-		gosmi.LoadModule(dep) // Potential panic point
-	}
-	_, result.ForkError = gosmi.LoadModule(moduleNameFromName) // Potential panic point
+	// --- Fork (lukeod/gosmi), compiled with the rest of the directory ---
+	result.ForkDuration = file.ParseDuration + file.ResolveDuration
+	var forkModule gosmi.SmiModule
+	forkModule, result.ForkError = compiledModule(file)
 	if result.ForkError == nil {
-		var forkModule gosmi.SmiModule
-		forkModule, result.ForkError = gosmi.GetModule(moduleNameFromName) // Potential panic point
-		if result.ForkError == nil {
-			forkResolvedMap = map[string]interface{}{
-				"moduleInfo": forkModule,
-				"nodes":      forkModule.GetNodes(), // Potential panic point (if module invalid?)
-				"types":      forkModule.GetTypes(), // Potential panic point
-			}
+		forkResolvedMap = map[string]interface{}{
+			"moduleInfo": forkModule,
+			"nodes":      forkModule.GetNodes(),
+			"types":      forkModule.GetTypes(),
 		}
 	}
-	result.ForkDuration = time.Since(forkStart)
-	gosmi.Exit() // Clean up fork instance
 
 	// --- Process with Mainline (sleepinggenius2/gosmi) ---
 	mainlineStart := time.Now()
 	mainline_gosmi.Init() // Potential panic point
 	mainline_gosmi.PrependPath(dir)
-	for _, dep := range deps {
-		mainline_gosmi.LoadModule(dep) // Potential panic point
-	}
 	_, result.MainlineError = mainline_gosmi.LoadModule(moduleNameFromName) // Potential panic point
 	if result.MainlineError == nil {
 		var mainlineModule mainline_gosmi.SmiModule
@@ -457,6 +468,7 @@ func compareSingleMibForDir(mibFilePath string) (result DirComparisonResult) {
 		} else {
 			// Use hasSemanticDifferences (defined in compare.go)
 			result.Same = !hasSemanticDifferences(comparisonResults) // Potential panic point
+			result.Differences = comparisonResults
 		}
 	} else {
 		// If either had a load error (or a panic was caught and assigned error), they are not the same
@@ -467,42 +479,86 @@ func compareSingleMibForDir(mibFilePath string) (result DirComparisonResult) {
 	return
 }
 
-// processDirectory handles the recursive directory processing
-func processDirectory(dirPath string) {
-	log.Printf("Processing directory recursively: %s\n", dirPath)
-	var results []DirComparisonResult
-	var mibFilesFound int
+// compiledModule returns the module the fork compiled from file, or the
+// first error reported for it. A module also provided by another file of the
+// directory is only kept from one of them.
+func compiledModule(file gosmi.FileReport) (gosmi.SmiModule, error) {
+	for _, d := range file.Diagnostics {
+		if d.Severity == diag.SeverityError {
+			return gosmi.SmiModule{}, errors.New(d.Message)
+		}
+	}
+	module, err := gosmi.GetModule(file.Module)
+	if err != nil {
+		return module, err
+	}
+	if filepath.Clean(module.Path) != filepath.Clean(file.Path) {
+		return module, fmt.Errorf("Module %s is taken from %s", file.Module, module.Path)
+	}
+	return module, nil
+}
 
-	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			log.Printf("Error accessing path %q: %v\n", path, err)
-			return err // Prevent further processing if path is inaccessible
-		}
-		if !d.IsDir() {
-			// Basic MIB file check (can be refined)
-			ext := strings.ToLower(filepath.Ext(path))
-			// Consider .mib, .txt, and files with no extension as potential MIBs
-			if ext == ".mib" || ext == ".txt" || ext == "" {
-				log.Printf("Found potential MIB: %s", path)
-				mibFilesFound++
-				// Call the comparison function for this file
-				comparisonResult := compareSingleMibForDir(path)
-				results = append(results, comparisonResult)
+// processDirectory compiles a directory with the fork and compares every
+// file compiled against mainline, writing an HTML report of the results if
+// htmlPath is set. With compileOnly, the per-file compile report is printed
+// instead, or written in reportFormat if set.
+func processDirectory(dirPath string, compileOnly bool, workers int, diagFormat, reportFormat, htmlPath string) {
+	log.Printf("Processing directory: %s\n", dirPath)
+	initFork()
+	defer gosmi.Exit()
+
+	// Ctrl-C stops the compile or the comparison but still reports the files
+	// done so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := gosmi.CompileDir(dirPath, gosmi.WithWorkers(workers), gosmi.WithContext(ctx), gosmi.WithProgress(logCompileProgress))
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Interrupted, reporting the files compiled so far.")
+	} else if err != nil {
+		log.Fatalf("Error compiling directory %q: %v", dirPath, err)
+	}
+	log.Printf("Compiled %d files in %s, %d failed.", len(report.Files), report.Duration, len(report.Failed()))
+	logDuplicates(report)
+
+	if compileOnly {
+		printCompileReport(dirPath, report, diagFormat, reportFormat)
+		return
+	}
+
+	var results []DirComparisonResult
+	for _, file := range report.Files {
+		if ctx.Err() != nil {
+			log.Printf("Interrupted, stopping early.")
+			break
+		}
+		comparisonResult := compareSingleMibForDir(file)
+		results = append(results, comparisonResult)
+		if diffStream && comparisonResult.Differences != nil {
+			if err := writeDiffStream(os.Stdout, file.Path, comparisonResult.Differences); err != nil {
+				log.Fatalf("Error writing differences: %v", err)
 			}
 		}
-		return nil // Continue walking
-	})
-
-	if err != nil {
-		log.Fatalf("Error walking directory %q: %v", dirPath, err)
 	}
 
-	log.Printf("Finished processing. Found %d potential MIB files.", mibFilesFound)
+	log.Printf("Finished processing. Compared %d MIB files.", len(results))
+
+	if htmlPath != "" {
+		if err := writeHTMLReport(htmlPath, newComparisonReport(dirPath, results)); err != nil {
+			log.Fatalf("Error writing HTML report: %v", err)
+		}
+		log.Printf("Wrote HTML report to %s", htmlPath)
+	}
 
 	// --- Print Summary Table ---
+	// Streamed differences keep stdout to themselves
+	out := os.Stdout
+	if diffStream {
+		out = os.Stderr
+	}
 	if len(results) > 0 {
-		fmt.Println("\n--- Directory Comparison Summary ---")
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) // Use spaces for alignment
+		fmt.Fprintln(out, "\n--- Directory Comparison Summary ---")
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) // Use spaces for alignment
 		// Print header
 		fmt.Fprintln(w, "File\tSame?\tFork Error\tMainline Error\tFork Time (ms)\tMainline Time (ms)")
 		fmt.Fprintln(w, "----\t-----\t----------\t--------------\t--------------\t------------------")
@@ -545,3 +601,97 @@ func processDirectory(dirPath string) {
 		log.Println("No MIB files processed in the directory.")
 	}
 }
+
+// logDuplicates logs the copies of files passed over and the modules
+// provided by several files of different content
+func logDuplicates(report gosmi.Report) {
+	for _, d := range report.Duplicates {
+		log.Printf("Skipped %s, a copy of %s", d.Path, d.Original)
+	}
+	near := report.NearDuplicates()
+	modules := make([]string, 0, len(near))
+	for module := range near {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		log.Printf("Module %s is provided by differing files: %s", module, strings.Join(near[module], ", "))
+	}
+}
+
+// printCompileReport prints the per-file report of a directory compile, or
+// writes it in reportFormat if set
+func printCompileReport(dirPath string, report gosmi.Report, diagFormat, reportFormat string) {
+	if reportFormat != "" {
+		if err := writeReport(os.Stdout, reportFormat, dirPath, report.Files); err != nil {
+			log.Fatalf("Error writing report: %v", err)
+		}
+		return
+	}
+
+	if diagFormat == diagFormatJSON {
+		// Keep stdout machine-readable: diagnostics only
+		var diagnostics []diag.Diagnostic
+		for _, res := range report.Files {
+			diagnostics = append(diagnostics, res.Diagnostics...)
+		}
+		if err := writeDiagnostics(os.Stdout, diagFormat, diagnostics); err != nil {
+			log.Fatalf("Error writing diagnostics: %v", err)
+		}
+		return
+	}
+
+	if len(report.Files) == 0 {
+		log.Println("No MIB files found in the directory.")
+		return
+	}
+
+	fmt.Println("\n--- Directory Compile Summary ---")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "File\tModule\tOK?\tParse Time (ms)\tResolve Time (ms)\tDiagnostics\tFirst Diagnostic")
+	fmt.Fprintln(w, "----\t------\t---\t---------------\t-----------------\t-----------\t----------------")
+	for _, res := range report.Files {
+		errStr := "nil"
+		if len(res.Diagnostics) > 0 {
+			errStr = res.Diagnostics[0].Message
+			if len(errStr) > 50 {
+				errStr = errStr[:47] + "..."
+			}
+		}
+		relPath, err := filepath.Rel(dirPath, res.Path)
+		if err != nil {
+			relPath = res.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%d\t%d\t%s\n",
+			relPath,
+			res.Module,
+			res.OK(),
+			res.ParseDuration.Milliseconds(),
+			res.ResolveDuration.Milliseconds(),
+			len(res.Diagnostics),
+			errStr,
+		)
+	}
+	w.Flush()
+
+	fmt.Println("\n--- Diagnostics ---")
+	for _, res := range report.Files {
+		if err := writeDiagnostics(os.Stdout, diagFormat, res.Diagnostics); err != nil {
+			log.Fatalf("Error writing diagnostics: %v", err)
+		}
+	}
+}
+
+// logCompileProgress logs every progressInterval files of each stage of a
+// directory compile, so that large directories show signs of life
+func logCompileProgress(p gosmi.Progress) {
+	const progressInterval = 500
+	switch {
+	case p.Stage == gosmi.StageDiscovered:
+		log.Printf("Found %d MIB files.", p.Discovered)
+	case p.Stage == gosmi.StageParsed && p.Parsed%progressInterval == 0:
+		log.Printf("Parsed %d/%d files, %d failed.", p.Parsed, p.Discovered, p.Failed)
+	case p.Stage == gosmi.StageResolved && p.Resolved%progressInterval == 0:
+		log.Printf("Resolved %d files, %d failed.", p.Resolved, p.Failed)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lukeod/gosmi"
+)
+
+// profileLoad loads a single MIB file or compiles a directory with the fork
+// and prints the n modules that took longest to load, with the time of each
+// phase, followed by the total time of each phase over all loaded modules
+func profileLoad(mibFilePath, dirPath string, workers, n int) {
+	initFork()
+	defer gosmi.Exit()
+	start := time.Now()
+	loadFork(mibFilePath, dirPath, workers)
+	elapsed := time.Since(start)
+
+	modules := gosmi.GetLoadedModules()
+	loaded := len(modules)
+	var total gosmi.LoadTiming
+	for _, m := range modules {
+		t := m.Timing()
+		total.Lex += t.Lex
+		total.Parse += t.Parse
+		total.Resolve += t.Resolve
+	}
+	sum := func(t gosmi.LoadTiming) time.Duration { return t.Lex + t.Parse + t.Resolve }
+	sort.SliceStable(modules, func(i, j int) bool { return sum(modules[i].Timing()) > sum(modules[j].Timing()) })
+	if n < len(modules) {
+		modules = modules[:n]
+	}
+
+	ms := func(d time.Duration) string { return fmt.Sprintf("%.2f", float64(d)/float64(time.Millisecond)) }
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Module\tLex (ms)\tParse (ms)\tResolve (ms)\tTotal (ms)\t")
+	for _, m := range modules {
+		t := m.Timing()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", m.Name, ms(t.Lex), ms(t.Parse), ms(t.Resolve), ms(sum(t)))
+	}
+	fmt.Fprintf(w, "All %d modules\t%s\t%s\t%s\t%s\t\n", loaded, ms(total.Lex), ms(total.Parse), ms(total.Resolve), ms(sum(total)))
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Error writing profile: %v", err)
+	}
+	// Files of a directory are parsed in parallel, so the phases may add up
+	// to more than the time taken
+	log.Printf("Loaded %d modules in %s.", loaded, elapsed)
+}
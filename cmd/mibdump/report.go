@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+)
+
+// CI report formats accepted by -report
+const (
+	reportFormatJUnit = "junit"
+	reportFormatSARIF = "sarif"
+)
+
+// writeReport writes the per-file results of -lint or -compile in a CI report
+// format, with paths relative to dirPath if set
+func writeReport(w io.Writer, format, dirPath string, files []gosmi.FileReport) error {
+	if format == reportFormatSARIF {
+		return writeSARIF(w, dirPath, files)
+	}
+	return writeJUnit(w, dirPath, files)
+}
+
+// diagnosticText is the message of d followed by its suggested fix, if any
+func diagnosticText(d diag.Diagnostic) string {
+	if d.Fix != "" {
+		return d.Message + " (" + d.Fix + ")"
+	}
+	return d.Message
+}
+
+// JUnit XML, as understood by Jenkins, GitLab and most CI systems: one test
+// case per file, failing on error diagnostics, with the others as output.
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, dirPath string, files []gosmi.FileReport) error {
+	suite := junitTestSuite{Name: "gosmi", Tests: len(files)}
+	var total float64
+	for _, f := range files {
+		seconds := (f.ParseDuration + f.ResolveDuration).Seconds()
+		total += seconds
+		tc := junitTestCase{
+			ClassName: f.Module,
+			Name:      relativePath(dirPath, f.Path),
+			Time:      fmt.Sprintf("%.3f", seconds),
+		}
+		if tc.ClassName == "" {
+			tc.ClassName = "gosmi"
+		}
+		var errors, others []string
+		for _, d := range f.Diagnostics {
+			line := d.String()
+			if d.Severity == diag.SeverityError {
+				if tc.Failure == nil {
+					tc.Failure = &junitFailure{Message: d.Message, Type: d.Code}
+				}
+				errors = append(errors, line)
+			} else {
+				others = append(others, line)
+			}
+		}
+		if tc.Failure != nil {
+			tc.Failure.Text = strings.Join(errors, "\n")
+			suite.Failures++
+		}
+		tc.SystemOut = strings.Join(others, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// SARIF 2.1.0, for code scanning UIs such as GitHub's: one result per
+// diagnostic, with a rule per diagnostic code.
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func sarifLevel(s diag.Severity) string {
+	switch s {
+	case diag.SeverityError:
+		return "error"
+	case diag.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func writeSARIF(w io.Writer, dirPath string, files []gosmi.FileReport) error {
+	codes := make(map[string]bool)
+	results := []sarifResult{}
+	for _, f := range files {
+		for _, d := range f.Diagnostics {
+			path := d.Pos.Filename
+			if path == "" {
+				path = f.Path
+			}
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(relativePath(dirPath, path))},
+			}}
+			if d.Pos.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Pos.Line, StartColumn: d.Pos.Column}
+			}
+			if d.Code != "" {
+				codes[d.Code] = true
+			}
+			results = append(results, sarifResult{
+				RuleID:    d.Code,
+				Level:     sarifLevel(d.Severity),
+				Message:   sarifMessage{Text: diagnosticText(d)},
+				Locations: []sarifLocation{loc},
+			})
+		}
+	}
+	rules := make([]sarifRule, 0, len(codes))
+	for code := range codes {
+		rules = append(rules, sarifRule{ID: code})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gosmi",
+				InformationURI: "https://github.com/lukeod/gosmi",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	})
+}
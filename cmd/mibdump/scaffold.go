@@ -0,0 +1,21 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/lukeod/gosmi"
+)
+
+// writeSkeleton writes a new SMIv2 module to stdout
+func writeSkeleton(module, organization, contact, root string) {
+	err := gosmi.WriteSkeleton(os.Stdout, gosmi.Skeleton{
+		Module:       module,
+		Organization: organization,
+		Contact:      contact,
+		Root:         root,
+	})
+	if err != nil {
+		log.Fatalf("Error writing module skeleton: %v", err)
+	}
+}
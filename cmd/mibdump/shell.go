@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+)
+
+const shellPrompt = "gosmi> "
+
+// shellCommand is a command of the interactive shell, run with the words
+// following its name.
+type shellCommand struct {
+	usage string
+	doc   string
+	run   func(s *shell, args []string) error
+}
+
+var shellCommands map[string]*shellCommand
+
+func init() {
+	// Set in init, as help refers to the table
+	shellCommands = map[string]*shellCommand{
+		"help":          {"help", "list the commands", (*shell).help},
+		"path":          {"path DIR...", "search DIR for modules before the current path", (*shell).path},
+		"load":          {"load MODULE|FILE|DIR...", "load modules by name or file, or compile a directory", (*shell).load},
+		"modules":       {"modules", "list the loaded modules", (*shell).modules},
+		"translate":     {"translate OID...", "translate between numeric and symbolic OIDs", (*shell).translate},
+		"tree":          {"tree [OID] [DEPTH]", "print the tree below OID, iso by default, DEPTH levels deep", (*shell).tree},
+		"search":        {"search PATTERN", "list the nodes whose name contains PATTERN, or matches it as a glob", (*shell).search},
+		"type":          {"type NAME|OID", "describe a type, or the type of a node", (*shell).typ},
+		"walk-annotate": {"walk-annotate FILE", "print snmpwalk -On output with OIDs named and enumerations labelled", (*shell).walkAnnotate},
+		"exit":          {"exit", "leave the shell", nil},
+	}
+}
+
+// shell runs commands against a handle kept initialized between them, so
+// that modules are loaded once for any number of lookups.
+type shell struct {
+	out       io.Writer
+	workers   int
+	oidFormat types.OidFormat
+}
+
+// runShell reads commands from in until end of input or exit, after loading
+// the file or directory given, if any.
+func runShell(mibFilePath, dirPath string, workers int, oidFormat types.OidFormat, in io.Reader, out io.Writer) {
+	initFork()
+	defer gosmi.Exit()
+	if oidFormat == types.OidFormatNumeric {
+		oidFormat = types.OidFormatModule
+	}
+	s := &shell{out: out, workers: workers, oidFormat: oidFormat}
+	for _, p := range []string{mibFilePath, dirPath} {
+		if p == "" {
+			continue
+		}
+		if err := s.load([]string{p}); err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, shellPrompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 || strings.HasPrefix(words[0], "#") {
+			continue
+		}
+		cmd, ok := shellCommands[words[0]]
+		if !ok {
+			fmt.Fprintf(out, "Unknown command %q, see help\n", words[0])
+			continue
+		}
+		if cmd.run == nil {
+			return
+		}
+		if err := cmd.run(s, words[1:]); err != nil {
+			fmt.Fprintf(out, "Error: %v\n", err)
+		}
+	}
+}
+
+func (s *shell) help(args []string) error {
+	names := make([]string, 0, len(shellCommands))
+	for name := range shellCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := shellCommands[name]
+		fmt.Fprintf(s.out, "  %-26s %s\n", cmd.usage, cmd.doc)
+	}
+	return nil
+}
+
+func (s *shell) path(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintln(s.out, gosmi.GetPath())
+		return nil
+	}
+	for i := len(args) - 1; i >= 0; i-- {
+		gosmi.PrependPath(args[i])
+	}
+	return nil
+}
+
+func (s *shell) load(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Usage: %s", shellCommands["load"].usage)
+	}
+	for _, arg := range args {
+		name := arg
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			report, err := gosmi.CompileDir(arg, gosmi.WithWorkers(s.workers))
+			if err != nil {
+				return fmt.Errorf("Compile directory %s: %w", arg, err)
+			}
+			fmt.Fprintf(s.out, "Compiled %d files, %d failed.\n", len(report.Files), len(report.Failed()))
+			continue
+		} else if err == nil {
+			// A file, loaded by name from its directory as the other modes do
+			gosmi.PrependPath(filepath.Dir(arg))
+			base := filepath.Base(arg)
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		name, err := gosmi.LoadModule(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "Loaded %s\n", name)
+	}
+	return nil
+}
+
+func (s *shell) modules(args []string) error {
+	for _, m := range gosmi.GetLoadedModules() {
+		fmt.Fprintf(s.out, "%s\t%s\n", m.Name, m.Path)
+	}
+	return nil
+}
+
+// numericOID matches OIDs made of numbers only, with an optional leading dot.
+var numericOID = regexp.MustCompile(`^\.?[0-9]+(\.[0-9]+)*$`)
+
+func (s *shell) translate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Usage: %s", shellCommands["translate"].usage)
+	}
+	for _, arg := range args {
+		node, instance, err := gosmi.ParseOID(arg)
+		if err != nil {
+			fmt.Fprintf(s.out, "%s: %v\n", arg, err)
+			continue
+		}
+		oid := append(append(types.Oid(nil), node.Oid...), instance...)
+		format := s.oidFormat
+		if !numericOID.MatchString(arg) {
+			format = types.OidFormatNumeric
+		}
+		fmt.Fprintf(s.out, "%s = %s\n", arg, gosmi.FormatOID(oid, format))
+	}
+	return nil
+}
+
+func (s *shell) tree(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("Usage: %s", shellCommands["tree"].usage)
+	}
+	oid, depth := "iso", -1
+	if len(args) > 0 {
+		oid = args[0]
+	}
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("Invalid depth %q", args[1])
+		}
+		depth = n
+	}
+	node, _, err := gosmi.ParseOID(oid)
+	if err != nil {
+		return err
+	}
+	s.printTree(node.GetRaw(), 0, depth)
+	return nil
+}
+
+// printTree prints node indented by its level below the root of the tree,
+// followed by its children down to depth levels, or all if depth is negative.
+// Children defined by other modules than node are included.
+func (s *shell) printTree(smiNode *types.SmiNode, level, depth int) {
+	n := gosmi.CreateNode(smiNode)
+	fmt.Fprintf(s.out, "%s%s(%d) %s\n", strings.Repeat("  ", level), n.Name, n.Oid[n.OidLen-1], n.Kind)
+	if level == depth {
+		return
+	}
+	for child := smi.GetFirstChildNode(smiNode); child != nil; child = smi.GetNextChildNode(child) {
+		s.printTree(child, level+1, depth)
+	}
+}
+
+func (s *shell) search(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Usage: %s", shellCommands["search"].usage)
+	}
+	pattern := strings.ToLower(args[0])
+	glob := strings.ContainsAny(pattern, "*?[")
+	if _, err := path.Match(pattern, ""); glob && err != nil {
+		return fmt.Errorf("Invalid pattern %q: %w", args[0], err)
+	}
+	for _, m := range gosmi.GetLoadedModules() {
+		for _, n := range m.GetNodes() {
+			name := strings.ToLower(n.Name)
+			var match bool
+			if glob {
+				match, _ = path.Match(pattern, name)
+			} else {
+				match = strings.Contains(name, pattern)
+			}
+			if match {
+				fmt.Fprintf(s.out, "%s::%s\t%s\n", m.Name, n.Name, n.RenderNumeric())
+			}
+		}
+	}
+	return nil
+}
+
+func (s *shell) typ(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Usage: %s", shellCommands["type"].usage)
+	}
+	t, err := gosmi.GetType(args[0])
+	if err != nil {
+		node, _, nodeErr := gosmi.ParseOID(args[0])
+		if nodeErr != nil {
+			return err
+		}
+		if node.Type == nil {
+			return fmt.Errorf("Node %s has no type", node.Name)
+		}
+		fmt.Fprintf(s.out, "%s::%s has type %s\n", node.GetModule().Name, node.Name, node.Type.Name)
+		// With the refinements of the node's SYNTAX
+		t = *gosmi.CreateTypeFromNode(node.GetRaw())
+	}
+	if m := t.GetModule(); m.Name != "" {
+		fmt.Fprintf(s.out, "Module:   %s\n", m.Name)
+	}
+	fmt.Fprintf(s.out, "Name:     %s\n", t.Name)
+	fmt.Fprintf(s.out, "Base:     %s\n", t.BaseType)
+	var chain []string
+	for _, parent := range t.BaseTypeChain() {
+		// Unnamed refinements carry the name of the type they refine
+		if len(chain) == 0 || chain[len(chain)-1] != parent.Name {
+			chain = append(chain, parent.Name)
+		}
+	}
+	if len(chain) > 1 {
+		fmt.Fprintf(s.out, "Chain:    %s\n", strings.Join(chain, " <- "))
+	}
+	if t.Format != "" {
+		fmt.Fprintf(s.out, "Format:   %s\n", t.Format)
+	}
+	if t.Units != "" {
+		fmt.Fprintf(s.out, "Units:    %s\n", t.Units)
+	}
+	for _, r := range t.Ranges {
+		fmt.Fprintf(s.out, "Range:    %d..%d\n", r.MinValue, r.MaxValue)
+	}
+	if t.Enum != nil {
+		for _, v := range t.Enum.Values {
+			fmt.Fprintf(s.out, "Value:    %s(%d)\n", v.Name, v.Value)
+		}
+	}
+	return nil
+}
+
+// walkLine matches a line of snmpwalk -On output: the OID, then the type and
+// value.
+var walkLine = regexp.MustCompile(`^(\.?[0-9]+(?:\.[0-9]+)*)(\s*=\s*)(.*)$`)
+
+// walkInteger matches the value of an INTEGER varbind.
+var walkInteger = regexp.MustCompile(`^INTEGER:\s*(-?[0-9]+)$`)
+
+func (s *shell) walkAnnotate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Usage: %s", shellCommands["walk-annotate"].usage)
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("Open file: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := walkLine.FindStringSubmatch(line)
+		if m == nil {
+			fmt.Fprintln(s.out, line)
+			continue
+		}
+		node, instance, err := gosmi.ParseOID(m[1])
+		if err != nil {
+			fmt.Fprintln(s.out, line)
+			continue
+		}
+		oid := append(append(types.Oid(nil), node.Oid...), instance...)
+		value := m[3]
+		if v := walkInteger.FindStringSubmatch(value); v != nil && node.Type != nil && node.Type.Enum != nil {
+			if n, err := strconv.ParseInt(v[1], 10, 64); err == nil {
+				value = fmt.Sprintf("INTEGER: %s(%d)", node.Type.Enum.Name(n), n)
+			}
+		}
+		fmt.Fprintf(s.out, "%s%s%s\n", gosmi.FormatOID(oid, s.oidFormat), m[2], value)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+)
+
+const (
+	statsFormatText = "text"
+	statsFormatJSON = "json"
+)
+
+// statsKinds are the node kinds given a column of their own in the text
+// output, in order
+var statsKinds = []types.NodeKind{
+	types.NodeNode,
+	types.NodeScalar,
+	types.NodeTable,
+	types.NodeRow,
+	types.NodeColumn,
+	types.NodeNotification,
+	types.NodeGroup,
+	types.NodeCompliance,
+	types.NodeCapabilities,
+}
+
+// dumpStats loads a single MIB file or compiles a directory with the fork and
+// writes the statistics of every loaded module and their total to stdout
+func dumpStats(mibFilePath, dirPath string, workers int, format string) {
+	initFork()
+	defer gosmi.Exit()
+	loadFork(mibFilePath, dirPath, workers)
+
+	stats := gosmi.GetStats()
+	total := gosmi.TotalStats(stats)
+	if format == statsFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(struct {
+			Modules []gosmi.ModuleStats `json:"modules"`
+			Total   gosmi.ModuleStats   `json:"total"`
+		}{stats, total})
+		if err != nil {
+			log.Fatalf("Error writing statistics: %v", err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "Module\tLanguage\t")
+	for _, kind := range statsKinds {
+		fmt.Fprintf(w, "%s\t", kind)
+	}
+	fmt.Fprintln(w, "Types\tDescription bytes\tDepth\tImports\tUnresolved\t")
+	row := func(name string, s gosmi.ModuleStats) {
+		fmt.Fprintf(w, "%s\t%s\t", name, s.Language)
+		for _, kind := range statsKinds {
+			fmt.Fprintf(w, "%d\t", s.Nodes[kind.String()])
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t\n", s.Types, s.DescriptionBytes, s.Depth, s.Imports, s.Unresolved)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Module < stats[j].Module })
+	for _, s := range stats {
+		row(s.Module, s)
+	}
+	row(fmt.Sprintf("Total (%d modules)", len(stats)), total)
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Error writing statistics: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// diffStream is set by -diff-stream to write every difference as a line of
+// JSON instead of the comparison summary.
+var diffStream bool
+
+// diffRecord is a line of -diff-stream output. Category is the field of
+// ComparisonResults the item is listed in.
+type diffRecord struct {
+	File     string      `json:"file"`
+	Category string      `json:"category"`
+	Item     interface{} `json:"item"`
+}
+
+// writeDiffStream writes a record for each difference of the results of the
+// file. The results should be compared without a limit on examples, or the
+// records are as truncated as the summary.
+func writeDiffStream(w io.Writer, file string, results *ComparisonResults) error {
+	enc := json.NewEncoder(w)
+	write := func(category string, item interface{}) error {
+		return enc.Encode(diffRecord{File: file, Category: category, Item: item})
+	}
+	var err error
+	each := func(category string, n int, item func(i int) interface{}) {
+		for i := 0; i < n && err == nil; i++ {
+			err = write(category, item(i))
+		}
+	}
+	each("moduleInfoDiffs", len(results.ModuleInfoDiffs), func(i int) interface{} { return results.ModuleInfoDiffs[i] })
+	each("nodesAdded", len(results.NodesAdded), func(i int) interface{} { return results.NodesAdded[i] })
+	each("nodesRemoved", len(results.NodesRemoved), func(i int) interface{} { return results.NodesRemoved[i] })
+	each("nodesModified", len(results.NodesModified), func(i int) interface{} { return results.NodesModified[i] })
+	each("typesAdded", len(results.TypesAdded), func(i int) interface{} { return results.TypesAdded[i] })
+	each("typesRemoved", len(results.TypesRemoved), func(i int) interface{} { return results.TypesRemoved[i] })
+	each("typesModified", len(results.TypesModified), func(i int) interface{} { return results.TypesModified[i] })
+	each("importsAdded", len(results.ImportsAdded), func(i int) interface{} { return results.ImportsAdded[i] })
+	each("importsRemoved", len(results.ImportsRemoved), func(i int) interface{} { return results.ImportsRemoved[i] })
+	each("importsMoved", len(results.ImportsMoved), func(i int) interface{} { return results.ImportsMoved[i] })
+	each("revisionsAdded", len(results.RevisionsAdded), func(i int) interface{} { return results.RevisionsAdded[i] })
+	each("revisionsRemoved", len(results.RevisionsRemoved), func(i int) interface{} { return results.RevisionsRemoved[i] })
+	each("revisionsModified", len(results.RevisionsModified), func(i int) interface{} { return results.RevisionsModified[i] })
+	if err == nil && results.Identity != nil {
+		err = write("identity", results.Identity)
+	}
+	return err
+}
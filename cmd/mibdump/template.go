@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/lukeod/gosmi"
+)
+
+// exportTemplate loads a single MIB file or compiles a directory with the
+// fork and renders the template file with the loaded modules to stdout
+func exportTemplate(mibFilePath, dirPath string, workers int, templatePath string) {
+	text, err := os.ReadFile(templatePath)
+	if err != nil {
+		log.Fatalf("Error reading template: %v", err)
+	}
+	tmpl, err := gosmi.ParseTemplate(templatePath, string(text))
+	if err != nil {
+		log.Fatalf("Error parsing template: %v", err)
+	}
+
+	initFork()
+	defer gosmi.Exit()
+	loadFork(mibFilePath, dirPath, workers)
+
+	if err := gosmi.ExportTemplate(os.Stdout, tmpl); err != nil {
+		log.Fatalf("Error rendering template: %v", err)
+	}
+}
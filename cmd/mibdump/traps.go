@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+)
+
+const (
+	trapFormatCSV  = "csv"
+	trapFormatJSON = "json"
+)
+
+// dumpTrapCatalog loads a single MIB file or compiles a directory with the
+// fork and writes the notifications of every loaded module to stdout, with
+// their OIDs in oidFormat
+func dumpTrapCatalog(mibFilePath, dirPath string, workers int, format string, oidFormat types.OidFormat) {
+	initFork()
+	defer gosmi.Exit()
+	loadFork(mibFilePath, dirPath, workers)
+
+	traps := gosmi.GetTrapCatalog()
+	log.Printf("Found %d notifications.", len(traps))
+	if oidFormat != types.OidFormatNumeric {
+		for i := range traps {
+			if oid, err := types.OidFromString(traps[i].Oid); err == nil {
+				traps[i].Oid = gosmi.FormatOID(oid, oidFormat)
+			}
+		}
+	}
+	var err error
+	if format == trapFormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(traps)
+	} else {
+		err = gosmi.WriteTrapCatalogCSV(os.Stdout, traps)
+	}
+	if err != nil {
+		log.Fatalf("Error writing trap catalog: %v", err)
+	}
+}
+
+// loadFork loads a single MIB file or compiles a directory with the
+// initialized fork
+func loadFork(mibFilePath, dirPath string, workers int) {
+	if mibFilePath != "" {
+		gosmi.PrependPath(filepath.Dir(mibFilePath))
+		baseName := filepath.Base(mibFilePath)
+		if _, err := gosmi.LoadModule(strings.TrimSuffix(baseName, filepath.Ext(baseName))); err != nil {
+			log.Fatalf("Error loading MIB %q: %v", mibFilePath, err)
+		}
+		return
+	}
+	report, err := gosmi.CompileDir(dirPath, gosmi.WithWorkers(workers))
+	if err != nil {
+		log.Fatalf("Error compiling directory %q: %v", dirPath, err)
+	}
+	log.Printf("Compiled %d files, %d failed.", len(report.Files), len(report.Failed()))
+}
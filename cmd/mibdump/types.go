@@ -11,7 +11,14 @@ import (
 
 // --- Semantic Comparison Data Structures ---
 
-const maxExamplesPerCategory = 3 // Limit the number of examples stored
+// maxExamplesPerCategory limits the number of added, removed and modified
+// nodes and types listed in comparison results, set by -examples. 0 lists all.
+var maxExamplesPerCategory = 3
+
+// keepExample tells whether another example fits in a category of count.
+func keepExample(count int) bool {
+	return maxExamplesPerCategory <= 0 || count < maxExamplesPerCategory
+}
 
 // DependencyParseResult tracks parsing results for a dependency
 type DependencyParseResult struct {
@@ -50,10 +57,65 @@ type NodeDifference struct {
 
 // TypeDifference holds details about a modified SmiType.
 type TypeDifference struct {
-	Name     string                 `json:"name"`
-	BaseType string                 `json:"baseType"` // Use BaseType for identification
-	KindDiff *ValuePair             `json:"kindDiff,omitempty"`
-	Diffs    []ModuleInfoDifference `json:"diffs"` // Generic list of field differences
+	Name      string                 `json:"name"`
+	BaseType  string                 `json:"baseType"` // Use BaseType for identification
+	KindDiff  *ValuePair             `json:"kindDiff,omitempty"`
+	Diffs     []ModuleInfoDifference `json:"diffs"` // Generic list of field differences
+	EnumDiff  *EnumDifference        `json:"enumDiff,omitempty"`
+	RangeDiff *RangeDifference       `json:"rangeDiff,omitempty"`
+}
+
+// NamedValue is a label of an enumeration or BITS type with its value.
+type NamedValue struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// LabelChange is a value labelled differently by fork and mainline.
+type LabelChange struct {
+	Value    int64  `json:"value"`
+	Fork     string `json:"fork"`
+	Mainline string `json:"mainline"`
+}
+
+// ValueChange is a label given different values by fork and mainline.
+type ValueChange struct {
+	Name     string `json:"name"`
+	Fork     int64  `json:"fork"`
+	Mainline int64  `json:"mainline"`
+}
+
+// EnumDifference compares the named numbers of a type element-wise. Added
+// values are only in the fork, removed ones only in mainline.
+type EnumDifference struct {
+	Added      []NamedValue  `json:"added,omitempty"`
+	Removed    []NamedValue  `json:"removed,omitempty"`
+	Relabeled  []LabelChange `json:"relabeled,omitempty"`
+	Renumbered []ValueChange `json:"renumbered,omitempty"`
+	Summary    string        `json:"summary"`
+}
+
+// RangeValue is a range or size constraint of a type.
+type RangeValue struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// Range changes of the fork relative to mainline
+const (
+	rangeWidened   = "widened"
+	rangeNarrowed  = "narrowed"
+	rangeDifferent = "changed"
+)
+
+// RangeDifference compares the ranges of a type. Change is "widened" if the
+// fork allows every value mainline does and more, "narrowed" if fewer, and
+// "changed" otherwise.
+type RangeDifference struct {
+	Fork     []RangeValue `json:"fork"`
+	Mainline []RangeValue `json:"mainline"`
+	Change   string       `json:"change"`
+	Summary  string       `json:"summary"`
 }
 
 // SimplifiedNode represents a node for addition/removal reporting.
@@ -92,10 +154,60 @@ type ComparisonResults struct {
 	TypesRemovedCount  int              `json:"typesRemovedCount"`
 	TypesModifiedCount int              `json:"typesModifiedCount"`
 
-	// TODO: Add fields for Imports, Revisions, Identity Node differences
-	// ImportsAdded/Removed/Modified
-	// RevisionsAdded/Removed/Modified
-	// IdentityNodeDiff *NodeDifference
+	// Imports are compared by symbol: added and removed symbols are imported
+	// by one side only, moved ones from different modules
+	ImportsAdded   []ImportValue `json:"importsAdded,omitempty"`
+	ImportsRemoved []ImportValue `json:"importsRemoved,omitempty"`
+	ImportsMoved   []ImportMove  `json:"importsMoved,omitempty"`
+
+	// Revisions are compared by date
+	RevisionsAdded    []RevisionValue      `json:"revisionsAdded,omitempty"`
+	RevisionsRemoved  []RevisionValue      `json:"revisionsRemoved,omitempty"`
+	RevisionsModified []RevisionDifference `json:"revisionsModified,omitempty"`
+
+	// Identity is set if the MODULE-IDENTITY differs
+	Identity *IdentityDifference `json:"identity,omitempty"`
+}
+
+// IdentityValue is the MODULE-IDENTITY of a module.
+type IdentityValue struct {
+	Name         string `json:"name"`
+	Oid          string `json:"oid"`
+	Organization string `json:"organization,omitempty"`
+	ContactInfo  string `json:"contactInfo,omitempty"`
+}
+
+// IdentityDifference holds the differences between the MODULE-IDENTITY of
+// fork and mainline. Only Fork or Mainline is set if the other side has none.
+type IdentityDifference struct {
+	Fork     *IdentityValue         `json:"fork,omitempty"`
+	Mainline *IdentityValue         `json:"mainline,omitempty"`
+	Diffs    []ModuleInfoDifference `json:"diffs,omitempty"`
+}
+
+// ImportValue is a symbol imported from a module.
+type ImportValue struct {
+	Module string `json:"module"`
+	Name   string `json:"name"`
+}
+
+// ImportMove is a symbol fork and mainline import from different modules.
+type ImportMove struct {
+	Name     string `json:"name"`
+	Fork     string `json:"fork"`
+	Mainline string `json:"mainline"`
+}
+
+// RevisionValue is a REVISION clause, its date formatted as YYYY-MM-DD HH:MM.
+type RevisionValue struct {
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+// RevisionDifference is a revision whose description differs.
+type RevisionDifference struct {
+	Date        string    `json:"date"`
+	Description ValuePair `json:"description"`
 }
 
 // DirComparisonResult holds the comparison result for a single file within a directory scan.
@@ -106,6 +218,7 @@ type DirComparisonResult struct {
 	MainlineError    error
 	ForkDuration     time.Duration
 	MainlineDuration time.Duration
+	Differences      *ComparisonResults // Set when both sides loaded
 }
 
 // --- Helper functions related to types (moved from compare.go for locality) ---
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/simdata"
+)
+
+type arrayStrings []string
+
+func (a arrayStrings) String() string {
+	return strings.Join(a, ",")
+}
+
+func (a *arrayStrings) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+
+	var modules, paths arrayStrings
+	flag.Var(&modules, "m", "Module to generate data for (repeatable)")
+	flag.Var(&paths, "p", "Path to add to the MIB search path (repeatable)")
+	format := flag.String("format", "snmprec", "Output format: snmprec or snmpd")
+	rows := flag.Int("rows", simdata.DefaultRows, "Number of rows to generate per table")
+	flag.Parse()
+
+	if len(modules) == 0 {
+		log.Fatal("Error: at least one -m module must be specified")
+	}
+	write := simdata.WriteSnmprec
+	switch *format {
+	case "snmprec":
+	case "snmpd":
+		write = simdata.WriteSnmpdConf
+	default:
+		log.Fatalf("Error: invalid -format %q. Must be 'snmprec' or 'snmpd'", *format)
+	}
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	for _, path := range paths {
+		gosmi.AppendPath(path)
+	}
+
+	var loaded []gosmi.SmiModule
+	for _, name := range modules {
+		moduleName, err := gosmi.LoadModule(name)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		module, err := gosmi.GetModule(moduleName)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		loaded = append(loaded, module)
+	}
+
+	records, err := simdata.Generate(loaded, simdata.WithRows(*rows))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := write(os.Stdout, records); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/lukeod/gosmi/lsp"
+)
+
+type arrayStrings []string
+
+func (a arrayStrings) String() string {
+	return strings.Join(a, ",")
+}
+
+func (a *arrayStrings) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// smilsp is a language server for MIB files, speaking the Language Server
+// Protocol on its standard input and output. Logs go to standard error.
+func main() {
+	log.SetFlags(0)
+
+	var paths arrayStrings
+	flag.Var(&paths, "p", "Path to add to the MIB search path (repeatable)")
+	flag.Parse()
+
+	server := lsp.NewServer(lsp.WithPath(paths...))
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+//go:build js && wasm
+
+// Command wasm exposes gosmi to JavaScript, for validating MIBs in the
+// browser. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o gosmi.wasm ./cmd/wasm
+//
+// and run it with the wasm_exec.js of the Go distribution. It defines a
+// global gosmi object whose functions return JSON strings:
+//
+//	gosmi.load({"ACME-MIB": "ACME-MIB DEFINITIONS ::= BEGIN ..."})
+//	gosmi.lint("ACME-MIB", "ACME-MIB DEFINITIONS ::= BEGIN ...")
+//	gosmi.translate("ACME-MIB::acmeName.0")
+//
+// load replaces the loaded modules with those of the files given, which can
+// import from each other, lint checks a single file without loading it, and
+// translate resolves a name or OID against the loaded modules.
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+type loadResult struct {
+	Modules     []string          `json:"modules"`
+	Diagnostics []diag.Diagnostic `json:"diagnostics"`
+	Errors      []string          `json:"errors,omitempty"`
+}
+
+type lintResult struct {
+	Diagnostics []diag.Diagnostic `json:"diagnostics"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type translateResult struct {
+	Name     string    `json:"name,omitempty"`
+	Module   string    `json:"module,omitempty"`
+	Oid      string    `json:"oid,omitempty"`
+	Instance types.Oid `json:"instance,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func main() {
+	gosmi.Init()
+	js.Global().Set("gosmi", js.ValueOf(map[string]interface{}{
+		"load":      js.FuncOf(load),
+		"lint":      js.FuncOf(lintFile),
+		"translate": js.FuncOf(translate),
+	}))
+	// Keep the functions callable
+	select {}
+}
+
+// load loads the files of an object mapping file names to their contents.
+func load(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 || args[0].Type() != js.TypeObject {
+		return toJSON(loadResult{Errors: []string{"Expected an object of files"}})
+	}
+	files := memFS{}
+	keys := js.Global().Get("Object").Call("keys", args[0])
+	names := make([]string, keys.Length())
+	for i := range names {
+		names[i] = keys.Index(i).String()
+		files[names[i]] = []byte(args[0].Get(names[i]).String())
+	}
+
+	gosmi.Exit()
+	gosmi.Init()
+	gosmi.SetFS(gosmi.NamedFS("Browser", files))
+	result := loadResult{Modules: []string{}}
+	for _, name := range names {
+		module, err := gosmi.LoadModule(name)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Modules = append(result.Modules, module)
+	}
+	result.Diagnostics = append([]diag.Diagnostic{}, gosmi.GetDiagnostics()...)
+	return toJSON(result)
+}
+
+// lintFile parses and lints a file given its name and contents.
+func lintFile(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 {
+		return toJSON(lintResult{Error: "Expected a file name and contents"})
+	}
+	filename := args[0].String()
+	var collector diag.Collector
+	module, err := parser.ParseBytes(filename, []byte(args[1].String()), parser.WithDiagnostics(&collector))
+	diagnostics := append([]diag.Diagnostic{}, collector.Diagnostics()...)
+	if err == nil {
+		diagnostics = append(diagnostics, lint.Check(filename, module)...)
+	}
+	return toJSON(lintResult{Diagnostics: diagnostics})
+}
+
+// translate resolves a name or OID, such as IF-MIB::ifInOctets.3 or
+// 1.3.6.1.2.1.2.2.1.10.3, to its node and instance.
+func translate(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return toJSON(translateResult{Error: "Expected a name or OID"})
+	}
+	node, instance, err := gosmi.ParseOID(args[0].String())
+	if err != nil {
+		return toJSON(translateResult{Error: err.Error()})
+	}
+	return toJSON(translateResult{
+		Name:     node.Name,
+		Module:   node.GetModule().Name,
+		Oid:      node.RenderNumeric(),
+		Instance: instance,
+	})
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return string(b)
+}
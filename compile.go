@@ -0,0 +1,618 @@
+package gosmi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/smi"
+)
+
+// DefaultCompileExtensions are the file extensions CompileDir treats as MIB
+// files when no extensions are given. The empty string matches files without
+// an extension.
+var DefaultCompileExtensions = []string{".mib", ".txt", ""}
+
+type compileConfig struct {
+	ctx        context.Context
+	workers    int
+	extensions []string
+	sniff      bool
+	index      bool
+	dedup      bool
+	progress   func(Progress)
+}
+
+func newCompileConfig(opts []CompileOption) compileConfig {
+	cfg := compileConfig{ctx: context.Background(), extensions: DefaultCompileExtensions, sniff: true, index: true, dedup: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.workers < 1 {
+		cfg.workers = runtime.NumCPU()
+	}
+	return cfg
+}
+
+// CompileOption configures CompileDir.
+type CompileOption func(*compileConfig)
+
+// WithWorkers sets the number of files parsed concurrently. Values below 1
+// use runtime.NumCPU().
+func WithWorkers(n int) CompileOption {
+	return func(c *compileConfig) { c.workers = n }
+}
+
+// WithExtensions sets the file extensions, compared case-insensitively, that
+// identify MIB files.
+func WithExtensions(ext ...string) CompileOption {
+	return func(c *compileConfig) { c.extensions = ext }
+}
+
+// WithSniff sets whether CompileDir passes over the files with a MIB
+// extension that do not look like MIB modules, as told by parser.SniffFile,
+// such as READMEs and licenses. It does by default.
+func WithSniff(sniff bool) CompileOption {
+	return func(c *compileConfig) { c.sniff = sniff }
+}
+
+// WithIndex sets whether CompileDir uses the index of the directory written
+// by WriteDirIndex, if any, and writes it again when out of date. It does by
+// default.
+func WithIndex(use bool) CompileOption {
+	return func(c *compileConfig) { c.index = use }
+}
+
+// WithDedup sets whether CompileDir passes over the files with the same
+// content as one found earlier in walk order, as copies of MIBs shipped in
+// several vendor packs often are, listing them in Report.Duplicates rather
+// than compiling them. It does by default.
+func WithDedup(dedup bool) CompileOption {
+	return func(c *compileConfig) { c.dedup = dedup }
+}
+
+// WithContext makes CompileDir stop once ctx is done.
+func WithContext(ctx context.Context) CompileOption {
+	return func(c *compileConfig) { c.ctx = ctx }
+}
+
+// WithProgress sets a function called as CompileDir discovers, parses and
+// resolves files. Calls are never concurrent, and the function should return
+// quickly as parse workers wait for it.
+func WithProgress(fn func(Progress)) CompileOption {
+	return func(c *compileConfig) { c.progress = fn }
+}
+
+// CompileStage is the step of CompileDir a Progress update is about.
+type CompileStage int
+
+const (
+	// StageDiscovered follows the directory walk, with Discovered set to
+	// the number of MIB files found.
+	StageDiscovered CompileStage = iota
+	// StageParsed follows parsing a file, in completion order.
+	StageParsed
+	// StageResolved follows resolving the module of a file. Files that
+	// failed to parse are not resolved, and files whose module is provided
+	// by an earlier file fail at this stage before the others resolve.
+	StageResolved
+)
+
+func (s CompileStage) String() string {
+	switch s {
+	case StageDiscovered:
+		return "discovered"
+	case StageParsed:
+		return "parsed"
+	case StageResolved:
+		return "resolved"
+	}
+	return fmt.Sprintf("CompileStage(%d)", int(s))
+}
+
+// Progress is a snapshot of the counters of a CompileDir call. Failed counts
+// the files that had errors in any stage so far, each once.
+type Progress struct {
+	Stage      CompileStage
+	Path       string // the file just parsed or resolved
+	Discovered int
+	Parsed     int
+	Resolved   int
+	Failed     int
+}
+
+// progressTracker keeps the counters behind WithProgress.
+type progressTracker struct {
+	mu     sync.Mutex
+	fn     func(Progress)
+	state  Progress
+	failed []bool
+}
+
+func (p *progressTracker) discovered(n int) {
+	if p.fn == nil {
+		return
+	}
+	p.failed = make([]bool, n)
+	p.state.Discovered = n
+	p.report(StageDiscovered, "")
+}
+
+// done records file i completing stage, failing if it has errors.
+func (p *progressTracker) done(stage CompileStage, i int, file *FileReport) {
+	if p.fn == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch stage {
+	case StageParsed:
+		p.state.Parsed++
+	case StageResolved:
+		p.state.Resolved++
+	}
+	if !file.OK() && !p.failed[i] {
+		p.failed[i] = true
+		p.state.Failed++
+	}
+	p.report(stage, file.Path)
+}
+
+func (p *progressTracker) report(stage CompileStage, path string) {
+	p.state.Stage = stage
+	p.state.Path = path
+	p.fn(p.state)
+}
+
+// FileReport is the outcome of compiling a single file. Diagnostics holds
+// what the lexer, parser and resolver reported while compiling it, including
+// for modules it caused to be loaded from the search path.
+type FileReport struct {
+	Path          string
+	Module        string
+	Diagnostics   []diag.Diagnostic
+	ParseDuration time.Duration
+	// LexDuration is the part of ParseDuration spent lexing.
+	LexDuration     time.Duration
+	ResolveDuration time.Duration
+}
+
+// OK reports whether the file compiled without error diagnostics.
+func (r FileReport) OK() bool { return !diag.HasErrors(r.Diagnostics) }
+
+// Report is the outcome of CompileDir, with one entry per MIB file found in
+// walk order.
+type Report struct {
+	Dir   string
+	Files []FileReport
+	// Skipped are the files with a MIB extension that were passed over as
+	// they do not look like MIB modules. They are not known when the files
+	// are listed by the index of the directory.
+	Skipped []string
+	// Duplicates are the files passed over as copies of a file found
+	// earlier; see WithDedup.
+	Duplicates []DuplicateFile
+	// Indexed is set when the files were listed by the index of the
+	// directory, rather than found by walking it.
+	Indexed  bool
+	Duration time.Duration
+}
+
+// DuplicateFile is a file with the same content as the file at Original.
+type DuplicateFile struct {
+	Path     string
+	Original string
+}
+
+// Failed returns the reports of files that had errors.
+func (r Report) Failed() (files []FileReport) {
+	for _, f := range r.Files {
+		if !f.OK() {
+			files = append(files, f)
+		}
+	}
+	return
+}
+
+// NearDuplicates returns the paths of the files compiled for each module
+// provided by several of them, in walk order. Such files differ in content,
+// copies being passed over as duplicates, typically as different revisions
+// or local edits of the module.
+func (r Report) NearDuplicates() map[string][]string {
+	paths := make(map[string][]string)
+	for _, f := range r.Files {
+		if f.Module != "" {
+			paths[f.Module] = append(paths[f.Module], f.Path)
+		}
+	}
+	for module, files := range paths {
+		if len(files) < 2 {
+			delete(paths, module)
+		}
+	}
+	return paths
+}
+
+// CompileDir walks dir, parses every MIB file concurrently and loads the
+// resulting modules into the current handle, resolving the modules they
+// import. Imports are satisfied from the compiled files first and from the
+// search path or the fetcher otherwise, and failures to load them are handled
+// according to the dependency mode. When several files define the same
+// module, the version policy picks one. Under VersionFirst the first in walk
+// order wins and the others are reported as errors; files passed over by
+// another policy or a preferred path get a warning. Files with a MIB
+// extension that do not look like MIB modules are listed as skipped, copies
+// of files found earlier are listed as duplicates, and files excluded with
+// SetExcludedFiles are passed over. If dir has an index
+// written by WriteDirIndex, the files it lists are compiled without walking
+// dir, unless its directories changed since, and the index is written again
+// if out of date; see WithIndex.
+//
+// Parsing is done by a bounded pool of workers; resolution is sequential. The
+// returned error is only set if the directory cannot be walked or the context
+// given with WithContext is done; per-file problems are recorded in the
+// report. When cancelled, the report holds the files parsed up to that point,
+// and the modules resolved so far stay loaded.
+func CompileDir(dir string, opts ...CompileOption) (Report, error) {
+	cfg := newCompileConfig(opts)
+	ctx := cfg.ctx
+
+	start := time.Now()
+	report := Report{Dir: dir}
+	var idx *DirIndex
+	var indexed bool
+	if cfg.index {
+		var err error
+		idx, err = ReadDirIndex(dir)
+		indexed = !errors.Is(err, os.ErrNotExist)
+	}
+	// The MIB files found, before exclusions, and the modification times
+	// of the directories walked for them
+	var found []string
+	var dirs map[string]int64
+	var err error
+	if report.Indexed = idx != nil && idx.fresh(ctx, dir); report.Indexed {
+		found, dirs = idx.paths(dir), idx.Dirs
+	} else {
+		dirs = make(map[string]int64)
+		found, err = findCompileFiles(ctx, dir, cfg.extensions, dirs)
+		if err == nil && cfg.sniff {
+			found, report.Skipped = sniffCompileFiles(found)
+		}
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return report, ctxErr
+		}
+		return report, fmt.Errorf("Walk directory: %w", err)
+	}
+	var paths []string
+	for _, path := range found {
+		if !smi.IsExcludedFile(indexPath(dir, path)) {
+			paths = append(paths, path)
+		}
+	}
+	// The hashes of the files' content by path, for the index and to find
+	// duplicates
+	var hashes map[string]fileHash
+	if indexed || cfg.dedup {
+		// Files listed by a fresh index are hashed again only if modified
+		var known map[string]IndexedFile
+		var written int64
+		if report.Indexed {
+			known, written = idx.files(dir), idx.written
+		}
+		hashes = hashCompileFiles(ctx, paths, cfg.workers, known, written)
+		if err := ctx.Err(); err != nil {
+			report.Duration = time.Since(start)
+			return report, err
+		}
+	}
+	listed := paths
+	if cfg.dedup {
+		paths, report.Duplicates = dedupCompileFiles(paths, hashes)
+	}
+
+	progress := progressTracker{fn: cfg.progress}
+	progress.discovered(len(paths))
+
+	report.Files = make([]FileReport, len(paths))
+	modules := make([]*parser.Module, len(paths))
+	parsed := make([]bool, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				modules[i], report.Files[i] = parseCompileFile(ctx, paths[i])
+				parsed[i] = ctx.Err() == nil
+				if parsed[i] {
+					progress.done(StageParsed, i, &report.Files[i])
+				}
+			}
+		}()
+	}
+feed:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		report.Files = parsedFiles(report.Files, parsed)
+		report.Duration = time.Since(start)
+		return report, err
+	}
+	if indexed && !upToDate(idx, report.Indexed, dir, listed, hashes) {
+		// The index is a cache: failing to write it only costs the next
+		// compile a walk
+		_ = updateDirIndex(dir, found, dirs, report, hashes)
+	}
+
+	// Files providing the same module, in walk order
+	var names []string
+	providers := make(map[string][]int)
+	for i, module := range modules {
+		if module == nil {
+			continue
+		}
+		name := module.Name.String()
+		if _, ok := providers[name]; !ok {
+			names = append(names, name)
+		}
+		providers[name] = append(providers[name], i)
+	}
+	for _, name := range names {
+		files := providers[name]
+		versions := make([]smi.ModuleVersion, len(files))
+		for j, i := range files {
+			versions[j] = smi.NewModuleVersion(paths[i], modules[i])
+			versions[j].Timing = parser.Timing{Lex: report.Files[i].LexDuration, Parse: report.Files[i].ParseDuration - report.Files[i].LexDuration}
+		}
+		selected, existing, err := smi.AddModuleVersions(versions)
+		for j, i := range files {
+			if j == selected && existing == "" {
+				continue
+			}
+			file := &report.Files[i]
+			d := diag.Diagnostic{
+				Severity: diag.SeverityError,
+				Pos:      diag.Position{Filename: file.Path},
+				Code:     diag.CodeDuplicateModule,
+				Module:   file.Module,
+			}
+			switch {
+			case err != nil:
+				d.Message = fmt.Sprintf("Select module version: %v", err)
+			case existing != "":
+				d.Message = fmt.Sprintf("Module %s already provided by %s", file.Module, existing)
+			case selected == 0 && smi.GetVersionPolicy() == smi.VersionFirst:
+				d.Message = fmt.Sprintf("Module %s already provided by %s", file.Module, paths[files[0]])
+			default:
+				// Passed over on purpose, by policy or preferred path
+				d.Severity = diag.SeverityWarning
+				d.Code = diag.CodeModuleSuperseded
+				d.Message = fmt.Sprintf("Module %s provided by %s instead", file.Module, paths[files[selected]])
+			}
+			file.Diagnostics = append(file.Diagnostics, d)
+			modules[i] = nil
+			progress.done(StageResolved, i, file)
+		}
+	}
+
+	diagnostics := smi.Diagnostics()
+	for i, module := range modules {
+		if module == nil {
+			continue
+		}
+		file := &report.Files[i]
+		resolveStart := time.Now()
+		n := diagnostics.Len()
+		_, err := smi.ResolveModuleContext(ctx, file.Module)
+		file.Diagnostics = append(file.Diagnostics, diagnostics.Since(n)...)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			file.ResolveDuration = time.Since(resolveStart)
+			report.Duration = time.Since(start)
+			return report, ctxErr
+		}
+		if err != nil && !diag.HasErrors(file.Diagnostics) {
+			file.Diagnostics = append(file.Diagnostics, diag.Diagnostic{
+				Severity: diag.SeverityError,
+				Pos:      diag.Position{Filename: file.Path},
+				Code:     diag.CodeModuleNotLoaded,
+				Message:  fmt.Sprintf("Resolve module: %v", err),
+				Module:   file.Module,
+			})
+		}
+		file.ResolveDuration = time.Since(resolveStart)
+		progress.done(StageResolved, i, file)
+	}
+
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// parsedFiles returns the reports of the files whose parse completed.
+func parsedFiles(files []FileReport, parsed []bool) []FileReport {
+	out := files[:0]
+	for i, f := range files {
+		if parsed[i] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// parseCompileFile parses the file at path.
+func parseCompileFile(ctx context.Context, path string) (*parser.Module, FileReport) {
+	report := FileReport{Path: path}
+	var diagnostics diag.Collector
+	var timing parser.Timing
+	start := time.Now()
+	opts := []parser.Option{
+		parser.WithContext(ctx),
+		parser.WithDiagnostics(&diagnostics),
+		parser.WithLimits(smi.GetLimits()),
+		parser.WithDialect(smi.GetDialects()...),
+		parser.WithPreParse(smi.PreParse),
+		parser.WithTiming(&timing),
+		parser.WithTextWhitespace(smi.GetTextNormalization().Whitespace),
+	}
+	if smi.GetTextNormalization().Escapes {
+		opts = append(opts, parser.WithEscapes())
+	}
+	if smi.GetWrappedQuoted() {
+		opts = append(opts, parser.WithWrappedQuoted())
+	}
+	if smi.GetRecovery() {
+		opts = append(opts, parser.WithRecovery())
+	}
+	if smi.GetStrict() {
+		opts = append(opts, parser.WithStrict())
+	}
+	module, err := parser.ParseFile(path, opts...)
+	report.ParseDuration = time.Since(start)
+	report.LexDuration = timing.Lex
+	if metrics := smi.GetMetrics(); metrics != nil {
+		metrics.Observe(smi.MetricParseSeconds, report.ParseDuration.Seconds())
+	}
+	report.Diagnostics = diagnostics.Diagnostics()
+	if module != nil {
+		report.Module = module.Name.String()
+	}
+	if err != nil {
+		if !diag.HasErrors(report.Diagnostics) {
+			// Reading the file failed before parsing started
+			report.Diagnostics = append(report.Diagnostics, diag.Diagnostic{
+				Severity: diag.SeverityError,
+				Pos:      diag.Position{Filename: path},
+				Code:     diag.CodeFileUnreadable,
+				Message:  err.Error(),
+			})
+		}
+		return nil, report
+	}
+	return module, report
+}
+
+// hashCompileFiles returns the hashes of the content of the files at paths
+// by path, reading them concurrently unless known, as hashFile does. Files
+// that cannot be read are left out, for their parse to report the error.
+func hashCompileFiles(ctx context.Context, paths []string, workers int, known map[string]IndexedFile, written int64) map[string]fileHash {
+	hashes := make(map[string]fileHash, len(paths))
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				if hash, err := hashFile(path, known[path], written); err == nil {
+					mu.Lock()
+					hashes[path] = hash
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+feed:
+	for _, path := range paths {
+		select {
+		case jobs <- path:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return hashes
+}
+
+// dedupCompileFiles splits paths into the first file with each content, in
+// order, and the copies of those.
+func dedupCompileFiles(paths []string, hashes map[string]fileHash) (unique []string, duplicates []DuplicateFile) {
+	first := make(map[string]string, len(paths))
+	for _, path := range paths {
+		h, ok := hashes[path]
+		if !ok {
+			unique = append(unique, path)
+			continue
+		}
+		if original, ok := first[h.hash]; ok {
+			duplicates = append(duplicates, DuplicateFile{Path: path, Original: original})
+			continue
+		}
+		first[h.hash] = path
+		unique = append(unique, path)
+	}
+	return
+}
+
+// sniffCompileFiles splits paths into the files that look like MIB modules
+// and the others. Files that cannot be read are kept, for their parse to
+// report the error.
+func sniffCompileFiles(paths []string) (mibs, skipped []string) {
+	mibs = paths[:0]
+	for _, path := range paths {
+		if ok, err := parser.SniffFile(path); ok || err != nil {
+			mibs = append(mibs, path)
+		} else {
+			skipped = append(skipped, path)
+		}
+	}
+	return
+}
+
+// findCompileFiles walks dir for the files with one of extensions, recording
+// the modification times of the directories walked in dirs.
+func findCompileFiles(ctx context.Context, dir string, extensions []string, dirs map[string]int64) (paths []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			dirs[indexPath(dir, path)] = info.ModTime().UnixNano()
+			return nil
+		}
+		if !d.Type().IsRegular() && d.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, e := range extensions {
+			if ext == strings.ToLower(e) {
+				paths = append(paths, path)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return
+}
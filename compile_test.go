@@ -0,0 +1,237 @@
+package gosmi_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compileBaseMib = `COMPILE-BASE-MIB DEFINITIONS ::= BEGIN
+compileBase OBJECT IDENTIFIER ::= { iso 9 }
+END
+`
+
+const compileAppMib = `COMPILE-APP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    compileBase FROM COMPILE-BASE-MIB;
+compileApp OBJECT IDENTIFIER ::= { compileBase 1 }
+END
+`
+
+func writeCompileFiles(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestCompileDir(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"app/COMPILE-APP-MIB.txt": compileAppMib,
+		"base/COMPILE-BASE-MIB":   compileBaseMib,
+		"copy/COMPILE-BASE-MIB":   compileBaseMib + "-- local copy\n",
+		"broken.mib":              "BROKEN-MIB DEFINITIONS ::= BEGIN",
+		"README.md":               "not a MIB",
+		"LICENSE.txt":             "Licensed under the terms of the license.",
+		".git/HEAD.mib":           "ignored",
+	})
+
+	report, err := gosmi.CompileDir(dir, gosmi.WithWorkers(2))
+	require.NoError(t, err)
+	require.Len(t, report.Files, 4)
+	assert.Equal(t, []string{filepath.Join(dir, "LICENSE.txt")}, report.Skipped)
+
+	files := make(map[string]gosmi.FileReport)
+	for _, f := range report.Files {
+		rel, err := filepath.Rel(dir, f.Path)
+		require.NoError(t, err)
+		files[filepath.ToSlash(rel)] = f
+	}
+
+	assert.True(t, files["app/COMPILE-APP-MIB.txt"].OK(), "%v", files["app/COMPILE-APP-MIB.txt"].Diagnostics)
+	assert.Equal(t, "COMPILE-APP-MIB", files["app/COMPILE-APP-MIB.txt"].Module)
+	assert.True(t, files["base/COMPILE-BASE-MIB"].OK(), "%v", files["base/COMPILE-BASE-MIB"].Diagnostics)
+	assert.False(t, files["copy/COMPILE-BASE-MIB"].OK())
+	require.False(t, files["broken.mib"].OK())
+	broken := files["broken.mib"].Diagnostics[0]
+	assert.Equal(t, diag.SeverityError, broken.Severity)
+	assert.Equal(t, diag.CodeSyntax, broken.Code)
+	assert.Equal(t, "BROKEN-MIB", broken.Module)
+	assert.Equal(t, 1, broken.Pos.Line)
+	assert.Len(t, report.Failed(), 2)
+
+	node, err := gosmi.GetNode("compileApp")
+	require.NoError(t, err)
+	assert.Equal(t, "1.9.1", node.RenderNumeric())
+}
+
+func TestCompileDirWithoutSniff(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"COMPILE-BASE-MIB": compileBaseMib,
+		"LICENSE.txt":      "Licensed under the terms of the license.",
+	})
+	report, err := gosmi.CompileDir(dir, gosmi.WithSniff(false))
+	require.NoError(t, err)
+	require.Len(t, report.Files, 2)
+	assert.Empty(t, report.Skipped)
+	assert.False(t, report.Files[1].OK())
+}
+
+func TestCompileDirLimits(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetLimits(gosmi.Limits{MaxBytes: len(compileBaseMib)})
+
+	dir := writeCompileFiles(t, map[string]string{
+		"COMPILE-BASE-MIB": compileBaseMib,
+		"LARGE-MIB":        compileBaseMib + strings.Repeat("-- padding\n", 10),
+	})
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	require.Len(t, report.Files, 2)
+	assert.True(t, report.Files[0].OK(), "%v", report.Files[0].Diagnostics)
+	require.False(t, report.Files[1].OK())
+	assert.Equal(t, diag.CodeLimitExceeded, report.Files[1].Diagnostics[0].Code)
+	assert.Equal(t, fmt.Sprintf("Module exceeds the limit of %d bytes", len(compileBaseMib)), report.Files[1].Diagnostics[0].Message)
+}
+
+func TestCompileDirContext(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"COMPILE-BASE-MIB": compileBaseMib,
+		"COMPILE-APP-MIB":  compileAppMib,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := gosmi.CompileDir(dir, gosmi.WithContext(ctx))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, report.Files)
+	assert.False(t, gosmi.IsLoaded("COMPILE-BASE-MIB"))
+
+	gosmi.SetPath(dir)
+	_, err = gosmi.LoadModuleContext(ctx, "COMPILE-APP-MIB")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, gosmi.IsLoaded("COMPILE-APP-MIB"))
+	_, err = gosmi.LoadModuleContext(context.Background(), "COMPILE-APP-MIB")
+	assert.NoError(t, err)
+}
+
+func TestCompileDirProgress(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"app/COMPILE-APP-MIB.txt": compileAppMib,
+		"base/COMPILE-BASE-MIB":   compileBaseMib,
+		"copy/COMPILE-BASE-MIB":   compileBaseMib + "-- local copy\n",
+		"broken.mib":              "BROKEN-MIB DEFINITIONS ::= BEGIN",
+	})
+
+	var updates []gosmi.Progress
+	report, err := gosmi.CompileDir(dir, gosmi.WithWorkers(2), gosmi.WithProgress(func(p gosmi.Progress) {
+		updates = append(updates, p)
+	}))
+	require.NoError(t, err)
+	require.Len(t, report.Files, 4)
+
+	// Discovery, four parses, then the duplicate and the two modules in walk
+	// order
+	require.Len(t, updates, 8)
+	assert.Equal(t, gosmi.Progress{Stage: gosmi.StageDiscovered, Discovered: 4}, updates[0])
+	for _, p := range updates[1:5] {
+		assert.Equal(t, gosmi.StageParsed, p.Stage)
+		assert.NotEmpty(t, p.Path)
+	}
+	assert.Equal(t, 4, updates[4].Parsed)
+	assert.Equal(t, 1, updates[4].Failed)
+	for _, p := range updates[5:] {
+		assert.Equal(t, gosmi.StageResolved, p.Stage)
+	}
+	assert.Equal(t, gosmi.Progress{Stage: gosmi.StageResolved, Path: report.Files[1].Path, Discovered: 4, Parsed: 4, Resolved: 3, Failed: 2}, updates[7])
+}
+
+func TestCompileDirDuplicates(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"app/COMPILE-APP-MIB.txt":      compileAppMib,
+		"base/COMPILE-BASE-MIB":        compileBaseMib,
+		"vendor-a/COMPILE-BASE-MIB":    compileBaseMib,
+		"vendor-b/COMPILE-BASE-MIB":    compileBaseMib + "-- local copy\n",
+		"vendor-b/COMPILE-APP-MIB.mib": compileAppMib,
+	})
+	path := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []gosmi.DuplicateFile{
+		{Path: path("vendor-a/COMPILE-BASE-MIB"), Original: path("base/COMPILE-BASE-MIB")},
+		{Path: path("vendor-b/COMPILE-APP-MIB.mib"), Original: path("app/COMPILE-APP-MIB.txt")},
+	}, report.Duplicates)
+	require.Len(t, report.Files, 3)
+	assert.Equal(t, map[string][]string{
+		"COMPILE-BASE-MIB": {path("base/COMPILE-BASE-MIB"), path("vendor-b/COMPILE-BASE-MIB")},
+	}, report.NearDuplicates())
+	require.Len(t, report.Failed(), 1)
+	assert.Equal(t, diag.CodeDuplicateModule, report.Failed()[0].Diagnostics[0].Code)
+
+	gosmi.Exit()
+	gosmi.Init()
+	report, err = gosmi.CompileDir(dir, gosmi.WithDedup(false))
+	require.NoError(t, err)
+	assert.Empty(t, report.Duplicates)
+	assert.Len(t, report.Files, 5)
+	assert.Len(t, report.Failed(), 3)
+}
+
+func TestCompileDirVersionPolicy(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetVersionPolicy(gosmi.VersionLatest)
+
+	dir := writeCompileFiles(t, map[string]string{
+		"a/VERSIONED-MIB": versionedMib("202401010000Z", "12"),
+		"b/VERSIONED-MIB": versionedMib("200001010000Z", "11"),
+		"c/VERSIONED-MIB": versionedMib("202501010000Z", "13"),
+	})
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	require.Len(t, report.Files, 3)
+	assert.Empty(t, report.Failed())
+	for _, f := range report.Files[:2] {
+		require.Len(t, f.Diagnostics, 1)
+		assert.Equal(t, diag.CodeModuleSuperseded, f.Diagnostics[0].Code)
+		assert.Equal(t, "Module VERSIONED-MIB provided by "+report.Files[2].Path+" instead", f.Diagnostics[0].Message)
+	}
+	assert.True(t, report.Files[2].OK())
+
+	node, err := gosmi.GetNode("versionedMib")
+	require.NoError(t, err)
+	assert.Equal(t, "1.13", node.RenderNumeric())
+
+	versions, err := gosmi.GetModuleVersions("VERSIONED-MIB")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.True(t, versions[2].Loaded)
+}
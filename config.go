@@ -3,7 +3,11 @@ package gosmi
 import (
 	"os"
 
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/mibs"
+	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
 )
 
 func Init() {
@@ -14,6 +18,9 @@ func Init() {
 
 func Exit() { smi.Exit() }
 
+// The search path lists directories and MIB archives (.zip, .tar, .tar.gz or
+// .tgz), separated by os.PathListSeparator. Modules in archives are found by
+// file name wherever they are nested, without extracting them.
 func GetPath() string         { return smi.GetPath() }
 func SetPath(path string)     { smi.SetPath(path) }
 func AppendPath(path string)  { smi.SetPath(string(os.PathListSeparator) + path) }
@@ -24,4 +31,142 @@ func SetFS(fs ...smi.NamedFS)                    { smi.SetFS(fs...) }
 func AppendFS(fs ...smi.NamedFS)                 { smi.AppendFS(fs...) }
 func PrependFS(fs ...smi.NamedFS)                { smi.PrependFS(fs...) }
 
+// LoadBaseModules appends the base modules of the SMI embedded in package
+// mibs, SNMPv2-SMI, SNMPv2-TC, SNMPv2-CONF, RFC1155-SMI, RFC-1212 and
+// RFC-1215, to the search path. They are loaded like modules found on disk,
+// when first imported, unless a directory earlier in the path provides them.
+// Setting the path with SetPath or SetFS drops them, so call it afterwards.
+func LoadBaseModules() { smi.AppendFS(smi.NewNamedFS("base modules", mibs.FS)) }
+
+type DependencyMode = smi.DependencyMode
+
+const (
+	DependencyWarn = smi.DependencyWarn
+	DependencyFail = smi.DependencyFail
+	DependencyStub = smi.DependencyStub
+)
+
+// SetDependencyMode sets whether LoadModule fails, only warns through the
+// error handler, or warns and substitutes stub symbols when a module imported
+// directly or transitively by the loaded module cannot be found.
+func SetDependencyMode(mode DependencyMode) { smi.SetDependencyMode(mode) }
+
+// Limits bounds the resources parsing a single module may take.
+type Limits = parser.Limits
+
+// SetLimits sets the limits enforced while parsing modules, by LoadModule and
+// CompileDir alike, so that services loading untrusted MIBs cannot be
+// exhausted by pathological inputs. Modules exceeding a limit fail to load
+// with a diag.CodeLimitExceeded diagnostic.
+func SetLimits(limits Limits) { smi.SetLimits(limits) }
+
+// SetCoreTypes sets whether the fundamental types of the SMI, Integer32,
+// Counter32, Counter64, Gauge32, Unsigned32, TimeTicks, IpAddress and Opaque
+// of SNMPv2-SMI, and Counter and Gauge of RFC1155-SMI, resolve to built-in
+// definitions when the module they are imported from cannot be loaded or is
+// stubbed, so that the structure of modules resolves without the base
+// modules. BITS and OBJECT IDENTIFIER are part of ASN.1 and always resolve.
+// The anchors of the OID tree, such as enterprises, can be made available
+// alike with RegisterSNMPRootNodes.
+func SetCoreTypes(enabled bool) { smi.SetCoreTypes(enabled) }
+
+// SetDialects sets the vendor dialects modules are parsed with, by name as
+// registered with parser.RegisterDialect. The clauses a dialect adds to the
+// standard macros are kept as parser.Extension values of the definitions in
+// the AST of the module, instead of failing the parse. Modules fail to load
+// if a dialect is not registered.
+func SetDialects(names ...string) { smi.SetDialects(names...) }
+
+// SetWrappedQuoted sets whether modules loaded from now on, by LoadModule and
+// CompileDir alike, may wrap hex and bin strings across lines, as some
+// generated MIBs do, with a warning instead of failing to load, see
+// parser.WithWrappedQuoted.
+func SetWrappedQuoted(wrapped bool) { smi.SetWrappedQuoted(wrapped) }
+
+// SetRecovery sets whether modules loaded from now on, by LoadModule and
+// CompileDir alike, load without the definitions having illegal characters,
+// each reported by a warning, instead of failing to load, see
+// parser.WithRecovery.
+func SetRecovery(recovery bool) { smi.SetRecovery(recovery) }
+
+// SetRetainAST sets whether loaded modules keep the AST they were built from,
+// as returned by SmiModule.AST, which they do by default. Services that only
+// need the resolved modules can save memory by discarding it: identifiers and
+// texts of the resolved modules are interned, so the source of each module is
+// freed once built. Only strings are shared between modules, not types, and
+// they are released with the last module holding them when unloaded.
+func SetRetainAST(retain bool) { smi.SetRetainAST(retain) }
+
+// SetLazyText sets whether modules loaded from now on skip storing the
+// DESCRIPTION, REFERENCE and CONTACT-INFO texts of their definitions, for
+// deployments that only need the OID and type structure. The texts are then
+// empty until loaded on demand with LoadText, which parses the module's file
+// again; revision descriptions are still stored. As identical texts are
+// stored once anyway, this saves the most on modules whose texts differ.
+func SetLazyText(lazy bool) { smi.SetLazyText(lazy) }
+
+// TextNormalization controls how the DESCRIPTION, REFERENCE, CONTACT-INFO
+// and REVISION texts of modules are rewritten before they are stored: to a
+// Unicode normalization form such as NFC, without control characters, or,
+// in strict mode, as printable ASCII. Its Whitespace chooses whether their
+// whitespace is normalized, as by default, kept as written with
+// parser.TextRaw or treated as libsmi does with parser.TextLibsmi, and its
+// Escapes whether backslash escapes are interpreted, see parser.WithEscapes.
+type TextNormalization = smi.TextNormalization
+
+// SetTextNormalization sets how the texts of modules loaded from now on are
+// normalized. gosmi has no Unicode tables of its own, so NFC takes a
+// normalizer such as norm.NFC.String of golang.org/x/text/unicode/norm:
+//
+//	gosmi.SetTextNormalization(gosmi.TextNormalization{Normalize: norm.NFC.String, StripControl: true})
+func SetTextNormalization(normalization TextNormalization) { smi.SetTextNormalization(normalization) }
+
+// SetFetcher sets where modules that are not found in the search path are
+// fetched from while loading modules and their imports, such as a
+// fetch.Fetcher downloading them from MIB repositories. A nil fetcher
+// disables fetching.
+func SetFetcher(fetcher smi.Fetcher) { smi.SetFetcher(fetcher) }
+
+type PreParseHook = smi.PreParseHook
+type PostResolveHook = smi.PostResolveHook
+type SymbolResolver = smi.SymbolResolver
+
+// AddPreParseHook adds a hook rewriting the source of every module before it
+// is parsed, by LoadModule and CompileDir alike, such as to fix up vendor
+// syntax. Hooks run in the order they were added and are dropped by Exit.
+func AddPreParseHook(hook PreParseHook) { smi.AddPreParseHook(hook) }
+
+// AddPostResolveHook adds a hook called with every module once it is built,
+// for telemetry or to enforce policies: a module the hook fails is unloaded
+// and fails to load.
+func AddPostResolveHook(hook PostResolveHook) { smi.AddPostResolveHook(hook) }
+
+// SetSymbolResolver sets the resolver mapping type names that modules use
+// without defining or importing them, such as private types, to types of
+// other modules. A nil resolver removes the mapping.
+func SetSymbolResolver(resolver SymbolResolver) { smi.SetSymbolResolver(resolver) }
+
+// GetDiagnostics returns the diagnostics reported by the lexer, parser and
+// resolver while loading modules since Init or the last ClearDiagnostics.
+func GetDiagnostics() []diag.Diagnostic { return smi.Diagnostics().Diagnostics() }
+func ClearDiagnostics()                 { smi.Diagnostics().Reset() }
+
+type RootNode = smi.RootNode
+
+// RegisterRootNode adds a well-known node, such as enterprises, which modules
+// can refer to by name without importing it, so that modules referencing
+// anchors from unavailable modules still resolve. The node is created in the
+// current handle if initialized and in every later one. Registering a name
+// again with a different OID fails.
+func RegisterRootNode(name string, oid types.Oid) error { return smi.RegisterRootNode(name, oid) }
+
+// RegisterSNMPRootNodes registers the well-known anchors of the internet
+// subtree defined by SNMPv2-SMI and RFC1155-SMI, from org to snmpV2.
+func RegisterSNMPRootNodes() error { return smi.RegisterSNMPRootNodes() }
+
+func GetRootNodes() []RootNode { return smi.GetRootNodes() }
+func ResetRootNodes()          { smi.ResetRootNodes() }
+
+func SetErrorHandler(handler types.SmiErrorHandler) { smi.SetErrorHandler(handler) }
+
 func ReadConfig(filename string, tag ...string) error { return smi.ReadConfig(filename, tag...) }
@@ -0,0 +1,189 @@
+package gosmi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames are the names FindConfig looks for, in order.
+var ConfigFileNames = []string{"gosmi.yaml", "gosmi.yml", "gosmi.toml"}
+
+// Config is the environment of gosmi-based tools, kept in a gosmi.yaml or
+// gosmi.toml file so that a team shares it through its repository:
+//
+//	paths: [mibs, vendor/mibs]
+//	aliases:
+//	  RFC1213-MIB: rfc1213.mib
+//	strict: true
+//	exclude: ["*.bak", "drafts/*"]
+//	output:
+//	  oid-format: module
+type Config struct {
+	// Paths are the directories and MIB archives prepended to the search
+	// path, in order. Relative paths are relative to the config file, and a
+	// leading ~ is the home directory.
+	Paths []string `yaml:"paths"`
+	// Aliases are the names of the files providing modules not named after
+	// them, see SetModuleAliases.
+	Aliases map[string]string `yaml:"aliases"`
+	// Strict parses modules in strict mode, see SetStrict.
+	Strict bool `yaml:"strict"`
+	// Exclude are the glob patterns of the files never loaded, see
+	// SetExcludedFiles.
+	Exclude []string `yaml:"exclude"`
+	// Output are defaults for the output of tools such as mibdump, which
+	// apply them to the options not given on their command line.
+	Output OutputConfig `yaml:"output"`
+}
+
+// OutputConfig holds the output defaults of a Config.
+type OutputConfig struct {
+	// OIDFormat is the format of OIDs, as accepted by
+	// types.OidFormatFromString.
+	OIDFormat string `yaml:"oid-format"`
+	// DiagFormat is the format of diagnostics, such as text or json.
+	DiagFormat string `yaml:"diag-format"`
+}
+
+// FindConfig returns the path of the first of ConfigFileNames found in dir or
+// the closest of its parents, or "" if there is none.
+func FindConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("Get absolute path: %w", err)
+	}
+	for {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ParseConfigFile reads the config at configPath without applying it. Unknown
+// keys are rejected. Files ending in .toml are read as TOML and others as
+// YAML. Of TOML, only what configs need is read: bare or quoted keys, one
+// level of [table] headers, basic and literal strings, booleans, decimal
+// integers and floats, and arrays of those, which may span lines. Files
+// using the rest of TOML are rejected: dotted keys and table names, arrays of
+// tables, inline tables, multi-line strings, dates and times, and hex, octal
+// or binary integers.
+func ParseConfigFile(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("Read config: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(configPath), ".toml") {
+		values, err := decodeTOML(data)
+		if err != nil {
+			return nil, fmt.Errorf("Parse config %s: %w", configPath, err)
+		}
+		// Decoded as YAML, for the same field mapping and checks
+		if data, err = yaml.Marshal(values); err != nil {
+			return nil, fmt.Errorf("Parse config %s: %w", configPath, err)
+		}
+	}
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("Parse config %s: %w", configPath, err)
+	}
+	if cfg.Output.OIDFormat != "" {
+		if _, err := types.OidFormatFromString(cfg.Output.OIDFormat); err != nil {
+			return nil, fmt.Errorf("Parse config %s: %w", configPath, err)
+		}
+	}
+	for i, p := range cfg.Paths {
+		if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("Parse config %s: %w", configPath, err)
+			}
+			cfg.Paths[i] = filepath.Join(home, p[1:])
+		} else if !filepath.IsAbs(p) {
+			cfg.Paths[i] = filepath.Join(filepath.Dir(configPath), p)
+		}
+	}
+	return &cfg, nil
+}
+
+// Apply sets up the current handle as configured: the search paths are
+// prepended to the search path and the aliases, strict mode and excluded
+// files replace those set before. Call it again after Init for a new handle.
+func (c *Config) Apply() error {
+	if err := SetExcludedFiles(c.Exclude...); err != nil {
+		return err
+	}
+	for i := len(c.Paths) - 1; i >= 0; i-- {
+		PrependPath(c.Paths[i])
+	}
+	SetModuleAliases(c.Aliases)
+	SetStrict(c.Strict)
+	return nil
+}
+
+// IsExcluded returns whether the file at name, relative to the directory it
+// is found in, matches one of the Exclude patterns, for tools finding MIB
+// files of their own.
+func (c *Config) IsExcluded(name string) bool {
+	name = filepath.ToSlash(name)
+	for _, pattern := range c.Exclude {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig reads the config at path and applies it to the current handle,
+// returning it for the output defaults.
+func LoadConfig(path string) (*Config, error) {
+	cfg, err := ParseConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Apply(); err != nil {
+		return nil, fmt.Errorf("Apply config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SetStrict sets whether modules loaded from now on, by LoadModule and
+// CompileDir alike, are parsed in strict mode, failing to load if they use
+// constructs the parser accepts for the sake of real world MIBs although SMI
+// does not allow them, see parser.WithStrict.
+func SetStrict(strict bool) { smi.SetStrict(strict) }
+
+// SetModuleAliases sets the names of the files providing modules that are not
+// named after them, keyed by module name, such as rfc1213.mib for
+// RFC1213-MIB. An alias is looked up in the search path as a file name, or
+// with the usual MIB extensions, when no file is named after the module.
+func SetModuleAliases(aliases map[string]string) { smi.SetModuleAliases(aliases) }
+
+// SetExcludedFiles sets the glob patterns, as of path.Match, of the files
+// that are never loaded from the search path nor compiled by CompileDir, such
+// as backups or drafts. A pattern without a slash matches file names, one
+// with a slash the path relative to the directory searched or compiled.
+func SetExcludedFiles(patterns ...string) error { return smi.SetExcludedFiles(patterns) }
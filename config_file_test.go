@@ -0,0 +1,145 @@
+package gosmi_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configYAML = `# Shared settings
+paths: [mibs, /opt/mibs, ~/mibs]
+aliases:
+  COMPILE-BASE-MIB: base.mib
+strict: true
+exclude: ["OLD-*", "drafts/*"]
+output:
+  oid-format: module
+  diag-format: json
+`
+
+const configTOML = `# Shared settings
+paths = [
+    "mibs", # next to the config
+    "/opt/mibs",
+    "~/mibs",
+]
+strict = true
+exclude = ['OLD-*', "drafts/*"]
+
+[aliases]
+"COMPILE-BASE-MIB" = "base.mib"
+
+[output]
+oid-format = "module"
+diag-format = "json"
+`
+
+const configStrictMib = `STRICT-MIB DEFINITIONS ::= BEGIN
+StrictEnum ::= INTEGER { one(1), two(2), }
+END
+`
+
+func TestParseConfigFile(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"gosmi.yaml": configYAML,
+		"gosmi.toml": configTOML,
+	})
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	expected := &gosmi.Config{
+		Paths:   []string{filepath.Join(dir, "mibs"), "/opt/mibs", filepath.Join(home, "mibs")},
+		Aliases: map[string]string{"COMPILE-BASE-MIB": "base.mib"},
+		Strict:  true,
+		Exclude: []string{"OLD-*", "drafts/*"},
+		Output:  gosmi.OutputConfig{OIDFormat: "module", DiagFormat: "json"},
+	}
+	for _, name := range []string{"gosmi.yaml", "gosmi.toml"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := gosmi.ParseConfigFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"unknown.yaml": "paths: [mibs]\nstrictness: true\n",
+		"format.yaml":  "output:\n  oid-format: dotted\n",
+		"value.yaml":   "strict: [yes]\n",
+		"array.yaml":   "paths: [mibs\n",
+		"header.toml":  "[[paths]]\n",
+		"value.toml":   "strict = yes\n",
+		"array.toml":   "paths = [\"mibs\"\n",
+		"dotted.toml":  "output.oid-format = \"module\"\n",
+		"inline.toml":  "output = { oid-format = \"module\" }\n",
+		"multi.toml":   "paths = [\"\"\"mibs\"\"\"]\n",
+		"date.toml":    "strict = 2024-01-01\n",
+		"hex.toml":     "strict = 0x1\n",
+	})
+	for _, name := range []string{"unknown.yaml", "format.yaml", "value.yaml", "array.yaml", "header.toml", "value.toml", "array.toml", "dotted.toml", "inline.toml", "multi.toml", "date.toml", "hex.toml"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := gosmi.ParseConfigFile(filepath.Join(dir, name))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+
+	dir := writeCompileFiles(t, map[string]string{
+		"gosmi.yaml":                 configYAML,
+		"mibs/base.mib":              compileBaseMib,
+		"mibs/COMPILE-APP-MIB":       compileAppMib,
+		"mibs/OLD-MIB.mib":           "OLD-MIB DEFINITIONS ::= BEGIN\nEND\n",
+		"mibs/STRICT-MIB":            configStrictMib,
+		"mibs/drafts/DRAFT-MIB.mib":  "DRAFT-MIB DEFINITIONS ::= BEGIN\nEND\n",
+		"mibs/drafts/README.txt.mib": "not a MIB",
+	})
+	cfg, err := gosmi.LoadConfig(filepath.Join(dir, "gosmi.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "module", cfg.Output.OIDFormat)
+
+	// Found through the alias of the module it imports
+	_, err = gosmi.LoadModule("COMPILE-APP-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("COMPILE-BASE-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "mibs", "base.mib"), module.Path)
+
+	_, err = gosmi.LoadModule("OLD-MIB")
+	assert.Error(t, err, "excluded")
+	_, err = gosmi.LoadModule("STRICT-MIB")
+	assert.Error(t, err, "strict")
+
+	report, err := gosmi.CompileDir(filepath.Join(dir, "mibs"))
+	require.NoError(t, err)
+	var paths []string
+	for _, f := range report.Files {
+		rel, err := filepath.Rel(dir, f.Path)
+		require.NoError(t, err)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	assert.ElementsMatch(t, []string{"mibs/base.mib", "mibs/COMPILE-APP-MIB", "mibs/STRICT-MIB"}, paths)
+}
+
+func TestFindConfig(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"gosmi.toml":   configTOML,
+		"a/b/file.mib": compileBaseMib,
+	})
+	path, err := gosmi.FindConfig(filepath.Join(dir, "a", "b"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "gosmi.toml"), path)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a", "gosmi.yml"), []byte(configYAML), 0o644))
+	path, err = gosmi.FindConfig(filepath.Join(dir, "a", "b"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a", "gosmi.yml"), path)
+}
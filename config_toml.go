@@ -0,0 +1,174 @@
+package gosmi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeTOML decodes the subset of TOML config files need: key/value pairs
+// at the top level and in [table] sections, with string, boolean, integer,
+// float and array values.
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	table := root
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("Line %d: unsupported table header %s", lineNo, line)
+			}
+			name, err := tomlKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %w", lineNo, err)
+			}
+			if _, ok := root[name]; ok {
+				return nil, fmt.Errorf("Line %d: table %s defined twice", lineNo, name)
+			}
+			table = make(map[string]interface{})
+			root[name] = table
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("Line %d: expected key = value", lineNo)
+		}
+		key, err := tomlKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %w", lineNo, err)
+		}
+		value := strings.TrimSpace(line[eq+1:])
+		// Arrays may continue over the following lines
+		for strings.HasPrefix(value, "[") && !tomlArrayClosed(value) && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		v, rest, err := tomlValue(value)
+		if err == nil && strings.TrimSpace(rest) != "" {
+			err = fmt.Errorf("unexpected %q after value", strings.TrimSpace(rest))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %w", lineNo, err)
+		}
+		if _, ok := table[key]; ok {
+			return nil, fmt.Errorf("Line %d: key %s defined twice", lineNo, key)
+		}
+		table[key] = v
+	}
+	return root, nil
+}
+
+// stripTOMLComment returns line without a trailing comment, leaving hashes in
+// strings alone.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == 0 && c == '#':
+			return line[:i]
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return line
+}
+
+func tomlArrayClosed(value string) bool {
+	_, _, err := tomlValue(value)
+	return err == nil
+}
+
+func tomlKey(key string) (string, error) {
+	if strings.HasPrefix(key, `"`) || strings.HasPrefix(key, "'") {
+		v, rest, err := tomlString(key)
+		if err != nil || rest != "" {
+			return "", fmt.Errorf("invalid key %s", key)
+		}
+		return v, nil
+	}
+	if key == "" || strings.IndexFunc(key, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) >= 0 {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return key, nil
+}
+
+// tomlValue decodes the value at the start of s, returning the rest of s.
+func tomlValue(s string) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	switch {
+	case s == "":
+		return nil, "", fmt.Errorf("missing value")
+	case s[0] == '"' || s[0] == '\'':
+		return tomlString(s)
+	case s[0] == '[':
+		var values []interface{}
+		s = strings.TrimLeft(s[1:], " \t")
+		for !strings.HasPrefix(s, "]") {
+			v, rest, err := tomlValue(s)
+			if err != nil {
+				return nil, "", err
+			}
+			values = append(values, v)
+			s = strings.TrimLeft(rest, " \t")
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " \t")
+			} else if !strings.HasPrefix(s, "]") {
+				return nil, "", fmt.Errorf("unterminated array")
+			}
+		}
+		return values, s[1:], nil
+	}
+	end := strings.IndexAny(s, " \t,]")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := s[:end], s[end:]
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	if n, err := strconv.ParseInt(strings.ReplaceAll(word, "_", ""), 10, 64); err == nil {
+		return n, rest, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(word, "_", ""), 64); err == nil {
+		return f, rest, nil
+	}
+	return nil, "", fmt.Errorf("unsupported value %q", word)
+}
+
+// tomlString decodes the basic or literal string at the start of s.
+func tomlString(s string) (string, string, error) {
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return v, s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
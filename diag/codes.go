@@ -0,0 +1,71 @@
+package diag
+
+// Stable diagnostic codes. The letter after the prefix is the default
+// severity (E, W or I), the first digit the layer reporting it: 1 lexer,
+// 2 parser and module structure, 3 resolver, 4 lint. Codes are never reused,
+// so gaps in the numbering are retired or reserved codes.
+const (
+	CodeUnterminatedString  = "GOSMI-E1001"
+	CodeIllegalCharacter    = "GOSMI-E1002"
+	CodeUnterminatedQuoted  = "GOSMI-E1003"
+	CodeInvalidQuotedDigit  = "GOSMI-E1004"
+	CodeInvalidQuotedSuffix = "GOSMI-E1005"
+	CodeInvalidASN1Tag      = "GOSMI-E1006"
+	CodeNumberOutOfRange    = "GOSMI-E1007"
+	CodeLimitExceeded       = "GOSMI-E1008"
+	CodeWrappedQuoted       = "GOSMI-W1009"
+	CodeSyntax              = "GOSMI-E2001"
+	CodeRangeOrder          = "GOSMI-W2005"
+	CodeTCDerivedFromTC     = "GOSMI-W2006"
+	CodeRangeWidened        = "GOSMI-E2007"
+	CodeParserPanic         = "GOSMI-E2008"
+	CodeNonConformant       = "GOSMI-E2009"
+	CodeSkippedTokens       = "GOSMI-W2010"
+	CodeFileUnreadable      = "GOSMI-E2011"
+	CodeImportNotFound      = "GOSMI-W3001"
+	CodeImportCycle         = "GOSMI-W3002"
+	CodeImportStubbed       = "GOSMI-W3003"
+	CodeUnknownOidParent    = "GOSMI-W3004"
+	CodeDuplicateModule     = "GOSMI-E3005"
+	CodeModuleNotLoaded     = "GOSMI-E3006"
+	CodeDependencyNotLoaded = "GOSMI-E3007"
+	CodeUnknownType         = "GOSMI-E3008"
+	CodeResolverPanic       = "GOSMI-E3009"
+	CodeModuleSuperseded    = "GOSMI-W3010"
+	CodeUnknownMacro        = "GOSMI-I3011"
+	CodeDuplicateDefinition = "GOSMI-W3012"
+	CodeNotExported         = "GOSMI-W3013"
+	CodeUnusedImport        = "GOSMI-W4001"
+	CodeUndefinedSymbol     = "GOSMI-E4002"
+	CodeImportWrongModule   = "GOSMI-W4003"
+	CodeDuplicateEnumValue  = "GOSMI-E4004"
+	CodeDuplicateEnumLabel  = "GOSMI-E4005"
+	CodeNegativeBit         = "GOSMI-E4006"
+	CodeBitTooLarge         = "GOSMI-W4007"
+	CodeBitGap              = "GOSMI-W4008"
+	CodeInvalidDate         = "GOSMI-E4009"
+	CodeLastUpdatedMismatch = "GOSMI-W4010"
+	CodeDuplicateRevision   = "GOSMI-E4011"
+	CodeRevisionOrder       = "GOSMI-W4012"
+	CodeRevisionDescription = "GOSMI-W4013"
+	CodeMixedLanguage       = "GOSMI-W4014"
+	CodeNonASCIIText        = "GOSMI-W4015"
+	CodeUndefinedExport     = "GOSMI-E4016"
+	CodeBackslashText       = "GOSMI-W4017"
+)
+
+// CodeSeverity returns the default severity encoded in code, or SeverityError
+// if code is not of the GOSMI-X0000 form.
+func CodeSeverity(code string) Severity {
+	const prefix = "GOSMI-"
+	if len(code) <= len(prefix) || code[:len(prefix)] != prefix {
+		return SeverityError
+	}
+	switch code[len(prefix)] {
+	case 'W':
+		return SeverityWarning
+	case 'I':
+		return SeverityInfo
+	}
+	return SeverityError
+}
@@ -0,0 +1,137 @@
+// Package diag defines the diagnostics reported by the lexer, parser and
+// resolver, and a collector that gathers them.
+package diag
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Position is a location in a source file. Line and Column are 1-based, a zero
+// Line means the position is unknown.
+type Position struct {
+	Filename string `json:"file,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.Filename
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.Filename, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// Diagnostic is a single finding about a module.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Pos      Position `json:"pos"`
+	Code     string   `json:"code,omitempty"`
+	Message  string   `json:"message"`
+	Module   string   `json:"module,omitempty"`
+	// Fix suggests how to address the finding, if there is an obvious way.
+	Fix string `json:"fix,omitempty"`
+	// Related are other positions involved in the finding, such as that of
+	// an earlier definition of the same name.
+	Related []Position `json:"related,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	s := d.Severity.String()
+	if d.Code != "" {
+		s += " " + d.Code
+	}
+	s += ": " + d.Message
+	if d.Fix != "" {
+		s += " (" + d.Fix + ")"
+	}
+	if pos := d.Pos.String(); pos != "" {
+		return pos + ": " + s
+	}
+	return s
+}
+
+// Error implements error, so diagnostics of error severity can be returned
+// as errors.
+func (d Diagnostic) Error() string { return d.String() }
+
+// Collector gathers diagnostics. It is safe for concurrent use, and a nil
+// *Collector discards everything added to it.
+type Collector struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+}
+
+// Add appends diagnostics to the collector.
+func (c *Collector) Add(d ...Diagnostic) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.diagnostics = append(c.diagnostics, d...)
+	c.mu.Unlock()
+}
+
+// Len returns the number of diagnostics collected so far.
+func (c *Collector) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.diagnostics)
+}
+
+// Diagnostics returns a copy of the diagnostics collected so far, in the
+// order they were added.
+func (c *Collector) Diagnostics() []Diagnostic {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Diagnostic(nil), c.diagnostics...)
+}
+
+// Since returns a copy of the diagnostics added after the first n, so that
+// callers can pick out what a single operation reported using Len beforehand.
+func (c *Collector) Since(n int) []Diagnostic {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n >= len(c.diagnostics) {
+		return nil
+	}
+	return append([]Diagnostic(nil), c.diagnostics[n:]...)
+}
+
+// Reset discards all collected diagnostics.
+func (c *Collector) Reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.diagnostics = nil
+	c.mu.Unlock()
+}
+
+// HasErrors reports whether any diagnostic of error severity was collected.
+func (c *Collector) HasErrors() bool {
+	return HasErrors(c.Diagnostics())
+}
+
+// HasErrors reports whether any of the diagnostics has error severity.
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
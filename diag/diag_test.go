@@ -0,0 +1,100 @@
+package diag_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeSeverity(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected diag.Severity
+	}{
+		{diag.CodeUnterminatedString, diag.SeverityError},
+		{diag.CodeRangeOrder, diag.SeverityWarning},
+		{"GOSMI-I9999", diag.SeverityInfo},
+		{"", diag.SeverityError},
+		{"OTHER-W1", diag.SeverityError},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, diag.CodeSeverity(tt.code), tt.code)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	d := diag.Diagnostic{
+		Severity: diag.SeverityWarning,
+		Pos:      diag.Position{Filename: "TEST-MIB", Line: 12, Column: 5},
+		Code:     diag.CodeRangeOrder,
+		Message:  "Ranges are not in ascending order",
+		Module:   "TEST-MIB",
+	}
+	assert.Equal(t, "TEST-MIB:12:5: Warning GOSMI-W2005: Ranges are not in ascending order", d.String())
+
+	d.Pos = diag.Position{}
+	d.Code = ""
+	assert.Equal(t, "Warning: Ranges are not in ascending order", d.String())
+}
+
+func TestDiagnosticJSON(t *testing.T) {
+	d := diag.Diagnostic{
+		Severity: diag.SeverityError,
+		Pos:      diag.Position{Filename: "TEST-MIB", Offset: 40, Line: 3, Column: 7},
+		Code:     diag.CodeSyntax,
+		Message:  "unexpected token",
+		Module:   "TEST-MIB",
+	}
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"severity": "Error",
+		"pos": {"file": "TEST-MIB", "offset": 40, "line": 3, "column": 7},
+		"code": "GOSMI-E2001",
+		"message": "unexpected token",
+		"module": "TEST-MIB"
+	}`, string(b))
+
+	var out diag.Diagnostic
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, d, out)
+}
+
+func TestCollector(t *testing.T) {
+	var c diag.Collector
+	c.Add(diag.Diagnostic{Severity: diag.SeverityWarning, Message: "first"})
+	n := c.Len()
+	c.Add(diag.Diagnostic{Severity: diag.SeverityError, Message: "second"})
+
+	assert.Equal(t, 2, c.Len())
+	assert.True(t, c.HasErrors())
+	since := c.Since(n)
+	require.Len(t, since, 1)
+	assert.Equal(t, "second", since[0].Message)
+
+	c.Reset()
+	assert.Equal(t, 0, c.Len())
+	assert.False(t, c.HasErrors())
+
+	var nilCollector *diag.Collector
+	nilCollector.Add(diag.Diagnostic{Message: "dropped"})
+	assert.Nil(t, nilCollector.Diagnostics())
+}
+
+func TestRecover(t *testing.T) {
+	parse := func() (err error) {
+		defer diag.Recover("BAD-MIB", &err)
+		var m map[string]int
+		m["x"]++
+		return nil
+	}
+	err := parse()
+	var panicErr *diag.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "BAD-MIB", panicErr.Filename)
+	assert.Contains(t, err.Error(), "Internal error in BAD-MIB: assignment to entry in nil map")
+	assert.NotEmpty(t, panicErr.Stack)
+}
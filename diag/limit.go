@@ -0,0 +1,24 @@
+package diag
+
+import "fmt"
+
+// LimitExceededError is returned when a module exceeds one of the resource
+// limits configured for parsing it. Pos is where the limit was exceeded, or
+// just the file if the limit applies to the module as a whole.
+type LimitExceededError struct {
+	Pos   Position
+	Limit string
+	Max   int
+}
+
+func (e *LimitExceededError) Error() string {
+	if pos := e.Pos.String(); pos != "" {
+		return pos + ": " + e.Message()
+	}
+	return e.Message()
+}
+
+// Message returns the error message without the position.
+func (e *LimitExceededError) Message() string {
+	return fmt.Sprintf("Module exceeds the limit of %d %s", e.Max, e.Limit)
+}
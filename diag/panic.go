@@ -0,0 +1,33 @@
+package diag
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned by the parser and resolver entry points instead of
+// letting a panic caused by malformed input escape. It always indicates a
+// bug in gosmi, and Stack holds the stack trace of the panic for reporting
+// it.
+type PanicError struct {
+	Filename string
+	Value    interface{}
+	Stack    []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("Internal error: %v", e.Value)
+	}
+	return fmt.Sprintf("Internal error in %s: %v", e.Filename, e.Value)
+}
+
+// Recover converts a panic in progress into a *PanicError stored in err. It
+// must be deferred directly by a function with a named error result:
+//
+//	defer diag.Recover(filename, &err)
+func Recover(filename string, err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Filename: filename, Value: r, Stack: debug.Stack()}
+	}
+}
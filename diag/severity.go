@@ -0,0 +1,12 @@
+package diag
+
+//go:generate enumer -type=Severity -autotrimprefix -json
+
+// Severity grades a diagnostic. Lower values are more severe.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
@@ -0,0 +1,262 @@
+package gosmi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lukeod/gosmi/parser"
+)
+
+// IndexFileName is the name of the index of a MIB directory, at its root.
+const IndexFileName = ".gosmi-index"
+
+// dirIndexVersion is the version of the index format written.
+const dirIndexVersion = 1
+
+// DirIndex lists the MIB files of a directory with the modules they define,
+// so that CompileDir need not walk the directory and sniff its files to find
+// them, nor module lookups in the search path list it. It is kept in
+// IndexFileName at the root of the directory.
+type DirIndex struct {
+	Version int `json:"version"`
+	// Dirs are the modification times, in Unix nanoseconds, of the
+	// directories walked, keyed by slash separated path relative to the
+	// root. Adding or removing files changes them.
+	Dirs  map[string]int64 `json:"dirs"`
+	Files []IndexedFile    `json:"files"`
+
+	// written is the modification time of the index file, in Unix
+	// nanoseconds. Files modified as late are hashed again, as they may
+	// have been modified after they were hashed within the same tick.
+	written int64
+}
+
+// IndexedFile is a MIB file of a DirIndex.
+type IndexedFile struct {
+	// Path is the slash separated path of the file relative to the root.
+	Path string `json:"path"`
+	// Module is the name of the module the file defines, empty if unknown.
+	Module string `json:"module,omitempty"`
+	// Hash is the hex encoded SHA-256 of the file's content.
+	Hash string `json:"hash"`
+	// ModTime is the modification time, in Unix nanoseconds, of the file
+	// when it was hashed. CompileDir only hashes it again if it changed.
+	ModTime int64 `json:"mtime,omitempty"`
+}
+
+// ReadDirIndex reads the index of dir. The error wraps os.ErrNotExist if dir
+// has none.
+func ReadDirIndex(dir string) (*DirIndex, error) {
+	path := filepath.Join(dir, IndexFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Read index: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Read index: %w", err)
+	}
+	var idx DirIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("Parse index: %w", err)
+	}
+	if idx.Version != dirIndexVersion {
+		return nil, fmt.Errorf("Unsupported index version %d", idx.Version)
+	}
+	idx.written = info.ModTime().UnixNano()
+	return &idx, nil
+}
+
+// WriteDirIndex walks dir for MIB files as CompileDir does, with the same
+// extension and sniff options, and writes their index to IndexFileName in
+// dir. CompileDir then uses the index instead of walking dir, and writes it
+// again when it finds it out of date.
+func WriteDirIndex(dir string, opts ...CompileOption) (*DirIndex, error) {
+	cfg := newCompileConfig(opts)
+	dirs := make(map[string]int64)
+	paths, err := findCompileFiles(cfg.ctx, dir, cfg.extensions, dirs)
+	if err == nil && cfg.sniff {
+		paths, _ = sniffCompileFiles(paths)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Walk directory: %w", err)
+	}
+	idx := &DirIndex{Version: dirIndexVersion, Dirs: dirs}
+	for _, path := range paths {
+		entry, err := readIndexedFile(dir, path)
+		if err != nil {
+			return nil, fmt.Errorf("Read file: %w", err)
+		}
+		idx.Files = append(idx.Files, entry)
+	}
+	if err := idx.write(dir); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// write writes the index to dir. Creating the index file changes the
+// modification time of dir, which is then recorded, rewriting the file in
+// place.
+func (idx *DirIndex) write(dir string) error {
+	for rewrite := 0; rewrite < 2; rewrite++ {
+		data, err := json.MarshalIndent(idx, "", "  ")
+		if err != nil {
+			return fmt.Errorf("Encode index: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, IndexFileName), append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("Write index: %w", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("Write index: %w", err)
+		}
+		if mtime := info.ModTime().UnixNano(); idx.Dirs["."] != mtime {
+			idx.Dirs["."] = mtime
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+// fresh reports whether none of the directories of the index changed since
+// it was written, so that it lists all the files. Files changed in place are
+// found by their modification time and hash.
+func (idx *DirIndex) fresh(ctx context.Context, dir string) bool {
+	for rel, mtime := range idx.Dirs {
+		if ctx.Err() != nil {
+			return false
+		}
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil || !info.IsDir() || info.ModTime().UnixNano() != mtime {
+			return false
+		}
+	}
+	return len(idx.Dirs) > 0
+}
+
+// paths returns the paths of the indexed files.
+func (idx *DirIndex) paths(dir string) []string {
+	paths := make([]string, len(idx.Files))
+	for i, f := range idx.Files {
+		paths[i] = filepath.Join(dir, filepath.FromSlash(f.Path))
+	}
+	return paths
+}
+
+// files returns the indexed files by path.
+func (idx *DirIndex) files(dir string) map[string]IndexedFile {
+	files := make(map[string]IndexedFile, len(idx.Files))
+	for _, f := range idx.Files {
+		files[filepath.Join(dir, filepath.FromSlash(f.Path))] = f
+	}
+	return files
+}
+
+// readIndexedFile reads the file at path in dir for its index entry.
+func readIndexedFile(dir, path string) (IndexedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return IndexedFile{}, err
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return IndexedFile{}, err
+	}
+	return IndexedFile{
+		Path:    indexPath(dir, path),
+		Module:  parser.SniffName(src),
+		Hash:    hashSource(src),
+		ModTime: info.ModTime().UnixNano(),
+	}, nil
+}
+
+func indexPath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		path = rel
+	}
+	return filepath.ToSlash(path)
+}
+
+func hashSource(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileHash is the hash of the content of a file, as hashSource returns it,
+// with the modification time of the file it was taken at.
+type fileHash struct {
+	hash  string
+	mtime int64
+}
+
+// hashFile returns the hash of the content of the file at path. It is taken
+// from the entry known for the file if the file was not modified since, and
+// not as late as written, the time the entry was.
+func hashFile(path string, known IndexedFile, written int64) (fileHash, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileHash{}, err
+	}
+	mtime := info.ModTime().UnixNano()
+	if known.Hash != "" && known.ModTime == mtime && mtime < written {
+		return fileHash{hash: known.Hash, mtime: mtime}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fileHash{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fileHash{}, err
+	}
+	return fileHash{hash: hex.EncodeToString(h.Sum(nil)), mtime: mtime}, nil
+}
+
+// upToDate reports whether the index lists the files at paths with the
+// hashes and modification times they were read with. An index out of date
+// is also one not used.
+func upToDate(idx *DirIndex, used bool, dir string, paths []string, hashes map[string]fileHash) bool {
+	if !used {
+		return false
+	}
+	known := idx.files(dir)
+	for _, path := range paths {
+		h, ok := hashes[path]
+		if f := known[path]; !ok || h.hash != f.Hash || h.mtime != f.ModTime {
+			return false
+		}
+	}
+	return true
+}
+
+// updateDirIndex writes the index of the files found in dir, taking the
+// modules of those compiled, and of their duplicates, from the report and
+// the hashes read, and reading the others, which were excluded.
+func updateDirIndex(dir string, found []string, dirs map[string]int64, report Report, hashes map[string]fileHash) error {
+	modules := make(map[string]string, len(report.Files)+len(report.Duplicates))
+	for _, f := range report.Files {
+		modules[f.Path] = f.Module
+	}
+	for _, d := range report.Duplicates {
+		modules[d.Path] = modules[d.Original]
+	}
+	idx := &DirIndex{Version: dirIndexVersion, Dirs: dirs}
+	for _, path := range found {
+		module, compiled := modules[path]
+		if h, ok := hashes[path]; ok && compiled {
+			idx.Files = append(idx.Files, IndexedFile{Path: indexPath(dir, path), Module: module, Hash: h.hash, ModTime: h.mtime})
+		} else if entry, err := readIndexedFile(dir, path); err == nil {
+			idx.Files = append(idx.Files, entry)
+		}
+	}
+	return idx.write(dir)
+}
@@ -0,0 +1,145 @@
+package gosmi_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func indexedModules(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	idx, err := gosmi.ReadDirIndex(dir)
+	require.NoError(t, err)
+	modules := make(map[string]string)
+	for _, f := range idx.Files {
+		modules[f.Path] = f.Module
+	}
+	return modules
+}
+
+func TestDirIndex(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"app/COMPILE-APP-MIB.txt": compileAppMib,
+		"base/COMPILE-BASE-MIB":   compileBaseMib,
+		"LICENSE.txt":             "Licensed under the terms of the license.",
+	})
+
+	_, err := gosmi.ReadDirIndex(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	idx, err := gosmi.WriteDirIndex(dir)
+	require.NoError(t, err)
+	require.Len(t, idx.Files, 2)
+	assert.Equal(t, "app/COMPILE-APP-MIB.txt", idx.Files[0].Path)
+	assert.Equal(t, "COMPILE-APP-MIB", idx.Files[0].Module)
+	assert.Len(t, idx.Files[0].Hash, 64)
+	assert.Equal(t, map[string]string{
+		"app/COMPILE-APP-MIB.txt": "COMPILE-APP-MIB",
+		"base/COMPILE-BASE-MIB":   "COMPILE-BASE-MIB",
+	}, indexedModules(t, dir))
+
+	compile := func(opts ...gosmi.CompileOption) gosmi.Report {
+		t.Helper()
+		gosmi.Init()
+		defer gosmi.Exit()
+		report, err := gosmi.CompileDir(dir, opts...)
+		require.NoError(t, err)
+		assert.Empty(t, report.Failed())
+		return report
+	}
+
+	report := compile()
+	assert.True(t, report.Indexed)
+	assert.Len(t, report.Files, 2)
+	assert.False(t, compile(gosmi.WithIndex(false)).Indexed)
+
+	// Changed in place, found by its hash
+	base := filepath.Join(dir, "base", "COMPILE-BASE-MIB")
+	before := idx.Files[1].Hash
+	require.NoError(t, os.WriteFile(base, []byte(compileBaseMib+"-- changed\n"), 0o644))
+	assert.True(t, compile().Indexed)
+	idx, err = gosmi.ReadDirIndex(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, before, idx.Files[1].Hash)
+
+	// Added, found as the directory changed
+	other := filepath.Join(dir, "base", "OTHER-MIB")
+	require.NoError(t, os.WriteFile(other, []byte("OTHER-MIB DEFINITIONS ::= BEGIN\nEND\n"), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Dir(other), later, later))
+	report = compile()
+	assert.False(t, report.Indexed)
+	assert.Len(t, report.Files, 3)
+	assert.Equal(t, "OTHER-MIB", indexedModules(t, dir)["base/OTHER-MIB"])
+	assert.True(t, compile().Indexed)
+}
+
+func TestDirIndexNotWritten(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"COMPILE-BASE-MIB": compileBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	assert.False(t, report.Indexed)
+	_, err = os.Stat(filepath.Join(dir, gosmi.IndexFileName))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestDirIndexUnmodifiedNotHashed(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"COMPILE-BASE-MIB": compileBaseMib,
+	})
+	base := filepath.Join(dir, "COMPILE-BASE-MIB")
+	earlier := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(base, earlier, earlier))
+	idx, err := gosmi.WriteDirIndex(dir)
+	require.NoError(t, err)
+	hash := idx.Files[0].Hash
+
+	// Changed with its modification time kept, so taken for unmodified
+	require.NoError(t, os.WriteFile(base, []byte(compileBaseMib+"-- changed\n"), 0o644))
+	require.NoError(t, os.Chtimes(base, earlier, earlier))
+	gosmi.Init()
+	defer gosmi.Exit()
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	assert.True(t, report.Indexed)
+	idx, err = gosmi.ReadDirIndex(dir)
+	require.NoError(t, err)
+	assert.Equal(t, hash, idx.Files[0].Hash)
+}
+
+func TestDirIndexLookup(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"base.mib":            compileBaseMib,
+		"sub/COMPILE-APP-MIB": compileAppMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("COMPILE-BASE-MIB")
+	assert.Error(t, err, "Expected no file named after the module without an index")
+
+	_, err = gosmi.WriteDirIndex(dir)
+	require.NoError(t, err)
+	name, err := gosmi.LoadModule("COMPILE-BASE-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, "COMPILE-BASE-MIB", name)
+	_, err = gosmi.LoadModule("COMPILE-APP-MIB")
+	assert.Error(t, err, "Expected subdirectories to be left out of the search path")
+
+	// Out of date once a file is added, and the directory listed instead
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "COMPILE-APP-MIB"), []byte(compileAppMib), 0o644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(dir, later, later))
+	name, err = gosmi.LoadModule("COMPILE-APP-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, "COMPILE-APP-MIB", name)
+}
@@ -0,0 +1,91 @@
+package gosmi
+
+import (
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// enum returns the enumeration or BITS labels of t, inherited from the types
+// it is derived from if it defines none itself.
+func (t SmiType) enum() *models.Enum {
+	if t.Enum != nil || t.smiType == nil {
+		return t.Enum
+	}
+	for _, parent := range t.BaseTypeChain() {
+		if parent.Enum != nil {
+			return parent.Enum
+		}
+	}
+	return nil
+}
+
+// EnumValue returns the value of the enumeration label or BITS position
+// named name, e.g. 1 for "up" in IF-MIB's ifOperStatus.
+func (t SmiType) EnumValue(name string) (int64, bool) {
+	if enum := t.enum(); enum != nil {
+		for _, v := range enum.Values {
+			if v.Name == name {
+				return v.Value, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// EnumName returns the enumeration label or BITS position with the given
+// value, the first one defined if several share it.
+func (t SmiType) EnumName(value int64) (string, bool) {
+	if enum := t.enum(); enum != nil {
+		for _, v := range enum.Values {
+			if v.Value == value {
+				return v.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// NamedNumberMatch is an enumeration label or BITS position found by
+// FindNamedNumbers. Node is set for labels defined inline in the SYNTAX of an
+// object rather than by a named type.
+type NamedNumberMatch struct {
+	Module      string
+	Type        SmiType
+	Node        *SmiNode
+	NamedNumber models.NamedNumber
+}
+
+// FindNamedNumbers searches the types and objects of the loaded modules for
+// enumeration labels and BITS positions named name, any name if empty, with
+// the given value if any, e.g. ("testing", 3) finds every definition of
+// testing(3).
+func FindNamedNumbers(name string, value ...int64) (matches []NamedNumberMatch) {
+	match := func(v models.NamedNumber) bool {
+		return (name == "" || v.Name == name) && (len(value) == 0 || v.Value == value[0])
+	}
+	for _, module := range GetLoadedModules() {
+		for _, t := range module.GetTypes() {
+			if t.Enum == nil || t.smiType.Decl == types.DeclImplicitType {
+				continue
+			}
+			for _, v := range t.Enum.Values {
+				if match(v) {
+					matches = append(matches, NamedNumberMatch{Module: module.Name, Type: t, NamedNumber: v})
+				}
+			}
+		}
+		for _, node := range module.GetNodes() {
+			if node.SmiType == nil || node.SmiType.smiType.Decl != types.DeclImplicitType || smi.GetFirstNamedNumber(node.SmiType.smiType) == nil {
+				continue
+			}
+			for _, v := range node.SmiType.Enum.Values {
+				if match(v) {
+					node := node
+					matches = append(matches, NamedNumberMatch{Module: module.Name, Type: *node.SmiType, Node: &node, NamedNumber: v})
+				}
+			}
+		}
+	}
+	return
+}
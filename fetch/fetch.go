@@ -0,0 +1,230 @@
+// Package fetch downloads modules that cannot be found in the search path
+// from MIB repositories over HTTP(S), such as vendor sites or internal
+// artifact stores. Downloaded modules are kept in an on-disk cache and can be
+// pinned to known checksums.
+//
+// A Fetcher is installed with gosmi.SetFetcher, after which loading a module
+// or any of its imports falls back to it:
+//
+//	gosmi.SetFetcher(fetch.New([]string{"https://mibs.example.com/{module}.mib"},
+//		fetch.WithCacheDir("/var/cache/mibs")))
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Placeholder is replaced by the module name in URL templates.
+const Placeholder = "{module}"
+
+// ErrChecksum is returned when a module does not have its pinned checksum.
+var ErrChecksum = errors.New("Checksum mismatch")
+
+// Fetcher fetches modules by name from a list of URL templates. It is safe
+// for concurrent use.
+type Fetcher struct {
+	templates []string
+	cacheDir  string
+	client    *http.Client
+	checksums map[string]string
+	maxBytes  int
+}
+
+// Option configures a Fetcher.
+type Option func(*Fetcher)
+
+// WithCacheDir stores fetched modules in dir, which is created if needed, and
+// serves later requests for them from there without going to the network.
+func WithCacheDir(dir string) Option {
+	return func(f *Fetcher) { f.cacheDir = dir }
+}
+
+// WithClient sets the HTTP client used for requests, for timeouts, proxies or
+// authentication. The default is http.DefaultClient.
+func WithClient(client *http.Client) Option {
+	return func(f *Fetcher) { f.client = client }
+}
+
+// WithChecksums pins modules to the hex encoded SHA-256 digests of their
+// files, keyed by module name. Pinned modules whose content differs, whether
+// fetched or cached, are rejected with ErrChecksum.
+func WithChecksums(checksums map[string]string) Option {
+	return func(f *Fetcher) {
+		f.checksums = make(map[string]string, len(checksums))
+		for name, sum := range checksums {
+			f.checksums[name] = strings.ToLower(sum)
+		}
+	}
+}
+
+// WithMaxBytes refuses modules larger than n bytes, without reading more
+// than that of a response or cached file, such as the MaxBytes of the
+// gosmi.Limits in use. Zero, the default, means no limit.
+func WithMaxBytes(n int) Option {
+	return func(f *Fetcher) { f.maxBytes = n }
+}
+
+// New returns a Fetcher trying templates in order, with Placeholder replaced
+// by the module name, e.g. "https://mibs.example.com/{module}.mib". Responses
+// with status 404 or 410 move on to the next template; other failures are
+// returned.
+func New(templates []string, opts ...Option) *Fetcher {
+	f := &Fetcher{
+		templates: templates,
+		client:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// FetchModule returns the file of the named module, from the cache if
+// possible. The returned path is the cached file or, without a cache, the URL
+// the module was fetched from. Modules no template provides yield an error
+// wrapping os.ErrNotExist.
+func (f *Fetcher) FetchModule(ctx context.Context, name string) (string, io.ReadCloser, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		// File names rather than module names, which only the search path
+		// can provide
+		return "", nil, fmt.Errorf("Fetch module %q: %w", name, os.ErrNotExist)
+	}
+
+	if f.cacheDir != "" {
+		path := filepath.Join(f.cacheDir, name)
+		b, err := f.readCached(path)
+		if errors.Is(err, errTooLarge) {
+			return path, nil, fmt.Errorf("Read cached module %s: %w", name, err)
+		}
+		if err == nil && f.verify(name, b) == nil {
+			return path, io.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+
+	for _, template := range f.templates {
+		u := strings.ReplaceAll(template, Placeholder, url.PathEscape(name))
+		b, err := f.get(ctx, u)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return u, nil, err
+		}
+		if err := f.verify(name, b); err != nil {
+			return u, nil, fmt.Errorf("Fetch %s: %w", u, err)
+		}
+		path := u
+		if f.cacheDir != "" {
+			if path, err = f.store(name, b); err != nil {
+				return u, nil, fmt.Errorf("Cache module %s: %w", name, err)
+			}
+		}
+		return path, io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return "", nil, fmt.Errorf("Fetch module %s: %w", name, os.ErrNotExist)
+}
+
+func (f *Fetcher) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Create request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, os.ErrNotExist
+	default:
+		return nil, fmt.Errorf("Fetch %s: %s", u, resp.Status)
+	}
+	b, err := f.read(resp.Body)
+	if errors.Is(err, errTooLarge) {
+		return nil, fmt.Errorf("Fetch %s: %w", u, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Read %s: %w", u, err)
+	}
+	return b, nil
+}
+
+// errTooLarge is returned for modules larger than the limit set with
+// WithMaxBytes.
+var errTooLarge = errors.New("module exceeds limit")
+
+// read reads the module from r, reading no more than one byte over the
+// limit, if any, which tells a module that is too large.
+func (f *Fetcher) read(r io.Reader) ([]byte, error) {
+	if f.maxBytes > 0 {
+		r = io.LimitReader(r, int64(f.maxBytes)+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if f.maxBytes > 0 && len(b) > f.maxBytes {
+		return nil, fmt.Errorf("%w of %d bytes", errTooLarge, f.maxBytes)
+	}
+	return b, nil
+}
+
+// readCached reads the cached module at path as read does.
+func (f *Fetcher) readCached(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return f.read(file)
+}
+
+func (f *Fetcher) verify(name string, b []byte) error {
+	want, ok := f.checksums[name]
+	if !ok {
+		return nil
+	}
+	sum := sha256.Sum256(b)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("%w: module %s has SHA-256 %s, want %s", ErrChecksum, name, got, want)
+	}
+	return nil
+}
+
+// store writes b to the cache through a temporary file, so that concurrent
+// readers never see a partial module.
+func (f *Fetcher) store(name string, b []byte) (string, error) {
+	if err := os.MkdirAll(f.cacheDir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(f.cacheDir, "."+name+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	path := filepath.Join(f.cacheDir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
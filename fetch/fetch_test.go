@@ -0,0 +1,151 @@
+package fetch_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/fetch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const remoteMib = `REMOTE-MIB DEFINITIONS ::= BEGIN
+remoteRoot OBJECT IDENTIFIER ::= { iso 8 }
+END
+`
+
+const localMib = `LOCAL-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    remoteRoot FROM REMOTE-MIB;
+localNode OBJECT IDENTIFIER ::= { remoteRoot 2 }
+END
+`
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newServer serves remoteMib at /mibs/REMOTE-MIB and counts requests.
+func newServer(t *testing.T) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/mibs/REMOTE-MIB":
+			io.WriteString(w, remoteMib)
+		case "/broken/REMOTE-MIB":
+			http.Error(w, "broken", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestFetchModule(t *testing.T) {
+	srv, requests := newServer(t)
+	cache := filepath.Join(t.TempDir(), "cache")
+	f := fetch.New([]string{srv.URL + "/missing/{module}", srv.URL + "/mibs/{module}"}, fetch.WithCacheDir(cache))
+
+	path, r, err := f.FetchModule(context.Background(), "REMOTE-MIB")
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, remoteMib, string(b))
+	assert.Equal(t, filepath.Join(cache, "REMOTE-MIB"), path)
+	assert.EqualValues(t, 2, atomic.LoadInt32(requests))
+
+	// Served from the cache
+	_, r, err = f.FetchModule(context.Background(), "REMOTE-MIB")
+	require.NoError(t, err)
+	b, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, remoteMib, string(b))
+	assert.EqualValues(t, 2, atomic.LoadInt32(requests))
+
+	_, _, err = f.FetchModule(context.Background(), "OTHER-MIB")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, _, err = f.FetchModule(context.Background(), "../REMOTE-MIB")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, _, err = fetch.New([]string{srv.URL + "/broken/{module}"}).FetchModule(context.Background(), "REMOTE-MIB")
+	assert.EqualError(t, err, "Fetch "+srv.URL+"/broken/REMOTE-MIB: 500 Internal Server Error")
+}
+
+func TestFetchModuleChecksum(t *testing.T) {
+	srv, requests := newServer(t)
+	cache := t.TempDir()
+	templates := []string{srv.URL + "/mibs/{module}"}
+
+	_, _, err := fetch.New(templates, fetch.WithCacheDir(cache), fetch.WithChecksums(map[string]string{
+		"REMOTE-MIB": sha256Hex("something else"),
+	})).FetchModule(context.Background(), "REMOTE-MIB")
+	assert.ErrorIs(t, err, fetch.ErrChecksum)
+	assert.NoFileExists(t, filepath.Join(cache, "REMOTE-MIB"))
+
+	// A tampered cache entry is fetched again
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "REMOTE-MIB"), []byte("tampered"), 0o644))
+	n := atomic.LoadInt32(requests)
+	_, r, err := fetch.New(templates, fetch.WithCacheDir(cache), fetch.WithChecksums(map[string]string{
+		"REMOTE-MIB": sha256Hex(remoteMib),
+	})).FetchModule(context.Background(), "REMOTE-MIB")
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, remoteMib, string(b))
+	assert.Equal(t, n+1, atomic.LoadInt32(requests))
+}
+
+func TestFetchModuleMaxBytes(t *testing.T) {
+	srv, _ := newServer(t)
+	cache := filepath.Join(t.TempDir(), "cache")
+
+	f := fetch.New([]string{srv.URL + "/mibs/{module}"}, fetch.WithCacheDir(cache), fetch.WithMaxBytes(len(remoteMib)-1))
+	_, _, err := f.FetchModule(context.Background(), "REMOTE-MIB")
+	assert.ErrorContains(t, err, "exceeds limit")
+	assert.NoFileExists(t, filepath.Join(cache, "REMOTE-MIB"))
+
+	f = fetch.New([]string{srv.URL + "/mibs/{module}"}, fetch.WithMaxBytes(len(remoteMib)))
+	_, r, err := f.FetchModule(context.Background(), "REMOTE-MIB")
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, remoteMib, string(b))
+
+	// Cached modules are held to the same limit
+	require.NoError(t, os.MkdirAll(cache, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cache, "REMOTE-MIB"), []byte(remoteMib+"-- padding\n"), 0o644))
+	f = fetch.New([]string{srv.URL + "/mibs/{module}"}, fetch.WithCacheDir(cache), fetch.WithMaxBytes(len(remoteMib)))
+	_, _, err = f.FetchModule(context.Background(), "REMOTE-MIB")
+	assert.ErrorContains(t, err, "exceeds limit")
+}
+
+func TestFetcherImports(t *testing.T) {
+	srv, _ := newServer(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "LOCAL-MIB"), []byte(localMib), 0o644))
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	gosmi.SetDependencyMode(gosmi.DependencyFail)
+	gosmi.SetFetcher(fetch.New([]string{srv.URL + "/mibs/{module}"}))
+
+	_, err := gosmi.LoadModule("LOCAL-MIB")
+	require.NoError(t, err)
+	assert.True(t, gosmi.IsLoaded("REMOTE-MIB"))
+	node, err := gosmi.GetNode("localNode")
+	require.NoError(t, err)
+	assert.Equal(t, "1.8.2", node.RenderNumeric())
+}
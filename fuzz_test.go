@@ -0,0 +1,45 @@
+package gosmi_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+)
+
+func FuzzCompileDir(f *testing.F) {
+	f.Add(chainMib)
+	f.Add(tableMib)
+	f.Add(nodeMib)
+	f.Add(trapMib)
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, input string) {
+		if err := os.WriteFile(filepath.Join(dir, "FUZZ-MIB"), []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gosmi.Init()
+		defer gosmi.Exit()
+		report, err := gosmi.CompileDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range report.Files {
+			for _, d := range file.Diagnostics {
+				if d.Code == diag.CodeParserPanic || d.Code == diag.CodeResolverPanic {
+					t.Fatal(d)
+				}
+			}
+		}
+		// Resolved modules must be safe to walk
+		for _, module := range gosmi.GetLoadedModules() {
+			for _, node := range module.GetNodes() {
+				node.GetSubtree()
+				node.AsTableModel()
+			}
+			module.GetTypes()
+			module.GetImports()
+		}
+	})
+}
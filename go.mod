@@ -5,13 +5,13 @@ go 1.18
 require (
 	github.com/alecthomas/participle/v2 v2.1.4
 	github.com/alecthomas/repr v0.4.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/sleepinggenius2/gosmi v0.4.4
 	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/alecthomas/participle v0.4.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/sleepinggenius2/gosmi v0.4.4 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,92 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stripPragmas struct{}
+
+func (stripPragmas) PreParse(path string, src []byte) ([]byte, error) {
+	return bytes.ReplaceAll(src, []byte("%pragma vendor\n"), nil), nil
+}
+
+type privateTypes map[string][2]string
+
+func (p privateTypes) ResolveType(module, name string) (string, string, bool) {
+	t, ok := p[name]
+	return t[0], t[1], ok
+}
+
+type recordModules []string
+
+func (r *recordModules) PostResolve(module *types.SmiModule) error {
+	if module.Name == "REJECTED-MIB" {
+		return errors.New("Rejected")
+	}
+	*r = append(*r, module.Name.String())
+	return nil
+}
+
+func TestHooks(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"TYPES-MIB": `TYPES-MIB DEFINITIONS ::= BEGIN
+Standard ::= INTEGER (0..255)
+END
+`,
+		"HOOK-MIB": `HOOK-MIB DEFINITIONS ::= BEGIN
+%pragma vendor
+hook OBJECT IDENTIFIER ::= { iso 1 }
+
+hookValue OBJECT-TYPE
+    SYNTAX Private
+    MAX-ACCESS read-only
+    STATUS current
+    ::= { hook 1 }
+END
+`,
+		"REJECTED-MIB": `REJECTED-MIB DEFINITIONS ::= BEGIN
+rejected OBJECT IDENTIFIER ::= { iso 2 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	var loaded recordModules
+	gosmi.AddPreParseHook(stripPragmas{})
+	gosmi.SetSymbolResolver(privateTypes{"Private": {"TYPES-MIB", "Standard"}})
+	gosmi.AddPostResolveHook(&loaded)
+
+	_, err := gosmi.LoadModule("HOOK-MIB")
+	require.NoError(t, err)
+	node, err := gosmi.GetNode("hookValue")
+	require.NoError(t, err)
+	require.NotNil(t, node.Type)
+	assert.Equal(t, "Standard", node.Type.Name)
+	assert.Equal(t, []string{"TYPES-MIB", "HOOK-MIB"}, []string(loaded))
+
+	_, err = gosmi.LoadModule("REJECTED-MIB")
+	assert.ErrorContains(t, err, "Rejected")
+	assert.False(t, gosmi.IsLoaded("REJECTED-MIB"))
+
+	// Hooks are dropped with the handle
+	gosmi.Exit()
+	gosmi.Init()
+	gosmi.SetPath(dir)
+	_, err = gosmi.LoadModule("HOOK-MIB")
+	assert.Error(t, err)
+
+	// CompileDir runs the pre-parse hooks too
+	gosmi.AddPreParseHook(stripPragmas{})
+	gosmi.SetSymbolResolver(privateTypes{"Private": {"TYPES-MIB", "Standard"}})
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed())
+}
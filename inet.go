@@ -0,0 +1,57 @@
+package gosmi
+
+import (
+	"strings"
+
+	"github.com/lukeod/gosmi/smi"
+)
+
+// hasType reports whether the type of the node is, or is derived from, the
+// named type.
+func (n SmiNode) hasType(name string) bool {
+	if n.SmiType == nil {
+		return false
+	}
+	for _, t := range n.SmiType.BaseTypeChain() {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInetAddressType returns the node whose InetAddressType value tells how
+// the InetAddress node n is to be decoded (RFC 4001, section 4.1): the
+// sibling named after n with a Type suffix, such as inetCidrRouteDestType for
+// inetCidrRouteDest, or else the nearest sibling before n of type
+// InetAddressType. ok is false if n is not an InetAddress or none is found.
+func (n SmiNode) GetInetAddressType() (node SmiNode, ok bool) {
+	if !n.hasType("InetAddress") {
+		return
+	}
+	parent := smi.GetParentNode(n.smiNode)
+	if parent == nil {
+		return
+	}
+	var nearest SmiNode
+	for smiNode := smi.GetFirstChildNode(parent); smiNode != nil; smiNode = smi.GetNextChildNode(smiNode) {
+		sibling := CreateNode(smiNode)
+		if sibling.Name == n.Name {
+			break
+		}
+		if !sibling.hasType("InetAddressType") {
+			continue
+		}
+		nearest, ok = sibling, true
+		if strings.TrimSuffix(sibling.Name, "Type") == n.Name {
+			return sibling, true
+		}
+	}
+	// The discriminator may also follow the address
+	for smiNode := smi.GetNextChildNode(n.smiNode); smiNode != nil; smiNode = smi.GetNextChildNode(smiNode) {
+		if sibling := CreateNode(smiNode); sibling.Name == n.Name+"Type" && sibling.hasType("InetAddressType") {
+			return sibling, true
+		}
+	}
+	return nearest, ok
+}
@@ -0,0 +1,155 @@
+package gosmi_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inetMib = `INET-TEST-MIB DEFINITIONS ::= BEGIN
+InetAddressType ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "The type of an address."
+    SYNTAX INTEGER { unknown(0), ipv4(1), ipv6(2), ipv4z(3), ipv6z(4), dns(16) }
+
+InetAddress ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "An address."
+    SYNTAX OCTET STRING (SIZE (0..255))
+
+inetRoot OBJECT IDENTIFIER ::= { iso 3 }
+
+routeTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF RouteEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Routes."
+    ::= { inetRoot 1 }
+
+routeEntry OBJECT-TYPE
+    SYNTAX RouteEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A route."
+    INDEX { routeDestType, routeDest }
+    ::= { routeTable 1 }
+
+RouteEntry ::= SEQUENCE {
+    routeDestType    InetAddressType,
+    routeDest        InetAddress,
+    routeNextHopType InetAddressType,
+    routeNextHop     InetAddress,
+    routeGateway     InetAddress
+}
+
+routeDestType OBJECT-TYPE
+    SYNTAX InetAddressType
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The type of routeDest."
+    ::= { routeEntry 1 }
+
+routeDest OBJECT-TYPE
+    SYNTAX InetAddress (SIZE (4 | 16))
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The destination."
+    ::= { routeEntry 2 }
+
+routeNextHopType OBJECT-TYPE
+    SYNTAX InetAddressType
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The type of routeNextHop and routeGateway."
+    ::= { routeEntry 3 }
+
+routeNextHop OBJECT-TYPE
+    SYNTAX InetAddress
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The next hop."
+    ::= { routeEntry 4 }
+
+routeGateway OBJECT-TYPE
+    SYNTAX InetAddress
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The gateway."
+    ::= { routeEntry 5 }
+END
+`
+
+func TestGetInetAddressType(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"INET-TEST-MIB": inetMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("INET-TEST-MIB")
+	require.NoError(t, err)
+
+	for address, addressType := range map[string]string{
+		"routeDest":    "routeDestType",
+		"routeNextHop": "routeNextHopType",
+		"routeGateway": "routeNextHopType",
+	} {
+		node, err := gosmi.GetNode(address)
+		require.NoError(t, err)
+		typeNode, ok := node.GetInetAddressType()
+		if assert.True(t, ok, address) {
+			assert.Equal(t, addressType, typeNode.Name, address)
+		}
+	}
+	node, err := gosmi.GetNode("routeDestType")
+	require.NoError(t, err)
+	_, ok := node.GetInetAddressType()
+	assert.False(t, ok)
+}
+
+func TestDecodeInetAddress(t *testing.T) {
+	tests := []struct {
+		addressType models.InetAddressType
+		value       []byte
+		want        string
+		wantErr     bool
+	}{
+		{addressType: models.InetAddressUnknown, value: []byte{}, want: ""},
+		{addressType: models.InetAddressIPv4, value: []byte{192, 0, 2, 1}, want: "192.0.2.1"},
+		{addressType: models.InetAddressIPv6, value: net.ParseIP("2001:db8::1"), want: "2001:db8::1"},
+		{addressType: models.InetAddressIPv4z, value: []byte{192, 0, 2, 1, 0, 0, 0, 3}, want: "192.0.2.1%3"},
+		{addressType: models.InetAddressIPv6z, value: append(net.ParseIP("fe80::1"), 0, 0, 1, 0), want: "fe80::1%256"},
+		{addressType: models.InetAddressDNS, value: []byte("example.com"), want: "example.com"},
+		{addressType: models.InetAddressIPv4, value: net.ParseIP("2001:db8::1"), wantErr: true},
+		{addressType: models.InetAddressIPv6, value: []byte{192, 0, 2, 1}, wantErr: true},
+		{addressType: models.InetAddressDNS, value: []byte{}, wantErr: true},
+		{addressType: 5, value: []byte{1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		address, err := models.DecodeInetAddress(tt.addressType, tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, "%s %v", tt.addressType, tt.value)
+			continue
+		}
+		require.NoError(t, err, "%s %v", tt.addressType, tt.value)
+		assert.Equal(t, tt.want, address.String())
+	}
+
+	address, rest, err := models.DecodeInetAddressIndex(types.Oid{1, 4, 192, 0, 2, 1, 7}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1", address.String())
+	assert.Equal(t, types.Oid{7}, rest)
+
+	address, rest, err = models.DecodeInetAddressIndex(types.Oid{16, 'a', '.', 'b'}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "a.b", address.String())
+	assert.Empty(t, rest)
+
+	_, _, err = models.DecodeInetAddressIndex(types.Oid{1, 4, 192, 0}, false)
+	assert.Error(t, err)
+	_, _, err = models.DecodeInetAddressIndex(types.Oid{1, 4, 192, 0, 2, 256}, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package gosmi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// InventoryEntry is a node of a flat object inventory, for import into
+// spreadsheets and CMDBs. Syntax is the name of the type of the node, or its
+// base type if the type is unnamed; both are empty for nodes without SYNTAX.
+type InventoryEntry struct {
+	Module      string         `json:"module"`
+	Name        string         `json:"name"`
+	Oid         string         `json:"oid"`
+	Kind        types.NodeKind `json:"kind"`
+	Syntax      string         `json:"syntax,omitempty"`
+	BaseType    string         `json:"baseType,omitempty"`
+	Access      types.Access   `json:"access"`
+	Status      types.Status   `json:"status"`
+	Units       string         `json:"units,omitempty"`
+	Description string         `json:"description,omitempty"`
+}
+
+// GetInventory returns the nodes of the named modules, or of every loaded
+// module if none are named, in module order and then by OID. Only the first
+// non-blank line of descriptions is kept.
+func GetInventory(modules ...string) (entries []InventoryEntry, err error) {
+	var selected []SmiModule
+	if len(modules) == 0 {
+		selected = GetLoadedModules()
+	}
+	for _, name := range modules {
+		module, err := GetModule(name)
+		if err != nil {
+			return nil, fmt.Errorf("Get inventory: %w", err)
+		}
+		selected = append(selected, module)
+	}
+	for _, module := range selected {
+		for _, node := range module.GetNodes() {
+			entry := InventoryEntry{
+				Module:      module.Name,
+				Name:        node.Name,
+				Oid:         node.RenderNumeric(),
+				Kind:        node.Kind,
+				Access:      node.Access,
+				Status:      node.Status,
+				Units:       node.Units,
+				Description: firstLine(node.Description),
+			}
+			if node.Type != nil {
+				entry.Syntax = node.Type.Name
+				entry.BaseType = node.Type.BaseType.String()
+				if entry.Syntax == "" {
+					entry.Syntax = entry.BaseType
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// firstLine returns the first non-blank line of s, trimmed.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// WriteInventoryCSV writes entries as CSV with a header row.
+func WriteInventoryCSV(w io.Writer, entries []InventoryEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"module", "name", "oid", "kind", "syntax", "base type", "access", "status", "units", "description"})
+	for _, entry := range entries {
+		cw.Write([]string{
+			entry.Module,
+			entry.Name,
+			entry.Oid,
+			entry.Kind.String(),
+			entry.Syntax,
+			entry.BaseType,
+			entry.Access.String(),
+			entry.Status.String(),
+			entry.Units,
+			entry.Description,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
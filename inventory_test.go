@@ -0,0 +1,73 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInventory(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"INVENTORY-MIB": `INVENTORY-MIB DEFINITIONS ::= BEGIN
+Percent ::= INTEGER (-100..100)
+
+inventory OBJECT IDENTIFIER ::= { iso 1 }
+
+inventoryLoad OBJECT-TYPE
+    SYNTAX Percent
+    UNITS "percent"
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "
+        The load, averaged.
+
+        Over a minute."
+    ::= { inventory 1 }
+
+inventoryName OBJECT-TYPE
+    SYNTAX OCTET STRING (SIZE (0..32))
+    MAX-ACCESS read-write
+    STATUS deprecated
+    DESCRIPTION "The name, as set."
+    ::= { inventory 2 }
+END
+`})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("INVENTORY-MIB")
+	require.NoError(t, err)
+
+	_, err = gosmi.GetInventory("MISSING-MIB")
+	assert.Error(t, err)
+
+	entries, err := gosmi.GetInventory("INVENTORY-MIB")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, gosmi.InventoryEntry{
+		Module: "INVENTORY-MIB",
+		Name:   "inventory",
+		Oid:    "1.1",
+		Kind:   types.NodeNode,
+	}, entries[0])
+	assert.Equal(t, gosmi.InventoryEntry{
+		Module:      "INVENTORY-MIB",
+		Name:        "inventoryLoad",
+		Oid:         "1.1.1",
+		Kind:        types.NodeScalar,
+		Syntax:      "Percent",
+		BaseType:    "Integer32",
+		Access:      types.AccessReadOnly,
+		Status:      types.StatusCurrent,
+		Units:       "percent",
+		Description: "The load, averaged.",
+	}, entries[1])
+
+	var buf bytes.Buffer
+	require.NoError(t, gosmi.WriteInventoryCSV(&buf, entries[2:]))
+	assert.Equal(t, "module,name,oid,kind,syntax,base type,access,status,units,description\n"+
+		"INVENTORY-MIB,inventoryName,1.1.2,Scalar,OctetString,OctetString,ReadWrite,Deprecated,,\"The name, as set.\"\n", buf.String())
+}
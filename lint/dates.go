@@ -0,0 +1,28 @@
+package lint
+
+import (
+	"github.com/lukeod/gosmi/diag"
+)
+
+// DatesRule reports LAST-UPDATED and REVISION dates that are malformed or do
+// not exist, such as a 30th of February.
+var DatesRule = &Rule{
+	Name: "dates",
+	Doc:  "reports invalid LAST-UPDATED and REVISION dates",
+	Run:  runDates,
+}
+
+func runDates(pass *Pass) {
+	identity := pass.Module.Body.Identity
+	if identity == nil {
+		return
+	}
+	if err := identity.LastUpdated.Validate(); err != nil {
+		pass.Report(diag.CodeInvalidDate, identity.Pos, "LAST-UPDATED of %s: %v", identity.Name, err)
+	}
+	for _, r := range identity.Revisions {
+		if err := r.Date.Validate(); err != nil {
+			pass.Report(diag.CodeInvalidDate, r.Pos, "REVISION of %s: %v", identity.Name, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const datesMib = `DATES-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, enterprises FROM SNMPv2-SMI;
+
+datesMib MODULE-IDENTITY
+    LAST-UPDATED "202302301200Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION "Example"
+    REVISION "202302301200Z"
+    DESCRIPTION "Bad day"
+    REVISION "9913011200Z"
+    DESCRIPTION "Bad month"
+    REVISION "9901011200Z"
+    DESCRIPTION "Initial"
+    ::= { enterprises 99999 }
+
+END
+`
+
+func TestCheckDates(t *testing.T) {
+	module, err := parser.ParseBytes("DATES-MIB", []byte(datesMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("DATES-MIB", module, lint.DatesRule)
+	require.Len(t, diags, 3)
+	for _, d := range diags {
+		assert.Equal(t, diag.CodeInvalidDate, d.Code)
+	}
+	assert.Equal(t, `LAST-UPDATED of datesMib: Invalid date "202302301200Z": day 30 out of range`, diags[0].Message)
+	assert.Equal(t, 10, diags[1].Pos.Line)
+	assert.Equal(t, `REVISION of datesMib: Invalid date "9913011200Z": month 13 out of range`, diags[2].Message)
+}
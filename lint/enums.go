@@ -0,0 +1,210 @@
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// MaxBitPosition is the highest BITS position agents are expected to handle.
+const MaxBitPosition = 127
+
+// EnumsRule reports duplicate values and labels in enumerations and BITS, and
+// BITS positions that are negative, too large or not contiguous.
+var EnumsRule = &Rule{
+	Name: "enums",
+	Doc:  "reports duplicate and badly numbered enumeration and BITS labels",
+	Run:  runEnums,
+}
+
+func runEnums(pass *Pass) {
+	for _, issue := range AnalyzeEnums(pass.Module) {
+		pass.ReportFix(issue.Kind.code(), issue.Pos, issue.Fix, "%s", issue.Message)
+	}
+}
+
+// EnumIssueKind is the kind of problem found by AnalyzeEnums.
+type EnumIssueKind int
+
+const (
+	// DuplicateValue is a number given to more than one label.
+	DuplicateValue EnumIssueKind = iota
+	// DuplicateLabel is a label defined more than once.
+	DuplicateLabel
+	// NegativeBit is a BITS label with a negative position.
+	NegativeBit
+	// BitTooLarge is a BITS label with a position above MaxBitPosition.
+	BitTooLarge
+	// BitGap is a BITS definition whose positions do not run from 0 without
+	// gaps, which some agent implementations rely on.
+	BitGap
+)
+
+func (k EnumIssueKind) String() string {
+	switch k {
+	case DuplicateValue:
+		return "duplicate value"
+	case DuplicateLabel:
+		return "duplicate label"
+	case NegativeBit:
+		return "negative bit"
+	case BitTooLarge:
+		return "bit too large"
+	case BitGap:
+		return "bit gap"
+	}
+	return "unknown"
+}
+
+func (k EnumIssueKind) code() string {
+	switch k {
+	case DuplicateValue:
+		return diag.CodeDuplicateEnumValue
+	case DuplicateLabel:
+		return diag.CodeDuplicateEnumLabel
+	case NegativeBit:
+		return diag.CodeNegativeBit
+	case BitTooLarge:
+		return diag.CodeBitTooLarge
+	}
+	return diag.CodeBitGap
+}
+
+// EnumIssue is a problem with the labels of an enumeration or BITS.
+type EnumIssue struct {
+	Kind EnumIssueKind
+	// Definition is the type or object whose syntax has the labels.
+	Definition types.SmiIdentifier
+	// Label is the offending label, empty for BitGap.
+	Label types.SmiIdentifier
+	Pos   lexer.Position
+	// Message describes the issue and Fix suggests how to address it.
+	Message string
+	Fix     string
+}
+
+// AnalyzeEnums checks the enumerations and BITS of the types and objects
+// defined by module, in order of definition.
+func AnalyzeEnums(module *parser.Module) []EnumIssue {
+	var issues []EnumIssue
+	for i := range module.Body.Types {
+		t := &module.Body.Types[i]
+		switch {
+		case t.TextualConvention != nil:
+			issues = append(issues, analyzeSyntax(t.Name, &t.TextualConvention.Syntax)...)
+		case t.Syntax != nil:
+			issues = append(issues, analyzeSyntax(t.Name, t.Syntax)...)
+		}
+	}
+	for i := range module.Body.Nodes {
+		n := &module.Body.Nodes[i]
+		if n.ObjectType != nil && n.ObjectType.Syntax.Type != nil {
+			issues = append(issues, analyzeSyntax(n.Name, n.ObjectType.Syntax.Type)...)
+		}
+	}
+	return issues
+}
+
+func analyzeSyntax(name types.SmiIdentifier, syntax *parser.SyntaxType) []EnumIssue {
+	if len(syntax.Enum) == 0 {
+		return nil
+	}
+	bits := syntax.Name == "BITS"
+	kind := "enumeration"
+	if bits {
+		kind = "BITS"
+	}
+
+	var issues []EnumIssue
+	report := func(k EnumIssueKind, n *parser.NamedNumber, fix, format string, args ...interface{}) {
+		issue := EnumIssue{
+			Kind:       k,
+			Definition: name,
+			Pos:        syntax.Pos,
+			Message:    fmt.Sprintf(format, args...),
+			Fix:        fix,
+		}
+		if n != nil {
+			issue.Label, issue.Pos = n.Name, n.Pos
+		}
+		issues = append(issues, issue)
+	}
+
+	labels := make(map[types.SmiIdentifier]bool, len(syntax.Enum))
+	values := make(map[int64]types.SmiIdentifier, len(syntax.Enum))
+	var max int64
+	for i := range syntax.Enum {
+		n := &syntax.Enum[i]
+		if labels[n.Name] {
+			report(DuplicateLabel, n, "give each label a unique name",
+				"Label %s of %s %s is defined more than once", n.Name, kind, name)
+		}
+		labels[n.Name] = true
+
+		value, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+		if first, ok := values[value]; ok {
+			report(DuplicateValue, n, "",
+				"Label %s of %s %s has value %d, already given to %s", n.Name, kind, name, value, first)
+		} else {
+			values[value] = n.Name
+		}
+		if value > max {
+			max = value
+		}
+
+		if !bits {
+			continue
+		}
+		switch {
+		case value < 0:
+			report(NegativeBit, n, "number bits from 0",
+				"Bit %s of %s has negative position %d", n.Name, name, value)
+		case value > MaxBitPosition:
+			report(BitTooLarge, n, fmt.Sprintf("keep positions at or below %d", MaxBitPosition),
+				"Bit %s of %s has position %d, above %d", n.Name, name, value, MaxBitPosition)
+		}
+	}
+
+	// Suggest the next free value for duplicates, now that all are known
+	next := max + 1
+	for i := range issues {
+		if issues[i].Kind == DuplicateValue {
+			issues[i].Fix = fmt.Sprintf("use an unused value such as %d", next)
+			next++
+		}
+	}
+
+	if bits {
+		if gaps := bitGaps(values); len(gaps) > 0 {
+			report(BitGap, nil, fmt.Sprintf("define positions %s or renumber the bits contiguously from 0", strings.Join(gaps, ", ")),
+				"BITS %s has no labels for positions %s", name, strings.Join(gaps, ", "))
+		}
+	}
+	return issues
+}
+
+// bitGaps returns the positions below the highest valid one that have no
+// label.
+func bitGaps(values map[int64]types.SmiIdentifier) []string {
+	var highest int64
+	for value := range values {
+		if value <= MaxBitPosition && value > highest {
+			highest = value
+		}
+	}
+	var gaps []string
+	for i := int64(0); i < highest; i++ {
+		if _, ok := values[i]; !ok {
+			gaps = append(gaps, strconv.FormatInt(i, 10))
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,85 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const enumsMib = `ENUMS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, enterprises FROM SNMPv2-SMI
+    TEXTUAL-CONVENTION FROM SNMPv2-TC;
+
+enumsRoot OBJECT IDENTIFIER ::= { enterprises 99999 }
+
+EnumsState ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "State"
+    SYNTAX INTEGER { up(1), down(2), down(3), testing(2) }
+
+EnumsFlags ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "Flags"
+    SYNTAX BITS { a(0), b(1), c(4), d(-1), e(128) }
+
+enumsGood OBJECT-TYPE
+    SYNTAX BITS { a(0), b(1), c(2) }
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Good"
+    ::= { enumsRoot 1 }
+
+END
+`
+
+func TestAnalyzeEnums(t *testing.T) {
+	module, err := parser.ParseBytes("ENUMS-MIB", []byte(enumsMib))
+	require.NoError(t, err)
+
+	issues := lint.AnalyzeEnums(module)
+	type summary struct {
+		Kind       lint.EnumIssueKind
+		Definition types.SmiIdentifier
+		Label      types.SmiIdentifier
+	}
+	var got []summary
+	for _, issue := range issues {
+		got = append(got, summary{issue.Kind, issue.Definition, issue.Label})
+	}
+	assert.Equal(t, []summary{
+		{lint.DuplicateLabel, "EnumsState", "down"},
+		{lint.DuplicateValue, "EnumsState", "testing"},
+		{lint.NegativeBit, "EnumsFlags", "d"},
+		{lint.BitTooLarge, "EnumsFlags", "e"},
+		{lint.BitGap, "EnumsFlags", ""},
+	}, got)
+
+	assert.Equal(t, "Label testing of enumeration EnumsState has value 2, already given to down", issues[1].Message)
+	assert.Equal(t, "use an unused value such as 4", issues[1].Fix)
+	assert.Equal(t, 11, issues[1].Pos.Line)
+	assert.Equal(t, "BITS EnumsFlags has no labels for positions 2, 3", issues[4].Message)
+}
+
+func TestCheckEnums(t *testing.T) {
+	module, err := parser.ParseBytes("ENUMS-MIB", []byte(enumsMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("ENUMS-MIB", module, lint.EnumsRule)
+	require.Len(t, diags, 5)
+	assert.Equal(t, diag.CodeDuplicateEnumLabel, diags[0].Code)
+	assert.Equal(t, diag.SeverityError, diags[0].Severity)
+	assert.Equal(t, "give each label a unique name", diags[0].Fix)
+	// The gap is reported at the BITS keyword, before the labels
+	assert.Equal(t, diag.CodeBitGap, diags[2].Code)
+	assert.Equal(t, diag.CodeBitTooLarge, diags[4].Code)
+	assert.Equal(t, diag.SeverityWarning, diags[4].Severity)
+
+	// Nothing else in the module is worth reporting
+	assert.Len(t, lint.Check("ENUMS-MIB", module), 5)
+}
@@ -0,0 +1,36 @@
+package lint
+
+import (
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/types"
+)
+
+// ExportsRule reports symbols listed in the EXPORTS clause that the module
+// neither defines nor imports, which modules importing them cannot resolve.
+var ExportsRule = &Rule{
+	Name: "exports",
+	Doc:  "reports exported symbols the module does not define",
+	Run:  runExports,
+}
+
+func runExports(pass *Pass) {
+	body := &pass.Module.Body
+	if len(body.Exports) == 0 {
+		return
+	}
+	known := definedSymbols(pass.Module)
+	for _, imp := range body.Imports {
+		for _, name := range imp.Names {
+			known[name] = true
+		}
+	}
+	seen := make(map[types.SmiIdentifier]bool, len(body.Exports))
+	for _, name := range body.Exports {
+		if known[name] || builtinSymbols[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		// The names of the EXPORTS clause have no positions of their own
+		pass.Report(diag.CodeUndefinedExport, body.Pos, "Exported symbol %s is not defined", name)
+	}
+}
@@ -0,0 +1,39 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exportsMib = `EXPORTS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    enterprises FROM SNMPv2-SMI;
+EXPORTS
+    exportsRoot, enterprises, INTEGER, exportsMissing, exportsMissing, ExportsType;
+
+exportsRoot OBJECT IDENTIFIER ::= { enterprises 99999 }
+
+END
+`
+
+func TestCheckExports(t *testing.T) {
+	module, err := parser.ParseBytes("EXPORTS-MIB", []byte(exportsMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("EXPORTS-MIB", module, lint.ExportsRule)
+	require.Len(t, diags, 2)
+	for _, d := range diags {
+		assert.Equal(t, diag.CodeUndefinedExport, d.Code)
+		assert.Equal(t, diag.SeverityError, d.Severity)
+	}
+	assert.Equal(t, "Exported symbol exportsMissing is not defined", diags[0].Message)
+	assert.Equal(t, "Exported symbol ExportsType is not defined", diags[1].Message)
+
+	// Exported symbols count as used imports
+	assert.Empty(t, lint.Check("EXPORTS-MIB", module, lint.ImportsRule))
+}
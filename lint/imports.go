@@ -0,0 +1,399 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// ImportsRule reports unused imports, symbols used without being imported or
+// defined, and symbols imported from a base module that does not define them.
+var ImportsRule = &Rule{
+	Name: "imports",
+	Doc:  "reports unused, missing and misplaced imports",
+	Run:  runImports,
+}
+
+func runImports(pass *Pass) {
+	a := AnalyzeImports(pass.Module)
+	for _, s := range a.Unused {
+		pass.Report(diag.CodeUnusedImport, s.Pos, "Imported symbol %s from %s is not used", s.Name, s.Module)
+	}
+	for _, r := range a.Missing {
+		pass.Report(diag.CodeUndefinedSymbol, r.Pos, "Symbol %s is neither imported nor defined", r.Name)
+	}
+	for _, s := range a.WrongModule {
+		if len(s.Expected) == 0 {
+			pass.Report(diag.CodeImportWrongModule, s.Pos, "Symbol %s is not defined in %s", s.Name, s.Module)
+			continue
+		}
+		pass.Report(diag.CodeImportWrongModule, s.Pos, "Symbol %s is imported from %s but defined in %s", s.Name, s.Module, strings.Join(s.Expected, " or "))
+	}
+}
+
+// ImportedSymbol is a symbol named in an IMPORTS clause.
+type ImportedSymbol struct {
+	Name   types.SmiIdentifier
+	Module types.SmiIdentifier
+	// Pos is that of the FROM clause importing the symbol.
+	Pos lexer.Position
+	// Expected lists the base modules defining the symbol, for symbols
+	// imported from the wrong module.
+	Expected []string
+}
+
+// Reference is a use of a symbol in the module body.
+type Reference struct {
+	Name types.SmiIdentifier
+	Pos  lexer.Position
+}
+
+// ImportAnalysis cross-references the imports of a module with its body.
+type ImportAnalysis struct {
+	// Unused are the imported symbols the module never refers to.
+	Unused []ImportedSymbol
+	// Missing are the first references to symbols that are neither imported
+	// nor defined in the module, in order of position.
+	Missing []Reference
+	// WrongModule are the symbols imported from a base module, such as
+	// SNMPv2-SMI or SNMPv2-TC, that does not define them.
+	WrongModule []ImportedSymbol
+}
+
+// builtinSymbols can be used without being imported.
+var builtinSymbols = map[types.SmiIdentifier]bool{
+	"INTEGER":           true,
+	"OCTET STRING":      true,
+	"OBJECT IDENTIFIER": true,
+	"BITS":              true,
+	"iso":               true,
+	"ccitt":             true,
+	"joint-iso-ccitt":   true,
+}
+
+// baseModules lists the symbols defined by the modules of the SMI. Only the
+// closed ones, which define nothing else, report other symbols imported
+// from them.
+var baseModules = map[types.SmiIdentifier]struct {
+	closed  bool
+	symbols []types.SmiIdentifier
+}{
+	"SNMPv2-SMI": {true, []types.SmiIdentifier{
+		"MODULE-IDENTITY", "OBJECT-IDENTITY", "OBJECT-TYPE", "NOTIFICATION-TYPE",
+		"Integer32", "Counter32", "Gauge32", "Unsigned32", "TimeTicks", "IpAddress", "Opaque", "Counter64",
+		"ObjectName", "NotificationName", "ObjectSyntax", "SimpleSyntax", "ApplicationSyntax", "ExtUTCTime",
+		"org", "dod", "internet", "directory", "mgmt", "mib-2", "transmission", "experimental", "private",
+		"enterprises", "security", "snmpV2", "snmpDomains", "snmpProxys", "snmpModules", "zeroDotZero",
+	}},
+	"SNMPv2-TC": {true, []types.SmiIdentifier{
+		"TEXTUAL-CONVENTION", "DisplayString", "PhysAddress", "MacAddress", "TruthValue", "TestAndIncr",
+		"AutonomousType", "InstancePointer", "VariablePointer", "RowPointer", "RowStatus", "TimeStamp",
+		"TimeInterval", "DateAndTime", "StorageType", "TDomain", "TAddress",
+	}},
+	"SNMPv2-CONF": {true, []types.SmiIdentifier{
+		"OBJECT-GROUP", "NOTIFICATION-GROUP", "MODULE-COMPLIANCE", "AGENT-CAPABILITIES",
+	}},
+	"RFC1155-SMI": {true, []types.SmiIdentifier{
+		"OBJECT-TYPE", "ObjectName", "ObjectSyntax", "SimpleSyntax", "ApplicationSyntax", "NetworkAddress",
+		"IpAddress", "Counter", "Gauge", "TimeTicks", "Opaque",
+		"internet", "directory", "mgmt", "experimental", "private", "enterprises",
+	}},
+	"RFC-1212": {true, []types.SmiIdentifier{"OBJECT-TYPE"}},
+	"RFC-1215": {true, []types.SmiIdentifier{"TRAP-TYPE"}},
+	"RFC1213-MIB": {false, []types.SmiIdentifier{
+		"mib-2", "DisplayString", "PhysAddress",
+	}},
+}
+
+// definingModules maps the symbols of baseModules to the modules defining
+// them.
+var definingModules = func() map[types.SmiIdentifier][]string {
+	m := make(map[types.SmiIdentifier][]string)
+	for module, base := range baseModules {
+		for _, symbol := range base.symbols {
+			m[symbol] = append(m[symbol], module.String())
+		}
+	}
+	for _, modules := range m {
+		sort.Strings(modules)
+	}
+	return m
+}()
+
+func definedIn(module, symbol types.SmiIdentifier) bool {
+	for _, s := range baseModules[module].symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeImports cross-references the symbols module imports with those its
+// body refers to. It works on the module alone, so whether imported symbols
+// exist is only checked for the base modules of the SMI.
+func AnalyzeImports(module *parser.Module) ImportAnalysis {
+	var a ImportAnalysis
+	refs := collectReferences(module)
+
+	used := make(map[types.SmiIdentifier]bool, len(refs.strong)+len(refs.weak))
+	for _, r := range refs.strong {
+		used[r.Name] = true
+	}
+	for _, name := range refs.weak {
+		used[name] = true
+	}
+
+	imported := make(map[types.SmiIdentifier]bool)
+	for _, imp := range module.Body.Imports {
+		base, isBase := baseModules[imp.Module]
+		for _, name := range imp.Names {
+			imported[name] = true
+			s := ImportedSymbol{Name: name, Module: imp.Module, Pos: imp.Pos}
+			if !used[name] {
+				a.Unused = append(a.Unused, s)
+			}
+			if !isBase || definedIn(imp.Module, name) {
+				continue
+			}
+			if s.Expected = definingModules[name]; s.Expected != nil || base.closed {
+				a.WrongModule = append(a.WrongModule, s)
+			}
+		}
+	}
+
+	defined := definedSymbols(module)
+	seen := make(map[types.SmiIdentifier]bool)
+	for _, r := range refs.strong {
+		if imported[r.Name] || defined[r.Name] || builtinSymbols[r.Name] || seen[r.Name] {
+			continue
+		}
+		seen[r.Name] = true
+		a.Missing = append(a.Missing, r)
+	}
+	sort.SliceStable(a.Missing, func(i, j int) bool { return a.Missing[i].Pos.Offset < a.Missing[j].Pos.Offset })
+	return a
+}
+
+func definedSymbols(module *parser.Module) map[types.SmiIdentifier]bool {
+	defined := make(map[types.SmiIdentifier]bool)
+	if module.Body.Identity != nil {
+		defined[module.Body.Identity.Name] = true
+	}
+	for _, t := range module.Body.Types {
+		defined[t.Name] = true
+	}
+	for _, n := range module.Body.Nodes {
+		defined[n.Name] = true
+	}
+	for _, m := range module.Body.Macros {
+		defined[m.Name] = true
+	}
+	for _, v := range module.Body.Values {
+		defined[v.Name] = true
+	}
+	for _, m := range module.Body.Invocations {
+		defined[m.Name] = true
+	}
+	return defined
+}
+
+// references are the symbols a module body refers to. Strong references must
+// resolve to an import or a definition; weak ones, such as identifiers in
+// DEFVAL clauses that may be enumeration labels, only count as uses.
+type references struct {
+	strong []Reference
+	weak   []types.SmiIdentifier
+}
+
+func (r *references) add(name types.SmiIdentifier, pos lexer.Position) {
+	r.strong = append(r.strong, Reference{Name: name, Pos: pos})
+}
+
+func (r *references) addList(names []types.SmiIdentifier, pos lexer.Position) {
+	for _, name := range names {
+		r.add(name, pos)
+	}
+}
+
+func (r *references) addOid(oid *parser.Oid) {
+	if oid == nil || len(oid.SubIdentifiers) == 0 || oid.SubIdentifiers[0].Name == nil {
+		return
+	}
+	first := oid.SubIdentifiers[0]
+	if first.Number != nil {
+		// name(number) form, which also defines the name
+		r.weak = append(r.weak, *first.Name)
+		return
+	}
+	r.add(*first.Name, first.Pos)
+}
+
+func (r *references) addSyntaxType(syntax *parser.SyntaxType) {
+	if syntax == nil || syntax.Name == "" {
+		return
+	}
+	r.add(syntax.Name, syntax.Pos)
+}
+
+func (r *references) addSyntax(syntax *parser.Syntax) {
+	if syntax == nil {
+		return
+	}
+	if syntax.Sequence != nil {
+		r.add(*syntax.Sequence, syntax.Pos)
+	}
+	r.addSyntaxType(syntax.Type)
+}
+
+// addWeakSyntax records the types of syntax refinements, which may come from
+// modules other than those imported.
+func (r *references) addWeakSyntax(syntax *parser.Syntax) {
+	if syntax == nil {
+		return
+	}
+	if syntax.Sequence != nil {
+		r.weak = append(r.weak, *syntax.Sequence)
+	}
+	if syntax.Type != nil {
+		r.weak = append(r.weak, syntax.Type.Name)
+	}
+}
+
+func (r *references) addDefval(defval *parser.Defval) {
+	switch {
+	case defval == nil:
+	case defval.Oid != nil:
+		for _, subId := range defval.Oid.SubIdentifiers {
+			if subId.Name != nil {
+				r.weak = append(r.weak, *subId.Name)
+			}
+		}
+	case defval.Bits:
+		r.weak = append(r.weak, defval.Names...)
+	case defval.Value != "":
+		if c := defval.Value[0]; c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			r.weak = append(r.weak, types.SmiIdentifier(defval.Value))
+		}
+	}
+}
+
+func collectReferences(module *parser.Module) *references {
+	r := &references{}
+	body := &module.Body
+	r.weak = append(r.weak, body.Exports...)
+
+	if identity := body.Identity; identity != nil {
+		r.add("MODULE-IDENTITY", identity.Pos)
+		r.addOid(&identity.Oid)
+	}
+
+	for i := range body.Types {
+		t := &body.Types[i]
+		switch {
+		case t.TextualConvention != nil:
+			r.add("TEXTUAL-CONVENTION", t.TextualConvention.Pos)
+			r.addSyntaxType(&t.TextualConvention.Syntax)
+		case t.Sequence != nil:
+			for j := range t.Sequence.Entries {
+				r.weak = append(r.weak, t.Sequence.Entries[j].Descriptor)
+				r.addSyntaxType(&t.Sequence.Entries[j].Syntax)
+			}
+		case t.Implicit != nil:
+			r.addSyntaxType(&t.Implicit.Syntax)
+		default:
+			r.addSyntaxType(t.Syntax)
+		}
+	}
+
+	for i := range body.Nodes {
+		n := &body.Nodes[i]
+		r.addOid(n.Oid)
+		switch {
+		case n.ObjectIdentity != nil:
+			r.add("OBJECT-IDENTITY", n.ObjectIdentity.Pos)
+		case n.ObjectGroup != nil:
+			r.add("OBJECT-GROUP", n.ObjectGroup.Pos)
+			r.addList(n.ObjectGroup.Objects, n.ObjectGroup.Pos)
+		case n.ObjectType != nil:
+			o := n.ObjectType
+			r.add("OBJECT-TYPE", o.Pos)
+			r.addSyntax(&o.Syntax)
+			for _, index := range o.Index {
+				r.add(index.Name, index.Pos)
+			}
+			if o.Augments != nil {
+				r.add(*o.Augments, o.Pos)
+			}
+			r.addDefval(o.Defval)
+		case n.NotificationGroup != nil:
+			r.add("NOTIFICATION-GROUP", n.NotificationGroup.Pos)
+			r.addList(n.NotificationGroup.Notifications, n.NotificationGroup.Pos)
+		case n.NotificationType != nil:
+			r.add("NOTIFICATION-TYPE", n.NotificationType.Pos)
+			r.addList(n.NotificationType.Objects, n.NotificationType.Pos)
+		case n.ModuleCompliance != nil:
+			r.add("MODULE-COMPLIANCE", n.ModuleCompliance.Pos)
+			addCompliance(r, module.Name, n.ModuleCompliance)
+		case n.AgentCapabilities != nil:
+			r.add("AGENT-CAPABILITIES", n.AgentCapabilities.Pos)
+			for _, m := range n.AgentCapabilities.Modules {
+				for j := range m.Variations {
+					r.addWeakSyntax(m.Variations[j].Syntax)
+					r.addWeakSyntax(m.Variations[j].WriteSyntax)
+					r.addDefval(m.Variations[j].Defval)
+				}
+			}
+		case n.TrapType != nil:
+			r.add("TRAP-TYPE", n.TrapType.Pos)
+			r.add(n.TrapType.Enterprise, n.TrapType.Pos)
+			r.addList(n.TrapType.Objects, n.TrapType.Pos)
+		}
+	}
+
+	for i := range body.Values {
+		v := &body.Values[i]
+		r.addSyntaxType(&v.Syntax)
+		// Enumeration label or reference to another value
+		r.weak = append(r.weak, types.SmiIdentifier(v.Value))
+	}
+
+	for i := range body.Invocations {
+		m := &body.Invocations[i]
+		r.add(m.Macro, m.Pos)
+		r.addOid(m.Oid)
+	}
+	return r
+}
+
+// addCompliance records the groups and objects of the compliance statements
+// for the module itself. Those for other modules are named after the module
+// statement and need not be imported.
+func addCompliance(r *references, name types.SmiIdentifier, compliance *parser.ModuleCompliance) {
+	for _, m := range compliance.Modules {
+		if m.Name != "" && types.SmiIdentifier(m.Name) != name {
+			for _, c := range m.Compliances {
+				if c.Object != nil {
+					r.addWeakSyntax(c.Object.Syntax)
+					r.addWeakSyntax(c.Object.WriteSyntax)
+				}
+			}
+			continue
+		}
+		r.addList(m.MandatoryGroups, m.Pos)
+		for _, c := range m.Compliances {
+			switch {
+			case c.Group != nil:
+				r.add(c.Group.Name, c.Group.Pos)
+			case c.Object != nil:
+				r.add(c.Object.Name, c.Object.Pos)
+				r.addWeakSyntax(c.Object.Syntax)
+				r.addWeakSyntax(c.Object.WriteSyntax)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importsMib = `IMPORTS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Integer32, enterprises, TimeTicks
+        FROM SNMPv2-SMI
+    Counter32, DisplayString, RowStatus
+        FROM SNMPv2-TC
+    MODULE-COMPLIANCE, OBJECT-GROUP
+        FROM SNMPv2-CONF
+    ifIndex
+        FROM IF-MIB;
+
+importsMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION "Example"
+    ::= { enterprises 99999 }
+
+importsTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF ImportsEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Table"
+    ::= { importsMib 1 }
+
+importsEntry OBJECT-TYPE
+    SYNTAX ImportsEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Entry"
+    INDEX { ifIndex }
+    ::= { importsTable 1 }
+
+ImportsEntry ::= SEQUENCE {
+    importsName DisplayString,
+    importsCount Counter32,
+    importsValue Integer32,
+    importsAddr IpAddress
+}
+
+importsName OBJECT-TYPE
+    SYNTAX DisplayString
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Name"
+    ::= { importsEntry 1 }
+
+importsCount OBJECT-TYPE
+    SYNTAX Counter32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Count"
+    ::= { importsEntry 2 }
+
+importsValue OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Value"
+    ::= { importsEntry 3 }
+
+importsAddr OBJECT-TYPE
+    SYNTAX IpAddress
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Address"
+    ::= { importsEntry 4 }
+
+importsGroup OBJECT-GROUP
+    OBJECTS { importsName, importsCount, importsValue, importsAddr, importsOther }
+    STATUS current
+    DESCRIPTION "Group"
+    ::= { importsMib 2 }
+
+END
+`
+
+func TestAnalyzeImports(t *testing.T) {
+	module, err := parser.ParseBytes("IMPORTS-MIB", []byte(importsMib))
+	require.NoError(t, err)
+
+	a := lint.AnalyzeImports(module)
+
+	var unused []types.SmiIdentifier
+	for _, s := range a.Unused {
+		unused = append(unused, s.Name)
+	}
+	assert.Equal(t, []types.SmiIdentifier{"TimeTicks", "RowStatus", "MODULE-COMPLIANCE"}, unused)
+
+	var missing []types.SmiIdentifier
+	for _, r := range a.Missing {
+		missing = append(missing, r.Name)
+	}
+	assert.Equal(t, []types.SmiIdentifier{"IpAddress", "importsOther"}, missing)
+	assert.Equal(t, 38, a.Missing[0].Pos.Line)
+
+	require.Len(t, a.WrongModule, 1)
+	assert.Equal(t, types.SmiIdentifier("Counter32"), a.WrongModule[0].Name)
+	assert.Equal(t, types.SmiIdentifier("SNMPv2-TC"), a.WrongModule[0].Module)
+	assert.Equal(t, []string{"SNMPv2-SMI"}, a.WrongModule[0].Expected)
+}
+
+func TestCheckImports(t *testing.T) {
+	module, err := parser.ParseBytes("IMPORTS-MIB", []byte(importsMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("IMPORTS-MIB", module, lint.ImportsRule)
+	var codes []string
+	for _, d := range diags {
+		assert.Equal(t, "IMPORTS-MIB", d.Module)
+		assert.Equal(t, "IMPORTS-MIB", d.Pos.Filename)
+		codes = append(codes, d.Code)
+	}
+	assert.Equal(t, []string{
+		diag.CodeUnusedImport,      // TimeTicks
+		diag.CodeUnusedImport,      // RowStatus
+		diag.CodeImportWrongModule, // Counter32
+		diag.CodeUnusedImport,      // MODULE-COMPLIANCE
+		diag.CodeUndefinedSymbol,   // IpAddress
+		diag.CodeUndefinedSymbol,   // importsOther
+	}, codes)
+	assert.Equal(t, "Symbol Counter32 is imported from SNMPv2-TC but defined in SNMPv2-SMI", diags[2].Message)
+	assert.Equal(t, diag.SeverityError, diags[4].Severity)
+}
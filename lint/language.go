@@ -0,0 +1,26 @@
+package lint
+
+import (
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/types"
+)
+
+// LanguageRule checks that an SMIv1 or SMIv2 module only uses constructs of
+// its language, as determined by parser.Module.Language.
+var LanguageRule = &Rule{
+	Name: "language",
+	Doc:  "reports SMIv1 constructs in SMIv2 modules and the reverse",
+	Run:  runLanguage,
+}
+
+func runLanguage(pass *Pass) {
+	language := pass.Module.Language()
+	if language != types.LanguageSMIv1 && language != types.LanguageSMIv2 {
+		return
+	}
+	for _, c := range pass.Module.Constructs() {
+		if c.Language != language {
+			pass.Report(diag.CodeMixedLanguage, c.Pos, "%s is %s, module %s is %s", c.Name, c.Language, pass.Module.Name, language)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mixedMib = `MIXED-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, enterprises FROM SNMPv2-SMI;
+
+mixed OBJECT IDENTIFIER ::= { enterprises 99999 }
+
+mixedObject OBJECT-TYPE
+    SYNTAX INTEGER
+    ACCESS read-only
+    STATUS mandatory
+    DESCRIPTION "SMIv1 clauses"
+    ::= { mixed 1 }
+
+mixedCurrent OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "SMIv2 clauses"
+    ::= { mixed 2 }
+
+END
+`
+
+func TestCheckLanguage(t *testing.T) {
+	module, err := parser.ParseBytes("MIXED-MIB", []byte(mixedMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("MIXED-MIB", module, lint.LanguageRule)
+	var messages []string
+	for _, d := range diags {
+		assert.Equal(t, diag.CodeMixedLanguage, d.Code)
+		assert.Equal(t, 7, d.Pos.Line)
+		messages = append(messages, d.Message)
+	}
+	assert.Equal(t, []string{
+		"ACCESS is SMIv1, module MIXED-MIB is SMIv2",
+		"STATUS mandatory is SMIv1, module MIXED-MIB is SMIv2",
+	}, messages)
+}
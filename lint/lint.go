@@ -0,0 +1,75 @@
+// Package lint checks parsed modules for mistakes that do not necessarily
+// stop them from loading, such as unused or misplaced imports. Checks work on
+// the AST of a single module, so they run without the imported modules being
+// available.
+//
+// Findings are reported as diag.Diagnostic values with GOSMI-x4nnn codes.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+)
+
+// Rule is a named check of a module.
+type Rule struct {
+	// Name identifies the rule, for enabling or disabling it.
+	Name string
+	// Doc is a one line description of what the rule reports.
+	Doc string
+	Run func(*Pass)
+}
+
+// Pass is a rule running on a module.
+type Pass struct {
+	Module   *parser.Module
+	Filename string
+
+	diagnostics []diag.Diagnostic
+}
+
+// Report adds a finding at pos, with the severity code implies.
+func (p *Pass) Report(code string, pos lexer.Position, format string, args ...interface{}) {
+	p.ReportFix(code, pos, "", format, args...)
+}
+
+// ReportFix is like Report, with a suggestion of how to address the finding.
+func (p *Pass) ReportFix(code string, pos lexer.Position, fix string, format string, args ...interface{}) {
+	filename := pos.Filename
+	if filename == "" {
+		filename = p.Filename
+	}
+	p.diagnostics = append(p.diagnostics, diag.Diagnostic{
+		Severity: diag.CodeSeverity(code),
+		Pos:      diag.Position{Filename: filename, Offset: pos.Offset, Line: pos.Line, Column: pos.Column},
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Module:   p.Module.Name.String(),
+		Fix:      fix,
+	})
+}
+
+// Rules returns every rule, in the order Check runs them by default.
+func Rules() []*Rule {
+	return []*Rule{ImportsRule, ExportsRule, EnumsRule, DatesRule, RevisionsRule, LanguageRule, TextRule, EscapesRule}
+}
+
+// Check runs rules, or all rules if none are given, on module parsed from
+// filename and returns their findings ordered by position.
+func Check(filename string, module *parser.Module, rules ...*Rule) []diag.Diagnostic {
+	if len(rules) == 0 {
+		rules = Rules()
+	}
+	pass := &Pass{Module: module, Filename: filename}
+	for _, rule := range rules {
+		rule.Run(pass)
+	}
+	sort.SliceStable(pass.diagnostics, func(i, j int) bool {
+		return pass.diagnostics[i].Pos.Offset < pass.diagnostics[j].Pos.Offset
+	})
+	return pass.diagnostics
+}
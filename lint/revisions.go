@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lukeod/gosmi/diag"
+)
+
+// RevisionsRule checks the REVISION clauses of MODULE-IDENTITY: the newest
+// must match LAST-UPDATED, dates must be unique and descending, and each must
+// be described. Invalid dates are left to DatesRule.
+var RevisionsRule = &Rule{
+	Name: "revisions",
+	Doc:  "reports inconsistent LAST-UPDATED and REVISION clauses",
+	Run:  runRevisions,
+}
+
+func runRevisions(pass *Pass) {
+	identity := pass.Module.Body.Identity
+	if identity == nil || len(identity.Revisions) == 0 {
+		return
+	}
+
+	seen := make(map[time.Time]bool, len(identity.Revisions))
+	var newest, previous time.Time
+	for _, r := range identity.Revisions {
+		if strings.TrimSpace(r.Description) == "" {
+			pass.Report(diag.CodeRevisionDescription, r.Pos, "REVISION %s has no DESCRIPTION", r.Date)
+		}
+		t, err := r.Date.Time()
+		if err != nil {
+			continue
+		}
+		if seen[t] {
+			pass.Report(diag.CodeDuplicateRevision, r.Pos, "REVISION %s is listed more than once", r.Date)
+			continue
+		}
+		seen[t] = true
+		if !previous.IsZero() && t.After(previous) {
+			pass.ReportFix(diag.CodeRevisionOrder, r.Pos, "list revisions newest first",
+				"REVISION %s is newer than the one before it", r.Date)
+		}
+		previous = t
+		if t.After(newest) {
+			newest = t
+		}
+	}
+
+	lastUpdated, err := identity.LastUpdated.Time()
+	if err != nil || newest.IsZero() {
+		return
+	}
+	if !lastUpdated.Equal(newest) {
+		pass.ReportFix(diag.CodeLastUpdatedMismatch, identity.Pos, "add a REVISION for LAST-UPDATED or update it",
+			"LAST-UPDATED %s of %s does not match its newest REVISION", identity.LastUpdated, identity.Name)
+	}
+}
@@ -0,0 +1,50 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const revisionsMib = `REVISIONS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, enterprises FROM SNMPv2-SMI;
+
+revisionsMib MODULE-IDENTITY
+    LAST-UPDATED "202405010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION "Example"
+    REVISION "202301010000Z"
+    DESCRIPTION "Older, listed first"
+    REVISION "202401010000Z"
+    DESCRIPTION ""
+    REVISION "202401010000Z"
+    DESCRIPTION "Duplicate"
+    ::= { enterprises 99999 }
+
+END
+`
+
+func TestCheckRevisions(t *testing.T) {
+	module, err := parser.ParseBytes("REVISIONS-MIB", []byte(revisionsMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("REVISIONS-MIB", module, lint.RevisionsRule)
+	var codes []string
+	for _, d := range diags {
+		codes = append(codes, d.Code)
+	}
+	assert.Equal(t, []string{
+		diag.CodeLastUpdatedMismatch,
+		diag.CodeRevisionDescription,
+		diag.CodeRevisionOrder,
+		diag.CodeDuplicateRevision,
+	}, codes)
+	assert.Equal(t, "LAST-UPDATED 202405010000Z of revisionsMib does not match its newest REVISION", diags[0].Message)
+	assert.Equal(t, "list revisions newest first", diags[2].Fix)
+}
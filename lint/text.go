@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// TextRule reports texts with characters outside of ASCII, to which RFC 2578,
+// section 3.1.1, restricts modules.
+var TextRule = &Rule{
+	Name: "text",
+	Doc:  "reports non-ASCII characters in texts",
+	Run:  runText,
+}
+
+// text is a quoted string of a module, the clause it is given by and the
+// definition it belongs to
+type text struct {
+	pos    lexer.Position
+	clause string
+	owner  types.SmiIdentifier
+	value  string
+}
+
+func runText(pass *Pass) {
+	for _, t := range collectTexts(pass.Module) {
+		for i, r := range t.value {
+			if r < utf8.RuneSelf {
+				continue
+			}
+			if r == utf8.RuneError {
+				pass.Report(diag.CodeNonASCIIText, t.pos, "%s of %s has invalid UTF-8 at byte %d", t.clause, t.owner, i)
+			} else {
+				pass.Report(diag.CodeNonASCIIText, t.pos, "%s of %s has non-ASCII character %U %q", t.clause, t.owner, r, r)
+			}
+			break
+		}
+	}
+}
+
+// EscapesRule reports texts with backslashes, which SMI has no escapes for:
+// other tools keep \n as written and end the text at \", so modules relying
+// on parser.WithEscapes are likely not portable.
+var EscapesRule = &Rule{
+	Name: "escapes",
+	Doc:  "reports backslash sequences in texts",
+	Run:  runEscapes,
+}
+
+func runEscapes(pass *Pass) {
+	for _, t := range collectTexts(pass.Module) {
+		i := strings.IndexByte(t.value, '\\')
+		if i < 0 {
+			continue
+		}
+		sequence := t.value[i:]
+		if _, w := utf8.DecodeRuneInString(sequence[1:]); len(sequence) > 1+w {
+			sequence = sequence[:1+w]
+		}
+		pass.Report(diag.CodeBackslashText, t.pos, "%s of %s has backslash sequence %q, which is not an escape in SMI", t.clause, t.owner, sequence)
+	}
+}
+
+func collectTexts(module *parser.Module) []text {
+	var texts []text
+	add := func(pos lexer.Position, clause string, owner types.SmiIdentifier, value string) {
+		if value != "" {
+			texts = append(texts, text{pos: pos, clause: clause, owner: owner, value: value})
+		}
+	}
+	addDefval := func(pos lexer.Position, owner types.SmiIdentifier, defval *parser.Defval) {
+		if defval != nil && defval.Text != nil {
+			add(pos, "DEFVAL", owner, *defval.Text)
+		}
+	}
+	body := &module.Body
+
+	if identity := body.Identity; identity != nil {
+		add(identity.Pos, "ORGANIZATION", identity.Name, identity.Organization)
+		add(identity.Pos, "CONTACT-INFO", identity.Name, identity.ContactInfo)
+		add(identity.Pos, "DESCRIPTION", identity.Name, identity.Description)
+		for _, r := range identity.Revisions {
+			add(r.Pos, "REVISION DESCRIPTION", identity.Name, r.Description)
+		}
+	}
+
+	for i := range body.Types {
+		t := &body.Types[i]
+		if tc := t.TextualConvention; tc != nil {
+			add(t.Pos, "DISPLAY-HINT", t.Name, tc.DisplayHint)
+			add(t.Pos, "DESCRIPTION", t.Name, tc.Description)
+			add(t.Pos, "REFERENCE", t.Name, tc.Reference)
+		}
+	}
+
+	for i := range body.Nodes {
+		n := &body.Nodes[i]
+		switch {
+		case n.ObjectIdentity != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.ObjectIdentity.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.ObjectIdentity.Reference)
+		case n.ObjectGroup != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.ObjectGroup.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.ObjectGroup.Reference)
+		case n.ObjectType != nil:
+			add(n.Pos, "UNITS", n.Name, n.ObjectType.Units)
+			add(n.Pos, "DESCRIPTION", n.Name, n.ObjectType.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.ObjectType.Reference)
+			addDefval(n.Pos, n.Name, n.ObjectType.Defval)
+		case n.NotificationGroup != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.NotificationGroup.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.NotificationGroup.Reference)
+		case n.NotificationType != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.NotificationType.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.NotificationType.Reference)
+		case n.TrapType != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.TrapType.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.TrapType.Reference)
+		case n.ModuleCompliance != nil:
+			add(n.Pos, "DESCRIPTION", n.Name, n.ModuleCompliance.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.ModuleCompliance.Reference)
+			for _, m := range n.ModuleCompliance.Modules {
+				for _, c := range m.Compliances {
+					if c.Group != nil {
+						add(c.Group.Pos, "GROUP DESCRIPTION", n.Name, c.Group.Description)
+					}
+					if c.Object != nil {
+						add(c.Object.Pos, "OBJECT DESCRIPTION", n.Name, c.Object.Description)
+					}
+				}
+			}
+		case n.AgentCapabilities != nil:
+			add(n.Pos, "PRODUCT-RELEASE", n.Name, n.AgentCapabilities.ProductRelease)
+			add(n.Pos, "DESCRIPTION", n.Name, n.AgentCapabilities.Description)
+			add(n.Pos, "REFERENCE", n.Name, n.AgentCapabilities.Reference)
+			for _, m := range n.AgentCapabilities.Modules {
+				for _, v := range m.Variations {
+					add(v.Pos, "VARIATION DESCRIPTION", n.Name, v.Description)
+					addDefval(v.Pos, n.Name, v.Defval)
+				}
+			}
+		}
+	}
+	return texts
+}
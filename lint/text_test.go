@@ -0,0 +1,77 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textMib = `TEXT-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, enterprises FROM SNMPv2-SMI;
+
+text OBJECT IDENTIFIER ::= { enterprises 99999 }
+
+textTemperature OBJECT-TYPE
+    SYNTAX INTEGER
+    UNITS "°C"
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The temperature, in degrees Celsius."
+    ::= { text 1 }
+
+textName OBJECT-TYPE
+    SYNTAX OCTET STRING
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "The name of the café, or “none”."
+    DEFVAL { "none" }
+    ::= { text 2 }
+END
+`
+
+func TestCheckText(t *testing.T) {
+	module, err := parser.ParseBytes("TEXT-MIB", []byte(textMib))
+	require.NoError(t, err)
+
+	diags := lint.Check("TEXT-MIB", module, lint.TextRule)
+	require.Len(t, diags, 2)
+	for _, d := range diags {
+		assert.Equal(t, diag.CodeNonASCIIText, d.Code)
+		assert.Equal(t, diag.SeverityWarning, d.Severity)
+	}
+	assert.Equal(t, `UNITS of textTemperature has non-ASCII character U+00B0 '°'`, diags[0].Message)
+	assert.Equal(t, 7, diags[0].Pos.Line)
+	// Only the first non-ASCII character of a text is reported
+	assert.Equal(t, `DESCRIPTION of textName has non-ASCII character U+00E9 'é'`, diags[1].Message)
+}
+
+func TestCheckEscapes(t *testing.T) {
+	module, err := parser.ParseBytes("ESCAPES-MIB", []byte(`ESCAPES-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, enterprises FROM SNMPv2-SMI;
+
+escapes OBJECT IDENTIFIER ::= { enterprises 99999 }
+
+escapesPath OBJECT-TYPE
+    SYNTAX OCTET STRING
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The path, such as C:\temp\n, or none."
+    REFERENCE "Plain."
+    ::= { escapes 1 }
+END
+`))
+	require.NoError(t, err)
+
+	diags := lint.Check("ESCAPES-MIB", module, lint.EscapesRule)
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.CodeBackslashText, diags[0].Code)
+	assert.Equal(t, diag.SeverityWarning, diags[0].Severity)
+	assert.Equal(t, `DESCRIPTION of escapesPath has backslash sequence "\\t", which is not an escape in SMI`, diags[0].Message)
+	assert.Equal(t, 7, diags[0].Pos.Line)
+}
@@ -0,0 +1,95 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+)
+
+// diagnostics returns the lexical and syntax errors of d, and warnings for
+// imports from modules that cannot be found and OID parents that are neither
+// defined nor imported.
+func (s *Server) diagnostics(d *document) []Diagnostic {
+	out := make([]Diagnostic, 0, len(d.diagnostics))
+	for _, dg := range d.diagnostics {
+		r := Range{}
+		if dg.Pos.Line > 0 {
+			r = d.wordRange(dg.Pos.Offset)
+		}
+		out = append(out, Diagnostic{
+			Range:    r,
+			Severity: severity(dg.Severity),
+			Code:     dg.Code,
+			Source:   "gosmi",
+			Message:  dg.Message,
+		})
+	}
+	if d.module == nil {
+		return out
+	}
+
+	report := func(code string, offset, length int, message string) {
+		out = append(out, Diagnostic{
+			Range:    d.span(offset, length),
+			Severity: severity(diag.CodeSeverity(code)),
+			Code:     code,
+			Source:   "gosmi",
+			Message:  message,
+		})
+	}
+	imported := make(map[string]bool)
+	for _, imp := range d.module.Body.Imports {
+		for _, name := range imp.Names {
+			imported[name.String()] = true
+		}
+		if s.module(imp.Module.String()) != nil {
+			continue
+		}
+		// The module name follows the imported names and FROM
+		offset := imp.Pos.Offset
+		if tok, ok := d.identAfter(imp.Pos.Offset, imp.Module.String()); ok {
+			offset = tok.Pos.Offset
+		}
+		report(diag.CodeImportNotFound, offset, len(imp.Module), fmt.Sprintf("Load import %s: module not found", imp.Module))
+	}
+	for _, def := range d.order {
+		if def.node == nil || def.node.Oid == nil || len(def.node.Oid.SubIdentifiers) == 0 {
+			continue
+		}
+		sub := def.node.Oid.SubIdentifiers[0]
+		if sub.Name == nil || sub.Number != nil {
+			continue
+		}
+		name := sub.Name.String()
+		if _, ok := d.defs[name]; ok || imported[name] {
+			continue
+		}
+		if _, ok := rootArcs[name]; ok {
+			continue
+		}
+		report(diag.CodeUnknownOidParent, sub.Pos.Offset, len(name), fmt.Sprintf("Unknown OID parent %s", name))
+	}
+	return out
+}
+
+// identAfter returns the first identifier token with the given value at or
+// after offset.
+func (d *document) identAfter(offset int, value string) (lexer.Token, bool) {
+	for _, tok := range d.idents {
+		if tok.Pos.Offset >= offset && tok.Value == value {
+			return tok, true
+		}
+	}
+	return lexer.Token{}, false
+}
+
+func severity(s diag.Severity) DiagnosticSeverity {
+	switch s {
+	case diag.SeverityError:
+		return DiagnosticError
+	case diag.SeverityWarning:
+		return DiagnosticWarning
+	}
+	return DiagnosticInformation
+}
@@ -0,0 +1,239 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	gosmilexer "github.com/lukeod/gosmi/parser/lexer"
+)
+
+// document is a parsed module, either open in the editor or loaded from the
+// search path to resolve imports.
+type document struct {
+	uri         string
+	text        string
+	lineStarts  []int
+	module      *parser.Module
+	diagnostics []diag.Diagnostic
+	idents      []lexer.Token
+	defs        map[string]*definition
+	order       []*definition
+}
+
+// definition is a named assignment at the top level of a module. Exactly one
+// of identity, typ, node and macro is set.
+type definition struct {
+	name     string
+	offset   int
+	kind     SymbolKind
+	detail   string
+	identity *parser.ModuleIdentity
+	typ      *parser.Type
+	node     *parser.Node
+	macro    *parser.Macro
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{
+		uri:        uri,
+		text:       text,
+		lineStarts: []int{0},
+		defs:       make(map[string]*definition),
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+
+	filename := uriToPath(uri)
+	var diagnostics diag.Collector
+	d.module, _ = parser.ParseBytes(filename, []byte(text), parser.WithDiagnostics(&diagnostics))
+	d.diagnostics = diagnostics.Diagnostics()
+
+	// Lex again for the identifiers, which the AST only keeps the positions
+	// of where they are defined. Lexical errors were reported by the parse.
+	identType := (&gosmilexer.LexerDefinition{}).Symbols()["Ident"]
+	lex, _ := (&gosmilexer.LexerDefinition{Diagnostics: &diag.Collector{}}).LexString(filename, text)
+	for {
+		tok, err := lex.Next()
+		if err != nil || tok.EOF() {
+			break
+		}
+		if tok.Type == identType {
+			d.idents = append(d.idents, tok)
+		}
+	}
+
+	d.index()
+	return d
+}
+
+func (d *document) index() {
+	if d.module == nil {
+		return
+	}
+	add := func(def *definition) {
+		if _, ok := d.defs[def.name]; !ok {
+			d.defs[def.name] = def
+		}
+		d.order = append(d.order, def)
+	}
+	body := &d.module.Body
+	if body.Identity != nil {
+		add(&definition{name: body.Identity.Name.String(), offset: body.Identity.Pos.Offset, kind: SymbolKindModule, detail: "MODULE-IDENTITY", identity: body.Identity})
+	}
+	for i := range body.Types {
+		t := &body.Types[i]
+		def := &definition{name: t.Name.String(), offset: t.Pos.Offset, kind: SymbolKindClass, typ: t}
+		switch {
+		case t.TextualConvention != nil:
+			def.detail = "TEXTUAL-CONVENTION"
+		case t.Sequence != nil:
+			def.kind, def.detail = SymbolKindStruct, string(t.Sequence.Type)
+		}
+		add(def)
+	}
+	for i := range body.Nodes {
+		n := &body.Nodes[i]
+		def := &definition{name: n.Name.String(), offset: n.Pos.Offset, node: n}
+		def.kind, def.detail = nodeKind(n)
+		add(def)
+	}
+	for i := range body.Macros {
+		m := &body.Macros[i]
+		add(&definition{name: m.Name.String(), offset: m.Pos.Offset, kind: SymbolKindFunction, detail: "MACRO", macro: m})
+	}
+	for i := range body.Values {
+		v := &body.Values[i]
+		add(&definition{name: v.Name.String(), offset: v.Pos.Offset, kind: SymbolKindVariable, detail: v.Syntax.Name.String()})
+	}
+	for i := range body.Invocations {
+		m := &body.Invocations[i]
+		add(&definition{name: m.Name.String(), offset: m.Pos.Offset, kind: SymbolKindNamespace, detail: m.Macro.String()})
+	}
+	sort.SliceStable(d.order, func(i, j int) bool { return d.order[i].offset < d.order[j].offset })
+}
+
+func nodeKind(n *parser.Node) (SymbolKind, string) {
+	switch {
+	case n.ObjectIdentity != nil:
+		return SymbolKindNamespace, "OBJECT-IDENTITY"
+	case n.ObjectGroup != nil:
+		return SymbolKindInterface, "OBJECT-GROUP"
+	case n.ObjectType != nil:
+		return SymbolKindVariable, "OBJECT-TYPE"
+	case n.NotificationGroup != nil:
+		return SymbolKindInterface, "NOTIFICATION-GROUP"
+	case n.NotificationType != nil:
+		return SymbolKindEvent, "NOTIFICATION-TYPE"
+	case n.ModuleCompliance != nil:
+		return SymbolKindInterface, "MODULE-COMPLIANCE"
+	case n.AgentCapabilities != nil:
+		return SymbolKindInterface, "AGENT-CAPABILITIES"
+	case n.TrapType != nil:
+		return SymbolKindEvent, "TRAP-TYPE"
+	}
+	return SymbolKindNamespace, "OBJECT IDENTIFIER"
+}
+
+// name returns the name of the module in the document, or an empty string
+// if it could not be parsed.
+func (d *document) name() string {
+	if d.module == nil {
+		return ""
+	}
+	return d.module.Name.String()
+}
+
+// identAt returns the identifier token at or immediately before offset.
+func (d *document) identAt(offset int) (lexer.Token, bool) {
+	i := sort.Search(len(d.idents), func(i int) bool {
+		return d.idents[i].Pos.Offset+len(d.idents[i].Value) >= offset
+	})
+	if i < len(d.idents) && d.idents[i].Pos.Offset <= offset {
+		return d.idents[i], true
+	}
+	return lexer.Token{}, false
+}
+
+// position converts a byte offset into an LSP position.
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := sort.SearchInts(d.lineStarts, offset+1) - 1
+	return Position{Line: line, Character: utf16Len(d.text[d.lineStarts[line]:offset])}
+}
+
+// offset converts an LSP position into a byte offset, clamped to the line.
+func (d *document) offset(p Position) int {
+	switch {
+	case p.Line < 0:
+		return 0
+	case p.Line >= len(d.lineStarts):
+		return len(d.text)
+	}
+	start := d.lineStarts[p.Line]
+	n := 0
+	for i, r := range d.text[start:] {
+		if n >= p.Character || r == '\n' {
+			return start + i
+		}
+		n += utf16Len(string(r))
+	}
+	return len(d.text)
+}
+
+// span returns the range of length bytes starting at offset.
+func (d *document) span(offset, length int) Range {
+	return Range{Start: d.position(offset), End: d.position(offset + length)}
+}
+
+// wordRange returns the range of the word starting at offset, which is empty
+// at the end of the document or a line.
+func (d *document) wordRange(offset int) Range {
+	end := offset
+	for end < len(d.text) && !unicode.IsSpace(rune(d.text[end])) {
+		end++
+	}
+	return d.span(offset, end-offset)
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive letters
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Top level arcs of the OID tree, which no module defines
+var rootArcs = map[string]types.SmiSubId{
+	"ccitt":           0,
+	"iso":             1,
+	"joint-iso-ccitt": 2,
+}
+
+// Bounds the length of OID parent chains, which only cyclic ones exceed
+const maxOidDepth = 128
+
+func (s *Server) hover(p TextDocumentPositionParams) *Hover {
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	tok, ok := d.identAt(d.offset(p.Position))
+	if !ok {
+		return nil
+	}
+	m, def := s.lookup(d, tok.Value)
+	if def == nil {
+		return nil
+	}
+	r := d.span(tok.Pos.Offset, len(tok.Value))
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: s.describe(m, def)},
+		Range:    &r,
+	}
+}
+
+// describe renders def of module d as markdown.
+func (s *Server) describe(d *document, def *definition) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** %s (%s)\n", def.name, def.detail, d.name())
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\n%s: %s  ", name, value)
+		}
+	}
+	if oid, ok := s.resolveOid(d, def.name, 0); ok {
+		field("OID", "`"+oid.String()+"`")
+	}
+
+	var description string
+	switch {
+	case def.identity != nil:
+		field("Last updated", string(def.identity.LastUpdated))
+		field("Organization", def.identity.Organization)
+		description = def.identity.Description
+	case def.typ != nil:
+		t := def.typ
+		switch {
+		case t.TextualConvention != nil:
+			field("Syntax", "`"+syntaxTypeString(t.TextualConvention.Syntax)+"`")
+			field("Display hint", t.TextualConvention.DisplayHint)
+			field("Status", string(t.TextualConvention.Status))
+			description = t.TextualConvention.Description
+		case t.Implicit != nil:
+			field("Syntax", "`"+t.Implicit.Tag+" IMPLICIT "+syntaxTypeString(t.Implicit.Syntax)+"`")
+		case t.Syntax != nil:
+			field("Syntax", "`"+syntaxTypeString(*t.Syntax)+"`")
+		}
+	case def.node != nil:
+		description = s.describeNode(def.node, field)
+	}
+	if description != "" {
+		b.WriteString("\n\n" + description)
+	}
+	return b.String()
+}
+
+func (s *Server) describeNode(n *parser.Node, field func(name, value string)) string {
+	switch {
+	case n.ObjectType != nil:
+		o := n.ObjectType
+		syntax := ""
+		switch {
+		case o.Syntax.Sequence != nil:
+			syntax = "SEQUENCE OF " + o.Syntax.Sequence.String()
+		case o.Syntax.Type != nil:
+			syntax = syntaxTypeString(*o.Syntax.Type)
+		}
+		field("Syntax", "`"+syntax+"`")
+		field("Units", o.Units)
+		field("Access", string(o.Access))
+		field("Status", string(o.Status))
+		return o.Description
+	case n.ObjectIdentity != nil:
+		field("Status", string(n.ObjectIdentity.Status))
+		return n.ObjectIdentity.Description
+	case n.ObjectGroup != nil:
+		field("Status", string(n.ObjectGroup.Status))
+		return n.ObjectGroup.Description
+	case n.NotificationType != nil:
+		field("Objects", identifierList(n.NotificationType.Objects))
+		field("Status", string(n.NotificationType.Status))
+		return n.NotificationType.Description
+	case n.NotificationGroup != nil:
+		field("Status", string(n.NotificationGroup.Status))
+		return n.NotificationGroup.Description
+	case n.ModuleCompliance != nil:
+		field("Status", string(n.ModuleCompliance.Status))
+		return n.ModuleCompliance.Description
+	case n.AgentCapabilities != nil:
+		field("Status", string(n.AgentCapabilities.Status))
+		return n.AgentCapabilities.Description
+	case n.TrapType != nil:
+		field("Enterprise", n.TrapType.Enterprise.String())
+		field("Variables", identifierList(n.TrapType.Objects))
+		return n.TrapType.Description
+	}
+	return ""
+}
+
+// resolveOid returns the OID of the node or module identity called name in
+// module d, if all its parents can be found.
+func (s *Server) resolveOid(d *document, name string, depth int) (types.Oid, bool) {
+	if depth > maxOidDepth {
+		return nil, false
+	}
+	m, def := s.lookup(d, name)
+	if def == nil {
+		arc, ok := rootArcs[name]
+		return types.Oid{arc}, ok
+	}
+	var oid *parser.Oid
+	switch {
+	case def.identity != nil:
+		oid = &def.identity.Oid
+	case def.node != nil && def.node.Oid != nil:
+		oid = def.node.Oid
+	default:
+		return nil, false
+	}
+
+	var out types.Oid
+	for i, sub := range oid.SubIdentifiers {
+		switch {
+		case sub.Number != nil:
+			out = append(out, *sub.Number)
+		case i == 0 && sub.Name != nil:
+			parent, ok := s.resolveOid(m, sub.Name.String(), depth+1)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, parent...)
+		default:
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+func syntaxTypeString(t parser.SyntaxType) string {
+	s := t.Name.String()
+	switch {
+	case t.SubType != nil && len(t.SubType.OctetString) > 0:
+		s += " (SIZE (" + rangesString(t.SubType.OctetString) + "))"
+	case t.SubType != nil:
+		s += " (" + rangesString(t.SubType.Integer) + ")"
+	case len(t.Enum) > 0:
+		names := make([]string, len(t.Enum))
+		for i, n := range t.Enum {
+			names[i] = fmt.Sprintf("%s(%s)", n.Name, n.Value)
+		}
+		s += " { " + strings.Join(names, ", ") + " }"
+	}
+	return s
+}
+
+func rangesString(ranges []parser.Range) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.Start
+		if r.End != "" {
+			parts[i] += ".." + r.End
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func identifierList(ids []types.SmiIdentifier) string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = id.String()
+	}
+	return strings.Join(names, ", ")
+}
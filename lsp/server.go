@@ -0,0 +1,296 @@
+// Package lsp implements a language server for SMI modules. It provides
+// diagnostics, document symbols, hover information and go-to-definition for
+// imports, type references and OID parents, speaking the Language Server
+// Protocol over a stream such as the standard input and output of a process.
+//
+// The server works on the parser's AST, so modules that fail to parse only
+// get the features the partially parsed AST allows.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Server is a language server. It handles one client, and its methods must
+// not be called concurrently.
+type Server struct {
+	paths     []string
+	documents map[string]*document // open documents, by URI
+	modules   map[string]*document // modules loaded from the search path, by name
+	out       io.Writer
+	shutdown  bool
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithPath adds directories to search for imported modules. The directories
+// of open documents and the workspace root are always searched, after these.
+func WithPath(dirs ...string) Option {
+	return func(s *Server) { s.paths = append(s.paths, dirs...) }
+}
+
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		documents: make(map[string]*document),
+		modules:   make(map[string]*document),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// errExit is returned by handle when the client asks the server to exit.
+var errExit = &responseError{Message: "exit"}
+
+// Serve reads messages from r and writes responses and notifications to w
+// until the client sends the exit notification or r is exhausted. It returns
+// an error if the client exits without shutting the server down first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	br := bufio.NewReader(r)
+	for {
+		content, err := readMessage(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Read message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := writeMessage(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &responseError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, rerr := s.handle(req.Method, req.Params)
+		if rerr == errExit {
+			if !s.shutdown {
+				return errors.New("Exit without shutdown")
+			}
+			return nil
+		}
+		if req.ID == nil {
+			// Notifications get no response, not even errors
+			continue
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID}
+		if rerr != nil {
+			resp.Error = rerr
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Error = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+		if err := writeMessage(w, resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, *responseError) {
+	decode := func(v interface{}) *responseError {
+		if err := json.Unmarshal(params, v); err != nil {
+			return &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch method {
+	case "initialize":
+		var p InitializeParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		if p.RootURI != "" {
+			s.paths = append(s.paths, uriToPath(p.RootURI))
+		}
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       textDocumentSyncFull,
+				HoverProvider:          true,
+				DefinitionProvider:     true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "gosmi"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "exit":
+		return nil, errExit
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		s.open(p.TextDocument.URI, p.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		if n := len(p.ContentChanges); n > 0 {
+			s.open(p.TextDocument.URI, p.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		delete(s.documents, p.TextDocument.URI)
+		s.publish(p.TextDocument.URI, []Diagnostic{})
+		return nil, nil
+	case "textDocument/definition":
+		var p TextDocumentPositionParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		return s.definition(p), nil
+	case "textDocument/hover":
+		var p TextDocumentPositionParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		return s.hover(p), nil
+	case "textDocument/documentSymbol":
+		var p DocumentSymbolParams
+		if err := decode(&p); err != nil {
+			return nil, err
+		}
+		return s.documentSymbols(p.TextDocument.URI), nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("Method %s not found", method)}
+}
+
+func (s *Server) open(uri, text string) {
+	d := newDocument(uri, text)
+	s.documents[uri] = d
+	s.publish(uri, s.diagnostics(d))
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) {
+	// A client that went away shows up as a read error in Serve
+	_ = writeMessage(s.out, notification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+// module returns the module called name, preferring open documents over
+// files in the search path.
+func (s *Server) module(name string) *document {
+	for _, d := range s.documents {
+		if d.name() == name {
+			return d
+		}
+	}
+	if d, ok := s.modules[name]; ok {
+		return d
+	}
+
+	dirs := append([]string{}, s.paths...)
+	for uri := range s.documents {
+		dirs = append(dirs, filepath.Dir(uriToPath(uri)))
+	}
+	for _, dir := range dirs {
+		for _, filename := range []string{name + ".mib", name + ".txt", name + ".my", name} {
+			path := filepath.Join(dir, filename)
+			b, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if d := newDocument(pathToURI(path), string(b)); d.name() == name {
+				s.modules[name] = d
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// lookup finds the definition name refers to in d, following imports.
+func (s *Server) lookup(d *document, name string) (*document, *definition) {
+	if def, ok := d.defs[name]; ok {
+		return d, def
+	}
+	if d.module == nil {
+		return nil, nil
+	}
+	for _, imp := range d.module.Body.Imports {
+		for _, imported := range imp.Names {
+			if imported.String() != name {
+				continue
+			}
+			if m := s.module(imp.Module.String()); m != nil {
+				if def, ok := m.defs[name]; ok {
+					return m, def
+				}
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) definition(p TextDocumentPositionParams) *Location {
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	tok, ok := d.identAt(d.offset(p.Position))
+	if !ok {
+		return nil
+	}
+	if m, def := s.lookup(d, tok.Value); def != nil {
+		return &Location{URI: m.uri, Range: m.span(def.offset, len(def.name))}
+	}
+
+	// Module names in IMPORTS
+	if d.module == nil {
+		return nil
+	}
+	for _, imp := range d.module.Body.Imports {
+		if imp.Module.String() != tok.Value {
+			continue
+		}
+		if m := s.module(tok.Value); m != nil {
+			return &Location{URI: m.uri, Range: m.span(m.module.Pos.Offset, len(tok.Value))}
+		}
+	}
+	return nil
+}
+
+func (s *Server) documentSymbols(uri string) []DocumentSymbol {
+	d, ok := s.documents[uri]
+	if !ok {
+		return nil
+	}
+	symbols := make([]DocumentSymbol, 0, len(d.order))
+	for i, def := range d.order {
+		// Definitions extend up to the next one
+		end := len(d.text)
+		if i+1 < len(d.order) {
+			end = d.order[i+1].offset
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           def.name,
+			Detail:         def.detail,
+			Kind:           def.kind,
+			Range:          Range{Start: d.position(def.offset), End: d.position(end)},
+			SelectionRange: d.span(def.offset, len(def.name)),
+		})
+	}
+	return symbols
+}
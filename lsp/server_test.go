@@ -0,0 +1,207 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lukeod/gosmi/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseMib = `BASE-MIB DEFINITIONS ::= BEGIN
+org OBJECT IDENTIFIER ::= { iso 3 }
+baseRoot OBJECT IDENTIFIER ::= { org 6 1 }
+
+Percent ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "d"
+    STATUS current
+    DESCRIPTION "A percentage."
+    SYNTAX INTEGER (0..100)
+END
+`
+
+const docMib = `DOC-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    baseRoot, Percent FROM BASE-MIB
+    other FROM MISSING-MIB;
+
+docRoot OBJECT IDENTIFIER ::= { baseRoot 7 }
+
+docLoad OBJECT-TYPE
+    SYNTAX Percent
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The load."
+    ::= { docRoot 1 }
+
+docLost OBJECT IDENTIFIER ::= { nowhere 1 }
+END
+`
+
+type client struct {
+	in bytes.Buffer
+	id int
+}
+
+func (c *client) send(method string, params interface{}) {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if !strings.HasPrefix(method, "textDocument/did") && method != "exit" {
+		c.id++
+		msg["id"] = c.id
+	}
+	b, _ := json.Marshal(msg)
+	fmt.Fprintf(&c.in, "Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+}
+
+func readMessages(t *testing.T, r io.Reader) (responses map[int]json.RawMessage, notifications []message) {
+	responses = make(map[int]json.RawMessage)
+	br := bufio.NewReader(r)
+	for {
+		header, err := textproto.NewReader(br).ReadMIMEHeader()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		require.NoError(t, err)
+		content := make([]byte, length)
+		_, err = io.ReadFull(br, content)
+		require.NoError(t, err)
+
+		var msg message
+		require.NoError(t, json.Unmarshal(content, &msg))
+		if msg.ID != nil {
+			responses[*msg.ID] = msg.Result
+		} else {
+			notifications = append(notifications, msg)
+		}
+	}
+}
+
+func position(text, needle string, n int) lsp.Position {
+	i := -1
+	for ; n >= 0; n-- {
+		i += strings.Index(text[i+1:], needle) + 1
+	}
+	line := strings.Count(text[:i], "\n")
+	return lsp.Position{Line: line, Character: i - strings.LastIndex(text[:i], "\n") - 1}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "BASE-MIB.mib"), []byte(baseMib), 0o644))
+	docPath := filepath.Join(dir, "DOC-MIB.mib")
+	docURI := "file://" + filepath.ToSlash(docPath)
+	baseURI := "file://" + filepath.ToSlash(filepath.Join(dir, "BASE-MIB.mib"))
+	doc := map[string]string{"uri": docURI}
+	at := func(needle string, n int) map[string]interface{} {
+		return map[string]interface{}{"textDocument": doc, "position": position(docMib, needle, n)}
+	}
+
+	var c client
+	c.send("initialize", map[string]interface{}{})
+	c.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": docURI, "languageId": "mib", "version": 1, "text": docMib},
+	})
+	c.send("textDocument/definition", at("Percent", 1))                                // 2: type reference
+	c.send("textDocument/definition", at("baseRoot", 1))                               // 3: OID parent
+	c.send("textDocument/definition", at("BASE-MIB", 0))                               // 4: imported module
+	c.send("textDocument/definition", at("docRoot", 1))                                // 5: local OID parent
+	c.send("textDocument/hover", at("docLoad", 0))                                     // 6
+	c.send("textDocument/documentSymbol", map[string]interface{}{"textDocument": doc}) // 7
+	c.send("textDocument/hover", at("DEFINITIONS", 0))                                 // 8: keyword
+	c.send("shutdown", nil)
+	c.send("exit", nil)
+
+	var out bytes.Buffer
+	require.NoError(t, lsp.NewServer().Serve(&c.in, &out))
+	responses, notifications := readMessages(t, &out)
+
+	var init lsp.InitializeResult
+	require.NoError(t, json.Unmarshal(responses[1], &init))
+	assert.True(t, init.Capabilities.DefinitionProvider)
+
+	location := func(id int) lsp.Location {
+		var loc lsp.Location
+		require.NoError(t, json.Unmarshal(responses[id], &loc), "response %d", id)
+		return loc
+	}
+	assert.Equal(t, lsp.Location{URI: baseURI, Range: lsp.Range{Start: lsp.Position{Line: 4, Character: 0}, End: lsp.Position{Line: 4, Character: 7}}}, location(2))
+	assert.Equal(t, lsp.Location{URI: baseURI, Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 0}, End: lsp.Position{Line: 2, Character: 8}}}, location(3))
+	assert.Equal(t, lsp.Location{URI: baseURI, Range: lsp.Range{Start: lsp.Position{Line: 0, Character: 0}, End: lsp.Position{Line: 0, Character: 8}}}, location(4))
+	assert.Equal(t, lsp.Location{URI: docURI, Range: lsp.Range{Start: lsp.Position{Line: 5, Character: 0}, End: lsp.Position{Line: 5, Character: 7}}}, location(5))
+
+	var hover lsp.Hover
+	require.NoError(t, json.Unmarshal(responses[6], &hover))
+	assert.Contains(t, hover.Contents.Value, "**docLoad** OBJECT-TYPE (DOC-MIB)")
+	assert.Contains(t, hover.Contents.Value, "OID: `1.3.6.1.7.1`")
+	assert.Contains(t, hover.Contents.Value, "Syntax: `Percent`")
+	assert.Contains(t, hover.Contents.Value, "The load.")
+
+	var symbols []lsp.DocumentSymbol
+	require.NoError(t, json.Unmarshal(responses[7], &symbols))
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name+" "+s.Detail)
+	}
+	assert.Equal(t, []string{"docRoot OBJECT IDENTIFIER", "docLoad OBJECT-TYPE", "docLost OBJECT IDENTIFIER"}, names)
+
+	assert.Equal(t, "null", string(responses[8]))
+
+	require.Len(t, notifications, 1)
+	var published lsp.PublishDiagnosticsParams
+	require.NoError(t, json.Unmarshal(notifications[0].Params, &published))
+	assert.Equal(t, docURI, published.URI)
+	var codes []string
+	for _, d := range published.Diagnostics {
+		codes = append(codes, d.Code+" "+d.Message)
+	}
+	assert.Equal(t, []string{
+		"GOSMI-W3001 Load import MISSING-MIB: module not found",
+		"GOSMI-W3004 Unknown OID parent nowhere",
+	}, codes)
+	assert.Equal(t, position(docMib, "nowhere", 0), published.Diagnostics[1].Range.Start)
+}
+
+func TestServerSyntaxError(t *testing.T) {
+	var c client
+	c.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file:///BROKEN-MIB", "text": "BROKEN-MIB DEFINITIONS ::= BEGIN\nfoo OBJECT-TYPE\nEND\n"},
+	})
+	c.send("shutdown", nil)
+	c.send("exit", nil)
+
+	var out bytes.Buffer
+	require.NoError(t, lsp.NewServer().Serve(&c.in, &out))
+	_, notifications := readMessages(t, &out)
+	require.Len(t, notifications, 1)
+	var published lsp.PublishDiagnosticsParams
+	require.NoError(t, json.Unmarshal(notifications[0].Params, &published))
+	require.NotEmpty(t, published.Diagnostics)
+	assert.Equal(t, "GOSMI-E2001", published.Diagnostics[0].Code)
+	assert.Equal(t, lsp.DiagnosticError, published.Diagnostics[0].Severity)
+	assert.Equal(t, lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 1, Character: 3}}, published.Diagnostics[0].Range)
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	var c client
+	c.send("exit", nil)
+	assert.Error(t, lsp.NewServer().Serve(&c.in, io.Discard))
+}
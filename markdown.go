@@ -0,0 +1,233 @@
+package gosmi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/types"
+)
+
+// WriteMarkdown documents the module in Markdown: a header with the module
+// identity, a table of contents, then a section per type and per node with
+// its OID, syntax, enumerations, ranges and description. Nodes link to the
+// nodes they refer to, the objects of notifications and groups and the index
+// of rows, and list the nodes referring to them. Sections are anchored by
+// the name of the definition.
+func WriteMarkdown(w io.Writer, module SmiModule) error {
+	bw := bufio.NewWriter(w)
+	doc := markdownDoc{w: bw, module: module}
+	doc.write()
+	return bw.Flush()
+}
+
+type markdownDoc struct {
+	w      *bufio.Writer
+	module SmiModule
+	// local holds the names defined by the module, which are linked to
+	local map[string]bool
+}
+
+func (d *markdownDoc) write() {
+	typs := d.module.GetTypes()
+	var nodes []SmiNode
+	for _, node := range d.module.GetNodes() {
+		// Such as the zero node under the enterprise of SMIv1 traps
+		if node.Decl != types.DeclImplObject {
+			nodes = append(nodes, node)
+		}
+	}
+	d.local = make(map[string]bool, len(typs)+len(nodes))
+	for _, t := range typs {
+		d.local[t.Name] = true
+	}
+	// References from a node to others, and the reverse
+	refs := make(map[string][]SmiNode)
+	refBy := make(map[string][]string)
+	for _, node := range nodes {
+		d.local[node.Name] = true
+		var to []SmiNode
+		switch node.Kind {
+		case types.NodeNotification, types.NodeGroup:
+			to = node.GetNotificationObjects()
+		case types.NodeRow:
+			to = node.GetIndex()
+		}
+		refs[node.Name] = to
+		for _, n := range to {
+			refBy[n.Name] = append(refBy[n.Name], node.Name)
+		}
+	}
+
+	fmt.Fprintf(d.w, "# %s\n\n", d.module.Name)
+	d.field("Language", d.module.Language.String())
+	d.field("Organization", d.module.Organization)
+	if lastUpdated := d.module.LastUpdated(); !lastUpdated.IsZero() {
+		d.field("Last updated", lastUpdated.Format("2006-01-02 15:04 MST"))
+	}
+	d.field("Contact", d.module.ContactInfo)
+	d.w.WriteString("\n")
+	d.text(d.module.Description)
+
+	d.w.WriteString("## Contents\n\n")
+	if len(typs) > 0 {
+		d.w.WriteString("- [Types](#types)\n")
+		for _, t := range typs {
+			fmt.Fprintf(d.w, "  - %s\n", d.link(t.Name))
+		}
+	}
+	if len(nodes) > 0 {
+		d.w.WriteString("- [Objects](#objects)\n")
+		for _, node := range nodes {
+			fmt.Fprintf(d.w, "  - %s\n", d.link(node.Name))
+		}
+	}
+	d.w.WriteString("\n")
+
+	if len(typs) > 0 {
+		d.w.WriteString("## Types\n\n")
+	}
+	for _, t := range typs {
+		d.heading(t.Name)
+		d.field("Base type", t.BaseType.String())
+		if parent, ok := t.GetParent(); ok && parent.Name != "" {
+			d.field("Parent", d.link(parent.Name))
+		}
+		d.field("Status", statusText(t.Status))
+		d.field("Display hint", code(t.Format))
+		d.field("Units", t.Units)
+		d.field("Ranges", rangesText(t.BaseType, t.Ranges))
+		d.w.WriteString("\n")
+		d.enum(&t)
+		d.text(t.Description)
+	}
+
+	if len(nodes) > 0 {
+		d.w.WriteString("## Objects\n\n")
+	}
+	for _, node := range nodes {
+		d.heading(node.Name)
+		d.field("OID", code(node.RenderNumeric()))
+		d.field("Kind", node.Kind.String())
+		if node.Type != nil {
+			syntax := node.Type.Name
+			if syntax == "" {
+				syntax = node.Type.BaseType.String()
+			}
+			d.field("Syntax", d.link(syntax))
+			d.field("Ranges", rangesText(node.Type.BaseType, node.Type.Ranges))
+		}
+		if node.Access != types.AccessUnknown {
+			d.field("Access", node.Access.String())
+		}
+		d.field("Status", statusText(node.Status))
+		d.field("Units", node.Units)
+		label := "Objects"
+		if node.Kind == types.NodeRow {
+			label = "Index"
+		}
+		d.field(label, d.links(refs[node.Name]))
+		var by []string
+		for _, name := range refBy[node.Name] {
+			by = append(by, d.link(name))
+		}
+		d.field("Referenced by", strings.Join(by, ", "))
+		d.w.WriteString("\n")
+		if node.SmiType != nil {
+			d.enum(node.SmiType)
+		}
+		d.text(node.Description)
+	}
+}
+
+func (d *markdownDoc) heading(name string) {
+	fmt.Fprintf(d.w, "<a id=\"%s\"></a>\n\n### %s\n\n", name, name)
+	d.w.WriteString("| | |\n|---|---|\n")
+}
+
+// field writes a row of the table of a section, skipping empty values
+func (d *markdownDoc) field(name, value string) {
+	if value == "" {
+		return
+	}
+	value = strings.ReplaceAll(strings.TrimSpace(value), "|", "\\|")
+	fmt.Fprintf(d.w, "| %s | %s |\n", name, strings.Join(strings.Fields(value), " "))
+}
+
+// text writes a description as a paragraph, its lines unindented
+func (d *markdownDoc) text(s string) {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if text := strings.Join(lines, "\n"); text != "" {
+		d.w.WriteString(text + "\n\n")
+	}
+}
+
+func (d *markdownDoc) enum(t *SmiType) {
+	if t.Enum == nil || len(t.Enum.Values) == 0 {
+		return
+	}
+	label := "Value"
+	if t.BaseType == types.BaseTypeBits {
+		label = "Bit"
+	}
+	fmt.Fprintf(d.w, "| Name | %s |\n|---|---|\n", label)
+	for _, n := range t.Enum.Values {
+		fmt.Fprintf(d.w, "| %s | %d |\n", n.Name, n.Value)
+	}
+	d.w.WriteString("\n")
+}
+
+// link links to the definition of name if the module has one
+func (d *markdownDoc) link(name string) string {
+	if !d.local[name] {
+		return name
+	}
+	return "[" + name + "](#" + name + ")"
+}
+
+func (d *markdownDoc) links(nodes []SmiNode) string {
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = d.link(n.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func statusText(status types.Status) string {
+	if status == types.StatusUnknown {
+		return ""
+	}
+	return status.String()
+}
+
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
+
+// rangesText renders ranges as in SMI, sizes for OCTET STRING
+func rangesText(baseType types.BaseType, ranges []models.Range) string {
+	if len(ranges) == 0 {
+		return ""
+	}
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = strconv.FormatInt(r.MinValue, 10)
+		if r.MaxValue != r.MinValue {
+			parts[i] += ".." + strconv.FormatInt(r.MaxValue, 10)
+		}
+	}
+	text := strings.Join(parts, " | ")
+	if baseType == types.BaseTypeOctetString {
+		return "SIZE (" + text + ")"
+	}
+	return "(" + text + ")"
+}
@@ -0,0 +1,104 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const markdownMib = `DOC-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE, Integer32
+        FROM SNMPv2-SMI
+    TEXTUAL-CONVENTION
+        FROM SNMPv2-TC;
+
+docMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "docs@example.com"
+    DESCRIPTION "Documents things."
+    ::= { iso 1 }
+
+DocName ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "255a"
+    STATUS current
+    DESCRIPTION "A name."
+    SYNTAX OCTET STRING (SIZE (0 | 4..8))
+
+DocState ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A state."
+    SYNTAX INTEGER { up(1), down(2) }
+
+docTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF DocEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Things."
+    ::= { docMib 1 }
+
+docEntry OBJECT-TYPE
+    SYNTAX DocEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A thing."
+    INDEX { docIndex }
+    ::= { docTable 1 }
+
+DocEntry ::= SEQUENCE { docIndex Integer32, docState DocState }
+
+docIndex OBJECT-TYPE
+    SYNTAX Integer32 (1..10)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The index."
+    ::= { docEntry 1 }
+
+docState OBJECT-TYPE
+    SYNTAX DocState
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION
+        "The state of
+         the thing."
+    ::= { docEntry 2 }
+
+docChanged NOTIFICATION-TYPE
+    OBJECTS { docState }
+    STATUS current
+    DESCRIPTION "The state changed."
+    ::= { docMib 2 }
+END
+`
+
+func TestWriteMarkdown(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"DOC-MIB": markdownMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("DOC-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("DOC-MIB")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, gosmi.WriteMarkdown(&buf, module))
+	doc := buf.String()
+	for _, want := range []string{
+		"# DOC-MIB\n\n| Language | SMIv2 |\n| Organization | Example |\n",
+		"- [Types](#types)\n  - [DocName](#DocName)\n  - [DocState](#DocState)\n- [Objects](#objects)\n  - [docMib](#docMib)\n",
+		"<a id=\"docState\"></a>\n\n### docState\n\n| | |\n|---|---|\n| OID | `1.1.1.1.2` |\n| Kind | Column |\n" +
+			"| Syntax | [DocState](#DocState) |\n| Access | ReadOnly |\n| Status | Current |\n" +
+			"| Referenced by | [docChanged](#docChanged) |\n\n| Name | Value |\n|---|---|\n| up | 1 |\n| down | 2 |\n\n" +
+			"The state of\nthe thing.\n",
+		"| Display hint | `255a` |\n| Ranges | SIZE (0 \\| 4..8) |\n",
+		"| Index | [docIndex](#docIndex) |\n",
+		"| Objects | [docState](#docState) |\n",
+	} {
+		assert.Contains(t, doc, want)
+	}
+}
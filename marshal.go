@@ -0,0 +1,196 @@
+package gosmi
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+type marshalConfig struct {
+	types bool
+	index bool
+}
+
+// MarshalOption configures NewNodeJSON and MarshalNodeJSON.
+type MarshalOption func(*marshalConfig)
+
+// WithTypeExpansion inlines the resolved type of nodes, with its effective
+// ranges and enumeration and the chain of types it derives from, instead of
+// its name alone.
+func WithTypeExpansion() MarshalOption {
+	return func(c *marshalConfig) { c.types = true }
+}
+
+// WithIndexExpansion inlines the index of rows and tables, with the OID and
+// type of each index column and whether the last is IMPLIED, instead of the
+// names of the columns alone.
+func WithIndexExpansion() MarshalOption {
+	return func(c *marshalConfig) { c.index = true }
+}
+
+// NodeJSON is the JSON form of a node. Syntax names the type of the node,
+// or its base type if the type is unnamed. Index and Augments name the
+// index columns and the row augmented of rows and tables. Type and
+// IndexColumns are only set when expanded; see MarshalOption.
+type NodeJSON struct {
+	Module       string            `json:"module"`
+	Name         string            `json:"name"`
+	Oid          string            `json:"oid"`
+	Kind         types.NodeKind    `json:"kind"`
+	Decl         types.Decl        `json:"decl"`
+	Syntax       string            `json:"syntax,omitempty"`
+	Access       types.Access      `json:"access,omitempty"`
+	Status       types.Status      `json:"status,omitempty"`
+	Format       string            `json:"format,omitempty"`
+	Units        string            `json:"units,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Reference    string            `json:"reference,omitempty"`
+	Index        []string          `json:"index,omitempty"`
+	Augments     string            `json:"augments,omitempty"`
+	Type         *TypeJSON         `json:"type,omitempty"`
+	IndexColumns []IndexColumnJSON `json:"indexColumns,omitempty"`
+	Implied      bool              `json:"implied,omitempty"`
+}
+
+// TypeJSON is the JSON form of a resolved type. The ranges, enumeration,
+// format and units are those in effect, inherited from the types it derives
+// from where it declares none. Chain lists those types, the primitive type
+// last, and is empty for the types in Chain themselves.
+type TypeJSON struct {
+	Name     string           `json:"name,omitempty"`
+	Module   string           `json:"module,omitempty"`
+	Decl     types.Decl       `json:"decl"`
+	BaseType types.BaseType   `json:"baseType"`
+	Format   string           `json:"format,omitempty"`
+	Units    string           `json:"units,omitempty"`
+	Ranges   []RangeJSON      `json:"ranges,omitempty"`
+	Enum     []NamedValueJSON `json:"enum,omitempty"`
+	Chain    []TypeJSON       `json:"chain,omitempty"`
+}
+
+// RangeJSON is a range or size constraint of a TypeJSON.
+type RangeJSON struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// NamedValueJSON is an enumeration label or named bit of a TypeJSON.
+type NamedValueJSON struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// IndexColumnJSON is an index column of a NodeJSON. Type is set if types are
+// expanded as well.
+type IndexColumnJSON struct {
+	Module string    `json:"module"`
+	Name   string    `json:"name"`
+	Oid    string    `json:"oid"`
+	Syntax string    `json:"syntax,omitempty"`
+	Type   *TypeJSON `json:"type,omitempty"`
+}
+
+// NewNodeJSON returns the JSON form of the node, self-contained with the
+// expansions the options ask for, to serve or embed in API responses.
+func NewNodeJSON(node SmiNode, opts ...MarshalOption) NodeJSON {
+	var cfg marshalConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	out := NodeJSON{
+		Module:      node.GetModule().Name,
+		Name:        node.Name,
+		Oid:         node.RenderNumeric(),
+		Kind:        node.Kind,
+		Decl:        node.Decl,
+		Syntax:      syntaxName(node),
+		Access:      node.Access,
+		Status:      node.Status,
+		Format:      node.Format,
+		Units:       node.Units,
+		Description: node.Description,
+		Reference:   node.Reference,
+	}
+	if cfg.types && node.SmiType != nil {
+		out.Type = newTypeJSON(*node.SmiType)
+	}
+	if node.Kind != types.NodeRow && node.Kind != types.NodeTable {
+		return out
+	}
+	if augmented := node.GetAugment(); augmented.smiNode != nil {
+		out.Augments = augmented.Name
+	}
+	for _, column := range node.GetIndex() {
+		out.Index = append(out.Index, column.Name)
+		if !cfg.index {
+			continue
+		}
+		ic := IndexColumnJSON{
+			Module: column.GetModule().Name,
+			Name:   column.Name,
+			Oid:    column.RenderNumeric(),
+			Syntax: syntaxName(column),
+		}
+		if cfg.types && column.SmiType != nil {
+			ic.Type = newTypeJSON(*column.SmiType)
+		}
+		out.IndexColumns = append(out.IndexColumns, ic)
+	}
+	if cfg.index {
+		out.Implied = node.GetImplied()
+	}
+	return out
+}
+
+// MarshalNodeJSON returns the JSON encoding of NewNodeJSON(node, opts...).
+func MarshalNodeJSON(node SmiNode, opts ...MarshalOption) ([]byte, error) {
+	return json.Marshal(NewNodeJSON(node, opts...))
+}
+
+// syntaxName returns the name of the type of the node, or of its base type
+// if the type is unnamed.
+func syntaxName(node SmiNode) string {
+	if node.Type == nil {
+		return ""
+	}
+	if node.Type.Name != "" {
+		return node.Type.Name
+	}
+	return node.Type.BaseType.String()
+}
+
+func newTypeJSON(t SmiType) *TypeJSON {
+	chain := t.BaseTypeChain()
+	if len(chain) == 0 {
+		return typeLevelJSON(t)
+	}
+	out := typeLevelJSON(chain[0])
+	for _, parent := range chain[1:] {
+		out.Chain = append(out.Chain, *typeLevelJSON(parent))
+	}
+	return out
+}
+
+// typeLevelJSON returns the JSON form of the type without its chain.
+func typeLevelJSON(t SmiType) *TypeJSON {
+	out := &TypeJSON{
+		Name:     t.Name,
+		Decl:     t.Decl,
+		BaseType: t.BaseType,
+		Format:   t.Format,
+		Units:    t.Units,
+	}
+	if module := t.GetModule().Name; !strings.HasPrefix(module, "<") {
+		out.Module = module
+	}
+	for _, r := range t.Ranges {
+		out.Ranges = append(out.Ranges, RangeJSON{Min: r.MinValue, Max: r.MaxValue})
+	}
+	if t.Enum != nil {
+		for _, v := range t.Enum.Values {
+			out.Enum = append(out.Enum, NamedValueJSON{Name: v.Name, Value: v.Value})
+		}
+	}
+	return out
+}
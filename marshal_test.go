@@ -0,0 +1,121 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const marshalMib = `MARSHAL-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Integer32 FROM SNMPv2-SMI
+    TEXTUAL-CONVENTION, DisplayString FROM SNMPv2-TC;
+
+marshalMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "gosmi"
+    CONTACT-INFO "gosmi"
+    DESCRIPTION "Marshaling."
+    ::= { iso 15 }
+
+MarshalLevel ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A level."
+    SYNTAX Integer32 (0..10)
+
+marshalTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF MarshalEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { marshalMib 1 }
+
+marshalEntry OBJECT-TYPE
+    SYNTAX MarshalEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { IMPLIED marshalName }
+    ::= { marshalTable 1 }
+
+MarshalEntry ::= SEQUENCE { marshalName DisplayString, marshalLevel MarshalLevel }
+
+marshalName OBJECT-TYPE
+    SYNTAX DisplayString (SIZE (1..32))
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The name."
+    ::= { marshalEntry 1 }
+
+marshalLevel OBJECT-TYPE
+    SYNTAX MarshalLevel (1..5)
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The level."
+    ::= { marshalEntry 2 }
+
+marshalExtTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF MarshalExtEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmenting table."
+    ::= { marshalMib 2 }
+
+marshalExtEntry OBJECT-TYPE
+    SYNTAX MarshalExtEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmenting row."
+    AUGMENTS { marshalEntry }
+    ::= { marshalExtTable 1 }
+
+MarshalExtEntry ::= SEQUENCE { marshalExtValue Integer32 }
+
+marshalExtValue OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A value."
+    ::= { marshalExtEntry 1 }
+END
+`
+
+func TestMarshalNodeJSON(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"MARSHAL-MIB": marshalMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	gosmi.LoadBaseModules()
+	_, err := gosmi.LoadModule("MARSHAL-MIB")
+	require.NoError(t, err)
+
+	marshal := func(name string, opts ...gosmi.MarshalOption) string {
+		t.Helper()
+		node, err := gosmi.GetNode(name)
+		require.NoError(t, err)
+		data, err := gosmi.MarshalNodeJSON(node, opts...)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.JSONEq(t, `{"module":"MARSHAL-MIB","name":"marshalLevel","oid":"1.15.1.1.2","kind":"Column","decl":"ObjectType",
+		"syntax":"MarshalLevel","access":"ReadOnly","status":"Current","description":"The level."}`, marshal("marshalLevel"))
+	assert.JSONEq(t, `{"module":"MARSHAL-MIB","name":"marshalLevel","oid":"1.15.1.1.2","kind":"Column","decl":"ObjectType",
+		"syntax":"MarshalLevel","access":"ReadOnly","status":"Current","description":"The level.",
+		"type":{"name":"MarshalLevel","module":"MARSHAL-MIB","decl":"TextualConvention","baseType":"Integer32","ranges":[{"min":1,"max":5}],"chain":[
+			{"name":"MarshalLevel","module":"MARSHAL-MIB","decl":"TextualConvention","baseType":"Integer32","ranges":[{"min":0,"max":10}]},
+			{"name":"Integer32","module":"SNMPv2-SMI","decl":"TypeAssignment","baseType":"Integer32"},
+			{"name":"Integer32","decl":"ImplicitType","baseType":"Integer32"}]}}`,
+		marshal("marshalLevel", gosmi.WithTypeExpansion()))
+
+	// Index names alone unless expanded
+	assert.JSONEq(t, `{"module":"MARSHAL-MIB","name":"marshalExtEntry","oid":"1.15.2.1","kind":"Row","decl":"ObjectType",
+		"access":"NotAccessible","status":"Current","description":"An augmenting row.",
+		"index":["marshalName"],"augments":"marshalEntry"}`, marshal("marshalExtEntry"))
+	assert.JSONEq(t, `{"module":"MARSHAL-MIB","name":"marshalTable","oid":"1.15.1","kind":"Table","decl":"ObjectType",
+		"access":"NotAccessible","status":"Current","description":"A table.","index":["marshalName"],
+		"indexColumns":[{"module":"MARSHAL-MIB","name":"marshalName","oid":"1.15.1.1.1","syntax":"DisplayString"}],"implied":true}`,
+		marshal("marshalTable", gosmi.WithIndexExpansion()))
+}
@@ -0,0 +1,38 @@
+package gosmi
+
+import (
+	"github.com/lukeod/gosmi/smi"
+)
+
+// Metrics receives counters and histograms of loading modules, for services
+// embedding gosmi to monitor MIB compilation. An adapter to the Prometheus
+// client could read:
+//
+//	type promMetrics struct {
+//		counters   *prometheus.CounterVec   // labeled by "name"
+//		histograms *prometheus.HistogramVec // labeled by "name"
+//	}
+//
+//	func (m promMetrics) Count(name string, delta int) {
+//		m.counters.WithLabelValues(name).Add(float64(delta))
+//	}
+//
+//	func (m promMetrics) Observe(name string, value float64) {
+//		m.histograms.WithLabelValues(name).Observe(value)
+//	}
+type Metrics = smi.Metrics
+
+// Names of the metrics reported to Metrics. Histograms of durations are in
+// seconds.
+const (
+	MetricParseSeconds  = smi.MetricParseSeconds
+	MetricBuildSeconds  = smi.MetricBuildSeconds
+	MetricModulesLoaded = smi.MetricModulesLoaded
+	MetricNodesResolved = smi.MetricNodesResolved
+	MetricCacheHits     = smi.MetricCacheHits
+	MetricCacheMisses   = smi.MetricCacheMisses
+)
+
+// SetMetrics sets where LoadModule and CompileDir report their measurements,
+// or stops reporting them if metrics is nil. Metrics are dropped by Exit.
+func SetMetrics(metrics Metrics) { smi.SetMetrics(metrics) }
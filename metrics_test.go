@@ -0,0 +1,60 @@
+package gosmi_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	mu           sync.Mutex
+	counters     map[string]int
+	observations map[string]int
+}
+
+func (m *testMetrics) Count(name string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name] += delta
+}
+
+func (m *testMetrics) Observe(name string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observations[name]++
+}
+
+func TestMetrics(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"BASE-MIB": `BASE-MIB DEFINITIONS ::= BEGIN
+base OBJECT IDENTIFIER ::= { iso 1 }
+END
+`,
+		"USER-MIB": `USER-MIB DEFINITIONS ::= BEGIN
+IMPORTS base FROM BASE-MIB;
+user OBJECT IDENTIFIER ::= { base 1 }
+userChild OBJECT IDENTIFIER ::= { user 1 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	metrics := &testMetrics{counters: make(map[string]int), observations: make(map[string]int)}
+	gosmi.SetMetrics(metrics)
+
+	_, err := gosmi.LoadModule("USER-MIB")
+	require.NoError(t, err)
+	_, err = gosmi.LoadModule("USER-MIB")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, metrics.counters[gosmi.MetricModulesLoaded])
+	assert.Equal(t, 3, metrics.counters[gosmi.MetricNodesResolved])
+	assert.Equal(t, 2, metrics.counters[gosmi.MetricCacheMisses])
+	assert.Positive(t, metrics.counters[gosmi.MetricCacheHits])
+	assert.Equal(t, 2, metrics.observations[gosmi.MetricParseSeconds])
+	assert.Equal(t, 2, metrics.observations[gosmi.MetricBuildSeconds])
+}
@@ -0,0 +1,18 @@
+// Package mibs embeds the base modules of the SMI, which nearly every module
+// imports from: SNMPv2-SMI, SNMPv2-TC and SNMPv2-CONF (RFC 2578 to 2580) and
+// RFC1155-SMI, RFC-1212 and RFC-1215 of SMIv1. With gosmi.LoadBaseModules
+// they need not be distributed along with the modules importing them.
+//
+// RFC-1212 imports the types its IndexSyntax refers to from RFC1155-SMI,
+// rather than DisplayString from RFC1158-MIB, which only its macro uses.
+package mibs
+
+import "embed"
+
+// FS holds the base modules, each in a file named after the module.
+//
+//go:embed SNMPv2-SMI SNMPv2-TC SNMPv2-CONF RFC1155-SMI RFC-1212 RFC-1215
+var FS embed.FS
+
+// Names are the names of the base modules in FS, SMIv2 first.
+var Names = []string{"SNMPv2-SMI", "SNMPv2-TC", "SNMPv2-CONF", "RFC1155-SMI", "RFC-1212", "RFC-1215"}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// CheckValue reports whether value, as an SNMP library decodes it, is a valid
+// value of the type: of a Go type fitting its base type, one of its
+// enumerated values and within its ranges, or sizes for strings.
+func (t Type) CheckValue(value interface{}) error {
+	switch t.BaseType {
+	case types.BaseTypeInteger32, types.BaseTypeUnsigned32, types.BaseTypeInteger64, types.BaseTypeUnsigned64, types.BaseTypeEnum:
+		if _, ok := value.(string); ok {
+			return errors.New("String value, not an integer")
+		}
+		if u, ok := value.(uint64); ok && u > math.MaxInt64 {
+			// Beyond the ranges, which are int64
+			if t.BaseType != types.BaseTypeUnsigned64 {
+				return fmt.Errorf("Value %d out of range", u)
+			}
+			return nil
+		}
+		n, err := ToInt64(value)
+		if err != nil {
+			return err
+		}
+		if t.BaseType == types.BaseTypeEnum {
+			if t.Enum != nil && !t.Enum.has(n) {
+				return fmt.Errorf("Value %d is not an enumerated value", n)
+			}
+			return nil
+		}
+		if len(t.Ranges) > 0 && !inRanges(t.Ranges, n) {
+			return fmt.Errorf("Value %d out of range", n)
+		}
+		return nil
+	case types.BaseTypeOctetString, types.BaseTypeBits:
+		var size int
+		switch v := value.(type) {
+		case []byte:
+			size = len(v)
+		case string:
+			size = len(v)
+		case net.IP:
+			size = len(v)
+			if v4 := v.To4(); v4 != nil && t.Name == "IpAddress" {
+				size = len(v4)
+			}
+		default:
+			return fmt.Errorf("Value of type %T, not an octet string", value)
+		}
+		// The ranges of BITS are those of their enumeration
+		if t.BaseType == types.BaseTypeOctetString && len(t.Ranges) > 0 && !inRanges(t.Ranges, int64(size)) {
+			return fmt.Errorf("Length %d outside of size", size)
+		}
+		return nil
+	case types.BaseTypeObjectIdentifier:
+		switch v := value.(type) {
+		case types.Oid, []uint32:
+		case string:
+			if _, err := types.OidFromString(v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("Value of type %T, not an object identifier", value)
+		}
+		return nil
+	}
+	return nil
+}
+
+func (e *Enum) has(value int64) bool {
+	e.initValueMap()
+	e.rw.RLock()
+	_, ok := e.valueMap[value]
+	e.rw.RUnlock()
+	return ok
+}
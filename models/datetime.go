@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// DateAndTimeHint is the DISPLAY-HINT of the DateAndTime textual convention
+// of SNMPv2-TC (RFC 2579), which identifies conventions using its layout.
+const DateAndTimeHint = "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"
+
+// DecodeDateAndTime converts a DateAndTime value to a time.Time. The value is
+// 8 bytes, the year in network byte order, month, day, hour, minutes,
+// seconds and deci-seconds, optionally followed by 3 for the direction, '+'
+// or '-', hours and minutes from UTC. Without them the time is local.
+func DecodeDateAndTime(b []byte) (time.Time, error) {
+	if len(b) != 8 && len(b) != 11 {
+		return time.Time{}, fmt.Errorf("Invalid DateAndTime length %d", len(b))
+	}
+	year := int(binary.BigEndian.Uint16(b))
+	month, day, hour, min, sec, deci := b[2], b[3], b[4], b[5], b[6], b[7]
+	// Seconds go up to 60 for leap seconds
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || min > 59 || sec > 60 || deci > 9 {
+		return time.Time{}, fmt.Errorf("Invalid DateAndTime %v", b)
+	}
+	loc := time.Local
+	if len(b) == 11 {
+		direction, hours, minutes := b[8], b[9], b[10]
+		if direction != '+' && direction != '-' || hours > 13 || minutes > 59 {
+			return time.Time{}, fmt.Errorf("Invalid DateAndTime offset %v", b[8:])
+		}
+		offset := int(hours)*3600 + int(minutes)*60
+		if direction == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+	return time.Date(year, time.Month(month), int(day), int(hour), int(min), int(sec), int(deci)*int(100*time.Millisecond), loc), nil
+}
+
+// EncodeDateAndTime converts t to an 11 byte DateAndTime value, with the
+// offset from UTC of its location, truncated to deci-seconds.
+func EncodeDateAndTime(t time.Time) []byte {
+	b := make([]byte, 11)
+	binary.BigEndian.PutUint16(b, uint16(t.Year()))
+	b[2], b[3] = byte(t.Month()), byte(t.Day())
+	b[4], b[5], b[6] = byte(t.Hour()), byte(t.Minute()), byte(t.Second())
+	b[7] = byte(t.Nanosecond() / int(100*time.Millisecond))
+	_, offset := t.Zone()
+	b[8] = '+'
+	if offset < 0 {
+		b[8] = '-'
+		offset = -offset
+	}
+	b[9], b[10] = byte(offset/3600), byte(offset%3600/60)
+	return b
+}
+
+// TicksToDuration converts hundredths of seconds, the unit of TimeTicks and
+// TimeInterval, to a time.Duration.
+func TicksToDuration(ticks int64) time.Duration {
+	return time.Duration(ticks) * 10 * time.Millisecond
+}
+
+// DurationToTicks converts d to hundredths of seconds, truncating it.
+func DurationToTicks(d time.Duration) int64 {
+	return int64(d / (10 * time.Millisecond))
+}
+
+// TimeStampTime returns when the event recorded by a TimeStamp happened: the
+// TimeStamp is the value sysUpTime had then, and sysUpTime had the given value
+// at now. Counting modulo 2^32, this holds across the wrap of sysUpTime. ok is
+// false for a zero TimeStamp, which stands for an event before the agent last
+// restarted.
+func TimeStampTime(stamp, sysUpTime uint32, now time.Time) (t time.Time, ok bool) {
+	if stamp == 0 {
+		return time.Time{}, false
+	}
+	return now.Add(-TicksToDuration(int64(sysUpTime - stamp))), true
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// InetAddressType is a value of the InetAddressType textual convention of
+// INET-ADDRESS-MIB (RFC 4001), which tells how the InetAddress it goes with
+// is encoded.
+type InetAddressType int64
+
+const (
+	InetAddressUnknown InetAddressType = 0
+	InetAddressIPv4    InetAddressType = 1
+	InetAddressIPv6    InetAddressType = 2
+	InetAddressIPv4z   InetAddressType = 3
+	InetAddressIPv6z   InetAddressType = 4
+	InetAddressDNS     InetAddressType = 16
+)
+
+func (t InetAddressType) String() string {
+	switch t {
+	case InetAddressUnknown:
+		return "unknown"
+	case InetAddressIPv4:
+		return "ipv4"
+	case InetAddressIPv6:
+		return "ipv6"
+	case InetAddressIPv4z:
+		return "ipv4z"
+	case InetAddressIPv6z:
+		return "ipv6z"
+	case InetAddressDNS:
+		return "dns"
+	}
+	return strconv.FormatInt(int64(t), 10)
+}
+
+// InetAddress is a decoded InetAddress value.
+type InetAddress struct {
+	Type InetAddressType
+	// IP is set for the IPv4 and IPv6 types, zoned or not.
+	IP net.IP
+	// Zone is the zone index of the zoned types.
+	Zone uint32
+	// Host is set for the dns type.
+	Host string
+}
+
+// String renders the address as in RFC 4001: 192.0.2.1, 2001:db8::1,
+// fe80::1%2 for zoned addresses and the name for DNS. The unknown type is
+// rendered empty.
+func (a InetAddress) String() string {
+	switch a.Type {
+	case InetAddressIPv4, InetAddressIPv6:
+		return a.IP.String()
+	case InetAddressIPv4z, InetAddressIPv6z:
+		return a.IP.String() + "%" + strconv.FormatUint(uint64(a.Zone), 10)
+	case InetAddressDNS:
+		return a.Host
+	}
+	return ""
+}
+
+// inetAddressSizes are the sizes of the encodings of each address type
+var inetAddressSizes = map[InetAddressType]int{
+	InetAddressUnknown: 0,
+	InetAddressIPv4:    4,
+	InetAddressIPv6:    16,
+	InetAddressIPv4z:   8,
+	InetAddressIPv6z:   20,
+}
+
+// DecodeInetAddress decodes the bytes of an InetAddress according to the
+// value of the InetAddressType going with it. Zoned addresses end with their
+// zone index in network byte order, DNS names are 1 to 255 bytes.
+func DecodeInetAddress(addressType InetAddressType, b []byte) (InetAddress, error) {
+	address := InetAddress{Type: addressType}
+	if addressType == InetAddressDNS {
+		if len(b) == 0 || len(b) > 255 {
+			return address, fmt.Errorf("Invalid DNS name length %d", len(b))
+		}
+		address.Host = string(b)
+		return address, nil
+	}
+	size, ok := inetAddressSizes[addressType]
+	if !ok {
+		return address, fmt.Errorf("Unknown InetAddressType %d", addressType)
+	}
+	if len(b) != size {
+		return address, fmt.Errorf("Invalid %s address length %d, expected %d", addressType, len(b), size)
+	}
+	switch addressType {
+	case InetAddressIPv4, InetAddressIPv4z:
+		address.IP = net.IP(append([]byte(nil), b[:4]...))
+	case InetAddressIPv6, InetAddressIPv6z:
+		address.IP = net.IP(append([]byte(nil), b[:16]...))
+	}
+	if addressType == InetAddressIPv4z || addressType == InetAddressIPv6z {
+		address.Zone = binary.BigEndian.Uint32(b[len(b)-4:])
+	}
+	return address, nil
+}
+
+// DecodeInetAddressIndex decodes an InetAddressType index followed by an
+// InetAddress index from the start of an instance OID suffix, such as that of
+// ipAddressTable, returning the rest of the suffix. The address is preceded
+// by its length unless implied.
+func DecodeInetAddressIndex(oid types.Oid, implied bool) (address InetAddress, rest types.Oid, err error) {
+	if len(oid) == 0 {
+		return address, nil, errors.New("Missing InetAddressType")
+	}
+	addressType := InetAddressType(oid[0])
+	oid = oid[1:]
+	n := len(oid)
+	if !implied {
+		if len(oid) == 0 {
+			return address, nil, errors.New("Missing InetAddress length")
+		}
+		n = int(oid[0])
+		oid = oid[1:]
+		if n > len(oid) {
+			return address, nil, fmt.Errorf("InetAddress length %d exceeds the %d remaining sub-identifiers", n, len(oid))
+		}
+	}
+	b := make([]byte, n)
+	for i, subId := range oid[:n] {
+		if subId > 255 {
+			return address, nil, fmt.Errorf("Invalid InetAddress byte %d", subId)
+		}
+		b[i] = byte(subId)
+	}
+	address, err = DecodeInetAddress(addressType, b)
+	return address, oid[n:], err
+}
@@ -24,4 +24,6 @@ type Module struct {
 type Revision struct {
 	Date        time.Time
 	Description string
+	// Line is that of the REVISION clause, 0 if unknown.
+	Line int
 }
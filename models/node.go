@@ -8,10 +8,13 @@ type Node struct {
 	Access      types.Access
 	Decl        types.Decl
 	Description string
+	Format      string
 	Kind        types.NodeKind
 	Name        string
 	Oid         types.Oid
 	OidLen      int
+	Reference   string
 	Status      types.Status
 	Type        *Type
+	Units       string
 }
@@ -0,0 +1,29 @@
+package models
+
+import "github.com/lukeod/gosmi/types"
+
+// Table is a view of a conceptual table that spares consumers from rebuilding
+// its semantics from the raw nodes. Row holds the entry with all of its
+// columns in definition order, the columns making up its index and whether
+// the last of them is IMPLIED.
+type Table struct {
+	TableNode
+	// DataColumns are the columns of the entry that are not part of its
+	// index, in definition order.
+	DataColumns []ColumnNode
+	// Augments is the chain of rows the entry augments, nearest first. It is
+	// empty unless the entry uses AUGMENTS.
+	Augments []RowNode
+	// ExternalIndex is set if the entry is indexed by columns of another
+	// table, as is the case for sparse augmentations and dependent tables.
+	ExternalIndex bool
+	// IndexKind tells how the entry relates to the row it shares its index
+	// with: IndexAugment for AUGMENTS, IndexSparse for an entry indexed by
+	// exactly the index of another row without augmenting it, so that it has
+	// rows for some of the rows of the other table only, and IndexExpand
+	// for an index starting with that of another row. It is IndexIndex for
+	// other entries.
+	IndexKind types.IndexKind
+	// Related is the row of IndexKind, nil for IndexIndex.
+	Related *RowNode
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 
 	"github.com/lukeod/gosmi/types"
@@ -64,6 +65,26 @@ type Range struct {
 	MaxValue int64
 }
 
+// IntersectRanges returns the parts of the ranges in a that are also covered
+// by a range in b, in the order of a.
+func IntersectRanges(a, b []Range) (ranges []Range) {
+	for _, x := range a {
+		for _, y := range b {
+			r := x
+			if y.MinValue > r.MinValue {
+				r.MinValue = y.MinValue
+			}
+			if y.MaxValue < r.MaxValue {
+				r.MaxValue = y.MaxValue
+			}
+			if r.MinValue <= r.MaxValue {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	return
+}
+
 type Type struct {
 	BaseType    types.BaseType
 	Decl        types.Decl
@@ -136,6 +157,15 @@ func (t Type) indexValueOctetString(value interface{}, implied bool) (types.Oid,
 	default:
 		return nil, errors.New("Invalid octet string value")
 	}
+	// Fixed size strings are encoded without their length, like IMPLIED ones
+	if size, ok := t.FixedSize(); ok {
+		if int64(len(bytes)) != size {
+			return nil, fmt.Errorf("Length %d is not the fixed size %d", len(bytes), size)
+		}
+		implied = true
+	} else if t.BaseType == types.BaseTypeOctetString && len(t.Ranges) > 0 && !inRanges(t.Ranges, int64(len(bytes))) {
+		return nil, fmt.Errorf("Length %d outside of size", len(bytes))
+	}
 	var ret types.Oid
 	var offset int
 	if implied {
@@ -151,7 +181,64 @@ func (t Type) indexValueOctetString(value interface{}, implied bool) (types.Oid,
 	return ret, nil
 }
 
+// indexValueIpAddress encodes an IPv4 address given as a net.IP, 4 bytes or
+// in dotted notation as its 4 bytes.
+func (t Type) indexValueIpAddress(value interface{}) (types.Oid, error) {
+	var ip net.IP
+	switch v := value.(type) {
+	case net.IP:
+		ip = v.To4()
+	case [4]byte:
+		ip = v[:]
+	case []byte:
+		if len(v) == net.IPv4len {
+			ip = v
+		}
+	case string:
+		ip = net.ParseIP(v).To4()
+	}
+	if ip == nil {
+		return nil, errors.New("Invalid IPv4 address value")
+	}
+	return types.Oid{types.SmiSubId(ip[0]), types.SmiSubId(ip[1]), types.SmiSubId(ip[2]), types.SmiSubId(ip[3])}, nil
+}
+
+func inRanges(ranges []Range, n int64) bool {
+	for _, r := range ranges {
+		if n >= r.MinValue && n <= r.MaxValue {
+			return true
+		}
+	}
+	return false
+}
+
+// FixedSize returns the size of OCTET STRING types restricted to a single
+// size, such as IpAddress or MacAddress.
+func (t Type) FixedSize() (int64, bool) {
+	if t.BaseType != types.BaseTypeOctetString || len(t.Ranges) != 1 || t.Ranges[0].MinValue != t.Ranges[0].MaxValue {
+		return 0, false
+	}
+	return t.Ranges[0].MinValue, true
+}
+
+// IndexValue encodes value as the sub-identifiers of an index column of the
+// type, following RFC 2578, section 7.7: integers as one sub-identifier,
+// strings and OIDs preceded by their length unless implied, the last index of
+// an IMPLIED row, or, for strings, of a fixed size. IpAddress is encoded as 4
+// sub-identifiers and the SMIv1 NetworkAddress as 1, for internet, followed by
+// the 4 of its IpAddress (RFC 1212, section 4.1.6). BITS are encoded as the
+// OCTET STRING of their bytes.
 func (t Type) IndexValue(value interface{}, implied bool) (types.Oid, error) {
+	switch t.Name {
+	case "IpAddress":
+		return t.indexValueIpAddress(value)
+	case "NetworkAddress":
+		address, err := t.indexValueIpAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		return append(types.Oid{1}, address...), nil
+	}
 	switch t.BaseType {
 	case types.BaseTypeEnum:
 		return t.indexValueEnum(value)
@@ -177,7 +264,7 @@ func (t Type) IndexValue(value interface{}, implied bool) (types.Oid, error) {
 			return t.indexValueObjectIdentifier(oid, implied)
 		}
 		return nil, errors.New("Invalid object identifier value")
-	case types.BaseTypeOctetString:
+	case types.BaseTypeOctetString, types.BaseTypeBits:
 		return t.indexValueOctetString(value, implied)
 	}
 	return nil, fmt.Errorf("Invalid base type: %v", t.BaseType)
@@ -1,9 +1,13 @@
 package gosmi
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/smi"
 	"github.com/lukeod/gosmi/types"
 )
@@ -21,6 +25,31 @@ func (m SmiModule) GetIdentityNode() (node SmiNode, ok bool) {
 	return CreateNode(smiIdentityNode), true
 }
 
+// ModuleIdentity is the MODULE-IDENTITY of a module: its node, with the OID
+// and description, and the clauses kept on the module.
+type ModuleIdentity struct {
+	SmiNode
+	Organization string
+	ContactInfo  string
+	LastUpdated  time.Time
+}
+
+// GetIdentity returns the MODULE-IDENTITY of the module, ok is false for
+// modules without one. ContactInfo is empty until the text of the module is
+// loaded if text is loaded lazily.
+func (m SmiModule) GetIdentity() (identity ModuleIdentity, ok bool) {
+	node, ok := m.GetIdentityNode()
+	if !ok {
+		return
+	}
+	return ModuleIdentity{
+		SmiNode:      node,
+		Organization: m.Organization,
+		ContactInfo:  m.ContactInfo,
+		LastUpdated:  m.LastUpdated(),
+	}, true
+}
+
 func (m SmiModule) GetImports() (imports []models.Import) {
 	for smiImport := smi.GetFirstImport(m.smiModule); smiImport != nil; smiImport = smi.GetNextImport(smiImport) {
 		_import := models.Import{
@@ -47,17 +76,47 @@ func (m SmiModule) GetNodes(kind ...types.NodeKind) (nodes []SmiNode) {
 	return
 }
 
+// GetScalars returns the scalar objects of the module.
+func (m SmiModule) GetScalars() []SmiNode { return m.GetNodes(types.NodeScalar) }
+
+// GetTables returns the tables of the module.
+func (m SmiModule) GetTables() []SmiNode { return m.GetNodes(types.NodeTable) }
+
+// GetRows returns the conceptual rows of the tables of the module.
+func (m SmiModule) GetRows() []SmiNode { return m.GetNodes(types.NodeRow) }
+
+// GetColumns returns the columns of the tables of the module.
+func (m SmiModule) GetColumns() []SmiNode { return m.GetNodes(types.NodeColumn) }
+
+// GetNotifications returns the notifications and traps of the module.
+func (m SmiModule) GetNotifications() []SmiNode { return m.GetNodes(types.NodeNotification) }
+
+// GetGroups returns the object and notification groups of the module.
+func (m SmiModule) GetGroups() []SmiNode { return m.GetNodes(types.NodeGroup) }
+
+// GetCompliances returns the compliance statements of the module.
+func (m SmiModule) GetCompliances() []SmiNode { return m.GetNodes(types.NodeCompliance) }
+
+// GetRevisions returns the revisions of the module newest first.
 func (m SmiModule) GetRevisions() (revisions []models.Revision) {
 	for smiRevision := smi.GetFirstRevision(m.smiModule); smiRevision != nil; smiRevision = smi.GetNextRevision(smiRevision) {
 		revision := models.Revision{
 			Date:        smiRevision.Date,
 			Description: smiRevision.Description,
+			Line:        smi.GetRevisionLine(smiRevision),
 		}
 		revisions = append(revisions, revision)
 	}
+	sort.SliceStable(revisions, func(i, j int) bool { return revisions[i].Date.After(revisions[j].Date) })
 	return
 }
 
+// LastUpdated returns the LAST-UPDATED date of the module, zero for modules
+// without MODULE-IDENTITY.
+func (m SmiModule) LastUpdated() time.Time {
+	return smi.GetModuleLastUpdated(m.smiModule)
+}
+
 func (m SmiModule) GetType(name string) (outType SmiType, err error) {
 	return GetType(name, m)
 }
@@ -69,6 +128,83 @@ func (m SmiModule) GetTypes() (types []SmiType) {
 	return
 }
 
+// ValueAssignment is a value the module assigns other than an OID, such as
+// someInteger INTEGER ::= 5.
+type ValueAssignment struct {
+	Name string
+	// Type is zero if the type of the value could not be resolved.
+	Type SmiType
+	// Value is converted for the base type: integers of its width,
+	// enumeration labels to their numbers and octet strings to []byte.
+	// Other values are kept as the string written.
+	Value types.SmiValue
+	Line  int
+}
+
+func createValueAssignment(smiValue *types.SmiValueAssignment) ValueAssignment {
+	return ValueAssignment{
+		Name:  smiValue.Name.String(),
+		Type:  CreateType(smi.GetValueAssignmentType(smiValue)),
+		Value: smiValue.Value,
+		Line:  smi.GetValueAssignmentLine(smiValue),
+	}
+}
+
+func (m SmiModule) GetValue(name string) (value ValueAssignment, err error) {
+	smiValue := smi.GetValueAssignment(m.smiModule, name)
+	if smiValue == nil {
+		err = fmt.Errorf("Could not find value named %s in module %s", name, m.Name)
+		return
+	}
+	return createValueAssignment(smiValue), nil
+}
+
+func (m SmiModule) GetValues() (values []ValueAssignment) {
+	for smiValue := smi.GetFirstValueAssignment(m.smiModule); smiValue != nil; smiValue = smi.GetNextValueAssignment(smiValue) {
+		values = append(values, createValueAssignment(smiValue))
+	}
+	return
+}
+
+// AST returns the parsed module the module was built from, for details the
+// resolved module does not keep, such as the exact DEFVAL text or macro
+// bodies. It is nil for modules that were not built from a file. The AST is
+// shared and must not be modified.
+func (m SmiModule) AST() *parser.Module {
+	return smi.GetModuleAST(m.smiModule)
+}
+
+// LoadTiming is the time the lex, parse and resolve phases of loading a
+// module took.
+type LoadTiming = smi.LoadTiming
+
+// Timing returns the time the phases of loading the module took. Resolve
+// does not count the modules it imports, which have timings of their own.
+func (m SmiModule) Timing() LoadTiming {
+	return smi.GetModuleTiming(m.smiModule)
+}
+
+// Exports returns the names of the EXPORTS clause of the module, nil if it has
+// none, in which case it exports every name it defines.
+func (m SmiModule) Exports() []string {
+	exports := smi.GetModuleExports(m.smiModule)
+	if exports == nil {
+		return nil
+	}
+	names := make([]string, len(exports))
+	for i, name := range exports {
+		names[i] = name.String()
+	}
+	return names
+}
+
+// LoadText fills in the Description and ContactInfo of the module if it was
+// loaded with SetLazyText, by parsing its file again.
+func (m *SmiModule) LoadText() (err error) {
+	m.Description, m.ContactInfo, err = smi.GetModuleText(m.smiModule)
+	return
+}
+
 func (m SmiModule) GetRaw() (module *types.SmiModule) {
 	return m.smiModule
 }
@@ -93,13 +229,81 @@ func CreateModule(smiModule *types.SmiModule) (module SmiModule) {
 }
 
 func LoadModule(modulePath string) (string, error) {
-	moduleName := smi.LoadModule(modulePath)
-	if moduleName == "" {
-		return "", fmt.Errorf("Could not load module at %s", modulePath)
+	return LoadModuleContext(context.Background(), modulePath)
+}
+
+// LoadModuleContext is LoadModule, but gives up with the context's error once
+// ctx is done.
+func LoadModuleContext(ctx context.Context, modulePath string) (string, error) {
+	moduleName, err := smi.ResolveModuleContext(ctx, modulePath)
+	if err != nil {
+		return "", fmt.Errorf("Could not load module at %s: %w", modulePath, err)
 	}
 	return moduleName, nil
 }
 
+// VersionPolicy controls which file is loaded when several files in the
+// search path or a compiled directory provide the same module.
+type VersionPolicy = smi.VersionPolicy
+
+const (
+	VersionFirst  = smi.VersionFirst
+	VersionLatest = smi.VersionLatest
+	VersionError  = smi.VersionError
+)
+
+// ModuleVersion is a file providing a module, with the revision date it
+// declares.
+type ModuleVersion = smi.ModuleVersion
+
+// SetVersionPolicy sets which of several files providing a module is loaded:
+// the first found (the default), the one with the latest revision, or none,
+// failing with an error wrapping smi.ErrAmbiguousModule.
+func SetVersionPolicy(policy VersionPolicy) { smi.SetVersionPolicy(policy) }
+
+// PreferModulePath makes loading the named module use the file at path,
+// whatever the version policy. An empty path removes the preference.
+func PreferModulePath(module, path string) { smi.SetPreferredPath(module, path) }
+
+// DuplicatePolicy controls which definition is kept when a module defines the
+// same descriptor more than once, as some broken modules do.
+type DuplicatePolicy = smi.DuplicatePolicy
+
+const (
+	DuplicateFirst = smi.DuplicateFirst
+	DuplicateLast  = smi.DuplicateLast
+)
+
+// SetDuplicatePolicy sets whether the first definition of a descriptor a
+// module defines more than once is kept (the default) or the last. Each
+// definition dropped is reported as a warning with the positions of both
+// definitions, as diag.CodeDuplicateDefinition.
+func SetDuplicatePolicy(policy DuplicatePolicy) { smi.SetDuplicatePolicy(policy) }
+
+// ExportPolicy controls how imports of names left out of the EXPORTS clause
+// of the module imported from are handled.
+type ExportPolicy = smi.ExportPolicy
+
+const (
+	ExportIgnore  = smi.ExportIgnore
+	ExportWarn    = smi.ExportWarn
+	ExportEnforce = smi.ExportEnforce
+)
+
+// SetExportPolicy sets whether EXPORTS clauses are ignored (the default), or
+// imports of names a module does not export are reported as warnings, as
+// diag.CodeNotExported, and either still resolved or left unresolved as if
+// they were not imported. Modules without an EXPORTS clause export every name
+// they define.
+func SetExportPolicy(policy ExportPolicy) { smi.SetExportPolicy(policy) }
+
+// GetModuleVersions lists the files found to provide the named module, in the
+// search path and in directories given to CompileDir, with their revision
+// dates. Loaded is set on the one in use.
+func GetModuleVersions(name string) ([]ModuleVersion, error) {
+	return smi.GetModuleVersions(name)
+}
+
 func GetLoadedModules() (modules []SmiModule) {
 	for smiModule := smi.GetFirstModule(); smiModule != nil; smiModule = smi.GetNextModule(smiModule) {
 		modules = append(modules, CreateModule(smiModule))
@@ -107,6 +311,89 @@ func GetLoadedModules() (modules []SmiModule) {
 	return
 }
 
+// GetAllNodes returns the nodes of every loaded module of the given kind,
+// which may combine several kinds, or of any kind if none is given, in module
+// load order.
+func GetAllNodes(kind ...types.NodeKind) (nodes []SmiNode) {
+	for _, module := range GetLoadedModules() {
+		nodes = append(nodes, module.GetNodes(kind...)...)
+	}
+	return
+}
+
+// GetScalars returns the scalar objects of every loaded module.
+func GetScalars() []SmiNode { return GetAllNodes(types.NodeScalar) }
+
+// GetTables returns the tables of every loaded module.
+func GetTables() []SmiNode { return GetAllNodes(types.NodeTable) }
+
+// GetRows returns the conceptual rows of every loaded module.
+func GetRows() []SmiNode { return GetAllNodes(types.NodeRow) }
+
+// GetColumns returns the columns of every loaded module.
+func GetColumns() []SmiNode { return GetAllNodes(types.NodeColumn) }
+
+// GetNotifications returns the notifications and traps of every loaded
+// module.
+func GetNotifications() []SmiNode { return GetAllNodes(types.NodeNotification) }
+
+// GetGroups returns the object and notification groups of every loaded
+// module.
+func GetGroups() []SmiNode { return GetAllNodes(types.NodeGroup) }
+
+// GetCompliances returns the compliance statements of every loaded module.
+func GetCompliances() []SmiNode { return GetAllNodes(types.NodeCompliance) }
+
+// UnloadReport lists the modules removed by UnloadModule or ReloadModule and
+// the imports they left unresolved.
+type UnloadReport = smi.UnloadReport
+
+// UnloadModule removes a loaded module. The modules importing it, directly or
+// through other modules, are removed with it since they refer to its
+// definitions; the report lists them and what they imported from it.
+func UnloadModule(name string) (UnloadReport, error) {
+	return smi.UnloadModule(name)
+}
+
+// ReloadModule refreshes a module whose file changed without tearing down
+// the handle: it unloads the module and its dependents like UnloadModule and
+// builds them again from the files they were loaded from. The report lists
+// the imports of the dependents the module no longer defines.
+func ReloadModule(name string) (UnloadReport, error) {
+	return smi.ReloadModule(name)
+}
+
+// DefineView names a set of modules, such as the standard MIBs or those of a
+// vendor, that can be loaded and switched to as a whole with LoadView and
+// UseView.
+func DefineView(name string, modules ...string) error {
+	return smi.DefineView(name, modules...)
+}
+
+// LoadView loads the modules of the named view and switches to it.
+func LoadView(name string) error {
+	return smi.LoadView(name)
+}
+
+// UseView switches to the named view without loading its modules. Once a
+// view is set, GetNode and GetType without a module, GetNodeByOID, ParseOID
+// and GetLoadedModules only see the modules of the view, the modules they
+// import and the well-known nodes, so that other loaded trees do not shadow
+// or add names. An empty name switches back to all loaded modules.
+func UseView(name string) error {
+	return smi.SetView(name)
+}
+
+// CurrentView returns the name of the view in use, empty if none.
+func CurrentView() string {
+	return smi.CurrentView()
+}
+
+// GetViews returns the names of the defined views, sorted.
+func GetViews() []string {
+	return smi.GetViews()
+}
+
 func IsLoaded(moduleName string) bool {
 	return smi.IsLoaded(moduleName)
 }
@@ -0,0 +1,1176 @@
+package gosmi_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/mibs"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	depTopMib = `DEP-TOP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    depMid FROM DEP-MID-MIB;
+depTop OBJECT IDENTIFIER ::= { depMid 1 }
+END
+`
+	depMidMib = `DEP-MID-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    depBase FROM DEP-BASE-MIB;
+depMid OBJECT IDENTIFIER ::= { depBase 1 }
+END
+`
+	depBaseMib = `DEP-BASE-MIB DEFINITIONS ::= BEGIN
+depBase OBJECT IDENTIFIER ::= { iso 8 }
+END
+`
+	cycleAMib = `CYCLE-A-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    cycleB FROM CYCLE-B-MIB;
+cycleA OBJECT IDENTIFIER ::= { iso 6 }
+cycleA1 OBJECT IDENTIFIER ::= { cycleB 1 }
+END
+`
+	cycleBMib = `CYCLE-B-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    cycleA FROM CYCLE-A-MIB;
+cycleB OBJECT IDENTIFIER ::= { iso 5 }
+END
+`
+)
+
+func TestLoadModuleDependencies(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	name, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, "DEP-TOP-MIB", name)
+	assert.True(t, gosmi.IsLoaded("DEP-MID-MIB"))
+	assert.True(t, gosmi.IsLoaded("DEP-BASE-MIB"))
+
+	node, err := gosmi.GetNode("depTop")
+	require.NoError(t, err)
+	assert.Equal(t, "1.8.1.1", node.RenderNumeric())
+
+	module, err := gosmi.GetModule("DEP-MID-MIB")
+	require.NoError(t, err)
+	ast := module.AST()
+	require.NotNil(t, ast)
+	assert.Equal(t, "DEP-MID-MIB", ast.Name.String())
+	require.Len(t, ast.Body.Imports, 1)
+	assert.Equal(t, "DEP-BASE-MIB", ast.Body.Imports[0].Module.String())
+	assert.Nil(t, gosmi.SmiModule{}.AST())
+}
+
+func TestModuleTiming(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	for _, name := range []string{"DEP-TOP-MIB", "DEP-MID-MIB", "DEP-BASE-MIB"} {
+		module, err := gosmi.GetModule(name)
+		require.NoError(t, err)
+		timing := module.Timing()
+		assert.Positive(t, timing.Lex, name)
+		assert.Positive(t, timing.Parse, name)
+		assert.Positive(t, timing.Resolve, name)
+	}
+	assert.Zero(t, gosmi.SmiModule{}.Timing())
+
+	// Modules parsed by CompileDir
+	gosmi.Exit()
+	gosmi.Init()
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	require.Len(t, report.Files, 3)
+	for _, f := range report.Files {
+		assert.Positive(t, f.LexDuration, f.Path)
+		assert.Less(t, f.LexDuration, f.ParseDuration, f.Path)
+		module, err := gosmi.GetModule(f.Module)
+		require.NoError(t, err)
+		assert.Equal(t, f.LexDuration, module.Timing().Lex)
+		assert.Positive(t, module.Timing().Parse)
+		assert.Positive(t, module.Timing().Resolve)
+	}
+}
+
+func TestLoadModuleDialect(t *testing.T) {
+	if err := parser.RegisterDialect(parser.Dialect{
+		Name:    "gosmi-test",
+		Clauses: map[string][]string{"OBJECT-TYPE": {"X-GOSMI-FLAG"}},
+	}); err != nil {
+		require.Contains(t, parser.Dialects(), "gosmi-test", err)
+	}
+	dir := writeCompileFiles(t, map[string]string{
+		"DIALECT-MIB": `DIALECT-MIB DEFINITIONS ::= BEGIN
+dialect OBJECT IDENTIFIER ::= { iso 7 }
+dialectObject OBJECT-TYPE
+    SYNTAX      INTEGER
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "An object."
+    X-GOSMI-FLAG "on"
+    ::= { dialect 1 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("DIALECT-MIB")
+	require.Error(t, err)
+
+	gosmi.SetDialects("gosmi-test")
+	_, err = gosmi.LoadModule("DIALECT-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("DIALECT-MIB")
+	require.NoError(t, err)
+	extensions := module.AST().ExtensionsOf("dialectObject")
+	require.Len(t, extensions, 1)
+	assert.Equal(t, "X-GOSMI-FLAG", extensions[0].Name)
+	assert.Equal(t, `"on"`, extensions[0].Value)
+
+	// Modules parsed by CompileDir
+	gosmi.Exit()
+	gosmi.Init()
+	gosmi.SetDialects("gosmi-test")
+	report, err := gosmi.CompileDir(dir)
+	require.NoError(t, err)
+	require.Len(t, report.Files, 1)
+	assert.True(t, report.Files[0].OK(), "%v", report.Files[0].Diagnostics)
+}
+
+func TestExportPolicy(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"EXP-BASE-MIB": `EXP-BASE-MIB DEFINITIONS ::= BEGIN
+EXPORTS
+    expBase;
+expBase OBJECT IDENTIFIER ::= { iso 3 }
+expHidden OBJECT IDENTIFIER ::= { iso 4 }
+END
+`,
+		"EXP-APP-MIB": `EXP-APP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    expBase, expHidden FROM EXP-BASE-MIB;
+expApp OBJECT IDENTIFIER ::= { expBase 1 }
+expOther OBJECT IDENTIFIER ::= { expHidden 1 }
+END
+`,
+	})
+	for _, test := range []struct {
+		policy   gosmi.ExportPolicy
+		reported bool
+		oid      string
+	}{
+		{gosmi.ExportIgnore, false, "1.4.1"},
+		{gosmi.ExportWarn, true, "1.4.1"},
+		{gosmi.ExportEnforce, true, ""},
+	} {
+		gosmi.Init()
+		gosmi.SetExportPolicy(test.policy)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("EXP-APP-MIB")
+		require.NoError(t, err)
+
+		base, err := gosmi.GetModule("EXP-BASE-MIB")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"expBase"}, base.Exports())
+		app, err := gosmi.GetModule("EXP-APP-MIB")
+		require.NoError(t, err)
+		assert.Nil(t, app.Exports())
+
+		node, err := gosmi.GetNode("expApp")
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.1", node.RenderNumeric())
+		node, err = gosmi.GetNode("expOther")
+		if test.oid != "" {
+			require.NoError(t, err)
+			assert.Equal(t, test.oid, node.RenderNumeric())
+		} else {
+			require.NoError(t, err)
+			assert.Empty(t, node.Oid, "expHidden is unresolved")
+		}
+
+		var reported []diag.Diagnostic
+		for _, d := range gosmi.GetDiagnostics() {
+			if d.Code == diag.CodeNotExported {
+				reported = append(reported, d)
+			}
+		}
+		if !test.reported {
+			assert.Empty(t, reported)
+		} else if assert.Len(t, reported, 1) {
+			assert.Equal(t, "Symbol expHidden is imported from EXP-BASE-MIB, which does not export it", reported[0].Message)
+			assert.Equal(t, "EXP-APP-MIB", reported[0].Module)
+			assert.Equal(t, 3, reported[0].Pos.Line)
+		}
+		gosmi.Exit()
+	}
+}
+
+func TestLoadBaseModules(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"BASE-APP-MIB": `BASE-APP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Counter32, enterprises FROM SNMPv2-SMI
+    DisplayString FROM SNMPv2-TC
+    OBJECT-GROUP FROM SNMPv2-CONF;
+baseApp MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION  "Example"
+    ::= { enterprises 99999 }
+baseAppName OBJECT-TYPE
+    SYNTAX      DisplayString
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Name."
+    ::= { baseApp 1 }
+baseAppCount OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Count."
+    ::= { baseApp 2 }
+baseAppGroup OBJECT-GROUP
+    OBJECTS     { baseAppName, baseAppCount }
+    STATUS      current
+    DESCRIPTION "Group."
+    ::= { baseApp 3 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	gosmi.LoadBaseModules()
+
+	_, err := gosmi.LoadModule("BASE-APP-MIB")
+	require.NoError(t, err)
+	for _, d := range gosmi.GetDiagnostics() {
+		assert.NotEqual(t, diag.SeverityError, d.Severity, "%v", d)
+	}
+	node, err := gosmi.GetNode("baseAppName")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.99999.1", node.RenderNumeric())
+	assert.Equal(t, "DisplayString", node.Type.Name)
+	assert.Equal(t, types.BaseTypeOctetString, node.Type.BaseType)
+	node, err = gosmi.GetNode("baseAppCount")
+	require.NoError(t, err)
+	assert.Equal(t, types.BaseTypeUnsigned32, node.Type.BaseType)
+	module, err := gosmi.GetModule("SNMPv2-TC")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("[base modules]", "SNMPv2-TC"), module.Path)
+
+	// Every base module loads on its own
+	for _, name := range mibs.Names {
+		_, err := gosmi.LoadModule(name)
+		assert.NoError(t, err, name)
+	}
+	assert.Empty(t, gosmi.GetDiagnostics())
+
+	// Directories earlier in the search path take precedence
+	gosmi.Exit()
+	gosmi.Init()
+	override := writeCompileFiles(t, map[string]string{"SNMPv2-TC": strings.Replace(string(mustReadBase(t, "SNMPv2-TC")), "SIZE (0..255)", "SIZE (0..127)", 1)})
+	gosmi.SetPath(override + string(os.PathListSeparator) + dir)
+	gosmi.LoadBaseModules()
+	_, err = gosmi.LoadModule("BASE-APP-MIB")
+	require.NoError(t, err)
+	module, err = gosmi.GetModule("SNMPv2-TC")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(override, "SNMPv2-TC"), module.Path)
+}
+
+func mustReadBase(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := mibs.FS.ReadFile(name)
+	require.NoError(t, err)
+	return b
+}
+
+func TestUnloadModule(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+		"OTHER-MIB":    "OTHER-MIB DEFINITIONS ::= BEGIN\nother OBJECT IDENTIFIER ::= { iso 8 }\nEND\n",
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	_, err = gosmi.LoadModule("OTHER-MIB")
+	require.NoError(t, err)
+
+	report, err := gosmi.UnloadModule("DEP-BASE-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, []types.SmiIdentifier{"DEP-BASE-MIB", "DEP-MID-MIB", "DEP-TOP-MIB"}, report.Modules)
+	require.Len(t, report.Unresolved, 1)
+	assert.Equal(t, types.SmiIdentifier("DEP-MID-MIB"), report.Unresolved[0].Module)
+	assert.Equal(t, types.SmiImport{Module: "DEP-BASE-MIB", Name: "depBase"}, report.Unresolved[0].Import)
+	for _, name := range report.Modules {
+		assert.False(t, gosmi.IsLoaded(name.String()))
+	}
+	_, err = gosmi.GetNode("depTop")
+	assert.Error(t, err)
+
+	// Nodes shared with other modules stay, with only their objects
+	node, err := gosmi.GetNodeByOID(types.Oid{1, 8})
+	require.NoError(t, err)
+	assert.Equal(t, "other", node.Name)
+	node, err = gosmi.GetNodeByOID(types.Oid{1, 8, 1})
+	require.NoError(t, err)
+	assert.Equal(t, "other", node.Name)
+
+	_, err = gosmi.UnloadModule("DEP-BASE-MIB")
+	assert.Error(t, err)
+	_, err = gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	node, err = gosmi.GetNode("depTop")
+	require.NoError(t, err)
+	assert.Equal(t, "1.8.1.1", node.RenderNumeric())
+}
+
+func TestModuleViews(t *testing.T) {
+	vendorMib := func(name string, arc int) string {
+		return fmt.Sprintf("%s DEFINITIONS ::= BEGIN\nvendor OBJECT IDENTIFIER ::= { iso %d }\nvendorStatus OBJECT IDENTIFIER ::= { vendor 1 }\nEND\n", name, arc)
+	}
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+		"VENDOR-A-MIB": vendorMib("VENDOR-A-MIB", 5),
+		"VENDOR-B-MIB": vendorMib("VENDOR-B-MIB", 6),
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	require.NoError(t, gosmi.DefineView("core", "DEP-TOP-MIB"))
+	require.NoError(t, gosmi.DefineView("a", "DEP-TOP-MIB", "VENDOR-A-MIB"))
+	require.NoError(t, gosmi.DefineView("b", "VENDOR-B-MIB"))
+	assert.Equal(t, []string{"a", "b", "core"}, gosmi.GetViews())
+	assert.Error(t, gosmi.UseView("missing"))
+	assert.Error(t, gosmi.DefineView(""))
+
+	require.NoError(t, gosmi.LoadView("b"))
+	require.NoError(t, gosmi.LoadView("a"))
+	assert.Equal(t, "a", gosmi.CurrentView())
+	node, err := gosmi.GetNode("vendorStatus")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.1", node.RenderNumeric())
+	// Modules imported by those of the view are visible
+	_, err = gosmi.GetNode("depBase")
+	assert.NoError(t, err)
+	_, err = gosmi.GetNodeByOID(types.Oid{1, 6, 1})
+	assert.Error(t, err)
+
+	require.NoError(t, gosmi.UseView("b"))
+	node, err = gosmi.GetNode("vendorStatus")
+	require.NoError(t, err)
+	assert.Equal(t, "1.6.1", node.RenderNumeric())
+	_, err = gosmi.GetNode("depTop")
+	assert.Error(t, err)
+	node, err = gosmi.GetNodeByOID(types.Oid{1, 6, 1, 7})
+	require.NoError(t, err)
+	assert.Equal(t, "vendorStatus", node.Name)
+	_, _, err = gosmi.ParseOID("vendor.1")
+	assert.NoError(t, err)
+	_, _, err = gosmi.ParseOID("iso.depBase")
+	assert.Error(t, err)
+	var names []string
+	for _, module := range gosmi.GetLoadedModules() {
+		names = append(names, module.Name)
+	}
+	assert.Equal(t, []string{"<well-known>", "VENDOR-B-MIB"}, names)
+
+	require.NoError(t, gosmi.UseView("core"))
+	_, err = gosmi.GetNode("vendorStatus")
+	assert.Error(t, err)
+	node, err = gosmi.GetNode("depBase")
+	require.NoError(t, err)
+	assert.Equal(t, "DEP-BASE-MIB", node.GetModule().Name)
+
+	require.NoError(t, gosmi.UseView(""))
+	assert.Len(t, gosmi.GetLoadedModules(), 6)
+}
+
+func TestReloadModule(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+
+	changed := strings.Replace(depBaseMib, "{ iso 8 }", "{ iso 9 }", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DEP-BASE-MIB"), []byte(changed), 0o644))
+	report, err := gosmi.ReloadModule("DEP-BASE-MIB")
+	require.NoError(t, err)
+	assert.Len(t, report.Modules, 3)
+	assert.Empty(t, report.Unresolved)
+	node, err := gosmi.GetNode("depTop")
+	require.NoError(t, err)
+	assert.Equal(t, "1.9.1.1", node.RenderNumeric())
+	node, err = gosmi.GetNodeByOID(types.Oid{1, 8, 1})
+	require.NoError(t, err)
+	assert.Equal(t, "iso", node.Name)
+
+	renamed := strings.Replace(changed, "depBase", "depRenamed", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DEP-BASE-MIB"), []byte(renamed), 0o644))
+	report, err = gosmi.ReloadModule("DEP-BASE-MIB")
+	require.NoError(t, err)
+	require.Len(t, report.Unresolved, 1)
+	assert.Equal(t, types.SmiIdentifier("depBase"), report.Unresolved[0].Import.Name)
+	assert.True(t, gosmi.IsLoaded("DEP-TOP-MIB"))
+}
+
+type rejectModule string
+
+func (r rejectModule) PostResolve(module *types.SmiModule) error {
+	if module.Name.String() == string(r) {
+		return errors.New("Rejected")
+	}
+	return nil
+}
+
+func TestReloadModuleFailures(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+
+	// Left loaded when a file no longer parses or provides the module
+	base := filepath.Join(dir, "DEP-BASE-MIB")
+	for _, content := range []string{
+		"DEP-BASE-MIB DEFINITIONS ::= BEGIN\nbroken\n",
+		strings.Replace(depBaseMib, "DEP-BASE-MIB", "OTHER-BASE-MIB", 1),
+	} {
+		require.NoError(t, os.WriteFile(base, []byte(content), 0o644))
+		report, err := gosmi.ReloadModule("DEP-BASE-MIB")
+		assert.Error(t, err)
+		assert.Empty(t, report.Modules)
+		node, err := gosmi.GetNode("depTop")
+		require.NoError(t, err)
+		assert.Equal(t, "1.8.1.1", node.RenderNumeric())
+	}
+
+	// Reported when a module fails to build again
+	require.NoError(t, os.WriteFile(base, []byte(depBaseMib), 0o644))
+	gosmi.AddPostResolveHook(rejectModule("DEP-MID-MIB"))
+	report, err := gosmi.ReloadModule("DEP-BASE-MIB")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "left unloaded: DEP-MID-MIB")
+	assert.Contains(t, report.Unloaded, types.SmiIdentifier("DEP-MID-MIB"))
+	assert.False(t, gosmi.IsLoaded("DEP-MID-MIB"))
+	assert.True(t, gosmi.IsLoaded("DEP-BASE-MIB"))
+}
+
+func TestLoadModuleMissingDependency(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB": depTopMib,
+		"DEP-MID-MIB": depMidMib,
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		gosmi.Init()
+		defer gosmi.Exit()
+		gosmi.SetPath(dir)
+		var warnings []string
+		gosmi.SetErrorHandler(func(path string, line int, severity int, msg string, tag string) {
+			warnings = append(warnings, msg)
+		})
+
+		_, err := gosmi.LoadModule("DEP-TOP-MIB")
+		require.NoError(t, err)
+		assert.True(t, gosmi.IsLoaded("DEP-MID-MIB"))
+		require.Len(t, warnings, 2)
+		assert.Contains(t, warnings[0], "DEP-BASE-MIB")
+		assert.Equal(t, "Unknown OID parent depBase", warnings[1])
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		gosmi.Init()
+		defer gosmi.Exit()
+		gosmi.SetPath(dir)
+		gosmi.SetDependencyMode(gosmi.DependencyFail)
+
+		_, err := gosmi.LoadModule("DEP-TOP-MIB")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "DEP-BASE-MIB")
+		assert.False(t, gosmi.IsLoaded("DEP-TOP-MIB"))
+		assert.False(t, gosmi.IsLoaded("DEP-MID-MIB"))
+	})
+}
+
+func TestLoadModuleImportCycle(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"CYCLE-A-MIB": cycleAMib,
+		"CYCLE-B-MIB": cycleBMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	gosmi.SetDependencyMode(gosmi.DependencyFail)
+	var warnings []string
+	gosmi.SetErrorHandler(func(path string, line int, severity int, msg string, tag string) {
+		warnings = append(warnings, msg)
+	})
+
+	_, err := gosmi.LoadModule("CYCLE-A-MIB")
+	require.NoError(t, err)
+	assert.True(t, gosmi.IsLoaded("CYCLE-B-MIB"))
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "CYCLE-A-MIB -> CYCLE-B-MIB -> CYCLE-A-MIB")
+
+	node, err := gosmi.GetNode("cycleA1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.1", node.RenderNumeric())
+}
+
+func TestLoadModuleStubDependency(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"STUB-MIB": `STUB-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, vendorRoot, VendorString FROM VENDOR-MIB;
+stubScalar OBJECT-TYPE
+    SYNTAX VendorString
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A scalar of a vendor type."
+    ::= { vendorRoot 1 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	gosmi.SetDependencyMode(gosmi.DependencyStub)
+	var warnings []string
+	gosmi.SetErrorHandler(func(path string, line int, severity int, msg string, tag string) {
+		warnings = append(warnings, msg)
+	})
+
+	_, err := gosmi.LoadModule("STUB-MIB")
+	require.NoError(t, err)
+	assert.True(t, gosmi.IsLoaded("VENDOR-MIB"))
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "using stubs for OBJECT-TYPE, vendorRoot, VendorString")
+	assert.Equal(t, "Unknown OID parent vendorRoot", warnings[1])
+
+	module, err := gosmi.GetModule("STUB-MIB")
+	require.NoError(t, err)
+	node, err := module.GetNode("stubScalar")
+	require.NoError(t, err)
+	require.NotNil(t, node.Type)
+	assert.Equal(t, "VendorString", node.Type.Name)
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(fw, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err = io.WriteString(w, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+}
+
+func TestLoadModuleArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "top.zip"), map[string]string{
+		"vendor/mibs/DEP-TOP-MIB.txt": depTopMib,
+		"README":                      "not a MIB",
+	})
+	writeTarGz(t, filepath.Join(dir, "deps.tar.gz"), map[string]string{
+		"deps/DEP-MID-MIB.mib": depMidMib,
+		"deps/v2/DEP-BASE-MIB": depBaseMib,
+	})
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(filepath.Join(dir, "top.zip"))
+	gosmi.AppendPath(filepath.Join(dir, "deps.tar.gz"))
+	gosmi.SetDependencyMode(gosmi.DependencyFail)
+
+	name, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, "DEP-TOP-MIB", name)
+	node, err := gosmi.GetNode("depTop")
+	require.NoError(t, err)
+	assert.Equal(t, "1.8.1.1", node.RenderNumeric())
+
+	_, err = gosmi.LoadModule("DEP-BASE-MIB")
+	require.NoError(t, err)
+	_, err = gosmi.LoadModule("OTHER-MIB")
+	assert.Error(t, err)
+}
+
+func TestLoadModuleArchiveBomb(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bomb.tar.gz")
+	writeTarGz(t, path, map[string]string{
+		"DEP-BASE-MIB": depBaseMib,
+		"BOMB-MIB":     strings.Repeat("-", 16<<20+1),
+	})
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(path)
+	_, err := gosmi.LoadModule("DEP-BASE-MIB")
+	assert.ErrorContains(t, err, "File BOMB-MIB exceeds 16777216 bytes")
+}
+
+func versionedMib(lastUpdated, oid string) string {
+	return `VERSIONED-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY FROM SNMPv2-SMI;
+versionedMib MODULE-IDENTITY
+    LAST-UPDATED "` + lastUpdated + `"
+    ORGANIZATION "gosmi"
+    CONTACT-INFO "gosmi"
+    DESCRIPTION "Versioned module."
+    ::= { iso ` + oid + ` }
+END
+`
+}
+
+func TestModuleVersions(t *testing.T) {
+	root := t.TempDir()
+	oldDir, newDir := filepath.Join(root, "old"), filepath.Join(root, "new")
+	require.NoError(t, os.Mkdir(oldDir, 0o755))
+	require.NoError(t, os.Mkdir(newDir, 0o755))
+	oldPath, newPath := filepath.Join(oldDir, "VERSIONED-MIB.mib"), filepath.Join(newDir, "VERSIONED-MIB.txt")
+	require.NoError(t, os.WriteFile(oldPath, []byte(versionedMib("200001010000Z", "11")), 0o644))
+	require.NoError(t, os.WriteFile(newPath, []byte(versionedMib("202401010000Z", "12")), 0o644))
+
+	load := func(t *testing.T, setup func()) (string, error) {
+		gosmi.Init()
+		t.Cleanup(gosmi.Exit)
+		gosmi.SetPath(oldDir + string(os.PathListSeparator) + newDir)
+		setup()
+		if _, err := gosmi.LoadModule("VERSIONED-MIB"); err != nil {
+			return "", err
+		}
+		node, err := gosmi.GetNode("versionedMib")
+		require.NoError(t, err)
+		return node.RenderNumeric(), nil
+	}
+
+	t.Run("First", func(t *testing.T) {
+		oid, err := load(t, func() {})
+		require.NoError(t, err)
+		assert.Equal(t, "1.11", oid)
+
+		versions, err := gosmi.GetModuleVersions("VERSIONED-MIB")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, oldPath, versions[0].Path)
+		assert.True(t, versions[0].Loaded)
+		assert.Equal(t, 2000, versions[0].Revision.Year())
+		assert.Equal(t, newPath, versions[1].Path)
+		assert.False(t, versions[1].Loaded)
+		assert.Equal(t, 2024, versions[1].Revision.Year())
+	})
+	t.Run("Latest", func(t *testing.T) {
+		oid, err := load(t, func() { gosmi.SetVersionPolicy(gosmi.VersionLatest) })
+		require.NoError(t, err)
+		assert.Equal(t, "1.12", oid)
+	})
+	t.Run("Error", func(t *testing.T) {
+		_, err := load(t, func() { gosmi.SetVersionPolicy(gosmi.VersionError) })
+		assert.ErrorIs(t, err, smi.ErrAmbiguousModule)
+	})
+	t.Run("Preferred path", func(t *testing.T) {
+		oid, err := load(t, func() {
+			gosmi.SetVersionPolicy(gosmi.VersionError)
+			gosmi.PreferModulePath("VERSIONED-MIB", newPath)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "1.12", oid)
+	})
+}
+
+func TestModuleVersionsDuplicates(t *testing.T) {
+	mib := versionedMib("202401010000Z", "12")
+	dir := writeCompileFiles(t, map[string]string{
+		"a/VERSIONED-MIB":     mib,
+		"b/VERSIONED-MIB.txt": mib,
+	})
+	first, copied := filepath.Join(dir, "a", "VERSIONED-MIB"), filepath.Join(dir, "b", "VERSIONED-MIB.txt")
+
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(filepath.Join(dir, "a") + string(os.PathListSeparator) + filepath.Join(dir, "b"))
+	gosmi.SetVersionPolicy(gosmi.VersionError)
+
+	// Copies of a file are not ambiguous
+	_, err := gosmi.LoadModule("VERSIONED-MIB")
+	require.NoError(t, err)
+	versions, err := gosmi.GetModuleVersions("VERSIONED-MIB")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, first, versions[0].Path)
+	assert.True(t, versions[0].Loaded)
+	assert.Empty(t, versions[0].DuplicateOf)
+	assert.Equal(t, copied, versions[1].Path)
+	assert.Equal(t, first, versions[1].DuplicateOf)
+}
+
+const revisionsMib = `REVISIONS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY FROM SNMPv2-SMI;
+revisionsMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "gosmi"
+    CONTACT-INFO "gosmi"
+    DESCRIPTION "Module with revisions."
+    REVISION "202301010000Z"
+    DESCRIPTION "Second."
+    REVISION "9901010000Z"
+    DESCRIPTION "First."
+    REVISION "202401010000Z"
+    DESCRIPTION "Third, out of order."
+    ::= { iso 13 }
+END
+`
+
+func TestModuleRevisions(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"REVISIONS-MIB": revisionsMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("REVISIONS-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("REVISIONS-MIB")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), module.LastUpdated())
+	revisions := module.GetRevisions()
+	require.Len(t, revisions, 3)
+	assert.Equal(t, "Third, out of order.", revisions[0].Description)
+	assert.Equal(t, 13, revisions[0].Line)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), revisions[1].Date)
+	assert.Equal(t, time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC), revisions[2].Date)
+}
+
+func TestModuleLazyText(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"REVISIONS-MIB": revisionsMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetLazyText(true)
+	defer gosmi.SetLazyText(false)
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("REVISIONS-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("REVISIONS-MIB")
+	require.NoError(t, err)
+	assert.Empty(t, module.Description)
+	assert.Empty(t, module.ContactInfo)
+	assert.Equal(t, "gosmi", module.Organization)
+	require.NoError(t, module.LoadText())
+	assert.Equal(t, "Module with revisions.", module.Description)
+	assert.Equal(t, "gosmi", module.ContactInfo)
+	assert.Equal(t, "Second.", module.GetRevisions()[1].Description)
+
+	identity, ok := module.GetIdentityNode()
+	require.True(t, ok)
+	require.NoError(t, identity.LoadText())
+	assert.Equal(t, "Module with revisions.", identity.Description)
+}
+
+func TestModuleIdentity(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"REVISIONS-MIB": revisionsMib,
+		"PLAIN-MIB":     "PLAIN-MIB DEFINITIONS ::= BEGIN\nplain OBJECT IDENTIFIER ::= { iso 14 }\nEND\n",
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("REVISIONS-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("REVISIONS-MIB")
+	require.NoError(t, err)
+	identity, ok := module.GetIdentity()
+	require.True(t, ok)
+	assert.Equal(t, "revisionsMib", identity.Name)
+	assert.Equal(t, "1.13", identity.RenderNumeric())
+	assert.Equal(t, types.DeclModuleIdentity, identity.Decl)
+	assert.Equal(t, "gosmi", identity.Organization)
+	assert.Equal(t, "gosmi", identity.ContactInfo)
+	assert.Equal(t, "Module with revisions.", identity.Description)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), identity.LastUpdated)
+
+	_, err = gosmi.LoadModule("PLAIN-MIB")
+	require.NoError(t, err)
+	module, err = gosmi.GetModule("PLAIN-MIB")
+	require.NoError(t, err)
+	_, ok = module.GetIdentity()
+	assert.False(t, ok)
+}
+
+const anchoredMib = `ANCHORED-MIB DEFINITIONS ::= BEGIN
+anchored OBJECT IDENTIFIER ::= { acmeRoot 7 }
+anchoredEnterprise OBJECT IDENTIFIER ::= { enterprises 99999 }
+END
+`
+
+func TestModuleValues(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"VALUE-MIB": `VALUE-MIB DEFINITIONS ::= BEGIN
+Status ::= INTEGER { up(1), down(2) }
+someInteger INTEGER ::= 5
+big INTEGER (0..18446744073709551615) ::= 18446744073709551615
+someString OCTET STRING ::= "text"
+bytes OCTET STRING ::= '0f1'H
+state Status ::= down
+unknown Missing ::= 1
+END
+`})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("VALUE-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("VALUE-MIB")
+	require.NoError(t, err)
+
+	values := module.GetValues()
+	require.Len(t, values, 6)
+	assert.Equal(t, "someInteger", values[0].Name)
+	assert.Equal(t, 3, values[0].Line)
+	assert.Equal(t, types.BaseTypeInteger32, values[0].Type.BaseType)
+	assert.Equal(t, int32(5), values[0].Value.Value)
+
+	value, err := module.GetValue("big")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), value.Value.Value)
+	value, err = module.GetValue("someString")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("text"), value.Value.Value)
+	value, err = module.GetValue("bytes")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0f, 0x10}, value.Value.Value)
+	value, err = module.GetValue("state")
+	require.NoError(t, err)
+	assert.Equal(t, "Status", value.Type.Name)
+	assert.Equal(t, int32(2), value.Value.Value)
+	value, err = module.GetValue("unknown")
+	require.NoError(t, err)
+	assert.Empty(t, value.Type.Name)
+	assert.Equal(t, "1", value.Value.Value)
+	_, err = module.GetValue("missing")
+	assert.Error(t, err)
+}
+
+func TestCoreTypes(t *testing.T) {
+	defer gosmi.ResetRootNodes()
+	dir := writeCompileFiles(t, map[string]string{"CORE-MIB": `CORE-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    OBJECT-TYPE, Counter32, Counter64, IpAddress, enterprises FROM SNMPv2-SMI;
+core OBJECT IDENTIFIER ::= { enterprises 99999 }
+coreCount OBJECT-TYPE
+    SYNTAX      Counter32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Count."
+    ::= { core 1 }
+coreAddress OBJECT-TYPE
+    SYNTAX      IpAddress
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Address."
+    ::= { core 2 }
+coreFlags OBJECT-TYPE
+    SYNTAX      BITS { up(0), down(1) }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Flags."
+    ::= { core 3 }
+CoreBig ::= Counter64
+END
+`})
+	require.NoError(t, gosmi.RegisterSNMPRootNodes())
+	for _, mode := range []gosmi.DependencyMode{gosmi.DependencyWarn, gosmi.DependencyStub} {
+		gosmi.Init()
+		gosmi.SetPath(dir)
+		gosmi.SetDependencyMode(mode)
+		_, err := gosmi.LoadModule("CORE-MIB")
+		require.NoError(t, err)
+		node, err := gosmi.GetNode("coreCount")
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.6.1.4.1.99999.1", node.RenderNumeric())
+		assert.True(t, node.Type == nil || node.Type.BaseType != types.BaseTypeUnsigned32, "unresolved without core types")
+		gosmi.Exit()
+
+		gosmi.Init()
+		gosmi.SetPath(dir)
+		gosmi.SetDependencyMode(mode)
+		gosmi.SetCoreTypes(true)
+		_, err = gosmi.LoadModule("CORE-MIB")
+		require.NoError(t, err)
+		node, err = gosmi.GetNode("coreCount")
+		require.NoError(t, err)
+		assert.Equal(t, "Counter32", node.Type.Name)
+		assert.Equal(t, types.BaseTypeUnsigned32, node.Type.BaseType)
+		node, err = gosmi.GetNode("coreAddress")
+		require.NoError(t, err)
+		assert.Equal(t, "IpAddress", node.Type.Name)
+		assert.Equal(t, types.BaseTypeOctetString, node.Type.BaseType)
+		require.Len(t, node.Type.Ranges, 1)
+		assert.Equal(t, int64(4), node.Type.Ranges[0].MinValue)
+		node, err = gosmi.GetNode("coreFlags")
+		require.NoError(t, err)
+		assert.Equal(t, types.BaseTypeBits, node.Type.BaseType)
+		typ, err := gosmi.GetType("CoreBig")
+		require.NoError(t, err)
+		assert.Equal(t, types.BaseTypeUnsigned64, typ.BaseType)
+		for _, d := range gosmi.GetDiagnostics() {
+			assert.NotEqual(t, diag.CodeUnknownType, d.Code, "%v", d)
+		}
+		gosmi.Exit()
+	}
+}
+
+func TestRegisterRootNode(t *testing.T) {
+	defer gosmi.ResetRootNodes()
+	dir := writeCompileFiles(t, map[string]string{"ANCHORED-MIB": anchoredMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+
+	require.NoError(t, gosmi.RegisterRootNode("acmeRoot", types.Oid{1, 3, 6, 1, 4, 1, 424242}))
+	require.NoError(t, gosmi.RegisterRootNode("acmeRoot", types.Oid{1, 3, 6, 1, 4, 1, 424242}))
+	assert.Error(t, gosmi.RegisterRootNode("acmeRoot", types.Oid{1, 3, 6, 1, 4, 1, 1}))
+	assert.Error(t, gosmi.RegisterRootNode("", types.Oid{1}))
+	assert.Error(t, gosmi.RegisterRootNode("empty", nil))
+	require.NoError(t, gosmi.RegisterSNMPRootNodes())
+
+	_, err := gosmi.LoadModule("ANCHORED-MIB")
+	require.NoError(t, err)
+	node, err := gosmi.GetNode("anchored")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.424242.7", node.RenderNumeric())
+	node, err = gosmi.GetNode("anchoredEnterprise")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.99999", node.RenderNumeric())
+
+	// Registrations carry over to the next handle until reset
+	gosmi.Exit()
+	gosmi.Init()
+	node, err = gosmi.GetNode("acmeRoot")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.424242", node.RenderNumeric())
+
+	gosmi.ResetRootNodes()
+	gosmi.Exit()
+	gosmi.Init()
+	_, err = gosmi.GetNode("acmeRoot")
+	assert.Error(t, err)
+	assert.Len(t, gosmi.GetRootNodes(), 3)
+}
+
+// benchModules writes modules repeating the same boilerplate, as vendor
+// trees do, and returns their directory and names.
+func benchModules(b *testing.B) (dir string, names []string) {
+	const modules, objects = 40, 50
+	files := make(map[string]string, modules)
+	for m := 0; m < modules; m++ {
+		name := fmt.Sprintf("BENCH%d-MIB", m)
+		names = append(names, name)
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%s DEFINITIONS ::= BEGIN\nbench%d OBJECT IDENTIFIER ::= { iso %d }\n", name, m, m+10)
+		for o := 0; o < objects; o++ {
+			fmt.Fprintf(&sb, `bench%dObject%d OBJECT-TYPE
+    SYNTAX INTEGER { enabled(1), disabled(2) }
+    UNITS "seconds"
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "Whether the feature is enabled. Setting this object to
+        disabled stops the feature immediately; setting it back to enabled
+        resumes it with the configuration in effect before it was stopped."
+    ::= { bench%d %d }
+`, m, o, m, o+1)
+		}
+		sb.WriteString("END\n")
+		files[name] = sb.String()
+	}
+	return writeCompileFiles(b, files), names
+}
+
+// BenchmarkLoad reports the time and allocations loading a set of modules
+// takes.
+func BenchmarkLoad(b *testing.B) {
+	dir, names := benchModules(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		gosmi.Init()
+		gosmi.SetPath(dir)
+		for _, name := range names {
+			if _, err := gosmi.LoadModule(name); err != nil {
+				b.Fatal(err)
+			}
+		}
+		gosmi.Exit()
+	}
+}
+
+// BenchmarkLoadMemory reports the heap taken by a set of modules, with and
+// without their ASTs and texts.
+func BenchmarkLoadMemory(b *testing.B) {
+	dir, names := benchModules(b)
+
+	for _, mode := range []struct {
+		name   string
+		retain bool
+		lazy   bool
+	}{
+		{"RetainAST", true, false},
+		{"DiscardAST", false, false},
+		{"LazyText", false, true},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			var heap uint64
+			for i := 0; i < b.N; i++ {
+				var loaded, empty runtime.MemStats
+				gosmi.Init()
+				gosmi.SetRetainAST(mode.retain)
+				gosmi.SetLazyText(mode.lazy)
+				gosmi.SetPath(dir)
+				for _, name := range names {
+					if _, err := gosmi.LoadModule(name); err != nil {
+						b.Fatal(err)
+					}
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&loaded)
+				gosmi.Exit()
+
+				// The next handle replaces the last one, which Exit keeps
+				gosmi.Init()
+				runtime.GC()
+				runtime.ReadMemStats(&empty)
+				gosmi.Exit()
+				heap += loaded.HeapAlloc - empty.HeapAlloc
+			}
+			b.ReportMetric(float64(heap)/float64(b.N), "heap-bytes/op")
+		})
+	}
+}
+
+func TestSetRetainAST(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"DEP-TOP-MIB":  depTopMib,
+		"DEP-MID-MIB":  depMidMib,
+		"DEP-BASE-MIB": depBaseMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetRetainAST(false)
+	defer gosmi.SetRetainAST(true)
+	gosmi.SetPath(dir)
+
+	_, err := gosmi.LoadModule("DEP-TOP-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("DEP-MID-MIB")
+	require.NoError(t, err)
+	assert.Nil(t, module.AST())
+	node, err := gosmi.GetNode("depTop")
+	require.NoError(t, err)
+	assert.Equal(t, "1.8.1.1", node.RenderNumeric())
+}
+
+func TestModuleNodesByKind(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"DOC-MIB": markdownMib, "TRAP-MIB": trapMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	for _, name := range []string{"DOC-MIB", "TRAP-MIB"} {
+		_, err := gosmi.LoadModule(name)
+		require.NoError(t, err)
+	}
+	module, err := gosmi.GetModule("DOC-MIB")
+	require.NoError(t, err)
+
+	names := func(nodes []gosmi.SmiNode) (names []string) {
+		for _, n := range nodes {
+			names = append(names, n.Name)
+		}
+		return
+	}
+	assert.Equal(t, []string{"docTable"}, names(module.GetTables()))
+	assert.Equal(t, []string{"docEntry"}, names(module.GetRows()))
+	assert.Equal(t, []string{"docIndex", "docState"}, names(module.GetColumns()))
+	assert.Empty(t, module.GetScalars())
+	assert.Equal(t, []string{"docChanged"}, names(module.GetNotifications()))
+
+	assert.Equal(t, []string{"trapStatus"}, names(gosmi.GetScalars()))
+	assert.Equal(t, []string{"docChanged", "trapLegacy", "trapStatusChange"}, names(gosmi.GetNotifications()))
+	assert.Equal(t, []string{"docTable", "docEntry", "trapStatus"}, names(gosmi.GetAllNodes(types.NodeTable|types.NodeRow|types.NodeScalar)))
+}
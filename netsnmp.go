@@ -0,0 +1,101 @@
+package gosmi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NetSNMPDefaultMIBDirs are the directories net-snmp searches for modules by
+// default, which MIBDIRS replaces or, starting with + or -, adds to.
+var NetSNMPDefaultMIBDirs = []string{"~/.snmp/mibs", "/usr/share/snmp/mibs", "/usr/local/share/snmp/mibs"}
+
+// NetSNMPDefaultMIBs are the modules net-snmp loads by default, which MIBS
+// replaces or, starting with + or -, adds to.
+var NetSNMPDefaultMIBs = []string{"SNMPv2-MIB", "IF-MIB", "IP-MIB", "TCP-MIB", "UDP-MIB"}
+
+// UseNetSNMPEnv searches and loads modules as net-snmp tools do, so that
+// gosmi-based tools work in environments set up for them. The directories of
+// MIBDIRS are searched before the current search path, and the modules of
+// MIBS are loaded, ALL standing for every module in the directories, their
+// subdirectories included. Either variable replaces net-snmp's defaults,
+// NetSNMPDefaultMIBDirs and NetSNMPDefaultMIBs, unless its value starts with
+// a +, which puts its entries before the defaults, or a -, after them.
+// Entries are separated by os.PathListSeparator, a colon except on Windows.
+//
+// Modules MIBS names that cannot be loaded fail UseNetSNMPEnv, once the
+// others are loaded. Default modules that cannot be found are passed over.
+func UseNetSNMPEnv() error {
+	dirs := netSNMPList(os.Getenv("MIBDIRS"), NetSNMPDefaultMIBDirs)
+	if len(dirs) > 0 {
+		PrependPath(strings.Join(dirs, string(os.PathListSeparator)))
+	}
+
+	value := os.Getenv("MIBS")
+	explicit := make(map[string]bool)
+	for _, name := range splitNetSNMPList(strings.TrimLeft(value, "+-")) {
+		explicit[name] = true
+	}
+	var failed []string
+	for _, name := range netSNMPList(value, NetSNMPDefaultMIBs) {
+		if name == "ALL" {
+			for _, dir := range dirs {
+				if _, err := os.Stat(expandHome(dir)); err != nil {
+					continue
+				}
+				if _, err := CompileDir(expandHome(dir)); err != nil {
+					failed = append(failed, fmt.Sprintf("%s: %v", dir, err))
+				}
+			}
+			continue
+		}
+		if _, err := LoadModule(name); err != nil && (explicit[name] || !errors.Is(err, os.ErrNotExist)) {
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Load MIBS: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// netSNMPList returns the entries of the value of a net-snmp list variable,
+// the defaults if it is empty, with the defaults after the entries if it
+// starts with a + and before them if it starts with a -.
+func netSNMPList(value string, defaults []string) []string {
+	var list []string
+	switch {
+	case value == "":
+		return append(list, defaults...)
+	case value[0] == '+':
+		list = append(splitNetSNMPList(value[1:]), defaults...)
+	case value[0] == '-':
+		list = append(append(list, defaults...), splitNetSNMPList(value[1:])...)
+	default:
+		list = splitNetSNMPList(value)
+	}
+	return list
+}
+
+func splitNetSNMPList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, string(os.PathListSeparator)) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// expandHome returns path with a leading ~ replaced by the home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
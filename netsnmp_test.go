@@ -0,0 +1,111 @@
+package gosmi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setNetSNMPDefaults(t *testing.T, dirs, mibs []string) {
+	t.Helper()
+	prevDirs, prevMibs := gosmi.NetSNMPDefaultMIBDirs, gosmi.NetSNMPDefaultMIBs
+	gosmi.NetSNMPDefaultMIBDirs, gosmi.NetSNMPDefaultMIBs = dirs, mibs
+	t.Cleanup(func() { gosmi.NetSNMPDefaultMIBDirs, gosmi.NetSNMPDefaultMIBs = prevDirs, prevMibs })
+}
+
+func loadedModuleNames() []string {
+	var names []string
+	for _, m := range gosmi.GetLoadedModules() {
+		if !strings.HasPrefix(m.Name, "<") {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+func TestUseNetSNMPEnv(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"base/COMPILE-BASE-MIB":   compileBaseMib,
+		"app/COMPILE-APP-MIB.txt": compileAppMib,
+		"other/OTHER-MIB":         "OTHER-MIB DEFINITIONS ::= BEGIN\nEND\n",
+	})
+	base, app, other := filepath.Join(dir, "base"), filepath.Join(dir, "app"), filepath.Join(dir, "other")
+	list := func(entries ...string) string { return strings.Join(entries, string(os.PathListSeparator)) }
+
+	tests := []struct {
+		name    string
+		mibdirs string
+		mibs    string
+		paths   []string
+		loaded  []string
+		err     string
+	}{
+		{
+			name:   "Defaults",
+			paths:  []string{base, app},
+			loaded: []string{"COMPILE-BASE-MIB"},
+		},
+		{
+			name:    "Replace",
+			mibdirs: list(app, base),
+			mibs:    "COMPILE-APP-MIB",
+			paths:   []string{app, base},
+			loaded:  []string{"COMPILE-APP-MIB", "COMPILE-BASE-MIB"},
+		},
+		{
+			name:    "Prepend",
+			mibdirs: "+" + other,
+			mibs:    "+" + list("OTHER-MIB", "COMPILE-APP-MIB"),
+			paths:   []string{other, base, app},
+			loaded:  []string{"OTHER-MIB", "COMPILE-APP-MIB", "COMPILE-BASE-MIB"},
+		},
+		{
+			name:    "Append",
+			mibdirs: "-" + other,
+			mibs:    "-OTHER-MIB",
+			paths:   []string{base, app, other},
+			loaded:  []string{"COMPILE-BASE-MIB", "OTHER-MIB"},
+		},
+		{
+			name:    "All",
+			mibdirs: list(base, other),
+			mibs:    "ALL",
+			paths:   []string{base, other},
+			loaded:  []string{"COMPILE-BASE-MIB", "OTHER-MIB"},
+		},
+		{
+			name:   "Missing",
+			mibs:   list("MISSING-MIB", "COMPILE-BASE-MIB"),
+			paths:  []string{base, app},
+			loaded: []string{"COMPILE-BASE-MIB"},
+			err:    "MISSING-MIB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setNetSNMPDefaults(t, []string{base, app, filepath.Join(dir, "absent")}, []string{"COMPILE-BASE-MIB", "ABSENT-MIB"})
+			t.Setenv("MIBDIRS", tt.mibdirs)
+			t.Setenv("MIBS", tt.mibs)
+
+			gosmi.Init()
+			defer gosmi.Exit()
+			gosmi.SetPath(other)
+			err := gosmi.UseNetSNMPEnv()
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			paths := filepath.SplitList(gosmi.GetPath())
+			require.GreaterOrEqual(t, len(paths), len(tt.paths))
+			assert.Equal(t, tt.paths, paths[:len(tt.paths)])
+			assert.ElementsMatch(t, tt.loaded, loadedModuleNames())
+		})
+	}
+}
@@ -19,6 +19,11 @@ func (n SmiNode) GetModule() (module SmiModule) {
 	return CreateModule(smiModule)
 }
 
+// Source returns where the node is defined.
+func (n SmiNode) Source() Source {
+	return newSource(smi.GetNodeModule(n.smiNode), smi.GetNodeLine(n.smiNode), smi.GetNodeColumn(n.smiNode))
+}
+
 func (n SmiNode) GetSubtree() (nodes []SmiNode) {
 	first := true
 	smiNode := n.smiNode
@@ -42,6 +47,21 @@ func (n SmiNode) RenderQualified() string {
 	return n.Render(types.RenderQualified)
 }
 
+// FormatOID renders the OID of the node followed by the instance
+// sub-identifiers, if any, in the given format, e.g. IF-MIB::ifInOctets.3 for
+// types.OidFormatModule.
+func (n SmiNode) FormatOID(format types.OidFormat, instance ...types.SmiSubId) string {
+	oid := append(append(types.Oid(nil), n.smiNode.Oid...), instance...)
+	return smi.FormatOID(oid, format)
+}
+
+// LoadText fills in the Description and Reference of the node if its module
+// was loaded with SetLazyText, by parsing the module's file again.
+func (n *SmiNode) LoadText() (err error) {
+	n.Description, n.Reference, err = smi.GetNodeText(n.smiNode)
+	return
+}
+
 func (n SmiNode) GetRaw() (node *types.SmiNode) {
 	return n.smiNode
 }
@@ -56,28 +76,56 @@ func CreateNode(smiNode *types.SmiNode) SmiNode {
 			Access:      smiNode.Access,
 			Decl:        smiNode.Decl,
 			Description: smiNode.Description,
+			Format:      smiNode.Format,
 			Kind:        smiNode.NodeKind,
 			Name:        string(smiNode.Name),
 			OidLen:      smiNode.OidLen,
 			Oid:         smiNode.Oid,
+			Reference:   smiNode.Reference,
 			Status:      smiNode.Status,
+			Units:       smiNode.Units,
 		},
 		smiNode: smiNode,
 		SmiType: CreateTypeFromNode(smiNode),
 	}
 	if node.SmiType != nil {
 		node.Type = &node.SmiType.Type
+		// The type carries the DISPLAY-HINT and UNITS in effect for the node
+		node.Format = node.Type.Format
+		node.Units = node.Type.Units
 	}
 	return node
 }
 
+// CollisionPolicy controls which definition GetNode, GetType and ParseOID
+// resolve a name to when it is not qualified by a module and several loaded
+// modules define it, as when a module repeats definitions of another.
+type CollisionPolicy = smi.CollisionPolicy
+
+const (
+	CollisionFirst   = smi.CollisionFirst
+	CollisionLast    = smi.CollisionLast
+	CollisionError   = smi.CollisionError
+	CollisionQualify = smi.CollisionQualify
+)
+
+// SetCollisionPolicy sets how names defined by several modules are resolved:
+// to the module loaded first (the default) or last, or failing with an error
+// wrapping smi.ErrAmbiguousName, either only when the definitions differ or
+// always, so that such names must be looked up with GetNodeInModule or
+// GetTypeInModule.
+func SetCollisionPolicy(policy CollisionPolicy) { smi.SetCollisionPolicy(policy) }
+
+// GetNode looks the name up in the given module, or in all loaded modules
+// according to the collision policy.
 func GetNode(name string, module ...SmiModule) (node SmiNode, err error) {
-	var smiModule *types.SmiModule
+	var smiNode *types.SmiNode
 	if len(module) > 0 {
-		smiModule = module[0].GetRaw()
+		smiNode = smi.GetNode(module[0].GetRaw(), name)
+	} else if smiNode, err = smi.ResolveNode(name); err != nil {
+		err = fmt.Errorf("Could not resolve node named %s: %w", name, err)
+		return
 	}
-
-	smiNode := smi.GetNode(smiModule, name)
 	if smiNode == nil {
 		if len(module) > 0 {
 			err = fmt.Errorf("Could not find node named %s in module %s", name, module[0].Name)
@@ -89,6 +137,16 @@ func GetNode(name string, module ...SmiModule) (node SmiNode, err error) {
 	return CreateNode(smiNode), nil
 }
 
+// GetNodeInModule looks the name up in the named module, loading it if
+// needed, whatever the collision policy.
+func GetNodeInModule(module, name string) (node SmiNode, err error) {
+	smiModule, err := GetModule(module)
+	if err != nil {
+		return
+	}
+	return GetNode(name, smiModule)
+}
+
 func GetNodeByOID(oid types.Oid) (node SmiNode, err error) {
 	smiNode := smi.GetNodeByOID(oid)
 	if smiNode == nil {
@@ -97,3 +155,21 @@ func GetNodeByOID(oid types.Oid) (node SmiNode, err error) {
 	}
 	return CreateNode(smiNode), nil
 }
+
+// ParseOID resolves an OID given as numeric, symbolic, relative to a node or
+// module-qualified, such as IF-MIB::ifInOctets.3, to the node it belongs to
+// and the instance sub-identifiers following it, 3 in the example. It is the
+// inverse of FormatOID.
+func ParseOID(s string) (node SmiNode, instance types.Oid, err error) {
+	smiNode, instance, err := smi.ParseOID(s)
+	if err != nil {
+		return
+	}
+	return CreateNode(smiNode), instance, nil
+}
+
+// FormatOID renders an OID, such as that of an instance, in the given format,
+// naming it after the deepest loaded node it belongs to.
+func FormatOID(oid types.Oid, format types.OidFormat) string {
+	return smi.FormatOID(oid, format)
+}
@@ -0,0 +1,681 @@
+package gosmi_test
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nodeMib = `NODE-MIB DEFINITIONS ::= BEGIN
+Celsius ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "d-1"
+    STATUS current
+    DESCRIPTION "A temperature in tenths of a degree."
+    SYNTAX INTEGER (-1000..1000)
+
+nodeRoot OBJECT IDENTIFIER ::= { iso 3 }
+
+nodeTemperature OBJECT-TYPE
+    SYNTAX Celsius
+    UNITS "0.1 degrees Celsius"
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The current temperature."
+    REFERENCE "Sensor datasheet, section 4."
+    ::= { nodeRoot 1 }
+
+nodePlain OBJECT-TYPE
+    SYNTAX INTEGER (0..10)
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A plain integer."
+    ::= { nodeRoot 2 }
+END
+`
+
+func TestSmiNodeUnitsReferenceFormat(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"NODE-MIB": nodeMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("NODE-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("nodeTemperature")
+	require.NoError(t, err)
+	assert.Equal(t, "0.1 degrees Celsius", node.Units)
+	assert.Equal(t, "Sensor datasheet, section 4.", node.Reference)
+	assert.Equal(t, "d-1", node.Format)
+
+	node, err = gosmi.GetNode("nodePlain")
+	require.NoError(t, err)
+	assert.Empty(t, node.Units)
+	assert.Empty(t, node.Reference)
+	assert.Empty(t, node.Format)
+}
+
+func TestSmiNodeSource(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"NODE-MIB": nodeMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("NODE-MIB")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "NODE-MIB")
+
+	node, err := gosmi.GetNode("nodeTemperature")
+	require.NoError(t, err)
+	src := node.Source()
+	assert.Equal(t, "NODE-MIB", src.Module)
+	assert.Equal(t, diag.Position{Filename: path, Line: 10, Column: 1}, src.Pos)
+	assert.Equal(t, "NODE-MIB ("+path+":10:1)", src.String())
+
+	require.NotNil(t, node.SmiType)
+	src = node.SmiType.Source()
+	assert.Equal(t, "NODE-MIB", src.Module)
+	assert.Equal(t, 2, src.Pos.Line)
+	assert.Equal(t, 1, src.Pos.Column)
+
+	// The built-in base type
+	chain := node.SmiType.BaseTypeChain()
+	assert.Zero(t, chain[len(chain)-1].Source().Pos.Line)
+}
+
+func TestFormatOID(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"NODE-MIB": nodeMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("NODE-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("nodeTemperature")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.1", node.FormatOID(types.OidFormatNumeric))
+	assert.Equal(t, "iso.nodeRoot.nodeTemperature.0", node.FormatOID(types.OidFormatFull, 0))
+	assert.Equal(t, "nodeTemperature.0", node.FormatOID(types.OidFormatSuffix, 0))
+	assert.Equal(t, "NODE-MIB::nodeTemperature.0", node.FormatOID(types.OidFormatModule, 0))
+
+	// Sub-identifiers below unknown nodes are kept numeric
+	oid := types.Oid{1, 3, 9, 4}
+	assert.Equal(t, "1.3.9.4", gosmi.FormatOID(oid, types.OidFormatNumeric))
+	assert.Equal(t, "iso.nodeRoot.9.4", gosmi.FormatOID(oid, types.OidFormatFull))
+	assert.Equal(t, "nodeRoot.9.4", gosmi.FormatOID(oid, types.OidFormatSuffix))
+	assert.Equal(t, "NODE-MIB::nodeRoot.9.4", gosmi.FormatOID(oid, types.OidFormatModule))
+	assert.Equal(t, "iso.5", gosmi.FormatOID(types.Oid{1, 5}, types.OidFormatModule))
+	assert.Equal(t, "7.1", gosmi.FormatOID(types.Oid{7, 1}, types.OidFormatModule))
+
+	for _, s := range []string{"S", "module", "Module"} {
+		format, err := types.OidFormatFromString(s)
+		require.NoError(t, err)
+		assert.Equal(t, types.OidFormatModule, format)
+	}
+	_, err = types.OidFormatFromString("x")
+	assert.Error(t, err)
+}
+
+func TestParseOID(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"NODE-MIB": nodeMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("NODE-MIB")
+	require.NoError(t, err)
+
+	tests := []struct {
+		oid      string
+		node     string
+		instance types.Oid
+	}{
+		{"NODE-MIB::nodeTemperature.0", "nodeTemperature", types.Oid{0}},
+		{"nodeTemperature", "nodeTemperature", types.Oid{}},
+		{"nodePlain.1.2", "nodePlain", types.Oid{1, 2}},
+		{"iso.nodeRoot.nodeTemperature.0", "nodeTemperature", types.Oid{0}},
+		{".iso.3.2.5", "nodePlain", types.Oid{5}},
+		{"1.3.1.0", "nodeTemperature", types.Oid{0}},
+		{".1.3.9", "nodeRoot", types.Oid{9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.oid, func(t *testing.T) {
+			node, instance, err := gosmi.ParseOID(tt.oid)
+			require.NoError(t, err)
+			assert.Equal(t, tt.node, node.Name)
+			assert.Equal(t, tt.instance, instance)
+		})
+	}
+
+	// Parsing what FormatOID renders gives back the same OID
+	oid := types.Oid{1, 3, 2, 7}
+	for _, format := range []types.OidFormat{types.OidFormatNumeric, types.OidFormatFull, types.OidFormatSuffix, types.OidFormatModule} {
+		node, instance, err := gosmi.ParseOID(gosmi.FormatOID(oid, format))
+		require.NoError(t, err)
+		assert.Equal(t, oid, append(node.Oid, instance...))
+	}
+
+	for _, bad := range []string{"", "OTHER-MIB::nodeRoot", "noSuchNode.1", "iso.nodePlain", "nodeRoot..1", "7.1"} {
+		_, _, err := gosmi.ParseOID(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCollisionPolicy(t *testing.T) {
+	collisionMib := func(name string, arc int, syntax string) string {
+		return name + " DEFINITIONS ::= BEGIN\n" +
+			"shared OBJECT IDENTIFIER ::= { iso 7 }\n" +
+			"clash OBJECT IDENTIFIER ::= { iso " + strconv.Itoa(arc) + " }\n" +
+			"Clash ::= " + syntax + "\n" +
+			"END\n"
+	}
+	dir := writeCompileFiles(t, map[string]string{
+		"COLLIDE-A-MIB": collisionMib("COLLIDE-A-MIB", 5, "INTEGER"),
+		"COLLIDE-B-MIB": collisionMib("COLLIDE-B-MIB", 6, "OCTET STRING"),
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	for _, name := range []string{"COLLIDE-A-MIB", "COLLIDE-B-MIB"} {
+		_, err := gosmi.LoadModule(name)
+		require.NoError(t, err)
+	}
+
+	node, err := gosmi.GetNode("clash")
+	require.NoError(t, err)
+	assert.Equal(t, "COLLIDE-A-MIB", node.GetModule().Name)
+
+	gosmi.SetCollisionPolicy(gosmi.CollisionLast)
+	node, err = gosmi.GetNode("clash")
+	require.NoError(t, err)
+	assert.Equal(t, "COLLIDE-B-MIB", node.GetModule().Name)
+	typ, err := gosmi.GetType("Clash")
+	require.NoError(t, err)
+	assert.Equal(t, types.BaseTypeOctetString, typ.BaseType)
+
+	gosmi.SetCollisionPolicy(gosmi.CollisionError)
+	_, err = gosmi.GetNode("clash")
+	assert.ErrorIs(t, err, smi.ErrAmbiguousName)
+	_, err = gosmi.GetType("Clash")
+	assert.ErrorIs(t, err, smi.ErrAmbiguousName)
+	_, _, err = gosmi.ParseOID("clash.0")
+	assert.ErrorIs(t, err, smi.ErrAmbiguousName)
+	// Alike definitions are not a conflict
+	node, err = gosmi.GetNode("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "COLLIDE-A-MIB", node.GetModule().Name)
+
+	gosmi.SetCollisionPolicy(gosmi.CollisionQualify)
+	_, err = gosmi.GetNode("shared")
+	assert.ErrorIs(t, err, smi.ErrAmbiguousName)
+	node, err = gosmi.GetNodeInModule("COLLIDE-B-MIB", "shared")
+	require.NoError(t, err)
+	assert.Equal(t, "COLLIDE-B-MIB", node.GetModule().Name)
+	node, err = gosmi.GetNodeInModule("COLLIDE-B-MIB", "clash")
+	require.NoError(t, err)
+	assert.Equal(t, "1.6", node.RenderNumeric())
+	typ, err = gosmi.GetTypeInModule("COLLIDE-A-MIB", "Clash")
+	require.NoError(t, err)
+	assert.Equal(t, types.BaseTypeInteger32, typ.BaseType)
+	_, err = gosmi.GetNodeInModule("COLLIDE-A-MIB", "missing")
+	assert.Error(t, err)
+	node, _, err = gosmi.ParseOID("COLLIDE-A-MIB::clash.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", node.RenderNumeric())
+	// The node of the module named, not the first module defining it
+	node, instance, err := gosmi.ParseOID("COLLIDE-B-MIB::shared.0")
+	require.NoError(t, err)
+	assert.Equal(t, "COLLIDE-B-MIB", node.GetModule().Name)
+	assert.Equal(t, types.Oid{0}, instance)
+	_, _, err = gosmi.ParseOID("COLLIDE-A-MIB::1.6.0")
+	assert.Error(t, err)
+}
+
+func TestUnknownMacroInvocation(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"VENDOR-MIB": `VENDOR-MIB DEFINITIONS ::= BEGIN
+vendor OBJECT IDENTIFIER ::= { iso 4 }
+vendorChild OBJECT IDENTIFIER ::= { vendorRouter 1 }
+vendorRouter VENDOR-PRODUCT
+	PRODUCT-NAME "Router 9000"
+	::= { vendor 1 }
+END
+`})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("VENDOR-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("vendorRouter")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.1", node.RenderNumeric())
+	assert.Equal(t, types.DeclUnknown, node.Decl)
+	node, err = gosmi.GetNode("vendorChild")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.1.1", node.RenderNumeric())
+
+	var codes []string
+	for _, d := range gosmi.GetDiagnostics() {
+		codes = append(codes, d.Code)
+	}
+	assert.Contains(t, codes, diag.CodeUnknownMacro)
+	assert.NotContains(t, codes, diag.CodeUnknownOidParent)
+}
+
+func TestDuplicateDefinitions(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TWICE-MIB": `TWICE-MIB DEFINITIONS ::= BEGIN
+twice OBJECT IDENTIFIER ::= { iso 5 }
+Twice ::= INTEGER
+once OBJECT IDENTIFIER ::= { iso 6 }
+twice OBJECT IDENTIFIER ::= { iso 7 }
+Twice ::= OCTET STRING
+END
+`})
+	for _, test := range []struct {
+		policy   gosmi.DuplicatePolicy
+		oid      string
+		baseType types.BaseType
+	}{
+		{gosmi.DuplicateFirst, "1.5", types.BaseTypeInteger32},
+		{gosmi.DuplicateLast, "1.7", types.BaseTypeOctetString},
+	} {
+		gosmi.Init()
+		gosmi.SetDuplicatePolicy(test.policy)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("TWICE-MIB")
+		require.NoError(t, err)
+
+		node, err := gosmi.GetNode("twice")
+		require.NoError(t, err)
+		assert.Equal(t, test.oid, node.RenderNumeric())
+		typ, err := gosmi.GetType("Twice")
+		require.NoError(t, err)
+		assert.Equal(t, test.baseType, typ.BaseType)
+		module, err := gosmi.GetModule("TWICE-MIB")
+		require.NoError(t, err)
+		assert.Len(t, module.GetNodes(), 2)
+
+		var duplicates []diag.Diagnostic
+		for _, d := range gosmi.GetDiagnostics() {
+			if d.Code == diag.CodeDuplicateDefinition {
+				duplicates = append(duplicates, d)
+			}
+		}
+		require.Len(t, duplicates, 2)
+		assert.Equal(t, diag.SeverityWarning, duplicates[0].Severity)
+		assert.Equal(t, 5, duplicates[0].Pos.Line)
+		assert.Equal(t, []diag.Position{{Filename: duplicates[0].Pos.Filename, Offset: duplicates[0].Related[0].Offset, Line: 2, Column: 1}}, duplicates[0].Related)
+		assert.Equal(t, 6, duplicates[1].Pos.Line)
+		assert.Equal(t, 3, duplicates[1].Related[0].Line)
+		gosmi.Exit()
+	}
+}
+
+func TestTextNormalization(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TEXT-MIB": "TEXT-MIB DEFINITIONS ::= BEGIN\n" +
+		"textRoot OBJECT IDENTIFIER ::= { iso 9 }\n" +
+		"textCafe OBJECT-IDENTITY\n" +
+		"    STATUS current\n" +
+		"    DESCRIPTION \"The cafe\u0301,\x07 or \u201cnone\u201d.\"\n" +
+		"    ::= { textRoot 1 }\n" +
+		"END\n"})
+	// Stands in for norm.NFC.String
+	nfc := func(s string) string { return strings.ReplaceAll(s, "e\u0301", "\u00e9") }
+	for _, test := range []struct {
+		normalization gosmi.TextNormalization
+		lazy          bool
+		description   string
+	}{
+		{gosmi.TextNormalization{}, false, "The cafe\u0301,\x07 or \u201cnone\u201d."},
+		{gosmi.TextNormalization{Normalize: nfc, StripControl: true}, false, "The caf\u00e9, or \u201cnone\u201d."},
+		{gosmi.TextNormalization{Normalize: nfc, ASCII: true}, false, "The caf?, or ?none?."},
+		{gosmi.TextNormalization{ASCII: true}, true, "The cafe?, or ?none?."},
+	} {
+		gosmi.Init()
+		gosmi.SetTextNormalization(test.normalization)
+		gosmi.SetLazyText(test.lazy)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("TEXT-MIB")
+		require.NoError(t, err)
+		node, err := gosmi.GetNode("textCafe")
+		require.NoError(t, err)
+		require.NoError(t, node.LoadText())
+		assert.Equal(t, test.description, node.Description)
+		gosmi.SetLazyText(false)
+		gosmi.Exit()
+	}
+}
+
+func TestTextWhitespace(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"SPACE-MIB": "SPACE-MIB DEFINITIONS ::= BEGIN\n" +
+		"spaceRoot OBJECT IDENTIFIER ::= { iso 9 }\n" +
+		"spaced OBJECT-IDENTITY\n" +
+		"    STATUS current\n" +
+		"    DESCRIPTION \"Two  spaces,\n" +
+		"            then\n" +
+		"              indented.\n" +
+		"    \"\n" +
+		"    ::= { spaceRoot 1 }\n" +
+		"END\n"})
+	for _, test := range []struct {
+		whitespace  parser.TextWhitespace
+		lazy        bool
+		description string
+	}{
+		{parser.TextNormalized, false, "Two spaces,\nthen\nindented."},
+		{parser.TextRaw, false, "Two  spaces,\n            then\n              indented.\n    "},
+		{parser.TextLibsmi, false, "Two  spaces,\nthen\n  indented.\n"},
+		{parser.TextLibsmi, true, "Two  spaces,\nthen\n  indented.\n"},
+	} {
+		gosmi.Init()
+		gosmi.SetTextNormalization(gosmi.TextNormalization{Whitespace: test.whitespace})
+		gosmi.SetLazyText(test.lazy)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("SPACE-MIB")
+		require.NoError(t, err)
+		node, err := gosmi.GetNode("spaced")
+		require.NoError(t, err)
+		require.NoError(t, node.LoadText())
+		assert.Equal(t, test.description, node.Description)
+		gosmi.SetLazyText(false)
+		gosmi.Exit()
+	}
+}
+
+func TestWrappedQuoted(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"WRAP-MIB": "WRAP-MIB DEFINITIONS ::= BEGIN\n" +
+		"IMPORTS OBJECT-TYPE FROM SNMPv2-SMI;\n" +
+		"wrapRoot OBJECT IDENTIFIER ::= { iso 9 }\n" +
+		"wrapKey OBJECT-TYPE\n" +
+		"    SYNTAX OCTET STRING\n" +
+		"    MAX-ACCESS read-only\n" +
+		"    STATUS current\n" +
+		"    DESCRIPTION \"The key.\"\n" +
+		"    DEFVAL { '0A0B\n" +
+		"              0C0D'H }\n" +
+		"    ::= { wrapRoot 1 }\n" +
+		"END\n"})
+	for _, wrapped := range []bool{false, true} {
+		gosmi.Init()
+		gosmi.SetWrappedQuoted(wrapped)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("WRAP-MIB")
+		if !wrapped {
+			assert.Error(t, err)
+			gosmi.Exit()
+			continue
+		}
+		require.NoError(t, err)
+		_, err = gosmi.GetNode("wrapKey")
+		assert.NoError(t, err)
+		var codes []string
+		for _, d := range gosmi.GetDiagnostics() {
+			codes = append(codes, d.Code)
+		}
+		assert.Contains(t, codes, diag.CodeWrappedQuoted)
+		gosmi.Exit()
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"RECOVER-MIB": "RECOVER-MIB DEFINITIONS ::= BEGIN\n" +
+		"recoverRoot OBJECT IDENTIFIER ::= { iso 9 }\n" +
+		"recoverBroken OBJECT IDENTIFIER ::= { recoverRoot # 1 }\n" +
+		"recoverKept OBJECT IDENTIFIER ::= { recoverRoot 2 }\n" +
+		"END\n"})
+	for _, recovery := range []bool{false, true} {
+		gosmi.Init()
+		gosmi.SetRecovery(recovery)
+		gosmi.SetPath(dir)
+		_, err := gosmi.LoadModule("RECOVER-MIB")
+		if !recovery {
+			assert.Error(t, err)
+			gosmi.Exit()
+			continue
+		}
+		require.NoError(t, err)
+		node, err := gosmi.GetNode("recoverKept")
+		require.NoError(t, err)
+		assert.Equal(t, "1.9.2", node.RenderNumeric())
+		_, err = gosmi.GetNode("recoverBroken")
+		assert.Error(t, err)
+		var codes []string
+		for _, d := range gosmi.GetDiagnostics() {
+			codes = append(codes, d.Code)
+		}
+		assert.Contains(t, codes, diag.CodeIllegalCharacter)
+		assert.Contains(t, codes, diag.CodeSkippedTokens)
+		gosmi.Exit()
+	}
+}
+
+func TestLazyText(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"NODE-MIB": nodeMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetLazyText(true)
+	defer gosmi.SetLazyText(false)
+	gosmi.SetRetainAST(false)
+	defer gosmi.SetRetainAST(true)
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("NODE-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("nodeTemperature")
+	require.NoError(t, err)
+	assert.Empty(t, node.Description)
+	assert.Empty(t, node.Reference)
+	assert.Equal(t, "0.1 degrees Celsius", node.Units)
+	require.NoError(t, node.LoadText())
+	assert.Equal(t, "The current temperature.", node.Description)
+	assert.Equal(t, "Sensor datasheet, section 4.", node.Reference)
+
+	celsius, err := gosmi.GetType("Celsius")
+	require.NoError(t, err)
+	assert.Empty(t, celsius.Description)
+	require.NoError(t, celsius.LoadText())
+	assert.Equal(t, "A temperature in tenths of a degree.", celsius.Description)
+
+	root, err := gosmi.GetNode("nodeRoot")
+	require.NoError(t, err)
+	require.NoError(t, root.LoadText())
+	assert.Empty(t, root.Description)
+}
+
+const kindMib = `KIND-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE, Integer32
+        FROM SNMPv2-SMI
+    OBJECT-GROUP, NOTIFICATION-GROUP, MODULE-COMPLIANCE, AGENT-CAPABILITIES
+        FROM SNMPv2-CONF;
+
+kindMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "gosmi"
+    CONTACT-INFO "gosmi"
+    DESCRIPTION "Node kinds."
+    ::= { iso 14 }
+
+kindObjects OBJECT IDENTIFIER ::= { kindMib 1 }
+
+kindScalar OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A scalar."
+    ::= { kindObjects 1 }
+
+kindHidden OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A not-accessible scalar."
+    ::= { kindObjects 2 }
+
+kindStray OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Listed by a SEQUENCE, defined outside of the row."
+    ::= { kindObjects 3 }
+
+kindTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF KindEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { kindObjects 4 }
+
+kindEntry OBJECT-TYPE
+    SYNTAX KindEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { kindIndex }
+    ::= { kindTable 1 }
+
+KindEntry ::= SEQUENCE { kindIndex Integer32, kindStray Integer32 }
+
+kindIndex OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A column."
+    ::= { kindEntry 1 }
+
+kindUnlisted OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A column the SEQUENCE leaves out."
+    ::= { kindEntry 2 }
+
+kindOtherTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF KindOtherEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table indexed by the columns of another."
+    ::= { kindObjects 5 }
+
+kindOtherEntry OBJECT-TYPE
+    SYNTAX KindOtherEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row indexed by another table's column."
+    INDEX { kindIndex, kindOtherValue }
+    ::= { kindOtherTable 1 }
+
+KindOtherEntry ::= SEQUENCE { kindOtherValue Integer32 }
+
+kindOtherValue OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A column."
+    ::= { kindOtherEntry 1 }
+
+kindBareTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF KindBareEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table whose row has no INDEX."
+    ::= { kindObjects 6 }
+
+kindBareEntry OBJECT-TYPE
+    SYNTAX KindBareEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row without INDEX."
+    ::= { kindBareTable 1 }
+
+KindBareEntry ::= SEQUENCE { kindBareValue Integer32 }
+
+kindBareValue OBJECT-TYPE
+    SYNTAX Integer32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A column."
+    ::= { kindBareEntry 1 }
+
+kindEvent NOTIFICATION-TYPE
+    OBJECTS { kindScalar }
+    STATUS current
+    DESCRIPTION "A notification."
+    ::= { kindMib 2 }
+
+kindGroup OBJECT-GROUP
+    OBJECTS { kindScalar }
+    STATUS current
+    DESCRIPTION "An object group."
+    ::= { kindMib 3 }
+
+kindEvents NOTIFICATION-GROUP
+    NOTIFICATIONS { kindEvent }
+    STATUS current
+    DESCRIPTION "A notification group."
+    ::= { kindMib 4 }
+
+kindCompliance MODULE-COMPLIANCE
+    STATUS current
+    DESCRIPTION "A compliance statement."
+    MODULE
+        MANDATORY-GROUPS { kindGroup }
+    ::= { kindMib 5 }
+
+kindCapabilities AGENT-CAPABILITIES
+    PRODUCT-RELEASE "1.0"
+    STATUS current
+    DESCRIPTION "Agent capabilities."
+    SUPPORTS KIND-MIB
+        INCLUDES { kindGroup }
+    ::= { kindMib 6 }
+END
+`
+
+func TestNodeKinds(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"KIND-MIB": kindMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("KIND-MIB")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		kind types.NodeKind
+	}{
+		{"kindMib", types.NodeNode},
+		{"kindObjects", types.NodeNode},
+		{"kindScalar", types.NodeScalar},
+		{"kindHidden", types.NodeScalar},
+		{"kindStray", types.NodeScalar},
+		{"kindTable", types.NodeTable},
+		{"kindEntry", types.NodeRow},
+		{"kindIndex", types.NodeColumn},
+		{"kindUnlisted", types.NodeColumn},
+		{"kindOtherTable", types.NodeTable},
+		{"kindOtherEntry", types.NodeRow},
+		{"kindOtherValue", types.NodeColumn},
+		{"kindBareTable", types.NodeTable},
+		{"kindBareEntry", types.NodeRow},
+		{"kindBareValue", types.NodeColumn},
+		{"kindEvent", types.NodeNotification},
+		{"kindGroup", types.NodeGroup},
+		{"kindEvents", types.NodeGroup},
+		{"kindCompliance", types.NodeCompliance},
+		{"kindCapabilities", types.NodeCapabilities},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := gosmi.GetNode(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, node.Kind)
+		})
+	}
+}
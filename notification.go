@@ -1,7 +1,11 @@
 package gosmi
 
 import (
+	"fmt"
+
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
 )
 
 type Notification struct {
@@ -27,3 +31,126 @@ func (n SmiNode) GetNotificationObjects() (objects []SmiNode) {
 	}
 	return
 }
+
+// Varbind is a variable binding of a received notification: the OID of an
+// instance and its value, as an SNMP library decodes it.
+type Varbind struct {
+	Oid   types.Oid
+	Value interface{}
+}
+
+// VarbindIssue is a discrepancy between the varbinds of a notification and
+// the OBJECTS of its definition.
+type VarbindIssue struct {
+	Severity diag.Severity `json:"severity"`
+	// Index is the position of the varbind in those validated, or -1 for an
+	// object missing from them.
+	Index   int    `json:"index"`
+	Object  string `json:"object,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i VarbindIssue) String() string {
+	if i.Index < 0 {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: varbind %d: %s", i.Severity, i.Index, i.Message)
+}
+
+var (
+	sysUpTimeInstance   = types.Oid{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	snmpTrapOIDInstance = types.Oid{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}
+)
+
+// ValidateVarbinds checks the varbinds of a received notification against the
+// OBJECTS of the notification with the given OID: every object must have a
+// varbind, in the order of the clause, for an instance of the object, with a
+// valid value of its type. Leading sysUpTime.0 and snmpTrapOID.0 varbinds, as
+// in SNMPv2 traps, are skipped, and the agent may append varbinds of other
+// objects (RFC 3416, section 4.2.6), which are reported as SeverityInfo. An
+// error is returned if no notification has the OID.
+func ValidateVarbinds(oid types.Oid, varbinds []Varbind) (issues []VarbindIssue, err error) {
+	notification, err := GetNodeByOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	if notification.Kind != types.NodeNotification || !notification.Oid.Equals(oid) {
+		return nil, fmt.Errorf("No notification is defined at %s", oid)
+	}
+	report := func(severity diag.Severity, index int, object, format string, args ...interface{}) {
+		issues = append(issues, VarbindIssue{Severity: severity, Index: index, Object: object, Message: fmt.Sprintf(format, args...)})
+	}
+
+	start := 0
+	if start < len(varbinds) && varbinds[start].Oid.Equals(sysUpTimeInstance) {
+		start++
+	}
+	if start < len(varbinds) && varbinds[start].Oid.Equals(snmpTrapOIDInstance) {
+		if trapOid, ok := varbinds[start].Value.(types.Oid); ok && !trapOid.Equals(oid) {
+			report(diag.SeverityError, start, "", "snmpTrapOID.0 is %s, not %s", trapOid, oid)
+		}
+		start++
+	}
+
+	objects := notification.GetNotificationObjects()
+	position := make(map[string]int, len(objects))
+	for i, object := range objects {
+		position[object.GetModule().Name+"::"+object.Name] = i
+	}
+	seen := make([]bool, len(objects))
+	last := -1
+	for i := start; i < len(varbinds); i++ {
+		vb := varbinds[i]
+		node, err := GetNodeByOID(vb.Oid)
+		if err != nil || (node.Kind != types.NodeScalar && node.Kind != types.NodeColumn) || !vb.Oid.ChildOf(node.Oid) {
+			report(diag.SeverityInfo, i, "", "Additional varbind %s of an unknown object", vb.Oid)
+			continue
+		}
+		j, ok := position[node.GetModule().Name+"::"+node.Name]
+		if !ok {
+			report(diag.SeverityInfo, i, node.Name, "Additional varbind of %s", node.Name)
+			continue
+		}
+		if seen[j] {
+			report(diag.SeverityWarning, i, node.Name, "Duplicate varbind of %s", node.Name)
+		} else if j < last {
+			report(diag.SeverityWarning, i, node.Name, "%s is out of order, it comes before %s", node.Name, objects[last].Name)
+		}
+		seen[j] = true
+		if j > last {
+			last = j
+		}
+		if msg := checkInstance(node, vb.Oid[len(node.Oid):]); msg != "" {
+			report(diag.SeverityError, i, node.Name, "%s", msg)
+		}
+		if node.Type != nil {
+			if err := node.Type.CheckValue(vb.Value); err != nil {
+				report(diag.SeverityError, i, node.Name, "Invalid value of %s: %s", node.Name, err)
+			}
+		}
+	}
+	for j, object := range objects {
+		if !seen[j] {
+			report(diag.SeverityError, -1, object.Name, "Missing varbind of %s", object.Name)
+		}
+	}
+	return issues, nil
+}
+
+// checkInstance describes what is wrong with the instance sub-identifiers of a
+// varbind of node, if anything
+func checkInstance(node SmiNode, instance types.Oid) string {
+	switch node.Kind {
+	case types.NodeScalar:
+		if len(instance) != 1 || instance[0] != 0 {
+			return fmt.Sprintf("Instance %s of scalar %s is not 0", instance, node.Name)
+		}
+	case types.NodeColumn:
+		if len(instance) == 0 {
+			return fmt.Sprintf("Varbind of column %s has no instance", node.Name)
+		}
+	default:
+		return fmt.Sprintf("%s is a %s, not an object with instances", node.Name, node.Kind)
+	}
+	return ""
+}
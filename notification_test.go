@@ -0,0 +1,129 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const varbindMib = `VARBIND-MIB DEFINITIONS ::= BEGIN
+vbRoot OBJECT IDENTIFIER ::= { iso 5 }
+
+vbStatus OBJECT-TYPE
+    SYNTAX INTEGER { up(1), down(2) }
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The status."
+    ::= { vbRoot 1 }
+
+vbLevel OBJECT-TYPE
+    SYNTAX INTEGER (0..100)
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The level."
+    ::= { vbRoot 2 }
+
+vbTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF VbEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { vbRoot 3 }
+
+vbEntry OBJECT-TYPE
+    SYNTAX VbEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { vbIndex }
+    ::= { vbTable 1 }
+
+VbEntry ::= SEQUENCE {
+    vbIndex INTEGER,
+    vbName  OCTET STRING
+}
+
+vbIndex OBJECT-TYPE
+    SYNTAX INTEGER (1..10)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The index."
+    ::= { vbEntry 1 }
+
+vbName OBJECT-TYPE
+    SYNTAX OCTET STRING (SIZE (1..8))
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The name."
+    ::= { vbEntry 2 }
+
+vbChange NOTIFICATION-TYPE
+    OBJECTS { vbStatus, vbLevel, vbName }
+    STATUS current
+    DESCRIPTION "Something changed."
+    ::= { vbRoot 4 }
+END
+`
+
+func TestValidateVarbinds(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"VARBIND-MIB": varbindMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("VARBIND-MIB")
+	require.NoError(t, err)
+
+	trap := types.Oid{1, 5, 4}
+	status := types.Oid{1, 5, 1, 0}
+	level := types.Oid{1, 5, 2, 0}
+	name := types.Oid{1, 5, 3, 1, 2, 7}
+
+	issues, err := gosmi.ValidateVarbinds(trap, []gosmi.Varbind{
+		{Oid: types.Oid{1, 3, 6, 1, 2, 1, 1, 3, 0}, Value: uint32(100)},
+		{Oid: types.Oid{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, Value: trap},
+		{Oid: status, Value: 1},
+		{Oid: level, Value: int64(50)},
+		{Oid: name, Value: []byte("eth0")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	issues, err = gosmi.ValidateVarbinds(trap, []gosmi.Varbind{
+		{Oid: level, Value: 101},
+		{Oid: status, Value: 3},
+		{Oid: types.Oid{1, 5, 3, 1, 2}, Value: "too long a name"},
+		{Oid: types.Oid{1, 6, 1}, Value: 1},
+	})
+	require.NoError(t, err)
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		"Error: varbind 0: Invalid value of vbLevel: Value 101 out of range",
+		"Warning: varbind 1: vbStatus is out of order, it comes before vbLevel",
+		"Error: varbind 1: Invalid value of vbStatus: Value 3 is not an enumerated value",
+		"Error: varbind 2: Varbind of column vbName has no instance",
+		"Error: varbind 2: Invalid value of vbName: Length 15 outside of size",
+		"Info: varbind 3: Additional varbind 1.6.1 of an unknown object",
+	}, messages)
+
+	issues, err = gosmi.ValidateVarbinds(trap, []gosmi.Varbind{
+		{Oid: types.Oid{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}, Value: types.Oid{1, 5, 5}},
+		{Oid: types.Oid{1, 5, 1, 1}, Value: "up"},
+	})
+	require.NoError(t, err)
+	require.Len(t, issues, 5)
+	assert.Equal(t, diag.SeverityError, issues[0].Severity)
+	assert.Equal(t, "Instance 1 of scalar vbStatus is not 0", issues[1].Message)
+	assert.Equal(t, "Invalid value of vbStatus: String value, not an integer", issues[2].Message)
+	assert.Equal(t, gosmi.VarbindIssue{Severity: diag.SeverityError, Index: -1, Object: "vbLevel", Message: "Missing varbind of vbLevel"}, issues[3])
+	assert.Equal(t, "vbName", issues[4].Object)
+
+	_, err = gosmi.ValidateVarbinds(status, nil)
+	assert.Error(t, err)
+}
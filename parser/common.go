@@ -3,7 +3,9 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
 	gosmilexer "github.com/lukeod/gosmi/parser/lexer"
 	"github.com/lukeod/gosmi/types"
@@ -18,9 +20,6 @@ type SubIdentifier struct {
 
 func (x *SubIdentifier) Parse(lex *lexer.PeekingLexer) error {
 	peekedToken := lex.Peek()
-	if peekedToken.EOF() {
-		return fmt.Errorf("unexpected EOF at start of SubIdentifier parse")
-	}
 
 	// Get symbols from the refactored lexer definition
 	symbols := (&gosmilexer.LexerDefinition{}).Symbols()
@@ -77,8 +76,9 @@ func (x *SubIdentifier) Parse(lex *lexer.PeekingLexer) error {
 		return nil
 	}
 
-	// If neither Int nor Ident, it's an error
-	return fmt.Errorf("unexpected %q, expected Int or Ident", peekedToken)
+	// Neither Int nor Ident, such as the closing brace ending the OID. This is
+	// how every OID ends, so no error is built for it
+	return participle.NextMatch
 }
 
 type Oid struct {
@@ -87,13 +87,66 @@ type Oid struct {
 	SubIdentifiers []SubIdentifier `parser:"@@+"`
 }
 
+// Defval is the value of a DEFVAL clause: a single Value or Text, the OID value
+// in braces of an OBJECT IDENTIFIER, as in { 1 3 6 1 }, or the named bits in
+// braces of a BITS value, as in { red, blue }. A single name in braces is
+// taken for a BITS value.
+type Defval struct {
+	Pos lexer.Position
+
+	// Value is a number, a binary or hexadecimal string, such as ''H, or a
+	// name, of an enumeration or an OID value.
+	Value string `parser:"  @( Int | Int64 | Uint64 | BinString | HexString | Ident )"`
+	// Text is a quoted string, unquoted.
+	Text *string `parser:"| @Text"`
+	Oid  *Oid    `parser:"| ( \"{\" (?= Int | Ident ( Int | Ident | \"(\" ) ) @@ \"}\" )"`
+	// Bits is set for a BITS value, which Names may be empty for.
+	Bits  bool                  `parser:"| ( @\"{\""`
+	Names []types.SmiIdentifier `parser:"( @Ident ( \",\" @Ident )* \",\"? )? \"}\" )"`
+}
+
+// String renders the value as written in the DEFVAL clause, within its outer
+// braces.
+func (x Defval) String() string {
+	switch {
+	case x.Oid != nil:
+		var b strings.Builder
+		b.WriteString("{")
+		for _, subId := range x.Oid.SubIdentifiers {
+			b.WriteString(" ")
+			switch {
+			case subId.Name != nil && subId.Number != nil:
+				fmt.Fprintf(&b, "%s(%d)", *subId.Name, *subId.Number)
+			case subId.Name != nil:
+				b.WriteString(subId.Name.String())
+			case subId.Number != nil:
+				b.WriteString(strconv.FormatUint(uint64(*subId.Number), 10))
+			}
+		}
+		b.WriteString(" }")
+		return b.String()
+	case x.Text != nil:
+		return `"` + *x.Text + `"`
+	case x.Bits:
+		if len(x.Names) == 0 {
+			return "{ }"
+		}
+		names := make([]string, len(x.Names))
+		for i, name := range x.Names {
+			names[i] = name.String()
+		}
+		return "{ " + strings.Join(names, ", ") + " }"
+	}
+	return x.Value
+}
+
 // Per RFC2578 Appendix A, not all valid ASN.1 refinements are allowed by SMI
 // Specifically, MIN and MAX are not valid range values, nor is '<' permitted on the lower or upper end point
 type Range struct {
 	Pos lexer.Position
 
-	Start string `parser:"@( \"-\"? Int | BinString | HexString | Ident )"`             // Allow Ident (for MIN/MAX)
-	End   string `parser:"( \"..\" @( \"-\"? Int | BinString | HexString | Ident ) )?"` // Allow Ident (for MIN/MAX)
+	Start string `parser:"@( Int | Int64 | Uint64 | BinString | HexString | Ident )"`             // Allow Ident (for MIN/MAX)
+	End   string `parser:"( \"..\" @( Int | Int64 | Uint64 | BinString | HexString | Ident ) )?"` // Allow Ident (for MIN/MAX)
 }
 
 type Status string
@@ -133,7 +186,7 @@ type NamedNumber struct {
 	Pos lexer.Position
 
 	Name  types.SmiIdentifier `parser:"@Ident"`
-	Value string              `parser:"\"(\" @( \"-\"? Int ) \")\""`
+	Value string              `parser:"\"(\" @( Int | Int64 | Uint64 ) \")\""`
 }
 
 type SyntaxType struct {
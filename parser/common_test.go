@@ -296,6 +296,41 @@ func TestSyntaxParsing(t *testing.T) {
 				assert.Equal(t, "10", subType.Integer[0].End)
 			},
 		},
+		{
+			name: "Integer with 64-bit ranges",
+			input: `TEST-MIB DEFINITIONS ::= BEGIN
+					testObj OBJECT-TYPE SYNTAX Integer64 (-9223372036854775808..-2147483649 | 4294967296..18446744073709551615) MAX-ACCESS read-only STATUS current ::= { test 1 }
+					test OBJECT IDENTIFIER ::= { iso 1 }
+					END`,
+			wantErr: false,
+			check: func(t *testing.T, mod *parser.Module) {
+				node := testutil.FindNodeByName(t, mod, "testObj")
+				subType := node.ObjectType.Syntax.Type.SubType
+				require.NotNil(t, subType)
+				require.Len(t, subType.Integer, 2)
+				assert.Equal(t, "-9223372036854775808", subType.Integer[0].Start)
+				assert.Equal(t, "-2147483649", subType.Integer[0].End)
+				assert.Equal(t, "4294967296", subType.Integer[1].Start)
+				assert.Equal(t, "18446744073709551615", subType.Integer[1].End)
+			},
+		},
+		{
+			name: "Integer with minus separated from its number",
+			input: `TEST-MIB DEFINITIONS ::= BEGIN
+					testObj OBJECT-TYPE SYNTAX Integer32 ( - 5 | -3 .. - 1 ) MAX-ACCESS read-only STATUS current ::= { test 1 }
+					test OBJECT IDENTIFIER ::= { iso 1 }
+					END`,
+			wantErr: false,
+			check: func(t *testing.T, mod *parser.Module) {
+				node := testutil.FindNodeByName(t, mod, "testObj")
+				subType := node.ObjectType.Syntax.Type.SubType
+				require.NotNil(t, subType)
+				require.Len(t, subType.Integer, 2)
+				assert.Equal(t, "-5", subType.Integer[0].Start)
+				assert.Equal(t, "-3", subType.Integer[1].Start)
+				assert.Equal(t, "-1", subType.Integer[1].End)
+			},
+		},
 		{
 			name: "Integer range invalid order", // Syntactically valid, semantically invalid
 			input: `TEST-MIB DEFINITIONS ::= BEGIN
@@ -604,3 +639,64 @@ func TestSyntaxParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestDefvalParsing(t *testing.T) {
+	defval := func(t *testing.T, syntax, value string) *parser.Defval {
+		mod, err := parser.ParseBytes("test.mib", []byte(`TEST-MIB DEFINITIONS ::= BEGIN
+testObj OBJECT-TYPE
+    SYNTAX `+syntax+`
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "A test object."
+    DEFVAL { `+value+` }
+    ::= { iso 1 }
+END`))
+		require.NoError(t, err)
+		node := testutil.FindNodeByName(t, mod, "testObj")
+		require.NotNil(t, node.ObjectType.Defval)
+		return node.ObjectType.Defval
+	}
+
+	d := defval(t, "OBJECT IDENTIFIER", "{ 1 3 6 1 }")
+	require.NotNil(t, d.Oid)
+	require.Len(t, d.Oid.SubIdentifiers, 4)
+	assert.Equal(t, types.SmiSubId(6), *d.Oid.SubIdentifiers[2].Number)
+	assert.False(t, d.Bits)
+	assert.Equal(t, "{ 1 3 6 1 }", d.String())
+
+	d = defval(t, "OBJECT IDENTIFIER", "{ iso org(3) 6 }")
+	require.NotNil(t, d.Oid)
+	assert.Equal(t, "{ iso org(3) 6 }", d.String())
+
+	d = defval(t, "BITS { red(0), green(1), blue(2) }", "{ red, blue }")
+	assert.Nil(t, d.Oid)
+	assert.True(t, d.Bits)
+	assert.Equal(t, []types.SmiIdentifier{"red", "blue"}, d.Names)
+	assert.Equal(t, "{ red, blue }", d.String())
+
+	d = defval(t, "BITS { red(0) }", "{ red }")
+	assert.True(t, d.Bits)
+	assert.Equal(t, []types.SmiIdentifier{"red"}, d.Names)
+
+	d = defval(t, "BITS { red(0) }", "{ }")
+	assert.True(t, d.Bits)
+	assert.Empty(t, d.Names)
+	assert.Equal(t, "{ }", d.String())
+
+	d = defval(t, "OCTET STRING", "''H")
+	assert.False(t, d.Bits)
+	assert.Equal(t, "''H", d.Value)
+	b, ok := parser.ObjectType{Defval: d}.DefvalBytes()
+	assert.True(t, ok)
+	assert.Empty(t, b)
+
+	d = defval(t, "OBJECT IDENTIFIER", "zeroDotZero")
+	assert.Equal(t, "zeroDotZero", d.Value)
+	assert.Nil(t, d.Oid)
+
+	d = defval(t, "DisplayString", `"default"`)
+	require.NotNil(t, d.Text)
+	assert.Equal(t, "default", *d.Text)
+	assert.Empty(t, d.Value)
+	assert.Equal(t, `"default"`, d.String())
+}
@@ -17,7 +17,7 @@ type AgentCapabilityVariation struct {
 	WriteSyntax *Syntax               `parser:"( \"WRITE-SYNTAX\" @@ )?"`
 	Access      *Access               `parser:"( \"ACCESS\" @( \"write-only\" | \"not-implemented\" | \"accessible-for-notify\" | \"read-only\" | \"read-write\" | \"read-create\" ) )?"`
 	Creation    []types.SmiIdentifier `parser:"( \"CREATION-REQUIRES\" \"{\" @Ident ( \",\" @Ident )* \"}\" )?"`
-	Defval      *string               `parser:"( \"DEFVAL\" \"{\" @( \"-\"? Int | BinString | HexString | Text | Ident | ( \"{\" ( Int+ | ( Ident ( \",\" Ident )* \",\"? )? ) \"}\" ) ) \"}\" )?"`
+	Defval      *Defval               `parser:"( \"DEFVAL\" \"{\" @@ \"}\" )?"`
 	Description string                `parser:"\"DESCRIPTION\" @Text"` // Required
 }
 
@@ -110,7 +110,7 @@ func TestAgentCapabilitiesParsing(t *testing.T) {
 				require.Len(t, var1.Creation, 1)
 				assert.Equal(t, types.SmiIdentifier("testObject2"), var1.Creation[0])
 				require.NotNil(t, var1.Defval)
-				assert.Equal(t, "50", *var1.Defval)
+				assert.Equal(t, "50", var1.Defval.Value)
 				assert.Contains(t, var1.Description, "Variation for testObject1")
 
 				var2 := mod1.Variations[1]
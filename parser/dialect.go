@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	gosmilexer "github.com/lukeod/gosmi/parser/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Dialect describes the clauses a vendor adds to the standard macros, such as
+// an extra clause in OBJECT-TYPE, which the grammar fails on. Modules parsed
+// with the dialect, see WithDialect, have these clauses taken out before
+// parsing and kept as Extensions of their definitions.
+type Dialect struct {
+	Name string
+	// Clauses maps a macro, such as OBJECT-TYPE, to the keywords of the
+	// clauses the dialect adds to it. The value of a clause runs up to the
+	// next clause of the macro or its "::=".
+	Clauses map[string][]string
+}
+
+// Extension is a clause of a dialect, as written in the module.
+type Extension struct {
+	Pos lexer.Position
+	// Dialect is the name of the dialect defining the clause.
+	Dialect string
+	Name    string
+	// Value is the source of the value of the clause, comments within it
+	// included, which is empty for a clause without one.
+	Value string
+}
+
+var dialects = struct {
+	sync.RWMutex
+	m map[string]*Dialect
+}{m: make(map[string]*Dialect)}
+
+// RegisterDialect makes the dialect available to WithDialect under its name.
+// The clauses of a dialect must be upper-case keywords of macros the grammar
+// knows, which are not standard clauses of the macro.
+func RegisterDialect(d Dialect) error {
+	if d.Name == "" {
+		return errors.New("Dialect has no name")
+	}
+	clauses := make(map[string][]string, len(d.Clauses))
+	for macro, keywords := range d.Clauses {
+		standard, ok := macroClauses[macro]
+		if !ok {
+			return fmt.Errorf("Dialect %s extends unknown macro %s", d.Name, macro)
+		}
+		for _, keyword := range keywords {
+			if !isClauseKeyword(keyword) {
+				return fmt.Errorf("Dialect %s clause %q of %s is not an upper-case keyword", d.Name, keyword, macro)
+			}
+			if standard[keyword] {
+				return fmt.Errorf("Dialect %s clause %s is a standard clause of %s", d.Name, keyword, macro)
+			}
+		}
+		clauses[macro] = append([]string(nil), keywords...)
+	}
+	d.Clauses = clauses
+
+	dialects.Lock()
+	defer dialects.Unlock()
+	if _, ok := dialects.m[d.Name]; ok {
+		return fmt.Errorf("Dialect %s already registered", d.Name)
+	}
+	dialects.m[d.Name] = &d
+	return nil
+}
+
+// Dialects returns the names of the registered dialects, sorted.
+func Dialects() []string {
+	dialects.RLock()
+	defer dialects.RUnlock()
+	names := make([]string, 0, len(dialects.m))
+	for name := range dialects.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithDialect parses modules with the registered dialects of the given names,
+// capturing their clauses as Extensions of the definitions they appear in.
+// Parsing fails if a dialect is not registered.
+func WithDialect(names ...string) Option {
+	return func(cfg *parseConfig) { cfg.dialects = append(cfg.dialects, names...) }
+}
+
+// lookupDialects returns the clauses of the named dialects, by macro and
+// keyword.
+func lookupDialects(names []string) (map[string]map[string]string, error) {
+	dialects.RLock()
+	defer dialects.RUnlock()
+	clauses := make(map[string]map[string]string)
+	for _, name := range names {
+		d, ok := dialects.m[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown dialect %q", name)
+		}
+		for macro, keywords := range d.Clauses {
+			if clauses[macro] == nil {
+				clauses[macro] = make(map[string]string)
+			}
+			for _, keyword := range keywords {
+				clauses[macro][keyword] = d.Name
+			}
+		}
+	}
+	return clauses, nil
+}
+
+// macroClauses are the keywords of the standard clauses of the macros the
+// grammar knows.
+var macroClauses = map[string]map[string]bool{
+	"MODULE-IDENTITY":    keywordSet("LAST-UPDATED", "ORGANIZATION", "CONTACT-INFO", "DESCRIPTION", "REVISION"),
+	"OBJECT-IDENTITY":    keywordSet("STATUS", "DESCRIPTION", "REFERENCE"),
+	"OBJECT-TYPE":        keywordSet("SYNTAX", "UNITS", "MAX-ACCESS", "ACCESS", "STATUS", "DESCRIPTION", "REFERENCE", "INDEX", "AUGMENTS", "DEFVAL"),
+	"NOTIFICATION-TYPE":  keywordSet("OBJECTS", "STATUS", "DESCRIPTION", "REFERENCE"),
+	"TRAP-TYPE":          keywordSet("ENTERPRISE", "VARIABLES", "DESCRIPTION", "REFERENCE"),
+	"TEXTUAL-CONVENTION": keywordSet("DISPLAY-HINT", "STATUS", "DESCRIPTION", "REFERENCE", "SYNTAX"),
+	"OBJECT-GROUP":       keywordSet("OBJECTS", "STATUS", "DESCRIPTION", "REFERENCE"),
+	"NOTIFICATION-GROUP": keywordSet("NOTIFICATIONS", "STATUS", "DESCRIPTION", "REFERENCE"),
+	"MODULE-COMPLIANCE":  keywordSet("STATUS", "DESCRIPTION", "REFERENCE", "MODULE", "MANDATORY-GROUPS", "GROUP", "OBJECT", "SYNTAX", "WRITE-SYNTAX", "MIN-ACCESS"),
+	"AGENT-CAPABILITIES": keywordSet("PRODUCT-RELEASE", "STATUS", "DESCRIPTION", "REFERENCE", "SUPPORTS", "INCLUDES", "VARIATION", "SYNTAX", "WRITE-SYNTAX", "ACCESS", "CREATION-REQUIRES", "DEFVAL"),
+}
+
+func keywordSet(keywords ...string) map[string]bool {
+	set := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		set[keyword] = true
+	}
+	return set
+}
+
+func isClauseKeyword(s string) bool {
+	if len(s) < 2 || s[0] < 'A' || s[0] > 'Z' || s[len(s)-1] == '-' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// dialectLexer passes on the tokens of a lexer but for the clauses of its
+// dialects, which it collects by the offset of the definition they are in.
+type dialectLexer struct {
+	lex     *gosmilexer.Lexer
+	src     string
+	clauses map[string]map[string]string
+
+	pending    *lexer.Token
+	prev       [2]lexer.Token
+	macro      string
+	owner      int // offset of the definition the macro is invoked by
+	depth      int
+	extensions map[int][]Extension
+}
+
+func (l *dialectLexer) Next() (lexer.Token, error) {
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return tok, err
+		}
+		typ := token.TokenType(tok.Type)
+		switch {
+		case typ == token.Ident && macroClauses[tok.Value] != nil:
+			// The macro is invoked by name MACRO, or Name ::= MACRO
+			l.macro, l.owner, l.depth = tok.Value, l.prev[1].Pos.Offset, 0
+			if token.TokenType(l.prev[1].Type) == token.Assign {
+				l.owner = l.prev[0].Pos.Offset
+			}
+		case typ == token.Assign && l.depth == 0:
+			l.macro = ""
+		case typ == token.LBrace || typ == token.LPAREN:
+			l.depth++
+		case typ == token.RBrace || typ == token.RPAREN:
+			l.depth--
+		case typ == token.Ident && l.depth == 0 && l.clauses[l.macro][tok.Value] != "":
+			if err := l.extension(tok); err != nil {
+				return lexer.Token{}, err
+			}
+			continue
+		}
+		l.prev[0], l.prev[1] = l.prev[1], tok
+		return tok, nil
+	}
+}
+
+func (l *dialectLexer) next() (lexer.Token, error) {
+	if l.pending != nil {
+		tok := *l.pending
+		l.pending = nil
+		return tok, nil
+	}
+	return l.lex.Next()
+}
+
+// extension collects the clause starting with keyword, up to the next clause
+// of the macro or the end of the macro, which is left pending.
+func (l *dialectLexer) extension(keyword lexer.Token) error {
+	start, end := l.lex.Offset(), l.lex.Offset()
+	depth := 0
+	for {
+		tok, err := l.lex.Next()
+		if err != nil {
+			return err
+		}
+		typ := token.TokenType(tok.Type)
+		if depth == 0 && (tok.EOF() || typ == token.Assign ||
+			typ == token.Ident && (macroClauses[l.macro][tok.Value] || l.clauses[l.macro][tok.Value] != "" || macroClauses[tok.Value] != nil)) {
+			l.pending = &tok
+			break
+		}
+		switch typ {
+		case token.LBrace, token.LPAREN:
+			depth++
+		case token.RBrace, token.RPAREN:
+			depth--
+		}
+		if end == start {
+			start = tok.Pos.Offset
+		}
+		end = l.lex.Offset()
+	}
+	if l.extensions == nil {
+		l.extensions = make(map[int][]Extension)
+	}
+	l.extensions[l.owner] = append(l.extensions[l.owner], Extension{
+		Pos:     keyword.Pos,
+		Dialect: l.clauses[l.macro][keyword.Value],
+		Name:    keyword.Value,
+		Value:   l.src[start:end],
+	})
+	return nil
+}
+
+// attach adds the extensions collected to the definitions of module.
+func (l *dialectLexer) attach(module *Module) {
+	if len(l.extensions) == 0 {
+		return
+	}
+	if identity := module.Body.Identity; identity != nil {
+		identity.Extensions = l.extensions[identity.Pos.Offset]
+	}
+	for i := range module.Body.Types {
+		module.Body.Types[i].Extensions = l.extensions[module.Body.Types[i].Pos.Offset]
+	}
+	for i := range module.Body.Nodes {
+		module.Body.Nodes[i].Extensions = l.extensions[module.Body.Nodes[i].Pos.Offset]
+	}
+}
+
+// ExtensionsOf returns the extensions of the definition of name in the module,
+// nil if it has none.
+func (x *Module) ExtensionsOf(name types.SmiIdentifier) []Extension {
+	if identity := x.Body.Identity; identity != nil && identity.Name == name {
+		return identity.Extensions
+	}
+	for _, t := range x.Body.Types {
+		if t.Name == name {
+			return t.Extensions
+		}
+	}
+	for _, n := range x.Body.Nodes {
+		if n.Name == name {
+			return n.Extensions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dialectInput = `DIALECT-MIB DEFINITIONS ::= BEGIN
+
+dialect MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "example@example.com"
+    DESCRIPTION  "The module."
+    X-TEST-RELEASE "1.2"
+    ::= { iso 1 }
+
+TestString ::= TEXTUAL-CONVENTION
+    STATUS      current
+    DESCRIPTION "A string."
+    X-TEST-FLAG
+    SYNTAX      OCTET STRING (SIZE (0..8))
+
+testObject OBJECT-TYPE
+    SYNTAX      INTEGER { up(1), down(2) }
+    MAX-ACCESS  read-only
+    X-TEST-FLAG
+    STATUS      current
+    DESCRIPTION "An object."
+    X-TEST-INFO { "ro", -- kept
+                  3 } -- dropped
+    ::= { dialect 1 }
+
+testOther OBJECT-TYPE
+    SYNTAX      TestString
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Another object."
+    ::= { dialect 2 }
+
+END
+`
+
+func init() {
+	err := parser.RegisterDialect(parser.Dialect{
+		Name: "test",
+		Clauses: map[string][]string{
+			"MODULE-IDENTITY":    {"X-TEST-RELEASE"},
+			"TEXTUAL-CONVENTION": {"X-TEST-FLAG"},
+			"OBJECT-TYPE":        {"X-TEST-FLAG", "X-TEST-INFO"},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	_, err := parser.ParseBytes("dialect.mib", []byte(dialectInput))
+	require.Error(t, err, "unknown clauses fail without the dialect")
+
+	module, err := parser.ParseBytes("dialect.mib", []byte(dialectInput), parser.WithDialect("test"))
+	require.NoError(t, err)
+
+	extensions := module.ExtensionsOf("dialect")
+	require.Len(t, extensions, 1)
+	assert.Equal(t, "test", extensions[0].Dialect)
+	assert.Equal(t, "X-TEST-RELEASE", extensions[0].Name)
+	assert.Equal(t, `"1.2"`, extensions[0].Value)
+	assert.Equal(t, 8, extensions[0].Pos.Line)
+
+	extensions = module.ExtensionsOf("TestString")
+	require.Len(t, extensions, 1)
+	assert.Equal(t, "X-TEST-FLAG", extensions[0].Name)
+	assert.Empty(t, extensions[0].Value)
+	require.Len(t, module.Body.Types, 1)
+	require.NotNil(t, module.Body.Types[0].TextualConvention)
+	assert.Equal(t, "A string.", module.Body.Types[0].TextualConvention.Description)
+
+	extensions = module.ExtensionsOf("testObject")
+	require.Len(t, extensions, 2)
+	assert.Equal(t, "X-TEST-FLAG", extensions[0].Name)
+	assert.Empty(t, extensions[0].Value)
+	assert.Equal(t, "X-TEST-INFO", extensions[1].Name)
+	assert.Equal(t, "{ \"ro\", -- kept\n                  3 }", extensions[1].Value)
+	assert.Equal(t, 23, extensions[1].Pos.Line)
+
+	assert.Empty(t, module.ExtensionsOf("testOther"))
+	require.Len(t, module.Body.Nodes, 2)
+	assert.Equal(t, "An object.", module.Body.Nodes[0].ObjectType.Description)
+}
+
+func TestParseUnknownDialect(t *testing.T) {
+	_, err := parser.ParseBytes("dialect.mib", []byte(dialectInput), parser.WithDialect("missing"))
+	assert.EqualError(t, err, `Unknown dialect "missing"`)
+}
+
+func TestRegisterDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect parser.Dialect
+		err     string
+	}{
+		{name: "No name", dialect: parser.Dialect{}, err: "Dialect has no name"},
+		{name: "Unknown macro", dialect: parser.Dialect{Name: "bad", Clauses: map[string][]string{"OBJECT-THING": {"X-FLAG"}}}, err: "Dialect bad extends unknown macro OBJECT-THING"},
+		{name: "Lower-case keyword", dialect: parser.Dialect{Name: "bad", Clauses: map[string][]string{"OBJECT-TYPE": {"x-flag"}}}, err: `Dialect bad clause "x-flag" of OBJECT-TYPE is not an upper-case keyword`},
+		{name: "Standard clause", dialect: parser.Dialect{Name: "bad", Clauses: map[string][]string{"OBJECT-TYPE": {"UNITS"}}}, err: "Dialect bad clause UNITS is a standard clause of OBJECT-TYPE"},
+		{name: "Registered", dialect: parser.Dialect{Name: "test"}, err: "Dialect test already registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, parser.RegisterDialect(tt.dialect), tt.err)
+		})
+	}
+	assert.Contains(t, parser.Dialects(), "test")
+	assert.NotContains(t, parser.Dialects(), "bad")
+}
@@ -0,0 +1,76 @@
+package parser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+)
+
+const fuzzSeedMib = `FUZZ-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Integer32 FROM SNMPv2-SMI
+    TEXTUAL-CONVENTION FROM SNMPv2-TC;
+
+fuzzMib MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Org"
+    CONTACT-INFO "Contact"
+    DESCRIPTION "Module."
+    REVISION "202401010000Z"
+    DESCRIPTION "Initial."
+    ::= { enterprises 1 }
+
+Level ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "d"
+    STATUS current
+    DESCRIPTION "A level."
+    SYNTAX INTEGER { low(1), high(2) }
+
+Tag ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+
+fuzzTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF FuzzEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { fuzzMib 1 }
+
+fuzzEntry OBJECT-TYPE
+    SYNTAX FuzzEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { IMPLIED fuzzIndex }
+    ::= { fuzzTable 1 }
+
+FuzzEntry ::= SEQUENCE { fuzzIndex OCTET STRING, fuzzLevel Level }
+
+fuzzLevel OBJECT-TYPE
+    SYNTAX Level
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "The level."
+    DEFVAL { high }
+    ::= { fuzzEntry 2 }
+END
+`
+
+func FuzzParse(f *testing.F) {
+	f.Add(fuzzSeedMib)
+	f.Add("X DEFINITIONS ::= BEGIN END")
+	f.Add("X DEFINITIONS ::= BEGIN x MACRO ::= BEGIN TYPE NOTATION ::= \"x\" END END")
+	f.Add("X DEFINITIONS ::= BEGIN t TRAP-TYPE ENTERPRISE e VARIABLES { a } ::= 1 END")
+	f.Fuzz(func(t *testing.T, input string) {
+		var diagnostics diag.Collector
+		_, err := parser.ParseBytes("fuzz.mib", []byte(input), parser.WithDiagnostics(&diagnostics))
+		var perr *diag.PanicError
+		if errors.As(err, &perr) {
+			t.Fatalf("%v\n%s", perr, perr.Stack)
+		}
+		if _, err := parser.ParseBytes("fuzz.mib", []byte(input)); errors.As(err, &perr) {
+			t.Fatalf("%v\n%s", perr, perr.Stack)
+		}
+	})
+}
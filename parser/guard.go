@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+)
+
+// DefinitionGuard is tried before each definition of a module body. It never
+// matches, but fails the parse once the parse's context is done or the module
+// has more definitions than MaxNodes allows, so that neither waits for the
+// whole module to be parsed. It holds no data.
+type DefinitionGuard struct{}
+
+// guardState is the state of a parse in progress that its guards check.
+type guardState struct {
+	ctx         context.Context
+	filename    string
+	maxNodes    int
+	definitions int
+	// err is the error the parse was failed with, which the parser may
+	// report as a deeper syntax error instead
+	err error
+}
+
+// guards are the states of the parses in progress, keyed by their first
+// token, which all copies the parser makes of a PeekingLexer share.
+var guards sync.Map
+
+func guardKey(lex *lexer.PeekingLexer) *lexer.Token {
+	tokens := lex.Range(0, 1)
+	if len(tokens) == 0 {
+		return nil
+	}
+	return &tokens[0]
+}
+
+// guard registers the state the guards of the parse of lex check, returning
+// the function to unregister it.
+func guard(lex *lexer.PeekingLexer, state *guardState) func() {
+	key := guardKey(lex)
+	if key == nil {
+		return func() {}
+	}
+	guards.Store(key, state)
+	return func() { guards.Delete(key) }
+}
+
+func (*DefinitionGuard) Parse(lex *lexer.PeekingLexer) error {
+	v, ok := guards.Load(guardKey(lex))
+	if !ok {
+		return participle.NextMatch
+	}
+	state := v.(*guardState)
+	next := lex.Peek()
+	if next.EOF() || next.Value == "END" {
+		return participle.NextMatch
+	}
+	if state.ctx != nil {
+		if err := state.ctx.Err(); err != nil {
+			return state.fail(lex, err)
+		}
+	}
+	if state.definitions++; state.maxNodes > 0 && state.definitions > state.maxNodes {
+		pos := next.Pos
+		return state.fail(lex, &diag.LimitExceededError{
+			Pos:   diag.Position{Filename: state.filename, Offset: pos.Offset, Line: pos.Line, Column: pos.Column},
+			Limit: "nodes",
+			Max:   state.maxNodes,
+		})
+	}
+	return participle.NextMatch
+}
+
+// fail fails the parse with err. The parser goes on to try the definitions
+// after a guard failing in place, as after any other alternative that does
+// not match, so the guard first skips more tokens than the parser looks
+// ahead, which makes it give up on the module.
+func (state *guardState) fail(lex *lexer.PeekingLexer, err error) error {
+	state.err = err
+	for i := 0; i <= lookahead; i++ {
+		lex.Next()
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/alecthomas/participle/v2/lexer"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// baseModules are the modules defining the macros and types of each language.
+var baseModules = map[types.SmiIdentifier]types.Language{
+	"RFC1065-SMI":     types.LanguageSMIv1,
+	"RFC1155-SMI":     types.LanguageSMIv1,
+	"RFC-1212":        types.LanguageSMIv1,
+	"RFC-1215":        types.LanguageSMIv1,
+	"SNMPv2-SMI":      types.LanguageSMIv2,
+	"SNMPv2-TC":       types.LanguageSMIv2,
+	"SNMPv2-CONF":     types.LanguageSMIv2,
+	"COPS-PR-SPPI":    types.LanguageSPPI,
+	"COPS-PR-SPPI-TC": types.LanguageSPPI,
+}
+
+// Construct is a use of a macro or clause that only one of SMIv1 and SMIv2
+// allows.
+type Construct struct {
+	Pos lexer.Position
+	// Name is the construct as written, such as TRAP-TYPE or STATUS mandatory.
+	Name     string
+	Language types.Language
+}
+
+// Constructs returns the SMIv1 and SMIv2 specific constructs the module uses,
+// in order of appearance:
+//
+//   - SMIv1: TRAP-TYPE, ACCESS, ACCESS write-only and STATUS mandatory or
+//     optional of OBJECT-TYPE
+//   - SMIv2: MODULE-IDENTITY, TEXTUAL-CONVENTION, OBJECT-IDENTITY,
+//     OBJECT-GROUP, NOTIFICATION-TYPE, NOTIFICATION-GROUP, MODULE-COMPLIANCE,
+//     AGENT-CAPABILITIES, and MAX-ACCESS, read-create, accessible-for-notify
+//     and STATUS current of OBJECT-TYPE
+func (m *Module) Constructs() []Construct {
+	var out []Construct
+	add := func(pos lexer.Position, name string, language types.Language) {
+		out = append(out, Construct{Pos: pos, Name: name, Language: language})
+	}
+	if m.Body.Identity != nil {
+		add(m.Body.Identity.Pos, "MODULE-IDENTITY", types.LanguageSMIv2)
+	}
+	for _, t := range m.Body.Types {
+		if t.TextualConvention != nil {
+			add(t.Pos, "TEXTUAL-CONVENTION", types.LanguageSMIv2)
+		}
+	}
+	for _, n := range m.Body.Nodes {
+		switch {
+		case n.ObjectIdentity != nil:
+			add(n.Pos, "OBJECT-IDENTITY", types.LanguageSMIv2)
+		case n.ObjectGroup != nil:
+			add(n.Pos, "OBJECT-GROUP", types.LanguageSMIv2)
+		case n.NotificationType != nil:
+			add(n.Pos, "NOTIFICATION-TYPE", types.LanguageSMIv2)
+		case n.NotificationGroup != nil:
+			add(n.Pos, "NOTIFICATION-GROUP", types.LanguageSMIv2)
+		case n.ModuleCompliance != nil:
+			add(n.Pos, "MODULE-COMPLIANCE", types.LanguageSMIv2)
+		case n.AgentCapabilities != nil:
+			add(n.Pos, "AGENT-CAPABILITIES", types.LanguageSMIv2)
+		case n.TrapType != nil:
+			add(n.Pos, "TRAP-TYPE", types.LanguageSMIv1)
+		case n.ObjectType != nil:
+			o := n.ObjectType
+			if o.MaxAccess {
+				add(n.Pos, "MAX-ACCESS", types.LanguageSMIv2)
+			} else {
+				add(n.Pos, "ACCESS", types.LanguageSMIv1)
+			}
+			switch o.Access {
+			case AccessWriteOnly:
+				add(n.Pos, "ACCESS write-only", types.LanguageSMIv1)
+			case AccessReadCreate, AccessAccessibleForNotify:
+				add(n.Pos, "MAX-ACCESS "+string(o.Access), types.LanguageSMIv2)
+			}
+			switch o.Status {
+			case StatusMandatory, StatusOptional:
+				add(n.Pos, "STATUS "+string(o.Status), types.LanguageSMIv1)
+			case StatusCurrent:
+				add(n.Pos, "STATUS current", types.LanguageSMIv2)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Pos.Offset < out[j].Pos.Offset })
+	return out
+}
+
+// Language determines the language the module is written in. The first of
+// these rules to apply decides:
+//
+//  1. The base modules of each language, such as RFC1155-SMI, SNMPv2-SMI or
+//     COPS-PR-SPPI, are of that language.
+//  2. A module importing from COPS-PR-SPPI or COPS-PR-SPPI-TC is SPPI.
+//  3. A module with a MODULE-IDENTITY, or importing from SNMPv2-SMI,
+//     SNMPv2-TC or SNMPv2-CONF, is SMIv2.
+//  4. A module importing from RFC1155-SMI, RFC1065-SMI, RFC-1212 or RFC-1215
+//     is SMIv1.
+//  5. A module using any SMIv2 construct is SMIv2, one using only SMIv1
+//     constructs is SMIv1. See Constructs.
+//
+// Modules none of them apply to, such as those only assigning OBJECT
+// IDENTIFIER values, are LanguageUnknown.
+func (m *Module) Language() types.Language {
+	if language, ok := baseModules[m.Name]; ok {
+		return language
+	}
+	imported := make(map[types.Language]bool)
+	for _, i := range m.Body.Imports {
+		imported[baseModules[i.Module]] = true
+	}
+	switch {
+	case imported[types.LanguageSPPI]:
+		return types.LanguageSPPI
+	case m.Body.Identity != nil || imported[types.LanguageSMIv2]:
+		return types.LanguageSMIv2
+	case imported[types.LanguageSMIv1]:
+		return types.LanguageSMIv1
+	}
+	language := types.LanguageUnknown
+	for _, c := range m.Constructs() {
+		if c.Language == types.LanguageSMIv2 {
+			return types.LanguageSMIv2
+		}
+		language = c.Language
+	}
+	return language
+}
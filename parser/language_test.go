@@ -0,0 +1,88 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		module string
+		want   types.Language
+	}{
+		{"BaseModule", `RFC1155-SMI DEFINITIONS ::= BEGIN
+internet OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 }
+END`, types.LanguageSMIv1},
+		{"SPPIImport", `SPPI-MIB DEFINITIONS ::= BEGIN
+IMPORTS MODULE-IDENTITY, pib FROM COPS-PR-SPPI;
+sppi MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION ""
+    CONTACT-INFO ""
+    DESCRIPTION ""
+    ::= { pib 1 }
+END`, types.LanguageSPPI},
+		{"SMIv2Import", `V2-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM SNMPv2-SMI;
+v2 OBJECT IDENTIFIER ::= { enterprises 1 }
+END`, types.LanguageSMIv2},
+		{"SMIv1Import", `V1-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM RFC1155-SMI;
+v1 OBJECT IDENTIFIER ::= { enterprises 1 }
+END`, types.LanguageSMIv1},
+		{"SMIv1Constructs", `V1-MIB DEFINITIONS ::= BEGIN
+v1Object OBJECT-TYPE
+    SYNTAX INTEGER
+    ACCESS read-only
+    STATUS mandatory
+    ::= { v1 1 }
+END`, types.LanguageSMIv1},
+		{"MixedConstructs", `MIXED-MIB DEFINITIONS ::= BEGIN
+mixedObject OBJECT-TYPE
+    SYNTAX INTEGER
+    ACCESS read-only
+    STATUS current
+    ::= { mixed 1 }
+END`, types.LanguageSMIv2},
+		{"NoConstructs", `OIDS-MIB DEFINITIONS ::= BEGIN
+oids OBJECT IDENTIFIER ::= { iso 1 }
+END`, types.LanguageUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, err := parser.ParseBytes(tt.name, []byte(tt.module))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, module.Language())
+		})
+	}
+}
+
+func TestModuleConstructs(t *testing.T) {
+	module, err := parser.ParseBytes("V1-MIB", []byte(`V1-MIB DEFINITIONS ::= BEGIN
+v1Trap TRAP-TYPE
+    ENTERPRISE v1
+    ::= 1
+v1Object OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS write-only
+    STATUS optional
+    ::= { v1 1 }
+END`))
+	require.NoError(t, err)
+
+	var names []string
+	for _, c := range module.Constructs() {
+		names = append(names, c.Name+" "+c.Language.String())
+	}
+	assert.Equal(t, []string{
+		"TRAP-TYPE SMIv1",
+		"MAX-ACCESS SMIv2",
+		"ACCESS write-only SMIv1",
+		"STATUS optional SMIv1",
+	}, names)
+}
@@ -0,0 +1,13 @@
+package lexer
+
+import "unsafe"
+
+// bytesToString returns a string sharing memory with b. The caller must
+// ensure b is not modified afterwards. Substrings of the result keep all of
+// b alive.
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
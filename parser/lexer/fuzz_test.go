@@ -0,0 +1,61 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+)
+
+func FuzzLexer(f *testing.F) {
+	for _, seed := range []string{
+		"TEST-MIB DEFINITIONS ::= BEGIN END",
+		"x OBJECT -- comment\n IDENTIFIER ::= { iso 1 }",
+		"SYNTAX INTEGER (-2147483648..2147483647 | 18446744073709551615)",
+		"Tag ::= [ APPLICATION 3 ] IMPLICIT OCTET STRING (SIZE (0..255))",
+		`DESCRIPTION "text with ""quotes"""`,
+		"'0aF'H '0101'B ''H '1'b",
+		"\"unterminated",
+		"[APPLICATION",
+		"-- comment -- ident -",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, canonical := range []bool{false, true} {
+			def := &LexerDefinition{Canonical: canonical, Diagnostics: &diag.Collector{}}
+			lex, err := def.LexString("fuzz.mib", input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := -1
+			// Every token but EOF consumes input, so there are at most as
+			// many tokens as bytes
+			for i := 0; ; i++ {
+				if i > len(input)+1 {
+					t.Fatalf("Lexer does not terminate on %q", input)
+				}
+				tok, err := lex.Next()
+				if err != nil {
+					t.Fatalf("Next: %v", err)
+				}
+				if tok.Pos.Offset < last || tok.Pos.Offset > len(input) {
+					t.Fatalf("Token %v at offset %d after offset %d", tok, tok.Pos.Offset, last)
+				}
+				last = tok.Pos.Offset
+				if tok.Type == lexer.EOF {
+					break
+				}
+			}
+		}
+		l := NewLexer("fuzz.mib", input)
+		l.SetDiagnostics(&diag.Collector{})
+		file, err := l.Tokenize()
+		if err != nil {
+			t.Fatalf("Tokenize: %v", err)
+		}
+		if replayed := string(file.Bytes()); replayed != input {
+			t.Fatalf("Tokens of %q replay as %q", input, replayed)
+		}
+	})
+}
@@ -1,49 +1,74 @@
 package lexer
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"sync" // Added for sync.Once
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser/lexer/token" // Import our token package
-	// "github.com/lukeod/gosmi/pkg/errors" // Assuming an error package exists or will be created
 )
 
 const eof = -1
 
 // Lexer holds the state of the lexer and implements the participle lexer.Lexer interface.
 type Lexer struct {
-	input       string // the string being scanned
-	filename    string // filename for position information
-	start       int    // start position of this item
-	pos         int    // current position in the input
-	width       int    // width of last rune read from input
-	line        int    // 1-based line number
-	column      int    // 1-based column number in bytes
-	startLine   int    // start line of the current token
-	startColumn int    // start column of the current token
-
-	// TODO: Add error collector if needed
-	// collector *errors.DefaultErrorCollector
+	input      string          // the string being scanned, possibly aliasing a caller's []byte
+	filename   string          // filename for position information
+	start      int             // start position of this item
+	pos        int             // current position in the input
+	width      int             // width of last rune read from input
+	lineStarts []int           // offsets at which the lines scanned so far start
+	scanned    int             // offset up to which lineStarts is complete
+	canonical  bool            // emit canonical values for keywords, ExtUTCTime and hex/bin strings
+	lastType   token.TokenType // type of the last emitted token, for context dependent rules
+	lastValue  string          // value of the last emitted token
+	skipText   bool            // emit empty values for the texts of skippedTextClauses
+	whitespace TextWhitespace  // treatment of the whitespace of quoted strings
+	escapes    bool            // interpret backslash escapes in quoted strings
+	wrapped    bool            // allow whitespace and line breaks in hex and bin strings
+
+	diagnostics *diag.Collector // receives lexical errors, printed if nil
+
+	ctx        context.Context // cancels lexing if set
+	maxTokens  int             // limit on the number of tokens, unlimited if 0
+	maxNesting int             // limit on the depth of braces and parentheses, unlimited if 0
+	tokens     int             // number of tokens returned so far
+	nesting    int             // current depth of braces and parentheses
+	err        error           // limit or context error, returned by every call to Next once set
 }
 
+// Number of tokens between checks of the lexer's context
+const contextCheckInterval = 256
+
 // NewLexer creates a new lexer for the given input string and filename.
 func NewLexer(filename, input string) *Lexer {
 	l := &Lexer{
-		input:    input,
-		filename: filename,
-		line:     1,
-		column:   1,
-		// collector: errors.NewErrorCollector(), // Initialize error collector
+		input:      input,
+		filename:   filename,
+		lineStarts: []int{0},
+		lastType:   token.EOF,
 	}
 	return l
 }
 
+// NewLexerBytes creates a new lexer for the given input without copying it.
+// Token values are substrings sharing memory with input, so input must not be
+// modified for as long as the tokens, or anything built from them, are in use.
+// Any one token value held keeps the whole of input from being freed; copy
+// the values that outlive the parse, such as with strings.Clone.
+func NewLexerBytes(filename string, input []byte) *Lexer {
+	return NewLexer(filename, bytesToString(input))
+}
+
 // --- Core Lexing Logic (implements lexer.Lexer) ---
 
 // next returns the next rune in the input.
@@ -55,15 +80,6 @@ func (l *Lexer) next() rune {
 	r, w := utf8.DecodeRuneInString(l.input[l.pos:])
 	l.width = w
 	l.pos += l.width
-
-	// Update position tracking
-	if r == '\n' {
-		l.line++
-		l.column = 1
-	} else {
-		// Handle tabs correctly if needed, otherwise just increment column
-		l.column += w // Assuming simple byte column count for now
-	}
 	return r
 }
 
@@ -77,17 +93,27 @@ func (l *Lexer) peek() rune {
 // backup steps back one rune. Can only be called once per call of next.
 func (l *Lexer) backup() {
 	l.pos -= l.width
-	// Correctly backup line and column
-	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
-	if r == '\n' {
-		l.line--
-		// Column needs to be recalculated based on the previous line's content
-		// This is complex, for now, we might accept slight inaccuracy on backup
-		// or avoid backup across newlines. A simpler approach is often sufficient.
-		// Resetting to 0 or 1 is a common simplification if full accuracy isn't critical.
-		l.column = 0 // Simplified: reset column (needs improvement for full accuracy)
-	} else {
-		l.column -= l.width
+}
+
+// position returns the position of the byte at offset. Lines and columns
+// are derived from the offsets at which lines start, so they are exact no
+// matter how the lexer moved through the input. Columns count bytes.
+func (l *Lexer) position(offset int) lexer.Position {
+	for ; l.scanned < offset; l.scanned++ {
+		if l.input[l.scanned] == '\n' {
+			l.lineStarts = append(l.lineStarts, l.scanned+1)
+		}
+	}
+	// Tokens are mostly emitted in order, so try the last line first
+	line := len(l.lineStarts) - 1
+	if l.lineStarts[line] > offset {
+		line = sort.SearchInts(l.lineStarts, offset+1) - 1
+	}
+	return lexer.Position{
+		Filename: l.filename,
+		Offset:   offset,
+		Line:     line + 1,
+		Column:   offset - l.lineStarts[line] + 1,
 	}
 }
 
@@ -95,27 +121,27 @@ func (l *Lexer) backup() {
 // Use this if using the channel approach for Participle.
 // emitToken creates and returns a standard lexer.Token.
 func (l *Lexer) emitToken(t token.TokenType) lexer.Token {
+	return l.emitValue(t, l.input[l.start:l.pos])
+}
+
+// emitValue is like emitToken, but uses value instead of the consumed input as
+// the token value. Callers pass a substring of l.input wherever possible so
+// that no copy is made.
+func (l *Lexer) emitValue(t token.TokenType, value string) lexer.Token {
 	tok := lexer.Token{
 		Type:  lexer.TokenType(t), // Use our custom TokenType as the value
-		Value: l.input[l.start:l.pos],
-		Pos: lexer.Position{
-			Filename: l.filename, // Use the stored filename
-			Offset:   l.start,
-			Line:     l.startLine,
-			Column:   l.startColumn,
-		},
+		Value: value,
+		Pos:   l.position(l.start),
 	}
+	l.lastType = t
+	l.lastValue = value
 	l.start = l.pos // Move start for the next token
-	l.startLine = l.line
-	l.startColumn = l.column
 	return tok
 }
 
 // ignore skips over the pending input before this point.
 func (l *Lexer) ignore() {
 	l.start = l.pos
-	l.startLine = l.line
-	l.startColumn = l.column
 }
 
 // acceptRun consumes a run of runes from the valid set.
@@ -125,13 +151,49 @@ func (l *Lexer) acceptRun(valid string) {
 	l.backup()
 }
 
-// recordError adds an error to the collector (if implemented).
-func (l *Lexer) recordError(message string) {
-	// Placeholder: Implement error recording logic
-	fmt.Printf("Lexer Error: Line %d, Col %d: %s\n", l.startLine, l.startColumn, message)
-	// Example with collector:
-	// pos := errors.Position{ Line: l.startLine, Column: l.startColumn }
-	// l.collector.AddError(errors.NewErrorWithPosition(errors.LexerError, errors.SeverityError, pos, message))
+// SetDiagnostics makes the lexer report lexical errors to c instead of
+// printing them.
+func (l *Lexer) SetDiagnostics(c *diag.Collector) {
+	l.diagnostics = c
+}
+
+// Offset returns the offset in the input just after the last token returned
+// by Next, which tells where tokens whose value is not the source as written,
+// such as Text, end.
+func (l *Lexer) Offset() int {
+	return l.pos
+}
+
+// recordError reports an error with the given diag code at the start of the
+// current token.
+func (l *Lexer) recordError(code string, message string) {
+	l.record(diag.SeverityError, code, message)
+}
+
+// recordWarning reports a warning with the given diag code at the start of
+// the current token.
+func (l *Lexer) recordWarning(code string, message string) {
+	l.record(diag.SeverityWarning, code, message)
+}
+
+// record reports a diagnostic, or prints it if there is no collector.
+func (l *Lexer) record(severity diag.Severity, code, message string) {
+	pos := l.position(l.start)
+	if l.diagnostics == nil {
+		fmt.Printf("Lexer %s: Line %d, Col %d: %s\n", severity, pos.Line, pos.Column, message)
+		return
+	}
+	l.diagnostics.Add(diag.Diagnostic{
+		Severity: severity,
+		Pos: diag.Position{
+			Filename: pos.Filename,
+			Offset:   pos.Offset,
+			Line:     pos.Line,
+			Column:   pos.Column,
+		},
+		Code:    code,
+		Message: message,
+	})
 }
 
 // --- State Functions (Example Structure) ---
@@ -150,13 +212,60 @@ func (l *Lexer) recordError(message string) {
 
 // Next returns the next token from the input, implementing the lexer.Lexer interface.
 // Errors are handled by returning an ILLEGAL token. EOF is signaled by a token with Type lexer.EOF.
+// Exceeding the token or nesting limit, returned as a *diag.LimitExceededError,
+// and the end of the context are the only errors, and end lexing.
 func (l *Lexer) Next() (lexer.Token, error) {
+	if l.err != nil {
+		return lexer.Token{}, l.err
+	}
+	if l.ctx != nil && l.tokens%contextCheckInterval == 0 {
+		if l.err = l.ctx.Err(); l.err != nil {
+			return lexer.Token{}, l.err
+		}
+	}
+	tok, err := l.nextToken()
+	if err != nil || tok.EOF() {
+		return tok, err
+	}
+
+	l.tokens++
+	switch token.TokenType(tok.Type) {
+	case token.LBrace, token.LPAREN:
+		l.nesting++
+	case token.RBrace, token.RPAREN:
+		if l.nesting > 0 {
+			l.nesting--
+		}
+	}
+	switch {
+	case l.maxTokens > 0 && l.tokens > l.maxTokens:
+		l.err = l.limitError(tok.Pos, "tokens", l.maxTokens)
+	case l.maxNesting > 0 && l.nesting > l.maxNesting:
+		l.err = l.limitError(tok.Pos, "nesting levels", l.maxNesting)
+	default:
+		return tok, nil
+	}
+	return lexer.Token{}, l.err
+}
+
+func (l *Lexer) limitError(pos lexer.Position, limit string, max int) error {
+	return &diag.LimitExceededError{
+		Pos: diag.Position{
+			Filename: pos.Filename,
+			Offset:   pos.Offset,
+			Line:     pos.Line,
+			Column:   pos.Column,
+		},
+		Limit: limit,
+		Max:   max,
+	}
+}
+
+func (l *Lexer) nextToken() (lexer.Token, error) {
 NextLoop: // Label for the outer loop
 	for {
 		// Set potential start position *before* skipping anything
 		l.start = l.pos
-		l.startLine = l.line
-		l.startColumn = l.column
 
 		r := l.peek() // Peek at the current character
 
@@ -205,11 +314,11 @@ NextLoop: // Label for the outer loop
 					return l.emitToken(token.Assign), nil
 				}
 				// Don't backup. Emit '::' as the illegal token.
-				l.recordError("Expected '=' after '::'")
+				l.recordError(diag.CodeIllegalCharacter, "Expected '=' after '::'")
 				// l.pos is already after '::', l.start is before the first ':'
 				return l.emitToken(token.ILLEGAL), nil // Emits '::'
 			}
-			l.recordError("Illegal single ':'")
+			l.recordError(diag.CodeIllegalCharacter, "Illegal single ':'")
 			return l.emitToken(token.ILLEGAL), nil
 		case r == '.':
 			if l.peek() == '.' {
@@ -233,7 +342,14 @@ NextLoop: // Label for the outer loop
 			return l.emitToken(token.Semicolon), nil
 		case r == '-':
 			// We already handled '--' comments in the skipping logic above.
-			// If we reach here with '-', it must be the Minus token.
+			// A '-' followed by a digit where a value may start is the sign
+			// of a number, even with whitespace in between, as in ( - 5 ).
+			// Anything else is the Minus token.
+			if l.valueMayStart() {
+				if tok, ok := l.lexSignedNumber(); ok {
+					return tok, nil
+				}
+			}
 			return l.emitToken(token.Minus), nil
 		case r == '[':
 			// Check for ASN1Tag specifically
@@ -245,7 +361,7 @@ NextLoop: // Label for the outer loop
 		case r == '\'':
 			l.backup() // Let lexQuotedString handle the '\''
 			return l.lexQuotedString(), nil
-		case unicode.IsDigit(r): // Check the consumed character
+		case isDigit(r): // Check the consumed character
 			l.backup() // Backup so lexNumber starts correctly
 			return l.lexNumber(), nil
 		case isIdentifierStart(r): // Check the consumed character
@@ -253,7 +369,7 @@ NextLoop: // Label for the outer loop
 			return l.lexIdentifier(), nil // Lex the identifier first
 		default:
 			// The illegal character 'r' was already consumed by l.next() above
-			l.recordError(fmt.Sprintf("Illegal character: %q", r))
+			l.recordError(diag.CodeIllegalCharacter, fmt.Sprintf("Illegal character: %q", r))
 			return l.emitToken(token.ILLEGAL), nil // Emits the single illegal char
 		}
 	}
@@ -282,12 +398,10 @@ func (l *Lexer) lexIdentifier() lexer.Token {
 		if match {
 			// Consume the entire sequence including intermediate stuff
 			// The value emitted will be l.input[l.start:endPos]
-			// Need to manually advance pos and column correctly.
-			// Store current pos before advancing.
-			startPos := l.pos
 			l.pos = endPos
-			// Crude column update - doesn't account for newlines/tabs in comments
-			l.column += (endPos - startPos)
+			if l.canonical {
+				return l.emitValue(token.ObjectIdentifier, "OBJECT IDENTIFIER")
+			}
 			return l.emitToken(token.ObjectIdentifier)
 		}
 		// If peekAheadN failed, fall through to lex "OBJECT" as a regular Ident
@@ -297,9 +411,10 @@ func (l *Lexer) lexIdentifier() lexer.Token {
 	if l.peekAhead("OCTET") {
 		match, endPos := l.peekAheadN(len("OCTET"), "STRING")
 		if match {
-			startPos := l.pos
 			l.pos = endPos
-			l.column += (endPos - startPos)
+			if l.canonical {
+				return l.emitValue(token.OctetString, "OCTET STRING")
+			}
 			return l.emitToken(token.OctetString)
 		}
 		// If peekAheadN failed, fall through to lex "OCTET" as a regular Ident
@@ -335,65 +450,305 @@ func (l *Lexer) lexIdentifier() lexer.Token {
 	return l.emitToken(token.Ident)
 }
 
+// lexNumber lexes a decimal number, including a sign that has already been
+// consumed. Numbers are classified by the range they fall into, so that the
+// grammar can tell 64-bit values apart.
 func (l *Lexer) lexNumber() lexer.Token {
 	l.acceptRun("0123456789")
-	// Could add checks for hex/binary/float here if needed
-	return l.emitToken(token.Int)
+	return l.emitNumber(l.input[l.start:l.pos])
+}
+
+// lexSignedNumber lexes the number after a consumed '-' and any whitespace
+// following it, reporting false, with nothing consumed, if there is none.
+// The value of a number with whitespace after its sign is copied without it.
+func (l *Lexer) lexSignedNumber() (lexer.Token, bool) {
+	signEnd := l.pos
+	for unicode.IsSpace(l.peek()) {
+		l.next()
+	}
+	if !isDigit(l.peek()) {
+		l.pos = signEnd
+		return lexer.Token{}, false
+	}
+	if l.pos == signEnd {
+		return l.lexNumber(), true
+	}
+	digits := l.pos
+	l.acceptRun("0123456789")
+	return l.emitNumber("-" + l.input[digits:l.pos]), true
+}
+
+// emitNumber emits the decimal number value, classified by the range it falls
+// into.
+func (l *Lexer) emitNumber(value string) lexer.Token {
+	if value[0] == '-' {
+		n, err := strconv.ParseInt(value, 10, 64)
+		switch {
+		case err != nil:
+			l.recordError(diag.CodeNumberOutOfRange, fmt.Sprintf("Number %s is out of the Integer64 range", value))
+			return l.emitValue(token.ILLEGAL, value)
+		case n < math.MinInt32:
+			return l.emitValue(token.Int64, value)
+		}
+		return l.emitValue(token.Int, value)
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	switch {
+	case err != nil:
+		l.recordError(diag.CodeNumberOutOfRange, fmt.Sprintf("Number %s is out of the Unsigned64 range", value))
+		return l.emitValue(token.ILLEGAL, value)
+	case n > math.MaxUint32:
+		return l.emitValue(token.Uint64, value)
+	}
+	return l.emitValue(token.Int, value)
+}
+
+// valueMayStart reports whether the last token is one that a range bound,
+// named number value, DEFVAL or assigned value can follow.
+func (l *Lexer) valueMayStart() bool {
+	switch l.lastType {
+	case token.LPAREN, token.Range, token.Pipe, token.LBrace, token.Comma, token.Assign:
+		return true
+	}
+	return false
+}
+
+// skippedTextClauses are the clauses whose text SkipText drops
+var skippedTextClauses = map[string]bool{
+	"DESCRIPTION":  true,
+	"REFERENCE":    true,
+	"ORGANIZATION": true,
+	"CONTACT-INFO": true,
 }
 
 func (l *Lexer) lexText() lexer.Token {
+	if l.skipText && l.lastType == token.Ident && skippedTextClauses[l.lastValue] {
+		if tok, ok := l.skipTextBody(); ok {
+			return tok
+		}
+	}
 	startPosForCheck := l.start // Remember original start for ExtUTCTime check
 	l.next()                    // Consume the opening '"'
+	contentStart := l.pos
 
-	var builder strings.Builder
-	// Estimate size: use current pos and original start pos. This is >= 1.
-	builder.Grow(l.pos - startPosForCheck)
-
-	atLineStart := true       // NEW: Flag to track if we are at the start of a line within the string
-	lastCharWasSpace := false // NEW: Flag to track if the last written char was a space
+	// Track whether the raw content would survive normalization unchanged, in
+	// which case the token value can simply be a substring of the input.
+	clean := true
+	prev := '\n' // Content starts "at line start" for leading-space stripping
 	isTerminated := false
 
 	for {
 		r := l.next() // Consume next rune
 
-		if r == '\\' {
+		if r == '\\' && l.escapes {
 			// Handle escape sequence
-			escapeChar := l.next() // Consume the character *after* the backslash
-			if escapeChar == eof {
-				l.recordError("Unterminated escape sequence at end of string")
+			if l.next() == eof { // Consume the character *after* the backslash
+				l.recordError(diag.CodeUnterminatedString, "Unterminated escape sequence at end of string")
 				break
 			}
-			// Write the *actual* escaped character (e.g., write '"' for '\"')
-			builder.WriteRune(escapeChar)
-			atLineStart = false      // Escaped char is not whitespace
-			lastCharWasSpace = false // Escaped char is not space
+			clean = false
+			prev = 0
 			continue
 		} else if r == '"' {
 			isTerminated = true
 			break // End of string
 		} else if r == eof {
-			l.recordError("Unterminated string literal")
+			l.recordError(diag.CodeUnterminatedString, "Unterminated string literal")
+			break
+		}
+
+		switch {
+		case r == '\r' || r == '\t':
+			clean = false
+		case r == ' ' && (prev == ' ' || prev == '\n'):
+			clean = false
+		}
+		prev = r
+	}
+
+	// --- Post-loop processing ---
+
+	if !isTerminated {
+		// Emit the content read so far (including opening quote) as ILLEGAL
+		l.start = startPosForCheck // Reset start to include opening quote for ILLEGAL token
+		return l.emitToken(token.ILLEGAL)
+	}
+
+	content := l.input[contentStart : l.pos-1] // Content without quotes
+
+	// Check for ExtUTCTime using the *original* content before normalizing
+	if isExtUTCTime(content) {
+		if l.canonical {
+			// Unquoted and upper-cased, as the parser grammar expects
+			return l.emitValue(token.ExtUTCTime, strings.ToUpper(content))
+		}
+		// Emit ExtUTCTime with the *original* value (including quotes)
+		return l.emitToken(token.ExtUTCTime)
+	}
+
+	switch l.whitespace {
+	case TextRaw:
+		return l.emitValue(token.Text, content)
+	case TextLibsmi:
+		return l.emitValue(token.Text, libsmiText(content))
+	}
+
+	// Trailing whitespace is trimmed by normalization
+	if prev == ' ' || prev == '\n' {
+		clean = false
+	}
+	if clean {
+		return l.emitValue(token.Text, content)
+	}
+	return l.emitValue(token.Text, normalizeText(content, l.escapes))
+}
+
+// skipTextBody consumes a quoted string, which it emits as a Text token with
+// an empty value, without normalizing its content. ok is false, with nothing
+// consumed, if the string is unterminated, for lexText to report it.
+func (l *Lexer) skipTextBody() (tok lexer.Token, ok bool) {
+	stops := "\""
+	if l.escapes {
+		stops = "\"\\"
+	}
+	for i := l.pos + 1; i < len(l.input); i++ {
+		n := strings.IndexAny(l.input[i:], stops)
+		if n < 0 {
 			break
 		}
+		i += n
+		if l.input[i] == '"' {
+			l.pos = i + 1
+			return l.emitValue(token.Text, ""), true
+		}
+		i++ // The escaped character
+	}
+	return tok, false
+}
+
+// isExtUTCTime reports whether the content of a quoted string has the shape of
+// an ExtUTCTime value: 10 or 12 digits followed by 'Z'.
+func isExtUTCTime(content string) bool {
+	if len(content) != 11 && len(content) != 13 {
+		return false
+	}
+	if last := content[len(content)-1]; last != 'Z' && last != 'z' {
+		return false
+	}
+	for i := 0; i < len(content)-1; i++ { // Check only digits before Z
+		if !unicode.IsDigit(rune(content[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// TextWhitespace is how the lexer treats the whitespace in the content of
+// quoted strings, trading fidelity to the module for comparability.
+type TextWhitespace int
+
+const (
+	// TextNormalized treats whitespace as normalizeText does, so that texts
+	// compare equal however they are indented or wrapped. It is the default.
+	TextNormalized TextWhitespace = iota
+	// TextRaw keeps the content as written.
+	TextRaw
+	// TextLibsmi treats whitespace as libsmi does, see libsmiText, so that
+	// texts compare equal to those of libsmi tools such as smidump.
+	TextLibsmi
+)
+
+// libsmiText turns CRLF and LFCR line breaks into LF and strips the leading
+// whitespace of the lines after the first, up to the indentation of the
+// first of them that is not blank, as the libsmi scanners do. Tabs advance to
+// the next multiple of 8 columns. Backslashes are kept as written.
+func libsmiText(content string) string {
+	if !strings.ContainsAny(content, "\n\r") {
+		return content
+	}
+	var builder strings.Builder
+	builder.Grow(len(content))
+
+	atLineStart := false // Only lines after a line break are cut off
+	column := 0          // Width of the leading whitespace of the line so far
+	cutoff := 0          // Negated while measuring the first indented line
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if i+1 < len(content) && (c == '\r' && content[i+1] == '\n' || c == '\n' && content[i+1] == '\r') {
+			i++
+			c = '\n'
+		}
+		switch {
+		case c == '\n':
+			builder.WriteByte('\n')
+			atLineStart = true
+			column = 0
+			if cutoff < 0 {
+				cutoff = 0
+			}
+		case atLineStart && (c == ' ' || c == '\t'):
+			width := 1
+			if c == '\t' {
+				width = 8 - column%8
+			}
+			if cutoff <= 0 {
+				cutoff -= width
+			}
+			column += width
+			if cutoff > 0 && column > cutoff {
+				builder.WriteByte(c)
+				atLineStart = false
+			}
+		default:
+			builder.WriteByte(c)
+			atLineStart = false
+			if cutoff < 0 {
+				cutoff = -cutoff
+			}
+		}
+	}
+	return builder.String()
+}
+
+// normalizeText strips leading whitespace on each line, compresses runs of
+// spaces and trims trailing whitespace from the content of a quoted string,
+// interpreting backslash escapes if escapes is set.
+func normalizeText(content string, escapes bool) string {
+	var builder strings.Builder
+	builder.Grow(len(content))
+
+	atLineStart := true       // Flag to track if we are at the start of a line within the string
+	lastCharWasSpace := false // Flag to track if the last written char was a space
+
+	for i := 0; i < len(content); {
+		r, w := utf8.DecodeRuneInString(content[i:])
+		i += w
+
+		if escapes && r == '\\' && i < len(content) {
+			// Write the *actual* escaped character (e.g., write '"' for '\"')
+			escapeChar, w := utf8.DecodeRuneInString(content[i:])
+			i += w
+			builder.WriteRune(escapeChar)
+			atLineStart = false      // Escaped char is not whitespace
+			lastCharWasSpace = false // Escaped char is not space
+			continue
+		}
 
 		// Normalize CRLF and standalone CR to LF or space
 		if r == '\r' {
-			if l.peek() == '\n' {
+			if i < len(content) && content[i] == '\n' {
 				continue // Skip \r, handle \n in next iteration
 			}
 			r = ' ' // Treat standalone \r as space
 		}
 
-		isSpace := r == ' ' || r == '\t'
-		isNewline := r == '\n'
-
-		if isNewline {
-			// Write the newline. Post-processing trims trailing whitespace anyway.
+		switch {
+		case r == '\n':
+			// Write the newline. Trailing whitespace is trimmed below anyway.
 			builder.WriteRune('\n')
 			atLineStart = true       // Next char will be at line start
 			lastCharWasSpace = false // Newline is not a space
-			continue
-		} else if isSpace {
+		case r == ' ' || r == '\t':
 			if atLineStart {
 				// Skip leading whitespace on a line
 				continue
@@ -403,9 +758,7 @@ func (l *Lexer) lexText() lexer.Token {
 				builder.WriteRune(' ')
 				lastCharWasSpace = true
 			}
-			// Don't update atLineStart, still processing spaces
-			continue
-		} else {
+		default:
 			// Regular character
 			builder.WriteRune(r)
 			atLineStart = false
@@ -413,68 +766,8 @@ func (l *Lexer) lexText() lexer.Token {
 		}
 	}
 
-	// --- Post-loop processing ---
-
-	if !isTerminated {
-		// Emit the content read so far (including opening quote) as ILLEGAL
-		// Reset pos back to start to emit the whole thing? No, emit what was consumed.
-		l.start = startPosForCheck // Reset start to include opening quote for ILLEGAL token
-		return l.emitToken(token.ILLEGAL)
-	}
-
-	// Check for ExtUTCTime using the *original* slice before emitting
-	originalValueWithQuotes := l.input[startPosForCheck:l.pos]
-	if len(originalValueWithQuotes) > 2 { // Need at least quotes + Z
-		content := originalValueWithQuotes[1 : len(originalValueWithQuotes)-1] // Extract content without quotes
-		if (len(content) == 11 || len(content) == 13) && (content[len(content)-1] == 'Z' || content[len(content)-1] == 'z') {
-			isUTCTime := true
-			for i := 0; i < len(content)-1; i++ { // Check only digits before Z
-				if !unicode.IsDigit(rune(content[i])) {
-					isUTCTime = false
-					break
-				}
-			}
-			if isUTCTime {
-				// Emit ExtUTCTime with the *original* value (including quotes)
-				l.start = startPosForCheck // Ensure start is correct for original value
-				return l.emitToken(token.ExtUTCTime)
-			}
-		}
-	}
-
-	// If it's a terminated string and not ExtUTCTime, emit Text with the compressed value
 	// Trim trailing newline/space from builder result
-	finalContent := builder.String()
-	for len(finalContent) > 0 {
-		lastRune, size := utf8.DecodeLastRuneInString(finalContent)
-		if lastRune == ' ' || lastRune == '\n' || lastRune == '\t' {
-			finalContent = finalContent[:len(finalContent)-size]
-		} else {
-			break
-		}
-	}
-
-	// Emit Text token with the processed value (no quotes)
-	l.start = startPosForCheck + 1 // Set start *after* opening quote for the final value
-	l.pos--                        // Set pos *before* closing quote for the final value
-	// Temporarily modify input slice for emitToken (hacky but avoids changing emitToken)
-	originalInput := l.input
-	l.input = finalContent
-	// originalStart := l.start // Removed unused variable
-	originalPos := l.pos
-	l.start = 0
-	l.pos = len(finalContent)
-
-	tok := l.emitToken(token.Text) // emitToken uses l.start:l.pos on l.input
-
-	// Restore original lexer state
-	l.input = originalInput
-	l.start = startPosForCheck // Restore original start for next token
-	l.pos = originalPos + 1    // Restore original pos (after closing quote) for next token
-	l.startLine = l.line       // Ensure startLine/Col are updated after processing
-	l.startColumn = l.column
-
-	return tok
+	return strings.TrimRight(builder.String(), " \n\t")
 }
 
 func (l *Lexer) lexQuotedString() lexer.Token {
@@ -488,8 +781,10 @@ func (l *Lexer) lexQuotedString() lexer.Token {
 			l.next() // Consume closing '\''
 			// isTerminated = true // No longer needed - Ensure this line is removed/remains commented
 			break // End of quoted part
+		} else if r == '\n' && l.wrapped && l.wrappedQuotedEnds() {
+			l.next() // A line break within a wrapped hex or bin string
 		} else if r == eof || r == '\n' { // Newlines not allowed in '...' strings
-			l.recordError("Unterminated or multi-line single-quoted string")
+			l.recordError(diag.CodeUnterminatedQuoted, "Unterminated or multi-line single-quoted string")
 			// Don't consume EOF/newline, let emitToken capture value up to current pos
 			return l.emitToken(token.ILLEGAL) // Emit the unterminated part
 		} else {
@@ -508,8 +803,11 @@ func (l *Lexer) lexQuotedString() lexer.Token {
 		tokType = token.HexString
 		// Validate content *now*
 		for i := contentStart; i < contentEnd; i++ {
+			if l.wrapped && isQuotedSpace(l.input[i]) {
+				continue
+			}
 			if !isHexDigit(rune(l.input[i])) {
-				l.recordError(fmt.Sprintf("Invalid character '%c' in HexString", l.input[i]))
+				l.recordError(diag.CodeInvalidQuotedDigit, fmt.Sprintf("Invalid character '%c' in HexString", l.input[i]))
 				hasContentError = true // Mark error
 				break                  // Stop validation on first error
 			}
@@ -519,8 +817,11 @@ func (l *Lexer) lexQuotedString() lexer.Token {
 		tokType = token.BinString
 		// Validate content *now*
 		for i := contentStart; i < contentEnd; i++ {
+			if l.wrapped && isQuotedSpace(l.input[i]) {
+				continue
+			}
 			if l.input[i] != '0' && l.input[i] != '1' {
-				l.recordError(fmt.Sprintf("Invalid character '%c' in BinString", l.input[i]))
+				l.recordError(diag.CodeInvalidQuotedDigit, fmt.Sprintf("Invalid character '%c' in BinString", l.input[i]))
 				hasContentError = true // Mark error
 				break                  // Stop validation on first error
 			}
@@ -528,7 +829,7 @@ func (l *Lexer) lexQuotedString() lexer.Token {
 		// <<< End of BinString validation loop
 	} else { // <<< Correctly placed else block
 		// No valid suffix found ('H'/'B') or EOF reached after closing quote.
-		l.recordError("Invalid or missing suffix for single-quoted string (expected 'H' or 'B')")
+		l.recordError(diag.CodeInvalidQuotedSuffix, "Invalid or missing suffix for single-quoted string (expected 'H' or 'B')")
 		// Check if there *is* a character immediately after the quote that isn't whitespace/EOF.
 		// If so, consume it as part of the ILLEGAL token.
 		if suffix != eof && !unicode.IsSpace(suffix) {
@@ -546,6 +847,31 @@ func (l *Lexer) lexQuotedString() lexer.Token {
 		tokType = token.ILLEGAL
 	}
 
+	if l.wrapped && tokType != token.ILLEGAL {
+		if content := l.input[contentStart:contentEnd]; strings.ContainsAny(content, " \t\r\n") {
+			kind := "HexString"
+			if tokType == token.BinString {
+				kind = "BinString"
+			}
+			l.recordWarning(diag.CodeWrappedQuoted, "Whitespace in "+kind+" ignored")
+			value := "'" + strings.Map(func(r rune) rune {
+				if r < utf8.RuneSelf && isQuotedSpace(byte(r)) {
+					return -1
+				}
+				return r
+			}, content) + l.input[contentEnd:l.pos]
+			if l.canonical {
+				value = strings.ToUpper(value)
+			}
+			return l.emitValue(tokType, value)
+		}
+	}
+
+	if l.canonical && tokType != token.ILLEGAL {
+		// strings.ToUpper returns its argument unchanged if there is nothing to convert
+		return l.emitValue(tokType, strings.ToUpper(l.input[l.start:l.pos]))
+	}
+
 	// emitToken uses l.start (before opening quote) and l.pos (after closing quote + suffix if valid/consumed)
 	return l.emitToken(tokType)
 }
@@ -561,24 +887,21 @@ func (l *Lexer) lexASN1Tag() lexer.Token {
 
 	// Expect "APPLICATION"
 	if !l.peekAhead("APPLICATION") {
-		l.recordError("Expected 'APPLICATION' in ASN.1 Tag")
-		l.pos = startPos // Reset pos to after '['
-		l.backup()       // Backup '[' itself
-		// Now emit '[' as ILLEGAL or maybe specific Bracket token?
-		// Let's emit ILLEGAL for the '[' for now.
-		l.next() // Consume '[' again
+		l.recordError(diag.CodeInvalidASN1Tag, "Expected 'APPLICATION' in ASN.1 Tag")
+		// Emit just the '[' as ILLEGAL. Resetting pos rather than backing
+		// up, as the last rune read may be a wider one after whitespace.
+		l.pos = startPos
 		return l.emitToken(token.ILLEGAL)
 	}
 	l.pos += len("APPLICATION")
-	// Column update is tricky here, skip for now
 
 	// Skip whitespace after APPLICATION
 	l.skipWhitespace()
 
 	// Expect digits
 	digitStart := l.pos
-	if !unicode.IsDigit(l.peek()) {
-		l.recordError("Expected digits after 'APPLICATION' in ASN.1 Tag")
+	if !isDigit(l.peek()) {
+		l.recordError(diag.CodeInvalidASN1Tag, "Expected digits after 'APPLICATION' in ASN.1 Tag")
 		// Emit the '[' + APPLICATION part as ILLEGAL?
 		// Reset pos to start of tag and emit ILLEGAL up to current point
 		l.pos = l.start    // Reset to '['
@@ -593,7 +916,7 @@ func (l *Lexer) lexASN1Tag() lexer.Token {
 
 	// Expect ']'
 	if l.peek() != ']' {
-		l.recordError("Expected ']' to close ASN.1 Tag")
+		l.recordError(diag.CodeInvalidASN1Tag, "Expected ']' to close ASN.1 Tag")
 		// Emit ILLEGAL up to the current position (which is likely EOF or the char after digits)
 		// Do not reset pos, as it caused infinite loops.
 		// l.pos = l.start // Reset to '[' - REMOVED
@@ -634,6 +957,39 @@ func isIdentifierChar(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-'
 }
 
+// isDigit reports whether r is an ASCII digit. Numbers are never made of
+// other Unicode digits.
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+// isQuotedSpace reports whether c is whitespace that wrapped hex and bin
+// strings may contain.
+func isQuotedSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// wrappedQuotedEnds reports whether the quoted string being lexed is closed
+// by a quote followed by H or B after nothing but hex digits and whitespace,
+// so that a line break may be taken as part of it without swallowing the
+// lines after a stray quote.
+func (l *Lexer) wrappedQuotedEnds() bool {
+	for i := l.pos; i < len(l.input); i++ {
+		c := l.input[i]
+		switch {
+		case c == '\'':
+			if i+1 == len(l.input) {
+				return false
+			}
+			suffix := l.input[i+1]
+			return suffix == 'H' || suffix == 'h' || suffix == 'B' || suffix == 'b'
+		case !isQuotedSpace(c) && !isHexDigit(rune(c)):
+			return false
+		}
+	}
+	return false
+}
+
 func isHexDigit(r rune) bool {
 	return ('0' <= r && r <= '9') || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
 }
@@ -653,20 +1009,12 @@ func (l *Lexer) peekAhead(prefix string) bool {
 // Returns true and the ending position of the match (after expected string) if successful.
 func (l *Lexer) peekAheadN(offset int, expected string) (bool, int) {
 	currentPos := l.pos + offset
-	startLine := l.line // Track position for potential comment skipping
-	startCol := l.column + offset
 
 	// Skip intermediate whitespace and comments
 	for currentPos < len(l.input) {
 		r, w := utf8.DecodeRuneInString(l.input[currentPos:])
 		if unicode.IsSpace(r) {
 			currentPos += w
-			if r == '\n' {
-				startLine++
-				startCol = 1
-			} else {
-				startCol += w
-			}
 			continue
 		}
 		// Check for comment start '--'
@@ -681,8 +1029,6 @@ func (l *Lexer) peekAheadN(offset int, expected string) (bool, int) {
 				rConsume, wConsume := utf8.DecodeRuneInString(l.input[currentPos:])
 				currentPos += wConsume
 				if rConsume == '\n' {
-					startLine++
-					startCol = 1
 					break // Stop after consuming newline
 				}
 				if currentPos >= len(l.input) { // Check if we hit EOF
@@ -715,25 +1061,78 @@ var (
 )
 
 // LexerDefinition implements the participle lexer.Definition interface.
-type LexerDefinition struct{}
+//
+// It also implements lexer.StringDefinition and lexer.BytesDefinition, so
+// participle's ParseString and ParseBytes lex their input in place rather than
+// copying it through an io.Reader.
+type LexerDefinition struct {
+	// Canonical makes the lexer emit the values the parser grammar expects:
+	// ExtUTCTime without quotes, ExtUTCTime/BinString/HexString upper-cased
+	// and multi-word keywords with single spaces. Doing this in the lexer
+	// avoids participle's mapping options, which hide LexString and LexBytes.
+	Canonical bool
+	// Diagnostics, if set, receives the lexical errors of every lexer created
+	// from the definition. Otherwise they are printed.
+	Diagnostics *diag.Collector
+	// MaxTokens and MaxNesting, if positive, limit the number of tokens of
+	// the input and the depth to which braces and parentheses nest in it.
+	// Lexers return a *diag.LimitExceededError when either is exceeded.
+	MaxTokens  int
+	MaxNesting int
+	// Context, if set, cancels lexing: once it is done, lexers return its
+	// error.
+	Context context.Context
+	// SkipText makes lexers emit the texts of DESCRIPTION, REFERENCE,
+	// ORGANIZATION and CONTACT-INFO clauses with empty values, skipping over
+	// them without normalizing them.
+	SkipText bool
+	// TextWhitespace is how lexers treat the whitespace of the content of
+	// quoted strings, by default as normalizeText does.
+	TextWhitespace TextWhitespace
+	// WrappedQuoted makes lexers accept hex and bin strings with whitespace
+	// and line breaks between their digits, such as long values wrapped by
+	// generators, with a warning. The whitespace is stripped from the value.
+	WrappedQuoted bool
+	// Escapes makes lexers treat a backslash in a quoted string as escaping
+	// the next character, so that \" does not end the string, and drop it
+	// from normalized texts. SMI has no escapes: by default a backslash is an
+	// ordinary character and a quote always ends the string.
+	Escapes bool
+}
+
+func (d *LexerDefinition) newLexer(filename string, input string) *Lexer {
+	l := NewLexer(filename, input)
+	l.canonical = d.Canonical
+	l.diagnostics = d.Diagnostics
+	l.maxTokens = d.MaxTokens
+	l.maxNesting = d.MaxNesting
+	l.ctx = d.Context
+	l.skipText = d.SkipText
+	l.whitespace = d.TextWhitespace
+	l.escapes = d.Escapes
+	l.wrapped = d.WrappedQuoted
+	return l
+}
 
 // Lex implements lexer.Definition.
 func (d *LexerDefinition) Lex(filename string, r io.Reader) (lexer.Lexer, error) {
-	inputBytes, err := ioutil.ReadAll(r)
+	inputBytes, err := io.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read input for lexing: %w", err)
 	}
-	return NewLexer(filename, string(inputBytes)), nil
+	// inputBytes is never exposed, so it is safe to lex it in place
+	return d.newLexer(filename, bytesToString(inputBytes)), nil
 }
 
-// LexString implements lexer.Definition.
+// LexString implements lexer.StringDefinition.
 func (d *LexerDefinition) LexString(filename string, input string) (lexer.Lexer, error) {
-	return NewLexer(filename, input), nil
+	return d.newLexer(filename, input), nil
 }
 
-// LexBytes implements lexer.Definition.
+// LexBytes implements lexer.BytesDefinition. The input is not copied, see
+// NewLexerBytes.
 func (d *LexerDefinition) LexBytes(filename string, input []byte) (lexer.Lexer, error) {
-	return NewLexer(filename, string(input)), nil
+	return d.newLexer(filename, bytesToString(input)), nil
 }
 
 // Symbols implements lexer.Definition, caching the result.
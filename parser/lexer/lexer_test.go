@@ -1,9 +1,12 @@
 package lexer
 
 import (
+	"reflect"
 	"testing"
+	"unsafe"
 
 	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser/lexer/token" // Corrected import path
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -144,6 +147,106 @@ func TestLexerBasicTokens(t *testing.T) {
 	}
 }
 
+func TestLexerNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "Signed range bounds",
+			input: "(-10..-1 | 5)",
+			expected: []token.Token{
+				{Type: token.LPAREN, Value: "("},
+				{Type: token.Int, Value: "-10"},
+				{Type: token.Range, Value: ".."},
+				{Type: token.Int, Value: "-1"},
+				{Type: token.Pipe, Value: "|"},
+				{Type: token.Int, Value: "5"},
+				{Type: token.RPAREN, Value: ")"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "Sign separated from its number",
+			input: "( - 5 | -3 .. -\n1 | - x)",
+			expected: []token.Token{
+				{Type: token.LPAREN, Value: "("},
+				{Type: token.Int, Value: "-5"},
+				{Type: token.Pipe, Value: "|"},
+				{Type: token.Int, Value: "-3"},
+				{Type: token.Range, Value: ".."},
+				{Type: token.Int, Value: "-1"},
+				{Type: token.Pipe, Value: "|"},
+				{Type: token.Minus, Value: "-"},
+				{Type: token.Ident, Value: "x"},
+				{Type: token.RPAREN, Value: ")"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "Minus outside of a value",
+			input: "5-3 x -1",
+			expected: []token.Token{
+				{Type: token.Int, Value: "5"},
+				{Type: token.Minus, Value: "-"},
+				{Type: token.Int, Value: "3"},
+				{Type: token.Ident, Value: "x"},
+				{Type: token.Minus, Value: "-"},
+				{Type: token.Int, Value: "1"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "32-bit limits",
+			input: "{-2147483648, 4294967295}",
+			expected: []token.Token{
+				{Type: token.LBrace, Value: "{"},
+				{Type: token.Int, Value: "-2147483648"},
+				{Type: token.Comma, Value: ","},
+				{Type: token.Int, Value: "4294967295"},
+				{Type: token.RBrace, Value: "}"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "64-bit values",
+			input: "{-2147483649, 4294967296, 18446744073709551615}",
+			expected: []token.Token{
+				{Type: token.LBrace, Value: "{"},
+				{Type: token.Int64, Value: "-2147483649"},
+				{Type: token.Comma, Value: ","},
+				{Type: token.Uint64, Value: "4294967296"},
+				{Type: token.Comma, Value: ","},
+				{Type: token.Uint64, Value: "18446744073709551615"},
+				{Type: token.RBrace, Value: "}"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "Out of range",
+			input: "(18446744073709551616)",
+			expected: []token.Token{
+				{Type: token.LPAREN, Value: "("},
+				{Type: token.ILLEGAL, Value: "18446744073709551616"},
+				{Type: token.RPAREN, Value: ")"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualTokens := lexAll(t, tt.input)
+			require.Equal(t, len(tt.expected), len(actualTokens), "Number of tokens mismatch")
+			for i := range tt.expected {
+				assert.Equal(t, lexer.TokenType(tt.expected[i].Type), actualTokens[i].Type, "Token %d Type mismatch", i)
+				assert.Equal(t, tt.expected[i].Value, actualTokens[i].Value, "Token %d Value mismatch", i)
+			}
+		})
+	}
+}
+
 func TestLexerPositionTracking(t *testing.T) {
 	input := `LINE1
 LINE2 IDENT
@@ -189,6 +292,34 @@ LINE4`
 	}
 }
 
+func TestLexerPositionTrackingMultiLine(t *testing.T) {
+	// Multi-word keywords and tags spanning lines, and numbers followed by a
+	// newline the lexer has to back up over
+	input := "x OBJECT -- c\n  IDENTIFIER [ APPLICATION\n 3 ]\n\ty 7\nz"
+	tokens := lexAll(t, input)
+
+	expected := []struct {
+		typ    token.TokenType
+		offset int
+		line   int
+		column int
+	}{
+		{token.Ident, 0, 1, 1},
+		{token.ObjectIdentifier, 2, 1, 3},
+		{token.ASN1Tag, 27, 2, 14},
+		{token.Ident, 47, 4, 2},
+		{token.Int, 49, 4, 4},
+		{token.Ident, 51, 5, 1},
+		{token.EOF, 52, 5, 2},
+	}
+	require.Len(t, tokens, len(expected))
+	for i, want := range expected {
+		pos := tokens[i].Pos
+		assert.Equal(t, lexer.TokenType(want.typ), tokens[i].Type, "Token %d type", i)
+		assert.Equal(t, lexer.Position{Filename: "test.smi", Offset: want.offset, Line: want.line, Column: want.column}, pos, "Token %d (%q) position", i, tokens[i].Value)
+	}
+}
+
 func TestLexerStringLiterals(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -199,15 +330,15 @@ func TestLexerStringLiterals(t *testing.T) {
 			name:  "Simple Text",
 			input: `"hello world"`,
 			expected: []token.Token{
-				{Type: token.Text, Value: `"hello world"`},
+				{Type: token.Text, Value: `hello world`},
 				{Type: token.EOF, Value: ""},
 			},
 		},
 		{
-			name:  "Text with escaped quote",
-			input: `"hello \"quoted\" world"`,
+			name:  "Text with backslash",
+			input: `"C:\path\"`,
 			expected: []token.Token{
-				{Type: token.Text, Value: `"hello \"quoted\" world"`},
+				{Type: token.Text, Value: `C:\path\`},
 				{Type: token.EOF, Value: ""},
 			},
 		},
@@ -215,7 +346,7 @@ func TestLexerStringLiterals(t *testing.T) {
 			name:  "Multiline Text",
 			input: "\"line one\nline two\"",
 			expected: []token.Token{
-				{Type: token.Text, Value: "\"line one\nline two\""},
+				{Type: token.Text, Value: "line one\nline two"},
 				{Type: token.EOF, Value: ""},
 			},
 		},
@@ -223,7 +354,7 @@ func TestLexerStringLiterals(t *testing.T) {
 			name:  "Empty Text",
 			input: `""`,
 			expected: []token.Token{
-				{Type: token.Text, Value: `""`},
+				{Type: token.Text, Value: ``},
 				{Type: token.EOF, Value: ""},
 			},
 		},
@@ -247,7 +378,7 @@ func TestLexerStringLiterals(t *testing.T) {
 			name:  "Text looks like UTC but wrong length",
 			input: `"20240501Z"`,
 			expected: []token.Token{
-				{Type: token.Text, Value: `"20240501Z"`}, // Should be Text, not ExtUTCTime
+				{Type: token.Text, Value: `20240501Z`}, // Should be Text, not ExtUTCTime
 				{Type: token.EOF, Value: ""},
 			},
 		},
@@ -255,7 +386,7 @@ func TestLexerStringLiterals(t *testing.T) {
 			name:  "Text looks like UTC but no Z",
 			input: `"20240501123000"`,
 			expected: []token.Token{
-				{Type: token.Text, Value: `"20240501123000"`}, // Should be Text
+				{Type: token.Text, Value: `20240501123000`}, // Should be Text
 				{Type: token.EOF, Value: ""},
 			},
 		},
@@ -648,3 +779,257 @@ func TestLexerIllegalChars(t *testing.T) {
 // TODO: Add tests for:
 // - Error cases (illegal characters, unterminated strings) - More specific error checks
 // - Comment edge cases (EOF, identifier followed by comment)
+
+func TestLexerBytesZeroCopy(t *testing.T) {
+	input := []byte(`sysDescr "A simple description" "needs   normalizing"`)
+	def := &LexerDefinition{}
+	lex, err := def.LexBytes("bytes.smi", input)
+	require.NoError(t, err)
+
+	inInput := func(s string) bool {
+		if len(s) == 0 {
+			return false
+		}
+		start := uintptr(unsafe.Pointer(&input[0]))
+		p := (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+		return p >= start && p < start+uintptr(len(input))
+	}
+
+	tok, err := lex.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "sysDescr", tok.Value)
+	assert.True(t, inInput(tok.Value), "Ident value should share memory with input")
+
+	tok, err = lex.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "A simple description", tok.Value)
+	assert.True(t, inInput(tok.Value), "clean Text value should share memory with input")
+
+	tok, err = lex.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "needs normalizing", tok.Value)
+	assert.False(t, inInput(tok.Value), "normalized Text value is a new string")
+}
+
+func TestLexerCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []token.Token
+	}{
+		{
+			name:  "ExtUTCTime unquoted and upper-cased",
+			input: `"202405011230z"`,
+			expected: []token.Token{
+				{Type: token.ExtUTCTime, Value: `202405011230Z`},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "Hex and bin strings upper-cased",
+			input: `'0af1'h '0101'b '0AF1'H`,
+			expected: []token.Token{
+				{Type: token.HexString, Value: `'0AF1'H`},
+				{Type: token.BinString, Value: `'0101'B`},
+				{Type: token.HexString, Value: `'0AF1'H`},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+		{
+			name:  "Multi-word keywords collapsed",
+			input: "OBJECT -- comment\n IDENTIFIER OCTET\tSTRING",
+			expected: []token.Token{
+				{Type: token.ObjectIdentifier, Value: "OBJECT IDENTIFIER"},
+				{Type: token.OctetString, Value: "OCTET STRING"},
+				{Type: token.EOF, Value: ""},
+			},
+		},
+	}
+
+	def := &LexerDefinition{Canonical: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex, err := def.LexString("canonical.smi", tt.input)
+			require.NoError(t, err)
+			for i, expected := range tt.expected {
+				tok, err := lex.Next()
+				require.NoError(t, err)
+				assert.Equal(t, lexer.TokenType(expected.Type), tok.Type, "Token %d Type mismatch", i)
+				assert.Equal(t, expected.Value, tok.Value, "Token %d Value mismatch", i)
+			}
+		})
+	}
+}
+
+func TestLexerDiagnostics(t *testing.T) {
+	var diagnostics diag.Collector
+	def := &LexerDefinition{Diagnostics: &diagnostics}
+	lex, err := def.LexString("diag.smi", "foo\n  'GG'H")
+	require.NoError(t, err)
+	for {
+		tok, err := lex.Next()
+		require.NoError(t, err)
+		if tok.EOF() {
+			break
+		}
+	}
+
+	require.Equal(t, 1, diagnostics.Len())
+	d := diagnostics.Diagnostics()[0]
+	assert.Equal(t, diag.SeverityError, d.Severity)
+	assert.Equal(t, diag.Position{Filename: "diag.smi", Offset: 6, Line: 2, Column: 3}, d.Pos)
+	assert.Equal(t, diag.CodeInvalidQuotedDigit, d.Code)
+	assert.Equal(t, "Invalid character 'G' in HexString", d.Message)
+}
+
+func TestLexerSkipText(t *testing.T) {
+	input := "DESCRIPTION \"a \\\"quoted\\\"\n   text\" UNITS \"seconds\" REFERENCE\n\"RFC 1\" LAST-UPDATED \"202401010000Z\" DESCRIPTION \"unterminated"
+	expected := []token.Token{
+		{Type: token.Ident, Value: "DESCRIPTION"},
+		{Type: token.Text, Value: ""},
+		{Type: token.Ident, Value: "UNITS"},
+		{Type: token.Text, Value: "seconds"},
+		{Type: token.Ident, Value: "REFERENCE"},
+		{Type: token.Text, Value: ""},
+		{Type: token.Ident, Value: "LAST-UPDATED"},
+		{Type: token.ExtUTCTime, Value: "202401010000Z"},
+		{Type: token.Ident, Value: "DESCRIPTION"},
+		{Type: token.ILLEGAL, Value: "\"unterminated"},
+		{Type: token.EOF, Value: ""},
+	}
+
+	var diagnostics diag.Collector
+	def := &LexerDefinition{Canonical: true, SkipText: true, Escapes: true, Diagnostics: &diagnostics}
+	lex, err := def.LexString("skip.smi", input)
+	require.NoError(t, err)
+	for i, expected := range expected {
+		tok, err := lex.Next()
+		require.NoError(t, err)
+		assert.Equal(t, lexer.TokenType(expected.Type), tok.Type, "Token %d Type mismatch", i)
+		assert.Equal(t, expected.Value, tok.Value, "Token %d Value mismatch", i)
+		if i == 5 {
+			assert.Equal(t, 3, tok.Pos.Line, "Lines are counted across skipped texts")
+		}
+	}
+	require.Equal(t, 1, diagnostics.Len())
+	assert.Equal(t, diag.CodeUnterminatedString, diagnostics.Diagnostics()[0].Code)
+}
+
+func TestLexerTextWhitespace(t *testing.T) {
+	input := "\"First  line\n      indented\n        deeper\r\n\n\tback \\q \""
+	tests := []struct {
+		whitespace TextWhitespace
+		expected   string
+	}{
+		{TextNormalized, "First line\nindented\ndeeper\n\nback \\q"},
+		{TextRaw, "First  line\n      indented\n        deeper\r\n\n\tback \\q "},
+		{TextLibsmi, "First  line\nindented\n  deeper\n\n\tback \\q "},
+	}
+	for _, tt := range tests {
+		def := &LexerDefinition{Canonical: true, TextWhitespace: tt.whitespace}
+		lex, err := def.LexString("text.smi", input)
+		require.NoError(t, err)
+		tok, err := lex.Next()
+		require.NoError(t, err)
+		assert.Equal(t, lexer.TokenType(token.Text), tok.Type)
+		assert.Equal(t, tt.expected, tok.Value, "Whitespace %d", tt.whitespace)
+	}
+	assert.Equal(t, "one line ", libsmiText("one line "))
+	assert.Equal(t, "a\nb\n    c", libsmiText("a\n\tb\n\t    c"))
+}
+
+func TestLexerEscapes(t *testing.T) {
+	input := "\"a \\\"quoted\\\"  \\\\ word\" \"C:\\\""
+	tests := []struct {
+		escapes    bool
+		whitespace TextWhitespace
+		expected   []token.Token
+	}{
+		{false, TextNormalized, []token.Token{
+			{Type: token.Text, Value: "a \\"},
+			{Type: token.Ident, Value: "quoted"},
+			{Type: token.ILLEGAL, Value: "\\"},
+			{Type: token.Text, Value: "\\\\ word"},
+			{Type: token.Text, Value: "C:\\"},
+		}},
+		{true, TextNormalized, []token.Token{
+			{Type: token.Text, Value: "a \"quoted\" \\ word"},
+			{Type: token.ILLEGAL, Value: "\"C:\\\""},
+		}},
+		{true, TextRaw, []token.Token{
+			{Type: token.Text, Value: "a \\\"quoted\\\"  \\\\ word"},
+			{Type: token.ILLEGAL, Value: "\"C:\\\""},
+		}},
+	}
+	for _, tt := range tests {
+		def := &LexerDefinition{Canonical: true, Escapes: tt.escapes, TextWhitespace: tt.whitespace, Diagnostics: new(diag.Collector)}
+		lex, err := def.LexString("escapes.smi", input)
+		require.NoError(t, err)
+		var tokens []lexer.Token
+		for {
+			tok, err := lex.Next()
+			require.NoError(t, err)
+			if tok.Type == lexer.EOF {
+				break
+			}
+			if tok.Type != lexer.TokenType(token.Whitespace) {
+				tokens = append(tokens, tok)
+			}
+		}
+		require.Len(t, tokens, len(tt.expected), "Escapes %t, whitespace %d: %v", tt.escapes, tt.whitespace, tokens)
+		for i, expected := range tt.expected {
+			assert.Equal(t, lexer.TokenType(expected.Type), tokens[i].Type, "Escapes %t, token %d type", tt.escapes, i)
+			assert.Equal(t, expected.Value, tokens[i].Value, "Escapes %t, token %d value", tt.escapes, i)
+		}
+	}
+}
+
+func TestLexerWrappedQuoted(t *testing.T) {
+	input := "'0a 0B\n    0C'h '1010\n 0101'B '0A\nfoo"
+	tests := []struct {
+		wrapped  bool
+		expected []token.Token
+	}{
+		{true, []token.Token{
+			{Type: token.HexString, Value: "'0A0B0C'H"},
+			{Type: token.BinString, Value: "'10100101'B"},
+			{Type: token.ILLEGAL, Value: "'0A"},
+			{Type: token.Ident, Value: "foo"},
+		}},
+		{false, []token.Token{
+			{Type: token.ILLEGAL, Value: "'0a 0B"},
+		}},
+	}
+	for _, tt := range tests {
+		diagnostics := new(diag.Collector)
+		def := &LexerDefinition{Canonical: true, WrappedQuoted: tt.wrapped, Diagnostics: diagnostics}
+		lex, err := def.LexString("wrapped.smi", input)
+		require.NoError(t, err)
+		var tokens []lexer.Token
+		for {
+			tok, err := lex.Next()
+			require.NoError(t, err)
+			if tok.Type == lexer.EOF {
+				break
+			}
+			if tok.Type != lexer.TokenType(token.Whitespace) {
+				tokens = append(tokens, tok)
+			}
+		}
+		require.GreaterOrEqual(t, len(tokens), len(tt.expected), "Wrapped %t: %v", tt.wrapped, tokens)
+		for i, expected := range tt.expected {
+			assert.Equal(t, lexer.TokenType(expected.Type), tokens[i].Type, "Wrapped %t, token %d type", tt.wrapped, i)
+			assert.Equal(t, expected.Value, tokens[i].Value, "Wrapped %t, token %d value", tt.wrapped, i)
+		}
+		if tt.wrapped {
+			var warnings int
+			for _, d := range diagnostics.Diagnostics() {
+				if d.Code == diag.CodeWrappedQuoted {
+					assert.Equal(t, diag.SeverityWarning, d.Severity)
+					warnings++
+				}
+			}
+			assert.Equal(t, 2, warnings)
+		}
+	}
+}
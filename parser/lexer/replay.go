@@ -0,0 +1,132 @@
+package lexer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+)
+
+// Trivia is whitespace or a comment between two tokens.
+type Trivia struct {
+	Pos lexer.Position
+	// Comment is set for a comment, which Text starts with "--". It does not
+	// include the newline ending it.
+	Comment bool
+	Text    string
+}
+
+// SourceToken is a token with the source it was lexed from and the trivia
+// preceding it.
+type SourceToken struct {
+	lexer.Token
+	// Raw is the token as written, such as a Text token with its quotes.
+	Raw string
+	// Leading are the whitespace and comments between the previous token and
+	// this one.
+	Leading []Trivia
+}
+
+// End returns the offset just after the token in the source.
+func (t SourceToken) End() int {
+	return t.Pos.Offset + len(t.Raw)
+}
+
+// File is the full token stream of a source file, with which tools rewrite
+// parts of a module and leave the rest of it exactly as written: edit the Raw
+// text or the Leading trivia of the tokens to change, then render the file
+// again with Bytes.
+type File struct {
+	Filename string
+	// Tokens end with an EOF token, whose Leading trivia ends the file.
+	Tokens []SourceToken
+}
+
+// Tokenize lexes the rest of the input into a File. Lexical errors are
+// reported as by Next, the ILLEGAL tokens kept, so the only errors returned
+// are those ending lexing, of limits and the context.
+func (l *Lexer) Tokenize() (*File, error) {
+	f := &File{Filename: l.filename}
+	prev := l.pos
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			return nil, err
+		}
+		t := SourceToken{
+			Token:   tok,
+			Raw:     l.input[tok.Pos.Offset:l.pos],
+			Leading: l.trivia(prev, tok.Pos.Offset),
+		}
+		if tok.EOF() {
+			t.Raw = ""
+			t.Leading = l.trivia(prev, len(l.input))
+		}
+		f.Tokens = append(f.Tokens, t)
+		if tok.EOF() {
+			return f, nil
+		}
+		prev = l.pos
+	}
+}
+
+// trivia splits the input from start to end, which only has whitespace and
+// comments, into trivia
+func (l *Lexer) trivia(start, end int) []Trivia {
+	var trivia []Trivia
+	for i := start; i < end; {
+		t := Trivia{Pos: l.position(i)}
+		j := i
+		if strings.HasPrefix(l.input[i:end], "--") {
+			t.Comment = true
+			if n := strings.IndexByte(l.input[i:end], '\n'); n >= 0 {
+				j = i + n
+			} else {
+				j = end
+			}
+		} else {
+			j = i + strings.IndexFunc(l.input[i:end], func(r rune) bool { return !unicode.IsSpace(r) })
+			if j < i {
+				j = end
+			}
+		}
+		t.Text = l.input[i:j]
+		trivia = append(trivia, t)
+		i = j
+	}
+	return trivia
+}
+
+// Bytes renders the tokens and trivia of the file, which gives back the
+// source the file was lexed from unless tokens were edited.
+func (f *File) Bytes() []byte {
+	var b strings.Builder
+	for _, t := range f.Tokens {
+		for _, trivia := range t.Leading {
+			b.WriteString(trivia.Text)
+		}
+		b.WriteString(t.Raw)
+	}
+	return []byte(b.String())
+}
+
+// TokenAt returns the index of the token starting at offset, as the Pos of
+// the definitions and clauses of a parsed module do. ok is false if no token
+// starts there.
+func (f *File) TokenAt(offset int) (i int, ok bool) {
+	i = sort.Search(len(f.Tokens), func(i int) bool { return f.Tokens[i].Pos.Offset >= offset })
+	return i, i < len(f.Tokens) && f.Tokens[i].Pos.Offset == offset && !f.Tokens[i].EOF()
+}
+
+// Idents returns the indices of the identifier tokens named name, in order.
+func (f *File) Idents(name string) []int {
+	var idents []int
+	for i, t := range f.Tokens {
+		if token.TokenType(t.Type) == token.Ident && t.Value == name {
+			idents = append(idents, i)
+		}
+	}
+	return idents
+}
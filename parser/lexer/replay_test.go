@@ -0,0 +1,78 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const replayInput = `-- A module
+REPLAY-MIB DEFINITIONS ::= BEGIN
+replayRoot  OBJECT
+    IDENTIFIER ::= { iso 9 } -- the root
+
+replayObject OBJECT-TYPE
+	SYNTAX OCTET STRING (SIZE (0..'ff'h))
+	DESCRIPTION "Two
+	    lines"
+	::= { replayRoot 1 }
+END
+-- trailing`
+
+func TestTokenize(t *testing.T) {
+	l := NewLexer("REPLAY-MIB", replayInput)
+	l.SetDiagnostics(new(diag.Collector))
+	f, err := l.Tokenize()
+	require.NoError(t, err)
+	assert.Equal(t, replayInput, string(f.Bytes()))
+
+	first := f.Tokens[0]
+	require.Len(t, first.Leading, 2)
+	assert.True(t, first.Leading[0].Comment)
+	assert.Equal(t, "-- A module", first.Leading[0].Text)
+	assert.Equal(t, "\n", first.Leading[1].Text)
+
+	last := f.Tokens[len(f.Tokens)-1]
+	assert.True(t, last.EOF())
+	require.Len(t, last.Leading, 2)
+	assert.Equal(t, "-- trailing", last.Leading[1].Text)
+
+	// Raw keeps what the canonical values change
+	for _, tok := range f.Tokens {
+		switch token.TokenType(tok.Type) {
+		case token.ObjectIdentifier:
+			assert.Equal(t, "OBJECT\n    IDENTIFIER", tok.Raw)
+		case token.Text:
+			assert.Equal(t, "\"Two\n\t    lines\"", tok.Raw)
+		case token.HexString:
+			assert.Equal(t, "'ff'h", tok.Raw)
+		}
+	}
+
+	// Renaming a descriptor only touches its tokens
+	idents := f.Idents("replayRoot")
+	require.Len(t, idents, 2)
+	for _, i := range idents {
+		f.Tokens[i].Raw = "newRoot"
+	}
+	i, ok := f.TokenAt(f.Tokens[idents[0]].Pos.Offset)
+	assert.True(t, ok)
+	assert.Equal(t, idents[0], i)
+	_, ok = f.TokenAt(f.Tokens[idents[0]].Pos.Offset + 1)
+	assert.False(t, ok)
+	assert.Equal(t, `-- A module
+REPLAY-MIB DEFINITIONS ::= BEGIN
+newRoot  OBJECT
+    IDENTIFIER ::= { iso 9 } -- the root
+
+replayObject OBJECT-TYPE
+	SYNTAX OCTET STRING (SIZE (0..'ff'h))
+	DESCRIPTION "Two
+	    lines"
+	::= { newRoot 1 }
+END
+-- trailing`, string(f.Bytes()))
+}
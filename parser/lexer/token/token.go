@@ -21,7 +21,9 @@ const (
 
 	// Literals
 	Ident      // Identifier (keywords are also initially lexed as Ident)
-	Int        // Integer literal
+	Int        // Integer literal within the Integer32 and Unsigned32 ranges
+	Int64      // Negative integer literal below the Integer32 range
+	Uint64     // Integer literal above the Unsigned32 range
 	Text       // Double-quoted string "..."
 	HexString  // Hex string '...'H
 	BinString  // Binary string '...'B
@@ -79,6 +81,10 @@ func TokenTypeString(tt TokenType) string {
 		return "Ident"
 	case Int:
 		return "Int"
+	case Int64:
+		return "Int64"
+	case Uint64:
+		return "Uint64"
 	case Text:
 		return "Text"
 	case HexString:
@@ -133,6 +139,8 @@ var Symbols = map[TokenType]string{
 	Whitespace:       "Whitespace",
 	Ident:            "Ident",
 	Int:              "Int",
+	Int64:            "Int64",
+	Uint64:           "Uint64",
 	Text:             "Text",
 	HexString:        "HexString",
 	BinString:        "BinString",
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// quotedContent returns the digits of a HexString or BinString value such as
+// '0AF1'H and the base they are in.
+func quotedContent(value string) (digits string, base int, err error) {
+	if len(value) < 3 || value[0] != '\'' || value[len(value)-2] != '\'' {
+		return "", 0, fmt.Errorf("Invalid quoted string %q", value)
+	}
+	switch value[len(value)-1] {
+	case 'H', 'h':
+		base = 16
+	case 'B', 'b':
+		base = 2
+	default:
+		return "", 0, fmt.Errorf("Invalid quoted string suffix in %q", value)
+	}
+	return value[1 : len(value)-2], base, nil
+}
+
+// IsQuotedString reports whether value is a HexString or BinString value.
+func IsQuotedString(value string) bool {
+	_, _, err := quotedContent(value)
+	return err == nil
+}
+
+// DecodeHexString decodes a HexString value such as '0AF1'H. As in ASN.1, an
+// odd number of digits is completed with a trailing zero nibble, so '0AF'H is
+// 0x0A 0xF0. An empty HexString decodes to an empty, non-nil slice.
+func DecodeHexString(value string) ([]byte, error) {
+	digits, base, err := quotedContent(value)
+	if err != nil {
+		return nil, err
+	}
+	if base != 16 {
+		return nil, fmt.Errorf("Not a hex string: %q", value)
+	}
+	out := make([]byte, (len(digits)+1)/2)
+	for i := 0; i < len(digits); i++ {
+		n, ok := hexDigit(digits[i])
+		if !ok {
+			return nil, fmt.Errorf("Invalid hex digit %q in %q", digits[i], value)
+		}
+		if i%2 == 0 {
+			n <<= 4
+		}
+		out[i/2] |= n
+	}
+	return out, nil
+}
+
+// DecodeBinString decodes a BinString value such as '0110'B. As in ASN.1,
+// the bits are completed with trailing zero bits to a multiple of 8, so
+// '1'B is 0x80. An empty BinString decodes to an empty, non-nil slice.
+func DecodeBinString(value string) ([]byte, error) {
+	digits, base, err := quotedContent(value)
+	if err != nil {
+		return nil, err
+	}
+	if base != 2 {
+		return nil, fmt.Errorf("Not a binary string: %q", value)
+	}
+	out := make([]byte, (len(digits)+7)/8)
+	for i := 0; i < len(digits); i++ {
+		switch digits[i] {
+		case '0':
+		case '1':
+			out[i/8] |= 0x80 >> (i % 8)
+		default:
+			return nil, fmt.Errorf("Invalid binary digit %q in %q", digits[i], value)
+		}
+	}
+	return out, nil
+}
+
+// DecodeQuotedString decodes a HexString or BinString value, depending on
+// its suffix.
+func DecodeQuotedString(value string) ([]byte, error) {
+	_, base, err := quotedContent(value)
+	if err != nil {
+		return nil, err
+	}
+	if base == 16 {
+		return DecodeHexString(value)
+	}
+	return DecodeBinString(value)
+}
+
+// ParseUint64 parses a decimal, HexString or BinString value as a number.
+// Unlike when decoding them to bytes, quoted strings are read as big-endian
+// numbers without padding, so '1'H is 1 and an empty HexString is 0.
+func ParseUint64(value string) (uint64, error) {
+	if value == "" {
+		return 0, errors.New("Empty number")
+	}
+	if value[0] != '\'' {
+		return strconv.ParseUint(value, 10, 64)
+	}
+	digits, base, err := quotedContent(value)
+	if err != nil {
+		return 0, err
+	}
+	if digits == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(digits, base, 64)
+}
+
+// ParseInt64 is like ParseUint64, but also accepts negative decimal values.
+func ParseInt64(value string) (int64, error) {
+	if value != "" && value[0] == '-' {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	n, err := ParseUint64(value)
+	if err != nil {
+		return 0, err
+	}
+	if n > 1<<63-1 {
+		return 0, fmt.Errorf("Number %s is out of the Integer64 range", value)
+	}
+	return int64(n), nil
+}
+
+// Int64Bounds returns the start and end of the range as signed numbers. End
+// is the same as start for single values. Ranges using MIN or MAX cannot be
+// converted.
+func (r Range) Int64Bounds() (start, end int64, err error) {
+	if start, err = ParseInt64(r.Start); err != nil {
+		return
+	}
+	if r.End == "" {
+		return start, start, nil
+	}
+	end, err = ParseInt64(r.End)
+	return
+}
+
+// Uint64Bounds is like Int64Bounds for ranges of unsigned numbers and sizes.
+func (r Range) Uint64Bounds() (start, end uint64, err error) {
+	if start, err = ParseUint64(r.Start); err != nil {
+		return
+	}
+	if r.End == "" {
+		return start, start, nil
+	}
+	end, err = ParseUint64(r.End)
+	return
+}
+
+// DefvalBytes returns the DEFVAL of the object decoded to bytes. ok is false
+// if there is no DEFVAL or it is not a HexString or BinString.
+func (x ObjectType) DefvalBytes() (value []byte, ok bool) {
+	return defvalBytes(x.Defval)
+}
+
+// DefvalBytes returns the DEFVAL of the variation decoded to bytes. ok is
+// false if there is no DEFVAL or it is not a HexString or BinString.
+func (x AgentCapabilityVariation) DefvalBytes() (value []byte, ok bool) {
+	return defvalBytes(x.Defval)
+}
+
+func defvalBytes(defval *Defval) ([]byte, bool) {
+	if defval == nil || defval.Value == "" {
+		return nil, false
+	}
+	value, err := DecodeQuotedString(defval.Value)
+	return value, err == nil
+}
+
+func hexDigit(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
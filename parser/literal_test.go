@@ -0,0 +1,98 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/parser/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeQuotedString(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []byte
+		wantErr  bool
+	}{
+		{value: "'0AF1'H", expected: []byte{0x0a, 0xf1}},
+		{value: "'0af'H", expected: []byte{0x0a, 0xf0}},
+		{value: "''H", expected: []byte{}},
+		{value: "'0110'B", expected: []byte{0x60}},
+		{value: "'111100001'B", expected: []byte{0xf0, 0x80}},
+		{value: "''B", expected: []byte{}},
+		{value: "'0G'H", wantErr: true},
+		{value: "'012'B", wantErr: true},
+		{value: "'01'X", wantErr: true},
+		{value: "01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			b, err := parser.DecodeQuotedString(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, b)
+		})
+	}
+}
+
+func TestParseInt64(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{value: "42", expected: 42},
+		{value: "-9223372036854775808", expected: -9223372036854775808},
+		{value: "'FF'H", expected: 255},
+		{value: "'F'H", expected: 15},
+		{value: "''H", expected: 0},
+		{value: "'101'B", expected: 5},
+		{value: "'8000000000000000'H", wantErr: true},
+		{value: "MAX", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			n, err := parser.ParseInt64(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, n)
+		})
+	}
+}
+
+func TestLiteralBoundsAndDefval(t *testing.T) {
+	mod, err := parser.ParseBytes("test.mib", []byte(`TEST-MIB DEFINITIONS ::= BEGIN
+test OBJECT IDENTIFIER ::= { iso 1 }
+testObj OBJECT-TYPE
+    SYNTAX OCTET STRING (SIZE ('04'H | '0110'B..18446744073709551615))
+    MAX-ACCESS read-only
+    STATUS current
+    DEFVAL { 'C0A8'H }
+    ::= { test 1 }
+END`))
+	require.NoError(t, err)
+	node := testutil.FindNodeByName(t, mod, "testObj")
+	ranges := node.ObjectType.Syntax.Type.SubType.OctetString
+	require.Len(t, ranges, 2)
+
+	start, end, err := ranges[0].Uint64Bounds()
+	require.NoError(t, err)
+	assert.Equal(t, [2]uint64{4, 4}, [2]uint64{start, end})
+	start, end, err = ranges[1].Uint64Bounds()
+	require.NoError(t, err)
+	assert.Equal(t, [2]uint64{6, 18446744073709551615}, [2]uint64{start, end})
+	_, _, err = ranges[1].Int64Bounds()
+	assert.Error(t, err)
+
+	defval, ok := node.ObjectType.DefvalBytes()
+	require.True(t, ok)
+	assert.Equal(t, []byte{0xc0, 0xa8}, defval)
+}
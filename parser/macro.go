@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 
+	"github.com/alecthomas/participle/v2"
 	"github.com/alecthomas/participle/v2/lexer"
 
 	gosmilexer "github.com/lukeod/gosmi/parser/lexer" // Import the refactored lexer package
@@ -114,3 +115,98 @@ type Macro struct {
 	Name types.SmiIdentifier `parser:"@Ident \"MACRO\" Assign"`
 	Body MacroBody           `parser:"@@"`
 }
+
+// knownMacros are the macros the grammar parses invocations of.
+var knownMacros = map[string]bool{
+	"MACRO":              true,
+	"MODULE-IDENTITY":    true,
+	"OBJECT-IDENTITY":    true,
+	"OBJECT-TYPE":        true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-TYPE":  true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+	"TEXTUAL-CONVENTION": true,
+	"TRAP-TYPE":          true,
+}
+
+// MacroInvocation is a definition made with a macro the grammar does not
+// know, typically one defined by a vendor module. It is kept so that modules
+// using such macros parse, with the clauses as tokens.
+type MacroInvocation struct {
+	Pos lexer.Position
+
+	Name  types.SmiIdentifier
+	Macro types.SmiIdentifier
+	// Tokens are those between the macro name and the assignment, with
+	// Text tokens quoted again.
+	Tokens []string
+	// Oid is the assigned value if it is an OID, otherwise Value is.
+	Oid   *Oid
+	Value string
+}
+
+func (m *MacroInvocation) Parse(lex *lexer.PeekingLexer) error {
+	symbols := (&gosmilexer.LexerDefinition{}).Symbols()
+	identType := symbols["Ident"]
+	assignType := symbols["Assign"]
+	textType := symbols["Text"]
+
+	// Only "name MACRO-NAME ... ::=" is an invocation; leave anything else
+	// to the other productions
+	checkpoint := lex.MakeCheckpoint()
+	name, macro := lex.Next(), lex.Next()
+	if name.Type != identType || !isLower(name.Value) || macro.Type != identType || isLower(macro.Value) || knownMacros[macro.Value] {
+		lex.LoadCheckpoint(checkpoint)
+		return participle.NextMatch
+	}
+	var tokens []string
+	for token := lex.Next(); token.Type != assignType; token = lex.Next() {
+		if token.EOF() || token.Value == "END" {
+			lex.LoadCheckpoint(checkpoint)
+			return participle.NextMatch
+		}
+		if token.Type == textType {
+			tokens = append(tokens, `"`+token.Value+`"`)
+		} else {
+			tokens = append(tokens, token.Value)
+		}
+	}
+	// Without clauses, or with only a subtype, this is a value assignment
+	// unless an OID is assigned
+	if (len(tokens) == 0 || tokens[0] == "(" && tokens[len(tokens)-1] == ")") && lex.Peek().Value != "{" {
+		lex.LoadCheckpoint(checkpoint)
+		return participle.NextMatch
+	}
+	m.Pos = name.Pos
+	m.Name = types.SmiIdentifier(name.Value)
+	m.Macro = types.SmiIdentifier(macro.Value)
+	m.Tokens = tokens
+
+	value := lex.Next()
+	if value.EOF() {
+		return fmt.Errorf("unexpected EOF, expected value of %s", m.Name)
+	}
+	if value.Value != "{" {
+		m.Value = value.Value
+		return nil
+	}
+	m.Oid = &Oid{Pos: value.Pos}
+	for lex.Peek().Value != "}" {
+		var subId SubIdentifier
+		if err := subId.Parse(lex); err != nil {
+			return err
+		}
+		m.Oid.SubIdentifiers = append(m.Oid.SubIdentifiers, subId)
+	}
+	lex.Next()
+	if len(m.Oid.SubIdentifiers) == 0 {
+		return fmt.Errorf("empty OID for %s", m.Name)
+	}
+	return nil
+}
+
+func isLower(s string) bool {
+	return s != "" && s[0] >= 'a' && s[0] <= 'z'
+}
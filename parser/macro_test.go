@@ -300,3 +300,51 @@ func TestMacroParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestMacroInvocation(t *testing.T) {
+	input := `VENDOR-MIB DEFINITIONS ::= BEGIN
+IMPORTS enterprises FROM RFC1155-SMI
+	VENDOR-PRODUCT FROM VENDOR-SMI;
+
+vendor OBJECT IDENTIFIER ::= { enterprises 9999 }
+
+vendorRouter VENDOR-PRODUCT
+	PRODUCT-NAME "Router 9000"
+	FAMILY { routers, edge }
+	::= { vendor 1 }
+
+vendorLevel VENDOR-LEVEL
+	STATUS current
+	::= 3
+
+vendorSwitch OBJECT IDENTIFIER ::= { vendor 2 }
+END`
+	mod, err := parser.Parse("VENDOR-MIB", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, mod.Body.Nodes, 2)
+	assert.Equal(t, types.SmiIdentifier("vendorSwitch"), mod.Body.Nodes[1].Name)
+	require.Len(t, mod.Body.Invocations, 2)
+
+	router := mod.Body.Invocations[0]
+	assert.Equal(t, types.SmiIdentifier("vendorRouter"), router.Name)
+	assert.Equal(t, types.SmiIdentifier("VENDOR-PRODUCT"), router.Macro)
+	assert.Equal(t, []string{"PRODUCT-NAME", `"Router 9000"`, "FAMILY", "{", "routers", ",", "edge", "}"}, router.Tokens)
+	require.NotNil(t, router.Oid)
+	require.Len(t, router.Oid.SubIdentifiers, 2)
+	assert.Equal(t, types.SmiIdentifier("vendor"), *router.Oid.SubIdentifiers[0].Name)
+	assert.Equal(t, types.SmiSubId(1), *router.Oid.SubIdentifiers[1].Number)
+	assert.Equal(t, 7, router.Pos.Line)
+
+	level := mod.Body.Invocations[1]
+	assert.Equal(t, types.SmiIdentifier("VENDOR-LEVEL"), level.Macro)
+	assert.Nil(t, level.Oid)
+	assert.Equal(t, "3", level.Value)
+
+	// Invocations of known macros must still match their grammar
+	_, err = parser.Parse("BAD-MIB", strings.NewReader(`BAD-MIB DEFINITIONS ::= BEGIN
+bad OBJECT-IDENTITY
+	FOO "bar"
+	::= { iso 1 }
+END`))
+	assert.Error(t, err)
+}
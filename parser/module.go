@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/alecthomas/participle/v2/lexer"
@@ -8,14 +9,64 @@ import (
 	"github.com/lukeod/gosmi/types"
 )
 
+// Date is an ExtUTCTime value, as in LAST-UPDATED and REVISION clauses:
+// YYMMDDHHMMZ or YYYYMMDDHHMMZ.
 type Date string
 
-func (d Date) ToTime() (t time.Time) {
-	if len(d) == 11 {
-		t, _ = time.Parse("0601021504Z", string(d))
+// Time parses the date. Per RFC 2578 section 2, two-digit years are those of
+// 1900-1999; later dates must use the four-digit form.
+func (d Date) Time() (time.Time, error) {
+	s := string(d)
+	if len(s) != 11 && len(s) != 13 {
+		return time.Time{}, fmt.Errorf("Invalid date %q: expected YYMMDDHHMMZ or YYYYMMDDHHMMZ", s)
+	}
+	if s[len(s)-1] != 'Z' {
+		return time.Time{}, fmt.Errorf("Invalid date %q: expected Z suffix", s)
+	}
+	digits := s[:len(s)-1]
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return time.Time{}, fmt.Errorf("Invalid date %q: expected digits", s)
+		}
+	}
+	// Every field is two digits except for a four-digit year
+	field := func(i int) int { return int(digits[i]-'0')*10 + int(digits[i+1]-'0') }
+	var year int
+	if len(digits) == 10 {
+		year = 1900 + field(0)
+		digits = digits[2:]
 	} else {
-		t, _ = time.Parse("200601021504Z", string(d))
+		year = field(0)*100 + field(2)
+		digits = digits[4:]
+	}
+	month, day, hour, minute := field(0), field(2), field(4), field(6)
+	switch {
+	case month < 1 || month > 12:
+		return time.Time{}, fmt.Errorf("Invalid date %q: month %d out of range", s, month)
+	case day < 1 || day > daysIn(time.Month(month), year):
+		return time.Time{}, fmt.Errorf("Invalid date %q: day %d out of range", s, day)
+	case hour > 23:
+		return time.Time{}, fmt.Errorf("Invalid date %q: hour %d out of range", s, hour)
+	case minute > 59:
+		return time.Time{}, fmt.Errorf("Invalid date %q: minute %d out of range", s, minute)
 	}
+	return time.Date(year, time.Month(month), day, hour, minute, 0, 0, time.UTC), nil
+}
+
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Validate reports whether the date is well formed and names an existing
+// month, day, hour and minute.
+func (d Date) Validate() error {
+	_, err := d.Time()
+	return err
+}
+
+// ToTime is like Time, returning the zero time for invalid dates.
+func (d Date) ToTime() (t time.Time) {
+	t, _ = d.Time()
 	return
 }
 
@@ -41,6 +92,20 @@ type Node struct {
 	Oid               *Oid                `parser:"Assign \"{\" @@+ \"}\" )"` // Changed @@ to @@+
 	TrapType          *TrapType           `parser:"| ( ( \"TRAP-TYPE\" @@ )"`
 	SubIdentifier     *types.SmiSubId     `parser:"Assign @Int ) )"`
+	// Extensions are the clauses of dialects in the macro defining the node.
+	Extensions []Extension
+}
+
+// ValueAssignment assigns a value of a type other than OBJECT IDENTIFIER,
+// such as someInteger INTEGER ::= 5.
+type ValueAssignment struct {
+	Pos lexer.Position
+
+	Name types.SmiIdentifier `parser:"@Ident"`
+	// Not a known macro, so that errors in those are reported as such
+	Syntax SyntaxType `parser:"(?! \"OBJECT-TYPE\" | \"OBJECT-IDENTITY\" | \"OBJECT-GROUP\" | \"NOTIFICATION-TYPE\" | \"NOTIFICATION-GROUP\" | \"MODULE-COMPLIANCE\" | \"AGENT-CAPABILITIES\" | \"TRAP-TYPE\" | \"MODULE-IDENTITY\" | \"MACRO\" ) @@ Assign"`
+	// Value is the literal as written, with Text unquoted.
+	Value string `parser:"@( Int | Int64 | Uint64 | BinString | HexString | Text | Ident )"`
 }
 
 type Revision struct {
@@ -60,17 +125,25 @@ type ModuleIdentity struct {
 	Description  string              `parser:"\"DESCRIPTION\" @Text"`        // Required
 	Revisions    []Revision          `parser:"( \"REVISION\" @@ )*"`
 	Oid          Oid                 `parser:"Assign \"{\" @@ \"}\""`
+	// Extensions are the clauses of dialects in MODULE-IDENTITY.
+	Extensions []Extension
 }
 
 type ModuleBody struct {
 	Pos lexer.Position
 
-	Imports  []Import              `parser:"( \"IMPORTS\" @@+ \";\" )?"`
-	Exports  []types.SmiIdentifier `parser:"( \"EXPORTS\"  @Ident ( \",\" @Ident )* \";\" )?"`
-	Identity *ModuleIdentity       `parser:"( @@"`
-	Types    []Type                `parser:"| @@"`
-	Nodes    []Node                `parser:"| @@"`
-	Macros   []Macro               `parser:"| @@ )*"`
+	Imports []Import              `parser:"( \"IMPORTS\" @@+ \";\" )?"`
+	Exports []types.SmiIdentifier `parser:"( \"EXPORTS\"  @Ident ( \",\" @Ident )* \";\" )?"`
+	// Guard enforces the timeout and MaxNodes before each definition.
+	Guard    DefinitionGuard `parser:"( @@" json:"-"`
+	Identity *ModuleIdentity `parser:"| @@"`
+	Types    []Type          `parser:"| @@"`
+	Nodes    []Node          `parser:"| @@"`
+	Macros   []Macro         `parser:"| @@"`
+	// Invocations are definitions made with macros the grammar does not
+	// know.
+	Invocations []MacroInvocation `parser:"| @@"`
+	Values      []ValueAssignment `parser:"| @@ )*"`
 }
 
 type Module struct {
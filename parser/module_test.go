@@ -269,3 +269,72 @@ END
 	require.Len(t, mod.Body.Imports[0].Names, 1)
 	assert.Equal(t, types.SmiIdentifier("someObject"), mod.Body.Imports[0].Names[0])
 }
+
+func TestDateValidate(t *testing.T) {
+	tests := []struct {
+		date    parser.Date
+		want    string
+		wantErr string
+	}{
+		{date: "9505241811Z", want: "1995-05-24T18:11:00Z"},
+		{date: "0501011200Z", want: "1905-01-01T12:00:00Z"},
+		{date: "202402291200Z", want: "2024-02-29T12:00:00Z"},
+		{date: "202302291200Z", wantErr: "day 29 out of range"},
+		{date: "202413011200Z", wantErr: "month 13 out of range"},
+		{date: "202400011200Z", wantErr: "month 0 out of range"},
+		{date: "202401012400Z", wantErr: "hour 24 out of range"},
+		{date: "202401011260Z", wantErr: "minute 60 out of range"},
+		{date: "2024010112Z", wantErr: "month 24 out of range"},
+		{date: "20240101Z", wantErr: "expected YYMMDDHHMMZ or YYYYMMDDHHMMZ"},
+		{date: "2024010112000", wantErr: "expected Z suffix"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.date), func(t *testing.T) {
+			err := tt.date.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.True(t, tt.date.ToTime().IsZero())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tt.date.ToTime().Format(time.RFC3339))
+		})
+	}
+}
+
+func TestValueAssignment(t *testing.T) {
+	input := `VALUE-MIB DEFINITIONS ::= BEGIN
+someInteger INTEGER ::= 5
+negative Integer32 ::= -3
+someString DisplayString ::= "a string"
+ranged INTEGER (0..10) ::= 7
+bytes OCTET STRING ::= 'ff01'H
+flag TruthValue ::= true
+zeroDotZero OBJECT IDENTIFIER ::= { 0 0 }
+END`
+	mod, err := parser.Parse("VALUE-MIB", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, mod.Body.Nodes, 1)
+	assert.Empty(t, mod.Body.Invocations)
+
+	tests := []struct {
+		name, syntax, value string
+	}{
+		{"someInteger", "INTEGER", "5"},
+		{"negative", "Integer32", "-3"},
+		{"someString", "DisplayString", "a string"},
+		{"ranged", "INTEGER", "7"},
+		{"bytes", "OCTET STRING", "'FF01'H"},
+		{"flag", "TruthValue", "true"},
+	}
+	require.Len(t, mod.Body.Values, len(tests))
+	for i, tt := range tests {
+		v := mod.Body.Values[i]
+		assert.Equal(t, types.SmiIdentifier(tt.name), v.Name)
+		assert.Equal(t, types.SmiIdentifier(tt.syntax), v.Syntax.Name)
+		assert.Equal(t, tt.value, v.Value)
+		assert.Equal(t, i+2, v.Pos.Line)
+	}
+	require.NotNil(t, mod.Body.Values[3].Syntax.SubType)
+}
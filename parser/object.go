@@ -27,10 +27,10 @@ type Access string
 
 const (
 	// In order from least to greatest
-	AccessWriteOnly           Access = "write-only" // Do not use
+	AccessWriteOnly           Access = "write-only" // SMIv1 only
 	AccessNotImplemented      Access = "not-implemented"
 	AccessNotAccessible       Access = "not-accessible"
-	AccessAccessibleForNotify Access = "accesible-for-notify"
+	AccessAccessibleForNotify Access = "accessible-for-notify"
 	AccessReadOnly            Access = "read-only"
 	AccessReadWrite           Access = "read-write"
 	AccessReadCreate          Access = "read-create"
@@ -39,7 +39,7 @@ const (
 func (a Access) ToSmi() types.Access {
 	switch a {
 	case AccessWriteOnly:
-		return types.AccessUnknown // What should this be?
+		return types.AccessWriteOnly
 	case AccessNotImplemented:
 		return types.AccessNotImplemented
 	case AccessNotAccessible:
@@ -66,11 +66,12 @@ type ObjectType struct {
 
 	Syntax      Syntax               `parser:"\"SYNTAX\" @@"` // Required
 	Units       string               `parser:"( \"UNITS\" @Text )?"`
-	Access      Access               `parser:"( \"ACCESS\" | \"MAX-ACCESS\" ) @( \"write-only\" | \"not-accessible\" | \"accessible-for-notify\" | \"read-only\" | \"read-write\" | \"read-create\" )"` // Required
-	Status      Status               `parser:"\"STATUS\" @( \"mandatory\" | \"optional\" | \"current\" | \"deprecated\" | \"obsolete\" )"`                                                              // Required
-	Description string               `parser:"( \"DESCRIPTION\" @Text )?"`                                                                                                                              // Required RFC 1212+
+	MaxAccess   bool                 `parser:"( \"ACCESS\" | @\"MAX-ACCESS\" )"`                                                                                        // SMIv2 keyword, ACCESS in SMIv1
+	Access      Access               `parser:"@( \"write-only\" | \"not-accessible\" | \"accessible-for-notify\" | \"read-only\" | \"read-write\" | \"read-create\" )"` // Required
+	Status      Status               `parser:"\"STATUS\" @( \"mandatory\" | \"optional\" | \"current\" | \"deprecated\" | \"obsolete\" )"`                              // Required
+	Description string               `parser:"( \"DESCRIPTION\" @Text )?"`                                                                                              // Required RFC 1212+
 	Reference   string               `parser:"( \"REFERENCE\" @Text )?"`
 	Index       []Index              `parser:"( ( \"INDEX\" \"{\" @@ ( \",\" @@ )* \"}\" )"` // Required for "row" without AUGMENTS
 	Augments    *types.SmiIdentifier `parser:"| ( \"AUGMENTS\" \"{\" @Ident \"}\" ) )?"`     // Required for "row" without INDEX
-	Defval      *string              `parser:"( \"DEFVAL\" \"{\" @( \"-\"? Int | BinString | HexString | Text | Ident | ( \"{\" \"}\" | \"{\" ( Int+ | Ident ( \",\" Ident )* ) \"}\" ) ) \"}\" )?"`
+	Defval      *Defval              `parser:"( \"DEFVAL\" \"{\" @@ \"}\" )?"`
 }
@@ -263,7 +263,7 @@ func TestObjectParsing(t *testing.T) {
 				assert.Equal(t, types.SmiIdentifier("Integer32"), valueOT.Syntax.Type.Name, "evalValue SYNTAX name mismatch")
 				assert.Equal(t, parser.AccessReadOnly, valueOT.Access, "evalValue MAX-ACCESS mismatch")
 				require.NotNil(t, valueOT.Defval, "evalValue DEFVAL is nil")
-				assert.Equal(t, "0", *valueOT.Defval, "evalValue DEFVAL value mismatch")
+				assert.Equal(t, "0", valueOT.Defval.Value, "evalValue DEFVAL value mismatch")
 
 				// Check evalStatus (Column with DEFVAL named number)
 				statusNode, ok := nodes["evalStatus"]
@@ -272,8 +272,8 @@ func TestObjectParsing(t *testing.T) {
 				statusOT := statusNode.ObjectType
 				assert.Equal(t, types.SmiIdentifier("RowStatus"), statusOT.Syntax.Type.Name, "evalStatus SYNTAX name mismatch")
 				assert.Equal(t, parser.AccessReadCreate, statusOT.Access, "evalStatus MAX-ACCESS mismatch")
-				require.NotNil(t, statusOT.Defval, "evalStatus DEFVAL is nil")                  // Corrected case: Defval
-				assert.Equal(t, "active", *statusOT.Defval, "evalStatus DEFVAL value mismatch") // Compare string value
+				require.NotNil(t, statusOT.Defval, "evalStatus DEFVAL is nil")                       // Corrected case: Defval
+				assert.Equal(t, "active", statusOT.Defval.Value, "evalStatus DEFVAL value mismatch") // Compare string value
 			},
 		},
 		{
@@ -1,43 +1,398 @@
 package parser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath" // Added for file path manipulation
+	"time"
 
 	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
 	gosmilexer "github.com/lukeod/gosmi/parser/lexer" // Import the refactored lexer package
 )
 
 var (
 	// Removed: compressSpace = regexp.MustCompile(`(?:\r?\n *)+`)
 	smiParser = participle.MustBuild[Module](
-		participle.Lexer(&gosmilexer.LexerDefinition{}), // Pass a pointer to the refactored lexer definition
-		participle.Unquote("ExtUTCTime"),                // Keep for now, might be redundant depending on parser needs
+		// Canonical mode unquotes ExtUTCTime and upper-cases ExtUTCTime,
+		// BinString and HexString in the lexer itself. participle.Unquote and
+		// participle.Upper would wrap the definition and disable LexBytes.
+		participle.Lexer(&gosmilexer.LexerDefinition{Canonical: true}),
 		// Removed Map for ObjectIdentifier - handled by handwritten lexer
 		// Removed Map for OctetString - handled by handwritten lexer
 		// Removed Map for Text - whitespace compression and unquoting now handled directly in lexer's lexText function
-		participle.Upper("ExtUTCTime", "BinString", "HexString"), // Keep for consistency if parser expects uppercase
 		// Removed Elide("Whitespace", "Comment") - handled by handwritten lexer's NextToken loop
+		participle.UseLookahead(lookahead),
 	)
 )
 
+// lookahead is the number of tokens the parser looks ahead, which a
+// DefinitionGuard failing the parse skips past.
+const lookahead = 1
+
+// Option configures Parse, ParseBytes and ParseFile.
+type Option func(*parseConfig)
+
+type parseConfig struct {
+	ctx         context.Context
+	diagnostics *diag.Collector
+	limits      Limits
+	preParse    func(filename string, src []byte) ([]byte, error)
+	strict      bool
+	skipText    bool
+	whitespace  TextWhitespace
+	escapes     bool
+	wrapped     bool
+	recovery    bool
+	timing      *Timing
+	dialects    []string
+}
+
+func newParseConfig(opts []Option) parseConfig {
+	var cfg parseConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithContext cancels parsing when ctx is done, in which case the parse fails
+// with the context's error.
+func WithContext(ctx context.Context) Option {
+	return func(cfg *parseConfig) { cfg.ctx = ctx }
+}
+
+// WithDiagnostics reports lexical and syntax errors to c, attributed to the
+// module being parsed, instead of printing lexical errors.
+func WithDiagnostics(c *diag.Collector) Option {
+	return func(cfg *parseConfig) { cfg.diagnostics = c }
+}
+
+// Limits bounds the resources parsing a module may take, so that services
+// parsing untrusted input cannot be exhausted by pathological modules. Zero
+// fields are unlimited. Exceeding a limit fails the parse with a
+// *diag.LimitExceededError.
+type Limits struct {
+	// MaxBytes limits the size of the input.
+	MaxBytes int
+	// MaxTokens limits the number of tokens, not counting whitespace and
+	// comments.
+	MaxTokens int
+	// MaxNodes limits the number of definitions in the module: types,
+	// macros, value assignments, macro invocations and nodes.
+	MaxNodes int
+	// MaxNesting limits the depth to which braces and parentheses nest.
+	MaxNesting int
+	// Timeout limits the time lexing and parsing may take, which bounds
+	// pathological backtracking. It is reported as a limit in milliseconds.
+	Timeout time.Duration
+}
+
+// WithLimits enforces limits while parsing.
+func WithLimits(limits Limits) Option {
+	return func(cfg *parseConfig) { cfg.limits = limits }
+}
+
+// WithPreParse rewrites the input with f before it is lexed, once checked
+// against the MaxBytes limit. An error of f fails the parse.
+func WithPreParse(f func(filename string, src []byte) ([]byte, error)) Option {
+	return func(cfg *parseConfig) { cfg.preParse = f }
+}
+
+// Timing is the time the phases of a parse took.
+type Timing struct {
+	// Lex is the time taken by lexing the input into tokens, which is done
+	// before parsing.
+	Lex time.Duration
+	// Parse is the time taken by parsing the tokens.
+	Parse time.Duration
+}
+
+// WithTiming records the time lexing and parsing took in t.
+func WithTiming(t *Timing) Option {
+	return func(cfg *parseConfig) { cfg.timing = t }
+}
+
+// WithStructureOnly parses the structure of a module, its names, OIDs and
+// syntaxes, for jobs such as indexing many modules: the DESCRIPTION,
+// REFERENCE, ORGANIZATION and CONTACT-INFO texts are skipped over by the
+// lexer rather than normalized and retained, and are empty in the Module.
+func WithStructureOnly() Option {
+	return func(cfg *parseConfig) { cfg.skipText = true }
+}
+
+// TextWhitespace is how the whitespace of quoted strings is treated.
+type TextWhitespace = gosmilexer.TextWhitespace
+
+const (
+	// TextNormalized strips the leading and trailing whitespace of each
+	// line and compresses runs of spaces.
+	TextNormalized = gosmilexer.TextNormalized
+	// TextRaw keeps quoted strings as written.
+	TextRaw = gosmilexer.TextRaw
+	// TextLibsmi strips the indentation of continuation lines as libsmi
+	// does, keeping other whitespace as written.
+	TextLibsmi = gosmilexer.TextLibsmi
+)
+
+// WithTextWhitespace sets how the whitespace of quoted strings, and so of
+// the DESCRIPTION and other texts, is treated, TextNormalized by default.
+func WithTextWhitespace(whitespace TextWhitespace) Option {
+	return func(cfg *parseConfig) { cfg.whitespace = whitespace }
+}
+
+// WithEscapes interprets backslash escapes in quoted strings, which SMI does
+// not have but some modules use: \" does not end the string, and normalized
+// texts keep the escaped character only. Without it a backslash is an
+// ordinary character and a quote always ends the string.
+func WithEscapes() Option {
+	return func(cfg *parseConfig) { cfg.escapes = true }
+}
+
+// WithWrappedQuoted accepts hex and bin strings with whitespace and line
+// breaks between their digits, such as '0A0B\n0C'H, which some generators
+// wrap long values in, reporting a warning for each. The whitespace is
+// stripped from the value. Otherwise they are lexical errors.
+func WithWrappedQuoted() Option {
+	return func(cfg *parseConfig) { cfg.wrapped = true }
+}
+
 // Parse function needs filename argument for v2
-func Parse(filename string, r io.Reader) (*Module, error) {
-	// Update Parse call signature - Parse now returns the struct and error
-	return smiParser.Parse(filename, r)
+func Parse(filename string, r io.Reader, opts ...Option) (*Module, error) {
+	b, err := readInput(r, newParseConfig(opts).limits)
+	if err != nil {
+		return nil, fmt.Errorf("Read input: %w", err)
+	}
+	return ParseBytes(filename, b, opts...)
+}
+
+// readInput reads r, stopping one byte beyond the size limit, which is
+// enough for ParseBytes to reject it.
+func readInput(r io.Reader, limits Limits) ([]byte, error) {
+	if limits.MaxBytes > 0 {
+		r = io.LimitReader(r, int64(limits.MaxBytes)+1)
+	}
+	return io.ReadAll(r)
+}
+
+// ParseBytes parses the module in b without copying it. Identifiers and other
+// strings in the returned Module share memory with b, so b must not be
+// modified afterwards. Any one of them held keeps the whole of b from being
+// freed, so strings kept after the Module is dropped should be copied, as
+// the smi package does for the modules it builds when ASTs are discarded.
+//
+// Malformed input never makes ParseBytes, Parse or ParseFile panic. Should
+// the parser hit a bug, the panic is returned as a *diag.PanicError.
+func ParseBytes(filename string, b []byte, opts ...Option) (*Module, error) {
+	cfg := newParseConfig(opts)
+	if max := cfg.limits.MaxBytes; max > 0 && len(b) > max {
+		err := &diag.LimitExceededError{Pos: diag.Position{Filename: filename}, Limit: "bytes", Max: max}
+		cfg.diagnostics.Add(syntaxDiagnostic(filename, err))
+		return nil, err
+	}
+	if cfg.preParse != nil {
+		var err error
+		if b, err = cfg.preParse(filename, b); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := cfg.ctx
+	if timeout := cfg.limits.Timeout; timeout > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	def := &gosmilexer.LexerDefinition{
+		Canonical:      true,
+		MaxTokens:      cfg.limits.MaxTokens,
+		MaxNesting:     cfg.limits.MaxNesting,
+		Context:        ctx,
+		SkipText:       cfg.skipText,
+		TextWhitespace: cfg.whitespace,
+		Escapes:        cfg.escapes,
+		WrappedQuoted:  cfg.wrapped,
+	}
+	// Collect locally first, so the diagnostics can be attributed to the
+	// module once its name is known
+	var local diag.Collector
+	if cfg.diagnostics != nil {
+		def.Diagnostics = &local
+	}
+	lex, err := def.LexBytes(filename, b)
+	if err != nil {
+		return nil, err
+	}
+	var dialect *dialectLexer
+	if len(cfg.dialects) > 0 {
+		clauses, err := lookupDialects(cfg.dialects)
+		if err != nil {
+			return nil, err
+		}
+		dialect = &dialectLexer{lex: lex.(*gosmilexer.Lexer), src: string(b), clauses: clauses}
+		lex = dialect
+	}
+	if cfg.recovery {
+		lex = &recoveryLexer{Lexer: lex, diagnostics: &local}
+	}
+	var strict *strictLexer
+	if cfg.strict {
+		strict = &strictLexer{Lexer: lex}
+		lex = strict
+	}
+	var module *Module
+	var timing Timing
+	err = protect(filename, func() error {
+		start := time.Now()
+		peeker, err := lexer.Upgrade(lex)
+		timing.Lex = time.Since(start)
+		if err != nil {
+			return err
+		}
+		start = time.Now()
+		module, err = parseTokens(ctx, filename, peeker, cfg.limits.MaxNodes)
+		timing.Parse = time.Since(start)
+		return err
+	})
+	if cfg.timing != nil {
+		*cfg.timing = timing
+	}
+	// The deadline of the timeout, rather than that of the caller's context
+	if timeout := cfg.limits.Timeout; timeout > 0 && errors.Is(err, context.DeadlineExceeded) && (cfg.ctx == nil || cfg.ctx.Err() == nil) {
+		module = nil
+		err = &diag.LimitExceededError{Pos: diag.Position{Filename: filename}, Limit: "milliseconds", Max: int(timeout.Milliseconds())}
+	}
+	if err == nil && strict != nil && strict.err != nil {
+		err = strict.err
+	}
+	if err == nil && dialect != nil {
+		dialect.attach(module)
+	}
+	if cfg.diagnostics == nil {
+		return module, err
+	}
+
+	// Cancellation says nothing about the module
+	if err != nil && !isContextError(err) {
+		local.Add(syntaxDiagnostic(filename, err))
+	}
+	var name string
+	if module != nil {
+		name = module.Name.String()
+	}
+	for _, d := range local.Diagnostics() {
+		d.Module = name
+		cfg.diagnostics.Add(d)
+	}
+	return module, err
+}
+
+// parseTokens parses the module from the tokens of peeker, giving up once ctx
+// is done or the module has more than maxNodes definitions, if maxNodes is
+// set. Both are checked before each definition, so a parse given up on while
+// it backtracks within a definition stops in the background once it gets
+// to the next one.
+func parseTokens(ctx context.Context, filename string, peeker *lexer.PeekingLexer, maxNodes int) (*Module, error) {
+	state := &guardState{ctx: ctx, filename: filename, maxNodes: maxNodes}
+	parse := func() (*Module, error) {
+		defer guard(peeker, state)()
+		module, err := smiParser.ParseFromLexer(peeker)
+		if state.err != nil {
+			return nil, state.err
+		}
+		return module, err
+	}
+	if ctx == nil || ctx.Done() == nil {
+		return parse()
+	}
+	type result struct {
+		module *Module
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		r.err = protect(filename, func() (err error) {
+			r.module, err = parse()
+			return err
+		})
+		done <- r
+	}()
+	select {
+	case r := <-done:
+		return r.module, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// protect calls f, returning a panic as a *diag.PanicError, so that no input
+// can crash the caller.
+func protect(filename string, f func() error) (err error) {
+	defer diag.Recover(filename, &err)
+	return f()
+}
+
+func syntaxDiagnostic(filename string, err error) diag.Diagnostic {
+	d := diag.Diagnostic{
+		Severity: diag.SeverityError,
+		Pos:      diag.Position{Filename: filename},
+		Code:     diag.CodeSyntax,
+		Message:  err.Error(),
+	}
+	var panicErr *diag.PanicError
+	if errors.As(err, &panicErr) {
+		d.Code = diag.CodeParserPanic
+		return d
+	}
+	var strictErr *StrictError
+	if errors.As(err, &strictErr) {
+		d.Code = diag.CodeNonConformant
+	}
+	var limitErr *diag.LimitExceededError
+	if errors.As(err, &limitErr) {
+		d.Pos = limitErr.Pos
+		d.Code = diag.CodeLimitExceeded
+		d.Message = limitErr.Message()
+		return d
+	}
+	var perr participle.Error
+	if errors.As(err, &perr) {
+		pos := perr.Position()
+		d.Pos = diag.Position{
+			Filename: filename,
+			Offset:   pos.Offset,
+			Line:     pos.Line,
+			Column:   pos.Column,
+		}
+		d.Message = perr.Message()
+	}
+	return d
 }
 
 // ParseFile already has filename, update Parse call inside
-func ParseFile(path string) (*Module, error) {
-	r, err := os.Open(path)
+func ParseFile(path string, opts ...Option) (*Module, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("Open file: %w", err)
+		return nil, fmt.Errorf("Read file: %w", err)
 	}
-	defer r.Close()
-	// Pass filename to Parse
-	module, err := Parse(path, r)
+	defer f.Close()
+	b, err := readInput(f, newParseConfig(opts).limits)
+	if err != nil {
+		return nil, fmt.Errorf("Read file: %w", err)
+	}
+	module, err := ParseBytes(path, b, opts...)
 	if err != nil {
 		// Add filename to error context if helpful
 		return module, fmt.Errorf("Parse file %q: %w", path, err)
@@ -45,6 +400,25 @@ func ParseFile(path string) (*Module, error) {
 	return module, nil
 }
 
+// ParseFileWithLimits parses the module at path within limits. Unlike
+// ParseFile with WithLimits, a file larger than MaxBytes is refused before it
+// is read. Exceeding a limit, including the Timeout, fails the parse with a
+// *diag.LimitExceededError.
+func ParseFileWithLimits(path string, limits Limits, opts ...Option) (*Module, error) {
+	if max := limits.MaxBytes; max > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("Read file: %w", err)
+		}
+		if info.Size() > int64(max) {
+			err := &diag.LimitExceededError{Pos: diag.Position{Filename: path}, Limit: "bytes", Max: max}
+			newParseConfig(opts).diagnostics.Add(syntaxDiagnostic(path, err))
+			return nil, fmt.Errorf("Parse file %q: %w", path, err)
+		}
+	}
+	return ParseFile(path, append(opts[:len(opts):len(opts)], WithLimits(limits))...)
+}
+
 // LoadMibTree parses the MIB file at rootMibPath and recursively loads all its dependencies
 // found within the specified mibDirs. It returns a map of module names to their parsed ASTs.
 func LoadMibTree(rootMibPath string, mibDirs []string) (map[string]*Module, error) {
@@ -1,12 +1,17 @@
 package parser_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/types"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +22,7 @@ func TestParse(t *testing.T) {
 	tests := []struct {
 		name    string
 		input   string // Input for parser.Parse
+		opts    []parser.Option
 		wantErr bool
 		check   func(t *testing.T, mod *parser.Module)
 	}{
@@ -45,6 +51,7 @@ testIdentity MODULE-IDENTITY
 
 END
 `,
+			opts:    []parser.Option{parser.WithEscapes()}, // For the \" in the description
 			wantErr: false,
 			check: func(t *testing.T, mod *parser.Module) {
 				require.NotNil(t, mod.Body.Identity, "Parsed module identity is nil")
@@ -81,6 +88,7 @@ testObject OBJECT-TYPE
 
 END
 `,
+			opts:    []parser.Option{parser.WithEscapes()},
 			wantErr: false,
 			check: func(t *testing.T, mod *parser.Module) {
 				require.Len(t, mod.Body.Nodes, 1, "Expected 1 node")
@@ -241,7 +249,7 @@ END
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Use parser.Parse directly to test error handling
-			mod, err := parser.Parse(tt.name+".mib", strings.NewReader(tt.input)) // Provide a dummy filename
+			mod, err := parser.Parse(tt.name+".mib", strings.NewReader(tt.input), tt.opts...) // Provide a dummy filename
 
 			if tt.wantErr {
 				require.Error(t, err, "Expected an error but got none")
@@ -339,3 +347,251 @@ END
 		})
 	}
 }
+
+func TestParseLimits(t *testing.T) {
+	input := `LIMIT-MIB DEFINITIONS ::= BEGIN
+limitRoot OBJECT IDENTIFIER ::= { iso 1 }
+limitNode OBJECT IDENTIFIER ::= { limitRoot 1 }
+END
+`
+	tests := []struct {
+		name    string
+		limits  parser.Limits
+		limit   string
+		line    int
+		wantErr bool
+	}{
+		{name: "Within limits", limits: parser.Limits{MaxBytes: len(input), MaxTokens: 19, MaxNodes: 2, MaxNesting: 1}},
+		{name: "Bytes", limits: parser.Limits{MaxBytes: len(input) - 1}, limit: "bytes", wantErr: true},
+		{name: "Tokens", limits: parser.Limits{MaxTokens: 18}, limit: "tokens", line: 4, wantErr: true},
+		{name: "Nodes", limits: parser.Limits{MaxNodes: 1}, limit: "nodes", line: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diagnostics diag.Collector
+			_, err := parser.Parse("limit.mib", strings.NewReader(input), parser.WithLimits(tt.limits), parser.WithDiagnostics(&diagnostics))
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Zero(t, diagnostics.Len())
+				return
+			}
+			var limitErr *diag.LimitExceededError
+			require.ErrorAs(t, err, &limitErr)
+			assert.Equal(t, tt.limit, limitErr.Limit)
+			assert.Equal(t, tt.line, limitErr.Pos.Line)
+			require.Equal(t, 1, diagnostics.Len())
+			d := diagnostics.Diagnostics()[0]
+			assert.Equal(t, diag.CodeLimitExceeded, d.Code)
+			assert.Equal(t, limitErr.Message(), d.Message)
+		})
+	}
+
+	nested := "NEST-MIB DEFINITIONS ::= BEGIN\nx ::= INTEGER { a(1) }\nEND\n"
+	_, err := parser.ParseBytes("nest.mib", []byte(nested), parser.WithLimits(parser.Limits{MaxNesting: 1}))
+	var limitErr *diag.LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, diag.Position{Filename: "nest.mib", Offset: 48, Line: 2, Column: 18}, limitErr.Pos)
+	assert.EqualError(t, err, "nest.mib:2:18: Module exceeds the limit of 1 nesting levels")
+}
+
+func TestParseFileWithLimits(t *testing.T) {
+	input := "LIMIT-MIB DEFINITIONS ::= BEGIN\n" + strings.Repeat("limitNode OBJECT IDENTIFIER ::= { iso 1 }\n", 100) + "END\n"
+	path := filepath.Join(t.TempDir(), "LIMIT-MIB")
+	require.NoError(t, os.WriteFile(path, []byte(input), 0o644))
+
+	module, err := parser.ParseFileWithLimits(path, parser.Limits{MaxBytes: len(input), Timeout: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, types.SmiIdentifier("LIMIT-MIB"), module.Name)
+
+	var diagnostics diag.Collector
+	_, err = parser.ParseFileWithLimits(path, parser.Limits{MaxBytes: 10}, parser.WithDiagnostics(&diagnostics))
+	var limitErr *diag.LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "bytes", limitErr.Limit)
+	assert.Equal(t, 1, diagnostics.Len())
+
+	module, err = parser.ParseFileWithLimits(path, parser.Limits{Timeout: time.Nanosecond})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "milliseconds", limitErr.Limit)
+	assert.Nil(t, module)
+
+	// The caller's deadline is not a limit
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	_, err = parser.ParseFileWithLimits(path, parser.Limits{Timeout: time.Minute}, parser.WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, errors.As(err, &limitErr))
+
+	_, err = parser.ParseFileWithLimits(filepath.Join(t.TempDir(), "MISSING"), parser.Limits{MaxBytes: 10})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestParseContext(t *testing.T) {
+	input := "CTX-MIB DEFINITIONS ::= BEGIN\n" + strings.Repeat("ctxNode OBJECT IDENTIFIER ::= { iso 1 }\n", 100) + "END\n"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var diagnostics diag.Collector
+	module, err := parser.Parse("ctx.mib", strings.NewReader(input), parser.WithContext(ctx), parser.WithDiagnostics(&diagnostics))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, module)
+	assert.Zero(t, diagnostics.Len())
+
+	_, err = parser.Parse("ctx.mib", strings.NewReader(input), parser.WithContext(context.Background()))
+	assert.NoError(t, err)
+}
+
+func TestParseContextStopsParse(t *testing.T) {
+	// Takes seconds to parse, far longer than the parse is given
+	input := "CTX-MIB DEFINITIONS ::= BEGIN\n" + strings.Repeat("ctxNode OBJECT IDENTIFIER ::= { iso 1 }\n", 100000) + "END\n"
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	_, err := parser.Parse("ctx.mib", strings.NewReader(input), parser.WithContext(ctx))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// assert.Eventually runs its condition in a goroutine of its own
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the parse to stop once its context is done, %d goroutines left of %d", runtime.NumGoroutine(), before)
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		line    int
+	}{
+		{name: "Conformant", body: "x OBJECT IDENTIFIER ::= { iso 1 }"},
+		{name: "Enum trailing comma", body: "X ::= INTEGER { up(1), down(2), }", message: `Trailing comma before "}"`, line: 3},
+		{name: "Bits trailing comma", body: "x OBJECT-TYPE SYNTAX BITS { a(0) } MAX-ACCESS read-only STATUS current DEFVAL { { a, } } ::= { iso 1 }", message: `Trailing comma before "}"`, line: 3},
+		{name: "Non-ASCII identifier", body: "xé OBJECT IDENTIFIER ::= { iso 1 }", message: `Identifier xé has the non-ASCII letter 'é'`, line: 3},
+		{name: "Identifier ending with a hyphen", body: "x- OBJECT IDENTIFIER ::= { iso 1 }", message: "Identifier x- ends with a hyphen", line: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "STRICT-MIB DEFINITIONS ::= BEGIN\n\n" + tt.body + "\nEND\n"
+			_, err := parser.ParseBytes("strict.mib", []byte(input))
+			require.NoError(t, err, "lenient by default")
+
+			var diagnostics diag.Collector
+			module, err := parser.ParseBytes("strict.mib", []byte(input), parser.WithStrict(), parser.WithDiagnostics(&diagnostics))
+			if tt.message == "" {
+				require.NoError(t, err)
+				assert.Equal(t, types.SmiIdentifier("STRICT-MIB"), module.Name)
+				assert.Zero(t, diagnostics.Len())
+				return
+			}
+			var strictErr *parser.StrictError
+			require.ErrorAs(t, err, &strictErr)
+			assert.Equal(t, tt.message, strictErr.Message())
+			assert.Equal(t, tt.line, strictErr.Pos.Line)
+			require.Equal(t, 1, diagnostics.Len())
+			d := diagnostics.Diagnostics()[0]
+			assert.Equal(t, diag.CodeNonConformant, d.Code)
+			assert.Equal(t, diag.SeverityError, d.Severity)
+			assert.Equal(t, tt.message, d.Message)
+			assert.Equal(t, "STRICT-MIB", d.Module)
+			assert.Equal(t, tt.line, d.Pos.Line)
+		})
+	}
+
+	// Syntax errors take precedence
+	_, err := parser.ParseBytes("strict.mib", []byte("STRICT-MIB DEFINITIONS ::= BEGIN\nX ::= INTEGER { up(1), }\nx ::=\nEND\n"), parser.WithStrict())
+	var strictErr *parser.StrictError
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &strictErr))
+}
+
+func TestParseTiming(t *testing.T) {
+	var timing parser.Timing
+	_, err := parser.ParseBytes("timing.mib", structureInput(10), parser.WithTiming(&timing))
+	require.NoError(t, err)
+	assert.Positive(t, timing.Lex)
+	assert.Positive(t, timing.Parse)
+}
+
+// structureInput returns a module with n objects described at length.
+func structureInput(n int) []byte {
+	var b strings.Builder
+	b.WriteString("STRUCTURE-MIB DEFINITIONS ::= BEGIN\n")
+	b.WriteString(`structure MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example
+                  example@example.com"
+    DESCRIPTION  "The module."
+    REVISION     "202401010000Z"
+    DESCRIPTION  "Initial revision."
+    ::= { iso 1 }
+`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `obj%d OBJECT-TYPE
+    SYNTAX      INTEGER { up(1), down(2) }
+    UNITS       "seconds"
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "%s"
+    REFERENCE   "RFC 2578, section 7.1"
+    DEFVAL      { up }
+    ::= { structure %d }
+`, i, strings.Repeat("A long description of the object,\n        wrapped over lines.\n", 10), i+1)
+	}
+	b.WriteString("END\n")
+	return []byte(b.String())
+}
+
+func TestParseStructureOnly(t *testing.T) {
+	input := structureInput(2)
+	full, err := parser.ParseBytes("structure.mib", input)
+	require.NoError(t, err)
+	module, err := parser.ParseBytes("structure.mib", input, parser.WithStructureOnly())
+	require.NoError(t, err)
+
+	assert.Equal(t, full.Body.Identity.LastUpdated, module.Body.Identity.LastUpdated)
+	assert.Empty(t, module.Body.Identity.Organization)
+	assert.Empty(t, module.Body.Identity.ContactInfo)
+	assert.Empty(t, module.Body.Identity.Description)
+	require.Len(t, module.Body.Identity.Revisions, 1)
+	assert.Equal(t, full.Body.Identity.Revisions[0].Date, module.Body.Identity.Revisions[0].Date)
+	assert.Empty(t, module.Body.Identity.Revisions[0].Description)
+
+	require.Len(t, module.Body.Nodes, 2)
+	for i, node := range module.Body.Nodes {
+		fullNode := full.Body.Nodes[i]
+		assert.Equal(t, fullNode.Name, node.Name)
+		assert.Equal(t, fullNode.Oid, node.Oid)
+		assert.Equal(t, fullNode.ObjectType.Syntax, node.ObjectType.Syntax)
+		assert.Equal(t, fullNode.ObjectType.Units, node.ObjectType.Units)
+		assert.Equal(t, fullNode.ObjectType.Defval, node.ObjectType.Defval)
+		assert.NotEmpty(t, fullNode.ObjectType.Description)
+		assert.Empty(t, node.ObjectType.Description)
+		assert.Empty(t, node.ObjectType.Reference)
+	}
+}
+
+// BenchmarkParse compares parsing a module in full and its structure only.
+func BenchmarkParse(b *testing.B) {
+	input := structureInput(200)
+	for _, mode := range []struct {
+		name string
+		opts []parser.Option
+	}{
+		{"Full", nil},
+		{"StructureOnly", []parser.Option{parser.WithStructureOnly()}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseBytes("structure.mib", input, mode.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+)
+
+// WithRecovery skips illegal characters rather than failing the parse on
+// them: the definition they are in is skipped up to the next top-level
+// definition or the END of the module, and parsing goes on from there.
+// Illegal characters before the first definition, in the header or IMPORTS,
+// are skipped on their own. Each skipped span is reported as a warning to the
+// collector of WithDiagnostics, beside the lexical error of the characters.
+func WithRecovery() Option {
+	return func(cfg *parseConfig) { cfg.recovery = true }
+}
+
+// recoveryLexer passes on the tokens of a lexer without the definitions
+// having illegal tokens. Which definition a token is in is only known once
+// it has been read up to the next definition, so the tokens are read ahead
+// in full on the first call of Next, as lexer.Upgrade would read them anyway.
+type recoveryLexer struct {
+	lexer.Lexer
+	diagnostics *diag.Collector
+
+	tokens []lexer.Token
+	read   bool
+}
+
+func (l *recoveryLexer) Next() (lexer.Token, error) {
+	if !l.read {
+		l.read = true
+		var tokens []lexer.Token
+		for {
+			tok, err := l.Lexer.Next()
+			if err != nil {
+				return tok, err
+			}
+			tokens = append(tokens, tok)
+			if tok.EOF() {
+				break
+			}
+		}
+		l.tokens = l.recover(tokens)
+	}
+	tok := l.tokens[0]
+	if len(l.tokens) > 1 {
+		l.tokens = l.tokens[1:]
+	}
+	return tok, nil
+}
+
+// recover returns tokens without the spans skipped for their illegal tokens,
+// reporting each. The last token, EOF, is always kept.
+func (l *recoveryLexer) recover(tokens []lexer.Token) []lexer.Token {
+	var (
+		kept     = make([]lexer.Token, 0, len(tokens))
+		begun    bool // past the BEGIN of the module
+		inMacro  bool // in the body of a MACRO, which takes any token
+		depth    int
+		defStart = -1 // index in kept of the definition being read
+	)
+	end := len(tokens) - 1
+	for i := 0; i < end; {
+		tok := tokens[i]
+		typ := token.TokenType(tok.Type)
+		if typ == token.ILLEGAL && !inMacro {
+			run := i
+			for run < end && token.TokenType(tokens[run].Type) == token.ILLEGAL {
+				run++
+			}
+			next := run
+			if defStart >= 0 {
+				// Braces of the broken definition need not balance, so
+				// depth is not a guide to its end
+				for next < end && !isRecoveryPoint(tokens, next) {
+					next++
+				}
+			}
+			l.report(kept, defStart, tokens[i:next])
+			if defStart >= 0 {
+				kept = kept[:defStart]
+			}
+			i, depth, defStart = next, 0, -1
+			continue
+		}
+		switch {
+		case !begun:
+			begun = typ == token.Ident && tok.Value == "BEGIN"
+		case inMacro:
+			inMacro = !(typ == token.Ident && tok.Value == "END")
+		case typ == token.Ident && tok.Value == "MACRO":
+			inMacro = true
+		case depth == 0 && isRecoveryPoint(tokens, i):
+			defStart = len(kept)
+		}
+		switch typ {
+		case token.LBrace, token.LPAREN:
+			depth++
+		case token.RBrace, token.RPAREN:
+			if depth > 0 {
+				depth--
+			}
+		}
+		kept = append(kept, tok)
+		i++
+	}
+	return append(kept, tokens[end])
+}
+
+// report reports the skipped tokens, following kept[defStart:] of the
+// definition they are in when defStart is not negative.
+func (l *recoveryLexer) report(kept []lexer.Token, defStart int, skipped []lexer.Token) {
+	if l.diagnostics == nil {
+		return
+	}
+	first, last := skipped[0], skipped[len(skipped)-1]
+	var message string
+	if defStart >= 0 {
+		first = kept[defStart]
+		message = fmt.Sprintf("Skipped definition %s, lines %d to %d, for its illegal characters", first.Value, first.Pos.Line, last.Pos.Line)
+	} else {
+		var text strings.Builder
+		for _, tok := range skipped {
+			text.WriteString(tok.Value)
+		}
+		message = fmt.Sprintf("Skipped illegal characters %q", text.String())
+	}
+	l.diagnostics.Add(diag.Diagnostic{
+		Severity: diag.SeverityWarning,
+		Pos: diag.Position{
+			Filename: first.Pos.Filename,
+			Offset:   first.Pos.Offset,
+			Line:     first.Pos.Line,
+			Column:   first.Pos.Column,
+		},
+		Code:    diag.CodeSkippedTokens,
+		Message: message,
+	})
+}
+
+// isRecoveryPoint tells whether tokens[i] starts a top-level definition or is
+// the END of the module, judging by the first tokens of the line it starts:
+// a descriptor followed by a macro, OBJECT IDENTIFIER or "::=", or a value
+// name followed by its type and "::=".
+func isRecoveryPoint(tokens []lexer.Token, i int) bool {
+	tok := tokens[i]
+	if token.TokenType(tok.Type) != token.Ident || i > 0 && tokens[i-1].Pos.Line == tok.Pos.Line {
+		return false
+	}
+	switch tok.Value {
+	case "END":
+		return true
+	case "IMPORTS", "EXPORTS":
+		// Followed by the names of macros as much as anything
+		return false
+	}
+	if i+1 >= len(tokens) {
+		return false
+	}
+	next := tokens[i+1]
+	switch token.TokenType(next.Type) {
+	case token.ObjectIdentifier, token.Assign:
+		return true
+	case token.Ident:
+		if macroClauses[next.Value] != nil || next.Value == "MACRO" {
+			return true
+		}
+		// Clause keywords followed by a value, such as STATUS mandatory
+		// before the "::=" of SMIv1, start upper case
+		return tok.Value[0] >= 'a' && tok.Value[0] <= 'z' && i+2 < len(tokens) && token.TokenType(tokens[i+2].Type) == token.Assign
+	}
+	return false
+}
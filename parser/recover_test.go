@@ -0,0 +1,88 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const recoveryInput = `RECOVERY-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    OBJECT-TYPE, Integer32 # FROM SNMPv2-SMI;
+
+recovery OBJECT IDENTIFIER ::= { iso 1 }
+
+recoveryBroken OBJECT-TYPE
+    SYNTAX      Integer32 (0..10 @@ }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Broken."
+    ::= { recovery 1 }
+
+recoveryKept OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Kept."
+    ::= { recovery 2 }
+
+RecoveryType ::= INTEGER { one(1), two(2) } $
+
+recoveryLast OBJECT IDENTIFIER ::= { recovery 3 }
+
+END
+`
+
+func TestParseRecovery(t *testing.T) {
+	_, err := parser.ParseBytes("recovery.mib", []byte(recoveryInput), parser.WithDiagnostics(new(diag.Collector)))
+	require.Error(t, err)
+
+	var diagnostics diag.Collector
+	module, err := parser.ParseBytes("recovery.mib", []byte(recoveryInput), parser.WithRecovery(), parser.WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, module.Body.Imports, 1)
+	assert.Equal(t, "SNMPv2-SMI", module.Body.Imports[0].Module.String())
+	var names []string
+	for _, node := range module.Body.Nodes {
+		names = append(names, node.Name.String())
+	}
+	assert.Equal(t, []string{"recovery", "recoveryKept", "recoveryLast"}, names)
+	assert.Empty(t, module.Body.Types)
+
+	var skipped []diag.Diagnostic
+	for _, d := range diagnostics.Diagnostics() {
+		if d.Code == diag.CodeSkippedTokens {
+			skipped = append(skipped, d)
+		}
+	}
+	require.Len(t, skipped, 3)
+	assert.Equal(t, diag.SeverityWarning, skipped[0].Severity)
+	assert.Equal(t, 4, skipped[0].Pos.Line)
+	assert.Equal(t, `Skipped illegal characters "#"`, skipped[0].Message)
+	assert.Equal(t, "RECOVERY-MIB", skipped[0].Module)
+	assert.Equal(t, 8, skipped[1].Pos.Line)
+	assert.Equal(t, "Skipped definition recoveryBroken, lines 8 to 13, for its illegal characters", skipped[1].Message)
+	assert.Equal(t, 22, skipped[2].Pos.Line)
+	assert.Equal(t, "Skipped definition RecoveryType, lines 22 to 22, for its illegal characters", skipped[2].Message)
+}
+
+func TestParseRecoveryMacro(t *testing.T) {
+	input := `MACRO-MIB DEFINITIONS ::= BEGIN
+TEST-MACRO MACRO ::=
+BEGIN
+    TYPE NOTATION ::= "VALUE" value(VALUE INTEGER) ?
+    VALUE NOTATION ::= value(VALUE INTEGER)
+END
+test OBJECT IDENTIFIER ::= { iso 1 } !
+END
+`
+	var diagnostics diag.Collector
+	module, err := parser.ParseBytes("macro.mib", []byte(input), parser.WithRecovery(), parser.WithDiagnostics(&diagnostics))
+	require.NoError(t, err)
+	require.Len(t, module.Body.Macros, 1)
+	assert.Empty(t, module.Body.Nodes)
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	gosmilexer "github.com/lukeod/gosmi/parser/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+)
+
+// SniffBytes is how much of the start of a file Sniff and SniffFile look at.
+const SniffBytes = 16 << 10
+
+// Sniff reports whether src, the start of a file, looks like a MIB module:
+// whether its first SniffBytes bytes, comments excluded, have the
+// "DEFINITIONS ::= BEGIN" of a module header. It tells MIB files from the
+// READMEs and licenses found next to them without parsing them.
+func Sniff(src []byte) bool {
+	return SniffName(src) != ""
+}
+
+// SniffName returns the name of the module src, the start of a file, defines:
+// the first identifier of the file, if Sniff finds a module header after it,
+// or "" otherwise.
+func SniffName(src []byte) string {
+	if len(src) > SniffBytes {
+		src = src[:SniffBytes]
+	}
+	l := gosmilexer.NewLexerBytes("", src)
+	l.SetDiagnostics(new(diag.Collector))
+	var name string
+	var prev [2]lexer.Token
+	for {
+		tok, err := l.Next()
+		if err != nil || tok.EOF() {
+			return ""
+		}
+		if name == "" && token.TokenType(tok.Type) == token.Ident {
+			name = tok.Value
+		}
+		if token.TokenType(tok.Type) == token.Ident && tok.Value == "BEGIN" &&
+			token.TokenType(prev[1].Type) == token.Assign &&
+			token.TokenType(prev[0].Type) == token.Ident && prev[0].Value == "DEFINITIONS" {
+			return name
+		}
+		prev[0], prev[1] = prev[1], tok
+	}
+}
+
+// SniffFile reads the start of the file at path and reports whether it looks
+// like a MIB module, as Sniff does.
+func SniffFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("Read file: %w", err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(io.LimitReader(f, SniffBytes))
+	if err != nil {
+		return false, fmt.Errorf("Read file: %w", err)
+	}
+	return Sniff(b), nil
+}
@@ -0,0 +1,51 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukeod/gosmi/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "Module", input: "TEST-MIB DEFINITIONS ::= BEGIN\nEND\n", want: true},
+		{name: "Header after comments", input: "-- Copyright\n-- TEST-MIB DEFINITIONS ::= BEGIN\n\nTEST-MIB\n  DEFINITIONS\n  ::=\n  BEGIN\n", want: true},
+		{name: "Without spaces", input: "TEST-MIB DEFINITIONS::=BEGIN", want: true},
+		{name: "Commented out", input: "-- TEST-MIB DEFINITIONS ::= BEGIN\n", want: false},
+		{name: "README", input: "This directory holds the MIBs of the product.\nSee LICENSE for details.\n", want: false},
+		{name: "Quoted", input: `Modules start with "DEFINITIONS ::= BEGIN".`, want: false},
+		{name: "Binary", input: "\x00\x01\xff\xfe", want: false},
+		{name: "Empty", input: "", want: false},
+		{name: "Header too late", input: strings.Repeat("-- padding\n", parser.SniffBytes/10) + "TEST-MIB DEFINITIONS ::= BEGIN\n", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parser.Sniff([]byte(tt.input)))
+		})
+	}
+
+	dir := t.TempDir()
+	mib := filepath.Join(dir, "TEST-MIB")
+	require.NoError(t, os.WriteFile(mib, []byte("TEST-MIB DEFINITIONS ::= BEGIN\nEND\n"), 0o644))
+	ok, err := parser.SniffFile(mib)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = parser.SniffFile(filepath.Join(dir, "MISSING"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSniffName(t *testing.T) {
+	assert.Equal(t, "TEST-MIB", parser.SniffName([]byte("-- TEST-MIB v2\nTEST-MIB DEFINITIONS ::= BEGIN\nEND\n")))
+	assert.Equal(t, "RFC1155-SMI", parser.SniffName([]byte("RFC1155-SMI DEFINITIONS ::= BEGIN\n")))
+	assert.Equal(t, "", parser.SniffName([]byte("See the TEST-MIB module.\n")))
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+)
+
+// WithStrict rejects modules using constructs the grammar accepts for the
+// sake of real world MIBs although SMI does not allow them: trailing commas
+// in enumerations and BITS values, and identifiers with non-ASCII letters or
+// ending with a hyphen. The parse fails with a *StrictError on the first of
+// them, once the module parsed otherwise.
+func WithStrict() Option {
+	return func(cfg *parseConfig) { cfg.strict = true }
+}
+
+// StrictError is a construct rejected by WithStrict.
+type StrictError struct {
+	Pos lexer.Position
+	Msg string
+}
+
+var _ participle.Error = (*StrictError)(nil)
+
+func (e *StrictError) Error() string            { return participle.FormatError(e) }
+func (e *StrictError) Message() string          { return e.Msg }
+func (e *StrictError) Position() lexer.Position { return e.Pos }
+
+// strictLexer passes on the tokens of a lexer, recording the first
+// non-conformant construct among them.
+type strictLexer struct {
+	lexer.Lexer
+	prev lexer.Token
+	err  *StrictError
+}
+
+func (l *strictLexer) Next() (lexer.Token, error) {
+	tok, err := l.Lexer.Next()
+	if err == nil && l.err == nil {
+		l.err = checkStrict(l.prev, tok)
+	}
+	l.prev = tok
+	return tok, err
+}
+
+func checkStrict(prev, tok lexer.Token) *StrictError {
+	typ := token.TokenType(tok.Type)
+	if token.TokenType(prev.Type) == token.Comma && typ == token.RBrace {
+		return &StrictError{Pos: prev.Pos, Msg: fmt.Sprintf("Trailing comma before %q", tok.Value)}
+	}
+	if typ != token.Ident {
+		return nil
+	}
+	if i := strings.IndexFunc(tok.Value, func(r rune) bool { return r > 0x7f }); i >= 0 {
+		return &StrictError{Pos: tok.Pos, Msg: fmt.Sprintf("Identifier %s has the non-ASCII letter %q", tok.Value, []rune(tok.Value[i:])[0])}
+	}
+	if strings.HasSuffix(tok.Value, "-") {
+		return &StrictError{Pos: tok.Pos, Msg: fmt.Sprintf("Identifier %s ends with a hyphen", tok.Value)}
+	}
+	return nil
+}
@@ -53,4 +53,7 @@ type Type struct {
 	Sequence          *Sequence           `parser:"| @@"`
 	Implicit          *Implicit           `parser:"| @@"`
 	Syntax            *SyntaxType         `parser:"| @@ )"`
+	// Extensions are the clauses of dialects in the TEXTUAL-CONVENTION
+	// defining the type.
+	Extensions []Extension
 }
@@ -0,0 +1,166 @@
+// Package refactor rewrites the files of loaded modules, changing only the
+// tokens a refactoring is about, so that their layout and comments are kept
+// as written.
+package refactor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/parser/lexer"
+	"github.com/lukeod/gosmi/parser/lexer/token"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// FileEdit is a module file changed by a refactoring.
+type FileEdit struct {
+	Module string
+	Path   string
+	// Old and New are the contents of the file before and after the change.
+	Old []byte
+	New []byte
+}
+
+// Patch returns the change as a unified diff.
+func (e FileEdit) Patch() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(e.Old)),
+		B:        difflib.SplitLines(string(e.New)),
+		FromFile: "a/" + e.Path,
+		ToFile:   "b/" + e.Path,
+		Context:  3,
+	})
+}
+
+// Write replaces the file with its new content.
+func (e FileEdit) Write() error {
+	info, err := os.Stat(e.Path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(e.Path, e.New, info.Mode())
+}
+
+// Rename renames the node or type oldName defined by the loaded module to
+// newName, in the definition, every use in the module and the loaded modules
+// importing it from the module, their IMPORTS included. Load the modules of
+// the workspace first, with gosmi.LoadModule or gosmi.CompileDir, for their
+// uses to be renamed. The files are left unchanged: the edits returned, one
+// per file changed with the module first, give their new contents.
+func Rename(module, oldName, newName string) ([]FileEdit, error) {
+	m, err := gosmi.GetModule(module)
+	if err != nil {
+		return nil, err
+	}
+	if !defines(m, oldName) {
+		return nil, fmt.Errorf("%s does not define %s", module, oldName)
+	}
+	if err := checkName(oldName, newName); err != nil {
+		return nil, err
+	}
+	if defines(m, newName) {
+		return nil, fmt.Errorf("%s already defines %s", module, newName)
+	}
+
+	modules := []gosmi.SmiModule{m}
+	for _, dependent := range gosmi.GetLoadedModules() {
+		imports := dependent.GetImports()
+		for _, i := range imports {
+			if i.Module == module && i.Name == oldName {
+				if err := checkUnused(dependent, imports, newName); err != nil {
+					return nil, err
+				}
+				modules = append(modules, dependent)
+				break
+			}
+		}
+	}
+	sort.SliceStable(modules[1:], func(i, j int) bool { return modules[1+i].Name < modules[1+j].Name })
+
+	var edits []FileEdit
+	for _, m := range modules {
+		edit, err := renameInFile(m, oldName, newName)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(edit.Old, edit.New) {
+			edits = append(edits, edit)
+		}
+	}
+	return edits, nil
+}
+
+// checkUnused checks that a module importing the name renamed does not
+// already define or import newName.
+func checkUnused(m gosmi.SmiModule, imports []models.Import, newName string) error {
+	if defines(m, newName) {
+		return fmt.Errorf("%s already defines %s", m.Name, newName)
+	}
+	for _, i := range imports {
+		if i.Name == newName {
+			return fmt.Errorf("%s already imports %s from %s", m.Name, newName, i.Module)
+		}
+	}
+	return nil
+}
+
+func defines(m gosmi.SmiModule, name string) bool {
+	if node, err := gosmi.GetNodeInModule(m.Name, name); err == nil && node.GetModule().Name == m.Name {
+		return true
+	}
+	t, err := gosmi.GetTypeInModule(m.Name, name)
+	return err == nil && t.GetModule().Name == m.Name
+}
+
+// checkName checks that newName is a descriptor, of the same case as oldName
+// as types start with an uppercase letter and nodes with a lowercase one.
+func checkName(oldName, newName string) error {
+	l := lexer.NewLexer("", newName)
+	l.SetDiagnostics(new(diag.Collector))
+	f, err := l.Tokenize()
+	if err != nil || len(f.Tokens) != 2 || token.TokenType(f.Tokens[0].Type) != token.Ident || f.Tokens[0].Raw != newName {
+		return fmt.Errorf("Invalid name %q", newName)
+	}
+	if isUpper(oldName[0]) && !isUpper(newName[0]) {
+		return fmt.Errorf("%s must start with an uppercase letter like %s", newName, oldName)
+	}
+	if !isUpper(oldName[0]) && isUpper(newName[0]) {
+		return fmt.Errorf("%s must start with a lowercase letter like %s", newName, oldName)
+	}
+	return nil
+}
+
+func isUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+
+// renameInFile renames the identifiers named oldName in the file of the
+// module. Named numbers, as in up(1), are names of their own, so identifiers
+// followed by a parenthesis are left alone.
+func renameInFile(m gosmi.SmiModule, oldName, newName string) (FileEdit, error) {
+	edit := FileEdit{Module: m.Name, Path: m.Path}
+	if m.Path == "" {
+		return edit, fmt.Errorf("%s was not loaded from a file", m.Name)
+	}
+	src, err := os.ReadFile(m.Path)
+	if err != nil {
+		return edit, fmt.Errorf("Read %s: %w", m.Name, err)
+	}
+	l := lexer.NewLexerBytes(m.Path, src)
+	l.SetDiagnostics(new(diag.Collector))
+	f, err := l.Tokenize()
+	if err != nil {
+		return edit, fmt.Errorf("Lex %s: %w", m.Name, err)
+	}
+	for _, i := range f.Idents(oldName) {
+		if i+1 < len(f.Tokens) && token.TokenType(f.Tokens[i+1].Type) == token.LPAREN {
+			continue
+		}
+		f.Tokens[i].Raw = newName
+	}
+	edit.Old, edit.New = src, f.Bytes()
+	return edit, nil
+}
@@ -0,0 +1,141 @@
+package refactor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/refactor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renameBaseMIB = `RENAME-BASE-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Integer32
+        FROM SNMPv2-SMI;
+
+renameBase MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION  "Base module."
+    ::= { iso 3 6 1 4 1 99999 1 }
+
+-- baseObject is renamed, but not this comment
+baseObject   OBJECT-TYPE
+    SYNTAX      INTEGER { baseObject(1), other(2) }
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "The baseObject of the module."
+    ::= { renameBase 1 }
+
+baseChild    OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Under baseObject."
+    ::= { baseObject 1 }
+
+END
+`
+
+const renameUserMIB = `RENAME-USER-MIB DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, Integer32
+        FROM SNMPv2-SMI
+    baseObject, baseChild
+        FROM RENAME-BASE-MIB;
+
+renameUser MODULE-IDENTITY
+    LAST-UPDATED "202401010000Z"
+    ORGANIZATION "Example"
+    CONTACT-INFO "Example"
+    DESCRIPTION  "User module."
+    ::= { iso 3 6 1 4 1 99999 2 }
+
+userObject   OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-only
+    STATUS      current
+    DESCRIPTION "Next to baseChild."
+    ::= {   baseObject   2 }
+
+END
+`
+
+func loadRenameModules(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "RENAME-BASE-MIB"), []byte(renameBaseMIB), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "RENAME-USER-MIB"), []byte(renameUserMIB), 0o644))
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("RENAME-USER-MIB")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestRename(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	dir := loadRenameModules(t)
+
+	edits, err := refactor.Rename("RENAME-BASE-MIB", "baseObject", "renamedObject")
+	require.NoError(t, err)
+	require.Len(t, edits, 2)
+
+	assert.Equal(t, "RENAME-BASE-MIB", edits[0].Module)
+	assert.Equal(t, []byte(renameBaseMIB), edits[0].Old)
+	assert.Contains(t, string(edits[0].New), "-- baseObject is renamed, but not this comment\nrenamedObject   OBJECT-TYPE\n")
+	assert.Contains(t, string(edits[0].New), "INTEGER { baseObject(1), other(2) }")
+	assert.Contains(t, string(edits[0].New), `"The baseObject of the module."`)
+	assert.Contains(t, string(edits[0].New), "::= { renamedObject 1 }")
+
+	assert.Equal(t, "RENAME-USER-MIB", edits[1].Module)
+	assert.Contains(t, string(edits[1].New), "    renamedObject, baseChild\n        FROM RENAME-BASE-MIB;")
+	assert.Contains(t, string(edits[1].New), "::= {   renamedObject   2 }")
+	assert.NotContains(t, string(edits[1].New), "baseObject")
+
+	patch, err := edits[1].Patch()
+	require.NoError(t, err)
+	assert.Contains(t, patch, "--- a/"+filepath.Join(dir, "RENAME-USER-MIB"))
+	assert.Contains(t, patch, "-    baseObject, baseChild\n+    renamedObject, baseChild\n")
+	assert.Contains(t, patch, "-    ::= {   baseObject   2 }\n+    ::= {   renamedObject   2 }\n")
+
+	// The files are only changed by writing the edits.
+	src, err := os.ReadFile(edits[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, renameUserMIB, string(src))
+	require.NoError(t, edits[1].Write())
+	src, err = os.ReadFile(edits[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, string(edits[1].New), string(src))
+}
+
+func TestRenameErrors(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	loadRenameModules(t)
+
+	for _, tc := range []struct {
+		name, oldName, newName, err string
+	}{
+		{"undefined", "noSuchObject", "otherObject", "RENAME-BASE-MIB does not define noSuchObject"},
+		{"imported", "Integer32", "Integer64", "RENAME-BASE-MIB does not define Integer32"},
+		{"invalid", "baseObject", "base object", `Invalid name "base object"`},
+		{"case", "baseObject", "BaseObject", "BaseObject must start with a lowercase letter like baseObject"},
+		{"defined", "baseObject", "baseChild", "RENAME-BASE-MIB already defines baseChild"},
+		{"dependent", "baseChild", "userObject", "RENAME-USER-MIB already defines userObject"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := refactor.Rename("RENAME-BASE-MIB", tc.oldName, tc.newName)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+
+	_, err := refactor.Rename("NO-SUCH-MIB", "baseObject", "renamedObject")
+	assert.Error(t, err)
+}
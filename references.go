@@ -0,0 +1,154 @@
+package gosmi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// ReferenceKind is how a definition refers to a node or type.
+type ReferenceKind string
+
+const (
+	// ReferenceSyntax is a SYNTAX of the type, refined or not
+	ReferenceSyntax ReferenceKind = "syntax"
+	// ReferenceParentType is a type derived from the type
+	ReferenceParentType ReferenceKind = "parent-type"
+	// ReferenceIndex is an INDEX clause naming the node
+	ReferenceIndex ReferenceKind = "index"
+	// ReferenceAugments is an AUGMENTS clause, or another clause reusing the
+	// index of the row
+	ReferenceAugments ReferenceKind = "augments"
+	// ReferenceObjects is an OBJECTS, VARIABLES or NOTIFICATIONS clause, or
+	// the MANDATORY-GROUPS of a compliance statement
+	ReferenceObjects ReferenceKind = "objects"
+	// ReferenceCompliance is a GROUP or OBJECT clause of a compliance
+	// statement, or a refined SYNTAX of one
+	ReferenceCompliance ReferenceKind = "compliance"
+)
+
+// Reference is a loaded definition referring to a node or type.
+type Reference struct {
+	Module string        `json:"module"`
+	Name   string        `json:"name"`
+	Kind   ReferenceKind `json:"kind"`
+	Source Source        `json:"source"`
+}
+
+// FindReferences returns the definitions of the loaded modules referring to
+// the node or type named symbol, optionally qualified by its module as in
+// IF-MIB::ifIndex, in module load order, to tell what deprecating it impacts.
+// Nodes are referred to by INDEX, AUGMENTS, OBJECTS and compliance clauses,
+// types by SYNTAX clauses and the types derived from them.
+func FindReferences(symbol string) (refs []Reference, err error) {
+	module, name := "", symbol
+	if i := strings.Index(symbol, "::"); i >= 0 {
+		module, name = symbol[:i], symbol[i+2:]
+	}
+	var node SmiNode
+	if module != "" {
+		node, err = GetNodeInModule(module, name)
+	} else {
+		node, err = GetNode(name)
+	}
+	if err == nil {
+		return findNodeReferences(node.smiNode), nil
+	}
+	var t SmiType
+	if module != "" {
+		t, err = GetTypeInModule(module, name)
+	} else {
+		t, err = GetType(name)
+	}
+	if err == nil {
+		return findTypeReferences(t.smiType), nil
+	}
+	return nil, fmt.Errorf("Unknown node or type %s", symbol)
+}
+
+func newNodeReference(smiNode *types.SmiNode, kind ReferenceKind) Reference {
+	module := smi.GetNodeModule(smiNode)
+	return Reference{
+		Module: string(module.Name),
+		Name:   string(smiNode.Name),
+		Kind:   kind,
+		Source: newSource(module, smi.GetNodeLine(smiNode), smi.GetNodeColumn(smiNode)),
+	}
+}
+
+func findNodeReferences(target *types.SmiNode) (refs []Reference) {
+	for module := smi.GetFirstModule(); module != nil; module = smi.GetNextModule(module) {
+		for smiNode := smi.GetFirstNode(module, types.NodeAny); smiNode != nil; smiNode = smi.GetNextNode(smiNode, types.NodeAny) {
+			elementKind := ReferenceObjects
+			if smiNode.NodeKind == types.NodeRow {
+				elementKind = ReferenceIndex
+			}
+			for element := smi.GetFirstElement(smiNode); element != nil; element = smi.GetNextElement(element) {
+				if smi.GetElementNode(element) == target {
+					refs = append(refs, newNodeReference(smiNode, elementKind))
+					break
+				}
+			}
+			if smiNode.NodeKind == types.NodeRow && smiNode.IndexKind != types.IndexIndex && smi.GetRelatedNode(smiNode) == target {
+				refs = append(refs, newNodeReference(smiNode, ReferenceAugments))
+			}
+			if smiNode.NodeKind != types.NodeCompliance {
+				continue
+			}
+			found := false
+			for option := smi.GetFirstOption(smiNode); option != nil && !found; option = smi.GetNextOption(option) {
+				found = smi.GetOptionNode(option) == target
+			}
+			for refinement := smi.GetFirstRefinement(smiNode); refinement != nil && !found; refinement = smi.GetNextRefinement(refinement) {
+				found = smi.GetRefinementNode(refinement) == target
+			}
+			if found {
+				refs = append(refs, newNodeReference(smiNode, ReferenceCompliance))
+			}
+		}
+	}
+	return
+}
+
+// usesType reports whether smiType is target, or an unnamed refinement of it
+func usesType(smiType, target *types.SmiType) bool {
+	if smiType == nil {
+		return false
+	}
+	if smiType == target {
+		return true
+	}
+	return smiType.Name == "" && smi.GetParentType(smiType) == target
+}
+
+func findTypeReferences(target *types.SmiType) (refs []Reference) {
+	for module := smi.GetFirstModule(); module != nil; module = smi.GetNextModule(module) {
+		for smiType := smi.GetFirstType(module); smiType != nil; smiType = smi.GetNextType(smiType) {
+			if smiType != target && smiType.Name != "" && smi.GetParentType(smiType) == target {
+				refs = append(refs, Reference{
+					Module: string(module.Name),
+					Name:   string(smiType.Name),
+					Kind:   ReferenceParentType,
+					Source: newSource(module, smi.GetTypeLine(smiType), smi.GetTypeColumn(smiType)),
+				})
+			}
+		}
+		for smiNode := smi.GetFirstNode(module, types.NodeAny); smiNode != nil; smiNode = smi.GetNextNode(smiNode, types.NodeAny) {
+			if usesType(smi.GetNodeType(smiNode), target) {
+				refs = append(refs, newNodeReference(smiNode, ReferenceSyntax))
+			}
+			if smiNode.NodeKind != types.NodeCompliance {
+				continue
+			}
+			for refinement := smi.GetFirstRefinement(smiNode); refinement != nil; refinement = smi.GetNextRefinement(refinement) {
+				if usesType(smi.GetRefinementType(refinement), target) || usesType(smi.GetRefinementWriteType(refinement), target) {
+					refs = append(refs, newNodeReference(smiNode, ReferenceCompliance))
+					break
+				}
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,149 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const refMib = `REF-MIB DEFINITIONS ::= BEGIN
+RefName ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A name."
+    SYNTAX OCTET STRING (SIZE (0..32))
+
+RefShortName ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A shorter name."
+    SYNTAX RefName (SIZE (0..8))
+
+refRoot OBJECT IDENTIFIER ::= { iso 6 }
+
+refTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF RefEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { refRoot 1 }
+
+refEntry OBJECT-TYPE
+    SYNTAX RefEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { refIndex }
+    ::= { refTable 1 }
+
+RefEntry ::= SEQUENCE {
+    refIndex INTEGER,
+    refName  RefName
+}
+
+refIndex OBJECT-TYPE
+    SYNTAX INTEGER (1..10)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The index."
+    ::= { refEntry 1 }
+
+refName OBJECT-TYPE
+    SYNTAX RefName (SIZE (1..16))
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The name."
+    ::= { refEntry 2 }
+
+refExtTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF RefExtEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmenting table."
+    ::= { refRoot 2 }
+
+refExtEntry OBJECT-TYPE
+    SYNTAX RefExtEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmenting row."
+    AUGMENTS { refEntry }
+    ::= { refExtTable 1 }
+
+RefExtEntry ::= SEQUENCE {
+    refAlias RefName
+}
+
+refAlias OBJECT-TYPE
+    SYNTAX RefName
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "An alias."
+    ::= { refExtEntry 1 }
+
+refChange NOTIFICATION-TYPE
+    OBJECTS { refName }
+    STATUS current
+    DESCRIPTION "A name changed."
+    ::= { refRoot 3 }
+
+refGroup OBJECT-GROUP
+    OBJECTS { refName, refAlias }
+    STATUS current
+    DESCRIPTION "The objects."
+    ::= { refRoot 4 }
+
+refCompliance MODULE-COMPLIANCE
+    STATUS current
+    DESCRIPTION "The compliance."
+    MODULE
+        MANDATORY-GROUPS { refGroup }
+        OBJECT refAlias
+            SYNTAX RefName (SIZE (0..4))
+            DESCRIPTION "Shorter."
+    ::= { refRoot 5 }
+END
+`
+
+func TestFindReferences(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"REF-MIB": refMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("REF-MIB")
+	require.NoError(t, err)
+
+	references := func(symbol string) map[string]gosmi.ReferenceKind {
+		refs, err := gosmi.FindReferences(symbol)
+		require.NoError(t, err, symbol)
+		kinds := make(map[string]gosmi.ReferenceKind)
+		for _, ref := range refs {
+			assert.Equal(t, "REF-MIB", ref.Module)
+			assert.NotZero(t, ref.Source.Pos.Line)
+			kinds[ref.Name+" "+string(ref.Kind)] = ref.Kind
+		}
+		return kinds
+	}
+	keys := func(m map[string]gosmi.ReferenceKind) (keys []string) {
+		for k := range m {
+			keys = append(keys, k)
+		}
+		return
+	}
+
+	assert.ElementsMatch(t, []string{"refEntry index"}, keys(references("refIndex")))
+	assert.ElementsMatch(t, []string{"refExtEntry augments"}, keys(references("REF-MIB::refEntry")))
+	assert.ElementsMatch(t, []string{"refChange objects", "refGroup objects"}, keys(references("refName")))
+	assert.ElementsMatch(t, []string{"refGroup objects", "refCompliance compliance"}, keys(references("refAlias")))
+	assert.ElementsMatch(t, []string{"refCompliance objects"}, keys(references("refGroup")))
+	assert.ElementsMatch(t, []string{
+		"RefShortName parent-type",
+		"refName syntax",
+		"refAlias syntax",
+		"refCompliance compliance",
+	}, keys(references("RefName")))
+	assert.Empty(t, references("refChange"))
+
+	_, err = gosmi.FindReferences("refMissing")
+	assert.Error(t, err)
+}
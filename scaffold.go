@@ -0,0 +1,193 @@
+package gosmi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lukeod/gosmi/parser"
+)
+
+// Skeleton describes a new SMIv2 module for WriteSkeleton.
+type Skeleton struct {
+	// Module is the module name, such as ACME-WIDGET-MIB. Definitions are
+	// named after it without the -MIB suffix, acmeWidgetTable for instance.
+	Module       string
+	Organization string
+	Contact      string
+	// Root is the OID of the MODULE-IDENTITY as a node of SNMPv2-SMI followed
+	// by sub-identifiers, such as enterprises.99999.
+	Root string
+	// Date is that of LAST-UPDATED and of the initial REVISION, today if zero.
+	Date time.Time
+}
+
+var (
+	skeletonModuleName = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*(-[A-Za-z0-9]+)*$`)
+	skeletonRoot       = regexp.MustCompile(`^([a-z][A-Za-z0-9-]*)((?:[. ][0-9]+)+)$`)
+)
+
+// snmpv2SmiNodes are the OID values SNMPv2-SMI defines, which a skeleton can
+// be rooted under
+var snmpv2SmiNodes = map[string]bool{
+	"org": true, "dod": true, "internet": true, "directory": true, "mgmt": true,
+	"mib-2": true, "transmission": true, "experimental": true, "private": true,
+	"enterprises": true, "security": true, "snmpV2": true, "snmpDomains": true,
+	"snmpProxys": true, "snmpModules": true,
+}
+
+// WriteSkeleton writes a new SMIv2 module: a MODULE-IDENTITY, a scalar, a
+// table with a RowStatus column, a notification and the conformance section
+// grouping them. The module is parsed before it is written, so that what is
+// written is known to be valid.
+func WriteSkeleton(w io.Writer, s Skeleton) error {
+	if !skeletonModuleName.MatchString(s.Module) {
+		return fmt.Errorf("Invalid module name %q", s.Module)
+	}
+	if strings.Contains(s.Organization, `"`) || strings.Contains(s.Contact, `"`) {
+		return errors.New("Organization and contact cannot contain double quotes")
+	}
+	root := skeletonRoot.FindStringSubmatch(s.Root)
+	if root == nil || !snmpv2SmiNodes[root[1]] {
+		return fmt.Errorf("Invalid root %q: expected a node of SNMPv2-SMI followed by numbers, such as enterprises.99999", s.Root)
+	}
+	date := s.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var words []string
+	for _, word := range strings.Split(strings.TrimSuffix(s.Module, "-MIB"), "-") {
+		words = append(words, strings.ToUpper(word[:1])+strings.ToLower(word[1:]))
+	}
+	prefix := strings.Join(words, "")
+	data := struct {
+		Skeleton
+		Prefix, Type, Parent, SubIds, Date string
+	}{
+		Skeleton: s,
+		Prefix:   strings.ToLower(prefix[:1]) + prefix[1:],
+		Type:     prefix,
+		Parent:   root[1],
+		SubIds:   strings.TrimSpace(strings.ReplaceAll(root[2], ".", " ")),
+		Date:     date.UTC().Format("200601021504Z"),
+	}
+	var buf bytes.Buffer
+	if err := skeletonTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("Generate module: %w", err)
+	}
+	if _, err := parser.ParseBytes(s.Module, buf.Bytes()); err != nil {
+		return fmt.Errorf("Generated module does not parse: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var skeletonTemplate = template.Must(template.New("skeleton").Parse(`{{.Module}} DEFINITIONS ::= BEGIN
+
+IMPORTS
+    MODULE-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE, Integer32,
+    {{.Parent}}
+        FROM SNMPv2-SMI
+    DisplayString, RowStatus
+        FROM SNMPv2-TC
+    MODULE-COMPLIANCE, OBJECT-GROUP, NOTIFICATION-GROUP
+        FROM SNMPv2-CONF;
+
+{{.Prefix}}MIB MODULE-IDENTITY
+    LAST-UPDATED "{{.Date}}"
+    ORGANIZATION "{{.Organization}}"
+    CONTACT-INFO "{{.Contact}}"
+    DESCRIPTION  "The MIB module of {{.Organization}}."
+    REVISION     "{{.Date}}"
+    DESCRIPTION  "Initial version."
+    ::= { {{.Parent}} {{.SubIds}} }
+
+{{.Prefix}}Notifications OBJECT IDENTIFIER ::= { {{.Prefix}}MIB 0 }
+{{.Prefix}}Objects       OBJECT IDENTIFIER ::= { {{.Prefix}}MIB 1 }
+{{.Prefix}}Conformance   OBJECT IDENTIFIER ::= { {{.Prefix}}MIB 2 }
+
+{{.Prefix}}Name OBJECT-TYPE
+    SYNTAX      DisplayString (SIZE (0..255))
+    MAX-ACCESS  read-write
+    STATUS      current
+    DESCRIPTION "An example scalar."
+    ::= { {{.Prefix}}Objects 1 }
+
+{{.Prefix}}Table OBJECT-TYPE
+    SYNTAX      SEQUENCE OF {{.Type}}Entry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "An example table."
+    ::= { {{.Prefix}}Objects 2 }
+
+{{.Prefix}}Entry OBJECT-TYPE
+    SYNTAX      {{.Type}}Entry
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "A row of {{.Prefix}}Table."
+    INDEX       { {{.Prefix}}Index }
+    ::= { {{.Prefix}}Table 1 }
+
+{{.Type}}Entry ::= SEQUENCE {
+    {{.Prefix}}Index     Integer32,
+    {{.Prefix}}Value     Integer32,
+    {{.Prefix}}RowStatus RowStatus
+}
+
+{{.Prefix}}Index OBJECT-TYPE
+    SYNTAX      Integer32 (1..2147483647)
+    MAX-ACCESS  not-accessible
+    STATUS      current
+    DESCRIPTION "The index of the row."
+    ::= { {{.Prefix}}Entry 1 }
+
+{{.Prefix}}Value OBJECT-TYPE
+    SYNTAX      Integer32
+    MAX-ACCESS  read-create
+    STATUS      current
+    DESCRIPTION "An example column."
+    ::= { {{.Prefix}}Entry 2 }
+
+{{.Prefix}}RowStatus OBJECT-TYPE
+    SYNTAX      RowStatus
+    MAX-ACCESS  read-create
+    STATUS      current
+    DESCRIPTION "The status of the row."
+    ::= { {{.Prefix}}Entry 3 }
+
+{{.Prefix}}ValueChanged NOTIFICATION-TYPE
+    OBJECTS     { {{.Prefix}}Value }
+    STATUS      current
+    DESCRIPTION "An example notification."
+    ::= { {{.Prefix}}Notifications 1 }
+
+{{.Prefix}}Compliances OBJECT IDENTIFIER ::= { {{.Prefix}}Conformance 1 }
+{{.Prefix}}Groups      OBJECT IDENTIFIER ::= { {{.Prefix}}Conformance 2 }
+
+{{.Prefix}}Compliance MODULE-COMPLIANCE
+    STATUS      current
+    DESCRIPTION "The compliance statement for {{.Module}}."
+    MODULE
+        MANDATORY-GROUPS { {{.Prefix}}ObjectGroup, {{.Prefix}}NotificationGroup }
+    ::= { {{.Prefix}}Compliances 1 }
+
+{{.Prefix}}ObjectGroup OBJECT-GROUP
+    OBJECTS     { {{.Prefix}}Name, {{.Prefix}}Value, {{.Prefix}}RowStatus }
+    STATUS      current
+    DESCRIPTION "The objects of {{.Module}}."
+    ::= { {{.Prefix}}Groups 1 }
+
+{{.Prefix}}NotificationGroup NOTIFICATION-GROUP
+    NOTIFICATIONS { {{.Prefix}}ValueChanged }
+    STATUS      current
+    DESCRIPTION "The notifications of {{.Module}}."
+    ::= { {{.Prefix}}Groups 2 }
+
+END
+`))
@@ -0,0 +1,45 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/lint"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSkeleton(t *testing.T) {
+	var buf bytes.Buffer
+	err := gosmi.WriteSkeleton(&buf, gosmi.Skeleton{
+		Module:       "ACME-WIDGET-MIB",
+		Organization: "ACME",
+		Contact:      "snmp@acme.example",
+		Root:         "enterprises.99999.1",
+		Date:         time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	module, err := parser.ParseBytes("ACME-WIDGET-MIB", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, types.LanguageSMIv2, module.Language())
+	require.NotNil(t, module.Body.Identity)
+	assert.Equal(t, types.SmiIdentifier("acmeWidgetMIB"), module.Body.Identity.Name)
+	assert.Equal(t, parser.Date("202405011230Z"), module.Body.Identity.LastUpdated)
+	assert.Contains(t, buf.String(), "::= { enterprises 99999 1 }")
+	assert.Contains(t, buf.String(), "SYNTAX      SEQUENCE OF AcmeWidgetEntry")
+	assert.Empty(t, lint.Check("ACME-WIDGET-MIB", module))
+
+	for _, s := range []gosmi.Skeleton{
+		{Module: "acme-mib", Root: "enterprises.1"},
+		{Module: "ACME-MIB", Root: "1.3.6.1.4.1"},
+		{Module: "ACME-MIB", Root: "unknown.1"},
+		{Module: "ACME-MIB", Root: "enterprises.1", Contact: `"`},
+	} {
+		assert.Error(t, gosmi.WriteSkeleton(&buf, s), s)
+	}
+}
@@ -0,0 +1,157 @@
+// Package simdata generates plausible static agent data for the objects of
+// resolved modules, so that pollers can be integration-tested without real
+// devices. The data can be written as snmpsim .snmprec files or as net-snmp
+// snmpd.conf override directives.
+package simdata
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Tag is the type of a record, numbered like the BER tags used in .snmprec
+// files.
+type Tag int
+
+const (
+	TagInteger          Tag = 2
+	TagOctetString      Tag = 4
+	TagNull             Tag = 5
+	TagObjectIdentifier Tag = 6
+	TagIpAddress        Tag = 64
+	TagCounter32        Tag = 65
+	TagGauge32          Tag = 66
+	TagTimeTicks        Tag = 67
+	TagOpaque           Tag = 68
+	TagCounter64        Tag = 70
+)
+
+// Record is the value of a single object instance. Value holds an int64 for
+// the numeric tags, a []byte for TagOctetString, TagIpAddress and TagOpaque,
+// and a types.Oid for TagObjectIdentifier.
+type Record struct {
+	Oid   types.Oid
+	Name  string
+	Tag   Tag
+	Value interface{}
+}
+
+// DefaultRows is the number of rows generated per table when WithRows is not
+// given.
+const DefaultRows = 2
+
+type config struct {
+	rows int
+}
+
+// Option configures Generate.
+type Option func(*config)
+
+// WithRows sets the number of rows generated for every table. Tables with
+// fewer distinct index values than that get fewer rows.
+func WithRows(n int) Option {
+	return func(c *config) { c.rows = n }
+}
+
+// Generate returns records for every accessible scalar and table column of
+// modules, sorted by OID. Values satisfy the effective ranges, sizes and
+// enumerations of the objects' types, and the rows of tables sharing an index
+// (augmentations and sparse tables) line up with each other.
+func Generate(modules []gosmi.SmiModule, opts ...Option) ([]Record, error) {
+	cfg := config{rows: DefaultRows}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	records := make(map[string]Record)
+	add := func(node gosmi.SmiNode, suffix types.Oid, row int) error {
+		tag, value, err := nodeValue(node, row)
+		if err != nil {
+			return fmt.Errorf("Generate value for %s: %w", node.Name, err)
+		}
+		oid := append(append(types.Oid{}, node.Oid...), suffix...)
+		records[oid.String()] = Record{Oid: oid, Name: node.Name, Tag: tag, Value: value}
+		return nil
+	}
+
+	for _, module := range modules {
+		for _, node := range module.GetScalars() {
+			if !readable(node) {
+				continue
+			}
+			if err := add(node, types.Oid{0}, 0); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, node := range module.GetTables() {
+			columns, columnOrder := node.GetColumns()
+			index := node.GetIndex()
+			implied := node.GetImplied()
+			seen := make(map[string]bool)
+			for row := 0; row < cfg.rows; row++ {
+				suffix, err := indexSuffix(index, implied, row)
+				if err != nil {
+					return nil, fmt.Errorf("Generate index of %s: %w", node.Name, err)
+				}
+				if seen[suffix.String()] {
+					// The index columns have run out of distinct values
+					break
+				}
+				seen[suffix.String()] = true
+				for _, name := range columnOrder {
+					column := columns[name]
+					if !readable(column) {
+						continue
+					}
+					if err := add(column, suffix, row); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	out := make([]Record, 0, len(records))
+	for _, record := range records {
+		out = append(out, record)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return oidLess(out[i].Oid, out[j].Oid)
+	})
+	return out, nil
+}
+
+func readable(node gosmi.SmiNode) bool {
+	return node.Access == types.AccessReadOnly || node.Access == types.AccessReadWrite
+}
+
+func indexSuffix(index []gosmi.SmiNode, implied bool, row int) (suffix types.Oid, err error) {
+	for i, column := range index {
+		if column.Type == nil {
+			return nil, fmt.Errorf("Index column %s has no type", column.Name)
+		}
+		_, value, err := nodeValue(column, row)
+		if err != nil {
+			return nil, err
+		}
+		oid, err := column.Type.IndexValue(value, implied && i == len(index)-1)
+		if err != nil {
+			return nil, fmt.Errorf("Index column %s: %w", column.Name, err)
+		}
+		suffix = append(suffix, oid...)
+	}
+	return
+}
+
+func oidLess(a, b types.Oid) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,136 @@
+package simdata_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/simdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simMib = `SIM-MIB DEFINITIONS ::= BEGIN
+IpAddress ::= [APPLICATION 0] IMPLICIT OCTET STRING (SIZE (4))
+Counter32 ::= [APPLICATION 1] IMPLICIT INTEGER (0..4294967295)
+
+DisplayString ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "255a"
+    STATUS current
+    DESCRIPTION "A printable string."
+    SYNTAX OCTET STRING (SIZE (0..255))
+
+simRoot OBJECT IDENTIFIER ::= { iso 7 }
+
+simName OBJECT-TYPE
+    SYNTAX DisplayString (SIZE (0..8))
+    MAX-ACCESS read-write
+    STATUS current
+    DESCRIPTION "A name."
+    ::= { simRoot 1 }
+
+simLevel OBJECT-TYPE
+    SYNTAX INTEGER (-5..-1 | 10..20)
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A level."
+    ::= { simRoot 2 }
+
+simHidden OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "Not readable."
+    ::= { simRoot 3 }
+
+simTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF SimEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table."
+    ::= { simRoot 4 }
+
+simEntry OBJECT-TYPE
+    SYNTAX SimEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { simAddress, simKind }
+    ::= { simTable 1 }
+
+SimEntry ::= SEQUENCE {
+    simAddress IpAddress,
+    simKind    INTEGER,
+    simPackets Counter32
+}
+
+simAddress OBJECT-TYPE
+    SYNTAX IpAddress
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The address."
+    ::= { simEntry 1 }
+
+simKind OBJECT-TYPE
+    SYNTAX INTEGER { first(3), second(5) }
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The kind."
+    ::= { simEntry 2 }
+
+simPackets OBJECT-TYPE
+    SYNTAX Counter32
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A counter."
+    ::= { simEntry 3 }
+END
+`
+
+func loadSimModule(t *testing.T) gosmi.SmiModule {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SIM-MIB"), []byte(simMib), 0o644))
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("SIM-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("SIM-MIB")
+	require.NoError(t, err)
+	return module
+}
+
+func TestWriteSnmprec(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	module := loadSimModule(t)
+
+	records, err := simdata.Generate([]gosmi.SmiModule{module}, simdata.WithRows(3))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, simdata.WriteSnmprec(&buf, records))
+	assert.Equal(t, `1.7.1.0|4|simName1
+1.7.2.0|2|10
+1.7.4.1.3.192.0.2.1.3|65|0
+1.7.4.1.3.192.0.2.2.5|65|1
+1.7.4.1.3.192.0.2.3.3|65|2
+`, buf.String())
+}
+
+func TestWriteSnmpdConf(t *testing.T) {
+	gosmi.Init()
+	defer gosmi.Exit()
+	module := loadSimModule(t)
+
+	records, err := simdata.Generate([]gosmi.SmiModule{module}, simdata.WithRows(1))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, simdata.WriteSnmpdConf(&buf, records))
+	assert.Equal(t, `override .1.7.1.0 octet_str "simName1"
+override .1.7.2.0 integer 10
+override .1.7.4.1.3.192.0.2.1.3 counter 0
+`, buf.String())
+}
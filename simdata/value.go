@@ -0,0 +1,191 @@
+package simdata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Maximum length of generated strings whose size is not constrained
+const defaultStringSize = 32
+
+// nodeValue returns the value of the row'th instance of node. Values differ
+// between rows as long as the type allows it.
+func nodeValue(node gosmi.SmiNode, row int) (Tag, interface{}, error) {
+	if node.SmiType == nil {
+		return 0, nil, fmt.Errorf("Node %s has no type", node.Name)
+	}
+	t := node.Type
+	tag := typeTag(node.SmiType)
+
+	switch tag {
+	case TagIpAddress:
+		return tag, []byte{192, 0, 2, byte(row + 1)}, nil
+	case TagObjectIdentifier:
+		return tag, types.Oid{0, types.SmiSubId(row)}, nil
+	case TagOpaque:
+		return tag, []byte{byte(row)}, nil
+	case TagOctetString:
+		if t.BaseType == types.BaseTypeBits {
+			return tag, bitsValue(t.Enum), nil
+		}
+		return tag, stringValue(node.Name, t, row), nil
+	}
+
+	if t.Enum != nil && len(t.Enum.Values) > 0 {
+		return tag, t.Enum.Values[row%len(t.Enum.Values)].Value, nil
+	}
+	value, ok := nthValue(t.Ranges, int64(row)+1)
+	if !ok {
+		return 0, nil, fmt.Errorf("Ranges of %s admit no value", node.Name)
+	}
+	return tag, value, nil
+}
+
+// typeTag maps t to the SNMP type its values are transferred as, identifying
+// the application types by name anywhere in the derivation chain.
+func typeTag(t *gosmi.SmiType) Tag {
+	for _, smiType := range t.BaseTypeChain() {
+		switch smiType.Name {
+		case "IpAddress", "NetworkAddress":
+			return TagIpAddress
+		case "Counter32", "Counter":
+			return TagCounter32
+		case "Gauge32", "Gauge", "Unsigned32":
+			return TagGauge32
+		case "TimeTicks":
+			return TagTimeTicks
+		case "Counter64":
+			return TagCounter64
+		case "Opaque":
+			return TagOpaque
+		}
+	}
+	switch t.BaseType {
+	case types.BaseTypeOctetString, types.BaseTypeBits:
+		return TagOctetString
+	case types.BaseTypeObjectIdentifier:
+		return TagObjectIdentifier
+	case types.BaseTypeUnsigned32:
+		return TagGauge32
+	case types.BaseTypeUnsigned64:
+		return TagCounter64
+	}
+	return TagInteger
+}
+
+// nthValue returns the n'th smallest value allowed by ranges, or n itself if
+// there are none, wrapping around if there are fewer than n. Non-negative
+// values are counted first, so that results can be used in indexes.
+func nthValue(ranges []models.Range, n int64) (int64, bool) {
+	if len(ranges) == 0 {
+		return n, true
+	}
+	var ordered, negative []models.Range
+	for _, r := range ranges {
+		switch {
+		case r.MinValue > r.MaxValue:
+			continue
+		case r.MaxValue < 0:
+			negative = append(negative, r)
+		case r.MinValue < 0:
+			negative = append(negative, models.Range{MinValue: r.MinValue, MaxValue: -1})
+			r.MinValue = 0
+			fallthrough
+		default:
+			ordered = append(ordered, r)
+		}
+	}
+	ordered = append(ordered, negative...)
+
+	var total int64
+	for _, r := range ordered {
+		if total += r.MaxValue - r.MinValue + 1; total >= n {
+			break
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	remaining := (n - 1) % total
+	for _, r := range ordered {
+		if size := r.MaxValue - r.MinValue; remaining <= size {
+			return r.MinValue + remaining, true
+		}
+		remaining -= r.MaxValue - r.MinValue + 1
+	}
+	return 0, false
+}
+
+// stringValue returns a string for the row'th instance of the object name,
+// readable if the display hint allows it and of a length within the size
+// ranges of t.
+func stringValue(name string, t *models.Type, row int) []byte {
+	minSize, maxSize := int64(0), int64(defaultStringSize)
+	if len(t.Ranges) > 0 {
+		minSize, maxSize = t.Ranges[0].MinValue, t.Ranges[0].MaxValue
+		if maxSize-minSize > defaultStringSize {
+			maxSize = minSize + defaultStringSize
+		}
+	}
+
+	if !displayable(t.Format) {
+		value := make([]byte, minSize)
+		if minSize == 0 {
+			value = make([]byte, min64(4, maxSize))
+		}
+		if len(value) > 0 {
+			value[len(value)-1] = byte(row + 1)
+		}
+		return value
+	}
+
+	value := fmt.Sprintf("%s %d", name, row+1)
+	if int64(len(value)) > maxSize {
+		// Keep the row number so that values stay distinct
+		suffix := fmt.Sprintf("%d", row+1)
+		if int64(len(suffix)) > maxSize {
+			suffix = suffix[int64(len(suffix))-maxSize:]
+		}
+		value = name[:min64(int64(len(name)), maxSize-int64(len(suffix)))] + suffix
+	}
+	if pad := minSize - int64(len(value)); pad > 0 {
+		value += strings.Repeat(" ", int(pad))
+	}
+	return []byte(value)
+}
+
+func displayable(format string) bool {
+	return strings.HasSuffix(format, "a") || strings.HasSuffix(format, "t")
+}
+
+// bitsValue returns a value of the right size for the named bits with none of
+// them set.
+func bitsValue(enum *models.Enum) []byte {
+	var maxBit int64
+	if enum != nil {
+		for _, bit := range enum.Values {
+			if bit.Value > maxBit {
+				maxBit = bit.Value
+			}
+		}
+	}
+	return make([]byte, maxBit/8+1)
+}
+
+func fixedSize(ranges []models.Range) (int64, bool) {
+	if len(ranges) != 1 || ranges[0].MinValue != ranges[0].MaxValue {
+		return 0, false
+	}
+	return ranges[0].MinValue, true
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package simdata
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// WriteSnmprec writes records in the snmpsim .snmprec format, one
+// OID|TAG|VALUE line per record. Octet strings that are not printable are
+// hex encoded, which is flagged by an x after the tag.
+func WriteSnmprec(w io.Writer, records []Record) error {
+	bw := bufio.NewWriter(w)
+	for _, record := range records {
+		tag := strconv.Itoa(int(record.Tag))
+		var value string
+		switch v := record.Value.(type) {
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case types.Oid:
+			value = v.String()
+		case []byte:
+			if record.Tag == TagIpAddress {
+				value = net.IP(v).String()
+			} else if printable(v) {
+				value = string(v)
+			} else {
+				tag += "x"
+				value = hex.EncodeToString(v)
+			}
+		default:
+			return fmt.Errorf("Invalid value of %s: %T", record.Name, record.Value)
+		}
+		fmt.Fprintf(bw, "%s|%s|%s\n", record.Oid, tag, value)
+	}
+	return bw.Flush()
+}
+
+var snmpdTypes = map[Tag]string{
+	TagInteger:          "integer",
+	TagOctetString:      "octet_str",
+	TagNull:             "null",
+	TagObjectIdentifier: "object_id",
+	TagIpAddress:        "ipaddress",
+	TagCounter32:        "counter",
+	TagGauge32:          "unsigned",
+	TagTimeTicks:        "timeticks",
+	TagOpaque:           "octet_str",
+	TagCounter64:        "counter64",
+}
+
+// WriteSnmpdConf writes records as net-snmp snmpd.conf override directives.
+// Octet strings that are not printable are written in hex.
+func WriteSnmpdConf(w io.Writer, records []Record) error {
+	bw := bufio.NewWriter(w)
+	for _, record := range records {
+		typ, ok := snmpdTypes[record.Tag]
+		if !ok {
+			return fmt.Errorf("Invalid type of %s: %d", record.Name, record.Tag)
+		}
+		var value string
+		switch v := record.Value.(type) {
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case types.Oid:
+			value = "." + v.String()
+		case []byte:
+			if record.Tag == TagIpAddress {
+				value = net.IP(v).String()
+			} else if printable(v) {
+				value = strconv.Quote(string(v))
+			} else {
+				value = "0x" + hex.EncodeToString(v)
+			}
+		default:
+			return fmt.Errorf("Invalid value of %s: %T", record.Name, record.Value)
+		}
+		fmt.Fprintf(bw, "override .%s %s %s\n", record.Oid, typ, value)
+	}
+	return bw.Flush()
+}
+
+func printable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e || c == '|' {
+			return false
+		}
+	}
+	return true
+}
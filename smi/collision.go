@@ -0,0 +1,29 @@
+package smi
+
+import "github.com/lukeod/gosmi/smi/internal"
+
+type CollisionPolicy = internal.CollisionPolicy
+
+const (
+	CollisionFirst   = internal.CollisionFirst
+	CollisionLast    = internal.CollisionLast
+	CollisionError   = internal.CollisionError
+	CollisionQualify = internal.CollisionQualify
+)
+
+var ErrAmbiguousName = internal.ErrAmbiguousName
+
+// SetCollisionPolicy sets which definition GetNode and GetType without a
+// module return when several modules define the name. There is no libsmi
+// equivalent.
+func SetCollisionPolicy(policy CollisionPolicy) {
+	checkInit()
+	internal.SetCollisionPolicy(policy)
+}
+
+// GetCollisionPolicy returns the policy set with SetCollisionPolicy. There is
+// no libsmi equivalent.
+func GetCollisionPolicy() CollisionPolicy {
+	checkInit()
+	return internal.GetCollisionPolicy()
+}
@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/smi/internal"
 	"github.com/lukeod/gosmi/types"
 )
@@ -28,6 +30,15 @@ var DefaultSmiPaths []string = []string{
 
 type FS = internal.FS
 type NamedFS = internal.NamedFS
+type Fetcher = internal.Fetcher
+
+type DependencyMode = internal.DependencyMode
+
+const (
+	DependencyWarn = internal.DependencyWarn
+	DependencyFail = internal.DependencyFail
+	DependencyStub = internal.DependencyStub
+)
 
 func NewNamedFS(name string, fs FS) NamedFS { return NamedFS{Name: "[" + name + "]", FS: fs} }
 
@@ -90,6 +101,186 @@ func SetFlags(userflags int) {
 	internal.SetFlags(userflags)
 }
 
+// SetDependencyMode sets how a failure to load an imported module is handled
+// while loading a module. There is no libsmi equivalent.
+func SetDependencyMode(mode DependencyMode) {
+	checkInit()
+	internal.SetDependencyMode(mode)
+}
+
+// SetRetainAST sets whether modules keep the AST they were built from, as
+// returned by GetModuleAST. Discarding it lets the source of each module be
+// freed once built, as the resolved module interns its strings. There is no
+// libsmi equivalent.
+func SetRetainAST(retain bool) {
+	checkInit()
+	internal.SetRetainAST(retain)
+}
+
+// SetLazyText sets whether modules loaded from now on skip storing their
+// DESCRIPTION, REFERENCE and CONTACT-INFO texts, which GetNodeText,
+// GetTypeText and GetModuleText then load from the module files on demand.
+// There is no libsmi equivalent.
+func SetLazyText(lazy bool) {
+	checkInit()
+	internal.SetLazyText(lazy)
+}
+
+type TextNormalization = internal.TextNormalization
+
+// SetTextNormalization sets how the texts of modules loaded from now on are
+// normalized before they are stored, and those GetNodeText, GetTypeText and
+// GetModuleText load on demand. There is no libsmi equivalent.
+func SetTextNormalization(normalization TextNormalization) {
+	checkInit()
+	internal.SetTextNormalization(normalization)
+}
+
+// GetTextNormalization returns how the texts of modules are normalized. There
+// is no libsmi equivalent.
+func GetTextNormalization() TextNormalization {
+	checkInit()
+	return internal.GetTextNormalization()
+}
+
+// SetLimits sets the resource limits enforced while parsing modules. There is
+// no libsmi equivalent.
+func SetLimits(limits parser.Limits) {
+	checkInit()
+	internal.SetLimits(limits)
+}
+
+// GetLimits returns the resource limits enforced while parsing modules. There
+// is no libsmi equivalent.
+func GetLimits() parser.Limits {
+	checkInit()
+	return internal.GetLimits()
+}
+
+// SetCoreTypes sets whether imports of the application types of SNMPv2-SMI,
+// such as Counter32 and IpAddress, resolve to built-in definitions when the
+// module imported from cannot be loaded. There is no libsmi equivalent.
+func SetCoreTypes(enabled bool) {
+	checkInit()
+	internal.SetCoreTypes(enabled)
+}
+
+// GetCoreTypes returns whether core types are enabled. There is no libsmi
+// equivalent.
+func GetCoreTypes() bool {
+	checkInit()
+	return internal.GetCoreTypes()
+}
+
+// SetWrappedQuoted sets whether hex and bin strings may have whitespace and
+// line breaks between their digits, see parser.WithWrappedQuoted. There is no
+// libsmi equivalent.
+func SetWrappedQuoted(wrapped bool) {
+	checkInit()
+	internal.SetWrappedQuoted(wrapped)
+}
+
+// GetWrappedQuoted returns whether hex and bin strings may have whitespace
+// between their digits. There is no libsmi equivalent.
+func GetWrappedQuoted() bool {
+	checkInit()
+	return internal.GetWrappedQuoted()
+}
+
+// SetRecovery sets whether the definitions of modules with illegal characters
+// are skipped rather than failing the module, see parser.WithRecovery. There
+// is no libsmi equivalent.
+func SetRecovery(recovery bool) {
+	checkInit()
+	internal.SetRecovery(recovery)
+}
+
+// GetRecovery returns whether definitions with illegal characters are
+// skipped. There is no libsmi equivalent.
+func GetRecovery() bool {
+	checkInit()
+	return internal.GetRecovery()
+}
+
+// SetDialects sets the registered parser dialects modules are parsed with,
+// see parser.RegisterDialect. There is no libsmi equivalent.
+func SetDialects(names ...string) {
+	checkInit()
+	internal.SetDialects(names)
+}
+
+// GetDialects returns the parser dialects modules are parsed with. There is no
+// libsmi equivalent.
+func GetDialects() []string {
+	checkInit()
+	return internal.GetDialects()
+}
+
+// SetStrict sets whether modules are parsed in strict mode, failing to load if
+// they use constructs SMI does not allow, see parser.WithStrict. There is no
+// libsmi equivalent.
+func SetStrict(strict bool) {
+	checkInit()
+	internal.SetStrict(strict)
+}
+
+// GetStrict returns whether modules are parsed in strict mode. There is no
+// libsmi equivalent.
+func GetStrict() bool {
+	checkInit()
+	return internal.GetStrict()
+}
+
+// SetModuleAliases sets the names of the files providing modules not named
+// after them, keyed by module name. There is no libsmi equivalent.
+func SetModuleAliases(aliases map[string]string) {
+	checkInit()
+	internal.SetModuleAliases(aliases)
+}
+
+// GetModuleAliases returns the aliases set with SetModuleAliases. There is no
+// libsmi equivalent.
+func GetModuleAliases() map[string]string {
+	checkInit()
+	return internal.GetModuleAliases()
+}
+
+// SetExcludedFiles sets the glob patterns of the files never loaded from the
+// search path. There is no libsmi equivalent.
+func SetExcludedFiles(patterns []string) error {
+	checkInit()
+	return internal.SetExcludedFiles(patterns)
+}
+
+// GetExcludedFiles returns the patterns set with SetExcludedFiles. There is
+// no libsmi equivalent.
+func GetExcludedFiles() []string {
+	checkInit()
+	return internal.GetExcludedFiles()
+}
+
+// IsExcludedFile returns whether the file at name, relative to the directory
+// it is in the search path or compiled from, matches an excluded pattern.
+// There is no libsmi equivalent.
+func IsExcludedFile(name string) bool {
+	checkInit()
+	return internal.IsExcludedFile(name)
+}
+
+// SetFetcher sets where modules not found in the search path are fetched
+// from, or disables fetching if fetcher is nil. There is no libsmi equivalent.
+func SetFetcher(fetcher Fetcher) {
+	checkInit()
+	internal.SetFetcher(fetcher)
+}
+
+// Diagnostics returns the collector holding everything reported while loading
+// modules into the current handle. There is no libsmi equivalent.
+func Diagnostics() *diag.Collector {
+	checkInit()
+	return internal.Diagnostics()
+}
+
 // char *smiGetPath(void)
 func GetPath() string {
 	checkInit()
@@ -131,3 +322,31 @@ func SetErrorHandler(smiErrorHandler types.SmiErrorHandler) {
 func SetFS(fs ...NamedFS)     { internal.SetFS(fs...) }
 func AppendFS(fs ...NamedFS)  { internal.AppendFS(fs...) }
 func PrependFS(fs ...NamedFS) { internal.PrependFS(fs...) }
+
+type RootNode = internal.RootNode
+
+// RegisterRootNode adds a well-known node which modules can refer to by name
+// without importing it, such as an anchor defined by a module that is not
+// available. Registrations persist across Exit and Init. There is no libsmi
+// equivalent.
+func RegisterRootNode(name string, oid types.Oid) error {
+	return internal.RegisterRootNode(types.SmiIdentifier(name), oid)
+}
+
+// GetRootNodes returns the well-known nodes. There is no libsmi equivalent.
+func GetRootNodes() []RootNode { return internal.GetRootNodes() }
+
+// ResetRootNodes drops the registered well-known nodes from the next Init on,
+// keeping only ccitt, iso and joint-iso-ccitt. There is no libsmi equivalent.
+func ResetRootNodes() { internal.ResetRootNodes() }
+
+// RegisterSNMPRootNodes registers the anchors of the internet subtree, from
+// org to snmpV2, as well-known nodes. There is no libsmi equivalent.
+func RegisterSNMPRootNodes() error {
+	for _, root := range internal.SNMPRootNodes {
+		if err := internal.RegisterRootNode(root.Name, root.Oid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
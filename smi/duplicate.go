@@ -0,0 +1,25 @@
+package smi
+
+import "github.com/lukeod/gosmi/smi/internal"
+
+type DuplicatePolicy = internal.DuplicatePolicy
+
+const (
+	DuplicateFirst = internal.DuplicateFirst
+	DuplicateLast  = internal.DuplicateLast
+)
+
+// SetDuplicatePolicy sets which definition is kept when a module defines the
+// same descriptor more than once. The others are dropped and reported with
+// diag.CodeDuplicateDefinition. There is no libsmi equivalent.
+func SetDuplicatePolicy(policy DuplicatePolicy) {
+	checkInit()
+	internal.SetDuplicatePolicy(policy)
+}
+
+// GetDuplicatePolicy returns the policy set with SetDuplicatePolicy. There is
+// no libsmi equivalent.
+func GetDuplicatePolicy() DuplicatePolicy {
+	checkInit()
+	return internal.GetDuplicatePolicy()
+}
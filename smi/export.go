@@ -0,0 +1,41 @@
+package smi
+
+import (
+	"unsafe"
+
+	"github.com/lukeod/gosmi/smi/internal"
+	"github.com/lukeod/gosmi/types"
+)
+
+type ExportPolicy = internal.ExportPolicy
+
+const (
+	ExportIgnore  = internal.ExportIgnore
+	ExportWarn    = internal.ExportWarn
+	ExportEnforce = internal.ExportEnforce
+)
+
+// SetExportPolicy sets how imports of names a module does not export are
+// handled. They are reported with diag.CodeNotExported unless ignored. There
+// is no libsmi equivalent.
+func SetExportPolicy(policy ExportPolicy) {
+	checkInit()
+	internal.SetExportPolicy(policy)
+}
+
+// GetExportPolicy returns the policy set with SetExportPolicy. There is no
+// libsmi equivalent.
+func GetExportPolicy() ExportPolicy {
+	checkInit()
+	return internal.GetExportPolicy()
+}
+
+// GetModuleExports returns the names of the EXPORTS clause of the module, nil
+// if it has none. There is no libsmi equivalent.
+func GetModuleExports(smiModulePtr *types.SmiModule) []types.SmiIdentifier {
+	if smiModulePtr == nil {
+		return nil
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	return modulePtr.Exports
+}
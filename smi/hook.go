@@ -0,0 +1,39 @@
+package smi
+
+import (
+	"github.com/lukeod/gosmi/smi/internal"
+)
+
+type PreParseHook = internal.PreParseHook
+type PostResolveHook = internal.PostResolveHook
+type SymbolResolver = internal.SymbolResolver
+
+// AddPreParseHook adds a hook rewriting the source of modules before they
+// are parsed. There is no libsmi equivalent.
+func AddPreParseHook(hook PreParseHook) {
+	checkInit()
+	internal.AddPreParseHook(hook)
+}
+
+// AddPostResolveHook adds a hook called with each module once built. There is
+// no libsmi equivalent.
+func AddPostResolveHook(hook PostResolveHook) {
+	checkInit()
+	internal.AddPostResolveHook(hook)
+}
+
+// SetSymbolResolver sets what type names modules use without defining or
+// importing them are mapped to, or removes the mapping if resolver is nil.
+// There is no libsmi equivalent.
+func SetSymbolResolver(resolver SymbolResolver) {
+	checkInit()
+	internal.SetSymbolResolver(resolver)
+}
+
+// PreParse runs the source of the module at path through the pre-parse
+// hooks, for parsing modules outside of the handle the way it would. There is
+// no libsmi equivalent.
+func PreParse(path string, src []byte) ([]byte, error) {
+	checkInit()
+	return internal.PreParse(path, src)
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SetModuleAliases sets the names of the files providing modules that are not
+// named after the module, such as rfc1213.mib for RFC1213-MIB. An alias is a
+// file name, or a base name completed with the usual MIB extensions, looked
+// up in the search path when no file is named after the module.
+func SetModuleAliases(aliases map[string]string) {
+	smiHandle.ModuleAliases = make(map[string]string, len(aliases))
+	for name, alias := range aliases {
+		smiHandle.ModuleAliases[name] = alias
+	}
+}
+
+func GetModuleAliases() map[string]string {
+	return smiHandle.ModuleAliases
+}
+
+// SetExcludedFiles sets the glob patterns, as of path.Match, of the files
+// never loaded from the search path. A pattern without a slash matches the
+// file name, one with a slash the path relative to the directory searched.
+func SetExcludedFiles(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("Invalid pattern %q: %w", pattern, err)
+		}
+	}
+	smiHandle.ExcludedFiles = append([]string(nil), patterns...)
+	return nil
+}
+
+func GetExcludedFiles() []string {
+	return smiHandle.ExcludedFiles
+}
+
+// IsExcludedFile returns whether the file at name, relative to the directory
+// searched, matches one of the excluded patterns.
+func IsExcludedFile(name string) bool {
+	name = filepath.ToSlash(name)
+	base := path.Base(name)
+	for _, pattern := range smiHandle.ExcludedFiles {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = base
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
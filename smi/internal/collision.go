@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CollisionPolicy controls which definition a lookup not qualified by a
+// module returns when several loaded modules define the name.
+type CollisionPolicy int
+
+const (
+	// CollisionFirst returns the definition of the module loaded first.
+	CollisionFirst CollisionPolicy = iota
+	// CollisionLast returns the definition of the module loaded last.
+	CollisionLast
+	// CollisionError fails if the definitions differ: nodes with different
+	// OIDs, or types with different base or parent types. Modules defining
+	// the name alike, as when a module repeats the definitions of another,
+	// resolve to the first.
+	CollisionError
+	// CollisionQualify fails whenever more than one module defines the name,
+	// so that it must be looked up in a given module.
+	CollisionQualify
+)
+
+// ErrAmbiguousName is returned by lookups not qualified by a module under
+// CollisionError or CollisionQualify when several modules define the name.
+var ErrAmbiguousName = errors.New("Name defined by several modules")
+
+func SetCollisionPolicy(policy CollisionPolicy) {
+	smiHandle.CollisionPolicy = policy
+}
+
+func GetCollisionPolicy() CollisionPolicy {
+	return smiHandle.CollisionPolicy
+}
+
+// LookupObject returns the object named name in the modules of the current
+// view, chosen according to the collision policy, or nil if none is.
+func LookupObject(name string) (*Object, error) {
+	var found []*Object
+	for module := GetFirstModule(); module != nil; module = GetNextModule(module) {
+		if obj := module.Objects.GetName(name); obj != nil {
+			if smiHandle.CollisionPolicy == CollisionFirst {
+				return obj, nil
+			}
+			found = append(found, obj)
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	switch smiHandle.CollisionPolicy {
+	case CollisionLast:
+		return found[len(found)-1], nil
+	case CollisionError:
+		for _, obj := range found[1:] {
+			if obj.Oid.String() != found[0].Oid.String() {
+				return nil, ambiguousName(name, len(found), func(i int) *Module { return found[i].Module })
+			}
+		}
+	case CollisionQualify:
+		if len(found) > 1 {
+			return nil, ambiguousName(name, len(found), func(i int) *Module { return found[i].Module })
+		}
+	}
+	return found[0], nil
+}
+
+// LookupType returns the type named name in the modules of the current view,
+// chosen according to the collision policy, or nil if none is.
+func LookupType(name string) (*Type, error) {
+	var found []*Type
+	for module := GetFirstModule(); module != nil; module = GetNextModule(module) {
+		if typ := module.Types.GetName(name); typ != nil {
+			if smiHandle.CollisionPolicy == CollisionFirst {
+				return typ, nil
+			}
+			found = append(found, typ)
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	switch smiHandle.CollisionPolicy {
+	case CollisionLast:
+		return found[len(found)-1], nil
+	case CollisionError:
+		for _, typ := range found[1:] {
+			if !sameType(typ, found[0]) {
+				return nil, ambiguousName(name, len(found), func(i int) *Module { return found[i].Module })
+			}
+		}
+	case CollisionQualify:
+		if len(found) > 1 {
+			return nil, ambiguousName(name, len(found), func(i int) *Module { return found[i].Module })
+		}
+	}
+	return found[0], nil
+}
+
+func sameType(a, b *Type) bool {
+	if a.BaseType != b.BaseType || (a.Parent == nil) != (b.Parent == nil) {
+		return false
+	}
+	return a.Parent == nil || a.Parent.Name == b.Parent.Name
+}
+
+func ambiguousName(name string, n int, module func(int) *Module) error {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = module(i).Name.String() + "::" + name
+	}
+	return fmt.Errorf("%w: %s", ErrAmbiguousName, strings.Join(names, ", "))
+}
@@ -52,8 +52,8 @@ func expandPath(path string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("Cannot stat '%s': %w", path, err)
 	}
-	if !info.IsDir() {
-		return "", fmt.Errorf("'%s' is not a directory", path)
+	if !info.IsDir() && !(info.Mode().IsRegular() && isArchive(path)) {
+		return "", fmt.Errorf("'%s' is not a directory or archive", path)
 	}
 	return path, nil
 }
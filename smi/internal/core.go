@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"github.com/lukeod/gosmi/types"
+)
+
+// coreTypeDefs are the application types of SNMPv2-SMI, and the Counter and
+// Gauge of RFC1155-SMI, as defined there. BITS, OBJECT IDENTIFIER and the
+// other ASN.1 types need no module.
+var coreTypeDefs = []struct {
+	name     types.SmiIdentifier
+	parent   func() *Type
+	tag      int // APPLICATION tag, -1 for none
+	min, max string
+}{
+	{"Integer32", func() *Type { return smiHandle.TypeInteger32 }, -1, "-2147483648", "2147483647"},
+	{"IpAddress", func() *Type { return smiHandle.TypeOctetString }, 0, "4", "4"},
+	{"Counter32", func() *Type { return smiHandle.TypeUnsigned32 }, 1, "0", "4294967295"},
+	{"Gauge32", func() *Type { return smiHandle.TypeUnsigned32 }, 2, "0", "4294967295"},
+	{"Unsigned32", func() *Type { return smiHandle.TypeUnsigned32 }, 2, "0", "4294967295"},
+	{"TimeTicks", func() *Type { return smiHandle.TypeUnsigned32 }, 3, "0", "4294967295"},
+	{"Opaque", func() *Type { return smiHandle.TypeOctetString }, 4, "", ""},
+	{"Counter64", func() *Type { return smiHandle.TypeUnsigned64 }, 6, "0", "18446744073709551615"},
+	{"Counter", func() *Type { return smiHandle.TypeUnsigned32 }, 1, "0", "4294967295"},
+	{"Gauge", func() *Type { return smiHandle.TypeUnsigned32 }, 2, "0", "4294967295"},
+}
+
+func SetCoreTypes(enabled bool) {
+	smiHandle.CoreTypes = enabled
+}
+
+func GetCoreTypes() bool {
+	return smiHandle.CoreTypes
+}
+
+// coreType returns the built-in definition of the core type name, if core
+// types are enabled, for imports of it from modules that are not available.
+// The types belong to the well-known module.
+func coreType(name types.SmiIdentifier) *Type {
+	if !smiHandle.CoreTypes {
+		return nil
+	}
+	if smiHandle.coreTypes == nil {
+		wellKnown := smiHandle.Modules.Get(WellKnownModuleName)
+		smiHandle.coreTypes = make(map[types.SmiIdentifier]*Type, len(coreTypeDefs))
+		for _, def := range coreTypeDefs {
+			parent := def.parent()
+			t := &Type{
+				SmiType: types.SmiType{
+					Name:     def.name,
+					BaseType: parent.BaseType,
+					Decl:     types.DeclTypeAssignment,
+					Status:   types.StatusCurrent,
+				},
+				Module: wellKnown,
+				Parent: parent,
+			}
+			if def.tag >= 0 {
+				t.Flags |= FlagTagged
+				t.Tag = def.tag
+			}
+			if def.min != "" {
+				// Sizes of OCTET STRING types are Unsigned32
+				baseType := t.BaseType
+				if baseType == types.BaseTypeOctetString {
+					baseType = types.BaseTypeUnsigned32
+				}
+				t.AddRange(GetValue(def.min, baseType), GetValue(def.max, baseType))
+			}
+			smiHandle.coreTypes[def.name] = t
+		}
+	}
+	return smiHandle.coreTypes[name]
+}
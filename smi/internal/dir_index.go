@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+)
+
+// indexFileName is the name of the index gosmi.WriteDirIndex writes at the
+// root of a MIB directory, and the version of its format read.
+const (
+	indexFileName   = ".gosmi-index"
+	dirIndexVersion = 1
+)
+
+// dirIndex is the part of a directory's index the module lookup uses.
+type dirIndex struct {
+	Version int `json:"version"`
+	// Dirs are the modification times, in Unix nanoseconds, of the
+	// directories indexed, by slash separated path
+	Dirs  map[string]int64 `json:"dirs"`
+	Files []struct {
+		Path   string `json:"path"`
+		Module string `json:"module"`
+	} `json:"files"`
+}
+
+// cachedIndex is the index of a search path directory read at the
+// modification time of its file.
+type cachedIndex struct {
+	mtime int64
+	index *dirIndex
+}
+
+// pathIndex returns the index of the search path directory p, if it has one
+// and none of the directories indexed changed since it was written. Indexes
+// are read once, and again when their file changes.
+func pathIndex(p NamedFS) *dirIndex {
+	info, err := stat(p.FS, indexFileName)
+	if err != nil {
+		delete(smiHandle.indexes, p.Name)
+		return nil
+	}
+	cached, ok := smiHandle.indexes[p.Name]
+	if mtime := info.ModTime().UnixNano(); !ok || cached.mtime != mtime {
+		cached = cachedIndex{mtime: mtime, index: readDirIndex(p.FS)}
+		if smiHandle.indexes == nil {
+			smiHandle.indexes = make(map[string]cachedIndex)
+		}
+		smiHandle.indexes[p.Name] = cached
+	}
+	if cached.index == nil || !cached.index.fresh(p.FS) {
+		return nil
+	}
+	return cached.index
+}
+
+// readDirIndex reads the index of fsys, returning nil if it cannot be read or
+// is of a version not known.
+func readDirIndex(fsys FS) *dirIndex {
+	f, err := fsys.Open(indexFileName)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+	var idx dirIndex
+	if json.Unmarshal(data, &idx) != nil || idx.Version != dirIndexVersion {
+		return nil
+	}
+	return &idx
+}
+
+// fresh reports whether none of the directories of the index changed since
+// it was written, so that it lists all the files in them.
+func (idx *dirIndex) fresh(fsys FS) bool {
+	for rel, mtime := range idx.Dirs {
+		info, err := stat(fsys, path.Clean(rel))
+		if err != nil || !info.IsDir() || info.ModTime().UnixNano() != mtime {
+			return false
+		}
+	}
+	return len(idx.Dirs) > 0
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alecthomas/participle/v2/lexer"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// DuplicatePolicy controls which definition is kept when a module defines
+// the same descriptor more than once.
+type DuplicatePolicy int
+
+const (
+	// DuplicateFirst keeps the definition coming first in the module.
+	DuplicateFirst DuplicatePolicy = iota
+	// DuplicateLast keeps the definition coming last in the module.
+	DuplicateLast
+)
+
+func SetDuplicatePolicy(policy DuplicatePolicy) {
+	smiHandle.DuplicatePolicy = policy
+}
+
+func GetDuplicatePolicy() DuplicatePolicy {
+	return smiHandle.DuplicatePolicy
+}
+
+// Kinds of definitions of a module body
+const (
+	definedType = iota
+	definedValue
+	definedNode
+	definedInvocation
+)
+
+// definition is the index-th definition of its kind in a module body
+type definition struct {
+	name  types.SmiIdentifier
+	pos   lexer.Position
+	kind  int
+	index int
+}
+
+// dropDuplicates returns the body of the module without the definitions of
+// descriptors it defines more than once but the one kept under the duplicate
+// policy, reporting the others. The module itself is left unchanged.
+func dropDuplicates(path string, in *parser.Module) parser.ModuleBody {
+	body := in.Body
+	var defs []definition
+	for i, t := range body.Types {
+		defs = append(defs, definition{name: t.Name, pos: t.Pos, kind: definedType, index: i})
+	}
+	for i, v := range body.Values {
+		defs = append(defs, definition{name: v.Name, pos: v.Pos, kind: definedValue, index: i})
+	}
+	for i, n := range body.Nodes {
+		defs = append(defs, definition{name: n.Name, pos: n.Pos, kind: definedNode, index: i})
+	}
+	for i, invocation := range body.Invocations {
+		defs = append(defs, definition{name: invocation.Name, pos: invocation.Pos, kind: definedInvocation, index: i})
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].pos.Offset < defs[j].pos.Offset })
+
+	byName := make(map[types.SmiIdentifier][]definition, len(defs))
+	var duplicated []types.SmiIdentifier
+	for _, def := range defs {
+		if len(byName[def.name]) == 1 {
+			duplicated = append(duplicated, def.name)
+		}
+		byName[def.name] = append(byName[def.name], def)
+	}
+	if len(duplicated) == 0 {
+		return body
+	}
+
+	dropped := make(map[definition]bool)
+	for _, name := range duplicated {
+		same := byName[name]
+		kept, which := same[0], "first"
+		if smiHandle.DuplicatePolicy == DuplicateLast {
+			kept, which = same[len(same)-1], "last"
+		}
+		for i, def := range same[1:] {
+			prev := same[i]
+			reportDiagnostic(diag.Diagnostic{
+				Severity: diag.CodeSeverity(diag.CodeDuplicateDefinition),
+				Pos:      diag.Position{Filename: path, Offset: def.pos.Offset, Line: def.pos.Line, Column: def.pos.Column},
+				Code:     diag.CodeDuplicateDefinition,
+				Message:  fmt.Sprintf("%s is already defined at line %d, keeping the %s definition", name, prev.pos.Line, which),
+				Module:   in.Name.String(),
+				Related:  []diag.Position{{Filename: path, Offset: prev.pos.Offset, Line: prev.pos.Line, Column: prev.pos.Column}},
+			})
+		}
+		for _, def := range same {
+			if def != kept {
+				dropped[def] = true
+			}
+		}
+	}
+
+	var (
+		typs        []parser.Type
+		values      []parser.ValueAssignment
+		nodes       []parser.Node
+		invocations []parser.MacroInvocation
+	)
+	for _, def := range defs {
+		if dropped[def] {
+			continue
+		}
+		switch def.kind {
+		case definedType:
+			typs = append(typs, body.Types[def.index])
+		case definedValue:
+			values = append(values, body.Values[def.index])
+		case definedNode:
+			nodes = append(nodes, body.Nodes[def.index])
+		case definedInvocation:
+			invocations = append(invocations, body.Invocations[def.index])
+		}
+	}
+	body.Types, body.Values, body.Nodes, body.Invocations = typs, values, nodes, invocations
+	return body
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/types"
+)
+
+// ExportPolicy controls how imports of names a module leaves out of its
+// EXPORTS clause are handled. Modules without an EXPORTS clause export every
+// name they define.
+type ExportPolicy int
+
+const (
+	// ExportIgnore resolves imports regardless of EXPORTS clauses.
+	ExportIgnore ExportPolicy = iota
+	// ExportWarn reports imports of names not exported, which still
+	// resolve.
+	ExportWarn
+	// ExportEnforce reports imports of names not exported and leaves them
+	// unresolved, as if the name was not imported.
+	ExportEnforce
+)
+
+func SetExportPolicy(policy ExportPolicy) {
+	smiHandle.ExportPolicy = policy
+}
+
+func GetExportPolicy() ExportPolicy {
+	return smiHandle.ExportPolicy
+}
+
+// exports returns whether the module makes name visible to the modules
+// importing from it.
+func (x *Module) exports(name types.SmiIdentifier) bool {
+	if x.Exports == nil {
+		return true
+	}
+	for _, exported := range x.Exports {
+		if exported == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExports reports the imports of module of names the modules imported
+// from do not export, hiding them under ExportEnforce. Imports of modules
+// that are not loaded are left to loadImports.
+func checkExports(path string, module *Module) {
+	if smiHandle.ExportPolicy == ExportIgnore {
+		return
+	}
+	for i := module.Imports.First; i != nil; i = i.Next {
+		from := smiHandle.Modules.Get(i.Module)
+		if from == nil || from.exports(i.Name) {
+			continue
+		}
+		report(diag.CodeNotExported, module.Name, path, i.Line, fmt.Sprintf("Symbol %s is imported from %s, which does not export it", i.Name, i.Module))
+		i.Hidden = smiHandle.ExportPolicy == ExportEnforce
+	}
+}
@@ -1,10 +1,20 @@
 package internal
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// Fetcher provides modules that are not found in the search path, for
+// example by downloading them. FetchModule returns an error wrapping
+// os.ErrNotExist if it does not know the module either.
+type Fetcher interface {
+	FetchModule(ctx context.Context, name string) (path string, r io.ReadCloser, err error)
+}
+
 type NamedFS struct {
 	Name string
 	FS   FS
@@ -12,10 +22,28 @@ type NamedFS struct {
 
 type pathFS string
 
+// newPathFS returns the directory at path, or the modules in it if it is a
+// ZIP or tar archive.
 func newPathFS(path string) NamedFS {
+	if isArchive(path) {
+		return NamedFS{path, newArchiveFS(path)}
+	}
 	return NamedFS{path, pathFS(path)}
 }
 
+// Archive extensions recognized in the path
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p pathFS) Open(name string) (File, error) {
 	filename := filepath.Join(string(p), name)
 	return os.Open(filename)
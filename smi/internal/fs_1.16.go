@@ -19,3 +19,8 @@ func (p pathFS) ReadDir(name string) ([]DirEntry, error) {
 	}
 	return os.ReadDir(path)
 }
+
+// stat returns the file info of the file name in fsys.
+func stat(fsys FS, name string) (os.FileInfo, error) {
+	return fs.Stat(fsys, name)
+}
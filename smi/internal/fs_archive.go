@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// archiveFS serves the files of a ZIP or tar archive as if they were all in
+// one directory, so that vendor MIB packs can be used without extracting
+// them. Files in nested directories are found by their base name, the first
+// one in archive order winning. The archive is read into memory on first
+// use, up to maxArchiveBytes.
+type archiveFS struct {
+	path string
+
+	once    sync.Once
+	err     error
+	size    int
+	byPath  map[string][]byte
+	byBase  map[string][]byte
+	entries []DirEntry
+}
+
+// The most an archive's files are read to. Files larger than the parser's
+// byte limit, or maxArchiveFileBytes without one, and archives whose files
+// add up to more than maxArchiveBytes, are rejected, so that a small
+// compressed archive cannot exhaust memory.
+const (
+	maxArchiveFileBytes = 16 << 20
+	maxArchiveBytes     = 512 << 20
+)
+
+func newArchiveFS(path string) *archiveFS {
+	return &archiveFS{path: path}
+}
+
+func (a *archiveFS) Open(name string) (File, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	b, ok := a.byPath[name]
+	if !ok {
+		b, ok = a.byBase[name]
+	}
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return newArchiveFile(path.Base(name), b), nil
+}
+
+func (a *archiveFS) ReadDir(name string) ([]DirEntry, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	if name != "." {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: os.ErrNotExist}
+	}
+	return a.entries, nil
+}
+
+func (a *archiveFS) load() error {
+	a.once.Do(func() {
+		a.byPath = make(map[string][]byte)
+		a.byBase = make(map[string][]byte)
+		lower := strings.ToLower(a.path)
+		if strings.HasSuffix(lower, ".zip") {
+			a.err = a.loadZip()
+		} else {
+			a.err = a.loadTar(strings.HasSuffix(lower, "gz"))
+		}
+		if a.err != nil {
+			a.err = fmt.Errorf("Read archive %s: %w", a.path, a.err)
+			return
+		}
+		sort.Slice(a.entries, func(i, j int) bool { return a.entries[i].Name() < a.entries[j].Name() })
+	})
+	return a.err
+}
+
+func (a *archiveFS) loadZip() error {
+	r, err := zip.OpenReader(a.path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("Open %s: %w", f.Name, err)
+		}
+		err = a.add(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *archiveFS) loadTar(gzipped bool) error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := a.add(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// add stores a file of the archive. Files larger than the parser's byte limit
+// are truncated just past it, which is enough for the parser to reject them
+// without holding decompressed data of unbounded size. Without a limit, files
+// larger than maxArchiveFileBytes fail the archive.
+func (a *archiveFS) add(name string, r io.Reader) error {
+	max := smiHandle.Limits.MaxBytes
+	if max <= 0 {
+		max = maxArchiveFileBytes
+	}
+	b, err := io.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return fmt.Errorf("Read %s: %w", name, err)
+	}
+	if len(b) > max && smiHandle.Limits.MaxBytes <= 0 {
+		return fmt.Errorf("File %s exceeds %d bytes", name, maxArchiveFileBytes)
+	}
+	if a.size += len(b); a.size > maxArchiveBytes {
+		return fmt.Errorf("Files exceed %d bytes", maxArchiveBytes)
+	}
+	name = path.Clean(name)
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return nil
+	}
+	a.byPath[name] = b
+	if _, ok := a.byBase[base]; !ok {
+		a.byBase[base] = b
+		a.entries = append(a.entries, newArchiveEntry(base, len(b)))
+	}
+	return nil
+}
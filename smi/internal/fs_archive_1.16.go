@@ -0,0 +1,43 @@
+//go:build go1.16
+// +build go1.16
+
+package internal
+
+import (
+	"bytes"
+	"io/fs"
+	"time"
+)
+
+// archiveFile is a file of an archiveFS, held in memory.
+type archiveFile struct {
+	*bytes.Reader
+	info archiveInfo
+}
+
+func newArchiveFile(name string, b []byte) File {
+	return &archiveFile{Reader: bytes.NewReader(b), info: archiveInfo{name: name, size: len(b)}}
+}
+
+func (f *archiveFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *archiveFile) Close() error               { return nil }
+
+func newArchiveEntry(name string, size int) DirEntry {
+	return archiveInfo{name: name, size: size}
+}
+
+// archiveInfo describes a regular file of an archiveFS, as both fs.FileInfo
+// and fs.DirEntry.
+type archiveInfo struct {
+	name string
+	size int
+}
+
+func (i archiveInfo) Name() string               { return i.name }
+func (i archiveInfo) Size() int64                { return int64(i.size) }
+func (i archiveInfo) Mode() fs.FileMode          { return 0o444 }
+func (i archiveInfo) ModTime() time.Time         { return time.Time{} }
+func (i archiveInfo) IsDir() bool                { return false }
+func (i archiveInfo) Sys() interface{}           { return nil }
+func (i archiveInfo) Type() fs.FileMode          { return 0 }
+func (i archiveInfo) Info() (fs.FileInfo, error) { return i, nil }
@@ -0,0 +1,23 @@
+//go:build !go1.16
+// +build !go1.16
+
+package internal
+
+import (
+	"bytes"
+	"io/ioutil"
+)
+
+func newArchiveFile(name string, b []byte) File {
+	return ioutil.NopCloser(bytes.NewReader(b))
+}
+
+func newArchiveEntry(name string, size int) DirEntry {
+	return archiveEntry(name)
+}
+
+// archiveEntry is a regular file of an archiveFS.
+type archiveEntry string
+
+func (e archiveEntry) Name() string { return string(e) }
+func (e archiveEntry) IsDir() bool  { return false }
@@ -44,3 +44,13 @@ func (p pathFS) ReadDir(name string) ([]DirEntry, error) {
 	}
 	return dirEntries, nil
 }
+
+// stat returns the file info of the file name in fsys, which only directories
+// of the file system provide.
+func stat(fsys FS, name string) (os.FileInfo, error) {
+	p, ok := fsys.(pathFS)
+	if !ok {
+		return nil, os.ErrInvalid
+	}
+	return os.Stat(filepath.Join(string(p), name))
+}
@@ -1,6 +1,9 @@
 package internal
 
 import (
+	"context"
+
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/types"
 )
@@ -33,8 +36,59 @@ type Handle struct {
 	CacheProg            string
 	ErrorLevel           int
 	ErrorHandler         types.SmiErrorHandler
+	DependencyMode       DependencyMode
+	Limits               parser.Limits
+	Dialects             []string
+	Strict               bool
+	WrappedQuoted        bool
+	Recovery             bool
+	Fetcher              Fetcher
+	Metrics              Metrics
+	VersionPolicy        VersionPolicy
+	CollisionPolicy      CollisionPolicy
+	ExportPolicy         ExportPolicy
+	CoreTypes            bool
+	DuplicatePolicy      DuplicatePolicy
+	PreferredPaths       map[string]string
+	ModuleAliases        map[string]string
+	ExcludedFiles        []string
+	Diagnostics          diag.Collector
+	DiscardAST           bool
+	LazyText             bool
+	TextNormalization    TextNormalization
+
+	parsed   map[types.SmiIdentifier]parsedModule
+	building []types.SmiIdentifier
+	versions map[types.SmiIdentifier][]ModuleVersion
+	ctx      context.Context
+	strings  stringPool
+	lazyAST  lazyAST
+	views    views
+	hooks    hooks
+
+	// indexes are the indexes read of the search path directories, by name
+	indexes map[string]cachedIndex
+
+	// coreTypes are created the first time one is needed
+	coreTypes map[types.SmiIdentifier]*Type
 }
 
+// DependencyMode controls what happens when a module imported by a module
+// being loaded cannot be loaded itself.
+type DependencyMode int
+
+const (
+	// DependencyWarn reports the failure to the error handler and continues
+	// loading the importing module with the affected symbols unresolved.
+	DependencyWarn DependencyMode = iota
+	// DependencyFail fails loading the importing module.
+	DependencyFail
+	// DependencyStub creates a stub module holding unknown placeholders for
+	// the imported symbols, reports them to the error handler and continues
+	// loading the importing module against the stubs.
+	DependencyStub
+)
+
 var smiHandle, firstHandlePtr, lastHandlePtr *Handle
 
 func addHandle(name string) *Handle {
@@ -77,6 +131,94 @@ func SetErrorHandler(smiErrorHandler types.SmiErrorHandler) {
 	smiHandle.ErrorHandler = smiErrorHandler
 }
 
+func SetDependencyMode(mode DependencyMode) {
+	smiHandle.DependencyMode = mode
+}
+
+func SetLimits(limits parser.Limits) {
+	smiHandle.Limits = limits
+}
+
+func SetRetainAST(retain bool) {
+	smiHandle.DiscardAST = !retain
+	smiHandle.strings.clone = !retain
+}
+
+func SetLazyText(lazy bool) {
+	smiHandle.LazyText = lazy
+}
+
+func GetLimits() parser.Limits {
+	return smiHandle.Limits
+}
+
+func SetStrict(strict bool) {
+	smiHandle.Strict = strict
+}
+
+func GetStrict() bool {
+	return smiHandle.Strict
+}
+
+func SetWrappedQuoted(wrapped bool) {
+	smiHandle.WrappedQuoted = wrapped
+}
+
+func GetWrappedQuoted() bool {
+	return smiHandle.WrappedQuoted
+}
+
+func SetRecovery(recovery bool) {
+	smiHandle.Recovery = recovery
+}
+
+func GetRecovery() bool {
+	return smiHandle.Recovery
+}
+
+func SetDialects(names []string) {
+	smiHandle.Dialects = append([]string(nil), names...)
+}
+
+func GetDialects() []string {
+	return smiHandle.Dialects
+}
+
+func SetFetcher(fetcher Fetcher) {
+	smiHandle.Fetcher = fetcher
+}
+
+// libsmiSeverity maps diagnostic severities to the levels passed to the
+// error handler.
+var libsmiSeverity = map[diag.Severity]int{
+	diag.SeverityError:   1,
+	diag.SeverityWarning: 3,
+	diag.SeverityInfo:    6,
+}
+
+// reportDiagnostic records d on the handle and passes it to the error handler.
+func reportDiagnostic(d diag.Diagnostic) {
+	smiHandle.Diagnostics.Add(d)
+	if smiHandle.ErrorHandler != nil {
+		smiHandle.ErrorHandler(d.Pos.Filename, d.Pos.Line, libsmiSeverity[d.Severity], d.Message, smiHandle.Name)
+	}
+}
+
+// report reports msg under a diag code, with the code's severity.
+func report(code string, module types.SmiIdentifier, path string, line int, msg string) {
+	reportDiagnostic(diag.Diagnostic{
+		Severity: diag.CodeSeverity(code),
+		Pos:      diag.Position{Filename: path, Line: line},
+		Code:     code,
+		Message:  msg,
+		Module:   module.String(),
+	})
+}
+
+func Diagnostics() *diag.Collector {
+	return &smiHandle.Diagnostics
+}
+
 func SetSeverity(pattern string, severity int) {}
 
 func SetErrorLevel(level int) {}
@@ -93,7 +235,11 @@ func GetFirstModule() *Module {
 	if smiHandle == nil {
 		return nil
 	}
-	return smiHandle.Modules.First
+	module := smiHandle.Modules.First
+	if module != nil && !InView(module) {
+		module = GetNextModule(module)
+	}
+	return module
 }
 
 func Root() *Node {
@@ -103,14 +249,6 @@ func Root() *Node {
 	return smiHandle.RootNode
 }
 
-func oidFromSubId(subId types.SmiSubId) parser.Oid {
-	return parser.Oid{
-		SubIdentifiers: []parser.SubIdentifier{
-			{Number: &subId},
-		},
-	}
-}
-
 func createBaseType(module *Module, baseType types.BaseType) *Type {
 	return &Type{
 		SmiType: types.SmiType{
@@ -132,38 +270,9 @@ func initData() bool {
 		PrefixNode: smiHandle.RootNode,
 	}
 
-	// Create ccitt well-known node
-	ccitt := &Object{
-		SmiNode: types.SmiNode{
-			Name:     "ccitt",
-			Decl:     types.DeclImplObject,
-			NodeKind: types.NodeNode,
-		},
-		Module: wellKnownModule,
-	}
-	wellKnownModule.Objects.AddWithOid(ccitt, oidFromSubId(WellKnownIdCcitt))
-
-	// Create iso well-known node
-	iso := &Object{
-		SmiNode: types.SmiNode{
-			Name:     "iso",
-			Decl:     types.DeclImplObject,
-			NodeKind: types.NodeNode,
-		},
-		Module: wellKnownModule,
-	}
-	wellKnownModule.Objects.AddWithOid(iso, oidFromSubId(WellKnownIdIso))
-
-	// Create joint-iso-ccitt well-known node
-	jointIsoCcitt := &Object{
-		SmiNode: types.SmiNode{
-			Name:     "joint-iso-ccitt",
-			Decl:     types.DeclImplObject,
-			NodeKind: types.NodeNode,
-		},
-		Module: wellKnownModule,
+	for _, root := range rootNodes {
+		addRootNode(wellKnownModule, root)
 	}
-	wellKnownModule.Objects.AddWithOid(jointIsoCcitt, oidFromSubId(WellKnownIdJointIsoCcitt))
 
 	smiHandle.Modules.Add(wellKnownModule)
 
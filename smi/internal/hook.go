@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// PreParseHook rewrites the source of a module before it is parsed, for
+// example to fix up vendor syntax. PreParse returns src itself to leave it
+// unchanged; an error fails loading the module.
+type PreParseHook interface {
+	PreParse(path string, src []byte) ([]byte, error)
+}
+
+// PostResolveHook is called with each module once it is built and added to
+// the handle, for example to record telemetry or check policies. An error
+// fails loading the module, which is removed from the handle again.
+type PostResolveHook interface {
+	PostResolve(module *types.SmiModule) error
+}
+
+// SymbolResolver maps the name of a type a module uses without defining or
+// importing it to a type of another module, for example private types of a
+// vendor to standard ones. The module is loaded if it is not yet.
+type SymbolResolver interface {
+	ResolveType(module, name string) (typeModule, typeName string, ok bool)
+}
+
+// hooks holds the extensions registered on a handle.
+type hooks struct {
+	preParse    []PreParseHook
+	postResolve []PostResolveHook
+	resolver    SymbolResolver
+}
+
+func AddPreParseHook(hook PreParseHook) {
+	smiHandle.hooks.preParse = append(smiHandle.hooks.preParse, hook)
+}
+
+func AddPostResolveHook(hook PostResolveHook) {
+	smiHandle.hooks.postResolve = append(smiHandle.hooks.postResolve, hook)
+}
+
+func SetSymbolResolver(resolver SymbolResolver) {
+	smiHandle.hooks.resolver = resolver
+}
+
+// PreParse runs the source of the module at path through the pre-parse
+// hooks, in the order they were added.
+func PreParse(path string, src []byte) ([]byte, error) {
+	for _, hook := range smiHandle.hooks.preParse {
+		var err error
+		if src, err = hook.PreParse(path, src); err != nil {
+			return nil, fmt.Errorf("Pre-parse hook: %w", err)
+		}
+	}
+	return src, nil
+}
+
+// postResolve runs the post-resolve hooks on the module, in the order they
+// were added, removing it from the handle if one fails.
+func postResolve(module *Module) error {
+	for _, hook := range smiHandle.hooks.postResolve {
+		if err := hook.PostResolve(&module.SmiModule); err != nil {
+			removeModule(module)
+			return fmt.Errorf("Post-resolve hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveType asks the symbol resolver for the type name stands for in
+// module x, nil if there is none or it maps to a type that does not exist.
+func (x *Module) resolveType(name types.SmiIdentifier) *Type {
+	if smiHandle.hooks.resolver == nil {
+		return nil
+	}
+	typeModule, typeName, ok := smiHandle.hooks.resolver.ResolveType(x.Name.String(), name.String())
+	if !ok || typeModule == x.Name.String() {
+		return nil
+	}
+	module, err := GetModule(typeModule)
+	if err != nil {
+		return nil
+	}
+	return module.Types.GetName(typeName)
+}
@@ -0,0 +1,90 @@
+package internal
+
+import "github.com/lukeod/gosmi/types"
+
+// stringPool interns the identifiers and texts of the modules built in a
+// handle. Large MIB sets repeat the same names, units and boilerplate
+// descriptions thousands of times; each is stored once. With clone set, as
+// when ASTs are discarded, they are copied out of the source they were lexed
+// from so that the source can be freed.
+//
+// Entries are counted by the modules interning them and released with the
+// last of those, so that unloading modules does not leave their strings in
+// the pool.
+type stringPool struct {
+	m     map[string]*poolEntry
+	clone bool
+}
+
+type poolEntry struct {
+	s    string
+	refs int
+	// last is the module that last took a reference, so that a module
+	// interning the same string over and over counts once
+	last *Module
+}
+
+// moduleStrings interns strings on behalf of a module.
+type moduleStrings struct {
+	pool   *stringPool
+	module *Module
+}
+
+// of returns the pool interning strings on behalf of module. Strings interned
+// for a nil module are only shared if some module holds them already.
+func (p *stringPool) of(module *Module) moduleStrings {
+	return moduleStrings{pool: p, module: module}
+}
+
+func (s moduleStrings) intern(str string) string {
+	return s.pool.intern(s.module, str)
+}
+
+func (s moduleStrings) id(str types.SmiIdentifier) types.SmiIdentifier {
+	return types.SmiIdentifier(s.pool.intern(s.module, string(str)))
+}
+
+func (p *stringPool) intern(module *Module, s string) string {
+	if s == "" {
+		return ""
+	}
+	e, ok := p.m[s]
+	if !ok {
+		if p.clone {
+			s = string([]byte(s))
+		}
+		if module == nil {
+			return s
+		}
+		if p.m == nil {
+			p.m = make(map[string]*poolEntry)
+		}
+		e = &poolEntry{s: s}
+		p.m[s] = e
+	}
+	if module != nil && e.last != module {
+		// Every reference taken is recorded, to be dropped by release
+		e.refs++
+		e.last = module
+		module.interned = append(module.interned, e.s)
+	}
+	return e.s
+}
+
+// release drops the references module took, removing the strings no other
+// module holds.
+func (p *stringPool) release(module *Module) {
+	for _, s := range module.interned {
+		e, ok := p.m[s]
+		if !ok {
+			continue
+		}
+		if e.last == module {
+			e.last = nil
+		}
+		if e.refs--; e.refs == 0 {
+			delete(p.m, s)
+		}
+	}
+	module.interned = nil
+}
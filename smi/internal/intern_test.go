@@ -0,0 +1,32 @@
+package internal
+
+import "testing"
+
+func TestStringPoolRelease(t *testing.T) {
+	var pool stringPool
+	a, b := &Module{}, &Module{}
+
+	shared := pool.of(a).intern("shared")
+	pool.of(a).intern("shared")
+	pool.of(a).intern("only-a")
+	if got := pool.of(b).intern(string([]byte("shared"))); got != shared {
+		t.Errorf("Expected the string interned for a, got a copy")
+	}
+	pool.of(nil).intern("unowned")
+	if len(pool.m) != 2 {
+		t.Fatalf("Expected 2 pooled strings, got %d", len(pool.m))
+	}
+
+	pool.release(a)
+	if _, ok := pool.m["only-a"]; ok {
+		t.Errorf("Expected only-a to be released with a")
+	}
+	if e, ok := pool.m["shared"]; !ok || e.refs != 1 {
+		t.Errorf("Expected shared to be held by b only, got %+v", e)
+	}
+	pool.release(a)
+	pool.release(b)
+	if len(pool.m) != 0 {
+		t.Errorf("Expected an empty pool, got %d strings", len(pool.m))
+	}
+}
@@ -0,0 +1,50 @@
+package internal
+
+import "time"
+
+// Metrics receives measurements of loading modules. Implementations must be
+// safe for concurrent use, as modules are parsed in parallel by CompileDir.
+type Metrics interface {
+	// Count adds delta to the named counter.
+	Count(name string, delta int)
+	// Observe records a value of the named histogram.
+	Observe(name string, value float64)
+}
+
+// Names of the metrics reported to Metrics.
+const (
+	// MetricParseSeconds observes the time parsing a module file took.
+	MetricParseSeconds = "gosmi_parse_seconds"
+	// MetricBuildSeconds observes the time resolving a parsed module took,
+	// including the imported modules it loaded.
+	MetricBuildSeconds = "gosmi_build_seconds"
+	// MetricModulesLoaded counts the modules built into the handle.
+	MetricModulesLoaded = "gosmi_modules_loaded_total"
+	// MetricNodesResolved counts the nodes the modules built define.
+	MetricNodesResolved = "gosmi_nodes_resolved_total"
+	// MetricCacheHits counts the module lookups served by a loaded or
+	// already parsed module.
+	MetricCacheHits = "gosmi_cache_hits_total"
+	// MetricCacheMisses counts the module lookups that read a module file.
+	MetricCacheMisses = "gosmi_cache_misses_total"
+)
+
+func SetMetrics(metrics Metrics) {
+	smiHandle.Metrics = metrics
+}
+
+func GetMetrics() Metrics {
+	return smiHandle.Metrics
+}
+
+func count(name string, delta int) {
+	if smiHandle.Metrics != nil {
+		smiHandle.Metrics.Count(name, delta)
+	}
+}
+
+func observeSince(name string, start time.Time) {
+	if smiHandle.Metrics != nil {
+		smiHandle.Metrics.Observe(name, time.Since(start).Seconds())
+	}
+}
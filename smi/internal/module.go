@@ -1,14 +1,18 @@
 package internal
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/types"
 )
@@ -20,6 +24,7 @@ type Module struct {
 	Objects                ObjectMap
 	Types                  TypeMap
 	Macros                 MacroMap
+	Values                 ValueMap
 	Imports                ImportMap
 	FirstRevision          *Revision
 	LastRevision           *Revision
@@ -30,8 +35,32 @@ type Module struct {
 	Prev                   *Module
 	Next                   *Module
 	PrefixNode             *Node
+	// AST is the parsed module this one was built from, nil for the
+	// well-known and stub modules.
+	AST    *parser.Module
+	Timing LoadTiming
+	// Exports are the names of the EXPORTS clause of the module, nil if it
+	// has none, in which case every name it defines is exported.
+	Exports []types.SmiIdentifier
 
 	pending map[types.SmiIdentifier]*Object
+	// interned are the strings of the handle's pool the module holds
+	interned []string
+}
+
+// LoadTiming is the time the phases of loading a module took.
+type LoadTiming struct {
+	// Lex and Parse are the time lexing and parsing the module file took,
+	// zero for modules not built from a file.
+	Lex   time.Duration
+	Parse time.Duration
+	// Resolve is the time building the module took, not counting the
+	// imported modules it loaded.
+	Resolve time.Duration
+}
+
+func (x *Module) setParseTiming(timing parser.Timing) {
+	x.Timing.Lex, x.Timing.Parse = timing.Lex, timing.Parse
 }
 
 func (x *Module) addPending(name types.SmiIdentifier) *Object {
@@ -93,7 +122,7 @@ func (x *Module) GetObject(name types.SmiIdentifier) *Object {
 		}
 	}
 	i := x.Imports.Get(name)
-	if i == nil {
+	if i == nil || i.Hidden {
 		return x.addPending(name)
 	}
 	i.Used = true
@@ -110,15 +139,21 @@ func (x *Module) GetType(name types.SmiIdentifier) *Type {
 		return t
 	}
 	i := x.Imports.Get(name)
-	if i == nil {
-		return nil
+	if i == nil || i.Hidden {
+		return x.resolveType(name)
 	}
 	i.Used = true
 	module, err := GetModule(i.Module.String())
 	if err != nil {
-		return nil
+		return coreType(i.Name)
+	}
+	t = module.GetType(i.Name)
+	if t == nil || t.Flags.Has(FlagStub) {
+		if core := coreType(i.Name); core != nil {
+			return core
+		}
 	}
-	return module.GetType(i.Name)
+	return t
 }
 
 func (x *Module) IsWellKnown() bool {
@@ -199,6 +234,24 @@ func (x *ModuleMap) Add(m *Module) {
 	x.m[m.Name] = m
 }
 
+func (x *ModuleMap) Remove(m *Module) {
+	if x.m[m.Name] != m {
+		return
+	}
+	delete(x.m, m.Name)
+	if m.Prev == nil {
+		x.First = m.Next
+	} else {
+		m.Prev.Next = m.Next
+	}
+	if m.Next == nil {
+		x.last = m.Prev
+	} else {
+		m.Next.Prev = m.Prev
+	}
+	m.Prev, m.Next = nil, nil
+}
+
 func (x *ModuleMap) Get(name types.SmiIdentifier) *Module {
 	if name == WellKnownModuleName {
 		return x.wellKnown
@@ -222,6 +275,9 @@ type Import struct {
 	Kind      Kind
 	Used      bool
 	Line      int
+	// Hidden is set for names the module imported from does not export,
+	// which are left unresolved under ExportEnforce.
+	Hidden bool
 }
 
 type ImportMap struct {
@@ -244,12 +300,21 @@ func (x *ImportMap) Add(i *Import) {
 		x.m = make(map[types.SmiIdentifier]*Import)
 	}
 	x.m[i.Name] = i
-	if newImport, ok := importConversions[i.SmiImport]; ok {
+	// Only if the module converted to could be loaded, otherwise the
+	// import resolves in the module named
+	if newImport, ok := importConversions[i.SmiImport]; ok && smiHandle.Modules.Get(newImport.Module) != nil {
 		i.Module = newImport.Module
 		i.Name = newImport.Name
 	}
 }
 
+// Resolved returns whether the module imported from is loaded, rather than
+// stood in for by a stub, and defines the name.
+func (x *Import) Resolved() bool {
+	module := smiHandle.Modules.Get(x.Module)
+	return !x.Hidden && module != nil && !module.Flags.Has(FlagStub) && module.provides(x.Name)
+}
+
 func (x *ImportMap) Get(name types.SmiIdentifier) *Import {
 	if x.m == nil {
 		return nil
@@ -303,66 +368,264 @@ func GetModuleFile(name string) (string, io.ReadCloser, error) {
 		return "", nil, os.ErrNotExist
 	}
 
+	files, err := findModuleFiles(name, false)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(files) == 0 {
+		return "", nil, os.ErrNotExist
+	}
+	r, err := files[0].open()
+	if err != nil {
+		return files[0].path, nil, fmt.Errorf("Open file: %w", err)
+	}
+	return files[0].path, r, nil
+}
+
+// moduleFile is a file in the search path that may provide a module.
+type moduleFile struct {
+	path string
+	fs   NamedFS
+	name string
+}
+
+func (f moduleFile) open() (io.ReadCloser, error) {
+	return f.fs.FS.Open(f.name)
+}
+
+// findModuleFiles returns the files in the search path named after the
+// module name with one of the usual MIB extensions, in search path order, or
+// else those named after its alias. It stops at the first one unless all is
+// set.
+func findModuleFiles(name string, all bool) (files []moduleFile, err error) {
+	files, err = findFiles(name, all)
+	if err != nil || len(files) > 0 {
+		return files, err
+	}
+	if alias, ok := smiHandle.ModuleAliases[name]; ok && alias != name {
+		return findFiles(alias, all)
+	}
+	return files, nil
+}
+
+// findFiles returns the files in the search path named name, or name with
+// one of the usual MIB extensions, leaving out excluded files. Directories
+// with an up to date index, as written by gosmi.WriteDirIndex, are not
+// listed: their files are found by the module the index has them define.
+func findFiles(name string, all bool) (files []moduleFile, err error) {
 	for _, path := range smiHandle.Paths {
+		if idx := pathIndex(path); idx != nil {
+			for _, f := range idx.Files {
+				if strings.Contains(f.Path, "/") || IsExcludedFile(f.Path) {
+					continue
+				}
+				if f.Module != name && (f.Module != "" || !isModuleFileName(f.Path, name)) {
+					continue
+				}
+				files = append(files, moduleFile{
+					path: filepath.Join(path.Name, f.Path),
+					fs:   path,
+					name: f.Path,
+				})
+				if !all {
+					return files, nil
+				}
+			}
+			continue
+		}
 		dirEntries, err := path.FS.ReadDir(".")
 		if err != nil {
-			return path.Name, nil, fmt.Errorf("Read directory: %w", err)
+			return files, fmt.Errorf("Read directory %s: %w", path.Name, err)
 		}
 		for _, dirEntry := range dirEntries {
-			if dirEntry.IsDir() {
+			if dirEntry.IsDir() || IsExcludedFile(dirEntry.Name()) || !isModuleFileName(dirEntry.Name(), name) {
 				continue
 			}
-			parts := strings.SplitN(dirEntry.Name(), ".", 2)
-			if parts[0] != name {
-				continue
-			}
-			var ext string
-			if len(parts) > 1 {
-				ext = parts[1]
-			}
-			switch ext {
-			case "", "mib", "my", "mi2", "txt":
-				fullpath := filepath.Join(path.Name, dirEntry.Name())
-				r, err := path.FS.Open(dirEntry.Name())
-				if err != nil {
-					return fullpath, nil, fmt.Errorf("Open file: %w", err)
-				}
-				return fullpath, r, nil
+			files = append(files, moduleFile{
+				path: filepath.Join(path.Name, dirEntry.Name()),
+				fs:   path,
+				name: dirEntry.Name(),
+			})
+			if !all {
+				return files, nil
 			}
 		}
 	}
-	return "", nil, os.ErrNotExist
+	return files, nil
+}
+
+// isModuleFileName reports whether file is name, or name with one of the
+// usual MIB extensions.
+func isModuleFileName(file, name string) bool {
+	if file == name {
+		return true
+	}
+	parts := strings.SplitN(file, ".", 2)
+	if parts[0] != name || len(parts) < 2 {
+		return false
+	}
+	switch parts[1] {
+	case "", "mib", "my", "mi2", "txt":
+		return true
+	}
+	return false
+}
+
+type parsedModule struct {
+	path   string
+	module *parser.Module
+	timing parser.Timing
+}
+
+// AddParsedModule makes an already parsed module available under its module
+// name. GetModule builds it on first use instead of locating and parsing a
+// file from the search path. If a module with the same name is already loaded
+// or pending, nothing is added and the path that provides it is returned.
+func AddParsedModule(path string, in *parser.Module) (existing string, ok bool) {
+	return addParsedModule(path, in, parser.Timing{})
+}
+
+func addParsedModule(path string, in *parser.Module, timing parser.Timing) (existing string, ok bool) {
+	if m := FindModuleByName(in.Name.String()); m != nil {
+		return m.Path, false
+	}
+	if p, found := smiHandle.parsed[in.Name]; found {
+		return p.path, false
+	}
+	if smiHandle.parsed == nil {
+		smiHandle.parsed = make(map[types.SmiIdentifier]parsedModule)
+	}
+	smiHandle.parsed[in.Name] = parsedModule{path: path, module: in, timing: timing}
+	return "", true
 }
 
+// ErrImportCycle is returned by GetModule when the requested module is
+// already being built further up the import chain.
+var ErrImportCycle = errors.New("Import cycle")
+
 func GetModule(name string) (*Module, error) {
 	module := FindModuleByName(name)
 	if module != nil {
+		count(MetricCacheHits, 1)
 		return module, nil
 	}
+	if err := loadContext().Err(); err != nil {
+		return nil, err
+	}
+	for i, building := range smiHandle.building {
+		if building.String() == name {
+			chain := make([]string, 0, len(smiHandle.building)-i+1)
+			for _, b := range smiHandle.building[i:] {
+				chain = append(chain, b.String())
+			}
+			return nil, fmt.Errorf("%w: %s", ErrImportCycle, strings.Join(append(chain, name), " -> "))
+		}
+	}
+	if p, ok := smiHandle.parsed[types.SmiIdentifier(name)]; ok {
+		count(MetricCacheHits, 1)
+		delete(smiHandle.parsed, p.module.Name)
+		out, err := BuildModule(p.path, p.module)
+		if err != nil {
+			return nil, fmt.Errorf("Build module: %w", err)
+		}
+		out.setParseTiming(p.timing)
+		return out, nil
+	}
+	count(MetricCacheMisses, 1)
 	return LoadModule(name)
 }
 
+// GetModuleContext is GetModule, except that loading the module and its
+// imports stops with the context's error once ctx is done.
+func GetModuleContext(ctx context.Context, name string) (*Module, error) {
+	prev := smiHandle.ctx
+	smiHandle.ctx = ctx
+	defer func() { smiHandle.ctx = prev }()
+	return GetModule(name)
+}
+
+// loadContext returns the context of the GetModuleContext call in progress.
+func loadContext() context.Context {
+	if smiHandle.ctx == nil {
+		return context.Background()
+	}
+	return smiHandle.ctx
+}
+
 func LoadModule(name string) (*Module, error) {
+	if versionSelection(name) {
+		return loadModuleVersion(name)
+	}
 	//log.Printf("%s: Loading", name)
 	path, f, err := GetModuleFile(name)
+	if errors.Is(err, os.ErrNotExist) && smiHandle.Fetcher != nil {
+		path, f, err = smiHandle.Fetcher.FetchModule(loadContext(), name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("Get module file %q: %w", path, err)
 	}
 	defer f.Close()
 	//log.Printf("%s: Found at %s", name, path)
-	in, err := parser.Parse(path, f)
+	var diagnostics diag.Collector
+	var timing parser.Timing
+	in, err := parseModuleFile(path, f, &diagnostics, &timing)
+	for _, d := range diagnostics.Diagnostics() {
+		reportDiagnostic(d)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("Parse module: %w", err)
+		return nil, err
 	}
 	//log.Printf("%s: Parsed", name)
 	out, err := BuildModule(path, in)
 	if err != nil {
 		return nil, fmt.Errorf("Build module: %w", err)
 	}
+	out.setParseTiming(timing)
 	//log.Printf("%s: Built", name)
 	return out, nil
 }
 
+// parseModuleFile parses the module in f under the handle's limits, recording
+// the time it took in timing if set.
+func parseModuleFile(path string, f io.Reader, diagnostics *diag.Collector, timing *parser.Timing) (*parser.Module, error) {
+	defer observeSince(MetricParseSeconds, time.Now())
+	r := f
+	if max := smiHandle.Limits.MaxBytes; max > 0 {
+		// One byte more than allowed is enough for the parser to reject it
+		r = io.LimitReader(f, int64(max)+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Read module file %q: %w", path, err)
+	}
+	opts := []parser.Option{
+		parser.WithContext(loadContext()),
+		parser.WithDiagnostics(diagnostics),
+		parser.WithLimits(smiHandle.Limits),
+		parser.WithDialect(smiHandle.Dialects...),
+		parser.WithPreParse(PreParse),
+		parser.WithTiming(timing),
+		parser.WithTextWhitespace(smiHandle.TextNormalization.Whitespace),
+	}
+	if smiHandle.TextNormalization.Escapes {
+		opts = append(opts, parser.WithEscapes())
+	}
+	if smiHandle.WrappedQuoted {
+		opts = append(opts, parser.WithWrappedQuoted())
+	}
+	if smiHandle.Recovery {
+		opts = append(opts, parser.WithRecovery())
+	}
+	if smiHandle.Strict {
+		opts = append(opts, parser.WithStrict())
+	}
+	in, err := parser.ParseBytes(path, b, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Parse module: %w", err)
+	}
+	return in, nil
+}
+
 type columnMap struct {
 	m map[types.SmiIdentifier]struct{}
 }
@@ -382,22 +645,149 @@ func (x *columnMap) CheckAndRemove(name types.SmiIdentifier) bool {
 	return ok
 }
 
-func BuildModule(path string, in *parser.Module) (*Module, error) {
+// loadImports loads the modules imported by in before it is built, so that
+// dependencies are resolved transitively from the search path. Import cycles
+// are reported and otherwise ignored; other failures are handled according to
+// the handle's DependencyMode.
+func loadImports(path string, in *parser.Module) error {
+	var modules []types.SmiIdentifier
+	symbols := make(map[types.SmiIdentifier][]types.SmiIdentifier)
+	lines := make(map[types.SmiIdentifier]int)
+	// originals are the modules imported from whose imports were converted
+	// to another module, to fall back to if that one cannot be loaded
+	originals := make(map[types.SmiIdentifier][]types.SmiIdentifier)
+	for _, i := range in.Body.Imports {
+		for _, name := range i.Names {
+			imp := types.SmiImport{Module: i.Module, Name: name}
+			if newImport, ok := importConversions[imp]; ok {
+				imp = newImport
+				if !containsIdentifier(originals[imp.Module], i.Module) {
+					originals[imp.Module] = append(originals[imp.Module], i.Module)
+				}
+			}
+			if _, ok := symbols[imp.Module]; !ok {
+				modules = append(modules, imp.Module)
+				lines[imp.Module] = i.Pos.Line
+			}
+			symbols[imp.Module] = append(symbols[imp.Module], imp.Name)
+		}
+	}
+	for _, module := range modules {
+		modulePtr, err := GetModule(module.String())
+		if err == nil {
+			if modulePtr.Flags.Has(FlagStub) {
+				modulePtr.addStubs(symbols[module])
+			}
+			continue
+		}
+		if ctxErr := loadContext().Err(); ctxErr != nil {
+			// Cancelled, rather than a missing dependency
+			return ctxErr
+		}
+		if loadOriginals(originals[module]) {
+			continue
+		}
+		if errors.Is(err, ErrImportCycle) {
+			report(diag.CodeImportCycle, in.Name, path, lines[module], fmt.Sprintf("Load import %s: %v", module, err))
+			continue
+		}
+		switch smiHandle.DependencyMode {
+		case DependencyWarn:
+			report(diag.CodeImportNotFound, in.Name, path, lines[module], fmt.Sprintf("Load import %s: %v", module, err))
+			continue
+		case DependencyFail:
+			report(diag.CodeDependencyNotLoaded, in.Name, path, lines[module], fmt.Sprintf("Load import %s: %v", module, err))
+			return fmt.Errorf("Load import %s: %w", module, err)
+		}
+		addStubModule(module).addStubs(symbols[module])
+		report(diag.CodeImportStubbed, in.Name, path, lines[module], fmt.Sprintf("Load import %s: %v, using stubs for %s", module, err, joinIdentifiers(symbols[module])))
+	}
+	return nil
+}
+
+// loadOriginals loads the modules imports were converted from, reporting
+// whether they all could be.
+func loadOriginals(modules []types.SmiIdentifier) bool {
+	for _, module := range modules {
+		if _, err := GetModule(module.String()); err != nil {
+			return false
+		}
+	}
+	return len(modules) > 0
+}
+
+func containsIdentifier(ids []types.SmiIdentifier, id types.SmiIdentifier) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildModule resolves in and adds it to the current handle. A panic while
+// resolving is returned as a *diag.PanicError and reported, in which case the
+// handle may keep some of the module's objects.
+func BuildModule(path string, in *parser.Module) (out *Module, err error) {
+	defer func() {
+		// Panics of imported modules were reported when building them
+		if panicErr, ok := err.(*diag.PanicError); ok {
+			out = nil
+			report(diag.CodeResolverPanic, in.Name, path, 0, panicErr.Error())
+		}
+	}()
+	defer diag.Recover(path, &err)
+	defer observeSince(MetricBuildSeconds, time.Now())
+	smiHandle.building = append(smiHandle.building, in.Name)
+	defer func() { smiHandle.building = smiHandle.building[:len(smiHandle.building)-1] }()
+	if err := loadImports(path, in); err != nil {
+		return nil, err
+	}
+	defer func(start time.Time) {
+		if out != nil {
+			out.Timing.Resolve = time.Since(start)
+		}
+	}(time.Now())
+
+	body := dropDuplicates(path, in)
 	var columnMap columnMap
-	out := &Module{
+	// The SEQUENCE types of the module, the syntax of its rows
+	rowTypes := make(map[types.SmiIdentifier]bool)
+	out = &Module{
 		SmiModule: types.SmiModule{
-			Name: in.Name,
-			Path: path,
+			Path:     path,
+			Language: in.Language(),
 		},
 	}
+	defer func(module *Module) {
+		if err != nil {
+			// The module is not kept, so neither are its strings
+			smiHandle.strings.release(module)
+		}
+	}(out)
+	str := smiHandle.strings.of(out)
+	out.Name = str.id(in.Name)
+	// The DESCRIPTION, REFERENCE and CONTACT-INFO texts, unless loaded lazily
+	text := func(s string) string {
+		if smiHandle.LazyText {
+			return ""
+		}
+		return str.intern(smiHandle.TextNormalization.apply(s))
+	}
+	if !smiHandle.DiscardAST {
+		out.AST = in
+	}
+	if smiHandle.LazyText {
+		out.Flags |= FlagLazyText
+	}
 
 	var currImport *Import
 	for _, i := range in.Body.Imports {
 		for _, name := range i.Names {
 			currImport = &Import{
 				SmiImport: types.SmiImport{
-					Module: i.Module,
-					Name:   name,
+					Module: str.id(i.Module),
+					Name:   str.id(name),
 				},
 				ModulePtr: out,
 				Line:      i.Pos.Line,
@@ -406,23 +796,31 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 			out.NumImportedIdentifiers++
 		}
 	}
+	checkExports(path, out)
+	if in.Body.Exports != nil {
+		out.Exports = make([]types.SmiIdentifier, len(in.Body.Exports))
+		for i, name := range in.Body.Exports {
+			out.Exports[i] = str.id(name)
+		}
+	}
 
 	if in.Body.Identity != nil {
 		out.NumModuleIdentities = 1
 		out.LastUpdated = in.Body.Identity.LastUpdated.ToTime()
-		out.Organization = in.Body.Identity.Organization
-		out.ContactInfo = in.Body.Identity.ContactInfo
-		out.Description = in.Body.Identity.Description
-		out.Language = types.LanguageSMIv2
+		out.Organization = str.intern(in.Body.Identity.Organization)
+		out.ContactInfo = text(in.Body.Identity.ContactInfo)
+		out.Description = text(in.Body.Identity.Description)
 
 		out.Identity = &Object{
 			SmiNode: types.SmiNode{
-				Name:        in.Body.Identity.Name,
+				Name:        str.id(in.Body.Identity.Name),
 				Decl:        types.DeclModuleIdentity,
-				Description: in.Body.Identity.Description,
+				Description: out.Description,
 				NodeKind:    types.NodeNode,
 			},
 			Module: out,
+			Line:   in.Body.Identity.Pos.Line,
+			Column: in.Body.Identity.Pos.Column,
 		}
 		out.Objects.AddWithOid(out.Identity, in.Body.Identity.Oid)
 
@@ -431,20 +829,24 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 			currRevision = &Revision{
 				SmiRevision: types.SmiRevision{
 					Date:        revision.Date.ToTime(),
-					Description: revision.Description,
+					Description: str.intern(smiHandle.TextNormalization.apply(revision.Description)),
 				},
 				Module: out,
 				Line:   revision.Pos.Line,
 			}
 			out.AddRevision(currRevision)
 		}
-	} else {
-		out.Language = types.LanguageSMIv1
 	}
 
 	var currType *Type
-	for _, t := range in.Body.Types {
+	var choices []parser.Type
+	for _, t := range body.Types {
+		if t.Sequence != nil && t.Sequence.Type == parser.SequenceTypeChoice {
+			choices = append(choices, t)
+			continue
+		}
 		if t.Sequence != nil {
+			rowTypes[t.Name] = true
 			for _, col := range t.Sequence.Entries {
 				columnMap.Add(col.Descriptor)
 			}
@@ -452,22 +854,29 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 		}
 		currType = &Type{
 			SmiType: types.SmiType{
-				Name: t.Name,
+				Name: str.id(t.Name),
 			},
 			Module: out,
 			Line:   t.Pos.Line,
+			Column: t.Pos.Column,
 		}
 		var syntax parser.SyntaxType
 		if t.TextualConvention != nil {
 			syntax = t.TextualConvention.Syntax
 			currType.Decl = types.DeclTextualConvention
-			currType.Description = t.TextualConvention.Description
-			currType.Format = t.TextualConvention.DisplayHint
-			currType.Reference = t.TextualConvention.Reference
+			currType.Description = text(t.TextualConvention.Description)
+			currType.Format = str.intern(t.TextualConvention.DisplayHint)
+			currType.Reference = text(t.TextualConvention.Reference)
 			currType.Status = t.TextualConvention.Status.ToSmi()
 		} else if t.Implicit != nil {
 			syntax = t.Implicit.Syntax
 			currType.Decl = types.DeclTypeAssignment
+			// The lexer only accepts APPLICATION tags, as [ APPLICATION n ]
+			fields := strings.Fields(strings.Trim(t.Implicit.Tag, "[]"))
+			if tag, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+				currType.Flags |= FlagTagged
+				currType.Tag = tag
+			}
 		} else {
 			syntax = *t.Syntax
 			currType.Decl = types.DeclTypeAssignment
@@ -476,13 +885,13 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 		if parentType == nil {
 			parentType = out.GetType(syntax.Name)
 			if parentType == nil {
-				// What do we do here?
-				break
+				report(diag.CodeUnknownType, out.Name, path, currType.Line, fmt.Sprintf("Unknown type %s for type %s", syntax.Name, currType.Name))
+				continue
 			}
 		}
-		if parentType.Decl == types.DeclTextualConvention {
-			// This is invalid
-			break
+		if parentType.Decl == types.DeclTextualConvention && currType.Decl == types.DeclTextualConvention {
+			// Not allowed by RFC 2579, but keep the derivation like libsmi does
+			report(diag.CodeTCDerivedFromTC, out.Name, path, currType.Line, fmt.Sprintf("Textual convention %s is derived from textual convention %s", currType.Name, parentType.Name))
 		}
 		currType.BaseType = parentType.BaseType
 		currType.Parent = parentType
@@ -498,28 +907,45 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 					baseType = types.BaseTypeUnsigned32
 				}
 			}
-			rangeSort(ranges)
+			if !rangeSort(ranges) {
+				report(diag.CodeRangeOrder, out.Name, path, currType.Line, fmt.Sprintf("Ranges of type %s are not in ascending order", currType.Name))
+			}
 			for _, r := range ranges {
 				if r.End == "" {
 					r.End = r.Start
 				}
 				currType.AddRange(GetValue(r.Start, baseType), GetValue(r.End, baseType))
 			}
+			if currType.widensParent() {
+				report(diag.CodeRangeWidened, out.Name, path, currType.Line, fmt.Sprintf("Ranges of type %s are not within those of its parent type %s", currType.Name, parentType.Name))
+			}
 		} else if len(syntax.Enum) > 0 {
 			namedNumberSort(syntax.Enum)
 			for _, nn := range syntax.Enum {
-				currType.AddNamedNumber(nn.Name, GetValue(nn.Value, currType.BaseType))
+				currType.AddNamedNumber(str.id(nn.Name), GetValue(nn.Value, currType.BaseType))
 			}
 			currType.BaseType = types.BaseTypeEnum
 		}
 		out.Types.Add(currType)
 	}
+	// CHOICE types may refer to the types following them, including other
+	// CHOICE types, so add them once their alternatives resolve
+	for force := false; len(choices) > 0; {
+		var unresolved []parser.Type
+		for _, t := range choices {
+			if !out.addChoiceType(path, t, force) {
+				unresolved = append(unresolved, t)
+			}
+		}
+		force = len(unresolved) == len(choices)
+		choices = unresolved
+	}
 
 	var currMacro *Macro
 	for _, macro := range in.Body.Macros {
 		currMacro = &Macro{
 			SmiMacro: types.SmiMacro{
-				Name: macro.Name,
+				Name: str.id(macro.Name),
 				Decl: types.DeclMacro,
 			},
 			Line: macro.Pos.Line,
@@ -527,15 +953,35 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 		out.Macros.Add(currMacro)
 	}
 
+	for _, value := range body.Values {
+		syntax := value.Syntax
+		valueType := GetBaseTypeFromSyntax(syntax)
+		if valueType == nil {
+			if valueType = out.GetType(syntax.Name); valueType == nil {
+				report(diag.CodeUnknownType, out.Name, path, syntax.Pos.Line, fmt.Sprintf("Unknown type %s for value %s", syntax.Name, value.Name))
+			}
+		}
+		out.Values.Add(&Value{
+			SmiValueAssignment: types.SmiValueAssignment{
+				Name:  str.id(value.Name),
+				Value: assignedValue(str.intern(value.Value), valueType),
+			},
+			Module: out,
+			Type:   valueType,
+			Line:   value.Pos.Line,
+		})
+	}
+
 	var currObject *Object
-	for _, node := range in.Body.Nodes {
+	for _, node := range body.Nodes {
 		currObject = out.getPending(node.Name)
 		if currObject == nil {
 			currObject = new(Object)
 		}
-		currObject.Name = node.Name
+		currObject.Name = str.id(node.Name)
 		currObject.Module = out
 		currObject.Line = node.Pos.Line
+		currObject.Column = node.Pos.Column
 
 		switch {
 		case node.ObjectIdentifier:
@@ -545,14 +991,14 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 			currObject.Decl = types.DeclObjectIdentity
 			currObject.NodeKind = types.NodeNode
 			currObject.Status = node.ObjectIdentity.Status.ToSmi()
-			currObject.Description = node.ObjectIdentity.Description
-			currObject.Reference = node.ObjectIdentity.Reference
+			currObject.Description = text(node.ObjectIdentity.Description)
+			currObject.Reference = text(node.ObjectIdentity.Reference)
 		case node.ObjectGroup != nil:
 			currObject.Decl = types.DeclObjectGroup
 			currObject.NodeKind = types.NodeGroup
 			currObject.Status = node.ObjectGroup.Status.ToSmi()
-			currObject.Description = node.ObjectGroup.Description
-			currObject.Reference = node.ObjectGroup.Reference
+			currObject.Description = text(node.ObjectGroup.Description)
+			currObject.Reference = text(node.ObjectGroup.Reference)
 			currObject.AddElements(node.ObjectGroup.Objects)
 		case node.ObjectType != nil:
 			objType := node.ObjectType
@@ -560,9 +1006,9 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 			currObject.Access = objType.Access.ToSmi()
 			currObject.Create = objType.Access == parser.AccessReadCreate
 			currObject.Status = objType.Status.ToSmi()
-			currObject.Units = objType.Units
-			currObject.Description = objType.Description
-			currObject.Reference = objType.Reference
+			currObject.Units = str.intern(objType.Units)
+			currObject.Description = text(objType.Description)
+			currObject.Reference = text(objType.Reference)
 			if len(objType.Index) > 0 {
 				currObject.NodeKind = types.NodeRow
 				currObject.IndexKind = types.IndexIndex
@@ -578,116 +1024,50 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 				currObject.Related = out.GetObject(*objType.Augments)
 			} else if objType.Syntax.Sequence != nil {
 				currObject.NodeKind = types.NodeTable
+			} else if objType.Syntax.Type != nil && rowTypes[objType.Syntax.Type.Name] {
+				// A row without INDEX clause, as SMIv1 allows
+				currObject.NodeKind = types.NodeRow
 			} else {
 				if columnMap.CheckAndRemove(node.Name) {
 					currObject.NodeKind = types.NodeColumn
 				} else {
 					currObject.NodeKind = types.NodeScalar
 				}
-				syntax := *objType.Syntax.Type
-				parentType := GetBaseTypeFromSyntax(syntax)
-				if parentType == nil {
-					parentType = out.GetType(syntax.Name)
-					if parentType == nil {
-						// What do we do here?
-						break
-					}
-				}
-				if syntax.SubType == nil && len(syntax.Enum) == 0 {
-					currObject.Type = parentType
-					break
-				}
-				currType = &Type{
-					SmiType: types.SmiType{
-						BaseType: parentType.BaseType,
-						Decl:     types.DeclImplicitType,
-						Status:   currObject.Status,
-					},
-					Module: out,
-					Parent: parentType,
-					Line:   syntax.Pos.Line,
-				}
-				baseType := currType.BaseType
-				if syntax.SubType != nil {
-					var ranges []parser.Range
-					if baseType == types.BaseTypeOctetString {
-						ranges = syntax.SubType.OctetString
-						baseType = types.BaseTypeUnsigned32
-					} else {
-						ranges = syntax.SubType.Integer
-					}
-					rangeSort(ranges)
-					for _, r := range ranges {
-						if r.End == "" {
-							r.End = r.Start
-						}
-						currType.AddRange(GetValue(r.Start, baseType), GetValue(r.End, baseType))
-					}
-				} else if len(syntax.Enum) > 0 {
-					if baseType == types.BaseTypeEnum {
-						if parentType.List == nil || parentType.List.Ptr == nil {
-							// TODO: Figure out a better option. This should never happen.
-							baseType = types.BaseTypeInteger32
-						} else {
-							baseType = parentType.List.Ptr.(*NamedNumber).Value.BaseType
-						}
-					} else if baseType == types.BaseTypeBits {
-						baseType = types.BaseTypeUnsigned32
-					}
-					namedNumberSort(syntax.Enum)
-					for _, nn := range syntax.Enum {
-						currType.AddNamedNumber(nn.Name, GetValue(nn.Value, baseType))
-					}
-					if currType.BaseType == types.BaseTypeBits {
-						if parentType == smiHandle.TypeBits {
-							currType.Name = "Bits"
-						} else {
-							currType.Name = parentType.Name
-						}
-					} else {
-						if parentType.Module == nil || parentType.Module.IsWellKnown() {
-							currType.Name = "Enumeration"
-						} else {
-							currType.Name = parentType.Name
-						}
-						currType.BaseType = types.BaseTypeEnum
-					}
-				}
-				currObject.Type = currType
+				currObject.Type = out.syntaxType(*objType.Syntax.Type, currObject.Status, "object "+currObject.Name.String(), path)
 			}
 		case node.NotificationGroup != nil:
 			currObject.Decl = types.DeclNotificationGroup
 			currObject.NodeKind = types.NodeGroup
 			currObject.Status = node.NotificationGroup.Status.ToSmi()
-			currObject.Description = node.NotificationGroup.Description
-			currObject.Reference = node.NotificationGroup.Reference
+			currObject.Description = text(node.NotificationGroup.Description)
+			currObject.Reference = text(node.NotificationGroup.Reference)
 			currObject.AddElements(node.NotificationGroup.Notifications)
 		case node.NotificationType != nil:
 			currObject.Decl = types.DeclNotificationType
 			currObject.NodeKind = types.NodeNotification
 			currObject.Status = node.NotificationType.Status.ToSmi()
-			currObject.Description = node.NotificationType.Description
-			currObject.Reference = node.NotificationType.Reference
+			currObject.Description = text(node.NotificationType.Description)
+			currObject.Reference = text(node.NotificationType.Reference)
 			currObject.AddElements(node.NotificationType.Objects)
 		case node.ModuleCompliance != nil:
 			currObject.Decl = types.DeclModuleCompliance
 			currObject.NodeKind = types.NodeCompliance
 			currObject.Status = node.ModuleCompliance.Status.ToSmi()
-			currObject.Description = node.ModuleCompliance.Description
-			currObject.Reference = node.ModuleCompliance.Reference
-			// TODO: Deal with node.ModuleCompliance.Modules
+			currObject.Description = text(node.ModuleCompliance.Description)
+			currObject.Reference = text(node.ModuleCompliance.Reference)
+			out.addCompliance(currObject, node.ModuleCompliance.Modules, path, text)
 		case node.AgentCapabilities != nil:
 			currObject.Decl = types.DeclAgentCapabilities
 			currObject.NodeKind = types.NodeCapabilities
 			currObject.Status = node.AgentCapabilities.Status.ToSmi()
-			currObject.Description = node.AgentCapabilities.Description
-			currObject.Reference = node.AgentCapabilities.Reference
+			currObject.Description = text(node.AgentCapabilities.Description)
+			currObject.Reference = text(node.AgentCapabilities.Reference)
 			// TODO: Deal with node.AgentCapabilities.Modules
 		case node.TrapType != nil:
 			currObject.Decl = types.DeclTrapType
 			currObject.NodeKind = types.NodeNotification
-			currObject.Description = node.TrapType.Description
-			currObject.Reference = node.TrapType.Reference
+			currObject.Description = text(node.TrapType.Description)
+			currObject.Reference = text(node.TrapType.Reference)
 			currObject.AddElements(node.TrapType.Objects)
 			placeholder := out.getTrapTypePlaceholder(node.TrapType.Enterprise, currObject.Line)
 			node.Oid = &parser.Oid{
@@ -702,6 +1082,252 @@ func BuildModule(path string, in *parser.Module) (*Module, error) {
 		}
 		out.Objects.AddWithOid(currObject, *node.Oid)
 	}
+
+	// Definitions made with macros the parser does not know are kept as
+	// plain nodes when they are assigned an OID, so that they can be the
+	// parents of other nodes
+	for _, invocation := range body.Invocations {
+		report(diag.CodeUnknownMacro, out.Name, path, invocation.Pos.Line,
+			fmt.Sprintf("%s is defined with unknown macro %s", invocation.Name, invocation.Macro))
+		if invocation.Oid == nil {
+			continue
+		}
+		currObject = out.getPending(invocation.Name)
+		if currObject == nil {
+			currObject = new(Object)
+		}
+		currObject.Name = str.id(invocation.Name)
+		currObject.Module = out
+		currObject.Line = invocation.Pos.Line
+		currObject.Column = invocation.Pos.Column
+		currObject.Decl = types.DeclUnknown
+		currObject.NodeKind = types.NodeNode
+		out.Objects.AddWithOid(currObject, *invocation.Oid)
+	}
+	out.classifyObjects()
+	out.resolveAugments()
+	out.reportPending()
 	smiHandle.Modules.Add(out)
+	invalidateView()
+	if err := postResolve(out); err != nil {
+		return nil, err
+	}
+	if smiHandle.Metrics != nil {
+		nodes := 0
+		for obj := out.Objects.First; obj != nil; obj = obj.Next {
+			nodes++
+		}
+		count(MetricModulesLoaded, 1)
+		count(MetricNodesResolved, nodes)
+	}
 	return out, nil
 }
+
+// addCompliance resolves the MODULE clauses of a compliance statement: their
+// MANDATORY-GROUPS become its elements, their GROUP clauses its options and
+// their OBJECT clauses its refinements. The clauses for other modules, which
+// need not be imported, resolve once those are loaded, and refine them with
+// the types known here.
+func (x *Module) addCompliance(compliance *Object, modules []parser.ModuleComplianceModule, path string, text func(string) string) {
+	for _, m := range modules {
+		lookup := x.GetObject
+		local := m.Name == "" || types.SmiIdentifier(m.Name) == x.Name
+		if !local {
+			other := smiHandle.Modules.GetName(string(m.Name))
+			if other == nil {
+				continue
+			}
+			lookup = other.Objects.Get
+		}
+		for _, name := range m.MandatoryGroups {
+			if group := lookup(name); group != nil {
+				compliance.AddElement(group)
+			}
+		}
+		for _, c := range m.Compliances {
+			switch {
+			case c.Group != nil:
+				if group := lookup(c.Group.Name); group != nil {
+					compliance.AddOption(&Option{
+						SmiOption:  types.SmiOption{Description: text(c.Group.Description)},
+						Compliance: compliance,
+						Object:     group,
+						Line:       c.Group.Pos.Line,
+					})
+				}
+			case c.Object != nil:
+				object := lookup(c.Object.Name)
+				if object == nil {
+					continue
+				}
+				refinement := &Refinement{
+					SmiRefinement: types.SmiRefinement{Description: text(c.Object.Description)},
+					Compliance:    compliance,
+					Object:        object,
+					Line:          c.Object.Pos.Line,
+				}
+				if c.Object.MinAccess != nil {
+					refinement.Access = c.Object.MinAccess.ToSmi()
+				}
+				owner := "refinement of " + c.Object.Name.String()
+				if syntax := c.Object.Syntax; syntax != nil && syntax.Type != nil && (local || x.knowsType(*syntax.Type)) {
+					refinement.Type = x.syntaxType(*syntax.Type, compliance.Status, owner, path)
+				}
+				if syntax := c.Object.WriteSyntax; syntax != nil && syntax.Type != nil && (local || x.knowsType(*syntax.Type)) {
+					refinement.WriteType = x.syntaxType(*syntax.Type, compliance.Status, owner, path)
+				}
+				compliance.AddRefinement(refinement)
+			}
+		}
+	}
+}
+
+// knowsType reports whether the type of syntax resolves in the module
+func (x *Module) knowsType(syntax parser.SyntaxType) bool {
+	return GetBaseTypeFromSyntax(syntax) != nil || x.GetType(syntax.Name) != nil
+}
+
+// syntaxType returns the type of the SYNTAX clause of owner, such as "object
+// ifIndex": the named or base type, or an implicit type refining it with
+// ranges or named numbers.
+func (x *Module) syntaxType(syntax parser.SyntaxType, status types.Status, owner, path string) *Type {
+	str := smiHandle.strings.of(x)
+	parentType := GetBaseTypeFromSyntax(syntax)
+	if parentType == nil {
+		parentType = x.GetType(syntax.Name)
+		if parentType == nil {
+			report(diag.CodeUnknownType, x.Name, path, syntax.Pos.Line, fmt.Sprintf("Unknown type %s for %s", syntax.Name, owner))
+			return nil
+		}
+	}
+	if syntax.SubType == nil && len(syntax.Enum) == 0 {
+		return parentType
+	}
+	currType := &Type{
+		SmiType: types.SmiType{
+			BaseType: parentType.BaseType,
+			Decl:     types.DeclImplicitType,
+			Status:   status,
+		},
+		Module: x,
+		Parent: parentType,
+		Line:   syntax.Pos.Line,
+	}
+	baseType := currType.BaseType
+	if syntax.SubType != nil {
+		var ranges []parser.Range
+		if baseType == types.BaseTypeOctetString {
+			ranges = syntax.SubType.OctetString
+			baseType = types.BaseTypeUnsigned32
+		} else {
+			ranges = syntax.SubType.Integer
+		}
+		if !rangeSort(ranges) {
+			report(diag.CodeRangeOrder, x.Name, path, syntax.Pos.Line, fmt.Sprintf("Ranges of %s are not in ascending order", owner))
+		}
+		for _, r := range ranges {
+			if r.End == "" {
+				r.End = r.Start
+			}
+			currType.AddRange(GetValue(r.Start, baseType), GetValue(r.End, baseType))
+		}
+		if currType.widensParent() {
+			report(diag.CodeRangeWidened, x.Name, path, syntax.Pos.Line, fmt.Sprintf("Ranges of %s are not within those of its type %s", owner, parentType.Name))
+		}
+	} else if len(syntax.Enum) > 0 {
+		if baseType == types.BaseTypeEnum {
+			if parentType.List == nil || parentType.List.Ptr == nil {
+				// TODO: Figure out a better option. This should never happen.
+				baseType = types.BaseTypeInteger32
+			} else {
+				baseType = parentType.List.Ptr.(*NamedNumber).Value.BaseType
+			}
+		} else if baseType == types.BaseTypeBits {
+			baseType = types.BaseTypeUnsigned32
+		}
+		namedNumberSort(syntax.Enum)
+		for _, nn := range syntax.Enum {
+			currType.AddNamedNumber(str.id(nn.Name), GetValue(nn.Value, baseType))
+		}
+		if currType.BaseType == types.BaseTypeBits {
+			if parentType == smiHandle.TypeBits {
+				currType.Name = "Bits"
+			} else {
+				currType.Name = parentType.Name
+			}
+		} else {
+			if parentType.Module == nil || parentType.Module.IsWellKnown() {
+				currType.Name = "Enumeration"
+			} else {
+				currType.Name = parentType.Name
+			}
+			currType.BaseType = types.BaseTypeEnum
+		}
+	}
+	return currType
+}
+
+// resolveAugments resolves the rows augmented by rows of the module that are
+// neither defined nor imported by it to the loaded module defining them, as
+// modules augmenting a table of another module sometimes forget to import
+// its row.
+func (x *Module) resolveAugments() {
+	for obj := x.Objects.First; obj != nil; obj = obj.Next {
+		if obj.IndexKind != types.IndexAugment || obj.Related == nil || obj.Related.Module != nil {
+			continue
+		}
+		for name, pending := range x.pending {
+			if pending != obj.Related {
+				continue
+			}
+			for m := smiHandle.Modules.First; m != nil; m = m.Next {
+				if row := m.Objects.Get(name); m != x && row != nil && row.NodeKind == types.NodeRow {
+					obj.Related = row
+					break
+				}
+			}
+		}
+	}
+}
+
+// classifyObjects corrects the kinds of the object types of the module from
+// their parent in the OID tree: objects below a row are columns, whether or
+// not the SEQUENCE of the row lists them, and objects a SEQUENCE lists but
+// defined elsewhere are scalars.
+func (x *Module) classifyObjects() {
+	for obj := x.Objects.First; obj != nil; obj = obj.Next {
+		if obj.Decl != types.DeclObjectType || obj.Node == nil || obj.Node.Parent == nil {
+			continue
+		}
+		var parent *Object
+		for p := obj.Node.Parent.FirstObject; p != nil; p = p.NextSameNode {
+			if p.Decl == types.DeclObjectType {
+				parent = p
+				break
+			}
+		}
+		switch {
+		case obj.NodeKind == types.NodeScalar && parent != nil && parent.NodeKind == types.NodeRow:
+			obj.NodeKind = types.NodeColumn
+		case obj.NodeKind == types.NodeColumn && obj.Node.Parent.FirstObject != nil && (parent == nil || parent.NodeKind != types.NodeRow):
+			obj.NodeKind = types.NodeScalar
+		}
+	}
+}
+
+// reportPending reports the OID parents that are still unknown once the
+// module is built. Objects below them have no OID.
+func (x *Module) reportPending() {
+	names := make([]string, 0, len(x.Objects.pending))
+	for name := range x.Objects.pending {
+		names = append(names, name.String())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var line int
+		if n := x.Objects.pending[types.SmiIdentifier(name)][0]; n.FirstObject != nil {
+			line = n.FirstObject.Line
+		}
+		report(diag.CodeUnknownOidParent, x.Name, x.Path, line, fmt.Sprintf("Unknown OID parent %s", name))
+	}
+}
@@ -28,6 +28,20 @@ func (x *Node) AddObject(obj *Object) {
 	x.LastObject = obj
 }
 
+func (x *Node) removeObject(obj *Object) {
+	if obj.PrevSameNode == nil {
+		x.FirstObject = obj.NextSameNode
+	} else {
+		obj.PrevSameNode.NextSameNode = obj.NextSameNode
+	}
+	if obj.NextSameNode == nil {
+		x.LastObject = obj.PrevSameNode
+	} else {
+		obj.NextSameNode.PrevSameNode = obj.PrevSameNode
+	}
+	obj.PrevSameNode, obj.NextSameNode = nil, nil
+}
+
 func (x *Node) IsRoot() bool {
 	return x != nil && x.Flags.Has(FlagRoot)
 }
@@ -81,6 +95,24 @@ func (x *NodeChildMap) Add(n *Node) {
 	x.m[n.SubId] = n
 }
 
+func (x *NodeChildMap) Remove(n *Node) {
+	if x.m[n.SubId] != n {
+		return
+	}
+	delete(x.m, n.SubId)
+	if n.Prev == nil {
+		x.First = n.Next
+	} else {
+		n.Prev.Next = n.Next
+	}
+	if n.Next == nil {
+		x.last = n.Prev
+	} else {
+		n.Next.Prev = n.Prev
+	}
+	n.Prev, n.Next = nil, nil
+}
+
 func (x *NodeChildMap) Get(id types.SmiSubId) *Node {
 	if x.m == nil {
 		return nil
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/lukeod/gosmi/parser"
+)
+
+// TextNormalization controls how the DESCRIPTION, REFERENCE, CONTACT-INFO and
+// REVISION texts of modules are rewritten before they are stored. The zero
+// value stores them as written.
+type TextNormalization struct {
+	// Normalize maps each text to a Unicode normalization form, for example
+	// norm.NFC.String of golang.org/x/text/unicode/norm for NFC.
+	Normalize func(string) string
+	// StripControl removes control characters other than newlines and tabs.
+	StripControl bool
+	// ASCII, for strict mode, also replaces characters outside of printable
+	// ASCII by '?', as RFC 2578 restricts texts to ASCII.
+	ASCII bool
+	// Whitespace is how the parser treats the whitespace of texts before
+	// they are rewritten, by default stripping indentation and compressing
+	// runs of spaces.
+	Whitespace parser.TextWhitespace
+	// Escapes makes the parser interpret backslash escapes in texts, see
+	// parser.WithEscapes.
+	Escapes bool
+}
+
+func SetTextNormalization(normalization TextNormalization) {
+	smiHandle.TextNormalization = normalization
+}
+
+func GetTextNormalization() TextNormalization {
+	return smiHandle.TextNormalization
+}
+
+func (n TextNormalization) apply(s string) string {
+	if n.Normalize != nil {
+		s = n.Normalize(s)
+	}
+	if !n.StripControl && !n.ASCII {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		case n.ASCII && r > unicode.MaxASCII:
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+func (t Text) normalize() Text {
+	n := smiHandle.TextNormalization
+	return Text{Description: n.apply(t.Description), Reference: n.apply(t.Reference), ContactInfo: n.apply(t.ContactInfo)}
+}
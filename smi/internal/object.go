@@ -46,6 +46,7 @@ type Object struct {
 	NextSameNode   *Object
 	UniquenessPtr  *List
 	Line           int
+	Column         int
 
 	lastList           *List
 	lastOptionList     *List
@@ -198,7 +199,7 @@ func (x *ObjectMap) linkObject(oid parser.Oid, o *Object) {
 				continue
 			}
 			if subId.Number == nil {
-				parentName = *subId.Name
+				parentName = smiHandle.strings.of(o.Module).id(*subId.Name)
 				continue
 			}
 		}
@@ -217,7 +218,7 @@ func (x *ObjectMap) linkObject(oid parser.Oid, o *Object) {
 			// Create parent
 			parent := &Object{
 				SmiNode: types.SmiNode{
-					Name:     *subId.Name,
+					Name:     smiHandle.strings.of(o.Module).id(*subId.Name),
 					Decl:     types.DeclImplObject,
 					NodeKind: types.NodeNode,
 				},
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// RootNode is a well-known node which modules can refer to by name without
+// importing it from any module.
+type RootNode struct {
+	Name types.SmiIdentifier
+	Oid  types.Oid
+}
+
+// DefaultRootNodes are the top-level arcs of the OID tree defined by X.660.
+var DefaultRootNodes = []RootNode{
+	{Name: "ccitt", Oid: types.Oid{WellKnownIdCcitt}},
+	{Name: "iso", Oid: types.Oid{WellKnownIdIso}},
+	{Name: "joint-iso-ccitt", Oid: types.Oid{WellKnownIdJointIsoCcitt}},
+}
+
+// SNMPRootNodes are the anchors of the internet subtree that SNMPv2-SMI and
+// RFC1155-SMI define, for loading modules which use them without importing
+// them.
+var SNMPRootNodes = []RootNode{
+	{Name: "org", Oid: types.Oid{1, 3}},
+	{Name: "dod", Oid: types.Oid{1, 3, 6}},
+	{Name: "internet", Oid: types.Oid{1, 3, 6, 1}},
+	{Name: "directory", Oid: types.Oid{1, 3, 6, 1, 1}},
+	{Name: "mgmt", Oid: types.Oid{1, 3, 6, 1, 2}},
+	{Name: "mib-2", Oid: types.Oid{1, 3, 6, 1, 2, 1}},
+	{Name: "transmission", Oid: types.Oid{1, 3, 6, 1, 2, 1, 10}},
+	{Name: "experimental", Oid: types.Oid{1, 3, 6, 1, 3}},
+	{Name: "private", Oid: types.Oid{1, 3, 6, 1, 4}},
+	{Name: "enterprises", Oid: types.Oid{1, 3, 6, 1, 4, 1}},
+	{Name: "security", Oid: types.Oid{1, 3, 6, 1, 5}},
+	{Name: "snmpV2", Oid: types.Oid{1, 3, 6, 1, 6}},
+}
+
+// rootNodes are created in the well-known module of every handle. Unlike the
+// rest of the handle's state, they persist across Exit and Init.
+var rootNodes = append([]RootNode(nil), DefaultRootNodes...)
+
+// RegisterRootNode adds a well-known node, creating it right away if a handle
+// is initialized. Registering a name again with the same OID does nothing.
+func RegisterRootNode(name types.SmiIdentifier, oid types.Oid) error {
+	if name == "" {
+		return errors.New("Name is required")
+	}
+	if len(oid) == 0 {
+		return fmt.Errorf("Root node %s: OID is required", name)
+	}
+	for _, root := range rootNodes {
+		if root.Name != name {
+			continue
+		}
+		if root.Oid.String() != oid.String() {
+			return fmt.Errorf("Root node %s already registered as %s", name, root.Oid)
+		}
+		return nil
+	}
+	root := RootNode{Name: name, Oid: append(types.Oid(nil), oid...)}
+	rootNodes = append(rootNodes, root)
+	if smiHandle != nil {
+		if wellKnownModule := smiHandle.Modules.Get(WellKnownModuleName); wellKnownModule != nil {
+			addRootNode(wellKnownModule, root)
+		}
+	}
+	return nil
+}
+
+// GetRootNodes returns the well-known nodes, in order of registration.
+func GetRootNodes() []RootNode {
+	return append([]RootNode(nil), rootNodes...)
+}
+
+// ResetRootNodes drops the registered well-known nodes, back to
+// DefaultRootNodes, from the next Init on.
+func ResetRootNodes() {
+	rootNodes = append([]RootNode(nil), DefaultRootNodes...)
+}
+
+// addRootNode creates the nodes leading to root as needed and attaches an
+// object for it in the well-known module.
+func addRootNode(wellKnownModule *Module, root RootNode) {
+	nodePtr := smiHandle.RootNode
+	for _, subId := range root.Oid {
+		child := nodePtr.Children.Get(subId)
+		if child == nil {
+			child = &Node{SubId: subId, Parent: nodePtr}
+			nodePtr.Children.Add(child)
+		}
+		nodePtr = child
+	}
+	obj := &Object{
+		SmiNode: types.SmiNode{
+			Name:     root.Name,
+			Decl:     types.DeclImplObject,
+			NodeKind: types.NodeNode,
+		},
+		Module: wellKnownModule,
+	}
+	nodePtr.AddObject(obj)
+	wellKnownModule.Objects.Add(obj)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// addStubModule registers an empty module flagged as a stub in place of a
+// module that could not be loaded.
+func addStubModule(name types.SmiIdentifier) *Module {
+	out := &Module{
+		SmiModule: types.SmiModule{
+			Name: name,
+		},
+		Flags: FlagStub,
+	}
+	smiHandle.Modules.Add(out)
+	invalidateView()
+	return out
+}
+
+// addStubs adds an unknown placeholder for each name not yet defined in the
+// stub module. Names are classified the way SMI spells them: all upper case
+// for macros, an upper case initial for types and anything else for objects.
+func (x *Module) addStubs(names []types.SmiIdentifier) {
+	for _, name := range names {
+		switch {
+		case x.Objects.Get(name) != nil || x.Types.Get(name) != nil || x.Macros.Get(name) != nil:
+		case isMacroName(name):
+			x.Macros.Add(&Macro{
+				SmiMacro: types.SmiMacro{
+					Name: name,
+					Decl: types.DeclMacro,
+				},
+				Module: x,
+				Flags:  FlagStub,
+			})
+		case unicode.IsUpper(rune(name[0])):
+			x.Types.Add(&Type{
+				SmiType: types.SmiType{
+					Name: name,
+				},
+				Module: x,
+				Flags:  FlagStub,
+			})
+		default:
+			x.Objects.Add(&Object{
+				SmiNode: types.SmiNode{
+					Name: name,
+				},
+				Module: x,
+				Flags:  FlagStub | FlagIncomplete,
+			})
+		}
+	}
+}
+
+func isMacroName(name types.SmiIdentifier) bool {
+	return name != "" && strings.ToUpper(name.String()) == name.String() && unicode.IsUpper(rune(name[0]))
+}
+
+func joinIdentifiers(names []types.SmiIdentifier) string {
+	s := make([]string, len(names))
+	for i, name := range names {
+		s[i] = name.String()
+	}
+	return strings.Join(s, ", ")
+}
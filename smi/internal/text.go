@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Text holds the DESCRIPTION, REFERENCE and, for modules, CONTACT-INFO
+// clauses of a definition, which modules built with LazyText set do not
+// store.
+type Text struct {
+	Description string
+	Reference   string
+	ContactInfo string
+}
+
+// lazyAST caches the last module parsed again for its texts, as they tend to
+// be asked for definition after definition.
+type lazyAST struct {
+	module *Module
+	ast    *parser.Module
+}
+
+// openModulePath opens the file a module was loaded from: the file in the
+// search path it was found as, or the file at path itself for modules parsed
+// outside the search path.
+func openModulePath(name types.SmiIdentifier, path string) (io.ReadCloser, error) {
+	files, err := findModuleFiles(name.String(), true)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.path == path {
+			return f.open()
+		}
+	}
+	return os.Open(path)
+}
+
+// text returns the AST of the module, parsing its file again if the module
+// does not keep it.
+func (x *Module) text() (*parser.Module, error) {
+	if x.AST != nil {
+		return x.AST, nil
+	}
+	if smiHandle.lazyAST.module == x {
+		return smiHandle.lazyAST.ast, nil
+	}
+	f, err := openModulePath(x.Name, x.Path)
+	if err != nil {
+		return nil, fmt.Errorf("Open module file %q: %w", x.Path, err)
+	}
+	defer f.Close()
+	in, err := parseModuleFile(x.Path, f, new(diag.Collector), nil)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != x.Name {
+		return nil, fmt.Errorf("Module file %q now provides %s instead of %s", x.Path, in.Name, x.Name)
+	}
+	smiHandle.lazyAST = lazyAST{module: x, ast: in}
+	return in, nil
+}
+
+// GetText returns the texts of the module itself.
+func (x *Module) GetText() (Text, error) {
+	if !x.Flags.Has(FlagLazyText) {
+		return Text{Description: x.Description, Reference: x.Reference, ContactInfo: x.ContactInfo}, nil
+	}
+	in, err := x.text()
+	if err != nil || in.Body.Identity == nil {
+		return Text{}, err
+	}
+	return Text{Description: in.Body.Identity.Description, ContactInfo: in.Body.Identity.ContactInfo}.normalize(), nil
+}
+
+// GetText returns the texts of the object.
+func (x *Object) GetText() (Text, error) {
+	if x.Module == nil || !x.Module.Flags.Has(FlagLazyText) || x.Decl == types.DeclImplObject {
+		return Text{Description: x.Description, Reference: x.Reference}, nil
+	}
+	text, err := x.lazyText()
+	return text.normalize(), err
+}
+
+// lazyText returns the texts of the object as written in its module.
+func (x *Object) lazyText() (Text, error) {
+	in, err := x.Module.text()
+	if err != nil {
+		return Text{}, err
+	}
+	if in.Body.Identity != nil && in.Body.Identity.Name == x.Name {
+		return Text{Description: in.Body.Identity.Description}, nil
+	}
+	for i := range in.Body.Nodes {
+		node := &in.Body.Nodes[i]
+		if node.Name != x.Name {
+			continue
+		}
+		switch {
+		case node.ObjectIdentity != nil:
+			return Text{Description: node.ObjectIdentity.Description, Reference: node.ObjectIdentity.Reference}, nil
+		case node.ObjectGroup != nil:
+			return Text{Description: node.ObjectGroup.Description, Reference: node.ObjectGroup.Reference}, nil
+		case node.ObjectType != nil:
+			return Text{Description: node.ObjectType.Description, Reference: node.ObjectType.Reference}, nil
+		case node.NotificationGroup != nil:
+			return Text{Description: node.NotificationGroup.Description, Reference: node.NotificationGroup.Reference}, nil
+		case node.NotificationType != nil:
+			return Text{Description: node.NotificationType.Description, Reference: node.NotificationType.Reference}, nil
+		case node.ModuleCompliance != nil:
+			return Text{Description: node.ModuleCompliance.Description, Reference: node.ModuleCompliance.Reference}, nil
+		case node.AgentCapabilities != nil:
+			return Text{Description: node.AgentCapabilities.Description, Reference: node.AgentCapabilities.Reference}, nil
+		case node.TrapType != nil:
+			return Text{Description: node.TrapType.Description, Reference: node.TrapType.Reference}, nil
+		}
+		break
+	}
+	return Text{Description: x.Description, Reference: x.Reference}, nil
+}
+
+// GetText returns the texts of the type.
+func (x *Type) GetText() (Text, error) {
+	if x.Module == nil || !x.Module.Flags.Has(FlagLazyText) || x.Decl != types.DeclTextualConvention {
+		return Text{Description: x.Description, Reference: x.Reference}, nil
+	}
+	in, err := x.Module.text()
+	if err != nil {
+		return Text{}, err
+	}
+	for i := range in.Body.Types {
+		if t := &in.Body.Types[i]; t.Name == x.Name && t.TextualConvention != nil {
+			return Text{Description: t.TextualConvention.Description, Reference: t.TextualConvention.Reference}.normalize(), nil
+		}
+	}
+	return Text{}, nil
+}
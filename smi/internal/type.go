@@ -1,9 +1,11 @@
 package internal
 
 import (
+	"fmt"
 	"sort"
 	"strconv"
 
+	"github.com/lukeod/gosmi/diag"
 	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/types"
 )
@@ -17,6 +19,9 @@ type Type struct {
 	Prev   *Type
 	Next   *Type
 	Line   int
+	Column int
+	// Tag is the number of the APPLICATION tag of the type if FlagTagged is set
+	Tag int
 
 	lastList *List
 }
@@ -95,6 +100,58 @@ type Range struct {
 	List *List
 }
 
+// addChoiceType adds a CHOICE type, such as NetworkAddress or ObjectSyntax
+// of RFC1155-SMI. A CHOICE of a single type derives from it. Otherwise it has
+// the base type its alternatives share, if any, and no parent. Unless force
+// is set, nothing is added if an alternative does not resolve yet.
+func (x *Module) addChoiceType(path string, t parser.Type, force bool) bool {
+	var alternatives []*Type
+	unknown := false
+	for _, entry := range t.Sequence.Entries {
+		if entry.Syntax.Name == "NULL" {
+			continue
+		}
+		alternative := GetBaseTypeFromSyntax(entry.Syntax)
+		if alternative == nil {
+			alternative = x.GetType(entry.Syntax.Name)
+		}
+		if alternative == nil {
+			if !force {
+				return false
+			}
+			report(diag.CodeUnknownType, x.Name, path, entry.Pos.Line, fmt.Sprintf("Unknown type %s for alternative %s of type %s", entry.Syntax.Name, entry.Descriptor, t.Name))
+			unknown = true
+			continue
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	typ := &Type{
+		SmiType: types.SmiType{
+			Name: smiHandle.strings.of(x).id(t.Name),
+			Decl: types.DeclTypeAssignment,
+		},
+		Module: x,
+		Line:   t.Pos.Line,
+		Column: t.Pos.Column,
+	}
+	switch {
+	case unknown || len(alternatives) == 0:
+	case len(alternatives) == 1:
+		typ.Parent = alternatives[0]
+		typ.BaseType = alternatives[0].BaseType
+	default:
+		typ.BaseType = alternatives[0].BaseType
+		for _, alternative := range alternatives[1:] {
+			if alternative.BaseType != typ.BaseType {
+				typ.BaseType = types.BaseTypeUnknown
+				break
+			}
+		}
+	}
+	x.Types.Add(typ)
+	return true
+}
+
 func GetBaseTypeFromSyntax(syntax parser.SyntaxType) *Type {
 	switch syntax.Name {
 	case "BITS":
@@ -228,8 +285,73 @@ func namedNumberSort(namedNumbers []parser.NamedNumber) {
 	})
 }
 
-func rangeSort(ranges []parser.Range) {
-	sort.Slice(ranges, func(i, j int) bool {
+// rangeSort sorts ranges by their start value and reports whether they were
+// already in order.
+func rangeSort(ranges []parser.Range) (sorted bool) {
+	less := func(i, j int) bool {
 		return intStringLess(ranges[i].Start, ranges[j].Start)
-	})
+	}
+	if sort.SliceIsSorted(ranges, less) {
+		return true
+	}
+	sort.Slice(ranges, less)
+	return false
+}
+
+// widensParent reports whether a range of x is not covered by the ranges of
+// a type it is derived from. Refinements may only restrict the values of
+// their parent type (RFC 2578, section 9).
+func (x *Type) widensParent() bool {
+	seen := map[*Type]bool{x: true}
+	for parent := x.Parent; parent != nil && !seen[parent]; parent = parent.Parent {
+		seen[parent] = true
+		if parent.List == nil {
+			continue
+		}
+		for list := x.List; list != nil; list = list.Next {
+			r, ok := list.Ptr.(*Range)
+			if ok && !parent.coversRange(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (x *Type) coversRange(r *Range) bool {
+	for list := x.List; list != nil; list = list.Next {
+		if p, ok := list.Ptr.(*Range); ok && !valueLess(r.MinValue, p.MinValue) && !valueLess(p.MaxValue, r.MaxValue) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueLess(a, b types.SmiValue) bool {
+	aNeg, aMag := valueMagnitude(a)
+	bNeg, bMag := valueMagnitude(b)
+	switch {
+	case aNeg != bNeg:
+		return aNeg
+	case aNeg:
+		return aMag > bMag
+	}
+	return aMag < bMag
+}
+
+func valueMagnitude(v types.SmiValue) (neg bool, mag uint64) {
+	switch n := v.Value.(type) {
+	case int32:
+		return valueMagnitude(types.SmiValue{Value: int64(n)})
+	case int64:
+		if n < 0 {
+			return true, uint64(-(n + 1)) + 1
+		}
+		return false, uint64(n)
+	case uint32:
+		return false, uint64(n)
+	case uint64:
+		return false, n
+	}
+	return false, 0
 }
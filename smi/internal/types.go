@@ -34,6 +34,9 @@ const (
 	FlagInGroup      Flags = 0x0080 // Node is contained in a group
 	FlagInCompliance Flags = 0x0100 // Group is mentioned in a compliance statement. In case of ImportFlags: the import is done through a compliance MODULE phrase
 	FlagInSyntax     Flags = 0x0200 // Type is mentioned in a syntax statement
+	FlagStub         Flags = 0x0400 // Stand-in for a symbol or module that could not be loaded
+	FlagLazyText     Flags = 0x0800 // On a Module: texts are not stored but loaded from the file on demand
+	FlagTagged       Flags = 0x1000 // On a Type: defined with an [APPLICATION n] IMPLICIT tag, n being its Tag
 )
 
 func (x Flags) Has(flag Flags) bool {
@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// UnresolvedImport is a symbol imported by Module that unloading or
+// reloading left without a definition.
+type UnresolvedImport struct {
+	Module types.SmiIdentifier
+	Import types.SmiImport
+}
+
+// UnloadReport describes what UnloadModule or ReloadModule removed.
+type UnloadReport struct {
+	// Modules are the module asked for followed by the modules importing it,
+	// directly or not, in load order. They were all unloaded.
+	Modules []types.SmiIdentifier
+	// Unresolved are the symbols the other modules imported from the module
+	// asked for. After ReloadModule, only those it no longer defines.
+	Unresolved []UnresolvedImport
+	// Unloaded are the modules ReloadModule failed to build again, which
+	// are left unloaded.
+	Unloaded []types.SmiIdentifier
+}
+
+// provides returns whether the module defines a symbol that can be imported
+// under name.
+func (x *Module) provides(name types.SmiIdentifier) bool {
+	return x.Objects.Get(name) != nil || x.Types.Get(name) != nil || x.Macros.Get(name) != nil || x.Values.Get(name) != nil
+}
+
+// dependents returns module followed by the loaded modules that import from
+// it directly or through other modules, in load order.
+func dependents(module *Module) []*Module {
+	unloading := map[types.SmiIdentifier]bool{module.Name: true}
+	out := []*Module{module}
+	for changed := true; changed; {
+		changed = false
+		for m := smiHandle.Modules.First; m != nil; m = m.Next {
+			if unloading[m.Name] {
+				continue
+			}
+			for i := m.Imports.First; i != nil; i = i.Next {
+				if unloading[i.Module] {
+					unloading[m.Name] = true
+					out = append(out, m)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	// Modules found in later passes may have been loaded before others
+	order := make(map[*Module]int)
+	n := 0
+	for m := smiHandle.Modules.First; m != nil; m = m.Next {
+		order[m] = n
+		n++
+	}
+	deps := out[1:]
+	sort.Slice(deps, func(i, j int) bool { return order[deps[i]] < order[deps[j]] })
+	return out
+}
+
+// UnloadModule removes the named module and the modules importing it, which
+// hold references to its definitions, from the handle.
+func UnloadModule(name string) (UnloadReport, error) {
+	module := FindModuleByName(name)
+	if module == nil {
+		return UnloadReport{}, fmt.Errorf("Module %s is not loaded", name)
+	}
+	if module.IsWellKnown() {
+		return UnloadReport{}, fmt.Errorf("Module %s cannot be unloaded", name)
+	}
+	var report UnloadReport
+	modules := dependents(module)
+	for _, m := range modules {
+		report.Modules = append(report.Modules, m.Name)
+		if m == module {
+			continue
+		}
+		for i := m.Imports.First; i != nil; i = i.Next {
+			if i.Module == module.Name {
+				report.Unresolved = append(report.Unresolved, UnresolvedImport{Module: m.Name, Import: i.SmiImport})
+			}
+		}
+	}
+	for _, m := range modules {
+		removeModule(m)
+	}
+	return report, nil
+}
+
+// ReloadModule unloads the named module like UnloadModule, then builds it
+// and the modules importing it again from the files they were loaded from.
+// The files are parsed first: if one no longer parses or provides another
+// module, nothing is unloaded. A module that fails to build is left unloaded
+// and listed in the report's Unloaded.
+func ReloadModule(name string) (UnloadReport, error) {
+	module := FindModuleByName(name)
+	if module == nil {
+		return UnloadReport{}, fmt.Errorf("Module %s is not loaded", name)
+	}
+	if module.IsWellKnown() {
+		return UnloadReport{}, fmt.Errorf("Module %s cannot be unloaded", name)
+	}
+	var reparsed []parsedModule
+	for _, m := range dependents(module) {
+		p, err := reparseModuleFile(m.Name, m.Path)
+		if err != nil {
+			return UnloadReport{}, fmt.Errorf("Reload module %s: %w", m.Name, err)
+		}
+		reparsed = append(reparsed, p)
+	}
+	report, err := UnloadModule(name)
+	if err != nil {
+		return report, err
+	}
+	var buildErr error
+	for _, p := range reparsed {
+		if FindModuleByName(p.module.Name.String()) != nil {
+			// Loaded meanwhile as an import of a module rebuilt before
+			continue
+		}
+		out, err := BuildModule(p.path, p.module)
+		if err != nil {
+			report.Unloaded = append(report.Unloaded, p.module.Name)
+			if buildErr == nil {
+				buildErr = fmt.Errorf("Reload module %s: Build module: %w", p.module.Name, err)
+			}
+			continue
+		}
+		out.setParseTiming(p.timing)
+	}
+	if buildErr != nil {
+		names := make([]string, len(report.Unloaded))
+		for i, m := range report.Unloaded {
+			names[i] = m.String()
+		}
+		return report, fmt.Errorf("%w; left unloaded: %s", buildErr, strings.Join(names, ", "))
+	}
+	reloaded := FindModuleByName(name)
+	unresolved := report.Unresolved[:0]
+	for _, u := range report.Unresolved {
+		if !reloaded.provides(u.Import.Name) {
+			unresolved = append(unresolved, u)
+		}
+	}
+	report.Unresolved = unresolved
+	return report, nil
+}
+
+// reparseModuleFile parses the module in the file at path again, checking
+// that it still provides the module name.
+func reparseModuleFile(name types.SmiIdentifier, path string) (parsedModule, error) {
+	f, err := openModulePath(name, path)
+	if err != nil {
+		return parsedModule{}, fmt.Errorf("Open module file %q: %w", path, err)
+	}
+	defer f.Close()
+	var diagnostics diag.Collector
+	var timing parser.Timing
+	in, err := parseModuleFile(path, f, &diagnostics, &timing)
+	for _, d := range diagnostics.Diagnostics() {
+		reportDiagnostic(d)
+	}
+	if err != nil {
+		return parsedModule{}, err
+	}
+	if in.Name != name {
+		return parsedModule{}, fmt.Errorf("Module file %q now provides %s", path, in.Name)
+	}
+	return parsedModule{path: path, module: in, timing: timing}, nil
+}
+
+// removeModule detaches the objects of module from the OID tree, dropping
+// the nodes left empty, and removes it from the handle.
+func removeModule(module *Module) {
+	removeObjects(smiHandle.RootNode, module)
+	smiHandle.Modules.Remove(module)
+	smiHandle.strings.release(module)
+	invalidateView()
+	if smiHandle.lazyAST.module == module {
+		smiHandle.lazyAST = lazyAST{}
+	}
+}
+
+func removeObjects(nodePtr *Node, module *Module) {
+	for child := nodePtr.Children.First; child != nil; {
+		next := child.Next
+		removeObjects(child, module)
+		if child.FirstObject == nil && child.Children.First == nil {
+			nodePtr.Children.Remove(child)
+		}
+		child = next
+	}
+	for obj := nodePtr.FirstObject; obj != nil; {
+		next := obj.NextSameNode
+		if obj.Module == module {
+			nodePtr.removeObject(obj)
+		}
+		obj = next
+	}
+}
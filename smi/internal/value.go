@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+type Value struct {
+	types.SmiValueAssignment
+	Module *Module
+	Type   *Type
+	Next   *Value
+	Prev   *Value
+	Line   int
+}
+
+type ValueMap struct {
+	First *Value
+
+	last *Value
+	m    map[types.SmiIdentifier]*Value
+}
+
+func (x *ValueMap) Add(v *Value) {
+	v.Prev = x.last
+	if x.First == nil {
+		x.First = v
+	} else {
+		x.last.Next = v
+	}
+	x.last = v
+
+	if x.m == nil {
+		x.m = make(map[types.SmiIdentifier]*Value)
+	}
+	x.m[v.Name] = v
+}
+
+func (x *ValueMap) Get(name types.SmiIdentifier) *Value {
+	if x.m == nil {
+		return nil
+	}
+	return x.m[name]
+}
+
+func (x *ValueMap) GetName(name string) *Value {
+	return x.Get(types.SmiIdentifier(name))
+}
+
+// getNamedNumber returns the value of the named number of the type or the
+// types it derives from.
+func (x *Type) getNamedNumber(name string) (types.SmiValue, bool) {
+	for t := x; t != nil; t = t.Parent {
+		for list := t.List; list != nil; list = list.Next {
+			if n, ok := list.Ptr.(*NamedNumber); ok && n.Name.String() == name {
+				return n.Value, true
+			}
+		}
+	}
+	return types.SmiValue{}, false
+}
+
+// assignedValue converts the literal of a value assignment to a value of the
+// type. Literals not understood for the type are kept as strings.
+func assignedValue(literal string, typ *Type) types.SmiValue {
+	if typ == nil {
+		return types.SmiValue{Value: literal}
+	}
+	if v, ok := typ.getNamedNumber(literal); ok {
+		return v
+	}
+	switch typ.BaseType {
+	case types.BaseTypeInteger32, types.BaseTypeInteger64, types.BaseTypeUnsigned32, types.BaseTypeUnsigned64:
+		if literal != "" && (literal[0] == '-' || literal[0] == '\'' || literal[0] >= '0' && literal[0] <= '9') {
+			return GetValue(literal, typ.BaseType)
+		}
+	case types.BaseTypeOctetString:
+		b, ok := quotedBytes(literal)
+		if !ok {
+			b = []byte(literal)
+		}
+		return types.SmiValue{BaseType: typ.BaseType, Len: uint(len(b)), Value: b}
+	}
+	return types.SmiValue{BaseType: typ.BaseType, Value: literal}
+}
+
+// quotedBytes decodes a 'hex'H or 'binary'B string. Both are padded with
+// zero bits to whole bytes on the right.
+func quotedBytes(literal string) ([]byte, bool) {
+	if len(literal) < 3 || literal[0] != '\'' || literal[len(literal)-2] != '\'' {
+		return nil, false
+	}
+	digits := literal[1 : len(literal)-2]
+	switch literal[len(literal)-1] {
+	case 'H':
+		if len(digits)%2 != 0 {
+			digits += "0"
+		}
+		b, err := hex.DecodeString(digits)
+		return b, err == nil
+	case 'B':
+		if strings.Trim(digits, "01") != "" {
+			return nil, false
+		}
+		b := make([]byte, (len(digits)+7)/8)
+		for i, c := range digits {
+			if c == '1' {
+				b[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		return b, true
+	}
+	return nil, false
+}
@@ -0,0 +1,324 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/types"
+)
+
+// VersionPolicy controls which file is loaded when several files provide the
+// same module, typically different revisions of it.
+type VersionPolicy int
+
+const (
+	// VersionFirst loads the first file in search path or walk order.
+	VersionFirst VersionPolicy = iota
+	// VersionLatest loads the file with the latest revision, as given by
+	// LAST-UPDATED and REVISION clauses, and the first of those tied.
+	VersionLatest
+	// VersionError fails to load a module provided by more than one file.
+	VersionError
+)
+
+// ErrAmbiguousModule is returned under VersionError when several files
+// provide the module being loaded.
+var ErrAmbiguousModule = errors.New("Module provided by several files")
+
+// ModuleVersion is a file providing a module.
+type ModuleVersion struct {
+	Name string
+	Path string
+	// Revision is the latest of LAST-UPDATED and the REVISION dates, zero
+	// for modules without MODULE-IDENTITY.
+	Revision time.Time
+	// Loaded is set for the file the loaded module came from.
+	Loaded bool
+	// Err is set if the file could not be parsed, which excludes it from
+	// selection.
+	Err error
+	// Timing is the time lexing and parsing the file took, if known.
+	Timing parser.Timing
+	// DuplicateOf is the path of an earlier file with the same content, set
+	// for copies of a file in several search path directories. Copies are
+	// not parsed again and the earlier file stands for them in selection.
+	DuplicateOf string
+
+	module      *parser.Module
+	diagnostics []diag.Diagnostic
+}
+
+// NewModuleVersion describes a file parsed outside the search path.
+func NewModuleVersion(path string, in *parser.Module) ModuleVersion {
+	return ModuleVersion{
+		Name:     in.Name.String(),
+		Path:     path,
+		Revision: moduleRevision(in),
+		module:   in,
+	}
+}
+
+func moduleRevision(in *parser.Module) (t time.Time) {
+	identity := in.Body.Identity
+	if identity == nil {
+		return
+	}
+	t = identity.LastUpdated.ToTime()
+	for _, r := range identity.Revisions {
+		if d := r.Date.ToTime(); d.After(t) {
+			t = d
+		}
+	}
+	return
+}
+
+func SetVersionPolicy(policy VersionPolicy) {
+	smiHandle.VersionPolicy = policy
+}
+
+func GetVersionPolicy() VersionPolicy {
+	return smiHandle.VersionPolicy
+}
+
+// SetPreferredPath makes loading the named module use the file at path,
+// whatever the version policy. An empty path removes the preference.
+func SetPreferredPath(name, path string) {
+	if path == "" {
+		delete(smiHandle.PreferredPaths, name)
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if smiHandle.PreferredPaths == nil {
+		smiHandle.PreferredPaths = make(map[string]string)
+	}
+	smiHandle.PreferredPaths[name] = path
+}
+
+// versionSelection reports whether loading the module needs all the files
+// providing it, rather than the first one found.
+func versionSelection(name string) bool {
+	if filepath.Ext(name) != "" || strings.ContainsAny(name, `/\~`) {
+		// A file rather than a module name
+		return false
+	}
+	_, preferred := smiHandle.PreferredPaths[name]
+	return preferred || smiHandle.VersionPolicy != VersionFirst
+}
+
+// selectVersion returns the index of the version to load according to the
+// preferred paths and the version policy.
+func selectVersion(name string, versions []ModuleVersion) (int, error) {
+	if path, ok := smiHandle.PreferredPaths[name]; ok {
+		for _, v := range versions {
+			if v.Path == path && v.DuplicateOf != "" {
+				// A copy is loaded from the file it copies
+				path = v.DuplicateOf
+				break
+			}
+		}
+		for i, v := range versions {
+			if v.Path == path {
+				return i, v.Err
+			}
+		}
+		return -1, fmt.Errorf("Preferred file %s of module %s not found", path, name)
+	}
+
+	selected := -1
+	var paths []string
+	for i, v := range versions {
+		if v.Err != nil || v.DuplicateOf != "" {
+			continue
+		}
+		paths = append(paths, v.Path)
+		switch {
+		case selected < 0:
+			selected = i
+		case smiHandle.VersionPolicy == VersionLatest && v.Revision.After(versions[selected].Revision):
+			selected = i
+		}
+	}
+	switch {
+	case selected < 0 && len(versions) > 0:
+		return -1, versions[0].Err
+	case selected < 0:
+		return -1, os.ErrNotExist
+	case smiHandle.VersionPolicy == VersionError && len(paths) > 1:
+		return -1, fmt.Errorf("%w: %s in %s", ErrAmbiguousModule, name, strings.Join(paths, ", "))
+	}
+	return selected, nil
+}
+
+// searchVersions parses every file in the search path named after the
+// module, other than copies of a file found earlier.
+func searchVersions(name string) ([]ModuleVersion, error) {
+	files, err := findModuleFiles(name, true)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ModuleVersion, 0, len(files))
+	// Paths of the files read by the hash of their content
+	seen := make(map[[sha256.Size]byte]string)
+	for _, file := range files {
+		v := ModuleVersion{Name: name, Path: file.path}
+		src, err := readModuleFile(file)
+		if err != nil {
+			v.Err = err
+			versions = append(versions, v)
+			continue
+		}
+		hash := sha256.Sum256(src)
+		if path, ok := seen[hash]; ok {
+			v.DuplicateOf = path
+			versions = append(versions, v)
+			continue
+		}
+		seen[hash] = file.path
+		var diagnostics diag.Collector
+		in, err := parseModuleFile(file.path, bytes.NewReader(src), &diagnostics, &v.Timing)
+		v.diagnostics = diagnostics.Diagnostics()
+		switch {
+		case err != nil:
+			v.Err = err
+		case in.Name.String() != name:
+			v.Err = fmt.Errorf("File defines module %s", in.Name)
+		default:
+			v.Revision = moduleRevision(in)
+			v.module = in
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// readModuleFile reads the file, up to one byte more than the size limit
+// for its parse to reject it.
+func readModuleFile(file moduleFile) ([]byte, error) {
+	f, err := file.open()
+	if err != nil {
+		return nil, fmt.Errorf("Open file: %w", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if max := smiHandle.Limits.MaxBytes; max > 0 {
+		r = io.LimitReader(f, int64(max)+1)
+	}
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Read module file %q: %w", file.path, err)
+	}
+	return src, nil
+}
+
+// loadModuleVersion loads the module from the file selected among those in
+// the search path. It returns an error wrapping os.ErrNotExist if there are
+// none.
+func loadModuleVersion(name string) (*Module, error) {
+	versions, err := searchVersions(name)
+	if err != nil {
+		return nil, fmt.Errorf("Find module files: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, os.ErrNotExist
+	}
+	recordVersions(name, versions)
+	i, err := selectVersion(name, versions)
+	if err != nil {
+		// Explain the first file that failed to parse, if any
+		for _, v := range versions {
+			if v.Err != nil {
+				for _, d := range v.diagnostics {
+					reportDiagnostic(d)
+				}
+				break
+			}
+		}
+		return nil, err
+	}
+	for _, d := range versions[i].diagnostics {
+		reportDiagnostic(d)
+	}
+	out, err := BuildModule(versions[i].Path, versions[i].module)
+	if err != nil {
+		return nil, fmt.Errorf("Build module: %w", err)
+	}
+	out.setParseTiming(versions[i].Timing)
+	return out, nil
+}
+
+// AddModuleVersions selects one of versions, files providing the same module
+// that were parsed outside the search path, and adds it like
+// AddParsedModule. It returns the index of the selected version and, if the
+// module is already loaded or pending, the path providing it, in which case
+// nothing is added. The versions are remembered for GetModuleVersions.
+func AddModuleVersions(versions []ModuleVersion) (selected int, existing string, err error) {
+	if len(versions) == 0 {
+		return -1, "", os.ErrNotExist
+	}
+	name := versions[0].Name
+	recordVersions(name, versions)
+	selected, err = selectVersion(name, versions)
+	if err != nil {
+		return -1, "", err
+	}
+	if existing, ok := addParsedModule(versions[selected].Path, versions[selected].module, versions[selected].Timing); !ok {
+		return selected, existing, nil
+	}
+	return selected, "", nil
+}
+
+// recordVersions remembers versions for GetModuleVersions, without their
+// ASTs.
+func recordVersions(name string, versions []ModuleVersion) {
+	if smiHandle.versions == nil {
+		smiHandle.versions = make(map[types.SmiIdentifier][]ModuleVersion)
+	}
+	id := types.SmiIdentifier(name)
+	for _, v := range versions {
+		v.module, v.diagnostics = nil, nil
+		smiHandle.versions[id] = append(smiHandle.versions[id], v)
+	}
+}
+
+// GetModuleVersions returns the files known to provide the named module:
+// those given to AddModuleVersions and those in the search path, which are
+// parsed for their revisions.
+func GetModuleVersions(name string) ([]ModuleVersion, error) {
+	seen := make(map[string]bool)
+	var out []ModuleVersion
+	add := func(versions []ModuleVersion) {
+		for _, v := range versions {
+			if !seen[v.Path] {
+				seen[v.Path] = true
+				out = append(out, v)
+			}
+		}
+	}
+	add(smiHandle.versions[types.SmiIdentifier(name)])
+	searched, err := searchVersions(name)
+	if err != nil {
+		return nil, fmt.Errorf("Find module files: %w", err)
+	}
+	add(searched)
+
+	loaded := ""
+	if m := FindModuleByName(name); m != nil {
+		loaded = m.Path
+	}
+	for i := range out {
+		out[i].Loaded = out[i].Path == loaded
+		out[i].module, out[i].diagnostics = nil, nil
+	}
+	return out, nil
+}
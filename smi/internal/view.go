@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// views holds the named sets of modules of a handle and the one lookups are
+// scoped to.
+type views struct {
+	m       map[string][]types.SmiIdentifier
+	current string
+	// scope caches the modules in the current view, nil until computed.
+	scope map[types.SmiIdentifier]bool
+}
+
+// DefineView names a set of modules. Redefining a view replaces its modules;
+// if it is the current one, lookups follow the new set.
+func DefineView(name string, modules []string) error {
+	if name == "" {
+		return errors.New("Name is required")
+	}
+	if smiHandle.views.m == nil {
+		smiHandle.views.m = make(map[string][]types.SmiIdentifier)
+	}
+	ids := make([]types.SmiIdentifier, len(modules))
+	for i, module := range modules {
+		ids[i] = smiHandle.strings.of(nil).id(types.SmiIdentifier(module))
+	}
+	smiHandle.views.m[name] = ids
+	invalidateView()
+	return nil
+}
+
+// GetView returns the modules of the named view.
+func GetView(name string) ([]types.SmiIdentifier, bool) {
+	ids, ok := smiHandle.views.m[name]
+	return append([]types.SmiIdentifier(nil), ids...), ok
+}
+
+// GetViews returns the names of the defined views, sorted.
+func GetViews() []string {
+	names := make([]string, 0, len(smiHandle.views.m))
+	for name := range smiHandle.views.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetView scopes lookups across modules to the named view. An empty name
+// removes the scope.
+func SetView(name string) error {
+	if _, ok := smiHandle.views.m[name]; name != "" && !ok {
+		return fmt.Errorf("View %s is not defined", name)
+	}
+	smiHandle.views.current = name
+	invalidateView()
+	return nil
+}
+
+// CurrentView returns the name of the view lookups are scoped to, empty if
+// none.
+func CurrentView() string {
+	return smiHandle.views.current
+}
+
+// LoadView loads the modules of the named view and switches to it. The view
+// is not switched to if a module fails to load.
+func LoadView(name string) error {
+	ids, ok := smiHandle.views.m[name]
+	if !ok {
+		return fmt.Errorf("View %s is not defined", name)
+	}
+	for _, id := range ids {
+		if _, err := GetModule(id.String()); err != nil {
+			return fmt.Errorf("View %s: %w", name, err)
+		}
+	}
+	return SetView(name)
+}
+
+// invalidateView drops the cached scope, to be called whenever the view or
+// the loaded modules change.
+func invalidateView() {
+	smiHandle.views.scope = nil
+}
+
+// InView returns whether the module is visible in the current view: it is
+// listed in the view, imported from directly or not by a listed module, or is
+// the well-known module. Every module is visible when no view is set.
+func InView(module *Module) bool {
+	if smiHandle.views.current == "" || module == nil || module.IsWellKnown() {
+		return true
+	}
+	if smiHandle.views.scope == nil {
+		smiHandle.views.scope = viewScope(smiHandle.views.m[smiHandle.views.current])
+	}
+	return smiHandle.views.scope[module.Name]
+}
+
+// viewScope returns the modules listed and the modules they import,
+// transitively.
+func viewScope(ids []types.SmiIdentifier) map[types.SmiIdentifier]bool {
+	scope := make(map[types.SmiIdentifier]bool, len(ids))
+	queue := append([]types.SmiIdentifier(nil), ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if scope[id] {
+			continue
+		}
+		scope[id] = true
+		module := smiHandle.Modules.Get(id)
+		if module == nil {
+			continue
+		}
+		for i := module.Imports.First; i != nil; i = i.Next {
+			if !scope[i.Module] {
+				queue = append(queue, i.Module)
+			}
+		}
+	}
+	return scope
+}
+
+// GetNextModule returns the module loaded after module that is in the
+// current view.
+func GetNextModule(module *Module) *Module {
+	for module = module.Next; module != nil && !InView(module); module = module.Next {
+	}
+	return module
+}
+
+// FirstObjectInView returns the first object of the node defined by a module
+// in the current view.
+func (x *Node) FirstObjectInView() *Object {
+	for obj := x.FirstObject; obj != nil; obj = obj.NextSameNode {
+		if InView(obj.Module) {
+			return obj
+		}
+	}
+	return nil
+}
@@ -22,7 +22,7 @@ func GetMacro(smiModulePtr *types.SmiModule, macro string) *types.SmiMacro {
 		}
 		return &macroPtr.SmiMacro
 	}
-	for modulePtr = internal.GetFirstModule(); modulePtr != nil; modulePtr = modulePtr.Next {
+	for modulePtr = internal.GetFirstModule(); modulePtr != nil; modulePtr = internal.GetNextModule(modulePtr) {
 		macroPtr := modulePtr.Macros.GetName(macro)
 		if macroPtr != nil {
 			return &macroPtr.SmiMacro
@@ -0,0 +1,30 @@
+package smi
+
+import (
+	"github.com/lukeod/gosmi/smi/internal"
+)
+
+type Metrics = internal.Metrics
+
+const (
+	MetricParseSeconds  = internal.MetricParseSeconds
+	MetricBuildSeconds  = internal.MetricBuildSeconds
+	MetricModulesLoaded = internal.MetricModulesLoaded
+	MetricNodesResolved = internal.MetricNodesResolved
+	MetricCacheHits     = internal.MetricCacheHits
+	MetricCacheMisses   = internal.MetricCacheMisses
+)
+
+// SetMetrics sets where measurements of loading modules are reported, or
+// stops reporting them if metrics is nil. There is no libsmi equivalent.
+func SetMetrics(metrics Metrics) {
+	checkInit()
+	internal.SetMetrics(metrics)
+}
+
+// GetMetrics returns where measurements of loading modules are reported, nil
+// if they are not. There is no libsmi equivalent.
+func GetMetrics() Metrics {
+	checkInit()
+	return internal.GetMetrics()
+}
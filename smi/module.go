@@ -1,9 +1,12 @@
 package smi
 
 import (
+	"context"
 	"fmt"
+	"time"
 	"unsafe"
 
+	"github.com/lukeod/gosmi/parser"
 	"github.com/lukeod/gosmi/smi/internal"
 	"github.com/lukeod/gosmi/types"
 )
@@ -21,12 +24,106 @@ func LoadModule(module string) string {
 	return modulePtr.Name.String()
 }
 
+// AddParsedModule registers an already parsed module so that loading it by
+// name, directly or through IMPORTS, uses the given AST instead of searching
+// the path. If the module name is already provided, the providing path is
+// returned with ok set to false. There is no libsmi equivalent.
+func AddParsedModule(path string, module *parser.Module) (existing string, ok bool) {
+	checkInit()
+	return internal.AddParsedModule(path, module)
+}
+
+// ResolveModule loads the named module like LoadModule, but returns the error
+// instead of printing it. There is no libsmi equivalent.
+func ResolveModule(module string) (string, error) {
+	checkInit()
+	modulePtr, err := internal.GetModule(module)
+	if err != nil {
+		return "", err
+	}
+	return modulePtr.Name.String(), nil
+}
+
+// ResolveModuleContext is ResolveModule, but stops loading the module and its
+// imports with the context's error once ctx is done. There is no libsmi
+// equivalent.
+func ResolveModuleContext(ctx context.Context, module string) (string, error) {
+	checkInit()
+	modulePtr, err := internal.GetModuleContext(ctx, module)
+	if err != nil {
+		return "", err
+	}
+	return modulePtr.Name.String(), nil
+}
+
 // int smiIsLoaded(const char *module)
 func IsLoaded(module string) bool {
 	checkInit()
 	return internal.FindModuleByName(module) != nil
 }
 
+type UnloadReport = internal.UnloadReport
+type UnresolvedImport = internal.UnresolvedImport
+
+// UnloadModule removes the module and the modules importing it from the
+// handle. There is no libsmi equivalent.
+func UnloadModule(module string) (UnloadReport, error) {
+	checkInit()
+	return internal.UnloadModule(module)
+}
+
+// ReloadModule unloads the module and the modules importing it and loads
+// them again from their files, leaving them loaded if a file no longer
+// parses. There is no libsmi equivalent.
+func ReloadModule(module string) (UnloadReport, error) {
+	checkInit()
+	return internal.ReloadModule(module)
+}
+
+// DefineView names a set of modules lookups can be scoped to with SetView.
+// There is no libsmi equivalent.
+func DefineView(name string, modules ...string) error {
+	checkInit()
+	return internal.DefineView(name, modules)
+}
+
+// GetView returns the modules of the named view. There is no libsmi
+// equivalent.
+func GetView(name string) ([]types.SmiIdentifier, bool) {
+	checkInit()
+	return internal.GetView(name)
+}
+
+// GetViews returns the names of the defined views. There is no libsmi
+// equivalent.
+func GetViews() []string {
+	checkInit()
+	return internal.GetViews()
+}
+
+// SetView restricts GetNode, GetType and GetMacro without a module,
+// GetNodeByOID and the module iteration to the modules of the named view and
+// the modules they import. An empty name removes the restriction. There is
+// no libsmi equivalent.
+func SetView(name string) error {
+	checkInit()
+	return internal.SetView(name)
+}
+
+// CurrentView returns the view set with SetView. There is no libsmi
+// equivalent.
+func CurrentView() string {
+	checkInit()
+	return internal.CurrentView()
+}
+
+// LoadView loads the modules of the named view and sets it. There is no
+// libsmi equivalent.
+func LoadView(name string) error {
+	checkInit()
+	return internal.LoadView(name)
+}
+
 // SmiModule *smiGetModule(const char *module)
 func GetModule(module string) *types.SmiModule {
 	if module == "" {
@@ -53,12 +150,44 @@ func GetNextModule(smiModulePtr *types.SmiModule) *types.SmiModule {
 	if smiModulePtr == nil {
 		return nil
 	}
-	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
-	if modulePtr.Next == nil {
+	modulePtr := internal.GetNextModule((*internal.Module)(unsafe.Pointer(smiModulePtr)))
+	if modulePtr == nil {
+		return nil
+	}
+	return &modulePtr.SmiModule
+
+}
+
+// GetModuleAST returns the parsed module the module was built from, or nil
+// for modules not built from a file. There is no libsmi equivalent.
+func GetModuleAST(smiModulePtr *types.SmiModule) *parser.Module {
+	if smiModulePtr == nil {
 		return nil
 	}
-	return &modulePtr.Next.SmiModule
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	return modulePtr.AST
+}
+
+type LoadTiming = internal.LoadTiming
+
+// GetModuleTiming returns the time the phases of loading the module took.
+// There is no libsmi equivalent.
+func GetModuleTiming(smiModulePtr *types.SmiModule) LoadTiming {
+	if smiModulePtr == nil {
+		return LoadTiming{}
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	return modulePtr.Timing
+}
 
+// GetModuleLastUpdated returns the LAST-UPDATED date of the module, zero for
+// modules without MODULE-IDENTITY. There is no libsmi equivalent.
+func GetModuleLastUpdated(smiModulePtr *types.SmiModule) time.Time {
+	if smiModulePtr == nil {
+		return time.Time{}
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	return modulePtr.LastUpdated
 }
 
 // SmiNode *smiGetModuleIdentityNode(SmiModule *smiModulePtr)
@@ -72,3 +201,15 @@ func GetModuleIdentityNode(smiModulePtr *types.SmiModule) *types.SmiNode {
 	}
 	return modulePtr.Identity.GetSmiNode()
 }
+
+// GetModuleText returns the DESCRIPTION and CONTACT-INFO of the module,
+// parsing its file again if it was loaded with lazy texts. There is no libsmi
+// equivalent.
+func GetModuleText(smiModulePtr *types.SmiModule) (description, contactInfo string, err error) {
+	if smiModulePtr == nil {
+		return
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	text, err := modulePtr.GetText()
+	return text.Description, text.ContactInfo, err
+}
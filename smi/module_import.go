@@ -44,3 +44,14 @@ func IsImported(smiModulePtr *types.SmiModule, importedModulePtr *types.SmiModul
 	}
 	return importedModulePtr == nil || importPtr.Module == importedModulePtr.Name
 }
+
+// IsImportResolved returns whether the module imported from is loaded and
+// defines the imported name, rather than a stub standing in for a module that
+// could not be loaded. There is no libsmi equivalent.
+func IsImportResolved(smiImportPtr *types.SmiImport) bool {
+	if smiImportPtr == nil {
+		return false
+	}
+	importPtr := (*internal.Import)(unsafe.Pointer(smiImportPtr))
+	return importPtr.Resolved()
+}
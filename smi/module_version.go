@@ -0,0 +1,61 @@
+package smi
+
+import (
+	"github.com/lukeod/gosmi/parser"
+	"github.com/lukeod/gosmi/smi/internal"
+)
+
+type VersionPolicy = internal.VersionPolicy
+type ModuleVersion = internal.ModuleVersion
+
+const (
+	VersionFirst  = internal.VersionFirst
+	VersionLatest = internal.VersionLatest
+	VersionError  = internal.VersionError
+)
+
+var ErrAmbiguousModule = internal.ErrAmbiguousModule
+
+// SetVersionPolicy sets which file is loaded when several provide the same
+// module. There is no libsmi equivalent.
+func SetVersionPolicy(policy VersionPolicy) {
+	checkInit()
+	internal.SetVersionPolicy(policy)
+}
+
+// GetVersionPolicy returns the policy set with SetVersionPolicy. There is no
+// libsmi equivalent.
+func GetVersionPolicy() VersionPolicy {
+	checkInit()
+	return internal.GetVersionPolicy()
+}
+
+// SetPreferredPath makes loading the named module use the file at path,
+// whatever the version policy, or removes the preference if path is empty.
+// There is no libsmi equivalent.
+func SetPreferredPath(module, path string) {
+	checkInit()
+	internal.SetPreferredPath(module, path)
+}
+
+// NewModuleVersion describes a parsed file for AddModuleVersions. There is
+// no libsmi equivalent.
+func NewModuleVersion(path string, module *parser.Module) ModuleVersion {
+	return internal.NewModuleVersion(path, module)
+}
+
+// AddModuleVersions selects one of several parsed files providing the same
+// module and adds it like AddParsedModule, returning its index. If the module
+// is already provided, nothing is added and the providing path is returned.
+// There is no libsmi equivalent.
+func AddModuleVersions(versions []ModuleVersion) (selected int, existing string, err error) {
+	checkInit()
+	return internal.AddModuleVersions(versions)
+}
+
+// GetModuleVersions returns the files known to provide the named module,
+// from AddModuleVersions and the search path. There is no libsmi equivalent.
+func GetModuleVersions(module string) ([]ModuleVersion, error) {
+	checkInit()
+	return internal.GetModuleVersions(module)
+}
@@ -1,6 +1,9 @@
 package smi
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/lukeod/gosmi/smi/internal"
@@ -21,13 +24,23 @@ func GetNode(smiModulePtr *types.SmiModule, name string) *types.SmiNode {
 		}
 		return objPtr.GetSmiNode()
 	}
-	for modulePtr = internal.GetFirstModule(); modulePtr != nil; modulePtr = modulePtr.Next {
-		objPtr := modulePtr.Objects.GetName(name)
-		if objPtr != nil {
-			return objPtr.GetSmiNode()
-		}
+	objPtr, _ := internal.LookupObject(name)
+	if objPtr == nil {
+		return nil
 	}
-	return nil
+	return objPtr.GetSmiNode()
+}
+
+// ResolveNode looks the name up in all modules like GetNode, but returns the
+// error of the collision policy when several modules define it. There is no
+// libsmi equivalent.
+func ResolveNode(name string) (*types.SmiNode, error) {
+	checkInit()
+	objPtr, err := internal.LookupObject(name)
+	if objPtr == nil || err != nil {
+		return nil, err
+	}
+	return objPtr.GetSmiNode(), nil
 }
 
 // SmiNode *smiGetNodeByOID(unsigned int oidlen, SmiSubid oid[])
@@ -42,10 +55,116 @@ func GetNodeByOID(oid types.Oid) *types.SmiNode {
 	if nodePtr == nil {
 		nodePtr = parentPtr
 	}
-	if nodePtr == nil || nodePtr.FirstObject == nil {
+	if nodePtr == nil {
+		return nil
+	}
+	objPtr := nodePtr.FirstObjectInView()
+	if objPtr == nil {
+		return nil
+	}
+	return objPtr.GetSmiNode()
+}
+
+// ParseOID parses an OID in any of the forms net-snmp accepts as input:
+// numeric (1.3.6.1.2.1.1.1.0), symbolic (iso.org.dod.internet.mgmt.mib-2.
+// system.sysDescr.0), relative to a node (sysDescr.0) or module-qualified
+// (SNMPv2-MIB::sysDescr.0), with an optional leading dot. It returns the
+// deepest node the OID belongs to and the remaining sub-identifiers as the
+// instance. A module-qualified OID yields the node as the module defines it,
+// or an error if the module does not define the deepest node the OID belongs
+// to. There is no libsmi equivalent.
+func ParseOID(s string) (smiNodePtr *types.SmiNode, instance types.Oid, err error) {
+	if internal.Root() == nil {
+		return nil, nil, fmt.Errorf("Not initialized")
+	}
+	path := strings.TrimSpace(s)
+	var smiModulePtr *types.SmiModule
+	if i := strings.Index(path, "::"); i >= 0 {
+		if smiModulePtr = GetModule(path[:i]); smiModulePtr == nil {
+			return nil, nil, fmt.Errorf("Could not find module %s", path[:i])
+		}
+		path = path[i+2:]
+	}
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil, fmt.Errorf("Empty OID %q", s)
+	}
+
+	var oid types.Oid
+	for i, label := range strings.Split(path, ".") {
+		if subId, err := strconv.ParseUint(label, 10, 32); err == nil {
+			oid = append(oid, types.SmiSubId(subId))
+			continue
+		}
+		if label == "" {
+			return nil, nil, fmt.Errorf("Empty sub-identifier in OID %q", s)
+		}
+		if i == 0 {
+			var nodePtr *types.SmiNode
+			if smiModulePtr != nil {
+				nodePtr = GetNode(smiModulePtr, label)
+			} else if nodePtr, err = ResolveNode(label); err != nil {
+				return nil, nil, err
+			}
+			if nodePtr == nil {
+				return nil, nil, fmt.Errorf("Could not find node named %s", label)
+			}
+			oid = append(oid, nodePtr.Oid...)
+			continue
+		}
+		child := findChildByName(internal.FindNodeByOid(len(oid), oid), types.SmiIdentifier(label))
+		if child == nil {
+			return nil, nil, fmt.Errorf("Could not find node named %s below %s", label, oid)
+		}
+		oid = append(oid, child.SubId)
+	}
+
+	var objPtr *internal.Object
+	var objNodePtr *internal.Node
+	var depth int
+	nodePtr := internal.Root()
+	for i := 0; i < len(oid); i++ {
+		if nodePtr = nodePtr.Children.Get(oid[i]); nodePtr == nil {
+			break
+		}
+		if first := nodePtr.FirstObjectInView(); first != nil {
+			objPtr, objNodePtr, depth = first, nodePtr, i+1
+		}
+	}
+	if objPtr == nil {
+		return nil, nil, fmt.Errorf("Could not find node for OID %s", oid)
+	}
+	if modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr)); modulePtr != nil && objPtr.Module != modulePtr {
+		// The node as defined by the module named, which need not be the
+		// first to define it
+		objPtr = nil
+		for obj := objNodePtr.FirstObject; obj != nil; obj = obj.NextSameNode {
+			if obj.Module == modulePtr {
+				objPtr = obj
+				break
+			}
+		}
+		if objPtr == nil {
+			return nil, nil, fmt.Errorf("Module %s does not define node %s", smiModulePtr.Name, oid[:depth])
+		}
+	}
+	return objPtr.GetSmiNode(), append(types.Oid{}, oid[depth:]...), nil
+}
+
+// findChildByName returns the child of nodePtr defined with name by any
+// module in the current view, or nil
+func findChildByName(nodePtr *internal.Node, name types.SmiIdentifier) *internal.Node {
+	if nodePtr == nil {
 		return nil
 	}
-	return nodePtr.FirstObject.GetSmiNode()
+	for child := nodePtr.Children.First; child != nil; child = child.Next {
+		for objPtr := child.FirstObject; objPtr != nil; objPtr = objPtr.NextSameNode {
+			if objPtr.Name == name && internal.InView(objPtr.Module) {
+				return child
+			}
+		}
+	}
+	return nil
 }
 
 // SmiNode *smiGetFirstNode(SmiModule *smiModulePtr, SmiNodekind nodekind)
@@ -221,7 +340,7 @@ func GetNodeType(smiNodePtr *types.SmiNode) *types.SmiType {
 	objPtr := (*internal.Object)(unsafe.Pointer(smiNodePtr))
 	if objPtr.Type == nil {
 		// Try to find the type through the module if available
-		if objPtr.Module != nil && objPtr.Module.Types != nil {
+		if objPtr.Module != nil {
 			// Look for a type with the same name as the node
 			typeObj := objPtr.Module.Types.Get(objPtr.Name)
 			if typeObj != nil {
@@ -241,3 +360,25 @@ func GetNodeLine(smiNodePtr *types.SmiNode) int {
 	objPtr := (*internal.Object)(unsafe.Pointer(smiNodePtr))
 	return objPtr.Line
 }
+
+// GetNodeColumn returns the column the node's definition starts at, or 0 if
+// unknown. There is no libsmi equivalent.
+func GetNodeColumn(smiNodePtr *types.SmiNode) int {
+	if smiNodePtr == nil {
+		return 0
+	}
+	objPtr := (*internal.Object)(unsafe.Pointer(smiNodePtr))
+	return objPtr.Column
+}
+
+// GetNodeText returns the DESCRIPTION and REFERENCE of the node, parsing its
+// module's file again if the module was loaded with lazy texts. There is no
+// libsmi equivalent.
+func GetNodeText(smiNodePtr *types.SmiNode) (description, reference string, err error) {
+	if smiNodePtr == nil {
+		return
+	}
+	objPtr := (*internal.Object)(unsafe.Pointer(smiNodePtr))
+	text, err := objPtr.GetText()
+	return text.Description, text.Reference, err
+}
@@ -46,3 +46,38 @@ func RenderOID(oid types.Oid, flags types.Render) string {
 	}
 	return b.String()
 }
+
+// FormatOID renders oid in the given format, naming it after the deepest
+// node it reaches and appending the remaining sub-identifiers as the
+// instance. OIDs which reach no named node are rendered numerically. There is
+// no libsmi equivalent.
+func FormatOID(oid types.Oid, format types.OidFormat) string {
+	parts := make([]string, len(oid))
+	for i, subId := range oid {
+		parts[i] = strconv.FormatUint(uint64(subId), 10)
+	}
+	if format == types.OidFormatNumeric || internal.Root() == nil {
+		return strings.Join(parts, ".")
+	}
+
+	var objPtr *internal.Object
+	var depth int
+	nodePtr := internal.Root()
+	for i := 0; i < len(oid); i++ {
+		if nodePtr = nodePtr.Children.Get(oid[i]); nodePtr == nil {
+			break
+		}
+		if obj := nodePtr.FirstObject; obj != nil && obj.Name != "" {
+			parts[i] = obj.Name.String()
+			objPtr, depth = obj, i+1
+		}
+	}
+	if objPtr == nil || format == types.OidFormatFull {
+		return strings.Join(parts, ".")
+	}
+	label := strings.Join(parts[depth-1:], ".")
+	if format == types.OidFormatModule && objPtr.Module != nil && !objPtr.Module.IsWellKnown() && objPtr.Module.Name != "" {
+		return objPtr.Module.Name.String() + "::" + label
+	}
+	return label
+}
@@ -22,13 +22,23 @@ func GetType(smiModulePtr *types.SmiModule, typeName string) *types.SmiType {
 		}
 		return &typePtr.SmiType
 	}
-	for modulePtr = internal.GetFirstModule(); modulePtr != nil; modulePtr = modulePtr.Next {
-		typePtr := modulePtr.Types.GetName(typeName)
-		if typePtr != nil {
-			return &typePtr.SmiType
-		}
+	typePtr, _ := internal.LookupType(typeName)
+	if typePtr == nil {
+		return nil
+	}
+	return &typePtr.SmiType
+}
+
+// ResolveType looks the name up in all modules like GetType, but returns the
+// error of the collision policy when several modules define it. There is no
+// libsmi equivalent.
+func ResolveType(typeName string) (*types.SmiType, error) {
+	checkInit()
+	typePtr, err := internal.LookupType(typeName)
+	if typePtr == nil || err != nil {
+		return nil, err
 	}
-	return nil
+	return &typePtr.SmiType, nil
 }
 
 // SmiType *smiGetFirstType(SmiModule *smiModulePtr)
@@ -88,3 +98,36 @@ func GetTypeLine(smiTypePtr *types.SmiType) int {
 	typePtr := (*internal.Type)(unsafe.Pointer(smiTypePtr))
 	return typePtr.Line
 }
+
+// GetTypeColumn returns the column the type's definition starts at, or 0 if
+// unknown. There is no libsmi equivalent.
+func GetTypeColumn(smiTypePtr *types.SmiType) int {
+	if smiTypePtr == nil {
+		return 0
+	}
+	typePtr := (*internal.Type)(unsafe.Pointer(smiTypePtr))
+	return typePtr.Column
+}
+
+// GetTypeTag returns the number of the APPLICATION tag the type is defined
+// with, as in Counter64 ::= [APPLICATION 6] IMPLICIT INTEGER. ok is false for
+// types defined without one. There is no libsmi equivalent.
+func GetTypeTag(smiTypePtr *types.SmiType) (tag int, ok bool) {
+	if smiTypePtr == nil {
+		return 0, false
+	}
+	typePtr := (*internal.Type)(unsafe.Pointer(smiTypePtr))
+	return typePtr.Tag, typePtr.Flags.Has(internal.FlagTagged)
+}
+
+// GetTypeText returns the DESCRIPTION and REFERENCE of the type, parsing its
+// module's file again if the module was loaded with lazy texts. There is no
+// libsmi equivalent.
+func GetTypeText(smiTypePtr *types.SmiType) (description, reference string, err error) {
+	if smiTypePtr == nil {
+		return
+	}
+	typePtr := (*internal.Type)(unsafe.Pointer(smiTypePtr))
+	text, err := typePtr.GetText()
+	return text.Description, text.Reference, err
+}
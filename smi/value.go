@@ -0,0 +1,85 @@
+package smi
+
+import (
+	"unsafe"
+
+	"github.com/lukeod/gosmi/smi/internal"
+	"github.com/lukeod/gosmi/types"
+)
+
+// GetValueAssignment returns the value assignment named name in the module.
+// There is no libsmi equivalent.
+func GetValueAssignment(smiModulePtr *types.SmiModule, name string) *types.SmiValueAssignment {
+	if smiModulePtr == nil || name == "" {
+		return nil
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	valuePtr := modulePtr.Values.GetName(name)
+	if valuePtr == nil {
+		return nil
+	}
+	return &valuePtr.SmiValueAssignment
+}
+
+// GetFirstValueAssignment returns the first value assignment of the module
+// other than those of OIDs, which are nodes. There is no libsmi equivalent.
+func GetFirstValueAssignment(smiModulePtr *types.SmiModule) *types.SmiValueAssignment {
+	if smiModulePtr == nil {
+		return nil
+	}
+	modulePtr := (*internal.Module)(unsafe.Pointer(smiModulePtr))
+	valuePtr := modulePtr.Values.First
+	if valuePtr == nil {
+		return nil
+	}
+	return &valuePtr.SmiValueAssignment
+}
+
+// GetNextValueAssignment returns the value assignment following
+// smiValuePtr in its module. There is no libsmi equivalent.
+func GetNextValueAssignment(smiValuePtr *types.SmiValueAssignment) *types.SmiValueAssignment {
+	if smiValuePtr == nil {
+		return nil
+	}
+	valuePtr := (*internal.Value)(unsafe.Pointer(smiValuePtr))
+	if valuePtr.Next == nil {
+		return nil
+	}
+	return &valuePtr.Next.SmiValueAssignment
+}
+
+// GetValueAssignmentType returns the type of the value, nil if it could not
+// be resolved. There is no libsmi equivalent.
+func GetValueAssignmentType(smiValuePtr *types.SmiValueAssignment) *types.SmiType {
+	if smiValuePtr == nil {
+		return nil
+	}
+	valuePtr := (*internal.Value)(unsafe.Pointer(smiValuePtr))
+	if valuePtr.Type == nil {
+		return nil
+	}
+	return &valuePtr.Type.SmiType
+}
+
+// GetValueAssignmentModule returns the module of the value assignment. There
+// is no libsmi equivalent.
+func GetValueAssignmentModule(smiValuePtr *types.SmiValueAssignment) *types.SmiModule {
+	if smiValuePtr == nil {
+		return nil
+	}
+	valuePtr := (*internal.Value)(unsafe.Pointer(smiValuePtr))
+	if valuePtr.Module == nil {
+		return nil
+	}
+	return &valuePtr.Module.SmiModule
+}
+
+// GetValueAssignmentLine returns the line of the value assignment. There is
+// no libsmi equivalent.
+func GetValueAssignmentLine(smiValuePtr *types.SmiValueAssignment) int {
+	if smiValuePtr == nil {
+		return 0
+	}
+	valuePtr := (*internal.Value)(unsafe.Pointer(smiValuePtr))
+	return valuePtr.Line
+}
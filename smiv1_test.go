@@ -0,0 +1,391 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The SMIv1 base modules, as published in RFC 1155, RFC 1212 and RFC 1215.
+const rfc1155Smi = `RFC1155-SMI DEFINITIONS ::= BEGIN
+
+EXPORTS -- EVERYTHING
+        internet, directory, mgmt,
+        experimental, private, enterprises,
+        OBJECT-TYPE, ObjectName, ObjectSyntax, SimpleSyntax,
+        ApplicationSyntax, NetworkAddress, IpAddress,
+        Counter, Gauge, TimeTicks, Opaque;
+
+ -- the path to the root
+
+ internet      OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 }
+
+ directory     OBJECT IDENTIFIER ::= { internet 1 }
+
+ mgmt          OBJECT IDENTIFIER ::= { internet 2 }
+
+ experimental  OBJECT IDENTIFIER ::= { internet 3 }
+
+ private       OBJECT IDENTIFIER ::= { internet 4 }
+ enterprises   OBJECT IDENTIFIER ::= { private 1 }
+
+ -- definition of object types
+
+ OBJECT-TYPE MACRO ::=
+ BEGIN
+     TYPE NOTATION ::= "SYNTAX" type (TYPE ObjectSyntax)
+                       "ACCESS" Access
+                       "STATUS" Status
+     VALUE NOTATION ::= value (VALUE ObjectName)
+
+     Access ::= "read-only"
+                     | "read-write"
+                     | "write-only"
+                     | "not-accessible"
+     Status ::= "mandatory"
+                     | "optional"
+                     | "obsolete"
+ END
+
+    -- names of objects in the MIB
+
+    ObjectName ::=
+        OBJECT IDENTIFIER
+
+    -- syntax of objects in the MIB
+
+    ObjectSyntax ::=
+        CHOICE {
+            simple
+                SimpleSyntax,
+
+    -- note that simple SEQUENCEs are not directly
+    -- mentioned here to keep things simple (i.e.,
+    -- prevent mis-use).  However, application-wide
+    -- types which are IMPLICITly encoded simple
+    -- SEQUENCEs may appear in the following CHOICE
+
+            application-wide
+                ApplicationSyntax
+        }
+
+       SimpleSyntax ::=
+           CHOICE {
+               number
+                   INTEGER,
+
+               string
+                   OCTET STRING,
+
+               object
+                   OBJECT IDENTIFIER,
+
+               empty
+                   NULL
+           }
+
+       ApplicationSyntax ::=
+           CHOICE {
+               address
+                   NetworkAddress,
+
+               counter
+                   Counter,
+
+               gauge
+                   Gauge,
+
+               ticks
+                   TimeTicks,
+
+               arbitrary
+                   Opaque
+
+       -- other application-wide types, as they are
+       -- defined, will be added here
+           }
+
+       -- application-wide types
+
+       NetworkAddress ::=
+           CHOICE {
+               internet
+                   IpAddress
+           }
+
+       IpAddress ::=
+           [APPLICATION 0]          -- in network-byte order
+               IMPLICIT OCTET STRING (SIZE (4))
+
+       Counter ::=
+           [APPLICATION 1]
+               IMPLICIT INTEGER (0..4294967295)
+
+       Gauge ::=
+           [APPLICATION 2]
+               IMPLICIT INTEGER (0..4294967295)
+
+       TimeTicks ::=
+           [APPLICATION 3]
+               IMPLICIT INTEGER (0..4294967295)
+
+       Opaque ::=
+           [APPLICATION 4]          -- arbitrary ASN.1 value,
+               IMPLICIT OCTET STRING   --   "double-wrapped"
+
+       END
+`
+
+const rfc1212 = `RFC-1212 DEFINITIONS ::= BEGIN
+
+          IMPORTS
+                  ObjectName
+                          FROM RFC1155-SMI
+                  DisplayString
+                          FROM RFC1158-MIB;
+
+          OBJECT-TYPE MACRO ::=
+          BEGIN
+              TYPE NOTATION ::=
+                                          -- must conform to
+                                          -- RFC1155's ObjectSyntax
+                                "SYNTAX" type(ObjectSyntax)
+                                "ACCESS" Access
+                                "STATUS" Status
+                                DescrPart
+                                ReferPart
+                                IndexPart
+                                DefValPart
+              VALUE NOTATION ::= value (VALUE ObjectName)
+
+              Access ::= "read-only"
+                              | "read-write"
+                              | "write-only"
+                              | "not-accessible"
+              Status ::= "mandatory"
+                              | "optional"
+                              | "obsolete"
+                              | "deprecated"
+
+              DescrPart ::=
+                         "DESCRIPTION" value (description DisplayString)
+                              | empty
+
+              ReferPart ::=
+                         "REFERENCE" value (reference DisplayString)
+                              | empty
+
+              IndexPart ::=
+                         "INDEX" "{" IndexTypes "}"
+                              | empty
+              IndexTypes ::=
+                         IndexType | IndexTypes "," IndexType
+              IndexType ::=
+                                  -- if indexobject, use the SYNTAX
+                                  -- value of the correspondent
+                                  -- OBJECT-TYPE invocation
+                         value (indexobject ObjectName)
+                                  -- otherwise use named SMI type
+                                  -- must conform to IndexSyntax below
+                              | type (indextype)
+
+              DefValPart ::=
+                         "DEFVAL" "{" value (defvalue ObjectSyntax) "}"
+                              | empty
+
+          END
+
+          IndexSyntax ::=
+              CHOICE {
+                  number
+                      INTEGER (0..MAX),
+                  string
+                      OCTET STRING,
+                  object
+                      OBJECT IDENTIFIER,
+                  address
+                      NetworkAddress,
+                  ipAddress
+                      IpAddress
+              }
+
+          END
+`
+
+const rfc1215 = `RFC-1215 DEFINITIONS ::= BEGIN
+
+          IMPORTS
+                  ObjectName
+                          FROM RFC1155-SMI;
+
+          TRAP-TYPE MACRO ::=
+          BEGIN
+              TYPE NOTATION ::= "ENTERPRISE" value
+                                    (enterprise OBJECT IDENTIFIER)
+                                VarPart
+                                DescrPart
+                                ReferPart
+              VALUE NOTATION ::= value (VALUE INTEGER)
+
+              VarPart ::=
+                         "VARIABLES" "{" VarTypes "}"
+                              | empty
+              VarTypes ::=
+                         VarType | VarTypes "," VarType
+              VarType ::=
+                         value (vartype ObjectName)
+
+              DescrPart ::=
+                         "DESCRIPTION" value (description DisplayString)
+                              | empty
+
+              ReferPart ::=
+                         "REFERENCE" value (reference DisplayString)
+                              | empty
+
+          END
+
+          END
+`
+
+const smiv1Mib = `SMIV1-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+	enterprises, NetworkAddress, IpAddress, Counter, Gauge, TimeTicks, Opaque
+		FROM RFC1155-SMI
+	OBJECT-TYPE
+		FROM RFC-1212
+	TRAP-TYPE
+		FROM RFC-1215;
+
+v1 OBJECT IDENTIFIER ::= { enterprises 4242 }
+
+v1Address OBJECT-TYPE
+	SYNTAX NetworkAddress
+	ACCESS read-only
+	STATUS mandatory
+	DESCRIPTION "An address."
+	::= { v1 1 }
+
+v1Counter OBJECT-TYPE
+	SYNTAX Counter
+	ACCESS read-only
+	STATUS mandatory
+	::= { v1 2 }
+
+v1Gauge OBJECT-TYPE
+	SYNTAX Gauge
+	ACCESS read-only
+	STATUS mandatory
+	::= { v1 3 }
+
+v1Ticks OBJECT-TYPE
+	SYNTAX TimeTicks
+	ACCESS read-only
+	STATUS mandatory
+	::= { v1 4 }
+
+v1Opaque OBJECT-TYPE
+	SYNTAX Opaque
+	ACCESS read-only
+	STATUS optional
+	::= { v1 5 }
+
+v1Secret OBJECT-TYPE
+	SYNTAX OCTET STRING
+	ACCESS write-only
+	STATUS deprecated
+	::= { v1 6 }
+
+v1Trap TRAP-TYPE
+	ENTERPRISE v1
+	VARIABLES { v1Counter }
+	::= 1
+END
+`
+
+func loadSMIv1(t *testing.T) {
+	t.Helper()
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI": rfc1155Smi,
+		"RFC-1212":    rfc1212,
+		"RFC-1215":    rfc1215,
+		"SMIV1-MIB":   smiv1Mib,
+	})
+	gosmi.Init()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("SMIV1-MIB")
+	require.NoError(t, err)
+}
+
+func TestRFC1155Types(t *testing.T) {
+	loadSMIv1(t)
+	defer gosmi.Exit()
+
+	for _, name := range []string{"RFC1155-SMI", "RFC-1212", "RFC-1215"} {
+		assert.True(t, gosmi.IsLoaded(name), name)
+	}
+	tests := []struct {
+		node     string
+		typ      string
+		baseType types.BaseType
+	}{
+		{"v1Address", "NetworkAddress", types.BaseTypeOctetString},
+		{"v1Counter", "Counter", types.BaseTypeUnsigned32},
+		{"v1Gauge", "Gauge", types.BaseTypeUnsigned32},
+		{"v1Ticks", "TimeTicks", types.BaseTypeUnsigned32},
+		{"v1Opaque", "Opaque", types.BaseTypeOctetString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			node, err := gosmi.GetNode(tt.node)
+			require.NoError(t, err)
+			require.NotNil(t, node.Type)
+			assert.Equal(t, tt.typ, node.Type.Name)
+			assert.Equal(t, tt.baseType, node.Type.BaseType)
+		})
+	}
+
+	// A CHOICE of a single type derives from it
+	address, err := gosmi.GetType("NetworkAddress")
+	require.NoError(t, err)
+	parent, ok := address.GetParent()
+	require.True(t, ok)
+	assert.Equal(t, "IpAddress", parent.Name)
+	syntax, err := gosmi.GetType("ObjectSyntax")
+	require.NoError(t, err)
+	assert.Equal(t, types.BaseTypeUnknown, syntax.BaseType)
+
+	trap, err := gosmi.GetNode("v1Trap")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.4242.0.1", trap.RenderNumeric())
+}
+
+func TestSMIv1AccessStatus(t *testing.T) {
+	loadSMIv1(t)
+	defer gosmi.Exit()
+
+	module, err := gosmi.GetModule("SMIV1-MIB")
+	require.NoError(t, err)
+	assert.Equal(t, types.LanguageSMIv1, module.Language)
+
+	tests := []struct {
+		node   string
+		access types.Access
+		status types.Status
+	}{
+		{"v1Address", types.AccessReadOnly, types.StatusMandatory},
+		{"v1Opaque", types.AccessReadOnly, types.StatusOptional},
+		{"v1Secret", types.AccessWriteOnly, types.StatusDeprecated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.node, func(t *testing.T) {
+			node, err := gosmi.GetNode(tt.node)
+			require.NoError(t, err)
+			assert.Equal(t, tt.access, node.Access)
+			assert.Equal(t, tt.status, node.Status)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package gosmi
+
+import (
+	"strings"
+
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Snapshot is an immutable copy of the nodes of the modules loaded when it
+// was taken. Its lookups take no locks and touch nothing shared with the
+// handle, so any number of goroutines can query a snapshot while modules are
+// loaded and unloaded; they keep seeing the modules as they were.
+//
+// To pick up modules loaded since, take a new snapshot with Update and
+// publish it, with an atomic.Value for instance, to the goroutines querying:
+//
+//	var current atomic.Value // *gosmi.Snapshot
+//	current.Store(gosmi.TakeSnapshot())
+//	// Readers
+//	node, instance, ok := current.Load().(*gosmi.Snapshot).GetNodeByOID(oid)
+//	// Writer, after loading modules
+//	current.Store(current.Load().(*gosmi.Snapshot).Update())
+type Snapshot struct {
+	modules  []*snapshotModule
+	byModule map[string]*snapshotModule
+	// byName and byOid resolve to the first loaded module defining a name or
+	// OID, like the default collision policy
+	byName map[string]*SnapshotNode
+	byOid  map[string]*SnapshotNode
+}
+
+type snapshotModule struct {
+	raw    *types.SmiModule
+	module models.Module
+	nodes  []*SnapshotNode
+	byName map[string]*SnapshotNode
+}
+
+// SnapshotNode is a node of a Snapshot. Unlike SmiNode, it has no methods
+// reaching back into the handle. Use EnumName rather than the Enum of its
+// Type, which locks.
+type SnapshotNode struct {
+	models.Node
+	Module string
+	// enum maps the values of an enumerated type to their names
+	enum map[int64]string
+}
+
+// EnumName returns the name of value if the node has an enumerated or BITS
+// type naming it.
+func (n *SnapshotNode) EnumName(value int64) (string, bool) {
+	name, ok := n.enum[value]
+	return name, ok
+}
+
+// TakeSnapshot copies the loaded modules into a Snapshot. Like any use of the
+// handle, it must not run concurrently with loading modules.
+func TakeSnapshot() *Snapshot {
+	return (*Snapshot)(nil).Update()
+}
+
+// Update takes a new snapshot of the loaded modules, sharing the copies of
+// the modules that are still loaded with s, which is left unchanged. Like
+// TakeSnapshot, it must not run concurrently with loading modules.
+func (s *Snapshot) Update() *Snapshot {
+	next := &Snapshot{
+		byModule: make(map[string]*snapshotModule),
+		byName:   make(map[string]*SnapshotNode),
+		byOid:    make(map[string]*SnapshotNode),
+	}
+	for smiModule := smi.GetFirstModule(); smiModule != nil; smiModule = smi.GetNextModule(smiModule) {
+		var m *snapshotModule
+		if s != nil {
+			// A module reloaded since is another *types.SmiModule
+			if prev := s.byModule[string(smiModule.Name)]; prev != nil && prev.raw == smiModule {
+				m = prev
+			}
+		}
+		if m == nil {
+			m = newSnapshotModule(smiModule)
+		}
+		next.modules = append(next.modules, m)
+		next.byModule[m.module.Name] = m
+		for _, node := range m.nodes {
+			if _, ok := next.byName[node.Name]; !ok {
+				next.byName[node.Name] = node
+			}
+			if key := oidKey(node.Oid); next.byOid[key] == nil {
+				next.byOid[key] = node
+			}
+		}
+	}
+	return next
+}
+
+func newSnapshotModule(smiModule *types.SmiModule) *snapshotModule {
+	module := CreateModule(smiModule)
+	m := &snapshotModule{
+		raw:    smiModule,
+		module: module.Module,
+		byName: make(map[string]*SnapshotNode),
+	}
+	for _, n := range module.GetNodes() {
+		node := &SnapshotNode{Node: n.Node, Module: m.module.Name}
+		// The type is created for the node, but the OID is the handle's
+		node.Oid = append(types.Oid(nil), n.Oid...)
+		if n.Type != nil && n.Type.Enum != nil {
+			node.enum = make(map[int64]string, len(n.Type.Enum.Values))
+			for _, v := range n.Type.Enum.Values {
+				node.enum[v.Value] = v.Name
+			}
+		}
+		m.nodes = append(m.nodes, node)
+		m.byName[node.Name] = node
+	}
+	return m
+}
+
+// oidKey encodes an OID as a map key
+func oidKey(oid types.Oid) string {
+	return string(appendOidKey(make([]byte, 0, 4*len(oid)), oid))
+}
+
+func appendOidKey(b []byte, oid types.Oid) []byte {
+	for _, subId := range oid {
+		b = append(b, byte(subId>>24), byte(subId>>16), byte(subId>>8), byte(subId))
+	}
+	return b
+}
+
+// Modules returns the modules of the snapshot, in load order.
+func (s *Snapshot) Modules() []models.Module {
+	modules := make([]models.Module, len(s.modules))
+	for i, m := range s.modules {
+		modules[i] = m.module
+	}
+	return modules
+}
+
+// GetNode looks up a node by name, optionally qualified by its module as in
+// IF-MIB::ifInOctets. An unqualified name resolves to the first loaded module
+// defining it.
+func (s *Snapshot) GetNode(name string) (*SnapshotNode, bool) {
+	if i := strings.Index(name, "::"); i >= 0 {
+		m := s.byModule[name[:i]]
+		if m == nil {
+			return nil, false
+		}
+		node, ok := m.byName[name[i+2:]]
+		return node, ok
+	}
+	node, ok := s.byName[name]
+	return node, ok
+}
+
+// GetNodeByOID returns the node with the longest OID prefixing oid and the
+// instance sub-identifiers following it, as ParseOID does.
+func (s *Snapshot) GetNodeByOID(oid types.Oid) (node *SnapshotNode, instance types.Oid, ok bool) {
+	key := appendOidKey(make([]byte, 0, 4*len(oid)), oid)
+	for n := len(oid); n > 0; n-- {
+		if node = s.byOid[string(key[:4*n])]; node != nil {
+			return node, oid[n:], true
+		}
+	}
+	return nil, nil, false
+}
+
+// GetModuleNodes returns the nodes of the named module in the order
+// SmiModule.GetNodes returns them.
+func (s *Snapshot) GetModuleNodes(module string) []*SnapshotNode {
+	if m := s.byModule[module]; m != nil {
+		return m.nodes
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package gosmi_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI": rfc1155Smi,
+		"RFC-1212":    rfc1212,
+		"SNAP-MIB": `SNAP-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+	enterprises FROM RFC1155-SMI
+	OBJECT-TYPE FROM RFC-1212;
+
+snap OBJECT IDENTIFIER ::= { enterprises 4343 }
+
+snapState OBJECT-TYPE
+	SYNTAX INTEGER { up(1), down(2) }
+	ACCESS read-only
+	STATUS mandatory
+	::= { snap 1 }
+END
+`,
+		"SNAP-EXT-MIB": `SNAP-EXT-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+	snap FROM SNAP-MIB;
+
+snapExt OBJECT IDENTIFIER ::= { snap 2 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("SNAP-MIB")
+	require.NoError(t, err)
+
+	snapshot := gosmi.TakeSnapshot()
+	var names []string
+	for _, m := range snapshot.Modules() {
+		names = append(names, m.Name)
+	}
+	assert.Contains(t, names, "SNAP-MIB")
+	assert.NotContains(t, names, "SNAP-EXT-MIB")
+
+	node, instance, ok := snapshot.GetNodeByOID(types.Oid{1, 3, 6, 1, 4, 1, 4343, 1, 0})
+	require.True(t, ok)
+	assert.Equal(t, "snapState", node.Name)
+	assert.Equal(t, "SNAP-MIB", node.Module)
+	assert.Equal(t, types.Oid{0}, instance)
+	name, ok := node.EnumName(2)
+	assert.True(t, ok)
+	assert.Equal(t, "down", name)
+	_, ok = node.EnumName(3)
+	assert.False(t, ok)
+
+	qualified, ok := snapshot.GetNode("SNAP-MIB::snapState")
+	require.True(t, ok)
+	assert.Same(t, node, qualified)
+	_, ok = snapshot.GetNode("OTHER-MIB::snapState")
+	assert.False(t, ok)
+	_, _, ok = snapshot.GetNodeByOID(types.Oid{7, 999})
+	assert.False(t, ok)
+
+	// Loading modules leaves the snapshot as it was, while it is queried
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				n, _, ok := snapshot.GetNodeByOID(types.Oid{1, 3, 6, 1, 4, 1, 4343, 2})
+				if assert.True(t, ok) {
+					assert.Equal(t, "snap", n.Name)
+				}
+			}
+		}()
+	}
+	_, err = gosmi.LoadModule("SNAP-EXT-MIB")
+	require.NoError(t, err)
+	wg.Wait()
+
+	updated := snapshot.Update()
+	node, _, ok = updated.GetNodeByOID(types.Oid{1, 3, 6, 1, 4, 1, 4343, 2})
+	require.True(t, ok)
+	assert.Equal(t, "snapExt", node.Name)
+	assert.Len(t, updated.GetModuleNodes("SNAP-EXT-MIB"), 1)
+	// Unchanged modules are shared
+	state, ok := updated.GetNode("snapState")
+	require.True(t, ok)
+	assert.Same(t, qualified, state)
+
+	_, err = gosmi.UnloadModule("SNAP-EXT-MIB")
+	require.NoError(t, err)
+	assert.Nil(t, updated.Update().GetModuleNodes("SNAP-EXT-MIB"))
+	assert.Len(t, updated.GetModuleNodes("SNAP-EXT-MIB"), 1)
+}
@@ -0,0 +1,31 @@
+package gosmi
+
+import (
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/types"
+)
+
+// Source is where a node or type is defined: the module and the position of
+// the start of the defining clause in the module's file. Pos.Line is zero for
+// built-in definitions and for nodes that are referenced but never defined.
+type Source struct {
+	Module string
+	Pos    diag.Position
+}
+
+func (s Source) String() string {
+	if s.Pos.Filename == "" {
+		return s.Module
+	}
+	return s.Module + " (" + s.Pos.String() + ")"
+}
+
+func newSource(module *types.SmiModule, line, column int) Source {
+	if module == nil {
+		return Source{}
+	}
+	return Source{
+		Module: module.Name.String(),
+		Pos:    diag.Position{Filename: module.Path, Line: line, Column: column},
+	}
+}
@@ -0,0 +1,88 @@
+package gosmi
+
+import (
+	"github.com/lukeod/gosmi/smi"
+	"github.com/lukeod/gosmi/types"
+)
+
+// ModuleStats summarizes the definitions of a module, for inventorying the
+// MIBs in use.
+type ModuleStats struct {
+	Module   string         `json:"module"`
+	Language types.Language `json:"language"`
+	// Nodes counts the nodes of the module by kind name, such as Scalar,
+	// Table, Row or Column.
+	Nodes map[string]int `json:"nodes"`
+	Types int            `json:"types"`
+	// DescriptionBytes is the length of the DESCRIPTION clauses of the
+	// module, its nodes and types. Texts of modules loaded with lazy texts
+	// are not counted.
+	DescriptionBytes int `json:"descriptionBytes"`
+	// Depth is the length of the longest OID the module defines.
+	Depth   int `json:"depth"`
+	Imports int `json:"imports"`
+	// Unresolved counts the imports from modules that are not loaded, are
+	// stubbed or do not define the name.
+	Unresolved int `json:"unresolved"`
+}
+
+// Stats returns the statistics of the module.
+func (m SmiModule) Stats() ModuleStats {
+	stats := ModuleStats{
+		Module:           m.Name,
+		Language:         m.Language,
+		Nodes:            make(map[string]int),
+		DescriptionBytes: len(m.Description),
+	}
+	for _, node := range m.GetNodes() {
+		stats.Nodes[node.Kind.String()]++
+		stats.DescriptionBytes += len(node.Description)
+		if node.OidLen > stats.Depth {
+			stats.Depth = node.OidLen
+		}
+	}
+	for _, t := range m.GetTypes() {
+		stats.Types++
+		stats.DescriptionBytes += len(t.Description)
+	}
+	for smiImport := smi.GetFirstImport(m.smiModule); smiImport != nil; smiImport = smi.GetNextImport(smiImport) {
+		stats.Imports++
+		if !smi.IsImportResolved(smiImport) {
+			stats.Unresolved++
+		}
+	}
+	return stats
+}
+
+// GetStats returns the statistics of every loaded module, in load order.
+func GetStats() (stats []ModuleStats) {
+	for _, module := range GetLoadedModules() {
+		stats = append(stats, module.Stats())
+	}
+	return
+}
+
+// TotalStats adds up the statistics of several modules. The depth of the
+// total is the deepest of them and its language is unknown unless they all
+// share one.
+func TotalStats(stats []ModuleStats) ModuleStats {
+	total := ModuleStats{Nodes: make(map[string]int)}
+	for i, s := range stats {
+		if i == 0 {
+			total.Language = s.Language
+		} else if s.Language != total.Language {
+			total.Language = types.LanguageUnknown
+		}
+		for kind, n := range s.Nodes {
+			total.Nodes[kind] += n
+		}
+		total.Types += s.Types
+		total.DescriptionBytes += s.DescriptionBytes
+		if s.Depth > total.Depth {
+			total.Depth = s.Depth
+		}
+		total.Imports += s.Imports
+		total.Unresolved += s.Unresolved
+	}
+	return total
+}
@@ -0,0 +1,89 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleStats(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI": rfc1155Smi,
+		"RFC-1212":    rfc1212,
+		"STATS-MIB": `STATS-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+	enterprises, Counter FROM RFC1155-SMI
+	OBJECT-TYPE FROM RFC-1212
+	Missing FROM MISSING-MIB;
+
+Name ::= OCTET STRING
+
+stats OBJECT IDENTIFIER ::= { enterprises 4242 }
+
+statsTable OBJECT-TYPE
+	SYNTAX SEQUENCE OF StatsEntry
+	ACCESS not-accessible
+	STATUS mandatory
+	DESCRIPTION "A table."
+	::= { stats 1 }
+
+statsEntry OBJECT-TYPE
+	SYNTAX StatsEntry
+	ACCESS not-accessible
+	STATUS mandatory
+	INDEX { statsName }
+	::= { statsTable 1 }
+
+StatsEntry ::= SEQUENCE { statsName Name, statsCount Counter }
+
+statsName OBJECT-TYPE
+	SYNTAX Name
+	ACCESS read-only
+	STATUS mandatory
+	::= { statsEntry 1 }
+
+statsCount OBJECT-TYPE
+	SYNTAX Counter
+	ACCESS read-only
+	STATUS mandatory
+	::= { statsEntry 2 }
+
+statsTotal OBJECT-TYPE
+	SYNTAX Counter
+	ACCESS read-only
+	STATUS mandatory
+	DESCRIPTION "Total."
+	::= { stats 2 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("STATS-MIB")
+	require.NoError(t, err)
+	module, err := gosmi.GetModule("STATS-MIB")
+	require.NoError(t, err)
+
+	stats := module.Stats()
+	assert.Equal(t, "STATS-MIB", stats.Module)
+	assert.Equal(t, types.LanguageSMIv1, stats.Language)
+	assert.Equal(t, map[string]int{"Node": 1, "Table": 1, "Row": 1, "Column": 2, "Scalar": 1}, stats.Nodes)
+	assert.Equal(t, 1, stats.Types)
+	assert.Equal(t, len("A table.")+len("Total."), stats.DescriptionBytes)
+	assert.Equal(t, 10, stats.Depth)
+	assert.Equal(t, 4, stats.Imports)
+	assert.Equal(t, 1, stats.Unresolved)
+
+	all := gosmi.GetStats()
+	require.Len(t, all, 4)
+	total := gosmi.TotalStats(all)
+	// RFC-1212 imports DisplayString from RFC1158-MIB, not provided
+	assert.Equal(t, 2, total.Unresolved)
+	assert.Equal(t, 10, total.Depth)
+	assert.Equal(t, 2, total.Nodes["Column"])
+	assert.Equal(t, types.LanguageUnknown, total.Language)
+}
@@ -1,6 +1,9 @@
 package gosmi
 
 import (
+	"fmt"
+
+	"github.com/lukeod/gosmi/models"
 	"github.com/lukeod/gosmi/smi"
 	"github.com/lukeod/gosmi/types"
 )
@@ -9,8 +12,11 @@ type Table struct {
 	SmiNode
 	Columns     map[string]SmiNode
 	ColumnOrder []string
+	Create      bool
 	Implied     bool
 	Index       []SmiNode
+	RowStatus   SmiNode
+	StorageType SmiNode
 }
 
 func (t SmiNode) AsTable() Table {
@@ -19,8 +25,11 @@ func (t SmiNode) AsTable() Table {
 		SmiNode:     t,
 		Columns:     columns,
 		ColumnOrder: columnOrder,
+		Create:      t.GetCreate(),
 		Implied:     t.GetImplied(),
 		Index:       t.GetIndex(),
+		RowStatus:   t.GetRowStatus(),
+		StorageType: t.GetStorageType(),
 	}
 }
 
@@ -141,3 +150,205 @@ func (t SmiNode) GetIndex() (index []SmiNode) {
 	}
 	return
 }
+
+// BuildIndex encodes index values as the instance OID suffix of a row of the
+// table, or of the row, t, which appended to the OID of a column identifies
+// its instance in the row. The values are those of the columns of the index
+// in order, of the types models.Type.IndexValue accepts. Fewer values than
+// index columns give the prefix of the instances of several rows.
+func (t SmiNode) BuildIndex(values ...interface{}) (types.Oid, error) {
+	table := t.AsTableModel()
+	if table.Name == "" {
+		return nil, fmt.Errorf("%s is not a table or row", t.Name)
+	}
+	index := table.Index()
+	if len(index) == 0 {
+		return nil, fmt.Errorf("%s has no index", table.Row.Name)
+	}
+	if len(values) > len(index) {
+		return nil, fmt.Errorf("%d index values given for the %d columns of the index of %s", len(values), len(index), table.Row.Name)
+	}
+	// Unlike models.TableNode.BuildIndex, a types.Oid value is that of an
+	// OBJECT IDENTIFIER column rather than the whole suffix
+	var suffix types.Oid
+	for i, value := range values {
+		oid, err := index[i].Type.IndexValue(value, table.Implied() && i == len(index)-1)
+		if err != nil {
+			return nil, fmt.Errorf("Index column %s: %w", index[i].Name, err)
+		}
+		suffix = append(suffix, oid...)
+	}
+	return suffix, nil
+}
+
+// GetCreate reports whether rows of the table can be created by management
+// operations, which is the case when the row has read-create columns or a
+// RowStatus column.
+func (t SmiNode) GetCreate() bool {
+	row := t.getRow()
+	if row == nil {
+		return false
+	}
+
+	for smiColumn := smi.GetFirstChildNode(row); smiColumn != nil; smiColumn = smi.GetNextChildNode(smiColumn) {
+		if smiColumn.Create {
+			return true
+		}
+	}
+	return t.GetRowStatus().smiNode != nil
+}
+
+// GetRowStatus returns the column of the row with a RowStatus syntax, which
+// controls row creation and deletion. The zero SmiNode is returned if there
+// is none.
+func (t SmiNode) GetRowStatus() (column SmiNode) {
+	return t.getColumnOfType("RowStatus")
+}
+
+// GetStorageType returns the column of the row with a StorageType syntax,
+// which controls whether the row is persisted. The zero SmiNode is returned
+// if there is none.
+func (t SmiNode) GetStorageType() (column SmiNode) {
+	return t.getColumnOfType("StorageType")
+}
+
+func (t SmiNode) getColumnOfType(typeName string) (column SmiNode) {
+	row := t.getRow()
+	if row == nil {
+		return
+	}
+
+	for smiColumn := smi.GetFirstChildNode(row); smiColumn != nil; smiColumn = smi.GetNextChildNode(smiColumn) {
+		if smiColumn.NodeKind != types.NodeColumn {
+			continue
+		}
+		node := CreateNode(smiColumn)
+		if node.SmiType == nil {
+			continue
+		}
+		// Also match types derived from the textual convention
+		for _, smiType := range node.SmiType.BaseTypeChain() {
+			if smiType.Name == typeName {
+				return node
+			}
+		}
+	}
+	return
+}
+
+// AsTableModel returns the models.Table view of the table, or of the table of
+// the row, t. The zero models.Table is returned for other nodes.
+func (t SmiNode) AsTableModel() (table models.Table) {
+	smiRow := t.getRow()
+	if smiRow == nil {
+		return
+	}
+	smiTable := smi.GetParentNode(smiRow)
+	if smiTable == nil {
+		return
+	}
+
+	row := CreateNode(smiRow)
+	columns, columnOrder := row.GetColumns()
+	table.BaseNode = CreateNode(smiTable).baseNode()
+	table.Row = row.rowNode(columns, columnOrder)
+
+	indexNames := make(map[string]bool, len(table.Row.Index))
+	for _, index := range table.Row.Index {
+		indexNames[index.Name] = true
+	}
+	for _, column := range table.Row.Columns {
+		if !indexNames[column.Name] {
+			table.DataColumns = append(table.DataColumns, column)
+		}
+	}
+
+	if smiRow.IndexKind == types.IndexIndex {
+		for _, index := range table.Row.Index {
+			if _, ok := columns[index.Name]; !ok {
+				table.ExternalIndex = true
+				break
+			}
+		}
+	}
+
+	seen := map[string]bool{row.Name: true}
+	for augmented := row.GetAugment(); augmented.smiNode != nil && !seen[augmented.Name]; augmented = augmented.GetAugment() {
+		seen[augmented.Name] = true
+		table.Augments = append(table.Augments, augmented.rowNode(augmented.GetColumns()))
+	}
+
+	table.IndexKind = types.IndexIndex
+	if smiRow.IndexKind == types.IndexAugment {
+		table.IndexKind = types.IndexAugment
+		if len(table.Augments) > 0 {
+			table.Related = &table.Augments[0]
+		}
+	} else if table.ExternalIndex {
+		table.IndexKind, table.Related = row.indexRelation()
+	}
+	return
+}
+
+// indexRelation finds the row of another table whose index the index of the
+// row, t, starts with. The row is that of the first index column, and the
+// relation is IndexSparse if the indices are the same, IndexExpand if the
+// index of t has more columns.
+func (t SmiNode) indexRelation() (types.IndexKind, *models.RowNode) {
+	index := t.GetIndex()
+	if len(index) == 0 {
+		return types.IndexIndex, nil
+	}
+	smiRow := smi.GetParentNode(index[0].smiNode)
+	if smiRow == nil || smiRow.NodeKind != types.NodeRow || smiRow == t.smiNode {
+		return types.IndexIndex, nil
+	}
+	other := CreateNode(smiRow)
+	otherIndex := other.GetIndex()
+	if len(otherIndex) == 0 || len(otherIndex) > len(index) {
+		return types.IndexIndex, nil
+	}
+	for i, column := range otherIndex {
+		if column.smiNode != index[i].smiNode {
+			return types.IndexIndex, nil
+		}
+	}
+	related := other.rowNode(other.GetColumns())
+	if len(otherIndex) == len(index) {
+		return types.IndexSparse, &related
+	}
+	return types.IndexExpand, &related
+}
+
+func (t SmiNode) rowNode(columns map[string]SmiNode, columnOrder []string) (row models.RowNode) {
+	row.BaseNode = t.baseNode()
+	row.Implied = t.GetImplied()
+	if augmented := t.GetAugment(); augmented.smiNode != nil {
+		// The index, and so whether it is IMPLIED, is the augmented row's
+		row.Implied = augmented.GetImplied()
+	}
+	for _, name := range columnOrder {
+		row.Columns = append(row.Columns, columns[name].columnNode())
+	}
+	for _, index := range t.GetIndex() {
+		row.Index = append(row.Index, index.columnNode())
+	}
+	return
+}
+
+func (n SmiNode) columnNode() (column models.ColumnNode) {
+	column.BaseNode = n.baseNode()
+	if n.Type != nil {
+		column.Type = *n.Type
+	}
+	return
+}
+
+func (n SmiNode) baseNode() models.BaseNode {
+	return models.BaseNode{
+		Name:         n.Name,
+		Oid:          n.Oid,
+		OidFormatted: n.Oid.String(),
+		OidLen:       uint(n.OidLen),
+	}
+}
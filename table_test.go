@@ -0,0 +1,443 @@
+package gosmi_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tableMib = `TABLE-MIB DEFINITIONS ::= BEGIN
+RowStatus ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "Row status."
+    SYNTAX INTEGER { active(1), notInService(2), notReady(3), createAndGo(4), createAndWait(5), destroy(6) }
+
+StorageType ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "Storage type."
+    SYNTAX INTEGER { other(1), volatile(2), nonVolatile(3), permanent(4), readOnly(5) }
+
+EntryStorage ::= StorageType
+
+tableRoot OBJECT IDENTIFIER ::= { iso 2 }
+
+createTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF CreateEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A table with row creation."
+    ::= { tableRoot 1 }
+
+createEntry OBJECT-TYPE
+    SYNTAX CreateEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { createIndex }
+    ::= { createTable 1 }
+
+CreateEntry ::= SEQUENCE {
+    createIndex   INTEGER,
+    createStorage EntryStorage,
+    createStatus  RowStatus
+}
+
+createIndex OBJECT-TYPE
+    SYNTAX INTEGER (1..100)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The index."
+    ::= { createEntry 1 }
+
+createStorage OBJECT-TYPE
+    SYNTAX EntryStorage
+    MAX-ACCESS read-create
+    STATUS current
+    DESCRIPTION "The storage type."
+    ::= { createEntry 2 }
+
+createStatus OBJECT-TYPE
+    SYNTAX RowStatus
+    MAX-ACCESS read-create
+    STATUS current
+    DESCRIPTION "The row status."
+    ::= { createEntry 3 }
+
+staticTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF StaticEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A read-only table."
+    ::= { tableRoot 2 }
+
+staticEntry OBJECT-TYPE
+    SYNTAX StaticEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { staticIndex }
+    ::= { staticTable 1 }
+
+StaticEntry ::= SEQUENCE {
+    staticIndex INTEGER,
+    staticValue INTEGER
+}
+
+staticIndex OBJECT-TYPE
+    SYNTAX INTEGER (1..100)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "The index."
+    ::= { staticEntry 1 }
+
+staticValue OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A value."
+    ::= { staticEntry 2 }
+
+augTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF AugEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmentation of the static table."
+    ::= { tableRoot 3 }
+
+augEntry OBJECT-TYPE
+    SYNTAX AugEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    AUGMENTS { staticEntry }
+    ::= { augTable 1 }
+
+AugEntry ::= SEQUENCE {
+    augValue INTEGER
+}
+
+augValue OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "An augmenting value."
+    ::= { augEntry 1 }
+
+sparseTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF SparseEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A sparse augmentation of the static table."
+    ::= { tableRoot 4 }
+
+sparseEntry OBJECT-TYPE
+    SYNTAX SparseEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { staticIndex }
+    ::= { sparseTable 1 }
+
+SparseEntry ::= SEQUENCE {
+    sparseValue INTEGER
+}
+
+sparseValue OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A sparse value."
+    ::= { sparseEntry 1 }
+END
+`
+
+func TestTableRowCreation(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TABLE-MIB": tableMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TABLE-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("createTable")
+	require.NoError(t, err)
+	table := node.AsTable()
+	assert.True(t, table.Create)
+	assert.Equal(t, "createStatus", table.RowStatus.Name)
+	assert.Equal(t, "createStorage", table.StorageType.Name)
+
+	row, err := gosmi.GetNode("createEntry")
+	require.NoError(t, err)
+	assert.True(t, row.GetCreate())
+	assert.Equal(t, "createStatus", row.GetRowStatus().Name)
+
+	node, err = gosmi.GetNode("staticTable")
+	require.NoError(t, err)
+	table = node.AsTable()
+	assert.False(t, table.Create)
+	assert.Empty(t, table.RowStatus.Name)
+	assert.Empty(t, table.StorageType.Name)
+}
+
+func TestTableModel(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TABLE-MIB": tableMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TABLE-MIB")
+	require.NoError(t, err)
+
+	columnNames := func(columns []models.ColumnNode) (names []string) {
+		for _, c := range columns {
+			names = append(names, c.Name)
+		}
+		return
+	}
+
+	node, err := gosmi.GetNode("createEntry")
+	require.NoError(t, err)
+	table := node.AsTableModel()
+	assert.Equal(t, "createTable", table.Name)
+	assert.Equal(t, "1.2.1", table.OidFormatted)
+	assert.Equal(t, "createEntry", table.Row.Name)
+	assert.Equal(t, []string{"createIndex", "createStorage", "createStatus"}, columnNames(table.Row.Columns))
+	assert.Equal(t, []string{"createIndex"}, columnNames(table.Index()))
+	assert.Equal(t, types.BaseTypeUnsigned32, table.Index()[0].Type.BaseType)
+	assert.Equal(t, []string{"createStorage", "createStatus"}, columnNames(table.DataColumns))
+	assert.False(t, table.Implied())
+	assert.Empty(t, table.Augments)
+	assert.False(t, table.ExternalIndex)
+	assert.Equal(t, types.IndexIndex, table.IndexKind)
+	assert.Nil(t, table.Related)
+
+	node, err = gosmi.GetNode("augTable")
+	require.NoError(t, err)
+	table = node.AsTableModel()
+	assert.Equal(t, []string{"staticIndex"}, columnNames(table.Index()))
+	assert.Equal(t, []string{"augValue"}, columnNames(table.DataColumns))
+	require.Len(t, table.Augments, 1)
+	assert.Equal(t, "staticEntry", table.Augments[0].Name)
+	assert.Equal(t, []string{"staticIndex", "staticValue"}, columnNames(table.Augments[0].Columns))
+	assert.False(t, table.ExternalIndex)
+	assert.Equal(t, types.IndexAugment, table.IndexKind)
+	require.NotNil(t, table.Related)
+	assert.Equal(t, "staticEntry", table.Related.Name)
+
+	node, err = gosmi.GetNode("sparseTable")
+	require.NoError(t, err)
+	table = node.AsTableModel()
+	assert.Equal(t, []string{"staticIndex"}, columnNames(table.Index()))
+	assert.Equal(t, []string{"sparseValue"}, columnNames(table.DataColumns))
+	assert.True(t, table.ExternalIndex)
+	assert.Equal(t, types.IndexSparse, table.IndexKind)
+	require.NotNil(t, table.Related)
+	assert.Equal(t, "staticEntry", table.Related.Name)
+	assert.Empty(t, table.Augments)
+
+	node, err = gosmi.GetNode("staticValue")
+	require.NoError(t, err)
+	assert.Empty(t, node.AsTableModel().Name)
+}
+
+func TestTableModelForeignRows(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"TABLE-MIB": tableMib,
+		// extAugEntry augments a row of TABLE-MIB without importing it
+		"TABLE-EXT-MIB": `TABLE-EXT-MIB DEFINITIONS ::= BEGIN
+IMPORTS staticIndex FROM TABLE-MIB;
+
+extRoot OBJECT IDENTIFIER ::= { iso 3 }
+
+extAugTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF ExtAugEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An augmentation of a foreign table."
+    ::= { extRoot 1 }
+
+extAugEntry OBJECT-TYPE
+    SYNTAX ExtAugEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    AUGMENTS { staticEntry }
+    ::= { extAugTable 1 }
+
+ExtAugEntry ::= SEQUENCE {
+    extAugValue INTEGER
+}
+
+extAugValue OBJECT-TYPE
+    SYNTAX INTEGER
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A value."
+    ::= { extAugEntry 1 }
+
+extExpandTable OBJECT-TYPE
+    SYNTAX SEQUENCE OF ExtExpandEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An expansion of a foreign table."
+    ::= { extRoot 2 }
+
+extExpandEntry OBJECT-TYPE
+    SYNTAX ExtExpandEntry
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "A row."
+    INDEX { staticIndex, extExpandIndex }
+    ::= { extExpandTable 1 }
+
+ExtExpandEntry ::= SEQUENCE {
+    extExpandIndex INTEGER
+}
+
+extExpandIndex OBJECT-TYPE
+    SYNTAX INTEGER (1..10)
+    MAX-ACCESS not-accessible
+    STATUS current
+    DESCRIPTION "An index."
+    ::= { extExpandEntry 1 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TABLE-MIB")
+	require.NoError(t, err)
+	_, err = gosmi.LoadModule("TABLE-EXT-MIB")
+	require.NoError(t, err)
+
+	node, err := gosmi.GetNode("extAugTable")
+	require.NoError(t, err)
+	table := node.AsTableModel()
+	assert.Equal(t, types.IndexAugment, table.IndexKind)
+	require.NotNil(t, table.Related)
+	assert.Equal(t, "staticEntry", table.Related.Name)
+	require.Len(t, table.Index(), 1)
+	assert.Equal(t, "staticIndex", table.Index()[0].Name)
+
+	node, err = gosmi.GetNode("extExpandTable")
+	require.NoError(t, err)
+	table = node.AsTableModel()
+	assert.True(t, table.ExternalIndex)
+	assert.Equal(t, types.IndexExpand, table.IndexKind)
+	require.NotNil(t, table.Related)
+	assert.Equal(t, "staticEntry", table.Related.Name)
+}
+
+func TestBuildIndex(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI": rfc1155Smi,
+		"RFC-1212":    rfc1212,
+		"IDX-MIB": `IDX-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+	enterprises, IpAddress, NetworkAddress FROM RFC1155-SMI
+	OBJECT-TYPE FROM RFC-1212;
+
+idx OBJECT IDENTIFIER ::= { enterprises 4444 }
+
+idxTable OBJECT-TYPE
+	SYNTAX SEQUENCE OF IdxEntry
+	ACCESS not-accessible
+	STATUS mandatory
+	::= { idx 1 }
+
+idxEntry OBJECT-TYPE
+	SYNTAX IdxEntry
+	ACCESS not-accessible
+	STATUS mandatory
+	INDEX { idxNumber, idxAddress, idxNet, idxMac, idxName, IMPLIED idxOid }
+	::= { idxTable 1 }
+
+IdxEntry ::= SEQUENCE {
+	idxNumber INTEGER, idxAddress IpAddress, idxNet NetworkAddress,
+	idxMac OCTET STRING, idxName OCTET STRING, idxOid OBJECT IDENTIFIER
+}
+
+idxNumber OBJECT-TYPE
+	SYNTAX INTEGER (1..10)
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 1 }
+
+idxAddress OBJECT-TYPE
+	SYNTAX IpAddress
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 2 }
+
+idxNet OBJECT-TYPE
+	SYNTAX NetworkAddress
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 3 }
+
+idxMac OBJECT-TYPE
+	SYNTAX OCTET STRING (SIZE (6))
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 4 }
+
+idxName OBJECT-TYPE
+	SYNTAX OCTET STRING (SIZE (0..8))
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 5 }
+
+idxOid OBJECT-TYPE
+	SYNTAX OBJECT IDENTIFIER
+	ACCESS read-only
+	STATUS mandatory
+	::= { idxEntry 6 }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("IDX-MIB")
+	require.NoError(t, err)
+
+	row, err := gosmi.GetNode("idxEntry")
+	require.NoError(t, err)
+	suffix, err := row.BuildIndex(3, net.IPv4(192, 0, 2, 1), "10.0.0.1", []byte{0, 1, 2, 3, 4, 5}, "ab", types.Oid{1, 3, 6})
+	require.NoError(t, err)
+	assert.Equal(t, types.Oid{
+		3,
+		192, 0, 2, 1,
+		1, 10, 0, 0, 1,
+		0, 1, 2, 3, 4, 5,
+		2, 'a', 'b',
+		1, 3, 6,
+	}, suffix)
+
+	table, err := gosmi.GetNode("idxTable")
+	require.NoError(t, err)
+	prefix, err := table.BuildIndex(3, "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, types.Oid{3, 192, 0, 2, 1}, prefix)
+
+	for _, values := range [][]interface{}{
+		{-1},
+		{1, "192.0.2"},
+		{1, "192.0.2.1", "10.0.0.1", "short"},
+		{1, "192.0.2.1", "10.0.0.1", "sixsix", "too long name"},
+		{1, "192.0.2.1", "10.0.0.1", "sixsix", "", "1.3", 7},
+	} {
+		_, err = row.BuildIndex(values...)
+		assert.Error(t, err, "%v", values)
+	}
+
+	column, err := gosmi.GetNode("idxName")
+	require.NoError(t, err)
+	_, err = column.BuildIndex()
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package gosmi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is what export templates are executed with. Templates reach
+// the resolved model through the methods of the modules, such as
+// {{range .Modules}}{{range .GetNodes}}{{.Name}} {{.RenderNumeric}}{{end}}{{end}},
+// and AsTable or GetNotificationObjects on nodes.
+type TemplateData struct {
+	// Modules are the loaded modules, in load order, including the
+	// well-known one.
+	Modules []SmiModule
+	Traps   []TrapDefinition
+}
+
+// TemplateFuncs returns the functions available to export templates besides
+// the text/template builtins: join, lower, upper, trim, replace, firstLine
+// and module, which looks a loaded module up by name.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":      strings.Join,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"replace":   strings.ReplaceAll,
+		"firstLine": firstLine,
+		"module":    GetModule,
+	}
+}
+
+// ParseTemplate parses an export template with TemplateFuncs.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs()).Parse(text)
+}
+
+// ExportTemplate executes tmpl, parsed with TemplateFuncs, with the loaded
+// modules.
+func ExportTemplate(w io.Writer, tmpl *template.Template) error {
+	data := TemplateData{
+		Modules: GetLoadedModules(),
+		Traps:   GetTrapCatalog(),
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("Execute template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTemplate(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TRAP-MIB": trapMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TRAP-MIB")
+	require.NoError(t, err)
+
+	tmpl, err := gosmi.ParseTemplate("test", `{{with module "TRAP-MIB"}}{{range .GetNodes}}{{.Name}} {{.RenderNumeric}} {{lower .Kind.String}}
+{{end}}{{end}}{{range .Traps}}{{.Name}}: {{join .Objects ","}}
+{{end}}`)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gosmi.ExportTemplate(&buf, tmpl))
+	assert.Equal(t, `trapRoot 1.1 node
+trapRoot# 1.1.0 node
+trapLegacy 1.1.0.3 notification
+trapStatus 1.1.1 scalar
+trapNotifications 1.1.2 node
+trapStatusChange 1.1.2.1 notification
+trapLegacy: trapStatus
+trapStatusChange: trapStatus
+`, buf.String())
+
+	tmpl, err = gosmi.ParseTemplate("missing", `{{module "MISSING-MIB"}}`)
+	require.NoError(t, err)
+	assert.Error(t, gosmi.ExportTemplate(&buf, tmpl))
+}
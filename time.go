@@ -0,0 +1,107 @@
+package gosmi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lukeod/gosmi/models"
+)
+
+// IsDateAndTime reports whether the values of the node are laid out as a
+// DateAndTime: its type is, or is derived from, DateAndTime, or it is another
+// convention with the DISPLAY-HINT of DateAndTime.
+func (n SmiNode) IsDateAndTime() bool {
+	if n.SmiType == nil {
+		return false
+	}
+	for _, t := range n.SmiType.BaseTypeChain() {
+		if t.Name == "DateAndTime" || t.Format == models.DateAndTimeHint {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTimeStamp reports whether the type of the node is, or is derived from,
+// TimeStamp, whose values are those of sysUpTime at some event.
+func (n SmiNode) IsTimeStamp() bool {
+	return n.hasType("TimeStamp")
+}
+
+// DecodeTime converts a value of the node to the time it stands for. A
+// DateAndTime value is an OCTET STRING, decoded by models.DecodeDateAndTime.
+// A TimeStamp value is converted by models.TimeStampTime, given the value
+// sysUpTime had at now; the zero time is returned for a zero TimeStamp.
+func (n SmiNode) DecodeTime(value interface{}, sysUpTime uint32, now time.Time) (time.Time, error) {
+	switch {
+	case n.IsDateAndTime():
+		b, ok := value.([]byte)
+		if !ok {
+			return time.Time{}, fmt.Errorf("DateAndTime value of %s is a %T, not []byte", n.Name, value)
+		}
+		return models.DecodeDateAndTime(b)
+	case n.IsTimeStamp():
+		ticks, ok := toUint32(value)
+		if !ok {
+			return time.Time{}, fmt.Errorf("Invalid TimeStamp value %v of %s", value, n.Name)
+		}
+		t, _ := models.TimeStampTime(ticks, sysUpTime, now)
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s is neither a DateAndTime nor a TimeStamp", n.Name)
+}
+
+// EncodeTime converts t to a value of the node: the bytes of a DateAndTime, or
+// for a TimeStamp, the value sysUpTime had at t given it had sysUpTime at now.
+// A time before sysUpTime started counting is encoded as zero.
+func (n SmiNode) EncodeTime(t time.Time, sysUpTime uint32, now time.Time) (interface{}, error) {
+	switch {
+	case n.IsDateAndTime():
+		return models.EncodeDateAndTime(t), nil
+	case n.IsTimeStamp():
+		ago := models.DurationToTicks(now.Sub(t))
+		if ago < 0 || ago >= int64(sysUpTime) {
+			return uint32(0), nil
+		}
+		return sysUpTime - uint32(ago), nil
+	}
+	return nil, fmt.Errorf("%s is neither a DateAndTime nor a TimeStamp", n.Name)
+}
+
+// DecodeDuration converts a value of a node of type TimeTicks or TimeInterval,
+// or derived from them, counting hundredths of seconds, to a time.Duration.
+func (n SmiNode) DecodeDuration(value interface{}) (time.Duration, error) {
+	if !n.hasType("TimeTicks") && !n.hasType("TimeInterval") {
+		return 0, fmt.Errorf("%s is neither a TimeTicks nor a TimeInterval", n.Name)
+	}
+	switch v := value.(type) {
+	case int:
+		return models.TicksToDuration(int64(v)), nil
+	case int32:
+		return models.TicksToDuration(int64(v)), nil
+	case int64:
+		return models.TicksToDuration(v), nil
+	}
+	ticks, ok := toUint32(value)
+	if !ok {
+		return 0, fmt.Errorf("Invalid duration value %v of %s", value, n.Name)
+	}
+	return models.TicksToDuration(int64(ticks)), nil
+}
+
+// toUint32 converts the unsigned integer types TimeTicks values come as
+func toUint32(value interface{}) (uint32, bool) {
+	switch v := value.(type) {
+	case uint32:
+		return v, true
+	case uint:
+		return uint32(v), uint64(v) <= 0xffffffff
+	case uint64:
+		return uint32(v), v <= 0xffffffff
+	case int:
+		return uint32(v), v >= 0 && int64(v) <= 0xffffffff
+	case int64:
+		return uint32(v), v >= 0 && v <= 0xffffffff
+	}
+	return 0, false
+}
@@ -0,0 +1,182 @@
+package gosmi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const timeMib = `TIME-TEST-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    TimeTicks FROM RFC1155-SMI;
+
+DateAndTime ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"
+    STATUS current
+    DESCRIPTION "A date-time specification."
+    SYNTAX OCTET STRING (SIZE (8 | 11))
+
+TimeStamp ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "The value of sysUpTime at an event."
+    SYNTAX TimeTicks
+
+VendorDateTime ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "2d-1d-1d,1d:1d:1d.1d,1a1d:1d"
+    STATUS current
+    DESCRIPTION "A vendor copy of DateAndTime."
+    SYNTAX OCTET STRING (SIZE (11))
+
+UtcDateAndTime ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A DateAndTime in UTC."
+    SYNTAX DateAndTime (SIZE (11))
+
+timeRoot OBJECT IDENTIFIER ::= { iso 3 }
+
+timeCreated OBJECT-TYPE
+    SYNTAX UtcDateAndTime
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Derived from DateAndTime."
+    ::= { timeRoot 1 }
+
+timeVendor OBJECT-TYPE
+    SYNTAX VendorDateTime
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Same layout as DateAndTime."
+    ::= { timeRoot 2 }
+
+timeChanged OBJECT-TYPE
+    SYNTAX TimeStamp
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A TimeStamp."
+    ::= { timeRoot 3 }
+
+timeUp OBJECT-TYPE
+    SYNTAX TimeTicks
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "An uptime."
+    ::= { timeRoot 4 }
+
+timeName OBJECT-TYPE
+    SYNTAX OCTET STRING (SIZE (11))
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "Not a time."
+    ::= { timeRoot 5 }
+END
+`
+
+func TestDateAndTime(t *testing.T) {
+	// 1992-5-26,13:30:15.0,-4:0 from RFC 2579
+	value := []byte{0x07, 0xc8, 5, 26, 13, 30, 15, 0, '-', 4, 0}
+	decoded, err := models.DecodeDateAndTime(value)
+	require.NoError(t, err)
+	assert.True(t, decoded.Equal(time.Date(1992, 5, 26, 17, 30, 15, 0, time.UTC)))
+	assert.Equal(t, value, models.EncodeDateAndTime(decoded))
+
+	local, err := models.DecodeDateAndTime(value[:8])
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, local.Location())
+
+	for _, invalid := range [][]byte{
+		value[:9],
+		{0x07, 0xc8, 13, 26, 13, 30, 15, 0},
+		{0x07, 0xc8, 5, 26, 13, 30, 15, 10},
+		{0x07, 0xc8, 5, 26, 13, 30, 15, 0, '*', 4, 0},
+	} {
+		_, err := models.DecodeDateAndTime(invalid)
+		assert.Error(t, err, "%v", invalid)
+	}
+
+	encoded := models.EncodeDateAndTime(time.Date(2024, 2, 29, 23, 59, 60, int(250*time.Millisecond), time.FixedZone("", 5*3600+30*60)))
+	assert.Equal(t, []byte{0x07, 0xe8, 3, 1, 0, 0, 0, 2, '+', 5, 30}, encoded)
+}
+
+func TestTimeStamp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at, ok := models.TimeStampTime(100, 6100, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-time.Minute), at)
+	// sysUpTime wrapped since the event
+	at, ok = models.TimeStampTime(0xffffffff, 99, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-time.Second), at)
+	_, ok = models.TimeStampTime(0, 6100, now)
+	assert.False(t, ok)
+
+	assert.Equal(t, 1500*time.Millisecond, models.TicksToDuration(150))
+	assert.Equal(t, int64(150), models.DurationToTicks(1509*time.Millisecond))
+}
+
+func TestNodeTime(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI":   rfc1155Smi,
+		"TIME-TEST-MIB": timeMib,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TIME-TEST-MIB")
+	require.NoError(t, err)
+
+	getNode := func(name string) gosmi.SmiNode {
+		node, err := gosmi.GetNode(name)
+		require.NoError(t, err)
+		return node
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	value := []byte{0x07, 0xe7, 12, 31, 23, 0, 0, 0, '+', 0, 0}
+	for _, name := range []string{"timeCreated", "timeVendor"} {
+		node := getNode(name)
+		assert.True(t, node.IsDateAndTime(), name)
+		decoded, err := node.DecodeTime(value, 0, now)
+		require.NoError(t, err, name)
+		assert.True(t, decoded.Equal(now.Add(-time.Hour)), name)
+		encoded, err := node.EncodeTime(decoded, 0, now)
+		require.NoError(t, err, name)
+		assert.Equal(t, value, encoded, name)
+		_, err = node.DecodeTime(uint32(1), 0, now)
+		assert.Error(t, err, name)
+	}
+
+	changed := getNode("timeChanged")
+	assert.True(t, changed.IsTimeStamp())
+	assert.False(t, changed.IsDateAndTime())
+	decoded, err := changed.DecodeTime(uint32(4000), 10000, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute), decoded)
+	encoded, err := changed.EncodeTime(decoded, 10000, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(4000), encoded)
+	// Before the agent restarted
+	encoded, err = changed.EncodeTime(now.Add(-time.Hour), 10000, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), encoded)
+	duration, err := changed.DecodeDuration(uint32(250))
+	require.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, duration)
+
+	up := getNode("timeUp")
+	assert.False(t, up.IsTimeStamp())
+	_, err = up.DecodeTime(uint32(1), 0, now)
+	assert.Error(t, err)
+	duration, err = up.DecodeDuration(uint64(6000))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, duration)
+
+	name := getNode("timeName")
+	assert.False(t, name.IsDateAndTime())
+	_, err = name.EncodeTime(now, 0, now)
+	assert.Error(t, err)
+	_, err = name.DecodeDuration(1)
+	assert.Error(t, err)
+}
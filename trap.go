@@ -0,0 +1,68 @@
+package gosmi
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/lukeod/gosmi/types"
+)
+
+// TrapDefinition is the entry of a NOTIFICATION-TYPE or TRAP-TYPE in a trap
+// catalog. Oid is the numeric notification OID; for TRAP-TYPE it is the
+// enterprise OID followed by 0 and the specific trap number (RFC 3584).
+type TrapDefinition struct {
+	Module      string       `json:"module"`
+	Name        string       `json:"name"`
+	Oid         string       `json:"oid"`
+	Decl        types.Decl   `json:"decl"`
+	Status      types.Status `json:"status"`
+	Description string       `json:"description"`
+	Reference   string       `json:"reference,omitempty"`
+	Objects     []string     `json:"objects"`
+}
+
+// GetTrapCatalog returns the definitions of every notification of the loaded
+// modules, in module load order and then by OID.
+func GetTrapCatalog() (traps []TrapDefinition) {
+	for _, module := range GetLoadedModules() {
+		for _, node := range module.GetNotifications() {
+			trap := TrapDefinition{
+				Module:      module.Name,
+				Name:        node.Name,
+				Oid:         node.RenderNumeric(),
+				Decl:        node.Decl,
+				Status:      node.Status,
+				Description: node.Description,
+				Reference:   node.Reference,
+				Objects:     []string{},
+			}
+			for _, object := range node.GetNotificationObjects() {
+				trap.Objects = append(trap.Objects, object.Name)
+			}
+			traps = append(traps, trap)
+		}
+	}
+	return
+}
+
+// WriteTrapCatalogCSV writes traps as CSV with a header row. The objects of a
+// trap are separated by spaces.
+func WriteTrapCatalogCSV(w io.Writer, traps []TrapDefinition) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"module", "name", "oid", "decl", "status", "description", "reference", "objects"})
+	for _, trap := range traps {
+		cw.Write([]string{
+			trap.Module,
+			trap.Name,
+			trap.Oid,
+			trap.Decl.String(),
+			trap.Status.String(),
+			trap.Description,
+			trap.Reference,
+			strings.Join(trap.Objects, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,71 @@
+package gosmi_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trapMib = `TRAP-MIB DEFINITIONS ::= BEGIN
+trapRoot OBJECT IDENTIFIER ::= { iso 1 }
+
+trapStatus OBJECT-TYPE
+    SYNTAX INTEGER { up(1), down(2) }
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "The status."
+    ::= { trapRoot 1 }
+
+trapNotifications OBJECT IDENTIFIER ::= { trapRoot 2 }
+
+trapStatusChange NOTIFICATION-TYPE
+    OBJECTS { trapStatus }
+    STATUS current
+    DESCRIPTION "The status changed."
+    ::= { trapNotifications 1 }
+
+trapLegacy TRAP-TYPE
+    ENTERPRISE trapRoot
+    VARIABLES { trapStatus }
+    DESCRIPTION "A legacy trap."
+    ::= 3
+END
+`
+
+func TestGetTrapCatalog(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"TRAP-MIB": trapMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TRAP-MIB")
+	require.NoError(t, err)
+
+	traps := gosmi.GetTrapCatalog()
+	require.Len(t, traps, 2)
+	assert.Equal(t, gosmi.TrapDefinition{
+		Module:      "TRAP-MIB",
+		Name:        "trapLegacy",
+		Oid:         "1.1.0.3",
+		Decl:        types.DeclTrapType,
+		Description: "A legacy trap.",
+		Objects:     []string{"trapStatus"},
+	}, traps[0])
+	assert.Equal(t, gosmi.TrapDefinition{
+		Module:      "TRAP-MIB",
+		Name:        "trapStatusChange",
+		Oid:         "1.1.2.1",
+		Decl:        types.DeclNotificationType,
+		Status:      types.StatusCurrent,
+		Description: "The status changed.",
+		Objects:     []string{"trapStatus"},
+	}, traps[1])
+
+	var buf bytes.Buffer
+	require.NoError(t, gosmi.WriteTrapCatalogCSV(&buf, traps[1:]))
+	assert.Equal(t, "module,name,oid,decl,status,description,reference,objects\n"+
+		"TRAP-MIB,trapStatusChange,1.1.2.1,NotificationType,Current,The status changed.,,trapStatus\n", buf.String())
+}
@@ -62,10 +62,91 @@ func (t *SmiType) getRanges() {
 	t.Ranges = ranges
 }
 
+// GetParent returns the type t is derived from. ok is false for the
+// primitive types at the end of every derivation chain.
+func (t SmiType) GetParent() (parent SmiType, ok bool) {
+	smiType := smi.GetParentType(t.smiType)
+	if smiType == nil {
+		return
+	}
+	return CreateType(smiType), true
+}
+
+// Source returns where the type is defined.
+func (t SmiType) Source() Source {
+	return newSource(smi.GetTypeModule(t.smiType), smi.GetTypeLine(t.smiType), smi.GetTypeColumn(t.smiType))
+}
+
+// BaseTypeChain returns t followed by every type it is derived from, ending
+// with the primitive type. Each element holds the effective constraints at
+// its level: its own ranges intersected with those it inherits, and the
+// inherited enumeration, format and units where it declares none.
+func (t SmiType) BaseTypeChain() (chain []SmiType) {
+	seen := make(map[*types.SmiType]bool)
+	for curr, ok := t, t.smiType != nil; ok && !seen[curr.smiType]; curr, ok = curr.GetParent() {
+		seen[curr.smiType] = true
+		chain = append(chain, curr)
+	}
+	for i := len(chain) - 2; i >= 0; i-- {
+		chain[i].inherit(chain[i+1])
+	}
+	return
+}
+
+// smiModules are the modules whose application types ApplicationTags has
+var smiModules = map[string]bool{"SNMPv2-SMI": true, "RFC1155-SMI": true, "RFC1065-SMI": true}
+
+// Tag returns the ASN.1 tag values of the type are encoded with in BER: the
+// APPLICATION tag of the nearest type in its chain defined with an IMPLICIT
+// one, as the application types of SNMPv2-SMI are, or else the UNIVERSAL tag
+// of its base type. ok is false for the base types SNMP has no tag for.
+func (t SmiType) Tag() (tag types.Tag, ok bool) {
+	for _, curr := range t.BaseTypeChain() {
+		if number, ok := smi.GetTypeTag(curr.smiType); ok {
+			return types.Tag{Class: types.TagClassApplication, Number: number}, true
+		}
+		if tag, ok := types.ApplicationTags[types.SmiIdentifier(curr.Name)]; ok {
+			if module := smi.GetTypeModule(curr.smiType); module != nil && smiModules[string(module.Name)] {
+				return tag, true
+			}
+		}
+	}
+	return t.BaseType.Tag()
+}
+
+func (t *SmiType) inherit(parent SmiType) {
+	switch {
+	case len(t.Ranges) == 0:
+		t.Ranges = parent.Ranges
+	case len(parent.Ranges) > 0:
+		t.Ranges = models.IntersectRanges(t.Ranges, parent.Ranges)
+	}
+	if t.Enum == nil {
+		t.Enum = parent.Enum
+	}
+	if t.Format == "" {
+		t.Format = parent.Format
+	}
+	if t.Units == "" {
+		t.Units = parent.Units
+	}
+}
+
 func (t SmiType) String() string {
 	return t.Type.String()
 }
 
+// LoadText fills in the Description and Reference of the type if its module
+// was loaded with SetLazyText, by parsing the module's file again.
+func (t *SmiType) LoadText() (err error) {
+	smiType := t.smiType
+	if smiType != nil && smiType.Name == "" {
+		smiType = smi.GetParentType(smiType)
+	}
+	t.Description, t.Reference, err = smi.GetTypeText(smiType)
+	return
+}
+
 func (t SmiType) GetRaw() (outType *types.SmiType) {
 	return t.smiType
 }
@@ -110,6 +191,12 @@ func CreateTypeFromNode(smiNode *types.SmiNode) (outType *SmiType) {
 	tempType := CreateType(smiType)
 	outType = &tempType
 
+	// Values of the node are subject to the constraints of the whole
+	// derivation chain, not just those of its (possibly implicit) type
+	if parent, ok := outType.GetParent(); ok {
+		outType.inherit(parent.BaseTypeChain()[0])
+	}
+
 	if smiNode.Format != "" {
 		outType.Format = smiNode.Format
 	}
@@ -120,13 +207,16 @@ func CreateTypeFromNode(smiNode *types.SmiNode) (outType *SmiType) {
 	return
 }
 
+// GetType looks the name up in the given module, or in all loaded modules
+// according to the collision policy.
 func GetType(name string, module ...SmiModule) (outType SmiType, err error) {
-	var smiModule *types.SmiModule
+	var smiType *types.SmiType
 	if len(module) > 0 {
-		smiModule = module[0].GetRaw()
+		smiType = smi.GetType(module[0].GetRaw(), name)
+	} else if smiType, err = smi.ResolveType(name); err != nil {
+		err = fmt.Errorf("Could not resolve type named %s: %w", name, err)
+		return
 	}
-
-	smiType := smi.GetType(smiModule, name)
 	if smiType == nil {
 		if len(module) > 0 {
 			err = fmt.Errorf("Could not find type named %s in module %s", name, module[0].Name)
@@ -138,6 +228,16 @@ func GetType(name string, module ...SmiModule) (outType SmiType, err error) {
 	return CreateType(smiType), nil
 }
 
+// GetTypeInModule looks the name up in the named module, loading it if
+// needed, whatever the collision policy.
+func GetTypeInModule(module, name string) (outType SmiType, err error) {
+	smiModule, err := GetModule(module)
+	if err != nil {
+		return
+	}
+	return GetType(name, smiModule)
+}
+
 func convertValue(value types.SmiValue) (outValue int64) {
 	switch v := value.Value.(type) {
 	case int32:
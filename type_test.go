@@ -0,0 +1,234 @@
+package gosmi_test
+
+import (
+	"testing"
+
+	"github.com/lukeod/gosmi"
+	"github.com/lukeod/gosmi/diag"
+	"github.com/lukeod/gosmi/models"
+	"github.com/lukeod/gosmi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chainMib = `CHAIN-MIB DEFINITIONS ::= BEGIN
+BaseString ::= TEXTUAL-CONVENTION
+    DISPLAY-HINT "255a"
+    STATUS current
+    DESCRIPTION "A base string."
+    SYNTAX OCTET STRING (SIZE (0..255))
+
+ShortString ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A string derived from a textual convention."
+    SYNTAX BaseString (SIZE (4..32 | 300..400))
+
+chainRoot OBJECT IDENTIFIER ::= { iso 4 }
+
+chainScalar OBJECT-TYPE
+    SYNTAX ShortString (SIZE (8..64))
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A scalar refining a derived type."
+    ::= { chainRoot 1 }
+
+chainNarrow OBJECT-TYPE
+    SYNTAX ShortString (SIZE (8..16))
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A scalar legally refining a derived type."
+    ::= { chainRoot 2 }
+END
+`
+
+func TestSmiTypeBaseTypeChain(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"CHAIN-MIB": chainMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("CHAIN-MIB")
+	require.NoError(t, err)
+
+	shortString, err := gosmi.GetType("ShortString")
+	require.NoError(t, err)
+	parent, ok := shortString.GetParent()
+	require.True(t, ok)
+	assert.Equal(t, "BaseString", parent.Name)
+
+	chain := shortString.BaseTypeChain()
+	require.Len(t, chain, 3)
+	assert.Equal(t, "ShortString", chain[0].Name)
+	assert.Equal(t, "BaseString", chain[1].Name)
+	assert.Equal(t, types.BaseTypeOctetString, chain[2].BaseType)
+	_, ok = chain[2].GetParent()
+	assert.False(t, ok)
+
+	assert.Equal(t, []models.Range{{BaseType: types.BaseTypeUnsigned32, MinValue: 4, MaxValue: 32}}, chain[0].Ranges)
+	assert.Equal(t, "255a", chain[0].Format)
+	assert.Equal(t, []models.Range{{BaseType: types.BaseTypeUnsigned32, MinValue: 0, MaxValue: 255}}, chain[1].Ranges)
+	assert.Empty(t, chain[2].Ranges)
+
+	node, err := gosmi.GetNode("chainScalar")
+	require.NoError(t, err)
+	require.NotNil(t, node.SmiType)
+	chain = node.SmiType.BaseTypeChain()
+	require.Len(t, chain, 4)
+	assert.Equal(t, []models.Range{{BaseType: types.BaseTypeUnsigned32, MinValue: 8, MaxValue: 32}}, chain[0].Ranges)
+}
+
+func TestSmiNodeEffectiveConstraints(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"CHAIN-MIB": chainMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("CHAIN-MIB")
+	require.NoError(t, err)
+
+	var widened []string
+	for _, d := range gosmi.GetDiagnostics() {
+		if d.Code == diag.CodeRangeWidened {
+			widened = append(widened, d.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"Ranges of type ShortString are not within those of its parent type BaseString",
+		"Ranges of object chainScalar are not within those of its type ShortString",
+	}, widened)
+
+	node, err := gosmi.GetNode("chainNarrow")
+	require.NoError(t, err)
+	require.NotNil(t, node.Type)
+	assert.Equal(t, "ShortString", node.Type.Name)
+	assert.Equal(t, []models.Range{{BaseType: types.BaseTypeUnsigned32, MinValue: 8, MaxValue: 16}}, node.Type.Ranges)
+	assert.Equal(t, "255a", node.Type.Format)
+
+	node, err = gosmi.GetNode("chainScalar")
+	require.NoError(t, err)
+	require.NotNil(t, node.Type)
+	assert.Equal(t, []models.Range{{BaseType: types.BaseTypeUnsigned32, MinValue: 8, MaxValue: 32}}, node.Type.Ranges)
+}
+
+const enumMib = `ENUM-MIB DEFINITIONS ::= BEGIN
+Status ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "An operational status."
+    SYNTAX INTEGER { up(1), down(2), testing(3) }
+
+DerivedStatus ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "A status derived from another."
+    SYNTAX Status
+
+enumRoot OBJECT IDENTIFIER ::= { iso 6 }
+
+enumInline OBJECT-TYPE
+    SYNTAX INTEGER { idle(0), testing(3), busy(4) }
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "An inline enumeration."
+    ::= { enumRoot 1 }
+
+enumStatus OBJECT-TYPE
+    SYNTAX Status
+    MAX-ACCESS read-only
+    STATUS current
+    DESCRIPTION "A status."
+    ::= { enumRoot 2 }
+END
+`
+
+func TestSmiTypeEnumLookup(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{"ENUM-MIB": enumMib})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("ENUM-MIB")
+	require.NoError(t, err)
+
+	status, err := gosmi.GetType("Status")
+	require.NoError(t, err)
+	value, ok := status.EnumValue("down")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), value)
+	name, ok := status.EnumName(3)
+	assert.True(t, ok)
+	assert.Equal(t, "testing", name)
+	_, ok = status.EnumValue("sideways")
+	assert.False(t, ok)
+	_, ok = status.EnumName(9)
+	assert.False(t, ok)
+
+	derived, err := gosmi.GetType("DerivedStatus")
+	require.NoError(t, err)
+	value, ok = derived.EnumValue("up")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), value)
+
+	node, err := gosmi.GetNode("enumInline")
+	require.NoError(t, err)
+	name, ok = node.SmiType.EnumName(4)
+	assert.True(t, ok)
+	assert.Equal(t, "busy", name)
+
+	matches := gosmi.FindNamedNumbers("testing", 3)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "Status", matches[0].Type.Name)
+	assert.Nil(t, matches[0].Node)
+	require.NotNil(t, matches[1].Node)
+	assert.Equal(t, "enumInline", matches[1].Node.Name)
+	assert.Equal(t, "ENUM-MIB", matches[1].Module)
+	assert.Empty(t, gosmi.FindNamedNumbers("testing", 4))
+	assert.Len(t, gosmi.FindNamedNumbers("", 3), 2)
+	assert.Len(t, gosmi.FindNamedNumbers("up"), 1)
+}
+
+func TestSmiTypeTag(t *testing.T) {
+	dir := writeCompileFiles(t, map[string]string{
+		"RFC1155-SMI": rfc1155Smi,
+		"TAG-MIB": `TAG-MIB DEFINITIONS ::= BEGIN
+IMPORTS
+    Counter, TimeTicks, IpAddress FROM RFC1155-SMI;
+
+Uptime ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "Derived from an application type."
+    SYNTAX TimeTicks
+
+VendorCounter ::= [APPLICATION 9] IMPLICIT INTEGER (0..255)
+
+Flag ::= TEXTUAL-CONVENTION
+    STATUS current
+    DESCRIPTION "An enumeration."
+    SYNTAX INTEGER { on(1), off(2) }
+END
+`,
+	})
+	gosmi.Init()
+	defer gosmi.Exit()
+	gosmi.SetPath(dir)
+	_, err := gosmi.LoadModule("TAG-MIB")
+	require.NoError(t, err)
+
+	for name, want := range map[string]types.Tag{
+		"Counter":       types.TagCounter32,
+		"IpAddress":     types.TagIpAddress,
+		"Uptime":        types.TagTimeTicks,
+		"VendorCounter": {Class: types.TagClassApplication, Number: 9},
+		"Flag":          types.TagInteger,
+	} {
+		smiType, err := gosmi.GetType(name)
+		require.NoError(t, err, name)
+		tag, ok := smiType.Tag()
+		assert.True(t, ok, name)
+		assert.Equal(t, want, tag, name)
+	}
+	assert.Equal(t, "[APPLICATION 6]", types.TagCounter64.String())
+	assert.Equal(t, byte(0x46), types.TagCounter64.Identifier())
+	assert.Equal(t, byte(0x02), types.TagInteger.Identifier())
+
+	tag, ok := types.BaseTypeOctetString.Tag()
+	assert.True(t, ok)
+	assert.Equal(t, types.TagOctetString, tag)
+	_, ok = types.BaseTypeFloat64.Tag()
+	assert.False(t, ok)
+}
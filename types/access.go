@@ -15,4 +15,5 @@ const (
 	AccessInstallNotify
 	AccessReportOnly
 	AccessEventOnly
+	AccessWriteOnly
 )
@@ -7,9 +7,9 @@ import (
 	"fmt"
 )
 
-const _Access_name = "UnknownNotImplementedNotAccessibleNotifyReadOnlyReadWriteInstallInstallNotifyReportOnlyEventOnly"
+const _Access_name = "UnknownNotImplementedNotAccessibleNotifyReadOnlyReadWriteInstallInstallNotifyReportOnlyEventOnlyWriteOnly"
 
-var _Access_index = [...]uint8{0, 7, 21, 34, 40, 48, 57, 64, 77, 87, 96}
+var _Access_index = [...]uint8{0, 7, 21, 34, 40, 48, 57, 64, 77, 87, 96, 105}
 
 func (i Access) String() string {
 	if i < 0 || i >= Access(len(_Access_index)-1) {
@@ -19,16 +19,17 @@ func (i Access) String() string {
 }
 
 var _AccessNameToValue_map = map[string]Access{
-	_Access_name[0:7]:   0,
-	_Access_name[7:21]:  1,
-	_Access_name[21:34]: 2,
-	_Access_name[34:40]: 3,
-	_Access_name[40:48]: 4,
-	_Access_name[48:57]: 5,
-	_Access_name[57:64]: 6,
-	_Access_name[64:77]: 7,
-	_Access_name[77:87]: 8,
-	_Access_name[87:96]: 9,
+	_Access_name[0:7]:    0,
+	_Access_name[7:21]:   1,
+	_Access_name[21:34]:  2,
+	_Access_name[34:40]:  3,
+	_Access_name[40:48]:  4,
+	_Access_name[48:57]:  5,
+	_Access_name[57:64]:  6,
+	_Access_name[64:77]:  7,
+	_Access_name[77:87]:  8,
+	_Access_name[87:96]:  9,
+	_Access_name[96:105]: 10,
 }
 
 func AccessFromString(s string) (Access, error) {
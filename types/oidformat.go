@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OidFormat selects how OIDs are rendered, after net-snmp's -O output
+// options. The examples are for instance 3 of IF-MIB::ifInOctets.
+type OidFormat int
+
+const (
+	// OidFormatNumeric renders 1.3.6.1.2.1.2.2.1.10.3, like -On.
+	OidFormatNumeric OidFormat = iota
+	// OidFormatFull renders iso.org.dod.internet.mgmt.mib-2.interfaces.
+	// ifTable.ifEntry.ifInOctets.3, like -Of.
+	OidFormatFull
+	// OidFormatSuffix renders ifInOctets.3, like -Os.
+	OidFormatSuffix
+	// OidFormatModule renders IF-MIB::ifInOctets.3, like -OS.
+	OidFormatModule
+)
+
+var oidFormatNames = []string{"numeric", "full", "suffix", "module"}
+
+func (f OidFormat) String() string {
+	if f < 0 || int(f) >= len(oidFormatNames) {
+		return fmt.Sprintf("OidFormat(%d)", int(f))
+	}
+	return oidFormatNames[f]
+}
+
+// OidFormatFromString parses the name of an OidFormat or the letter of the
+// equivalent net-snmp -O option: n, f, s or S.
+func OidFormatFromString(s string) (OidFormat, error) {
+	switch s {
+	case "n":
+		return OidFormatNumeric, nil
+	case "f":
+		return OidFormatFull, nil
+	case "s":
+		return OidFormatSuffix, nil
+	case "S":
+		return OidFormatModule, nil
+	}
+	for i, name := range oidFormatNames {
+		if strings.EqualFold(s, name) {
+			return OidFormat(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%s does not belong to OidFormat values", s)
+}
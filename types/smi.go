@@ -90,5 +90,13 @@ type SmiMacro struct {
 	Reference   string
 }
 
+// SmiValueAssignment is a value assignment of a type other than OBJECT
+// IDENTIFIER, such as someInteger INTEGER ::= 5. There is no libsmi
+// equivalent.
+type SmiValueAssignment struct {
+	Name  SmiIdentifier
+	Value SmiValue
+}
+
 // void (SmiErrorHandler) (char *path, int line, int severity, char *msg, char *tag)
 type SmiErrorHandler func(path string, line int, severity int, msg string, tag string)
@@ -0,0 +1,95 @@
+package types
+
+import "strconv"
+
+// TagClass is the class of an ASN.1 tag.
+type TagClass int
+
+const (
+	TagClassUniversal TagClass = iota
+	TagClassApplication
+	TagClassContextSpecific
+	TagClassPrivate
+)
+
+func (c TagClass) String() string {
+	switch c {
+	case TagClassUniversal:
+		return "UNIVERSAL"
+	case TagClassApplication:
+		return "APPLICATION"
+	case TagClassContextSpecific:
+		return "CONTEXT-SPECIFIC"
+	case TagClassPrivate:
+		return "PRIVATE"
+	}
+	return "TagClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Tag is the ASN.1 tag a value is encoded with in BER.
+type Tag struct {
+	Class  TagClass
+	Number int
+}
+
+// String renders the tag as in ASN.1, such as [APPLICATION 6].
+func (t Tag) String() string {
+	return "[" + t.Class.String() + " " + strconv.Itoa(t.Number) + "]"
+}
+
+// Identifier returns the BER identifier octet of a primitive encoding with
+// the tag, whose number must be below 31, as those of SNMP are.
+func (t Tag) Identifier() byte {
+	return byte(t.Class)<<6 | byte(t.Number)
+}
+
+// The tags of the SNMP types (RFC 2578 and RFC 1442 for UInteger32)
+var (
+	TagInteger          = Tag{Class: TagClassUniversal, Number: 2}
+	TagOctetString      = Tag{Class: TagClassUniversal, Number: 4}
+	TagNull             = Tag{Class: TagClassUniversal, Number: 5}
+	TagObjectIdentifier = Tag{Class: TagClassUniversal, Number: 6}
+	TagIpAddress        = Tag{Class: TagClassApplication, Number: 0}
+	TagCounter32        = Tag{Class: TagClassApplication, Number: 1}
+	TagGauge32          = Tag{Class: TagClassApplication, Number: 2}
+	TagTimeTicks        = Tag{Class: TagClassApplication, Number: 3}
+	TagOpaque           = Tag{Class: TagClassApplication, Number: 4}
+	TagNsapAddress      = Tag{Class: TagClassApplication, Number: 5}
+	TagCounter64        = Tag{Class: TagClassApplication, Number: 6}
+	TagUInteger32       = Tag{Class: TagClassApplication, Number: 7}
+)
+
+// ApplicationTags maps the application types of SNMPv2-SMI and RFC1155-SMI to
+// their tags, for base modules defining them without their IMPLICIT tags.
+var ApplicationTags = map[SmiIdentifier]Tag{
+	"IpAddress":      TagIpAddress,
+	"NetworkAddress": TagIpAddress,
+	"Counter":        TagCounter32,
+	"Counter32":      TagCounter32,
+	"Gauge":          TagGauge32,
+	"Gauge32":        TagGauge32,
+	"Unsigned32":     TagGauge32,
+	"TimeTicks":      TagTimeTicks,
+	"Opaque":         TagOpaque,
+	"NsapAddress":    TagNsapAddress,
+	"Counter64":      TagCounter64,
+	"UInteger32":     TagUInteger32,
+}
+
+// Tag returns the universal tag of the values of a base type, which the
+// application types derived from it replace. Only the base types of SNMP have
+// one: the 64 bit and floating point types of SMIng are carried in an Opaque.
+func (b BaseType) Tag() (Tag, bool) {
+	switch b {
+	case BaseTypeInteger32, BaseTypeEnum:
+		return TagInteger, true
+	case BaseTypeOctetString, BaseTypeBits:
+		return TagOctetString, true
+	case BaseTypeObjectIdentifier:
+		return TagObjectIdentifier, true
+	case BaseTypeUnsigned32:
+		// An INTEGER with a non-negative range is still universal
+		return TagInteger, true
+	}
+	return Tag{}, false
+}